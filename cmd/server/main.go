@@ -5,19 +5,24 @@ import (
 	"database/sql"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
-	"runtime"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"veterinaria-server/internal/accesos"
 	"veterinaria-server/internal/album"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/caja"
 	"veterinaria-server/internal/cita_medica"
 	"veterinaria-server/internal/clientes"
 	"veterinaria-server/internal/compra"
+	"veterinaria-server/internal/comprobante_electronico"
 	"veterinaria-server/internal/config"
+	"veterinaria-server/internal/confirmacion_cita"
 	"veterinaria-server/internal/consultas"
 	"veterinaria-server/internal/detalle_compra"
 	"veterinaria-server/internal/detalle_examen_cualitativo"
@@ -36,18 +41,28 @@ import (
 	"veterinaria-server/internal/factura"
 	"veterinaria-server/internal/generos"
 	"veterinaria-server/internal/healthcheck"
+	"veterinaria-server/internal/horario_veterinario"
 	"veterinaria-server/internal/hospitalizacion"
 	"veterinaria-server/internal/lote"
 	"veterinaria-server/internal/mascotas"
 	"veterinaria-server/internal/medida"
+	"veterinaria-server/internal/movimiento_inventario"
+	"veterinaria-server/internal/nota_credito"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/internal/permiso"
 	"veterinaria-server/internal/productos"
 	"veterinaria-server/internal/proveedor"
 	"veterinaria-server/internal/proveedor_producto"
 	"veterinaria-server/internal/receta"
+	"veterinaria-server/internal/recordatorio_cita"
 	"veterinaria-server/internal/rol"
 	"veterinaria-server/internal/servicio_producto"
 	"veterinaria-server/internal/servicios"
+	"veterinaria-server/internal/sri"
 	"veterinaria-server/internal/stock_individual"
+	"veterinaria-server/internal/tarifa_iva"
+	"veterinaria-server/internal/tipo_cita"
 	"veterinaria-server/internal/tipo_examen"
 	"veterinaria-server/internal/unidad"
 	"veterinaria-server/internal/usuario_rol"
@@ -55,30 +70,23 @@ import (
 	"veterinaria-server/pkg/accesslog"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/migrate"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/storage"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 	"github.com/go-ozzo/ozzo-routing/v2/content"
 	"github.com/go-ozzo/ozzo-routing/v2/cors"
-	"github.com/go-ozzo/ozzo-routing/v2/file"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/mileusna/crontab"
-	"google.golang.org/protobuf/proto"
-
-	_ "github.com/mattn/go-sqlite3"
-
-	"github.com/mdp/qrterminal"
-	"go.mau.fi/whatsmeow"
-	waProto "go.mau.fi/whatsmeow/binary/proto"
-	"go.mau.fi/whatsmeow/store/sqlstore"
-	"go.mau.fi/whatsmeow/types"
-	waLog "go.mau.fi/whatsmeow/util/log"
 )
 
 // Version indicates the current version of the application.
 var Version = "1.0.0"
 
 var flagConfig = flag.String("config", "./config/local.yml", "path to the config file")
+var flagMigrations = flag.String("migrations", "./migrations", "path to the directory of the database migrations")
 
 func main() {
 	flag.Parse()
@@ -106,6 +114,15 @@ func main() {
 		}
 	}()
 
+	// "server migrate up|down [n]|version" manages the schema instead of serving
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(migrate.New(db, *flagMigrations, logger), flag.Args()[1:], logger); err != nil {
+			logger.Error(err)
+			os.Exit(-1)
+		}
+		return
+	}
+
 	// build HTTP server
 	address := fmt.Sprintf(":%v", cfg.ServerPort)
 	hs := &http.Server{
@@ -122,6 +139,58 @@ func main() {
 	}
 }
 
+// runMigrate runs the migrate command given by args: up applies the pending migrations, down reverts
+// the last n migrations (1 by default) and version prints the version of the schema.
+func runMigrate(migrator *migrate.Migrator, args []string, logger log.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: server migrate up|down [n]|version")
+	}
+	switch args[0] {
+	case "up":
+		aplicadas, err := migrator.Up()
+		logger.Infof("%d migrations applied", aplicadas)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations to revert: %s", args[1])
+			}
+			steps = n
+		}
+		revertidas, err := migrator.Down(steps)
+		logger.Infof("%d migrations reverted", revertidas)
+		return err
+	case "version":
+		version, dirty, err := migrator.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d", version)
+		if dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command: %s", args[0])
+}
+
+// buildStorage returns the storage of the directory, or key prefix in the bucket, with the configured backend.
+func buildStorage(cfg *config.Config, dir string) storage.Storage {
+	if cfg.Storage == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, dir)
+	}
+	return storage.NewLocal(dir)
+}
+
 // buildHandler sets up the HTTP routing and builds an HTTP handler.
 func buildHandler(logger log.Logger, db *dbcontext.DB, cfg *config.Config) http.Handler {
 	router := routing.New()
@@ -138,11 +207,30 @@ func buildHandler(logger log.Logger, db *dbcontext.DB, cfg *config.Config) http.
 
 	rg := router.Group("/v1")
 
-	authHandler := auth.Handler(cfg.JWTSigningKey)
+	politicaClave := auth.PoliticaClave{
+		LongitudMinima: cfg.ClaveLongitudMinima,
+		Mayuscula:      cfg.ClaveMayuscula,
+		Numero:         cfg.ClaveNumero,
+		Simbolo:        cfg.ClaveSimbolo,
+	}
+	authService := auth.NewService(db, cfg.JWTSigningKey, cfg.JWTExpiration, cfg.JWTAccessExpiration, auth.Seguridad{
+		Politica:       politicaClave,
+		MaxIntentos:    cfg.LoginMaxIntentos,
+		MinutosBloqueo: cfg.LoginMinutosBloqueo,
+	}, logger)
+	authHandler := auth.HandlerConRevocacion(cfg.JWTSigningKey, authService)
+	//Cada grupo de rutas exige que los roles del usuario tengan el permiso de su módulo
+	permisos := permiso.NewService(permiso.NewRepository(db, logger), logger)
+	authorize := func(modulo string) routing.Handler {
+		return auth.Authorize(authHandler, permisos, modulo)
+	}
+	plantillas := buildStorage(cfg, cfg.StoragePlantillas)
+	documentos := buildStorage(cfg, cfg.StorageDocumentos)
+	cargas := buildStorage(cfg, cfg.StorageCargas)
 
 	album.RegisterHandlers(rg.Group(""),
 		album.NewService(album.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloAlbums), logger,
 	)
 
 	accesos.RegisterHandlers(rg.Group(""),
@@ -152,354 +240,408 @@ func buildHandler(logger log.Logger, db *dbcontext.DB, cfg *config.Config) http.
 
 	generos.RegisterHandlers(rg.Group(""),
 		generos.NewService(generos.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	especies.RegisterHandlers(rg.Group(""),
 		especies.NewService(especies.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	clientes.RegisterHandlers(rg.Group(""),
 		clientes.NewService(clientes.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloClientes), logger,
 	)
 
 	mascotas.RegisterHandlers(rg.Group(""),
 		mascotas.NewService(mascotas.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloClientes), logger,
 	)
 
 	tipo_examen.RegisterHandlers(rg.Group(""),
 		tipo_examen.NewService(tipo_examen.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloCatalogos), logger, db,
 	)
 
 	detalle_examen_cualitativo.RegisterHandlers(rg.Group(""),
 		detalle_examen_cualitativo.NewService(detalle_examen_cualitativo.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	detalle_examen_cuantitativo.RegisterHandlers(rg.Group(""),
 		detalle_examen_cuantitativo.NewService(detalle_examen_cuantitativo.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	detalle_examen_informativo.RegisterHandlers(rg.Group(""),
 		detalle_examen_informativo.NewService(detalle_examen_informativo.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	examen_mascota.RegisterHandlers(rg.Group(""),
 		examen_mascota.NewService(examen_mascota.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloConsultas), logger, db, plantillas, documentos,
 	)
 
 	factura.RegisterHandlers(rg.Group(""),
 		factura.NewService(factura.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloFacturacion), logger, db,
+	)
+
+	tarifa_iva.RegisterHandlers(rg.Group(""),
+		tarifa_iva.NewService(tarifa_iva.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloCatalogos), logger,
+	)
+
+	comprobante_electronico.RegisterHandlers(rg.Group(""),
+		comprobante_electronico.NewService(comprobante_electronico.NewRepository(db, logger),
+			emisorSRI(cfg), firmadorSRI(cfg, logger), clienteSRI(cfg), logger),
+		authorize(permiso.ModuloFacturacion), logger,
 	)
 
 	compra.RegisterHandlers(rg.Group(""),
 		compra.NewService(compra.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloInventario), logger, db,
 	)
 
 	detalle_compra.RegisterHandlers(rg.Group(""),
 		detalle_compra.NewService(detalle_compra.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloInventario), logger,
 	)
 
 	detalle_factura.RegisterHandlers(rg.Group(""),
 		detalle_factura.NewService(detalle_factura.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloFacturacion), logger,
+	)
+
+	nota_credito.RegisterHandlers(rg.Group(""),
+		nota_credito.NewService(nota_credito.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloFacturacion), logger,
+	)
+
+	pago.RegisterHandlers(rg.Group(""),
+		pago.NewService(pago.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloPagos), logger,
+	)
+
+	caja.RegisterHandlers(rg.Group(""),
+		caja.NewService(caja.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloCaja), logger, plantillas, documentos,
 	)
 
 	consultas.RegisterHandlers(rg.Group(""),
 		consultas.NewService(consultas.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloConsultas), logger, db,
 	)
 
 	proveedor.RegisterHandlers(rg.Group(""),
 		proveedor.NewService(proveedor.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloInventario), logger,
 	)
 
 	productos.RegisterHandlers(rg.Group(""),
 		productos.NewService(productos.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloInventario), logger,
 	)
 
 	proveedor_producto.RegisterHandlers(rg.Group(""),
 		proveedor_producto.NewService(proveedor_producto.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloInventario), logger,
 	)
 
+	movimientosService := movimiento_inventario.NewService(movimiento_inventario.NewRepository(db, logger), logger)
+
 	lote.RegisterHandlers(rg.Group(""),
-		lote.NewService(lote.NewRepository(db, logger), logger),
-		authHandler, logger,
+		lote.NewService(lote.NewRepository(db, logger), movimientosService, logger),
+		authorize(permiso.ModuloInventario), logger,
 	)
 
 	stock_individual.RegisterHandlers(rg.Group(""),
-		stock_individual.NewService(stock_individual.NewRepository(db, logger), logger),
-		authHandler, logger,
+		stock_individual.NewService(stock_individual.NewRepository(db, logger), movimientosService, logger),
+		authorize(permiso.ModuloInventario), logger,
+	)
+
+	movimiento_inventario.RegisterHandlers(rg.Group(""),
+		movimientosService,
+		authorize(permiso.ModuloInventario), logger,
 	)
 
 	unidad.RegisterHandlers(rg.Group(""),
 		unidad.NewService(unidad.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	medida.RegisterHandlers(rg.Group(""),
 		medida.NewService(medida.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	servicios.RegisterHandlers(rg.Group(""),
 		servicios.NewService(servicios.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloCatalogos), logger, db,
 	)
 
 	servicio_producto.RegisterHandlers(rg.Group(""),
 		servicio_producto.NewService(servicio_producto.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCatalogos), logger,
 	)
 
 	documento_mascota.RegisterHandlers(rg.Group(""),
 		documento_mascota.NewService(documento_mascota.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloClientes), logger, cargas,
 	)
 
 	hospitalizacion.RegisterHandlers(rg.Group(""),
-		hospitalizacion.NewService(hospitalizacion.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		hospitalizacion.NewServiceConTarifa(hospitalizacion.NewRepository(db, logger), logger, money.FromFloat(cfg.HospitalizacionTarifaDiaria)),
+		authorize(permiso.ModuloHospitalizaciones), logger, db,
 	)
 
 	detalle_hospitalizacion.RegisterHandlers(rg.Group(""),
 		detalle_hospitalizacion.NewService(detalle_hospitalizacion.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloHospitalizaciones), logger,
 	)
 
 	detalle_servicio_hospitalizacion.RegisterHandlers(rg.Group(""),
 		detalle_servicio_hospitalizacion.NewService(detalle_servicio_hospitalizacion.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloHospitalizaciones), logger, db,
 	)
 
 	detalle_uso_servicio.RegisterHandlers(rg.Group(""),
 		detalle_uso_servicio.NewService(detalle_uso_servicio.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloHospitalizaciones), logger,
 	)
 
 	usuarios.RegisterHandlers(rg.Group(""),
-		usuarios.NewService(usuarios.NewRepository(db, logger), logger),
-		authHandler, logger,
+		usuarios.NewService(usuarios.NewRepository(db, logger), logger, politicaClave),
+		authorize(permiso.ModuloUsuarios), logger,
 	)
 
 	rol.RegisterHandlers(rg.Group(""),
 		rol.NewService(rol.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloRoles), logger,
+	)
+
+	permiso.RegisterHandlers(rg.Group(""),
+		permisos,
+		authorize(permiso.ModuloRoles), logger,
+	)
+
+	auditoria.RegisterHandlers(rg.Group(""),
+		auditoria.NewService(auditoria.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloAuditoria), logger,
 	)
 
 	usuario_rol.RegisterHandlers(rg.Group(""),
 		usuario_rol.NewService(usuario_rol.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloUsuarios), logger,
 	)
 
 	receta.RegisterHandlers(rg.Group(""),
 		receta.NewService(receta.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloConsultas), logger, plantillas, documentos,
 	)
 
 	detalle_servicio_consulta.RegisterHandlers(rg.Group(""),
 		detalle_servicio_consulta.NewService(detalle_servicio_consulta.NewRepository(db, logger), logger),
-		authHandler, logger, db,
+		authorize(permiso.ModuloConsultas), logger, db,
 	)
 
 	detalle_uso_servicio_consulta.RegisterHandlers(rg.Group(""),
 		detalle_uso_servicio_consulta.NewService(detalle_uso_servicio_consulta.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloConsultas), logger,
 	)
 
 	cita_medica.RegisterHandlers(rg.Group(""),
 		cita_medica.NewService(cita_medica.NewRepository(db, logger), logger),
-		authHandler, logger,
+		authorize(permiso.ModuloCitas), logger,
 	)
 
 	auth.RegisterHandlers(rg.Group(""),
-		auth.NewService(db, cfg.JWTSigningKey, cfg.JWTExpiration, logger),
-		logger,
-	)
-
-	// Serving Static Files
-	if runtime.GOOS == "windows" {
-		rg.Get("/files/*", file.Server(file.PathMap{
-			"/v1/files":                   "/resources/",
-			"/v1/files/documentosMascota": "/documentos-mascota",
-		}))
-	} else {
-		rg.Get("/files/*", file.Server(file.PathMap{
-			"/v1/files":                   "/root/go/src/github.com/JorgeTom0609/veterinaria-server/resources/",
-			"/v1/files/documentosMascota": "/root/go/src/github.com/JorgeTom0609/veterinaria-server/documentos-mascota",
-		}))
-	}
+		authService,
+		authHandler, logger,
+	)
 
-	wac, err := WAConnect()
-	if err != nil {
-		fmt.Println(err)
+	notifiers, fuentes := canales(cfg, logger)
+	notificacionesService := notificaciones.NewService(notificaciones.NewRepository(db, logger), notifiers, logger)
+	notificaciones.RegisterHandlers(rg.Group(""),
+		notificacionesService,
+		authorize(permiso.ModuloNotificaciones), logger,
+	)
+
+	tipo_cita.RegisterHandlers(rg.Group(""),
+		tipo_cita.NewService(tipo_cita.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloCatalogos), logger,
+	)
+
+	horario_veterinario.RegisterHandlers(rg.Group(""),
+		horario_veterinario.NewService(horario_veterinario.NewRepository(db, logger), logger),
+		authorize(permiso.ModuloCitas), logger,
+	)
+
+	recordatoriosService := recordatorio_cita.NewService(recordatorio_cita.NewRepository(db, logger), notificacionesService, logger)
+	recordatorio_cita.RegisterHandlers(rg.Group(""),
+		recordatoriosService,
+		authorize(permiso.ModuloCitas), logger,
+	)
+
+	confirmacionesService := confirmacion_cita.NewService(confirmacion_cita.NewRepository(db, logger), notificacionesService, logger)
+	confirmacion_cita.RegisterHandlers(rg.Group(""),
+		confirmacionesService,
+		authorize(permiso.ModuloNotificaciones), logger,
+	)
+	escucharMensajes(db, fuentes, confirmacionesService, logger)
+
+	// Serving the generated documents and the uploaded documentos of the mascotas
+	rg.Get("/files/"+documento_mascota.RutaCargas+"*", storage.Server(cargas, "/v1/files/"+documento_mascota.RutaCargas))
+	rg.Get("/files/*", storage.Server(documentos, "/v1/files/"))
+
+	if err := programarTareas(cfg, db, notificacionesService, recordatoriosService, logger); err != nil {
+		logger.Errorf("failed to schedule the tareas: %s", err)
 	}
 
-	cron := crontab.New()
+	return router
+}
 
-	//err = cron.AddJob("*/5 * * * *", func() {
-	err = cron.AddJob("00 10 * * *", func() {
-		wac, err = WAConnect()
+// canales returns the Notifier of each configured canal and the Fuentes of the canales that receive mensajes.
+// A canal that fails to connect is left out so the server still starts, and its notificaciones can not be queued.
+func canales(cfg *config.Config, logger log.Logger) (map[string]notificaciones.Notifier, []notificaciones.Fuente) {
+	notifiers := map[string]notificaciones.Notifier{}
+	fuentes := []notificaciones.Fuente{}
+	if cfg.WhatsAppSesion != "" {
+		client, err := notificaciones.ConectarWhatsApp(cfg.WhatsAppSesion, logger)
 		if err != nil {
-			fmt.Println(err)
-		}
-		ctx := context.Background()
-		//Buscar Citas Sin notificar
-		scm := cita_medica.NewService(cita_medica.NewRepository(db, logger), logger)
-		citas, err1 := scm.GetCitasMedicaSinNotificar(ctx)
-
-		if err1 != nil {
-			return
+			logger.Errorf("failed to connect to WhatsApp: %s", err)
+		} else {
+			notifiers[notificaciones.CanalWhatsApp] = notificaciones.NewWhatsApp(client)
+			fuentes = append(fuentes, notificaciones.NewFuenteWhatsApp(client))
 		}
+	}
+	if cfg.SMTPHost != "" {
+		notifiers[notificaciones.CanalEmail] = notificaciones.NewEmail(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsuario, cfg.SMTPClave, cfg.SMTPRemitente)
+	}
+	return notifiers, fuentes
+}
 
-		for i := 0; i < len(citas); i++ {
-			_, err = wac.SendMessage(types.JID{
-				User:   citas[i].Telefono,
-				Server: types.DefaultUserServer,
-			}, "", &waProto.Message{
-				Conversation: proto.String("Saludos " + citas[i].Duenio +
-					", veterinaria DELFICAR le informa que el día *" + Format(citas[i].Fecha) +
-					"* tiene agendada una cita médica para su mascota *" + citas[i].Mascota +
-					"* por el siguiente motivo: *" + citas[i].Motivo + "*."),
+// escucharMensajes processes the mensajes received by the fuentes, each in its own transaction.
+func escucharMensajes(db *dbcontext.DB, fuentes []notificaciones.Fuente, sc confirmacion_cita.Service, logger log.Logger) {
+	for _, fuente := range fuentes {
+		fuente.Escuchar(func(mensaje notificaciones.MensajeRecibido) {
+			err := db.Transactional(context.Background(), func(ctx context.Context) error {
+				_, err := sc.ProcesarMensaje(ctx, mensaje)
+				return err
 			})
 			if err != nil {
-				fmt.Println(err)
-			} else {
-				_, _ = scm.ActualizarCitaMedica(ctx, cita_medica.UpdateCitaMedicaRequest{
-					IdCitaMedica:       citas[i].IdCitaMedica,
-					IdMascota:          citas[i].IdMascota,
-					Motivo:             citas[i].Motivo,
-					Fecha:              citas[i].Fecha,
-					EstadoNotificacion: "SI",
-				})
+				logger.Errorf("failed to process the mensaje from %s: %s", mensaje.Remitente, err)
 			}
+		})
+	}
+}
+
+// programarTareas schedules the periodic tareas: the recordatorios of the citas médicas, the alertas and
+// the delivery of the queued notificaciones.
+func programarTareas(cfg *config.Config, db *dbcontext.DB, sn notificaciones.Service, sr recordatorio_cita.Service, logger log.Logger) error {
+	cron := crontab.New()
+	pro := productos.NewService(productos.NewRepository(db, logger), logger)
+
+	err := cron.AddJob(cfg.RecordatoriosCron, func() {
+		if _, err := sr.Programar(context.Background()); err != nil {
+			logger.Errorf("failed to queue the recordatorios of the citas médicas: %s", err)
 		}
 	})
-
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return err
 	}
 
+	//Alerta diaria para comprobar que los canales funcionan
 	err = cron.AddJob("00 08 * * *", func() {
-		wac, err = WAConnect()
-		if err != nil {
-			fmt.Println(err)
-		}
-
-		_, err = wac.SendMessage(types.JID{
-			User:   "593960270781",
-			Server: types.DefaultUserServer,
-		}, "", &waProto.Message{
-			Conversation: proto.String("Whatsapp funcionando"),
-		})
-		if err != nil {
-			fmt.Println(err)
+		if _, err := sn.Alertar(context.Background(), notificaciones.AlertaEstadoCanal, nil); err != nil {
+			logger.Errorf("failed to queue the alerta %s: %s", notificaciones.AlertaEstadoCanal, err)
 		}
 	})
-
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return err
 	}
 
 	err = cron.AddJob("00 09 * * 1,4", func() {
-		wac, err = WAConnect()
+		ctx := context.Background()
+		productos, err := pro.GetProductosPocoStock(ctx)
 		if err != nil {
-			fmt.Println(err)
+			logger.Errorf("failed to read the productos with poco stock: %s", err)
+			return
 		}
-		ctx := context.Background()
-		//Buscar Citas Sin notificar
-		pro := productos.NewService(productos.NewRepository(db, logger), logger)
-		productos, err1 := pro.GetProductosPocoStock(ctx)
-
-		if err1 != nil {
+		if len(productos) == 0 {
 			return
 		}
-
-		if len(productos) > 0 {
-			mensaje := "*Alerta de poco stock*\n"
-
-			for i := 0; i < len(productos); i++ {
-				mensaje = mensaje + "Producto: *" + productos[i].Producto + "*, Stock: *" + strconv.Itoa(productos[i].Stock) + "*\n\n"
-			}
-			mensaje = mensaje + "."
-			_, err = wac.SendMessage(types.JID{
-				User:   "593969708327",
-				Server: types.DefaultUserServer,
-			}, "", &waProto.Message{
-				Conversation: proto.String(mensaje),
-			})
+		if _, err := sn.Alertar(ctx, notificaciones.AlertaPocoStock, productos); err != nil {
+			logger.Errorf("failed to queue the alerta %s: %s", notificaciones.AlertaPocoStock, err)
 		}
 	})
-
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return err
 	}
 
-	return router
-}
-
-func Format(t time.Time) string {
-	//days[t.Weekday()][:3], t.Day(), months[t.Month()-1][:3],
-	return fmt.Sprintf("%s %02d de %s a las %02d:%02d",
-		days[t.Weekday()], t.Day(), months[t.Month()-1], t.Hour(), t.Minute(),
-	)
+	//Un envio lento no debe empezar otro en paralelo en el siguiente minuto
+	var enviando int32
+	return cron.AddJob("* * * * *", func() {
+		if !atomic.CompareAndSwapInt32(&enviando, 0, 1) {
+			return
+		}
+		defer atomic.StoreInt32(&enviando, 0)
+		if _, err := sn.EnviarPendientes(context.Background()); err != nil {
+			logger.Errorf("failed to send the notificaciones: %s", err)
+		}
+	})
 }
 
-var days = [...]string{
-	"Domingo", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado"}
-
-var months = [...]string{
-	"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio",
-	"Julio", "Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre",
+// emisorSRI returns the taxpayer data printed on the comprobantes electrónicos.
+func emisorSRI(cfg *config.Config) sri.Emisor {
+	return sri.Emisor{
+		Ambiente:                 cfg.SRIAmbiente,
+		Ruc:                      cfg.SRIRuc,
+		RazonSocial:              cfg.SRIRazonSocial,
+		NombreComercial:          cfg.SRINombreComercial,
+		DireccionMatriz:          cfg.SRIDireccionMatriz,
+		DireccionEstablecimiento: cfg.SRIDireccionEstablecimiento,
+		Establecimiento:          cfg.SRIEstablecimiento,
+		PuntoEmision:             cfg.SRIPuntoEmision,
+		ObligadoContabilidad:     cfg.SRIObligadoContabilidad,
+	}
 }
 
-func WAConnect() (*whatsmeow.Client, error) {
-	container, err := sqlstore.New("sqlite3", "file:wapp.db?_foreign_keys=on", waLog.Noop)
+// firmadorSRI loads the configured .p12 certificate. It returns nil when there is no usable certificate,
+// so the server still starts but comprobantes electrónicos can not be generated.
+func firmadorSRI(cfg *config.Config, logger log.Logger) sri.Firmador {
+	if cfg.SRICertificado == "" {
+		logger.Info("no SRI certificate configured, comprobantes electrónicos are disabled")
+		return nil
+	}
+	p12, err := ioutil.ReadFile(cfg.SRICertificado)
 	if err != nil {
-		return nil, err
+		logger.Errorf("failed to read the SRI certificate: %s", err)
+		return nil
 	}
-	deviceStore, err := container.GetFirstDevice()
+	firmador, err := sri.NuevoFirmador(p12, cfg.SRIClaveCertificado)
 	if err != nil {
-		panic(err)
+		logger.Errorf("failed to load the SRI certificate: %s", err)
+		return nil
 	}
-	client := whatsmeow.NewClient(deviceStore, waLog.Noop)
-	if client.Store.ID == nil {
-		// No ID stored, new login
-		qrChan, _ := client.GetQRChannel(context.Background())
-		err = client.Connect()
-		if err != nil {
-			return nil, err
-		}
-		for evt := range qrChan {
-			if evt.Event == "code" {
-				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
-			} else {
-				fmt.Println("Login event:", evt.Event)
-			}
-		}
-	} else {
-		err := client.Connect()
-		if err != nil {
-			return nil, err
-		}
+	return firmador
+}
+
+// clienteSRI returns the client of the SRI web services of the configured environment, unless
+// other addresses are configured (e.g. a local stub).
+func clienteSRI(cfg *config.Config) sri.Cliente {
+	urlRecepcion, urlAutorizacion := sri.URLRecepcionPruebas, sri.URLAutorizacionPruebas
+	if cfg.SRIAmbiente == sri.AmbienteProduccion {
+		urlRecepcion, urlAutorizacion = sri.URLRecepcionProduccion, sri.URLAutorizacionProduccion
+	}
+	if cfg.SRIURLRecepcion != "" {
+		urlRecepcion = cfg.SRIURLRecepcion
+	}
+	if cfg.SRIURLAutorizacion != "" {
+		urlAutorizacion = cfg.SRIURLAutorizacion
 	}
-	return client, nil
+	return sri.NuevoCliente(urlRecepcion, urlAutorizacion, &http.Client{Timeout: 30 * time.Second})
 }
 
 // logDBQuery returns a logging function that can be used to log SQL queries.
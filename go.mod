@@ -12,20 +12,20 @@ require (
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/google/uuid v1.1.1
 	github.com/lib/pq v1.2.0
-	github.com/mattn/go-sqlite3 v1.14.13 // indirect
-	github.com/mdp/qrterminal v1.0.1 // indirect
+	github.com/mattn/go-sqlite3 v1.14.13
+	github.com/mdp/qrterminal v1.0.1
 	github.com/mdp/qrterminal/v3 v3.0.0 // indirect
-	github.com/mileusna/crontab v1.2.0 // indirect
+	github.com/mileusna/crontab v1.2.0
 	github.com/nguyenthenguyen/docx v0.0.0-20211025112708-b6075f50a612
 	github.com/qiangxue/go-env v1.0.0
 	github.com/stretchr/testify v1.7.1
-	github.com/xuri/excelize/v2 v2.5.0 // indirect
-	go.mau.fi/whatsmeow v0.0.0-20220601182603-a8d86cf1812c // indirect
+	github.com/xuri/excelize/v2 v2.5.0
+	go.mau.fi/whatsmeow v0.0.0-20220601182603-a8d86cf1812c
 	go.uber.org/atomic v1.5.1 // indirect
 	go.uber.org/multierr v1.4.0 // indirect
 	go.uber.org/zap v1.13.0
 	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
 	golang.org/x/lint v0.0.0-20200130185559-910be7a94367 // indirect
-	google.golang.org/protobuf v1.28.0 // indirect
+	google.golang.org/protobuf v1.28.0
 	gopkg.in/yaml.v2 v2.2.2
 )
@@ -2,6 +2,7 @@ package album
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
@@ -44,12 +45,12 @@ func (r repository) Get(ctx context.Context, id string) (entity.Album, error) {
 // Create saves a new album record in the database.
 // It returns the ID of the newly inserted album record.
 func (r repository) Create(ctx context.Context, album entity.Album) error {
-	return r.db.With(ctx).Model(&album).Insert()
+	return auditoria.Insertar(ctx, r.db, &album)
 }
 
 // Update saves the changes to an album in the database.
 func (r repository) Update(ctx context.Context, album entity.Album) error {
-	return r.db.With(ctx).Model(&album).Update()
+	return auditoria.Actualizar(ctx, r.db, &album)
 }
 
 // Delete deletes an album with the specified ID from the database.
@@ -0,0 +1,32 @@
+package auditoria
+
+import (
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/auditoria", res.getAuditorias)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+// getAuditorias filters the auditoria by entidad, id_registro, id_usuario, accion and the dates
+// fecha_desde and fecha_hasta as AAAA-MM-DD, both included.
+func (r resource) getAuditorias(c *routing.Context) error {
+	pages, err := r.service.GetAuditorias(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
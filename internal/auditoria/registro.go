@@ -0,0 +1,135 @@
+package auditoria
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Acciones recorded in the auditoria.
+const (
+	AccionInsertar   = "INSERT"
+	AccionActualizar = "UPDATE"
+)
+
+// camposOcultos are the JSON fields never copied to the auditoria.
+var camposOcultos = []string{"clave", "token_hash"}
+
+// Insertar inserts the model, a pointer to an entity, and records its values in the auditoria
+// within the same transaction.
+func Insertar(ctx context.Context, db *dbcontext.DB, model interface{}) error {
+	if err := db.With(ctx).Model(model).Insert(); err != nil {
+		return err
+	}
+	return registrar(ctx, db, AccionInsertar, model, nil)
+}
+
+// Actualizar updates the attrs of the model, every one when none is given, and records its values
+// before and after the update in the auditoria within the same transaction.
+func Actualizar(ctx context.Context, db *dbcontext.DB, model interface{}, attrs ...string) error {
+	return ActualizarCon(ctx, db, model, func() error {
+		return db.With(ctx).Model(model).Update(attrs...)
+	})
+}
+
+// ActualizarCon runs update, a query that changes the record of the model, e.g. incrementing a column,
+// and records the values of the record before and after it in the auditoria. The model only needs
+// its primary key.
+func ActualizarCon(ctx context.Context, db *dbcontext.DB, model interface{}, update func() error) error {
+	antes, err := leer(ctx, db, model)
+	if err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	//Se relee el registro porque el modelo puede traer solo los campos actualizados
+	despues, err := leer(ctx, db, model)
+	if err != nil {
+		return err
+	}
+	if despues == nil {
+		despues = model
+	}
+	return registrar(ctx, db, AccionActualizar, despues, antes)
+}
+
+// leer returns the record of the model stored in the database, nil if there is none.
+func leer(ctx context.Context, db *dbcontext.DB, model interface{}) (interface{}, error) {
+	registro := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+	err := db.With(ctx).Select().Model(idRegistro(model), registro)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return registro, err
+}
+
+func registrar(ctx context.Context, db *dbcontext.DB, accion string, model interface{}, antes interface{}) error {
+	auditoria := entity.Auditoria{
+		Fecha:      time.Now(),
+		Entidad:    dbx.GetTableName(model),
+		IdRegistro: fmt.Sprint(idRegistro(model)),
+		Accion:     accion,
+	}
+	//Sin usuario en el contexto el cambio lo hizo el sistema
+	if identity := auth.CurrentUser(ctx); identity != nil {
+		idUsuario, nombreUsuario := identity.GetIdUsuario(), identity.GetNombreUsuario()
+		auditoria.IdUsuario = &idUsuario
+		auditoria.NombreUsuario = &nombreUsuario
+	}
+	var err error
+	if antes != nil {
+		if auditoria.Antes, err = valores(antes); err != nil {
+			return err
+		}
+	}
+	if auditoria.Despues, err = valores(model); err != nil {
+		return err
+	}
+	return db.With(ctx).Model(&auditoria).Insert()
+}
+
+// valores returns the model as JSON without its camposOcultos.
+func valores(model interface{}) (*string, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	campos := map[string]interface{}{}
+	if err := json.Unmarshal(data, &campos); err != nil {
+		return nil, err
+	}
+	for _, campo := range camposOcultos {
+		delete(campos, campo)
+	}
+	if data, err = json.Marshal(campos); err != nil {
+		return nil, err
+	}
+	result := string(data)
+	return &result, nil
+}
+
+// idRegistro returns the value of the primary key of the model, the field tagged as pk or else
+// the one named ID like ozzo-dbx does.
+func idRegistro(model interface{}) interface{} {
+	value := reflect.Indirect(reflect.ValueOf(model))
+	for i := 0; i < value.NumField(); i++ {
+		tag := value.Type().Field(i).Tag.Get("db")
+		if tag == "pk" || strings.HasPrefix(tag, "pk,") {
+			return value.Field(i).Interface()
+		}
+	}
+	if id := value.FieldByName("ID"); id.IsValid() {
+		return id.Interface()
+	}
+	return nil
+}
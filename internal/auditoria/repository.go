@@ -0,0 +1,55 @@
+package auditoria
+
+import (
+	"context"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// Repository encapsulates the logic to access the auditoria from the data source.
+type Repository interface {
+	// GetAuditorias returns the records of the auditoria that match the query, the newest first.
+	GetAuditorias(ctx context.Context, query pagination.Query) ([]entity.Auditoria, *pagination.Pages, error)
+}
+
+// repository persists the auditoria in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new auditoria repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposAuditorias are the fields the auditoria can be filtered, searched and sorted by.
+var camposAuditorias = pagination.Fields{
+	Filters: map[string]string{
+		"entidad":     "entidad",
+		"id_registro": "id_registro",
+		"id_usuario":  "id_usuario",
+		"accion":      "accion",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Search: []string{"nombre_usuario", "entidad"},
+	Sort: map[string]string{
+		"id_auditoria":   "id_auditoria",
+		"fecha":          "fecha",
+		"entidad":        "entidad",
+		"nombre_usuario": "nombre_usuario",
+	},
+	DefaultSort: []string{"fecha desc", "id_auditoria desc"},
+}
+
+func (r repository) GetAuditorias(ctx context.Context, query pagination.Query) ([]entity.Auditoria, *pagination.Pages, error) {
+	var auditorias []entity.Auditoria = []entity.Auditoria{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("auditoria"), camposAuditorias, &auditorias)
+	if err != nil {
+		return nil, nil, err
+	}
+	return auditorias, pages, nil
+}
@@ -0,0 +1,51 @@
+package auditoria
+
+import (
+	"context"
+	"encoding/json"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// Service encapsulates usecase logic for the auditoria.
+type Service interface {
+	GetAuditorias(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+}
+
+// Auditoria represents a change recorded in the auditoria, with the values as JSON objects.
+type Auditoria struct {
+	entity.Auditoria
+	Antes   json.RawMessage `json:"antes"`
+	Despues json.RawMessage `json:"despues"`
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new auditoria service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+func (s service) GetAuditorias(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	auditorias, pages, err := s.repo.GetAuditorias(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Auditoria{}
+	for _, item := range auditorias {
+		auditoria := Auditoria{Auditoria: item}
+		if item.Antes != nil {
+			auditoria.Antes = json.RawMessage(*item.Antes)
+		}
+		if item.Despues != nil {
+			auditoria.Despues = json.RawMessage(*item.Despues)
+		}
+		result = append(result, auditoria)
+	}
+	pages.Items = result
+	return pages, nil
+}
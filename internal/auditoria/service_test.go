@@ -0,0 +1,56 @@
+package auditoria
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_GetAuditorias(t *testing.T) {
+	logger, _ := log.NewForTest()
+	antes, despues := `{"anulada":false}`, `{"anulada":true}`
+	repo := &mockRepository{auditorias: []entity.Auditoria{
+		{IdAuditoria: 1, Entidad: "facturas", IdRegistro: "7", Accion: AccionActualizar, Antes: &antes, Despues: &despues},
+	}}
+	s := NewService(repo, logger)
+
+	query := pagination.Query{Page: 1, PerPage: 10, Params: url.Values{"entidad": {"facturas"}, "id_registro": {"7"}}}
+	pages, err := s.GetAuditorias(context.Background(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, query, repo.query)
+	assert.Equal(t, 1, pages.TotalCount)
+	auditorias := pages.Items.([]Auditoria)
+	if assert.Len(t, auditorias, 1) {
+		assert.JSONEq(t, antes, string(auditorias[0].Antes))
+		assert.JSONEq(t, despues, string(auditorias[0].Despues))
+	}
+}
+
+func Test_valores(t *testing.T) {
+	user := entity.User{IdUsuario: 3, NombreUsuario: "aloor", Clave: "hash"}
+	assert.Equal(t, 3, idRegistro(&user))
+	assert.Equal(t, "1", idRegistro(&entity.Album{ID: "1"}))
+
+	result, err := valores(&user)
+	if assert.Nil(t, err) {
+		assert.NotContains(t, *result, `"clave"`)
+		assert.Contains(t, *result, `"nombre_usuario":"aloor"`)
+	}
+}
+
+type mockRepository struct {
+	auditorias []entity.Auditoria
+	query      pagination.Query
+}
+
+func (m *mockRepository) GetAuditorias(ctx context.Context, query pagination.Query) ([]entity.Auditoria, *pagination.Pages, error) {
+	m.query = query
+	pages := pagination.New(query.Page, query.PerPage, len(m.auditorias))
+	pages.Items = m.auditorias
+	return m.auditorias, pages, nil
+}
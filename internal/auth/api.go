@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"net/http"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
 
@@ -8,8 +9,11 @@ import (
 )
 
 // RegisterHandlers registers handlers for different HTTP requests.
-func RegisterHandlers(rg *routing.RouteGroup, service Service, logger log.Logger) {
+func RegisterHandlers(rg *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
 	rg.Post("/login", login(service, logger))
+	rg.Post("/refresh", refresh(service, logger))
+	rg.Post("/logout", authHandler, logout(service))
+	rg.Post("/cambiarClave", authHandler, cambiarClave(service, logger))
 }
 
 // login returns a handler that handles user login request.
@@ -25,12 +29,62 @@ func login(service Service, logger log.Logger) routing.Handler {
 			return errors.BadRequest("")
 		}
 
-		token, err := service.Login(c.Request.Context(), req.Username, req.Password)
+		tokens, err := service.Login(c.Request.Context(), req.Username, req.Password)
 		if err != nil {
 			return err
 		}
-		return c.Write(struct {
-			Token string `json:"token"`
-		}{token})
+		return c.Write(tokens)
+	}
+}
+
+// refresh returns a handler that exchanges a refresh token for new tokens.
+func refresh(service Service, logger log.Logger) routing.Handler {
+	return func(c *routing.Context) error {
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+
+		if err := c.Read(&req); err != nil {
+			logger.With(c.Request.Context()).Errorf("invalid request: %v", err)
+			return errors.BadRequest("")
+		}
+
+		tokens, err := service.Refresh(c.Request.Context(), req.RefreshToken)
+		if err != nil {
+			return err
+		}
+		return c.Write(tokens)
+	}
+}
+
+// logout returns a handler that revokes the sesion of the current user.
+func logout(service Service) routing.Handler {
+	return func(c *routing.Context) error {
+		if err := service.Logout(c.Request.Context()); err != nil {
+			return err
+		}
+		c.Response.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+// cambiarClave returns a handler that changes the clave of the current user.
+func cambiarClave(service Service, logger log.Logger) routing.Handler {
+	return func(c *routing.Context) error {
+		var req struct {
+			ClaveActual string `json:"clave_actual"`
+			ClaveNueva  string `json:"clave_nueva"`
+		}
+
+		if err := c.Read(&req); err != nil {
+			logger.With(c.Request.Context()).Errorf("invalid request: %v", err)
+			return errors.BadRequest("")
+		}
+
+		tokens, err := service.CambiarClave(c.Request.Context(), req.ClaveActual, req.ClaveNueva)
+		if err != nil {
+			return err
+		}
+		return c.Write(tokens)
 	}
 }
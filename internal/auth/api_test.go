@@ -11,17 +11,39 @@ import (
 
 type mockService struct{}
 
-func (m mockService) Login(ctx context.Context, username, password string) (string, error) {
+func (m mockService) Login(ctx context.Context, username, password string) (Tokens, error) {
 	if username == "test" && password == "pass" {
-		return "token-100", nil
+		return Tokens{Token: "token-100", RefreshToken: "refresh-100", ExpiresIn: 900}, nil
 	}
-	return "", errors.Unauthorized("")
+	return Tokens{}, errors.Unauthorized("")
+}
+
+func (m mockService) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	if refreshToken == "refresh-100" {
+		return Tokens{Token: "token-101", RefreshToken: "refresh-101", ExpiresIn: 900}, nil
+	}
+	return Tokens{}, errors.Unauthorized("")
+}
+
+func (m mockService) Logout(ctx context.Context) error {
+	return nil
+}
+
+func (m mockService) SesionActiva(ctx context.Context, idSesion int) (bool, error) {
+	return true, nil
+}
+
+func (m mockService) CambiarClave(ctx context.Context, claveActual string, claveNueva string) (Tokens, error) {
+	if claveActual != "pass" {
+		return Tokens{}, errors.BadRequest("")
+	}
+	return Tokens{Token: "token-102", RefreshToken: "refresh-102", ExpiresIn: 900}, nil
 }
 
 func TestAPI(t *testing.T) {
 	logger, _ := log.NewForTest()
 	router := test.MockRouter(logger)
-	RegisterHandlers(router.Group(""), mockService{}, logger)
+	RegisterHandlers(router.Group(""), mockService{}, MockAuthHandler, logger)
 
 	tests := []test.APITestCase{
 		{
@@ -31,7 +53,7 @@ func TestAPI(t *testing.T) {
 			Body:         `{"username":"test","password":"pass"}`,
 			Header:       nil,
 			WantStatus:   http.StatusOK,
-			WantResponse: `{"token":"token-100"}`,
+			WantResponse: `{"token":"token-100","refresh_token":"refresh-100","expires_in":900,"cambiar_clave":false}`,
 		},
 		{
 			Name:         "bad credential",
@@ -50,6 +72,60 @@ func TestAPI(t *testing.T) {
 			WantStatus:   http.StatusBadRequest,
 			WantResponse: "",
 		},
+		{
+			Name:         "refresh",
+			Method:       "POST",
+			URL:          "/refresh",
+			Body:         `{"refresh_token":"refresh-100"}`,
+			Header:       nil,
+			WantStatus:   http.StatusOK,
+			WantResponse: `{"token":"token-101","refresh_token":"refresh-101","expires_in":900,"cambiar_clave":false}`,
+		},
+		{
+			Name:         "bad refresh token",
+			Method:       "POST",
+			URL:          "/refresh",
+			Body:         `{"refresh_token":"refresh-99"}`,
+			Header:       nil,
+			WantStatus:   http.StatusUnauthorized,
+			WantResponse: "",
+		},
+		{
+			Name:         "logout unauthorized",
+			Method:       "POST",
+			URL:          "/logout",
+			Body:         "",
+			Header:       nil,
+			WantStatus:   http.StatusUnauthorized,
+			WantResponse: "",
+		},
+		{
+			Name:         "logout",
+			Method:       "POST",
+			URL:          "/logout",
+			Body:         "",
+			Header:       MockAuthHeader(),
+			WantStatus:   http.StatusNoContent,
+			WantResponse: "",
+		},
+		{
+			Name:         "cambiar clave",
+			Method:       "POST",
+			URL:          "/cambiarClave",
+			Body:         `{"clave_actual":"pass","clave_nueva":"Nueva123!"}`,
+			Header:       MockAuthHeader(),
+			WantStatus:   http.StatusOK,
+			WantResponse: `{"token":"token-102","refresh_token":"refresh-102","expires_in":900,"cambiar_clave":false}`,
+		},
+		{
+			Name:         "cambiar clave wrong current",
+			Method:       "POST",
+			URL:          "/cambiarClave",
+			Body:         `{"clave_actual":"wrong","clave_nueva":"Nueva123!"}`,
+			Header:       MockAuthHeader(),
+			WantStatus:   http.StatusBadRequest,
+			WantResponse: "",
+		},
 	}
 	for _, tc := range tests {
 		test.Endpoint(t, router, tc)
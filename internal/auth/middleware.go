@@ -17,6 +17,29 @@ func Handler(verificationKey string) routing.Handler {
 	return auth.JWT(verificationKey, auth.JWTOptions{TokenHandler: handleToken})
 }
 
+// ValidadorSesion checks whether the sesion of a JWT is still valid.
+type ValidadorSesion interface {
+	SesionActiva(ctx context.Context, idSesion int) (bool, error)
+}
+
+// HandlerConRevocacion returns a JWT-based authentication middleware that also rejects the tokens
+// whose sesion was revoked or whose user was deactivated.
+func HandlerConRevocacion(verificationKey string, validador ValidadorSesion) routing.Handler {
+	return auth.JWT(verificationKey, auth.JWTOptions{TokenHandler: func(c *routing.Context, token *jwt.Token) error {
+		if err := handleToken(c, token); err != nil {
+			return err
+		}
+		activa, err := validador.SesionActiva(c.Request.Context(), CurrentSesion(c.Request.Context()))
+		if err != nil {
+			return err
+		}
+		if !activa {
+			return errors.Unauthorized("La sesión fue revocada.")
+		}
+		return nil
+	}})
+}
+
 // handleToken stores the user identity in the request context so that it can be accessed elsewhere.
 func handleToken(c *routing.Context, token *jwt.Token) error {
 	ctx := WithUser(
@@ -24,14 +47,81 @@ func handleToken(c *routing.Context, token *jwt.Token) error {
 		int(token.Claims.(jwt.MapClaims)["id"].(float64)),
 		token.Claims.(jwt.MapClaims)["username"].(string),
 	)
+	//Los tokens emitidos antes de los permisos no tienen roles y no pasan ninguna autorización
+	roles := []int{}
+	if claim, ok := token.Claims.(jwt.MapClaims)["roles"].([]interface{}); ok {
+		for _, rol := range claim {
+			if idRol, ok := rol.(float64); ok {
+				roles = append(roles, int(idRol))
+			}
+		}
+	}
+	ctx = WithRoles(ctx, roles)
+	//Los tokens sin sesión no pueden revocarse y no pasan HandlerConRevocacion
+	if sid, ok := token.Claims.(jwt.MapClaims)["sid"].(float64); ok {
+		ctx = WithSesion(ctx, int(sid))
+	}
+	if cambiarClave, ok := token.Claims.(jwt.MapClaims)["cambiar_clave"].(bool); ok && cambiarClave {
+		ctx = WithCambiarClave(ctx)
+	}
 	c.Request = c.Request.WithContext(ctx)
 	return nil
 }
 
+// PermisoTotal is the codigo of the permiso that grants every other permiso.
+const PermisoTotal = "*"
+
+// Acciones that a permiso grants over a modulo. Escribir includes leer.
+const (
+	AccionLeer     = "leer"
+	AccionEscribir = "escribir"
+)
+
+// Autorizador checks the permisos granted to roles.
+type Autorizador interface {
+	// TienePermiso reports whether any of the roles is granted any of the codigos.
+	TienePermiso(ctx context.Context, roles []int, codigos []string) (bool, error)
+}
+
+// Authorize returns a middleware that authenticates the request with authHandler and then requires
+// the roles of the user to be granted the modulo: reading requests need the leer or escribir permiso
+// of the modulo, any other request needs the escribir permiso. Users that must change the clave are
+// not authorized.
+func Authorize(authHandler routing.Handler, autorizador Autorizador, modulo string) routing.Handler {
+	return func(c *routing.Context) error {
+		if err := authHandler(c); err != nil {
+			return err
+		}
+		if CambiarClavePendiente(c.Request.Context()) {
+			return errors.Forbidden("Debe cambiar su clave antes de continuar.")
+		}
+		codigos := []string{CodigoPermiso(modulo, AccionEscribir), PermisoTotal}
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			codigos = append(codigos, CodigoPermiso(modulo, AccionLeer))
+		}
+		permitido, err := autorizador.TienePermiso(c.Request.Context(), CurrentRoles(c.Request.Context()), codigos)
+		if err != nil {
+			return err
+		}
+		if !permitido {
+			return errors.Forbidden("")
+		}
+		return nil
+	}
+}
+
+// CodigoPermiso returns the codigo of the permiso for the accion over the modulo, e.g. "facturas.escribir".
+func CodigoPermiso(modulo string, accion string) string {
+	return modulo + "." + accion
+}
+
 type contextKey int
 
 const (
 	userKey contextKey = iota
+	rolesKey
+	sesionKey
+	cambiarClaveKey
 )
 
 // WithUser returns a context that contains the user identity from the given JWT.
@@ -39,6 +129,43 @@ func WithUser(ctx context.Context, id int, name string) context.Context {
 	return context.WithValue(ctx, userKey, entity.User{IdUsuario: id, NombreUsuario: name})
 }
 
+// WithRoles returns a context that contains the roles of the user from the given JWT.
+func WithRoles(ctx context.Context, roles []int) context.Context {
+	return context.WithValue(ctx, rolesKey, roles)
+}
+
+// CurrentRoles returns the roles of the user from the given context.
+func CurrentRoles(ctx context.Context) []int {
+	if roles, ok := ctx.Value(rolesKey).([]int); ok {
+		return roles
+	}
+	return nil
+}
+
+// WithSesion returns a context that contains the sesion of the given JWT.
+func WithSesion(ctx context.Context, idSesion int) context.Context {
+	return context.WithValue(ctx, sesionKey, idSesion)
+}
+
+// CurrentSesion returns the sesion from the given context, 0 if there is none.
+func CurrentSesion(ctx context.Context) int {
+	if idSesion, ok := ctx.Value(sesionKey).(int); ok {
+		return idSesion
+	}
+	return 0
+}
+
+// WithCambiarClave returns a context that marks the user must change the clave.
+func WithCambiarClave(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cambiarClaveKey, true)
+}
+
+// CambiarClavePendiente returns whether the user from the given context must change the clave.
+func CambiarClavePendiente(ctx context.Context) bool {
+	pendiente, _ := ctx.Value(cambiarClaveKey).(bool)
+	return pendiente
+}
+
 // CurrentUser returns the user identity from the given context.
 // Nil is returned if no user identity is found in the context.
 func CurrentUser(ctx context.Context) Identity {
@@ -48,6 +175,32 @@ func CurrentUser(ctx context.Context) Identity {
 	return nil
 }
 
+// UsuarioActuante returns the id of the current user, who records the action. idUsuario is the user sent
+// in the request: 0 takes the current user and any other user is rejected.
+func UsuarioActuante(ctx context.Context, idUsuario int) (int, error) {
+	identity := CurrentUser(ctx)
+	if identity == nil {
+		return 0, errors.Unauthorized("")
+	}
+	if idUsuario != 0 && idUsuario != identity.GetIdUsuario() {
+		return 0, errors.Forbidden("No puede registrar acciones a nombre de otro usuario.")
+	}
+	return identity.GetIdUsuario(), nil
+}
+
+// UsuarioRegistro returns the user of a record being saved, idGuardado the one stored, 0 for a new record.
+// Updates keep the stored user when the request sends 0 or the same user, or take the record for the
+// current user; any other user is rejected like UsuarioActuante does.
+func UsuarioRegistro(ctx context.Context, idUsuario int, idGuardado int) (int, error) {
+	if idGuardado != 0 && (idUsuario == 0 || idUsuario == idGuardado) {
+		if CurrentUser(ctx) == nil {
+			return 0, errors.Unauthorized("")
+		}
+		return idGuardado, nil
+	}
+	return UsuarioActuante(ctx, idUsuario)
+}
+
 // MockAuthHandler creates a mock authentication middleware for testing purpose.
 // If the request contains an Authorization header whose value is "TEST", then
 // it considers the user is authenticated as "Tester" whose ID is "100".
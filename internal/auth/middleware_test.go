@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"veterinaria-server/internal/errors"
 	"veterinaria-server/internal/test"
 
 	"github.com/dgrijalva/jwt-go"
+	routing "github.com/go-ozzo/ozzo-routing/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,7 +18,7 @@ func TestCurrentUser(t *testing.T) {
 	ctx = WithUser(ctx, 100, "test")
 	identity := CurrentUser(ctx)
 	if assert.NotNil(t, identity) {
-		assert.Equal(t, "100", identity.GetIdUsuario())
+		assert.Equal(t, 100, identity.GetIdUsuario())
 		assert.Equal(t, "test", identity.GetNombreUsuario())
 	}
 }
@@ -32,14 +34,14 @@ func Test_handleToken(t *testing.T) {
 
 	err := handleToken(ctx, &jwt.Token{
 		Claims: jwt.MapClaims{
-			"id":   "100",
-			"name": "test",
+			"id":       float64(100),
+			"username": "test",
 		},
 	})
 	assert.Nil(t, err)
 	identity := CurrentUser(ctx.Request.Context())
 	if assert.NotNil(t, identity) {
-		assert.Equal(t, "100", identity.GetIdUsuario())
+		assert.Equal(t, 100, identity.GetIdUsuario())
 		assert.Equal(t, "test", identity.GetNombreUsuario())
 	}
 }
@@ -53,3 +55,84 @@ func TestMocks(t *testing.T) {
 	assert.Nil(t, MockAuthHandler(ctx))
 	assert.NotNil(t, CurrentUser(ctx.Request.Context()))
 }
+
+type mockAutorizador map[int][]string
+
+func (m mockAutorizador) TienePermiso(ctx context.Context, roles []int, codigos []string) (bool, error) {
+	for _, rol := range roles {
+		for _, permiso := range m[rol] {
+			for _, codigo := range codigos {
+				if permiso == codigo {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	autorizador := mockAutorizador{1: {"facturacion.leer"}, 2: {PermisoTotal}}
+	withRoles := func(roles ...int) routing.Handler {
+		return func(c *routing.Context) error {
+			c.Request = c.Request.WithContext(WithRoles(c.Request.Context(), roles))
+			return nil
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/facturas", nil)
+	ctx, _ := test.MockRoutingContext(req)
+	assert.Nil(t, Authorize(withRoles(1), autorizador, "facturacion")(ctx))
+
+	req, _ = http.NewRequest("POST", "http://example.com/facturas", nil)
+	ctx, _ = test.MockRoutingContext(req)
+	assert.Equal(t, errors.Forbidden(""), Authorize(withRoles(1), autorizador, "facturacion")(ctx))
+	ctx, _ = test.MockRoutingContext(req)
+	assert.Nil(t, Authorize(withRoles(1, 2), autorizador, "facturacion")(ctx))
+
+	req, _ = http.NewRequest("GET", "http://example.com/pagos", nil)
+	ctx, _ = test.MockRoutingContext(req)
+	assert.Equal(t, errors.Forbidden(""), Authorize(withRoles(1), autorizador, "pagos")(ctx))
+	ctx, _ = test.MockRoutingContext(req)
+	assert.NotNil(t, Authorize(MockAuthHandler, autorizador, "pagos")(ctx))
+
+	// clave por cambiar
+	req, _ = http.NewRequest("GET", "http://example.com/facturas", nil)
+	ctx, _ = test.MockRoutingContext(req)
+	ctx.Request = ctx.Request.WithContext(WithCambiarClave(ctx.Request.Context()))
+	assert.Equal(t, errors.Forbidden("Debe cambiar su clave antes de continuar."), Authorize(withRoles(2), autorizador, "facturacion")(ctx))
+}
+
+func TestUsuarioActuante(t *testing.T) {
+	_, err := UsuarioActuante(context.Background(), 0)
+	assert.Equal(t, errors.Unauthorized(""), err)
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	req.Header = MockAuthHeader()
+	ctx, _ := test.MockRoutingContext(req)
+	assert.Nil(t, MockAuthHandler(ctx))
+
+	idUsuario, err := UsuarioActuante(ctx.Request.Context(), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, idUsuario)
+	idUsuario, err = UsuarioActuante(ctx.Request.Context(), 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, idUsuario)
+	_, err = UsuarioActuante(ctx.Request.Context(), 7)
+	assert.NotNil(t, err)
+
+	// registros guardados por otro usuario
+	idUsuario, err = UsuarioRegistro(ctx.Request.Context(), 0, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, idUsuario)
+	idUsuario, err = UsuarioRegistro(ctx.Request.Context(), 7, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, idUsuario)
+	idUsuario, err = UsuarioRegistro(ctx.Request.Context(), 100, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, idUsuario)
+	_, err = UsuarioRegistro(ctx.Request.Context(), 8, 7)
+	assert.NotNil(t, err)
+	_, err = UsuarioRegistro(context.Background(), 7, 7)
+	assert.Equal(t, errors.Unauthorized(""), err)
+}
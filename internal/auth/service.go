@@ -2,9 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math/big"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/dbcontext"
@@ -18,8 +26,118 @@ import (
 // Service encapsulates the authentication logic.
 type Service interface {
 	// authenticate authenticates a user using username and password.
-	// It returns a JWT token if authentication succeeds. Otherwise, an error is returned.
-	Login(ctx context.Context, username, password string) (string, error)
+	// It returns a JWT token and a refresh token if authentication succeeds. Otherwise, an error is returned.
+	Login(ctx context.Context, username, password string) (Tokens, error)
+	// Refresh exchanges a refresh token for new tokens. A refresh token can be used only once:
+	// using it again revokes the whole sesion, since it means the token was stolen.
+	Refresh(ctx context.Context, refreshToken string) (Tokens, error)
+	// Logout revokes the sesion of the current user.
+	Logout(ctx context.Context) error
+	// SesionActiva reports whether the sesion is not revoked and its user is still active.
+	SesionActiva(ctx context.Context, idSesion int) (bool, error)
+	// CambiarClave changes the clave of the current user, who must know the current one. Every sesion
+	// of the user is revoked, so it returns the tokens of a new sesion.
+	CambiarClave(ctx context.Context, claveActual string, claveNueva string) (Tokens, error)
+}
+
+// Tokens are the credentials issued to a sesion.
+type Tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the lifetime of the token in seconds.
+	ExpiresIn int `json:"expires_in"`
+	// CambiarClave tells the user must change the clave, no other request is authorized until then.
+	CambiarClave bool `json:"cambiar_clave"`
+}
+
+// Seguridad are the rules for claves and failed logins.
+type Seguridad struct {
+	Politica PoliticaClave
+	// MaxIntentos failed logins in a row lock the user for MinutosBloqueo. There is no lockout when 0.
+	MaxIntentos    int
+	MinutosBloqueo int
+}
+
+// PoliticaClave are the rules a new clave must follow.
+type PoliticaClave struct {
+	LongitudMinima int
+	Mayuscula      bool
+	Numero         bool
+	Simbolo        bool
+}
+
+// Validar checks the clave follows the politica.
+func (p PoliticaClave) Validar(clave string) error {
+	var mayuscula, numero, simbolo bool
+	for _, c := range clave {
+		switch {
+		case unicode.IsUpper(c):
+			mayuscula = true
+		case unicode.IsDigit(c):
+			numero = true
+		case !unicode.IsLetter(c):
+			simbolo = true
+		}
+	}
+	requisitos := []string{}
+	if utf8.RuneCountInString(clave) < p.LongitudMinima {
+		requisitos = append(requisitos, fmt.Sprintf("al menos %d caracteres", p.LongitudMinima))
+	}
+	if p.Mayuscula && !mayuscula {
+		requisitos = append(requisitos, "una mayúscula")
+	}
+	if p.Numero && !numero {
+		requisitos = append(requisitos, "un número")
+	}
+	if p.Simbolo && !simbolo {
+		requisitos = append(requisitos, "un símbolo")
+	}
+	if len(requisitos) > 0 {
+		return errors.BadRequest("La clave debe tener " + strings.Join(requisitos, ", ") + ".")
+	}
+	return nil
+}
+
+// ClaveTemporal generates a random clave that follows the politica.
+func (p PoliticaClave) ClaveTemporal() (string, error) {
+	const (
+		minusculas = "abcdefghijkmnpqrstuvwxyz"
+		mayusculas = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		numeros    = "23456789"
+		simbolos   = "!#$%&*+-=?@"
+	)
+	longitud := p.LongitudMinima
+	if longitud < 12 {
+		longitud = 12
+	}
+	//Un caracter de cada tipo y el resto de cualquiera
+	grupos := []string{minusculas, mayusculas, numeros, simbolos}
+	clave := make([]byte, longitud)
+	for i := range clave {
+		grupo := minusculas + mayusculas + numeros + simbolos
+		if i < len(grupos) {
+			grupo = grupos[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(grupo))))
+		if err != nil {
+			return "", err
+		}
+		clave[i] = grupo[n.Int64()]
+	}
+	for i := len(clave) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		clave[i], clave[j.Int64()] = clave[j.Int64()], clave[i]
+	}
+	return string(clave), nil
+}
+
+// HashClave returns the bcrypt hash stored for a clave.
+func HashClave(clave string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(clave), bcrypt.DefaultCost)
+	return string(hash), err
 }
 
 // Identity represents an authenticated user identity.
@@ -31,6 +149,10 @@ type Identity interface {
 	// IsEstado returns the user status
 	IsEstado() sql.NullBool
 	GetNombres() string
+	// GetRoles returns the ids of the roles of the user.
+	GetRoles() []int
+	// IsCambiarClave returns whether the user must change the clave.
+	IsCambiarClave() bool
 }
 
 type service struct {
@@ -38,20 +160,186 @@ type service struct {
 	signingKey      string
 	tokenExpiration int
 	logger          log.Logger
+	// accessExpiration is the lifetime in minutes of the JWT, tokenExpiration the one in hours of the refresh tokens.
+	accessExpiration int
+	seguridad        Seguridad
 }
 
 // NewService creates a new authentication service.
-func NewService(db *dbcontext.DB, signingKey string, tokenExpiration int, logger log.Logger) Service {
-	return service{db, signingKey, tokenExpiration, logger}
+func NewService(db *dbcontext.DB, signingKey string, tokenExpiration int, accessExpiration int, seguridad Seguridad, logger log.Logger) Service {
+	return service{db, signingKey, tokenExpiration, logger, accessExpiration, seguridad}
 }
 
 // Login authenticates a user and generates a JWT token if authentication succeeds.
 // Otherwise, an error is returned.
-func (s service) Login(ctx context.Context, username, password string) (string, error) {
-	if identity := s.authenticate(ctx, username, password); identity != nil {
-		return s.generateJWT(identity)
+func (s service) Login(ctx context.Context, username, password string) (Tokens, error) {
+	var bloqueadoHasta *time.Time
+	err := s.db.With(ctx).Select("bloqueado_hasta").From("usuarios").Where(dbx.HashExp{"nombre_usuario": username}).Row(&bloqueadoHasta)
+	if err != nil && err != sql.ErrNoRows {
+		return Tokens{}, err
+	}
+	if bloqueadoHasta != nil && time.Now().Before(*bloqueadoHasta) {
+		return Tokens{}, errors.Unauthorized("La cuenta está bloqueada temporalmente por intentos fallidos.")
+	}
+	identity := s.authenticate(ctx, username, password)
+	if identity == nil {
+		return Tokens{}, errors.Unauthorized("")
+	}
+	return s.iniciarSesion(ctx, identity)
+}
+
+// iniciarSesion creates a new sesion of the user with its tokens.
+func (s service) iniciarSesion(ctx context.Context, identity Identity) (Tokens, error) {
+	sesion := entity.SesionUsuario{IdUsuario: identity.GetIdUsuario(), FechaCreacion: time.Now()}
+	if err := s.db.With(ctx).Model(&sesion).Insert(); err != nil {
+		return Tokens{}, err
+	}
+	return s.emitirTokens(ctx, identity, sesion.IdSesionUsuario)
+}
+
+func (s service) CambiarClave(ctx context.Context, claveActual string, claveNueva string) (Tokens, error) {
+	identity := CurrentUser(ctx)
+	if identity == nil {
+		return Tokens{}, errors.Unauthorized("")
+	}
+	user := entity.User{}
+	if err := s.db.With(ctx).Select().Where(dbx.HashExp{"id_usuario": identity.GetIdUsuario(), "estado": true}).One(&user); err != nil {
+		if err == sql.ErrNoRows {
+			return Tokens{}, errors.Unauthorized("")
+		}
+		return Tokens{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Clave), []byte(claveActual)); err != nil {
+		return Tokens{}, errors.BadRequest("La clave actual es incorrecta.")
+	}
+	if claveNueva == claveActual {
+		return Tokens{}, errors.BadRequest("La nueva clave debe ser distinta de la actual.")
+	}
+	if err := s.seguridad.Politica.Validar(claveNueva); err != nil {
+		return Tokens{}, err
+	}
+	hash, err := HashClave(claveNueva)
+	if err != nil {
+		return Tokens{}, err
+	}
+	user.Clave = hash
+	user.CambiarClave = false
+	if err := s.db.With(ctx).Model(&user).Update("Clave", "CambiarClave"); err != nil {
+		return Tokens{}, err
+	}
+	if err := RevocarSesionesUsuario(ctx, s.db, user.IdUsuario, "Cambio de clave"); err != nil {
+		return Tokens{}, err
 	}
-	return "", errors.Unauthorized("")
+	if err := s.db.With(ctx).Select("id_rol").From("usuario_rol").Where(dbx.HashExp{"id_usuario": user.IdUsuario}).Column(&user.Roles); err != nil {
+		return Tokens{}, err
+	}
+	return s.iniciarSesion(ctx, user)
+}
+
+func (s service) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	var token entity.RefreshToken
+	err := s.db.With(ctx).
+		NewQuery("SELECT * FROM refresh_tokens WHERE token_hash = {:hash} FOR UPDATE").
+		Bind(dbx.Params{"hash": hashToken(refreshToken)}).
+		One(&token)
+	if err == sql.ErrNoRows {
+		return Tokens{}, errors.Unauthorized("")
+	}
+	if err != nil {
+		return Tokens{}, err
+	}
+	var sesion entity.SesionUsuario
+	if err := s.db.With(ctx).Select().Model(token.IdSesionUsuario, &sesion); err != nil {
+		return Tokens{}, err
+	}
+	if sesion.FechaRevocacion != nil {
+		return Tokens{}, errors.Unauthorized("")
+	}
+	if token.Usado {
+		//La revocación se guarda fuera de la transacción, que se deshace al responder con error
+		s.logger.With(ctx, "user", sesion.IdUsuario).Infof("refresh token reused, revoking sesion %d", sesion.IdSesionUsuario)
+		if err := revocarSesiones(ctx, s.db.DB(), dbx.HashExp{"id_sesion_usuario": sesion.IdSesionUsuario}, "Reutilización de refresh token"); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, errors.Unauthorized("")
+	}
+	if time.Now().After(token.FechaExpiracion) {
+		return Tokens{}, errors.Unauthorized("")
+	}
+
+	user := entity.User{}
+	if err := s.db.With(ctx).Select().Where(dbx.HashExp{"id_usuario": sesion.IdUsuario, "estado": true}).One(&user); err != nil {
+		if err == sql.ErrNoRows {
+			return Tokens{}, errors.Unauthorized("")
+		}
+		return Tokens{}, err
+	}
+	if err := s.db.With(ctx).Select("id_rol").From("usuario_rol").Where(dbx.HashExp{"id_usuario": user.IdUsuario}).Column(&user.Roles); err != nil {
+		return Tokens{}, err
+	}
+	token.Usado = true
+	if err := s.db.With(ctx).Model(&token).Update("Usado"); err != nil {
+		return Tokens{}, err
+	}
+	return s.emitirTokens(ctx, user, sesion.IdSesionUsuario)
+}
+
+func (s service) Logout(ctx context.Context) error {
+	idSesion := CurrentSesion(ctx)
+	if idSesion == 0 {
+		return errors.Unauthorized("")
+	}
+	return revocarSesiones(ctx, s.db.With(ctx), dbx.HashExp{"id_sesion_usuario": idSesion}, "Cierre de sesión")
+}
+
+func (s service) SesionActiva(ctx context.Context, idSesion int) (bool, error) {
+	var sesiones int
+	err := s.db.With(ctx).
+		Select("count(*)").
+		From("sesiones_usuario as s").
+		InnerJoin("usuarios as u", dbx.NewExp("u.id_usuario = s.id_usuario")).
+		Where(dbx.HashExp{"s.id_sesion_usuario": idSesion, "s.fecha_revocacion": nil, "u.estado": true}).
+		Row(&sesiones)
+	return sesiones > 0, err
+}
+
+// RevocarSesionesUsuario revokes every sesion of the user, e.g. when the user is deactivated or its clave changes.
+func RevocarSesionesUsuario(ctx context.Context, db *dbcontext.DB, idUsuario int, motivo string) error {
+	return revocarSesiones(ctx, db.With(ctx), dbx.HashExp{"id_usuario": idUsuario}, motivo)
+}
+
+func revocarSesiones(ctx context.Context, builder dbx.Builder, where dbx.HashExp, motivo string) error {
+	where["fecha_revocacion"] = nil
+	_, err := builder.Update("sesiones_usuario", dbx.Params{"fecha_revocacion": time.Now(), "motivo_revocacion": motivo}, where).Execute()
+	return err
+}
+
+// emitirTokens generates the JWT of the sesion and a new refresh token.
+func (s service) emitirTokens(ctx context.Context, identity Identity, idSesion int) (Tokens, error) {
+	valor := make([]byte, 32)
+	if _, err := rand.Read(valor); err != nil {
+		return Tokens{}, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(valor)
+	if err := s.db.With(ctx).Model(&entity.RefreshToken{
+		IdSesionUsuario: idSesion,
+		TokenHash:       hashToken(refreshToken),
+		FechaCreacion:   time.Now(),
+		FechaExpiracion: time.Now().Add(time.Duration(s.tokenExpiration) * time.Hour),
+	}).Insert(); err != nil {
+		return Tokens{}, err
+	}
+	token, err := s.generateJWT(identity, idSesion)
+	if err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{Token: token, RefreshToken: refreshToken, ExpiresIn: s.accessExpiration * 60, CambiarClave: identity.IsCambiarClave()}, nil
+}
+
+// hashToken returns the hex SHA-256 of a refresh token, which is what gets stored.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
 // authenticate authenticates a user using username and password.
@@ -69,19 +357,51 @@ func (s service) authenticate(ctx context.Context, username, password string) Id
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Clave), []byte(password)); err != nil {
 		fmt.Println(err)
 		logger.Infof("authentication failed")
+		s.registrarIntentoFallido(ctx, user)
+		return nil
+	}
+	if user.IntentosFallidos > 0 || user.BloqueadoHasta != nil {
+		user.IntentosFallidos = 0
+		user.BloqueadoHasta = nil
+		if err := s.db.With(ctx).Model(&user).Update("IntentosFallidos", "BloqueadoHasta"); err != nil {
+			logger.Errorf("failed to reset the failed logins: %s", err)
+			return nil
+		}
+	}
+	var roles []int
+	if err := s.db.With(ctx).Select("id_rol").From("usuario_rol").Where(dbx.HashExp{"id_usuario": user.IdUsuario}).Column(&roles); err != nil {
+		logger.Errorf("failed to read the roles: %s", err)
 		return nil
 	}
 	logger.Infof("authentication successful")
-	u := entity.User{IdUsuario: user.IdUsuario, NombreUsuario: user.NombreUsuario, Estado: user.Estado, Nombre: user.Nombre, Apellido: user.Apellido}
+	u := entity.User{IdUsuario: user.IdUsuario, NombreUsuario: user.NombreUsuario, Estado: user.Estado, Nombre: user.Nombre, Apellido: user.Apellido, CambiarClave: user.CambiarClave, Roles: roles}
 	return u
 }
 
-// generateJWT generates a JWT that encodes an identity.
-func (s service) generateJWT(identity Identity) (string, error) {
+// registrarIntentoFallido counts a failed login of the user and locks the user when it reaches the limit.
+// It is saved outside the transaction of the request, which is rolled back when the login fails.
+func (s service) registrarIntentoFallido(ctx context.Context, user entity.User) {
+	params := dbx.Params{"intentos_fallidos": dbx.NewExp("intentos_fallidos + 1")}
+	if s.seguridad.MaxIntentos > 0 && user.IntentosFallidos+1 >= s.seguridad.MaxIntentos {
+		params = dbx.Params{"intentos_fallidos": 0, "bloqueado_hasta": time.Now().Add(time.Duration(s.seguridad.MinutosBloqueo) * time.Minute)}
+		s.logger.With(ctx, "user", user.NombreUsuario).Infof("user locked after %d failed logins", s.seguridad.MaxIntentos)
+	}
+	_, err := s.db.DB().WithContext(ctx).Update("usuarios", params, dbx.HashExp{"id_usuario": user.IdUsuario}).Execute()
+	if err != nil {
+		s.logger.With(ctx).Errorf("failed to save the failed login: %s", err)
+	}
+}
+
+// generateJWT generates a JWT that encodes an identity and its sesion.
+func (s service) generateJWT(identity Identity, idSesion int) (string, error) {
 	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"id":       identity.GetIdUsuario(),
 		"username": identity.GetNombreUsuario(),
-		"exp":      time.Now().Add(time.Duration(s.tokenExpiration) * time.Hour).Unix(),
+		"sid":      idSesion,
+		"exp":      time.Now().Add(time.Duration(s.accessExpiration) * time.Minute).Unix(),
 		"nombres":  identity.GetNombres(),
+		"roles":    identity.GetRoles(),
+		//Con la clave por cambiar el token solo sirve para cambiarla
+		"cambiar_clave": identity.IsCambiarClave(),
 	}).SignedString([]byte(s.signingKey))
 }
@@ -12,7 +12,7 @@ import (
 
 func Test_service_Authenticate(t *testing.T) {
 	logger, _ := log.NewForTest()
-	s := NewService(nil, "test", 100, logger)
+	s := NewService(nil, "test", 100, 15, Seguridad{}, logger)
 	_, err := s.Login(context.Background(), "unknown", "bad")
 	assert.Equal(t, errors.Unauthorized(""), err)
 	token, err := s.Login(context.Background(), "demo", "pass")
@@ -22,19 +22,34 @@ func Test_service_Authenticate(t *testing.T) {
 
 func Test_service_authenticate(t *testing.T) {
 	logger, _ := log.NewForTest()
-	s := service{nil, "test", 100, logger}
+	s := service{nil, "test", 100, logger, 15, Seguridad{}}
 	assert.Nil(t, s.authenticate(context.Background(), "unknown", "bad"))
 	assert.NotNil(t, s.authenticate(context.Background(), "demo", "pass"))
 }
 
 func Test_service_GenerateJWT(t *testing.T) {
 	logger, _ := log.NewForTest()
-	s := service{nil, "test", 100, logger}
+	s := service{nil, "test", 100, logger, 15, Seguridad{}}
 	token, err := s.generateJWT(entity.User{
 		IdUsuario:     100,
 		NombreUsuario: "demo",
-	})
+	}, 1)
 	if assert.Nil(t, err) {
 		assert.NotEmpty(t, token)
 	}
 }
+
+func TestPoliticaClave(t *testing.T) {
+	politica := PoliticaClave{LongitudMinima: 8, Mayuscula: true, Numero: true, Simbolo: true}
+	assert.NotNil(t, politica.Validar("Corta1!"))
+	assert.NotNil(t, politica.Validar("sinmayuscula1!"))
+	assert.NotNil(t, politica.Validar("SinNumero!"))
+	assert.NotNil(t, politica.Validar("SinSimbolo1"))
+	assert.Nil(t, politica.Validar("Valida123!"))
+	assert.Nil(t, PoliticaClave{}.Validar("x"))
+
+	temporal, err := politica.ClaveTemporal()
+	if assert.Nil(t, err) {
+		assert.Nil(t, politica.Validar(temporal))
+	}
+}
@@ -0,0 +1,181 @@
+package caja
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+	"veterinaria-server/pkg/storage"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+// The reports are filled from the templates of plantillas and saved in documentos.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, plantillas storage.Storage, documentos storage.Storage) {
+	res := resource{service, logger, plantillas, documentos}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/caja/sesiones", res.getSesionesCaja)
+	r.Get("/caja/sesiones/<idSesionCaja>", res.getResumenCaja)
+	r.Get("/caja/sesiones/<idSesionCaja>/reporte", res.reporte)
+	r.Get("/caja/actual", res.getResumenCajaActual)
+	r.Post("/caja/abrir", res.abrirCaja)
+	r.Post("/caja/movimientos", res.registrarMovimiento)
+	r.Post("/caja/cerrar", res.cerrarCaja)
+}
+
+type resource struct {
+	service    Service
+	logger     log.Logger
+	plantillas storage.Storage
+	documentos storage.Storage
+}
+
+func (r resource) getSesionesCaja(c *routing.Context) error {
+	pages, err := r.service.GetSesionesCaja(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getResumenCaja(c *routing.Context) error {
+	idSesionCaja, _ := strconv.Atoi(c.Param("idSesionCaja"))
+	resumen, err := r.service.GetResumenCaja(c.Request.Context(), idSesionCaja)
+	if err != nil {
+		return err
+	}
+	return c.Write(resumen)
+}
+
+func (r resource) getResumenCajaActual(c *routing.Context) error {
+	resumen, err := r.service.GetResumenCajaActual(c.Request.Context())
+	if err != nil {
+		return err
+	}
+	return c.Write(resumen)
+}
+
+func (r resource) abrirCaja(c *routing.Context) error {
+	var input AbrirCajaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	sesion, err := r.service.AbrirCaja(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(sesion, http.StatusCreated)
+}
+
+func (r resource) registrarMovimiento(c *routing.Context) error {
+	var input CreateMovimientoCajaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	movimiento, err := r.service.RegistrarMovimiento(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(movimiento, http.StatusCreated)
+}
+
+func (r resource) cerrarCaja(c *routing.Context) error {
+	var input CerrarCajaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	resumen, err := r.service.CerrarCaja(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.Write(resumen)
+}
+
+func (r resource) reporte(c *routing.Context) error {
+	idSesionCaja, _ := strconv.Atoi(c.Param("idSesionCaja"))
+	resumen, err := r.service.GetResumenCaja(c.Request.Context(), idSesionCaja)
+	if err != nil {
+		return err
+	}
+
+	plantilla, err := r.plantillas.Open(c.Request.Context(), "CierreCaja.xlsx")
+	if err != nil {
+		return err
+	}
+	defer plantilla.Close()
+	ss, err := excelize.OpenReader(plantilla)
+	if err != nil {
+		return err
+	}
+
+	sheet := ss.GetSheetName(0)
+	sesion := resumen.Sesion
+	ss.SetCellValue(sheet, "B3", sesion.IdSesionCaja)
+	ss.SetCellValue(sheet, "E3", sesion.IdUsuario)
+	ss.SetCellValue(sheet, "B4", sesion.FechaApertura.Format("2006-01-02 15:04:05"))
+	if sesion.FechaCierre != nil {
+		ss.SetCellValue(sheet, "E4", sesion.FechaCierre.Format("2006-01-02 15:04:05"))
+	}
+	ss.SetCellValue(sheet, "B5", sesion.MontoInicial.Float64())
+	ss.SetCellValue(sheet, "E5", sesion.Estado)
+	ss.SetCellValue(sheet, "B6", resumen.Facturado.NumeroFacturas)
+	ss.SetCellValue(sheet, "E6", resumen.Facturado.Total.Float64())
+
+	//Esperado vs contado por método de pago
+	fila := 9
+	var esperado, contado, diferencia money.Money
+	for _, metodo := range resumen.Metodos {
+		ss.SetCellValue(sheet, "A"+strconv.Itoa(fila), metodo.MetodoPago)
+		ss.MergeCell(sheet, "A"+strconv.Itoa(fila), "B"+strconv.Itoa(fila))
+		ss.SetCellValue(sheet, "C"+strconv.Itoa(fila), metodo.Esperado.Float64())
+		ss.SetCellValue(sheet, "D"+strconv.Itoa(fila), metodo.Contado.Float64())
+		ss.SetCellValue(sheet, "E"+strconv.Itoa(fila), metodo.Diferencia.Float64())
+		esperado += metodo.Esperado
+		contado += metodo.Contado
+		diferencia += metodo.Diferencia
+		fila++
+	}
+	ss.SetCellValue(sheet, "A"+strconv.Itoa(fila), "TOTAL")
+	ss.SetCellValue(sheet, "C"+strconv.Itoa(fila), esperado.Float64())
+	ss.SetCellValue(sheet, "D"+strconv.Itoa(fila), contado.Float64())
+	ss.SetCellValue(sheet, "E"+strconv.Itoa(fila), diferencia.Float64())
+
+	//Ingresos y egresos manuales
+	fila += 2
+	ss.SetCellValue(sheet, "A"+strconv.Itoa(fila), "Movimientos de caja")
+	fila++
+	for _, movimiento := range resumen.Movimientos {
+		ss.SetCellValue(sheet, "A"+strconv.Itoa(fila), movimiento.Fecha.Format("2006-01-02 15:04"))
+		ss.SetCellValue(sheet, "B"+strconv.Itoa(fila), movimiento.Tipo)
+		ss.SetCellValue(sheet, "C"+strconv.Itoa(fila), movimiento.Concepto)
+		ss.MergeCell(sheet, "C"+strconv.Itoa(fila), "D"+strconv.Itoa(fila))
+		ss.SetCellValue(sheet, "E"+strconv.Itoa(fila), movimiento.Monto.Float64())
+		fila++
+	}
+	if sesion.Observacion != nil {
+		fila++
+		ss.SetCellValue(sheet, "A"+strconv.Itoa(fila), "Observación:")
+		ss.SetCellValue(sheet, "B"+strconv.Itoa(fila), *sesion.Observacion)
+	}
+
+	fileName := fmt.Sprintf("CierreCaja-%d-%s.xlsx", sesion.IdSesionCaja, sesion.FechaApertura.Format("2006-01-02"))
+	var archivo bytes.Buffer
+	if err := ss.Write(&archivo); err != nil {
+		return err
+	}
+	if err := r.documentos.Save(c.Request.Context(), fileName, &archivo); err != nil {
+		return err
+	}
+	return c.Write(fileName)
+}
@@ -0,0 +1,172 @@
+package caja
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access sesionesCaja from the data source.
+type Repository interface {
+	// GetSesionesCaja returns the list sesionesCaja, the most recent first.
+	GetSesionesCaja(ctx context.Context, query pagination.Query) ([]entity.SesionCaja, *pagination.Pages, error)
+	GetSesionCajaPorId(ctx context.Context, idSesionCaja int) (entity.SesionCaja, error)
+	// GetSesionCajaAbierta locks and returns the open sesionCaja of the usuario.
+	GetSesionCajaAbierta(ctx context.Context, idUsuario int) (entity.SesionCaja, error)
+	// BloquearUsuario locks the usuario until the current transaction ends, so the cajas of a usuario are
+	// opened one at a time.
+	BloquearUsuario(ctx context.Context, idUsuario int) error
+	CrearSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error)
+	ActualizarSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error)
+	GetMovimientosCaja(ctx context.Context, idSesionCaja int) ([]entity.MovimientoCaja, error)
+	CrearMovimientoCaja(ctx context.Context, movimiento entity.MovimientoCaja) (entity.MovimientoCaja, error)
+	// GetTotalesPagos sums the pagos received in the sesionCaja by método de pago.
+	GetTotalesPagos(ctx context.Context, idSesionCaja int) ([]TotalMetodo, error)
+	// GetTotalFacturado counts and sums the facturas issued in the sesionCaja, excluding voided facturas.
+	GetTotalFacturado(ctx context.Context, idSesionCaja int) (TotalFacturado, error)
+	GetCierresCaja(ctx context.Context, idSesionCaja int) ([]entity.CierreCaja, error)
+	CrearCierreCaja(ctx context.Context, cierre entity.CierreCaja) (entity.CierreCaja, error)
+}
+
+// repository persists sesionesCaja in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new caja repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposSesionesCaja are the fields the list of sesiones caja can be filtered, searched and sorted by.
+var camposSesionesCaja = pagination.Fields{
+	Filters: map[string]string{"id_usuario": "id_usuario", "estado": "estado"},
+	Ranges:  map[string]string{"fecha_apertura": "fecha_apertura", "fecha_cierre": "fecha_cierre"},
+	Search:  []string{"observacion"},
+	Sort: map[string]string{
+		"id_sesion_caja": "id_sesion_caja",
+		"id_usuario":     "id_usuario",
+		"fecha_apertura": "fecha_apertura",
+		"monto_inicial":  "monto_inicial",
+		"fecha_cierre":   "fecha_cierre",
+		"monto_contado":  "monto_contado",
+		"diferencia":     "diferencia",
+		"estado":         "estado",
+		"observacion":    "observacion",
+	},
+	DefaultSort: []string{"fecha_apertura desc"},
+}
+
+func (r repository) GetSesionesCaja(ctx context.Context, query pagination.Query) ([]entity.SesionCaja, *pagination.Pages, error) {
+	var sesiones []entity.SesionCaja = []entity.SesionCaja{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("sesiones_caja"), camposSesionesCaja, &sesiones)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sesiones, pages, nil
+}
+
+func (r repository) GetSesionCajaPorId(ctx context.Context, idSesionCaja int) (entity.SesionCaja, error) {
+	var sesion entity.SesionCaja
+	err := r.db.With(ctx).Select().Model(idSesionCaja, &sesion)
+	return sesion, err
+}
+
+func (r repository) GetSesionCajaAbierta(ctx context.Context, idUsuario int) (entity.SesionCaja, error) {
+	var sesion entity.SesionCaja
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM sesiones_caja WHERE id_usuario = {:idUsuario} AND estado = {:estado} FOR UPDATE").
+		Bind(dbx.Params{"idUsuario": idUsuario, "estado": EstadoAbierta}).
+		One(&sesion)
+	return sesion, err
+}
+
+func (r repository) BloquearUsuario(ctx context.Context, idUsuario int) error {
+	var id int
+	return r.db.With(ctx).
+		NewQuery("SELECT id_usuario FROM usuarios WHERE id_usuario = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idUsuario}).
+		Row(&id)
+}
+
+// CrearSesionCaja saves a new SesionCaja record in the database.
+// It returns the sesionCaja with the ID of the newly inserted record.
+func (r repository) CrearSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error) {
+	err := auditoria.Insertar(ctx, r.db, &sesion)
+	if err != nil {
+		return entity.SesionCaja{}, err
+	}
+	return sesion, nil
+}
+
+func (r repository) ActualizarSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error) {
+	err := auditoria.Actualizar(ctx, r.db, &sesion)
+	if err != nil {
+		return entity.SesionCaja{}, err
+	}
+	return sesion, nil
+}
+
+func (r repository) GetMovimientosCaja(ctx context.Context, idSesionCaja int) ([]entity.MovimientoCaja, error) {
+	var movimientos []entity.MovimientoCaja = []entity.MovimientoCaja{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_sesion_caja": idSesionCaja}).
+		OrderBy("fecha asc").
+		All(&movimientos)
+	return movimientos, err
+}
+
+func (r repository) CrearMovimientoCaja(ctx context.Context, movimiento entity.MovimientoCaja) (entity.MovimientoCaja, error) {
+	err := auditoria.Insertar(ctx, r.db, &movimiento)
+	if err != nil {
+		return entity.MovimientoCaja{}, err
+	}
+	return movimiento, nil
+}
+
+func (r repository) GetTotalesPagos(ctx context.Context, idSesionCaja int) ([]TotalMetodo, error) {
+	var totales []TotalMetodo = []TotalMetodo{}
+	err := r.db.With(ctx).
+		Select("metodo_pago", "sum(monto) as total").
+		From("pagos").
+		Where(dbx.HashExp{"id_sesion_caja": idSesionCaja}).
+		GroupBy("metodo_pago").
+		All(&totales)
+	return totales, err
+}
+
+func (r repository) GetTotalFacturado(ctx context.Context, idSesionCaja int) (TotalFacturado, error) {
+	var total TotalFacturado
+	err := r.db.With(ctx).
+		Select("count(*) as numero_facturas", "coalesce(sum(valor), 0) as total").
+		From("facturas").
+		Where(dbx.HashExp{"id_sesion_caja": idSesionCaja, "anulada": false}).
+		One(&total)
+	return total, err
+}
+
+func (r repository) GetCierresCaja(ctx context.Context, idSesionCaja int) ([]entity.CierreCaja, error) {
+	var cierres []entity.CierreCaja = []entity.CierreCaja{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_sesion_caja": idSesionCaja}).
+		OrderBy("id_cierre_caja asc").
+		All(&cierres)
+	return cierres, err
+}
+
+func (r repository) CrearCierreCaja(ctx context.Context, cierre entity.CierreCaja) (entity.CierreCaja, error) {
+	err := auditoria.Insertar(ctx, r.db, &cierre)
+	if err != nil {
+		return entity.CierreCaja{}, err
+	}
+	return cierre, nil
+}
@@ -0,0 +1,328 @@
+package caja
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Estados de una sesión de caja.
+const (
+	EstadoAbierta = "ABIERTA"
+	EstadoCerrada = "CERRADA"
+)
+
+// Tipos de movimiento manual de caja.
+const (
+	TipoIngreso = "ingreso"
+	TipoEgreso  = "egreso"
+)
+
+// metodosPago is the order in which the métodos are listed in the cierre.
+var metodosPago = []string{pago.MetodoEfectivo, pago.MetodoTarjetaCredito, pago.MetodoTarjetaDebito, pago.MetodoTransferencia, pago.MetodoCheque}
+
+// Service encapsulates usecase logic for caja.
+type Service interface {
+	GetSesionesCaja(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	// GetResumenCaja returns the sesionCaja with its totals by método de pago and its movimientos.
+	GetResumenCaja(ctx context.Context, idSesionCaja int) (ResumenCaja, error)
+	// GetResumenCajaActual returns the resumen of the open sesionCaja of the current usuario.
+	GetResumenCajaActual(ctx context.Context) (ResumenCaja, error)
+	AbrirCaja(ctx context.Context, input AbrirCajaRequest) (SesionCaja, error)
+	RegistrarMovimiento(ctx context.Context, input CreateMovimientoCajaRequest) (entity.MovimientoCaja, error)
+	// CerrarCaja closes the open sesionCaja of the current usuario comparing the counted amounts to the expected ones.
+	CerrarCaja(ctx context.Context, input CerrarCajaRequest) (ResumenCaja, error)
+}
+
+// SesionCaja represents the data about a sesionCaja.
+type SesionCaja struct {
+	entity.SesionCaja
+}
+
+// TotalMetodo is the amount received with a método de pago.
+type TotalMetodo struct {
+	MetodoPago string      `json:"metodo_pago" db:"metodo_pago"`
+	Total      money.Money `json:"total" db:"total"`
+}
+
+// TotalFacturado is the number and amount of the facturas issued in a sesionCaja.
+type TotalFacturado struct {
+	NumeroFacturas int         `json:"numero_facturas" db:"numero_facturas"`
+	Total          money.Money `json:"total" db:"total"`
+}
+
+// ResumenCaja is the state of a sesionCaja. While the sesionCaja is open only the expected amounts are known.
+type ResumenCaja struct {
+	Sesion      SesionCaja              `json:"sesion"`
+	Facturado   TotalFacturado          `json:"facturado"`
+	Metodos     []entity.CierreCaja     `json:"metodos"`
+	Movimientos []entity.MovimientoCaja `json:"movimientos"`
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new caja service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+// AbrirCajaRequest represents the opening of a sesionCaja.
+type AbrirCajaRequest struct {
+	MontoInicial money.Money `json:"monto_inicial"`
+}
+
+// Validate validates the AbrirCajaRequest fields.
+func (m AbrirCajaRequest) Validate() error {
+	if m.MontoInicial < 0 {
+		return errors.BadRequest("El monto inicial no puede ser negativo.")
+	}
+	return nil
+}
+
+// CreateMovimientoCajaRequest represents a manual cash in or out.
+type CreateMovimientoCajaRequest struct {
+	Tipo     string      `json:"tipo"`
+	Concepto string      `json:"concepto"`
+	Monto    money.Money `json:"monto"`
+}
+
+// Validate validates the CreateMovimientoCajaRequest fields.
+func (m CreateMovimientoCajaRequest) Validate() error {
+	if m.Monto <= 0 {
+		return errors.BadRequest("El monto debe ser mayor a cero.")
+	}
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Tipo, validation.Required, validation.In(TipoIngreso, TipoEgreso)),
+		validation.Field(&m.Concepto, validation.Required, validation.Length(0, 200)),
+	)
+}
+
+// ContadoRequest is the amount counted for a método de pago.
+type ContadoRequest struct {
+	MetodoPago string      `json:"metodo_pago"`
+	Monto      money.Money `json:"monto"`
+}
+
+// CerrarCajaRequest represents the closing of a sesionCaja. Métodos that are not counted count as zero.
+type CerrarCajaRequest struct {
+	Contado     []ContadoRequest `json:"contado"`
+	Observacion *string          `json:"observacion"`
+}
+
+// Validate validates the CerrarCajaRequest fields.
+func (m CerrarCajaRequest) Validate() error {
+	for _, contado := range m.Contado {
+		if err := validation.Validate(contado.MetodoPago, validation.Required, validation.In(pago.MetodoEfectivo, pago.MetodoTarjetaCredito, pago.MetodoTarjetaDebito, pago.MetodoTransferencia, pago.MetodoCheque)); err != nil {
+			return errors.BadRequest("Método de pago no válido: " + contado.MetodoPago)
+		}
+		if contado.Monto < 0 {
+			return errors.BadRequest("El monto contado no puede ser negativo.")
+		}
+	}
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Observacion, validation.NilOrNotEmpty, validation.Length(0, 1000)),
+	)
+}
+
+func (s service) GetSesionesCaja(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	sesiones, pages, err := s.repo.GetSesionesCaja(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []SesionCaja{}
+	for _, item := range sesiones {
+		result = append(result, SesionCaja{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetResumenCaja(ctx context.Context, idSesionCaja int) (ResumenCaja, error) {
+	sesion, err := s.repo.GetSesionCajaPorId(ctx, idSesionCaja)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	return s.resumen(ctx, sesion)
+}
+
+func (s service) GetResumenCajaActual(ctx context.Context) (ResumenCaja, error) {
+	sesion, err := s.sesionAbierta(ctx)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	return s.resumen(ctx, sesion)
+}
+
+func (s service) AbrirCaja(ctx context.Context, req AbrirCajaRequest) (SesionCaja, error) {
+	if err := req.Validate(); err != nil {
+		return SesionCaja{}, err
+	}
+	identity := auth.CurrentUser(ctx)
+	if identity == nil {
+		return SesionCaja{}, errors.Unauthorized("")
+	}
+	//Sin sesion abierta no hay fila que bloquear, se bloquea al usuario para que dos aperturas no pasen ambas
+	if err := s.repo.BloquearUsuario(ctx, identity.GetIdUsuario()); err != nil {
+		return SesionCaja{}, err
+	}
+	_, err := s.repo.GetSesionCajaAbierta(ctx, identity.GetIdUsuario())
+	if err == nil {
+		return SesionCaja{}, errors.Conflict("Ya tiene una caja abierta.")
+	}
+	if err != sql.ErrNoRows {
+		return SesionCaja{}, err
+	}
+	sesionG, err := s.repo.CrearSesionCaja(ctx, entity.SesionCaja{
+		IdUsuario:     identity.GetIdUsuario(),
+		FechaApertura: time.Now(),
+		MontoInicial:  req.MontoInicial,
+		Estado:        EstadoAbierta,
+	})
+	if err != nil {
+		return SesionCaja{}, err
+	}
+	return SesionCaja{sesionG}, nil
+}
+
+func (s service) RegistrarMovimiento(ctx context.Context, req CreateMovimientoCajaRequest) (entity.MovimientoCaja, error) {
+	if err := req.Validate(); err != nil {
+		return entity.MovimientoCaja{}, err
+	}
+	sesion, err := s.sesionAbierta(ctx)
+	if err != nil {
+		return entity.MovimientoCaja{}, err
+	}
+	if req.Tipo == TipoEgreso {
+		resumen, err := s.resumen(ctx, sesion)
+		if err != nil {
+			return entity.MovimientoCaja{}, err
+		}
+		if req.Monto > resumen.Metodos[0].Esperado {
+			return entity.MovimientoCaja{}, errors.Conflict("El egreso excede el efectivo disponible en caja.")
+		}
+	}
+	return s.repo.CrearMovimientoCaja(ctx, entity.MovimientoCaja{
+		IdSesionCaja: sesion.IdSesionCaja,
+		IdUsuario:    sesion.IdUsuario,
+		Tipo:         req.Tipo,
+		Concepto:     req.Concepto,
+		Monto:        req.Monto,
+		Fecha:        time.Now(),
+	})
+}
+
+func (s service) CerrarCaja(ctx context.Context, req CerrarCajaRequest) (ResumenCaja, error) {
+	if err := req.Validate(); err != nil {
+		return ResumenCaja{}, err
+	}
+	sesion, err := s.sesionAbierta(ctx)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	resumen, err := s.resumen(ctx, sesion)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+
+	contado := map[string]money.Money{}
+	for _, item := range req.Contado {
+		contado[item.MetodoPago] += item.Monto
+	}
+	totalContado, totalDiferencia := money.Zero, money.Zero
+	for i, metodo := range resumen.Metodos {
+		metodo.Contado = contado[metodo.MetodoPago]
+		metodo.Diferencia = metodo.Contado - metodo.Esperado
+		cierreG, err := s.repo.CrearCierreCaja(ctx, metodo)
+		if err != nil {
+			return ResumenCaja{}, err
+		}
+		resumen.Metodos[i] = cierreG
+		totalContado += cierreG.Contado
+		totalDiferencia += cierreG.Diferencia
+	}
+
+	fechaCierre := time.Now()
+	sesion.FechaCierre = &fechaCierre
+	sesion.MontoContado = &totalContado
+	sesion.Diferencia = &totalDiferencia
+	sesion.Estado = EstadoCerrada
+	sesion.Observacion = req.Observacion
+	sesionG, err := s.repo.ActualizarSesionCaja(ctx, sesion)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	resumen.Sesion = SesionCaja{sesionG}
+	return resumen, nil
+}
+
+// sesionAbierta returns the open sesionCaja of the current usuario.
+func (s service) sesionAbierta(ctx context.Context) (entity.SesionCaja, error) {
+	identity := auth.CurrentUser(ctx)
+	if identity == nil {
+		return entity.SesionCaja{}, errors.Unauthorized("")
+	}
+	sesion, err := s.repo.GetSesionCajaAbierta(ctx, identity.GetIdUsuario())
+	if err == sql.ErrNoRows {
+		return entity.SesionCaja{}, errors.Conflict("No tiene una caja abierta.")
+	}
+	return sesion, err
+}
+
+func (s service) resumen(ctx context.Context, sesion entity.SesionCaja) (ResumenCaja, error) {
+	facturado, err := s.repo.GetTotalFacturado(ctx, sesion.IdSesionCaja)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	movimientos, err := s.repo.GetMovimientosCaja(ctx, sesion.IdSesionCaja)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	resumen := ResumenCaja{Sesion: SesionCaja{sesion}, Facturado: facturado, Movimientos: movimientos}
+	if sesion.Estado == EstadoCerrada {
+		resumen.Metodos, err = s.repo.GetCierresCaja(ctx, sesion.IdSesionCaja)
+		return resumen, err
+	}
+	totales, err := s.repo.GetTotalesPagos(ctx, sesion.IdSesionCaja)
+	if err != nil {
+		return ResumenCaja{}, err
+	}
+	resumen.Metodos = esperado(sesion, totales, movimientos)
+	return resumen, nil
+}
+
+// esperado computes the amount that should be in caja for each método de pago. Efectivo also
+// includes the monto inicial and the manual movimientos.
+func esperado(sesion entity.SesionCaja, totales []TotalMetodo, movimientos []entity.MovimientoCaja) []entity.CierreCaja {
+	porMetodo := map[string]money.Money{pago.MetodoEfectivo: sesion.MontoInicial}
+	for _, total := range totales {
+		porMetodo[total.MetodoPago] += total.Total
+	}
+	for _, movimiento := range movimientos {
+		if movimiento.Tipo == TipoIngreso {
+			porMetodo[pago.MetodoEfectivo] += movimiento.Monto
+		} else {
+			porMetodo[pago.MetodoEfectivo] -= movimiento.Monto
+		}
+	}
+	result := []entity.CierreCaja{}
+	for _, metodo := range metodosPago {
+		result = append(result, entity.CierreCaja{
+			IdSesionCaja: sesion.IdSesionCaja,
+			MetodoPago:   metodo,
+			Esperado:     porMetodo[metodo],
+		})
+	}
+	return result
+}
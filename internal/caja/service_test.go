@@ -0,0 +1,125 @@
+package caja
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_CerrarCaja(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{totales: []TotalMetodo{
+		{MetodoPago: pago.MetodoEfectivo, Total: 12000},
+		{MetodoPago: pago.MetodoTarjetaCredito, Total: 5000},
+	}}
+	s := NewService(repo, logger)
+	ctx := auth.WithUser(context.Background(), 7, "recepcion")
+
+	// sin caja abierta
+	_, err := s.CerrarCaja(ctx, CerrarCajaRequest{})
+	assert.NotNil(t, err)
+
+	sesion, err := s.AbrirCaja(ctx, AbrirCajaRequest{MontoInicial: 2000})
+	assert.Nil(t, err)
+	assert.Equal(t, EstadoAbierta, sesion.Estado)
+	_, err = s.AbrirCaja(ctx, AbrirCajaRequest{MontoInicial: 2000})
+	assert.NotNil(t, err)
+
+	_, err = s.RegistrarMovimiento(ctx, CreateMovimientoCajaRequest{Tipo: TipoEgreso, Concepto: "Compra de insumos", Monto: 1500})
+	assert.Nil(t, err)
+	_, err = s.RegistrarMovimiento(ctx, CreateMovimientoCajaRequest{Tipo: TipoEgreso, Concepto: "Retiro", Monto: 20000})
+	assert.NotNil(t, err)
+
+	resumen, err := s.CerrarCaja(ctx, CerrarCajaRequest{Contado: []ContadoRequest{
+		{MetodoPago: pago.MetodoEfectivo, Monto: 12000},
+		{MetodoPago: pago.MetodoTarjetaCredito, Monto: 5000},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, EstadoCerrada, resumen.Sesion.Estado)
+	assert.Equal(t, entity.CierreCaja{IdSesionCaja: 1, MetodoPago: pago.MetodoEfectivo, Esperado: 12500, Contado: 12000, Diferencia: -500}, resumen.Metodos[0])
+	assert.Equal(t, money.Money(0), resumen.Metodos[1].Diferencia)
+	assert.Equal(t, money.Money(-500), *resumen.Sesion.Diferencia)
+	assert.Len(t, repo.cierres, len(metodosPago))
+}
+
+type mockRepository struct {
+	sesiones    []entity.SesionCaja
+	movimientos []entity.MovimientoCaja
+	cierres     []entity.CierreCaja
+	totales     []TotalMetodo
+}
+
+func (m *mockRepository) GetSesionesCaja(ctx context.Context, query pagination.Query) ([]entity.SesionCaja, *pagination.Pages, error) {
+	items := m.sesiones
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m *mockRepository) GetSesionCajaPorId(ctx context.Context, idSesionCaja int) (entity.SesionCaja, error) {
+	for _, sesion := range m.sesiones {
+		if sesion.IdSesionCaja == idSesionCaja {
+			return sesion, nil
+		}
+	}
+	return entity.SesionCaja{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetSesionCajaAbierta(ctx context.Context, idUsuario int) (entity.SesionCaja, error) {
+	for _, sesion := range m.sesiones {
+		if sesion.IdUsuario == idUsuario && sesion.Estado == EstadoAbierta {
+			return sesion, nil
+		}
+	}
+	return entity.SesionCaja{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) BloquearUsuario(ctx context.Context, idUsuario int) error {
+	return nil
+}
+
+func (m *mockRepository) CrearSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error) {
+	sesion.IdSesionCaja = len(m.sesiones) + 1
+	m.sesiones = append(m.sesiones, sesion)
+	return sesion, nil
+}
+
+func (m *mockRepository) ActualizarSesionCaja(ctx context.Context, sesion entity.SesionCaja) (entity.SesionCaja, error) {
+	m.sesiones[sesion.IdSesionCaja-1] = sesion
+	return sesion, nil
+}
+
+func (m *mockRepository) GetMovimientosCaja(ctx context.Context, idSesionCaja int) ([]entity.MovimientoCaja, error) {
+	return m.movimientos, nil
+}
+
+func (m *mockRepository) CrearMovimientoCaja(ctx context.Context, movimiento entity.MovimientoCaja) (entity.MovimientoCaja, error) {
+	movimiento.IdMovimientoCaja = len(m.movimientos) + 1
+	m.movimientos = append(m.movimientos, movimiento)
+	return movimiento, nil
+}
+
+func (m *mockRepository) GetTotalesPagos(ctx context.Context, idSesionCaja int) ([]TotalMetodo, error) {
+	return m.totales, nil
+}
+
+func (m *mockRepository) GetTotalFacturado(ctx context.Context, idSesionCaja int) (TotalFacturado, error) {
+	return TotalFacturado{NumeroFacturas: 3, Total: 17000}, nil
+}
+
+func (m *mockRepository) GetCierresCaja(ctx context.Context, idSesionCaja int) ([]entity.CierreCaja, error) {
+	return m.cierres, nil
+}
+
+func (m *mockRepository) CrearCierreCaja(ctx context.Context, cierre entity.CierreCaja) (entity.CierreCaja, error) {
+	m.cierres = append(m.cierres, cierre)
+	return cierre, nil
+}
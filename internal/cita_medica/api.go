@@ -3,8 +3,10 @@ package cita_medica
 import (
 	"net/http"
 	"strconv"
+	"time"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -17,9 +19,11 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 	r.Get("/citasMedica", res.getCitasMedica)
 	r.Get("/citasMedica/pendientes", res.getCitasMedicaPendientes)
 	r.Get("/citasMedica/sinNotificar", res.getCitasMedicaSinNotificar)
+	r.Get("/citasMedica/disponibilidad/<idUsuario>", res.getDisponibilidad)
 	r.Get("/citasMedica/<idCitaMedica>", res.getCitaMedicaPorId)
 	r.Post("/citasMedica", res.crearCitaMedica)
 	r.Put("/citasMedica", res.actualizarCitaMedica)
+	r.Post("/citasMedica/<idCitaMedica>/estado", res.cambiarEstado)
 }
 
 type resource struct {
@@ -28,11 +32,12 @@ type resource struct {
 }
 
 func (r resource) getCitasMedica(c *routing.Context) error {
-	citasMedica, err := r.service.GetCitasMedica(c.Request.Context())
+	pages, err := r.service.GetCitasMedica(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(citasMedica)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getCitasMedicaPendientes(c *routing.Context) error {
@@ -85,3 +90,37 @@ func (r resource) getCitaMedicaPorId(c *routing.Context) error {
 	}
 	return c.Write(citaMedica)
 }
+
+func (r resource) cambiarEstado(c *routing.Context) error {
+	idCitaMedica, _ := strconv.Atoi(c.Param("idCitaMedica"))
+	var input CambiarEstadoRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	citaMedica, err := r.service.CambiarEstado(c.Request.Context(), idCitaMedica, input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(citaMedica, http.StatusCreated)
+}
+
+// getDisponibilidad returns the free espacios of the veterinario on the day of the fecha query parameter, e.g.
+// "?fecha=2026-10-06&duracion_minutos=30". Without duracion_minutos it uses the one of id_tipo_cita.
+func (r resource) getDisponibilidad(c *routing.Context) error {
+	idUsuario, _ := strconv.Atoi(c.Param("idUsuario"))
+	dia, err := time.ParseInLocation("2006-01-02", c.Query("fecha"), time.Local)
+	if err != nil {
+		return errors.BadRequest("La fecha debe tener el formato AAAA-MM-DD.")
+	}
+	var idTipoCita *int
+	if id, err := strconv.Atoi(c.Query("id_tipo_cita")); err == nil {
+		idTipoCita = &id
+	}
+	duracionMinutos, _ := strconv.Atoi(c.Query("duracion_minutos"))
+	espacios, err := r.service.GetDisponibilidad(c.Request.Context(), idUsuario, dia, idTipoCita, duracionMinutos)
+	if err != nil {
+		return err
+	}
+	return c.Write(espacios)
+}
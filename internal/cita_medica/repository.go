@@ -2,9 +2,12 @@ package cita_medica
 
 import (
 	"context"
+	"time"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,11 +17,25 @@ type Repository interface {
 	// GetCitaMedicaPorId returns the citaMedica with the specified citaMedica ID.
 	GetCitaMedicaPorId(ctx context.Context, idCitaMedica int) (entity.CitaMedica, error)
 	// GetCitasMedica returns the list citasMedica.
-	GetCitasMedica(ctx context.Context) ([]entity.CitaMedica, error)
+	GetCitasMedica(ctx context.Context, query pagination.Query) ([]entity.CitaMedica, *pagination.Pages, error)
 	GetCitasMedicaPendientes(ctx context.Context) ([]entity.CitaMedica, error)
 	GetCitasMedicaSinNotificar(ctx context.Context) ([]CitaMedicaDatos, error)
 	CrearCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error)
 	ActualizarCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error)
+	// ActualizarEstado saves the estado of the cita.
+	ActualizarEstado(ctx context.Context, citaMedica entity.CitaMedica) error
+	GetTipoCitaPorId(ctx context.Context, idTipoCita int) (entity.TipoCita, error)
+	// GetHorarios returns the active horarios of the veterinario on the dia de la semana.
+	GetHorarios(ctx context.Context, idUsuario int, diaSemana int) ([]entity.HorarioVeterinario, error)
+	// GetCitasVeterinario returns the agendadas and confirmadas citas of the veterinario that overlap the
+	// time from desde to hasta.
+	GetCitasVeterinario(ctx context.Context, idUsuario int, desde, hasta time.Time) ([]entity.CitaMedica, error)
+	// BloquearVeterinario locks the usuario of the veterinario until the current transaction ends, so the citas
+	// of a veterinario are agendadas one at a time.
+	BloquearVeterinario(ctx context.Context, idUsuario int) error
+	// GetCitasSolapadas returns the other agendadas and confirmadas citas of the veterinario or the sala of the
+	// cita that overlap it, and locks them and the time they overlap until the current transaction ends.
+	GetCitasSolapadas(ctx context.Context, citaMedica entity.CitaMedica) ([]entity.CitaMedica, error)
 }
 
 // repository persists citasMedica in database
@@ -32,18 +49,40 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposCitasMedica are the fields the list of citas medica can be filtered, searched and sorted by.
+var camposCitasMedica = pagination.Fields{
+	Filters: map[string]string{
+		"id_mascota":          "id_mascota",
+		"id_tipo_cita":        "id_tipo_cita",
+		"id_usuario":          "id_usuario",
+		"sala":                "sala",
+		"estado":              "estado",
+		"estado_notificacion": "estado_notificacion",
+		"estado_confirmacion": "estado_confirmacion",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Search: []string{"motivo"},
+	Sort: map[string]string{
+		"id_cita_medica":      "id_cita_medica",
+		"id_mascota":          "id_mascota",
+		"motivo":              "motivo",
+		"fecha":               "fecha",
+		"estado":              "estado",
+		"estado_notificacion": "estado_notificacion",
+		"estado_confirmacion": "estado_confirmacion",
+	},
+	DefaultSort: []string{"id_cita_medica asc"},
+}
+
 // Get reads the list citasMedica from the database.
-func (r repository) GetCitasMedica(ctx context.Context) ([]entity.CitaMedica, error) {
-	var citasMedica []entity.CitaMedica
+func (r repository) GetCitasMedica(ctx context.Context, query pagination.Query) ([]entity.CitaMedica, *pagination.Pages, error) {
+	var citasMedica []entity.CitaMedica = []entity.CitaMedica{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&citasMedica)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("citas_medicas"), camposCitasMedica, &citasMedica)
 	if err != nil {
-		return citasMedica, err
+		return nil, nil, err
 	}
-	return citasMedica, err
+	return citasMedica, pages, nil
 }
 
 func (r repository) GetCitasMedicaPendientes(ctx context.Context) ([]entity.CitaMedica, error) {
@@ -53,6 +92,7 @@ func (r repository) GetCitasMedicaPendientes(ctx context.Context) ([]entity.Cita
 		Select().
 		From().
 		Where(dbx.NewExp("DATE(now()) <= fecha")).
+		AndWhere(dbx.In("estado", EstadoAgendada, EstadoConfirmada)).
 		All(&citasMedica)
 	if err != nil {
 		return citasMedica, err
@@ -60,52 +100,29 @@ func (r repository) GetCitasMedicaPendientes(ctx context.Context) ([]entity.Cita
 	return citasMedica, err
 }
 
+// GetCitasMedicaSinNotificar reads the citas of the next 3 days not notified yet, with the mascota
+// and its duenio, in a single query.
 func (r repository) GetCitasMedicaSinNotificar(ctx context.Context) ([]CitaMedicaDatos, error) {
-	var citasMedica []entity.CitaMedica
 	var citasMedicasDatos []CitaMedicaDatos = []CitaMedicaDatos{}
 
 	err := r.db.With(ctx).
-		Select().
-		From().
-		Where(dbx.NewExp("(date(fecha) between date(now()) and date_add(date(now()),interval 3 day)) and estado_notificacion = 'NO'")).
-		All(&citasMedica)
-
+		Select("cm.*", "c.id_cliente", "CONCAT(c.apellidos, ' ', c.nombres) AS duenio", "COALESCE(c.telefono, '') AS telefono", "COALESCE(c.correo, '') AS correo", "COALESCE(m.nombre, '') AS mascota").
+		From("citas_medicas cm").
+		InnerJoin("mascotas m", dbx.NewExp("m.id_mascota = cm.id_mascota")).
+		InnerJoin("clientes c", dbx.NewExp("c.id_cliente = m.id_cliente")).
+		Where(dbx.NewExp("(date(cm.fecha) between date(now()) and date_add(date(now()),interval 3 day)) and cm.estado_notificacion = 'NO'")).
+		OrderBy("cm.id_cita_medica asc").
+		All(&citasMedicasDatos)
 	if err != nil {
 		return []CitaMedicaDatos{}, err
 	}
-
-	for i := 0; i < len(citasMedica); i++ {
-		var duenioNombre, duenioApellido, telefono, nombreMascota string
-		duenioNombre = ""
-		duenioApellido = ""
-		telefono = ""
-		nombreMascota = ""
-		err := r.db.With(ctx).
-			Select("c.nombres", "c.apellidos", "c.telefono", "m.nombre").
-			From("mascotas m").
-			InnerJoin("clientes c", dbx.NewExp("c.id_cliente = m.id_cliente")).
-			Where(dbx.HashExp{"m.id_mascota": citasMedica[i].IdMascota}).
-			Row(&duenioNombre, &duenioApellido, &telefono, &nombreMascota)
-
-		if err != nil {
-			return []CitaMedicaDatos{}, err
-		}
-
-		citasMedicasDatos = append(citasMedicasDatos, CitaMedicaDatos{
-			citasMedica[i],
-			duenioApellido + " " + duenioNombre,
-			telefono,
-			nombreMascota,
-		})
-	}
-
-	return citasMedicasDatos, err
+	return citasMedicasDatos, nil
 }
 
 // Create saves a new CitaMedica record in the database.
 // It returns the ID of the newly inserted citaMedica record.
 func (r repository) CrearCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error) {
-	err := r.db.With(ctx).Model(&citaMedica).Insert()
+	err := auditoria.Insertar(ctx, r.db, &citaMedica)
 	if err != nil {
 		return entity.CitaMedica{}, err
 	}
@@ -117,9 +134,9 @@ func (r repository) CrearCitaMedica(ctx context.Context, citaMedica entity.CitaM
 func (r repository) ActualizarCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error) {
 	var err error
 	if citaMedica.IdCitaMedica != 0 {
-		err = r.db.With(ctx).Model(&citaMedica).Update()
+		err = auditoria.Actualizar(ctx, r.db, &citaMedica)
 	} else {
-		err = r.db.With(ctx).Model(&citaMedica).Insert()
+		err = auditoria.Insertar(ctx, r.db, &citaMedica)
 	}
 	if err != nil {
 		return entity.CitaMedica{}, err
@@ -133,3 +150,75 @@ func (r repository) GetCitaMedicaPorId(ctx context.Context, idCitaMedica int) (e
 	err := r.db.With(ctx).Select().Model(idCitaMedica, &citaMedica)
 	return citaMedica, err
 }
+
+func (r repository) ActualizarEstado(ctx context.Context, citaMedica entity.CitaMedica) error {
+	return auditoria.Actualizar(ctx, r.db, &citaMedica, "Estado")
+}
+
+func (r repository) GetTipoCitaPorId(ctx context.Context, idTipoCita int) (entity.TipoCita, error) {
+	var tipoCita entity.TipoCita
+	err := r.db.With(ctx).Select().Model(idTipoCita, &tipoCita)
+	return tipoCita, err
+}
+
+func (r repository) GetHorarios(ctx context.Context, idUsuario int, diaSemana int) ([]entity.HorarioVeterinario, error) {
+	var horarios []entity.HorarioVeterinario = []entity.HorarioVeterinario{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_usuario": idUsuario, "dia_semana": diaSemana, "activo": true}).
+		OrderBy("hora_inicio asc").
+		All(&horarios)
+	return horarios, err
+}
+
+func (r repository) GetCitasVeterinario(ctx context.Context, idUsuario int, desde, hasta time.Time) ([]entity.CitaMedica, error) {
+	var citasMedica []entity.CitaMedica = []entity.CitaMedica{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_usuario": idUsuario}).
+		AndWhere(dbx.In("estado", EstadoAgendada, EstadoConfirmada)).
+		AndWhere(solapa(desde, hasta)).
+		OrderBy("fecha asc").
+		All(&citasMedica)
+	return citasMedica, err
+}
+
+func (r repository) GetCitasSolapadas(ctx context.Context, citaMedica entity.CitaMedica) ([]entity.CitaMedica, error) {
+	var citasMedica []entity.CitaMedica = []entity.CitaMedica{}
+	recursos := []dbx.Expression{}
+	if citaMedica.IdUsuario != nil {
+		recursos = append(recursos, dbx.HashExp{"id_usuario": *citaMedica.IdUsuario})
+	}
+	if citaMedica.Sala != nil {
+		recursos = append(recursos, dbx.HashExp{"sala": *citaMedica.Sala})
+	}
+	if len(recursos) == 0 {
+		return citasMedica, nil
+	}
+	fin := citaMedica.Fecha.Add(time.Duration(citaMedica.DuracionMinutos) * time.Minute)
+	q := r.db.With(ctx).
+		Select().
+		From("citas_medicas").
+		Where(dbx.Or(recursos...)).
+		AndWhere(dbx.Not(dbx.HashExp{"id_cita_medica": citaMedica.IdCitaMedica})).
+		AndWhere(dbx.In("estado", EstadoAgendada, EstadoConfirmada)).
+		AndWhere(solapa(citaMedica.Fecha, fin)).
+		OrderBy("fecha asc").
+		Build()
+	//FOR UPDATE bloquea tambien los huecos del indice, otra cita de la misma sala espera a esta transaccion
+	err := r.db.With(ctx).NewQuery(q.SQL() + " FOR UPDATE").Bind(q.Params()).All(&citasMedica)
+	return citasMedica, err
+}
+
+func (r repository) BloquearVeterinario(ctx context.Context, idUsuario int) error {
+	var id int
+	return r.db.With(ctx).
+		NewQuery("SELECT id_usuario FROM usuarios WHERE id_usuario = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idUsuario}).
+		Row(&id)
+}
+
+// solapa is the condition of the citas that overlap the time from desde to hasta.
+func solapa(desde, hasta time.Time) dbx.Expression {
+	return dbx.NewExp("fecha < {:hasta} AND DATE_ADD(fecha, INTERVAL duracion_minutos MINUTE) > {:desde}", dbx.Params{"desde": desde, "hasta": hasta})
+}
@@ -0,0 +1,51 @@
+package cita_medica
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+)
+
+// BenchmarkRepository_GetCitasMedicaSinNotificar checks that the citas to notify are read with a
+// single query whatever the number of citas.
+func BenchmarkRepository_GetCitasMedicaSinNotificar(b *testing.B) {
+	logger, _ := log.NewForTest()
+	db := test.DB(b)
+	repo := NewRepository(db, logger)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("citas=%d", n), func(b *testing.B) {
+			test.Rollback(b, db, func(ctx context.Context) {
+				nombre := "Firulais"
+				cliente := entity.Cliente{Nombres: "Ana", Apellidos: "Pérez", Cedula: "0900000001"}
+				especie := entity.Especie{Descripcion: "Canino"}
+				genero := entity.Genero{Descripcion: "Macho"}
+				test.Insert(b, ctx, db, &cliente, &especie, &genero)
+				mascota := entity.Mascota{IdCliente: cliente.IdCliente, IdEspecie: especie.IdEspecie, IdGenero: genero.IdGenero, Nombre: &nombre}
+				test.Insert(b, ctx, db, &mascota)
+				for i := 0; i < n; i++ {
+					test.Insert(b, ctx, db, &entity.CitaMedica{IdMascota: mascota.IdMascota, Motivo: "Control", Fecha: time.Now().AddDate(0, 0, 1), EstadoNotificacion: "NO"})
+				}
+
+				consultas := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					consultas = test.CountQueries(db, func() {
+						citas, err := repo.GetCitasMedicaSinNotificar(ctx)
+						if err != nil || len(citas) < n {
+							b.Fatalf("GetCitasMedicaSinNotificar: %d citas, %v", len(citas), err)
+						}
+					})
+				}
+				if consultas != 1 {
+					b.Fatalf("GetCitasMedicaSinNotificar ran %d queries", consultas)
+				}
+				b.ReportMetric(float64(consultas), "queries/op")
+			})
+		})
+	}
+}
@@ -2,21 +2,59 @@ package cita_medica
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/horario_veterinario"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// Estados of a cita. Atendida, cancelada and no asistio are final.
+const (
+	EstadoAgendada   = "AGENDADA"
+	EstadoConfirmada = "CONFIRMADA"
+	EstadoAtendida   = "ATENDIDA"
+	EstadoCancelada  = "CANCELADA"
+	EstadoNoAsistio  = "NO_ASISTIO"
+)
+
+// transiciones are the estados a cita can move to from each estado.
+var transiciones = map[string][]string{
+	EstadoAgendada:   {EstadoConfirmada, EstadoAtendida, EstadoCancelada, EstadoNoAsistio},
+	EstadoConfirmada: {EstadoAtendida, EstadoCancelada, EstadoNoAsistio},
+}
+
+// DuracionPredeterminada is the duracion, in minutes, of a cita without duracion nor tipo de cita.
+const DuracionPredeterminada = 30
+
+// Estados de confirmacion of a cita, set from the replies of the cliente to the recordatorio.
+const (
+	ConfirmacionPendiente   = "PENDIENTE"
+	ConfirmacionConfirmada  = "CONFIRMADA"
+	ConfirmacionCancelada   = "CANCELADA"
+	ConfirmacionReprogramar = "REPROGRAMAR"
+)
+
 // Service encapsulates usecase logic for citasMedica.
 type Service interface {
-	GetCitasMedica(ctx context.Context) ([]CitaMedica, error)
+	GetCitasMedica(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetCitasMedicaPendientes(ctx context.Context) ([]CitaMedica, error)
 	GetCitasMedicaSinNotificar(ctx context.Context) ([]CitaMedicaDatos, error)
 	GetCitaMedicaPorId(ctx context.Context, idCitaMedica int) (CitaMedica, error)
+	// CrearCitaMedica creates an agendada cita. A cita of a veterinario must be within the horario of the
+	// veterinario and, like a cita in a sala, must not overlap the other agendadas and confirmadas.
 	CrearCitaMedica(ctx context.Context, input CreateCitaMedicaRequest) (CitaMedica, error)
 	ActualizarCitaMedica(ctx context.Context, input UpdateCitaMedicaRequest) (CitaMedica, error)
+	// CambiarEstado moves the cita to another estado, following the transiciones of the estados.
+	CambiarEstado(ctx context.Context, idCitaMedica int, input CambiarEstadoRequest) (CitaMedica, error)
+	// GetDisponibilidad returns the free espacios of the agenda of the veterinario on the dia for a cita of the
+	// duracion, or of the duracion of the tipo de cita when it is 0.
+	GetDisponibilidad(ctx context.Context, idUsuario int, dia time.Time, idTipoCita *int, duracionMinutos int) ([]Espacio, error)
 }
 
 // CitasMedica represents the data about an citasMedica.
@@ -26,24 +64,33 @@ type CitaMedica struct {
 
 type CitaMedicaDatos struct {
 	entity.CitaMedica
-	Duenio   string `json:"duenio"`
-	Telefono string `json:"telefono"`
-	Mascota  string `json:"mascota"`
+	IdCliente int    `json:"id_cliente"`
+	Duenio    string `json:"duenio"`
+	Telefono  string `json:"telefono"`
+	Correo    string `json:"correo"`
+	Mascota   string `json:"mascota"`
+}
+
+// Espacio is a free time of the agenda of a veterinario.
+type Espacio struct {
+	Inicio time.Time `json:"inicio"`
+	Fin    time.Time `json:"fin"`
 }
 
 type service struct {
 	repo   Repository
 	logger log.Logger
+	now    func() time.Time
 }
 
 // NewService creates a new citasMedica service.
 func NewService(repo Repository, logger log.Logger) Service {
-	return service{repo, logger}
+	return service{repo, logger, time.Now}
 }
 
 // Get returns the list citasMedica.
-func (s service) GetCitasMedica(ctx context.Context) ([]CitaMedica, error) {
-	citasMedica, err := s.repo.GetCitasMedica(ctx)
+func (s service) GetCitasMedica(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	citasMedica, pages, err := s.repo.GetCitasMedica(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +98,8 @@ func (s service) GetCitasMedica(ctx context.Context) ([]CitaMedica, error) {
 	for _, item := range citasMedica {
 		result = append(result, CitaMedica{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetCitasMedicaPendientes(ctx context.Context) ([]CitaMedica, error) {
@@ -75,18 +123,27 @@ func (s service) GetCitasMedicaSinNotificar(ctx context.Context) ([]CitaMedicaDa
 }
 
 // CreateCitaMedicaRequest represents an citaMedica creation request.
+// The duracion of a cita is optional, a cita without duracion takes the one of its tipo de cita.
 type CreateCitaMedicaRequest struct {
 	IdMascota          int       `json:"id_mascota"`
+	IdTipoCita         *int      `json:"id_tipo_cita"`
+	IdUsuario          *int      `json:"id_usuario"`
 	Motivo             string    `json:"motivo"`
 	Fecha              time.Time `json:"fecha"`
+	DuracionMinutos    int       `json:"duracion_minutos"`
+	Sala               *string   `json:"sala"`
 	EstadoNotificacion string    `json:"estado_notificacion"`
 }
 
 type UpdateCitaMedicaRequest struct {
 	IdCitaMedica       int       `json:"id_cita_medica"`
 	IdMascota          int       `json:"id_mascota"`
+	IdTipoCita         *int      `json:"id_tipo_cita"`
+	IdUsuario          *int      `json:"id_usuario"`
 	Motivo             string    `json:"motivo"`
 	Fecha              time.Time `json:"fecha"`
+	DuracionMinutos    int       `json:"duracion_minutos"`
+	Sala               *string   `json:"sala"`
 	EstadoNotificacion string    `json:"estado_notificacion"`
 }
 
@@ -95,6 +152,8 @@ func (m UpdateCitaMedicaRequest) ValidateUpdate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.IdMascota, validation.Required),
 		validation.Field(&m.Motivo, validation.Required, validation.Length(0, 1000)),
+		validation.Field(&m.DuracionMinutos, validation.Min(0), validation.Max(24*60)),
+		validation.Field(&m.Sala, validation.Length(0, 50)),
 	)
 }
 
@@ -103,6 +162,8 @@ func (m CreateCitaMedicaRequest) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.IdMascota, validation.Required),
 		validation.Field(&m.Motivo, validation.Required, validation.Length(0, 1000)),
+		validation.Field(&m.DuracionMinutos, validation.Min(0), validation.Max(24*60)),
+		validation.Field(&m.Sala, validation.Length(0, 50)),
 	)
 }
 
@@ -111,12 +172,22 @@ func (s service) CrearCitaMedica(ctx context.Context, req CreateCitaMedicaReques
 	if err := req.Validate(); err != nil {
 		return CitaMedica{}, err
 	}
-	citaMedicaG, err := s.repo.CrearCitaMedica(ctx, entity.CitaMedica{
+	citaMedica := entity.CitaMedica{
 		IdMascota:          req.IdMascota,
+		IdTipoCita:         req.IdTipoCita,
+		IdUsuario:          req.IdUsuario,
 		Motivo:             req.Motivo,
 		Fecha:              req.Fecha,
+		DuracionMinutos:    req.DuracionMinutos,
+		Sala:               sala(req.Sala),
+		Estado:             EstadoAgendada,
 		EstadoNotificacion: req.EstadoNotificacion,
-	})
+		EstadoConfirmacion: ConfirmacionPendiente,
+	}
+	if err := s.agendar(ctx, &citaMedica); err != nil {
+		return CitaMedica{}, err
+	}
+	citaMedicaG, err := s.repo.CrearCitaMedica(ctx, citaMedica)
 	if err != nil {
 		return CitaMedica{}, err
 	}
@@ -128,13 +199,37 @@ func (s service) ActualizarCitaMedica(ctx context.Context, req UpdateCitaMedicaR
 	if err := req.ValidateUpdate(); err != nil {
 		return CitaMedica{}, err
 	}
-	citaMedicaG, err := s.repo.ActualizarCitaMedica(ctx, entity.CitaMedica{
+	citaMedica := entity.CitaMedica{
 		IdCitaMedica:       req.IdCitaMedica,
 		IdMascota:          req.IdMascota,
+		IdTipoCita:         req.IdTipoCita,
+		IdUsuario:          req.IdUsuario,
 		Motivo:             req.Motivo,
 		Fecha:              req.Fecha,
+		DuracionMinutos:    req.DuracionMinutos,
+		Sala:               sala(req.Sala),
+		Estado:             EstadoAgendada,
 		EstadoNotificacion: req.EstadoNotificacion,
-	})
+		EstadoConfirmacion: ConfirmacionPendiente,
+	}
+	//El estado cambia por CambiarEstado y la confirmacion la registra el cliente, se conservan salvo que la
+	//cita cambie de fecha
+	if req.IdCitaMedica != 0 {
+		actual, err := s.repo.GetCitaMedicaPorId(ctx, req.IdCitaMedica)
+		if err != nil {
+			return CitaMedica{}, err
+		}
+		citaMedica.Estado = actual.Estado
+		if actual.Fecha.Equal(req.Fecha) {
+			citaMedica.EstadoConfirmacion = actual.EstadoConfirmacion
+		} else if actual.Estado == EstadoConfirmada {
+			citaMedica.Estado = EstadoAgendada
+		}
+	}
+	if err := s.agendar(ctx, &citaMedica); err != nil {
+		return CitaMedica{}, err
+	}
+	citaMedicaG, err := s.repo.ActualizarCitaMedica(ctx, citaMedica)
 	if err != nil {
 		return CitaMedica{}, err
 	}
@@ -149,3 +244,175 @@ func (s service) GetCitaMedicaPorId(ctx context.Context, idCitaMedica int) (Cita
 	}
 	return CitaMedica{citaMedica}, nil
 }
+
+// CambiarEstadoRequest represents a request to move a cita to another estado.
+type CambiarEstadoRequest struct {
+	Estado string `json:"estado"`
+}
+
+// Validate validates the CambiarEstadoRequest fields.
+func (m CambiarEstadoRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Estado, validation.Required, validation.In(EstadoAgendada, EstadoConfirmada, EstadoAtendida, EstadoCancelada, EstadoNoAsistio)),
+	)
+}
+
+func (s service) CambiarEstado(ctx context.Context, idCitaMedica int, req CambiarEstadoRequest) (CitaMedica, error) {
+	if err := req.Validate(); err != nil {
+		return CitaMedica{}, err
+	}
+	citaMedica, err := s.repo.GetCitaMedicaPorId(ctx, idCitaMedica)
+	if err != nil {
+		return CitaMedica{}, err
+	}
+	if !TransicionValida(citaMedica.Estado, req.Estado) {
+		return CitaMedica{}, errors.Conflict(fmt.Sprintf("Una cita %s no puede pasar a %s.", citaMedica.Estado, req.Estado))
+	}
+	citaMedica.Estado = req.Estado
+	if err := s.repo.ActualizarEstado(ctx, citaMedica); err != nil {
+		return CitaMedica{}, err
+	}
+	return CitaMedica{citaMedica}, nil
+}
+
+// TransicionValida reports whether a cita in the estado desde can move to the estado hacia.
+func TransicionValida(desde, hacia string) bool {
+	for _, estado := range transiciones[desde] {
+		if estado == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+// Activa reports whether a cita in the estado takes time of the agenda, which are the agendadas and confirmadas.
+func Activa(estado string) bool {
+	return estado == EstadoAgendada || estado == EstadoConfirmada
+}
+
+func (s service) GetDisponibilidad(ctx context.Context, idUsuario int, dia time.Time, idTipoCita *int, duracionMinutos int) ([]Espacio, error) {
+	duracionMinutos, err := s.duracion(ctx, idTipoCita, duracionMinutos)
+	if err != nil {
+		return nil, err
+	}
+	duracion := time.Duration(duracionMinutos) * time.Minute
+	dia = time.Date(dia.Year(), dia.Month(), dia.Day(), 0, 0, 0, 0, dia.Location())
+	horarios, err := s.repo.GetHorarios(ctx, idUsuario, int(dia.Weekday()))
+	if err != nil {
+		return nil, err
+	}
+	citas, err := s.repo.GetCitasVeterinario(ctx, idUsuario, dia, dia.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	ahora := s.now()
+	espacios := []Espacio{}
+	for _, horario := range horarios {
+		inicio, fin, err := horario_veterinario.Intervalo(horario, dia)
+		if err != nil {
+			return nil, err
+		}
+		for t := inicio; !t.Add(duracion).After(fin); {
+			//Un espacio ocupado se salta hasta el final de la cita
+			if cita, ok := cruce(citas, t, t.Add(duracion)); ok {
+				t = finCita(cita)
+				continue
+			}
+			if !t.Before(ahora) {
+				espacios = append(espacios, Espacio{t, t.Add(duracion)})
+			}
+			t = t.Add(duracion)
+		}
+	}
+	return espacios, nil
+}
+
+// agendar completes the duracion of the cita and, when it is agendada or confirmada, checks it is within the
+// horario of its veterinario and does not overlap the citas of its veterinario or its sala.
+func (s service) agendar(ctx context.Context, cita *entity.CitaMedica) error {
+	duracion, err := s.duracion(ctx, cita.IdTipoCita, cita.DuracionMinutos)
+	if err != nil {
+		return err
+	}
+	cita.DuracionMinutos = duracion
+	if !Activa(cita.Estado) || (cita.IdUsuario == nil && cita.Sala == nil) {
+		return nil
+	}
+	//Los horarios son de la hora local de la veterinaria
+	inicio := cita.Fecha.Local()
+	fin := finCita(*cita).Local()
+	if cita.IdUsuario != nil {
+		//Dos citas simultaneas del mismo veterinario no pasan juntas la revision de cruces
+		if err := s.repo.BloquearVeterinario(ctx, *cita.IdUsuario); err != nil {
+			return err
+		}
+		horarios, err := s.repo.GetHorarios(ctx, *cita.IdUsuario, int(inicio.Weekday()))
+		if err != nil {
+			return err
+		}
+		dentro := false
+		for _, horario := range horarios {
+			apertura, cierre, err := horario_veterinario.Intervalo(horario, inicio)
+			if err != nil {
+				return err
+			}
+			if !inicio.Before(apertura) && !fin.After(cierre) {
+				dentro = true
+				break
+			}
+		}
+		if !dentro {
+			return errors.Conflict("La cita está fuera del horario de atención del veterinario.")
+		}
+	}
+	solapadas, err := s.repo.GetCitasSolapadas(ctx, *cita)
+	if err != nil {
+		return err
+	}
+	for _, otra := range solapadas {
+		horas := otra.Fecha.Local().Format("15:04") + " a " + finCita(otra).Local().Format("15:04")
+		if cita.IdUsuario != nil && otra.IdUsuario != nil && *otra.IdUsuario == *cita.IdUsuario {
+			return errors.Conflict(fmt.Sprintf("El veterinario ya tiene la cita %d de %s.", otra.IdCitaMedica, horas))
+		}
+		return errors.Conflict(fmt.Sprintf("La sala %s ya está ocupada por la cita %d de %s.", *cita.Sala, otra.IdCitaMedica, horas))
+	}
+	return nil
+}
+
+// duracion returns the duracion of a cita, the one of its tipo de cita when it has none.
+func (s service) duracion(ctx context.Context, idTipoCita *int, duracionMinutos int) (int, error) {
+	if duracionMinutos > 0 {
+		return duracionMinutos, nil
+	}
+	if idTipoCita == nil {
+		return DuracionPredeterminada, nil
+	}
+	tipoCita, err := s.repo.GetTipoCitaPorId(ctx, *idTipoCita)
+	if err != nil || tipoCita.DuracionMinutos <= 0 {
+		return DuracionPredeterminada, err
+	}
+	return tipoCita.DuracionMinutos, nil
+}
+
+// cruce returns the first of the citas that overlaps the time from inicio to fin.
+func cruce(citas []entity.CitaMedica, inicio, fin time.Time) (entity.CitaMedica, bool) {
+	for _, cita := range citas {
+		if cita.Fecha.Before(fin) && finCita(cita).After(inicio) {
+			return cita, true
+		}
+	}
+	return entity.CitaMedica{}, false
+}
+
+func finCita(cita entity.CitaMedica) time.Time {
+	return cita.Fecha.Add(time.Duration(cita.DuracionMinutos) * time.Minute)
+}
+
+// sala returns nil for an empty sala, so it is not checked for overlaps.
+func sala(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	limpia := strings.TrimSpace(*s)
+	return &limpia
+}
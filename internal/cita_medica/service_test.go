@@ -0,0 +1,263 @@
+package cita_medica
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lunes is a day the veterinario 1 attends from 08:00 to 12:00 and from 14:00 to 17:00.
+var lunes = time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+
+func nuevoMockRepository() *mockRepository {
+	return &mockRepository{
+		tiposCita: []entity.TipoCita{{IdTipoCita: 4, Descripcion: "Cirugía", DuracionMinutos: 120}},
+		horarios: []entity.HorarioVeterinario{
+			{IdHorarioVeterinario: 1, IdUsuario: 1, DiaSemana: 1, HoraInicio: "08:00:00", HoraFin: "12:00:00", Activo: true},
+			{IdHorarioVeterinario: 2, IdUsuario: 1, DiaSemana: 1, HoraInicio: "14:00:00", HoraFin: "17:00:00", Activo: true},
+		},
+	}
+}
+
+func Test_service_CrearCitaMedica(t *testing.T) {
+	logger, _ := log.NewForTest()
+	veterinario, otro, cirugia := 1, 2, 4
+	quirofano := " Quirófano "
+	hora := func(h, m int) time.Time { return lunes.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	tests := []struct {
+		name     string
+		req      CreateCitaMedicaRequest
+		wantErr  int
+		duracion int
+	}{
+		{"sin veterinario", CreateCitaMedicaRequest{IdMascota: 1, Motivo: "Control", Fecha: hora(20, 0)}, 0, DuracionPredeterminada},
+		{"en horario", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, Motivo: "Control", Fecha: hora(9, 0)}, 0, 30},
+		{"duracion del tipo", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, IdTipoCita: &cirugia, Motivo: "Esterilización", Fecha: hora(14, 0)}, 0, 120},
+		{"termina fuera del horario", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, IdTipoCita: &cirugia, Motivo: "Esterilización", Fecha: hora(11, 0)}, http.StatusConflict, 0},
+		{"dia sin horario", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, Motivo: "Control", Fecha: hora(24+9, 0)}, http.StatusConflict, 0},
+		{"cruce con otra cita", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, Motivo: "Control", Fecha: hora(10, 15)}, http.StatusConflict, 0},
+		{"despues de otra cita", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &veterinario, Motivo: "Control", Fecha: hora(10, 30), DuracionMinutos: 15}, 0, 15},
+		{"sala ocupada", CreateCitaMedicaRequest{IdMascota: 1, Motivo: "Curación", Fecha: hora(10, 0), Sala: &quirofano}, http.StatusConflict, 0},
+		{"veterinario sin horario", CreateCitaMedicaRequest{IdMascota: 1, IdUsuario: &otro, Motivo: "Control", Fecha: hora(10, 0)}, http.StatusConflict, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := nuevoMockRepository()
+			sala := "Quirófano"
+			repo.citas = []entity.CitaMedica{
+				{IdCitaMedica: 1, IdMascota: 2, IdUsuario: &veterinario, Fecha: hora(10, 0), DuracionMinutos: 30, Sala: &sala, Estado: EstadoAgendada},
+				{IdCitaMedica: 2, IdMascota: 2, IdUsuario: &veterinario, Fecha: hora(9, 0), DuracionMinutos: 30, Estado: EstadoCancelada},
+			}
+			s := NewService(repo, logger)
+			cita, err := s.CrearCitaMedica(context.Background(), tt.req)
+			if tt.wantErr != 0 {
+				if assert.IsType(t, errors.ErrorResponse{}, err) {
+					assert.Equal(t, tt.wantErr, err.(errors.ErrorResponse).StatusCode())
+				}
+				return
+			}
+			if assert.Nil(t, err) {
+				assert.Equal(t, EstadoAgendada, cita.Estado)
+				assert.Equal(t, tt.duracion, cita.DuracionMinutos)
+				if tt.req.IdUsuario != nil {
+					assert.Equal(t, []int{*tt.req.IdUsuario}, repo.bloqueados)
+				}
+			}
+		})
+	}
+}
+
+func Test_service_ActualizarCitaMedica(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := nuevoMockRepository()
+	repo.citas = []entity.CitaMedica{
+		{IdCitaMedica: 1, IdMascota: 1, Fecha: lunes.Add(9 * time.Hour), DuracionMinutos: 30, Estado: EstadoConfirmada, EstadoConfirmacion: ConfirmacionConfirmada},
+	}
+	s := NewService(repo, logger)
+	ctx := context.Background()
+
+	cita, err := s.ActualizarCitaMedica(ctx, UpdateCitaMedicaRequest{IdCitaMedica: 1, IdMascota: 1, Motivo: "Control anual", Fecha: lunes.Add(9 * time.Hour)})
+	if assert.Nil(t, err) {
+		assert.Equal(t, EstadoConfirmada, cita.Estado)
+		assert.Equal(t, ConfirmacionConfirmada, cita.EstadoConfirmacion)
+	}
+
+	//Una cita que cambia de fecha vuelve a estar agendada
+	cita, err = s.ActualizarCitaMedica(ctx, UpdateCitaMedicaRequest{IdCitaMedica: 1, IdMascota: 1, Motivo: "Control anual", Fecha: lunes.Add(10 * time.Hour)})
+	if assert.Nil(t, err) {
+		assert.Equal(t, EstadoAgendada, cita.Estado)
+		assert.Equal(t, ConfirmacionPendiente, cita.EstadoConfirmacion)
+	}
+}
+
+func Test_service_CambiarEstado(t *testing.T) {
+	logger, _ := log.NewForTest()
+	tests := []struct {
+		desde, hacia string
+		wantErr      bool
+	}{
+		{EstadoAgendada, EstadoConfirmada, false},
+		{EstadoAgendada, EstadoNoAsistio, false},
+		{EstadoConfirmada, EstadoAtendida, false},
+		{EstadoConfirmada, EstadoCancelada, false},
+		{EstadoConfirmada, EstadoAgendada, true},
+		{EstadoAtendida, EstadoCancelada, true},
+		{EstadoCancelada, EstadoConfirmada, true},
+		{EstadoAgendada, "REPROGRAMADA", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desde+" a "+tt.hacia, func(t *testing.T) {
+			repo := nuevoMockRepository()
+			repo.citas = []entity.CitaMedica{{IdCitaMedica: 1, IdMascota: 1, Fecha: lunes, Estado: tt.desde}}
+			cita, err := NewService(repo, logger).CambiarEstado(context.Background(), 1, CambiarEstadoRequest{Estado: tt.hacia})
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.hacia, cita.Estado)
+				assert.Equal(t, tt.hacia, repo.citas[0].Estado)
+			}
+		})
+	}
+}
+
+func Test_service_GetDisponibilidad(t *testing.T) {
+	logger, _ := log.NewForTest()
+	veterinario, cirugia := 1, 4
+	repo := nuevoMockRepository()
+	repo.citas = []entity.CitaMedica{
+		{IdCitaMedica: 1, IdUsuario: &veterinario, Fecha: lunes.Add(9 * time.Hour), DuracionMinutos: 45, Estado: EstadoAgendada},
+		{IdCitaMedica: 2, IdUsuario: &veterinario, Fecha: lunes.Add(11 * time.Hour), DuracionMinutos: 30, Estado: EstadoCancelada},
+		{IdCitaMedica: 3, IdUsuario: &veterinario, Fecha: lunes.Add(15 * time.Hour), DuracionMinutos: 30, Estado: EstadoConfirmada},
+	}
+	s := NewService(repo, logger).(service)
+	s.now = func() time.Time { return lunes.Add(8*time.Hour + 10*time.Minute) }
+	ctx := context.Background()
+
+	espacios, err := s.GetDisponibilidad(ctx, veterinario, lunes, nil, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"09:45", "10:45", "14:00", "15:30"}, inicios(espacios))
+	assert.Equal(t, lunes.Add(10*time.Hour+45*time.Minute), espacios[0].Fin)
+
+	espacios, err = s.GetDisponibilidad(ctx, veterinario, lunes, &cirugia, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"09:45"}, inicios(espacios))
+
+	espacios, err = s.GetDisponibilidad(ctx, veterinario, lunes.AddDate(0, 0, 1), nil, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, espacios)
+}
+
+func inicios(espacios []Espacio) []string {
+	result := []string{}
+	for _, espacio := range espacios {
+		result = append(result, espacio.Inicio.Format("15:04"))
+	}
+	return result
+}
+
+type mockRepository struct {
+	citas      []entity.CitaMedica
+	tiposCita  []entity.TipoCita
+	horarios   []entity.HorarioVeterinario
+	bloqueados []int //Veterinarios bloqueados por BloquearVeterinario
+}
+
+func (m *mockRepository) GetCitaMedicaPorId(ctx context.Context, idCitaMedica int) (entity.CitaMedica, error) {
+	for _, cita := range m.citas {
+		if cita.IdCitaMedica == idCitaMedica {
+			return cita, nil
+		}
+	}
+	return entity.CitaMedica{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetCitasMedica(ctx context.Context, query pagination.Query) ([]entity.CitaMedica, *pagination.Pages, error) {
+	return m.citas, pagination.New(query.Page, query.PerPage, len(m.citas)), nil
+}
+
+func (m *mockRepository) GetCitasMedicaPendientes(ctx context.Context) ([]entity.CitaMedica, error) {
+	return m.citas, nil
+}
+
+func (m *mockRepository) GetCitasMedicaSinNotificar(ctx context.Context) ([]CitaMedicaDatos, error) {
+	return []CitaMedicaDatos{}, nil
+}
+
+func (m *mockRepository) CrearCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error) {
+	citaMedica.IdCitaMedica = len(m.citas) + 1
+	m.citas = append(m.citas, citaMedica)
+	return citaMedica, nil
+}
+
+func (m *mockRepository) ActualizarCitaMedica(ctx context.Context, citaMedica entity.CitaMedica) (entity.CitaMedica, error) {
+	for i := range m.citas {
+		if m.citas[i].IdCitaMedica == citaMedica.IdCitaMedica {
+			m.citas[i] = citaMedica
+			return citaMedica, nil
+		}
+	}
+	return m.CrearCitaMedica(ctx, citaMedica)
+}
+
+func (m *mockRepository) ActualizarEstado(ctx context.Context, citaMedica entity.CitaMedica) error {
+	for i := range m.citas {
+		if m.citas[i].IdCitaMedica == citaMedica.IdCitaMedica {
+			m.citas[i].Estado = citaMedica.Estado
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) GetTipoCitaPorId(ctx context.Context, idTipoCita int) (entity.TipoCita, error) {
+	for _, tipoCita := range m.tiposCita {
+		if tipoCita.IdTipoCita == idTipoCita {
+			return tipoCita, nil
+		}
+	}
+	return entity.TipoCita{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetHorarios(ctx context.Context, idUsuario int, diaSemana int) ([]entity.HorarioVeterinario, error) {
+	result := []entity.HorarioVeterinario{}
+	for _, horario := range m.horarios {
+		if horario.IdUsuario == idUsuario && horario.DiaSemana == diaSemana && horario.Activo {
+			result = append(result, horario)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetCitasVeterinario(ctx context.Context, idUsuario int, desde, hasta time.Time) ([]entity.CitaMedica, error) {
+	result := []entity.CitaMedica{}
+	for _, cita := range m.citas {
+		if cita.IdUsuario != nil && *cita.IdUsuario == idUsuario && Activa(cita.Estado) && cita.Fecha.Before(hasta) && finCita(cita).After(desde) {
+			result = append(result, cita)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) BloquearVeterinario(ctx context.Context, idUsuario int) error {
+	m.bloqueados = append(m.bloqueados, idUsuario)
+	return nil
+}
+
+func (m *mockRepository) GetCitasSolapadas(ctx context.Context, citaMedica entity.CitaMedica) ([]entity.CitaMedica, error) {
+	result := []entity.CitaMedica{}
+	for _, cita := range m.citas {
+		mismoVeterinario := citaMedica.IdUsuario != nil && cita.IdUsuario != nil && *cita.IdUsuario == *citaMedica.IdUsuario
+		mismaSala := citaMedica.Sala != nil && cita.Sala != nil && *cita.Sala == *citaMedica.Sala
+		if cita.IdCitaMedica != citaMedica.IdCitaMedica && (mismoVeterinario || mismaSala) && Activa(cita.Estado) &&
+			cita.Fecha.Before(finCita(citaMedica)) && finCita(cita).After(citaMedica.Fecha) {
+			result = append(result, cita)
+		}
+	}
+	return result, nil
+}
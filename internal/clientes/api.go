@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -27,11 +28,12 @@ type resource struct {
 }
 
 func (r resource) getClientes(c *routing.Context) error {
-	clientes, err := r.service.GetClientes(c.Request.Context())
+	pages, err := r.service.GetClientes(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(clientes)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearCliente(c *routing.Context) error {
@@ -3,9 +3,11 @@ package clientes
 import (
 	"context"
 	"database/sql"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -16,7 +18,7 @@ type Repository interface {
 	GetClientePorId(ctx context.Context, idCliente int) (entity.Cliente, error)
 	GetClientePorCedula(ctx context.Context, cedula string) (entity.Cliente, error)
 	// GetClientes returns the list clientes.
-	GetClientes(ctx context.Context) ([]entity.Cliente, error)
+	GetClientes(ctx context.Context, query pagination.Query) ([]entity.Cliente, *pagination.Pages, error)
 	CrearCliente(ctx context.Context, cliente entity.Cliente) (entity.Cliente, error)
 	ActualizarCliente(ctx context.Context, cliente entity.Cliente) (entity.Cliente, error)
 }
@@ -32,25 +34,37 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposClientes are the fields the list of clientes can be filtered, searched and sorted by.
+var camposClientes = pagination.Fields{
+	Search: []string{"nombres", "apellidos", "cedula", "correo", "telefono", "direccion", "nacionalidad"},
+	Sort: map[string]string{
+		"id_cliente":   "id_cliente",
+		"nombres":      "nombres",
+		"apellidos":    "apellidos",
+		"cedula":       "cedula",
+		"correo":       "correo",
+		"telefono":     "telefono",
+		"direccion":    "direccion",
+		"nacionalidad": "nacionalidad",
+	},
+	DefaultSort: []string{"apellidos asc"},
+}
+
 // Get reads the list clientes from the database.
-func (r repository) GetClientes(ctx context.Context) ([]entity.Cliente, error) {
-	var clientes []entity.Cliente
+func (r repository) GetClientes(ctx context.Context, query pagination.Query) ([]entity.Cliente, *pagination.Pages, error) {
+	var clientes []entity.Cliente = []entity.Cliente{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		OrderBy("apellidos asc").
-		All(&clientes)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("clientes"), camposClientes, &clientes)
 	if err != nil {
-		return clientes, err
+		return nil, nil, err
 	}
-	return clientes, err
+	return clientes, pages, nil
 }
 
 // Create saves a new Cliente record in the database.
 // It returns the ID of the newly inserted cliente record.
 func (r repository) CrearCliente(ctx context.Context, cliente entity.Cliente) (entity.Cliente, error) {
-	err := r.db.With(ctx).Model(&cliente).Insert()
+	err := auditoria.Insertar(ctx, r.db, &cliente)
 	if err != nil {
 		return entity.Cliente{}, err
 	}
@@ -62,9 +76,9 @@ func (r repository) CrearCliente(ctx context.Context, cliente entity.Cliente) (e
 func (r repository) ActualizarCliente(ctx context.Context, cliente entity.Cliente) (entity.Cliente, error) {
 	var err error
 	if cliente.IdCliente != 0 {
-		err = r.db.With(ctx).Model(&cliente).Update()
+		err = auditoria.Actualizar(ctx, r.db, &cliente)
 	} else {
-		err = r.db.With(ctx).Model(&cliente).Insert()
+		err = auditoria.Insertar(ctx, r.db, &cliente)
 	}
 	if err != nil {
 		return entity.Cliente{}, err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -11,7 +12,7 @@ import (
 
 // Service encapsulates usecase logic for clientes.
 type Service interface {
-	GetClientes(ctx context.Context) ([]Cliente, error)
+	GetClientes(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetClientePorId(ctx context.Context, idCliente int) (Cliente, error)
 	GetClientePorCedula(ctx context.Context, cedula string) (Cliente, error)
 	CrearCliente(ctx context.Context, input CreateClienteRequest) (Cliente, error)
@@ -34,8 +35,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list clientes.
-func (s service) GetClientes(ctx context.Context) ([]Cliente, error) {
-	clientes, err := s.repo.GetClientes(ctx)
+func (s service) GetClientes(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	clientes, pages, err := s.repo.GetClientes(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +44,8 @@ func (s service) GetClientes(ctx context.Context) ([]Cliente, error) {
 	for _, item := range clientes {
 		result = append(result, Cliente{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateClienteRequest represents an cliente creation request.
@@ -6,9 +6,11 @@ import (
 	"veterinaria-server/internal/detalle_compra"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/internal/lote"
+	"veterinaria-server/internal/movimiento_inventario"
 	"veterinaria-server/internal/stock_individual"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -33,11 +35,12 @@ type resource struct {
 }
 
 func (r resource) getCompras(c *routing.Context) error {
-	compras, err := r.service.GetCompras(c.Request.Context())
+	pages, err := r.service.GetCompras(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(compras)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getComprasConDatos(c *routing.Context) error {
@@ -95,22 +98,48 @@ func (r resource) crearCompraConDetalles(c *routing.Context) error {
 		return err
 	}
 	detallesComprasConLoteG := []DetallesComprasConLote{}
+	origen := "compras"
 	//Guardar detalles compra
 	for i := 0; i < len(input.DetallesCompras); i++ {
-		s := lote.NewService(lote.NewRepository(r.db, r.logger), r.logger)
+		sm := movimiento_inventario.NewService(movimiento_inventario.NewRepository(r.db, r.logger), r.logger)
+		s := lote.NewService(lote.NewRepository(r.db, r.logger), sm, r.logger)
 		loteG, err := s.CrearLote(c.Request.Context(), input.DetallesCompras[i].Lote)
 		if err != nil {
 			return err
 		}
+		if loteG.Stock != 0 {
+			_, err = sm.RegistrarEntrada(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+				Tabla:        movimiento_inventario.TablaLote,
+				IdReferencia: loteG.IdLote,
+				Tipo:         movimiento_inventario.TipoEntradaCompra,
+				Cantidad:     float32(loteG.Stock),
+				Origen:       &origen,
+				IdOrigen:     &compraG.IdCompra,
+			})
+			if err != nil {
+				return err
+			}
+		}
 
 		stockIndiidualesG := []stock_individual.StockIndividual{}
 		for j := 0; j < len(input.DetallesCompras[i].StocksIndividuales); j++ {
 			input.DetallesCompras[i].StocksIndividuales[j].IdLote = loteG.IdLote
-			s2 := stock_individual.NewService(stock_individual.NewRepository(r.db, r.logger), r.logger)
+			s2 := stock_individual.NewService(stock_individual.NewRepository(r.db, r.logger), sm, r.logger)
 			stockIndividualG, err := s2.CrearStockIndividual(c.Request.Context(), input.DetallesCompras[i].StocksIndividuales[j])
 			if err != nil {
 				return err
 			}
+			_, err = sm.RegistrarEntrada(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+				Tabla:        movimiento_inventario.TablaStockIndividual,
+				IdReferencia: stockIndividualG.IdStockIndividual,
+				Tipo:         movimiento_inventario.TipoEntradaCompra,
+				Cantidad:     stockIndividualG.Cantidad,
+				Origen:       &origen,
+				IdOrigen:     &compraG.IdCompra,
+			})
+			if err != nil {
+				return err
+			}
 			stockIndiidualesG = append(stockIndiidualesG, stockIndividualG)
 		}
 		input.DetallesCompras[i].DetalleCompra.IdCompra = compraG.IdCompra
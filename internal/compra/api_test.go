@@ -0,0 +1,86 @@
+package compra
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_crearCompraConDetalles(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), logger), auth.MockAuthHandler, logger, db)
+
+	tests := []struct {
+		caso              test.APITestCase
+		compras           int
+		lotes             dbx.HashExp //Lote que debe quedar registrado
+		stockIndividuales int
+		movimientos       int
+	}{
+		{
+			caso: test.APITestCase{
+				Name:         "lote por unidades",
+				Method:       "POST",
+				URL:          "/compras/conDetalle",
+				Body:         `{"compra":{"id_proveedor":1,"fecha":"2026-10-01T10:00:00Z","valor":60},"detalles_compra":[{"lote":{"id_proveedor_producto":1,"stock":10,"descripcion":"L-100","fecha_caducidad":"2099-01-31T00:00:00Z","codigo_barra":"7861234"},"detalle_compra":{"cantidad":10,"valor":60}}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"codigo_barra":"7861234"*`,
+			},
+			compras:           1,
+			lotes:             dbx.HashExp{"id_proveedor_producto": 1, "stock": 10, "codigo_barra": "7861234"},
+			stockIndividuales: 0,
+			movimientos:       1,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "lote por medida",
+				Method:       "POST",
+				URL:          "/compras/conDetalle",
+				Body:         `{"compra":{"id_proveedor":1,"fecha":"2026-10-01T10:00:00Z","valor":36},"detalles_compra":[{"lote":{"id_proveedor_producto":2,"stock":2,"descripcion":"V-200","fecha_caducidad":"2099-01-31T00:00:00Z"},"detalle_compra":{"cantidad":2,"valor":36},"stock_individuales":[{"descripcion":"V-200 - unidad 1","cantidad":100,"cantidad_inicial":100},{"descripcion":"V-200 - unidad 2","cantidad":100,"cantidad_inicial":100}]}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"descripcion":"V-200 - unidad 2"*`,
+			},
+			compras:           1,
+			lotes:             dbx.HashExp{"id_proveedor_producto": 2, "stock": 2},
+			stockIndividuales: 2,
+			movimientos:       3,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "lote sin descripcion",
+				Method:       "POST",
+				URL:          "/compras/conDetalle",
+				Body:         `{"compra":{"id_proveedor":1,"fecha":"2026-10-01T10:00:00Z","valor":60},"detalles_compra":[{"lote":{"id_proveedor_producto":1,"stock":10},"detalle_compra":{"cantidad":10,"valor":60}}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusBadRequest,
+				WantResponse: "",
+			},
+			compras:           0,
+			lotes:             nil,
+			stockIndividuales: 0,
+			movimientos:       0,
+		},
+	}
+	for _, tc := range tests {
+		test.Fixtures(t, db, "testdata/fixtures.sql")
+		test.Endpoint(t, router, tc.caso)
+		assert.Equal(t, tc.compras, test.Count(t, db, "compras", nil), tc.caso.Name)
+		if tc.lotes != nil {
+			assert.Equal(t, 1, test.Count(t, db, "lote", tc.lotes), tc.caso.Name)
+		} else {
+			assert.Equal(t, 0, test.Count(t, db, "lote", nil), tc.caso.Name)
+		}
+		assert.Equal(t, tc.compras, test.Count(t, db, "detalles_compra", nil), tc.caso.Name)
+		assert.Equal(t, tc.stockIndividuales, test.Count(t, db, "stock_individual", nil), tc.caso.Name)
+		assert.Equal(t, tc.movimientos, test.Count(t, db, "movimientos_inventario", dbx.HashExp{"tipo": "entrada_compra"}), tc.caso.Name)
+	}
+}
@@ -2,9 +2,11 @@ package compra
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetCompraPorId returns the compra with the specified compra ID.
 	GetCompraPorId(ctx context.Context, idCompra int) (entity.Compras, error)
 	// GetCompras returns the list compras.
-	GetCompras(ctx context.Context) ([]entity.Compras, error)
+	GetCompras(ctx context.Context, query pagination.Query) ([]entity.Compras, *pagination.Pages, error)
 	GetComprasConDatos(ctx context.Context) ([]ComprasConDatos, error)
 	CrearCompra(ctx context.Context, compra entity.Compras) (entity.Compras, error)
 	ActualizarCompra(ctx context.Context, compra entity.Compras) (entity.Compras, error)
@@ -31,18 +33,31 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposCompras are the fields the list of compras can be filtered, searched and sorted by.
+var camposCompras = pagination.Fields{
+	Filters: map[string]string{"id_usuario": "id_usuario", "id_proveedor": "id_proveedor"},
+	Ranges:  map[string]string{"fecha": "fecha"},
+	Search:  []string{"descripcion"},
+	Sort: map[string]string{
+		"id_compra":    "id_compra",
+		"id_usuario":   "id_usuario",
+		"id_proveedor": "id_proveedor",
+		"fecha":        "fecha",
+		"valor":        "valor",
+		"descripcion":  "descripcion",
+	},
+	DefaultSort: []string{"id_compra asc"},
+}
+
 // Get reads the list compras from the database.
-func (r repository) GetCompras(ctx context.Context) ([]entity.Compras, error) {
-	var compras []entity.Compras
+func (r repository) GetCompras(ctx context.Context, query pagination.Query) ([]entity.Compras, *pagination.Pages, error) {
+	var compras []entity.Compras = []entity.Compras{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&compras)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("compras"), camposCompras, &compras)
 	if err != nil {
-		return compras, err
+		return nil, nil, err
 	}
-	return compras, err
+	return compras, pages, nil
 }
 
 func (r repository) GetComprasConDatos(ctx context.Context) ([]ComprasConDatos, error) {
@@ -91,7 +106,7 @@ func (r repository) GetComprasConDatos(ctx context.Context) ([]ComprasConDatos,
 // Create saves a new Compras record in the database.
 // It returns the ID of the newly inserted compra record.
 func (r repository) CrearCompra(ctx context.Context, compra entity.Compras) (entity.Compras, error) {
-	err := r.db.With(ctx).Model(&compra).Insert()
+	err := auditoria.Insertar(ctx, r.db, &compra)
 	if err != nil {
 		return entity.Compras{}, err
 	}
@@ -103,9 +118,9 @@ func (r repository) CrearCompra(ctx context.Context, compra entity.Compras) (ent
 func (r repository) ActualizarCompra(ctx context.Context, compra entity.Compras) (entity.Compras, error) {
 	var err error
 	if compra.IdCompra != 0 {
-		err = r.db.With(ctx).Model(&compra).Update()
+		err = auditoria.Actualizar(ctx, r.db, &compra)
 	} else {
-		err = r.db.With(ctx).Model(&compra).Insert()
+		err = auditoria.Insertar(ctx, r.db, &compra)
 	}
 	if err != nil {
 		return entity.Compras{}, err
@@ -3,18 +3,20 @@ package compra
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/detalle_compra"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/internal/lote"
 	"veterinaria-server/internal/stock_individual"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for compras.
 type Service interface {
-	GetCompras(ctx context.Context) ([]Compras, error)
+	GetCompras(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetComprasConDatos(ctx context.Context) ([]ComprasConDatos, error)
 	GetCompraPorId(ctx context.Context, idCompra int) (Compras, error)
 	CrearCompra(ctx context.Context, input CreateCompraRequest) (Compras, error)
@@ -43,8 +45,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list compras.
-func (s service) GetCompras(ctx context.Context) ([]Compras, error) {
-	compras, err := s.repo.GetCompras(ctx)
+func (s service) GetCompras(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	compras, pages, err := s.repo.GetCompras(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +54,8 @@ func (s service) GetCompras(ctx context.Context) ([]Compras, error) {
 	for _, item := range compras {
 		result = append(result, Compras{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetComprasConDatos(ctx context.Context) ([]ComprasConDatos, error) {
@@ -114,6 +117,11 @@ func (m CreateCompraRequest) Validate() error {
 
 // CrearCompra creates a new compra.
 func (s service) CrearCompra(ctx context.Context, req CreateCompraRequest) (Compras, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return Compras{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return Compras{}, err
 	}
@@ -132,6 +140,19 @@ func (s service) CrearCompra(ctx context.Context, req CreateCompraRequest) (Comp
 
 // ActualizarCompra creates a new compra.
 func (s service) ActualizarCompra(ctx context.Context, req UpdateCompraRequest) (Compras, error) {
+	var idGuardado int
+	if req.IdCompra != 0 {
+		compraBD, err := s.repo.GetCompraPorId(ctx, req.IdCompra)
+		if err != nil {
+			return Compras{}, err
+		}
+		idGuardado = compraBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return Compras{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return Compras{}, err
 	}
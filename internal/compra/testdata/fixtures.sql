@@ -0,0 +1,21 @@
+-- Un proveedor que entrega un producto por unidad y otro por medida.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO medida (id_medida, descripcion) VALUES
+    (1, 'Volumen');
+
+INSERT INTO unidad (id_unidad, id_medida, descripcion) VALUES
+    (1, 1, 'ml');
+
+INSERT INTO producto (id_producto, descripcion, precio_venta, iva, uso_interno, venta_publico, por_medida, stock_minimo, id_unidad, contenido) VALUES
+    (1, 'Antiparasitario', 10.00, 1, 0, 1, 0, 0, NULL, NULL),
+    (2, 'Vacuna múltiple', 25.00, 0, 1, 0, 1, 0, 1, 100.000);
+
+INSERT INTO proveedor (id_proveedor, descripcion) VALUES
+    (1, 'Distribuidora Veterinaria');
+
+INSERT INTO proveedor_producto (id_proveedor_producto, id_proveedor, id_producto, precio_compra) VALUES
+    (1, 1, 1, 6.00),
+    (2, 1, 2, 18.00);
@@ -0,0 +1,65 @@
+package comprobante_electronico
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"veterinaria-server/pkg/log"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/facturas/<idFactura>/comprobanteElectronico", res.getComprobantePorFactura)
+	r.Get("/facturas/<idFactura>/comprobanteElectronico/xml", res.getXmlComprobante)
+	r.Post("/facturas/<idFactura>/comprobanteElectronico", res.generarComprobante)
+	r.Post("/facturas/<idFactura>/comprobanteElectronico/enviar", res.enviarComprobante)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getComprobantePorFactura(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	comprobante, err := r.service.GetComprobantePorFactura(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	return c.Write(comprobante)
+}
+
+func (r resource) getXmlComprobante(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	comprobante, err := r.service.GetComprobantePorFactura(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.Response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.xml\"", comprobante.ClaveAcceso))
+	_, err = c.Response.Write([]byte(comprobante.Xml))
+	return err
+}
+
+func (r resource) generarComprobante(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	comprobante, err := r.service.GenerarComprobante(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(comprobante, http.StatusCreated)
+}
+
+func (r resource) enviarComprobante(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	comprobante, err := r.service.EnviarComprobante(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	return c.Write(comprobante)
+}
@@ -0,0 +1,130 @@
+package comprobante_electronico
+
+import (
+	"context"
+	"database/sql"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/detalle_factura"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access comprobantesElectronicos from the data source.
+type Repository interface {
+	// GetComprobantePorFactura returns the comprobanteElectronico of the specified factura.
+	GetComprobantePorFactura(ctx context.Context, idFactura int) (entity.ComprobanteElectronico, error)
+	CrearComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error)
+	ActualizarComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error)
+	// SiguienteSecuencial locks the numbering of the establecimiento and punto de emisión and returns its next secuencial.
+	SiguienteSecuencial(ctx context.Context, codDoc string, establecimiento string, puntoEmision string) (int, error)
+	GetFactura(ctx context.Context, idFactura int) (entity.Factura, error)
+	GetCliente(ctx context.Context, idCliente int) (entity.Cliente, error)
+	// GetDetallesComprobante returns the detalles of the factura with the producto they were taken from.
+	GetDetallesComprobante(ctx context.Context, idFactura int) ([]DetalleComprobante, error)
+}
+
+// repository persists comprobantesElectronicos in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new comprobanteElectronico repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+func (r repository) GetComprobantePorFactura(ctx context.Context, idFactura int) (entity.ComprobanteElectronico, error) {
+	var comprobante entity.ComprobanteElectronico
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_factura": idFactura}).
+		One(&comprobante)
+	return comprobante, err
+}
+
+// CrearComprobante saves a new ComprobanteElectronico record in the database.
+// It returns the comprobante with the ID of the newly inserted record.
+func (r repository) CrearComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error) {
+	err := auditoria.Insertar(ctx, r.db, &comprobante)
+	if err != nil {
+		return entity.ComprobanteElectronico{}, err
+	}
+	return comprobante, nil
+}
+
+func (r repository) ActualizarComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error) {
+	err := auditoria.Actualizar(ctx, r.db, &comprobante)
+	if err != nil {
+		return entity.ComprobanteElectronico{}, err
+	}
+	return comprobante, nil
+}
+
+func (r repository) SiguienteSecuencial(ctx context.Context, codDoc string, establecimiento string, puntoEmision string) (int, error) {
+	var secuencial entity.SecuencialComprobante
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM secuenciales_comprobante WHERE cod_doc = {:codDoc} AND establecimiento = {:establecimiento} AND punto_emision = {:puntoEmision} FOR UPDATE").
+		Bind(dbx.Params{"codDoc": codDoc, "establecimiento": establecimiento, "puntoEmision": puntoEmision}).
+		One(&secuencial)
+	if err == sql.ErrNoRows {
+		//Primer comprobante del punto de emisión
+		secuencial = entity.SecuencialComprobante{
+			CodDoc:          codDoc,
+			Establecimiento: establecimiento,
+			PuntoEmision:    puntoEmision,
+			Secuencial:      1,
+		}
+		return secuencial.Secuencial, auditoria.Insertar(ctx, r.db, &secuencial)
+	}
+	if err != nil {
+		return 0, err
+	}
+	secuencial.Secuencial++
+	return secuencial.Secuencial, auditoria.Actualizar(ctx, r.db, &secuencial, "Secuencial")
+}
+
+func (r repository) GetFactura(ctx context.Context, idFactura int) (entity.Factura, error) {
+	var factura entity.Factura
+	err := r.db.With(ctx).Select().Model(idFactura, &factura)
+	return factura, err
+}
+
+func (r repository) GetCliente(ctx context.Context, idCliente int) (entity.Cliente, error) {
+	var cliente entity.Cliente
+	err := r.db.With(ctx).Select().Model(idCliente, &cliente)
+	return cliente, err
+}
+
+func (r repository) GetDetallesComprobante(ctx context.Context, idFactura int) ([]DetalleComprobante, error) {
+	var detalles []DetalleComprobante = []DetalleComprobante{}
+	err := r.db.With(ctx).
+		Select("d.id_detalle_factura", "d.cantidad", "d.precio_unitario", "d.descuento", "d.subtotal", "d.porcentaje_iva", "d.valor_iva",
+			"coalesce(p.id_producto, 0) as id_producto",
+			"coalesce(d.descripcion, p.descripcion, d.tabla) as descripcion").
+		From("detalles_factura d").
+		LeftJoin("stock_individual si", dbx.And(
+			dbx.NewExp("d.tabla <> 'lote' and si.id_stock_individual = d.id_referencia"),
+			dbx.NotIn("d.tabla", tablasSinInventario()...))).
+		LeftJoin("lote l", dbx.NewExp("l.id_lote = (case when d.tabla = 'lote' then d.id_referencia else si.id_lote end)")).
+		LeftJoin("proveedor_producto pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
+		LeftJoin("producto p", dbx.NewExp("p.id_producto = pp.id_producto")).
+		Where(dbx.HashExp{"d.id_factura": idFactura}).
+		OrderBy("d.id_detalle_factura asc").
+		All(&detalles)
+	if err != nil {
+		return []DetalleComprobante{}, err
+	}
+	return detalles, err
+}
+
+func tablasSinInventario() []interface{} {
+	tablas := []interface{}{}
+	for _, tabla := range detalle_factura.TablasSinInventario {
+		tablas = append(tablas, tabla)
+	}
+	return tablas
+}
@@ -0,0 +1,247 @@
+package comprobante_electronico
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/sri"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+)
+
+// EstadoFirmado is the estado of a comprobante that has been signed but not sent to the SRI yet.
+// The remaining estados are the ones returned by the SRI web services.
+const EstadoFirmado = "FIRMADO"
+
+// identificadorClaveRegistrada is the SRI message returned when the clave de acceso was already received.
+const identificadorClaveRegistrada = "43"
+
+// Service encapsulates usecase logic for comprobantesElectronicos.
+type Service interface {
+	GetComprobantePorFactura(ctx context.Context, idFactura int) (ComprobanteElectronico, error)
+	// GenerarComprobante builds the SRI factura XML of the factura with the next secuencial of the
+	// punto de emisión, signs it and stores it.
+	GenerarComprobante(ctx context.Context, idFactura int) (ComprobanteElectronico, error)
+	// EnviarComprobante sends the signed comprobante to the SRI and queries its authorization.
+	EnviarComprobante(ctx context.Context, idFactura int) (ComprobanteElectronico, error)
+}
+
+// ComprobanteElectronico represents the data about a comprobanteElectronico.
+type ComprobanteElectronico struct {
+	entity.ComprobanteElectronico
+}
+
+// DetalleComprobante is a detalleFactura with the producto data printed on the comprobante.
+type DetalleComprobante struct {
+	IdDetalleFactura int         `db:"id_detalle_factura"`
+	Cantidad         float32     `db:"cantidad"`
+	PrecioUnitario   money.Money `db:"precio_unitario"`
+	Descuento        money.Money `db:"descuento"`
+	Subtotal         money.Money `db:"subtotal"`
+	PorcentajeIva    int         `db:"porcentaje_iva"`
+	ValorIva         money.Money `db:"valor_iva"`
+	IdProducto       int         `db:"id_producto"`
+	Descripcion      string      `db:"descripcion"`
+}
+
+type service struct {
+	repo     Repository
+	emisor   sri.Emisor
+	firmador sri.Firmador
+	cliente  sri.Cliente
+	logger   log.Logger
+}
+
+// NewService creates a new comprobanteElectronico service. The firmador may be nil when no
+// certificate is configured, in which case comprobantes can not be generated.
+func NewService(repo Repository, emisor sri.Emisor, firmador sri.Firmador, cliente sri.Cliente, logger log.Logger) Service {
+	return service{repo, emisor, firmador, cliente, logger}
+}
+
+// GetComprobantePorFactura returns the comprobanteElectronico of the specified factura.
+func (s service) GetComprobantePorFactura(ctx context.Context, idFactura int) (ComprobanteElectronico, error) {
+	comprobante, err := s.repo.GetComprobantePorFactura(ctx, idFactura)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	return ComprobanteElectronico{comprobante}, nil
+}
+
+func (s service) GenerarComprobante(ctx context.Context, idFactura int) (ComprobanteElectronico, error) {
+	if s.firmador == nil {
+		return ComprobanteElectronico{}, errors.InternalServerError("No se ha configurado el certificado de firma electrónica.")
+	}
+	_, err := s.repo.GetComprobantePorFactura(ctx, idFactura)
+	if err == nil {
+		return ComprobanteElectronico{}, errors.Conflict("La factura ya tiene un comprobante electrónico.")
+	}
+	if err != sql.ErrNoRows {
+		return ComprobanteElectronico{}, err
+	}
+
+	factura, err := s.repo.GetFactura(ctx, idFactura)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	if factura.Anulada {
+		return ComprobanteElectronico{}, errors.Conflict("La factura está anulada.")
+	}
+	cliente, err := s.repo.GetCliente(ctx, factura.IdCliente)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	detalles, err := s.repo.GetDetallesComprobante(ctx, idFactura)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+
+	secuencial, err := s.repo.SiguienteSecuencial(ctx, sri.CodDocFactura, s.emisor.Establecimiento, s.emisor.PuntoEmision)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	codigoNumerico, err := codigoNumerico()
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	clave := sri.ClaveAcceso{
+		FechaEmision:    factura.Fecha,
+		CodDoc:          sri.CodDocFactura,
+		Ruc:             s.emisor.Ruc,
+		Ambiente:        s.emisor.Ambiente,
+		Establecimiento: s.emisor.Establecimiento,
+		PuntoEmision:    s.emisor.PuntoEmision,
+		Secuencial:      secuencial,
+		CodigoNumerico:  codigoNumerico,
+		TipoEmision:     sri.TipoEmisionNormal,
+	}
+
+	items := []sri.ItemFactura{}
+	for _, detalle := range detalles {
+		items = append(items, sri.ItemFactura{
+			Codigo:         strconv.Itoa(detalle.IdProducto),
+			Descripcion:    detalle.Descripcion,
+			Cantidad:       float64(detalle.Cantidad),
+			PrecioUnitario: detalle.PrecioUnitario,
+			Descuento:      detalle.Descuento,
+			Subtotal:       detalle.Subtotal,
+			TarifaIva:      detalle.PorcentajeIva,
+			ValorIva:       detalle.ValorIva,
+		})
+	}
+	comprador := sri.Comprador{
+		Identificacion: cliente.Cedula,
+		RazonSocial:    strings.TrimSpace(cliente.Nombres + " " + cliente.Apellidos),
+	}
+	if cliente.Direccion != nil {
+		comprador.Direccion = *cliente.Direccion
+	}
+	if cliente.Correo != nil {
+		comprador.Correo = *cliente.Correo
+	}
+	if cliente.Telefono != nil {
+		comprador.Telefono = *cliente.Telefono
+	}
+
+	facturaSRI, err := sri.NuevaFactura(s.emisor, clave, comprador, items)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	contenido, err := facturaSRI.XML()
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	firmado, err := s.firmador.Firmar(contenido)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+
+	comprobanteG, err := s.repo.CrearComprobante(ctx, entity.ComprobanteElectronico{
+		IdFactura:       idFactura,
+		CodDoc:          sri.CodDocFactura,
+		Establecimiento: s.emisor.Establecimiento,
+		PuntoEmision:    s.emisor.PuntoEmision,
+		Secuencial:      secuencial,
+		ClaveAcceso:     facturaSRI.InfoTributaria.ClaveAcceso,
+		Xml:             string(firmado),
+		Estado:          EstadoFirmado,
+		Fecha:           time.Now(),
+	})
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	return ComprobanteElectronico{comprobanteG}, nil
+}
+
+func (s service) EnviarComprobante(ctx context.Context, idFactura int) (ComprobanteElectronico, error) {
+	comprobante, err := s.repo.GetComprobantePorFactura(ctx, idFactura)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+
+	if comprobante.Estado == EstadoFirmado || comprobante.Estado == sri.EstadoDevuelta {
+		recepcion, err := s.cliente.Enviar(ctx, []byte(comprobante.Xml))
+		if err != nil {
+			return ComprobanteElectronico{}, err
+		}
+		comprobante.Estado = recepcion.Estado
+		comprobante.Mensajes = mensajes(recepcion.Mensajes)
+		//Un reenvío de un comprobante ya recibido se consulta directamente
+		for _, mensaje := range recepcion.Mensajes {
+			if mensaje.Identificador == identificadorClaveRegistrada {
+				comprobante.Estado = sri.EstadoRecibida
+			}
+		}
+	}
+
+	if comprobante.Estado == sri.EstadoRecibida || comprobante.Estado == sri.EstadoEnProcesamiento {
+		autorizacion, err := s.cliente.Autorizar(ctx, comprobante.ClaveAcceso)
+		if err != nil {
+			return ComprobanteElectronico{}, err
+		}
+		comprobante.Estado = autorizacion.Estado
+		if len(autorizacion.Mensajes) > 0 {
+			comprobante.Mensajes = mensajes(autorizacion.Mensajes)
+		}
+		if autorizacion.Estado == sri.EstadoAutorizado {
+			comprobante.NumeroAutorizacion = &autorizacion.NumeroAutorizacion
+			if fecha, err := time.Parse(time.RFC3339, autorizacion.FechaAutorizacion); err == nil {
+				comprobante.FechaAutorizacion = &fecha
+			}
+		}
+	}
+
+	comprobanteG, err := s.repo.ActualizarComprobante(ctx, comprobante)
+	if err != nil {
+		return ComprobanteElectronico{}, err
+	}
+	return ComprobanteElectronico{comprobanteG}, nil
+}
+
+// codigoNumerico returns the random 8 digit code that makes the clave de acceso unpredictable.
+func codigoNumerico() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08d", n.Int64()), nil
+}
+
+func mensajes(m []sri.Mensaje) *string {
+	if len(m) == 0 {
+		return nil
+	}
+	contenido, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	s := string(contenido)
+	return &s
+}
@@ -0,0 +1,173 @@
+package comprobante_electronico
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/sri"
+	"veterinaria-server/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var emisor = sri.Emisor{
+	Ambiente:        sri.AmbientePruebas,
+	Ruc:             "0912345678001",
+	RazonSocial:     "Veterinaria de prueba",
+	DireccionMatriz: "Guayaquil",
+	Establecimiento: "001",
+	PuntoEmision:    "002",
+}
+
+func Test_service_GenerarComprobante(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		facturas: map[int]entity.Factura{
+			1: {IdFactura: 1, IdCliente: 3, Fecha: time.Date(2022, 6, 15, 10, 0, 0, 0, time.UTC), Valor: 2620},
+			2: {IdFactura: 2, IdCliente: 3, Fecha: time.Now(), Anulada: true},
+		},
+		detalles: []DetalleComprobante{
+			{IdDetalleFactura: 1, Cantidad: 2, PrecioUnitario: 500, Subtotal: 1000, PorcentajeIva: 12, ValorIva: 120, IdProducto: 8, Descripcion: "Antiparasitario"},
+			{IdDetalleFactura: 2, Cantidad: 1, PrecioUnitario: 1500, Subtotal: 1500, IdProducto: 9, Descripcion: "Vacuna"},
+		},
+		secuencial: 41,
+	}
+	s := NewService(repo, emisor, firmadorStub{}, nil, logger)
+	ctx := context.Background()
+
+	comprobante, err := s.GenerarComprobante(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, EstadoFirmado, comprobante.Estado)
+	assert.Equal(t, 42, comprobante.Secuencial)
+	assert.Len(t, comprobante.ClaveAcceso, 49)
+	assert.True(t, strings.HasPrefix(comprobante.ClaveAcceso, "15062022010912345678001100100200000004"))
+	assert.Contains(t, comprobante.Xml, "<importeTotal>26.20</importeTotal>")
+	assert.Contains(t, comprobante.Xml, "<razonSocialComprador>Juan Perez</razonSocialComprador>")
+	assert.True(t, strings.HasSuffix(comprobante.Xml, "<firma></firma></factura>"))
+
+	// solo un comprobante por factura
+	_, err = s.GenerarComprobante(ctx, 1)
+	assert.NotNil(t, err)
+	assert.Equal(t, 42, repo.secuencial)
+
+	// factura anulada
+	_, err = s.GenerarComprobante(ctx, 2)
+	assert.NotNil(t, err)
+
+	// sin certificado
+	_, err = NewService(repo, emisor, nil, nil, logger).GenerarComprobante(ctx, 2)
+	assert.NotNil(t, err)
+}
+
+func Test_service_EnviarComprobante(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{comprobantes: map[int]entity.ComprobanteElectronico{
+		1: {IdComprobanteElectronico: 1, IdFactura: 1, ClaveAcceso: "123", Xml: "<factura></factura>", Estado: EstadoFirmado},
+		2: {IdComprobanteElectronico: 2, IdFactura: 2, ClaveAcceso: "456", Xml: "<factura></factura>", Estado: EstadoFirmado},
+	}}
+	cliente := &clienteStub{
+		recepciones: map[string]sri.Recepcion{
+			"<factura></factura>": {Estado: sri.EstadoRecibida},
+		},
+		autorizaciones: map[string]sri.Autorizacion{
+			"123": {Estado: sri.EstadoAutorizado, NumeroAutorizacion: "123", FechaAutorizacion: "2022-06-15T10:00:00-05:00"},
+			"456": {Estado: sri.EstadoNoAutorizado, Mensajes: []sri.Mensaje{{Identificador: "39", Mensaje: "FIRMA INVALIDA"}}},
+		},
+	}
+	s := NewService(repo, emisor, firmadorStub{}, cliente, logger)
+	ctx := context.Background()
+
+	comprobante, err := s.EnviarComprobante(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, sri.EstadoAutorizado, comprobante.Estado)
+	assert.Equal(t, "123", *comprobante.NumeroAutorizacion)
+	assert.NotNil(t, comprobante.FechaAutorizacion)
+
+	// un comprobante autorizado no se vuelve a enviar
+	_, err = s.EnviarComprobante(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cliente.envios)
+
+	comprobante, err = s.EnviarComprobante(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, sri.EstadoNoAutorizado, comprobante.Estado)
+	assert.Contains(t, *comprobante.Mensajes, "FIRMA INVALIDA")
+
+	_, err = s.EnviarComprobante(ctx, 3)
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+type firmadorStub struct{}
+
+func (f firmadorStub) Firmar(comprobante []byte) ([]byte, error) {
+	s := string(comprobante)
+	return []byte(strings.TrimSuffix(s, "</factura>") + "<firma></firma></factura>"), nil
+}
+
+type clienteStub struct {
+	recepciones    map[string]sri.Recepcion
+	autorizaciones map[string]sri.Autorizacion
+	envios         int
+}
+
+func (c *clienteStub) Enviar(ctx context.Context, comprobante []byte) (sri.Recepcion, error) {
+	c.envios++
+	return c.recepciones[string(comprobante)], nil
+}
+
+func (c *clienteStub) Autorizar(ctx context.Context, claveAcceso string) (sri.Autorizacion, error) {
+	return c.autorizaciones[claveAcceso], nil
+}
+
+type mockRepository struct {
+	facturas     map[int]entity.Factura
+	detalles     []DetalleComprobante
+	comprobantes map[int]entity.ComprobanteElectronico
+	secuencial   int
+}
+
+func (m *mockRepository) GetComprobantePorFactura(ctx context.Context, idFactura int) (entity.ComprobanteElectronico, error) {
+	comprobante, ok := m.comprobantes[idFactura]
+	if !ok {
+		return entity.ComprobanteElectronico{}, sql.ErrNoRows
+	}
+	return comprobante, nil
+}
+
+func (m *mockRepository) CrearComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error) {
+	if m.comprobantes == nil {
+		m.comprobantes = map[int]entity.ComprobanteElectronico{}
+	}
+	comprobante.IdComprobanteElectronico = len(m.comprobantes) + 1
+	m.comprobantes[comprobante.IdFactura] = comprobante
+	return comprobante, nil
+}
+
+func (m *mockRepository) ActualizarComprobante(ctx context.Context, comprobante entity.ComprobanteElectronico) (entity.ComprobanteElectronico, error) {
+	m.comprobantes[comprobante.IdFactura] = comprobante
+	return comprobante, nil
+}
+
+func (m *mockRepository) SiguienteSecuencial(ctx context.Context, codDoc string, establecimiento string, puntoEmision string) (int, error) {
+	m.secuencial++
+	return m.secuencial, nil
+}
+
+func (m *mockRepository) GetFactura(ctx context.Context, idFactura int) (entity.Factura, error) {
+	factura, ok := m.facturas[idFactura]
+	if !ok {
+		return entity.Factura{}, sql.ErrNoRows
+	}
+	return factura, nil
+}
+
+func (m *mockRepository) GetCliente(ctx context.Context, idCliente int) (entity.Cliente, error) {
+	return entity.Cliente{IdCliente: idCliente, Nombres: "Juan", Apellidos: "Perez", Cedula: "0912345678"}, nil
+}
+
+func (m *mockRepository) GetDetallesComprobante(ctx context.Context, idFactura int) ([]DetalleComprobante, error) {
+	return m.detalles, nil
+}
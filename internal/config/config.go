@@ -5,6 +5,7 @@ import (
 	"veterinaria-server/pkg/log"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/qiangxue/go-env"
 	"gopkg.in/yaml.v2"
 )
@@ -12,6 +13,20 @@ import (
 const (
 	defaultServerPort         = 8080
 	defaultJWTExpirationHours = 72
+	defaultJWTAccessMinutes   = 15
+	defaultClaveLongitud      = 8
+	defaultLoginMaxIntentos   = 5
+	defaultLoginBloqueo       = 15
+	defaultSRIAmbiente        = "1"
+	defaultSRIEstablecimiento = "001"
+	defaultSRIPuntoEmision    = "001"
+	defaultStorage            = "local"
+	defaultStoragePlantillas  = "./plantillas"
+	defaultStorageDocumentos  = "./resources"
+	defaultStorageCargas      = "./documentos-mascota"
+	defaultWhatsAppSesion     = "wapp.db"
+	defaultSMTPPort           = 587
+	defaultRecordatoriosCron  = "*/5 * * * *"
 )
 
 // Config represents an application configuration.
@@ -22,8 +37,67 @@ type Config struct {
 	DSN string `yaml:"dsn" env:"DSN,secret"`
 	// JWT signing key. required.
 	JWTSigningKey string `yaml:"jwt_signing_key" env:"JWT_SIGNING_KEY,secret"`
-	// JWT expiration in hours. Defaults to 72 hours (3 days)
+	// refresh token expiration in hours. Defaults to 72 hours (3 days)
 	JWTExpiration int `yaml:"jwt_expiration" env:"JWT_EXPIRATION"`
+	// JWT expiration in minutes. Defaults to 15 minutes
+	JWTAccessExpiration int `yaml:"jwt_access_expiration" env:"JWT_ACCESS_EXPIRATION"`
+	// SRI environment: 1 for pruebas, 2 for producción. Defaults to 1
+	SRIAmbiente string `yaml:"sri_ambiente" env:"SRI_AMBIENTE"`
+	// RUC and names of the taxpayer issuing the comprobantes electrónicos
+	SRIRuc             string `yaml:"sri_ruc" env:"SRI_RUC"`
+	SRIRazonSocial     string `yaml:"sri_razon_social" env:"SRI_RAZON_SOCIAL"`
+	SRINombreComercial string `yaml:"sri_nombre_comercial" env:"SRI_NOMBRE_COMERCIAL"`
+	// addresses of the main office and of the establishment
+	SRIDireccionMatriz          string `yaml:"sri_direccion_matriz" env:"SRI_DIRECCION_MATRIZ"`
+	SRIDireccionEstablecimiento string `yaml:"sri_direccion_establecimiento" env:"SRI_DIRECCION_ESTABLECIMIENTO"`
+	// establishment and point of emission codes. Default to 001
+	SRIEstablecimiento string `yaml:"sri_establecimiento" env:"SRI_ESTABLECIMIENTO"`
+	SRIPuntoEmision    string `yaml:"sri_punto_emision" env:"SRI_PUNTO_EMISION"`
+	// SI or NO
+	SRIObligadoContabilidad string `yaml:"sri_obligado_contabilidad" env:"SRI_OBLIGADO_CONTABILIDAD"`
+	// path and password of the .p12 certificate used to sign the comprobantes
+	SRICertificado      string `yaml:"sri_certificado" env:"SRI_CERTIFICADO"`
+	SRIClaveCertificado string `yaml:"sri_clave_certificado" env:"SRI_CLAVE_CERTIFICADO,secret"`
+	// reception and authorization web services. Default to the ones of the configured environment
+	SRIURLRecepcion    string `yaml:"sri_url_recepcion" env:"SRI_URL_RECEPCION"`
+	SRIURLAutorizacion string `yaml:"sri_url_autorizacion" env:"SRI_URL_AUTORIZACION"`
+	// daily stay charged when a hospitalización is discharged. No stay is charged when 0
+	HospitalizacionTarifaDiaria float64 `yaml:"hospitalizacion_tarifa_diaria" env:"HOSPITALIZACION_TARIFA_DIARIA"`
+	// minimum length of a new clave. Defaults to 8
+	ClaveLongitudMinima int `yaml:"clave_longitud_minima" env:"CLAVE_LONGITUD_MINIMA"`
+	// whether a new clave needs an uppercase letter, a number and a symbol
+	ClaveMayuscula bool `yaml:"clave_mayuscula" env:"CLAVE_MAYUSCULA"`
+	ClaveNumero    bool `yaml:"clave_numero" env:"CLAVE_NUMERO"`
+	ClaveSimbolo   bool `yaml:"clave_simbolo" env:"CLAVE_SIMBOLO"`
+	// failed logins in a row that lock a user, and for how many minutes. Default to 5 and 15. No lockout when 0
+	LoginMaxIntentos    int `yaml:"login_max_intentos" env:"LOGIN_MAX_INTENTOS"`
+	LoginMinutosBloqueo int `yaml:"login_minutos_bloqueo" env:"LOGIN_MINUTOS_BLOQUEO"`
+	// where the files are kept: local or s3. Defaults to local
+	Storage string `yaml:"storage" env:"STORAGE"`
+	// directories, or key prefixes in the bucket, of the report templates, the generated documents and the
+	// uploaded documentos of the mascotas. Default to ./plantillas, ./resources and ./documentos-mascota
+	StoragePlantillas string `yaml:"storage_plantillas" env:"STORAGE_PLANTILLAS"`
+	StorageDocumentos string `yaml:"storage_documentos" env:"STORAGE_DOCUMENTOS"`
+	StorageCargas     string `yaml:"storage_cargas" env:"STORAGE_CARGAS"`
+	// S3-compatible server and bucket used when storage is s3. The region defaults to us-east-1
+	S3Endpoint  string `yaml:"s3_endpoint" env:"S3_ENDPOINT"`
+	S3Bucket    string `yaml:"s3_bucket" env:"S3_BUCKET"`
+	S3Region    string `yaml:"s3_region" env:"S3_REGION"`
+	S3AccessKey string `yaml:"s3_access_key" env:"S3_ACCESS_KEY,secret"`
+	S3SecretKey string `yaml:"s3_secret_key" env:"S3_SECRET_KEY,secret"`
+	// SQLite file with the session of the WhatsApp account the notificaciones are sent from. Defaults to
+	// wapp.db. WhatsApp is disabled when empty
+	WhatsAppSesion string `yaml:"whatsapp_sesion" env:"WHATSAPP_SESION"`
+	// SMTP server the notificaciones by email are sent through. Email is disabled when the host is empty.
+	// The port defaults to 587
+	SMTPHost      string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort      int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsuario   string `yaml:"smtp_usuario" env:"SMTP_USUARIO"`
+	SMTPClave     string `yaml:"smtp_clave" env:"SMTP_CLAVE,secret"`
+	SMTPRemitente string `yaml:"smtp_remitente" env:"SMTP_REMITENTE"`
+	// cron expression of how often the due recordatorios of the citas médicas are queued. Defaults to every
+	// 5 minutes. The minutes before each cita are configured per tipo de cita in recordatorios_tipo_cita
+	RecordatoriosCron string `yaml:"recordatorios_cron" env:"RECORDATORIOS_CRON"`
 }
 
 // Validate validates the application configuration.
@@ -31,6 +105,24 @@ func (c Config) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.DSN, validation.Required),
 		validation.Field(&c.JWTSigningKey, validation.Required),
+		validation.Field(&c.JWTAccessExpiration, validation.Min(1)),
+		validation.Field(&c.SRIAmbiente, validation.In("1", "2")),
+		validation.Field(&c.SRIObligadoContabilidad, validation.In("SI", "NO")),
+		validation.Field(&c.HospitalizacionTarifaDiaria, validation.Min(0.0)),
+		validation.Field(&c.ClaveLongitudMinima, validation.Min(1)),
+		validation.Field(&c.LoginMaxIntentos, validation.Min(0)),
+		validation.Field(&c.LoginMinutosBloqueo, validation.Min(1)),
+		validation.Field(&c.Storage, validation.In("local", "s3")),
+		validation.Field(&c.StoragePlantillas, validation.Required),
+		validation.Field(&c.StorageDocumentos, validation.Required),
+		validation.Field(&c.StorageCargas, validation.Required),
+		validation.Field(&c.S3Endpoint, validation.When(c.Storage == "s3", validation.Required, is.URL)),
+		validation.Field(&c.S3Bucket, validation.When(c.Storage == "s3", validation.Required)),
+		validation.Field(&c.S3AccessKey, validation.When(c.Storage == "s3", validation.Required)),
+		validation.Field(&c.S3SecretKey, validation.When(c.Storage == "s3", validation.Required)),
+		validation.Field(&c.SMTPPort, validation.Min(1)),
+		validation.Field(&c.SMTPRemitente, validation.When(c.SMTPHost != "", validation.Required, is.Email)),
+		validation.Field(&c.RecordatoriosCron, validation.Required),
 	)
 }
 
@@ -38,8 +130,22 @@ func (c Config) Validate() error {
 func Load(file string, logger log.Logger) (*Config, error) {
 	// default config
 	c := Config{
-		ServerPort:    defaultServerPort,
-		JWTExpiration: defaultJWTExpirationHours,
+		ServerPort:          defaultServerPort,
+		JWTExpiration:       defaultJWTExpirationHours,
+		JWTAccessExpiration: defaultJWTAccessMinutes,
+		SRIAmbiente:         defaultSRIAmbiente,
+		SRIEstablecimiento:  defaultSRIEstablecimiento,
+		SRIPuntoEmision:     defaultSRIPuntoEmision,
+		ClaveLongitudMinima: defaultClaveLongitud,
+		LoginMaxIntentos:    defaultLoginMaxIntentos,
+		LoginMinutosBloqueo: defaultLoginBloqueo,
+		Storage:             defaultStorage,
+		StoragePlantillas:   defaultStoragePlantillas,
+		StorageDocumentos:   defaultStorageDocumentos,
+		StorageCargas:       defaultStorageCargas,
+		WhatsAppSesion:      defaultWhatsAppSesion,
+		SMTPPort:            defaultSMTPPort,
+		RecordatoriosCron:   defaultRecordatoriosCron,
 	}
 
 	// load from YAML config file
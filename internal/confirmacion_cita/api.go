@@ -0,0 +1,30 @@
+package confirmacion_cita
+
+import (
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/mensajesEntrantes", res.getMensajes)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getMensajes(c *routing.Context) error {
+	pages, err := r.service.GetMensajes(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
@@ -0,0 +1,119 @@
+package confirmacion_cita
+
+import (
+	"context"
+	"time"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/cita_medica"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access the mensajes received and the citas they confirm from the data source.
+type Repository interface {
+	GetMensajes(ctx context.Context, query pagination.Query) ([]entity.MensajeEntrante, *pagination.Pages, error)
+	// GetClientePorTelefono returns the cliente whose telefono has the same last 9 digits as the number, so it
+	// matches whether the cliente registered it with the country code or not.
+	GetClientePorTelefono(ctx context.Context, telefono string) (entity.Cliente, error)
+	// GetProximaCita returns the first agendada or confirmada cita of the cliente after desde, with the mascota
+	// and its duenio.
+	GetProximaCita(ctx context.Context, idCliente int, desde time.Time) (CitaConfirmacion, error)
+	// GetFechaRecordatorio returns when the last recordatorio of the cita was queued in the notificaciones.
+	GetFechaRecordatorio(ctx context.Context, idCitaMedica int) (time.Time, error)
+	// ActualizarEstadoConfirmacion saves the estado_confirmacion and the estado of the cita.
+	ActualizarEstadoConfirmacion(ctx context.Context, cita entity.CitaMedica) error
+	CrearMensaje(ctx context.Context, mensaje *entity.MensajeEntrante) error
+}
+
+// repository persists the mensajes received in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new confirmacionCita repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposMensajes are the fields the list of mensajes received can be filtered, searched and sorted by.
+var camposMensajes = pagination.Fields{
+	Filters: map[string]string{
+		"canal":          "canal",
+		"remitente":      "remitente",
+		"id_cliente":     "id_cliente",
+		"id_cita_medica": "id_cita_medica",
+		"accion":         "accion",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Search: []string{"cuerpo"},
+	Sort: map[string]string{
+		"id_mensaje_entrante": "id_mensaje_entrante",
+		"fecha":               "fecha",
+	},
+	DefaultSort: []string{"id_mensaje_entrante desc"},
+}
+
+func (r repository) GetMensajes(ctx context.Context, query pagination.Query) ([]entity.MensajeEntrante, *pagination.Pages, error) {
+	var mensajes []entity.MensajeEntrante = []entity.MensajeEntrante{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("mensajes_entrantes"), camposMensajes, &mensajes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mensajes, pages, nil
+}
+
+func (r repository) GetClientePorTelefono(ctx context.Context, telefono string) (entity.Cliente, error) {
+	var cliente entity.Cliente
+	if len(telefono) > 9 {
+		telefono = telefono[len(telefono)-9:]
+	}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.NewExp("RIGHT(REPLACE(REPLACE(telefono, ' ', ''), '-', ''), 9) = {:telefono}", dbx.Params{"telefono": telefono})).
+		OrderBy("id_cliente asc").
+		One(&cliente)
+	return cliente, err
+}
+
+func (r repository) GetProximaCita(ctx context.Context, idCliente int, desde time.Time) (CitaConfirmacion, error) {
+	var cita CitaConfirmacion
+	err := r.db.With(ctx).
+		Select("cm.*", "CONCAT(c.apellidos, ' ', c.nombres) AS duenio", "COALESCE(c.telefono, '') AS telefono", "COALESCE(m.nombre, '') AS mascota").
+		From("citas_medicas cm").
+		InnerJoin("mascotas m", dbx.NewExp("m.id_mascota = cm.id_mascota")).
+		InnerJoin("clientes c", dbx.NewExp("c.id_cliente = m.id_cliente")).
+		Where(dbx.NewExp("c.id_cliente = {:idCliente} AND cm.fecha > {:desde}", dbx.Params{"idCliente": idCliente, "desde": desde})).
+		AndWhere(dbx.In("cm.estado", cita_medica.EstadoAgendada, cita_medica.EstadoConfirmada)).
+		OrderBy("cm.fecha asc", "cm.id_cita_medica asc").
+		Limit(1).
+		One(&cita)
+	return cita, err
+}
+
+func (r repository) GetFechaRecordatorio(ctx context.Context, idCitaMedica int) (time.Time, error) {
+	var fecha time.Time
+	err := r.db.With(ctx).
+		Select("fecha_creacion").
+		From("notificaciones").
+		Where(dbx.HashExp{"tabla": tablaCitaMedica, "id_referencia": idCitaMedica, "codigo_plantilla": notificaciones.PlantillaRecordatorioCita}).
+		OrderBy("fecha_creacion desc").
+		Limit(1).
+		Row(&fecha)
+	return fecha, err
+}
+
+func (r repository) ActualizarEstadoConfirmacion(ctx context.Context, cita entity.CitaMedica) error {
+	return auditoria.Actualizar(ctx, r.db, &cita, "EstadoConfirmacion", "Estado")
+}
+
+// CrearMensaje registers a mensaje received. It is not audited, like the notificaciones.
+func (r repository) CrearMensaje(ctx context.Context, mensaje *entity.MensajeEntrante) error {
+	return r.db.With(ctx).Model(mensaje).Insert()
+}
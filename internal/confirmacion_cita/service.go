@@ -0,0 +1,213 @@
+package confirmacion_cita
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+	"unicode"
+	"veterinaria-server/internal/cita_medica"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// Acciones a cliente can ask for when replying to the recordatorio of a cita.
+const (
+	AccionConfirmar   = "CONFIRMAR"
+	AccionCancelar    = "CANCELAR"
+	AccionReprogramar = "REPROGRAMAR"
+	// AccionDesconocida is a mensaje that is not a reply to the recordatorio. It is only registered.
+	AccionDesconocida = "DESCONOCIDA"
+)
+
+// Codigos of the plantillas of the replies sent to the cliente.
+const (
+	PlantillaCitaConfirmada  = "respuesta_cita_confirmada"
+	PlantillaCitaCancelada   = "respuesta_cita_cancelada"
+	PlantillaCitaReprogramar = "respuesta_cita_reprogramar"
+)
+
+// tablaCitaMedica is the tabla of the notificaciones of the replies.
+const tablaCitaMedica = "CitaMedica"
+
+// ventanaRespuesta is how long after the recordatorio of a cita a mensaje is taken as a reply to it.
+const ventanaRespuesta = 72 * time.Hour
+
+// accion is what a reply does to the cita: the estado de confirmacion it sets, the estado the cita moves to, if
+// any, the plantilla of the reply to the cliente and the alerta to the staff, if any.
+type accion struct {
+	confirmacion string
+	estado       string
+	plantilla    string
+	alerta       string
+}
+
+var acciones = map[string]accion{
+	AccionConfirmar:   {cita_medica.ConfirmacionConfirmada, cita_medica.EstadoConfirmada, PlantillaCitaConfirmada, ""},
+	AccionCancelar:    {cita_medica.ConfirmacionCancelada, cita_medica.EstadoCancelada, PlantillaCitaCancelada, notificaciones.AlertaCitaCancelada},
+	AccionReprogramar: {cita_medica.ConfirmacionReprogramar, "", PlantillaCitaReprogramar, notificaciones.AlertaCitaReprogramar},
+}
+
+// Service encapsulates usecase logic for the replies of the clientes to the recordatorios of the citas.
+type Service interface {
+	GetMensajes(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	// ProcesarMensaje registers the mensaje and, when it is a reply of a cliente with a cita pending that was
+	// reminded within the ventanaRespuesta, sets the estado de confirmacion of the cita, replies to the cliente
+	// and alerts the staff of cancellations and requests to reschedule.
+	ProcesarMensaje(ctx context.Context, mensaje notificaciones.MensajeRecibido) (MensajeEntrante, error)
+}
+
+// MensajeEntrante represents the data about a mensaje received.
+type MensajeEntrante struct {
+	entity.MensajeEntrante
+}
+
+// CitaConfirmacion is a cita with the data the plantillas of the replies and the alertas show.
+type CitaConfirmacion struct {
+	entity.CitaMedica
+	Duenio   string `json:"duenio"`
+	Telefono string `json:"telefono"`
+	Mascota  string `json:"mascota"`
+}
+
+type service struct {
+	repo           Repository
+	notificaciones notificaciones.Service
+	logger         log.Logger
+	now            func() time.Time
+}
+
+// NewService creates a new confirmacionCita service that replies through the notificaciones.
+func NewService(repo Repository, notificaciones notificaciones.Service, logger log.Logger) Service {
+	return service{repo, notificaciones, logger, time.Now}
+}
+
+func (s service) GetMensajes(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	mensajes, pages, err := s.repo.GetMensajes(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []MensajeEntrante{}
+	for _, item := range mensajes {
+		result = append(result, MensajeEntrante{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) ProcesarMensaje(ctx context.Context, mensaje notificaciones.MensajeRecibido) (MensajeEntrante, error) {
+	ahora := s.now()
+	registro := entity.MensajeEntrante{
+		Canal:     mensaje.Canal,
+		Remitente: mensaje.Remitente,
+		Cuerpo:    mensaje.Cuerpo,
+		Accion:    interpretar(mensaje.Cuerpo),
+		Fecha:     mensaje.Fecha,
+	}
+	if registro.Fecha.IsZero() {
+		registro.Fecha = ahora
+	}
+
+	var cita CitaConfirmacion
+	cliente, err := s.repo.GetClientePorTelefono(ctx, mensaje.Remitente)
+	if err != nil && err != sql.ErrNoRows {
+		return MensajeEntrante{}, err
+	}
+	if err == nil {
+		registro.IdCliente = &cliente.IdCliente
+		cita, err = s.repo.GetProximaCita(ctx, cliente.IdCliente, ahora)
+		if err != nil && err != sql.ErrNoRows {
+			return MensajeEntrante{}, err
+		}
+		if err == nil {
+			registro.IdCitaMedica = &cita.IdCitaMedica
+		}
+	}
+	//Solo se atiende la respuesta a un recordatorio reciente de la cita
+	if registro.Accion != AccionDesconocida && registro.IdCitaMedica != nil {
+		recordatorio, err := s.repo.GetFechaRecordatorio(ctx, cita.IdCitaMedica)
+		if err != nil && err != sql.ErrNoRows {
+			return MensajeEntrante{}, err
+		}
+		if err == sql.ErrNoRows || registro.Fecha.Sub(recordatorio) > ventanaRespuesta {
+			registro.Accion = AccionDesconocida
+		}
+	}
+	//La conversacion se registra aunque no se pueda atender
+	if err := s.repo.CrearMensaje(ctx, &registro); err != nil {
+		return MensajeEntrante{}, err
+	}
+
+	a, ok := acciones[registro.Accion]
+	if !ok || registro.IdCitaMedica == nil {
+		return MensajeEntrante{registro}, nil
+	}
+	cita.EstadoConfirmacion = a.confirmacion
+	if cita_medica.TransicionValida(cita.Estado, a.estado) {
+		cita.Estado = a.estado
+	}
+	if err := s.repo.ActualizarEstadoConfirmacion(ctx, cita.CitaMedica); err != nil {
+		return MensajeEntrante{}, err
+	}
+	tabla := tablaCitaMedica
+	referencia := notificaciones.Referencia{IdCliente: registro.IdCliente, Tabla: &tabla, IdReferencia: registro.IdCitaMedica}
+	if _, err := s.notificaciones.Notificar(ctx, a.plantilla, mensaje.Canal, mensaje.Remitente, referencia, cita); err != nil && err != notificaciones.ErrCanalNoConfigurado {
+		return MensajeEntrante{}, err
+	}
+	if a.alerta != "" {
+		if _, err := s.notificaciones.Alertar(ctx, a.alerta, cita); err != nil {
+			return MensajeEntrante{}, err
+		}
+	}
+	return MensajeEntrante{registro}, nil
+}
+
+// interpretar returns the accion of the reply to the recordatorio, which asks for 1 to confirm, 2 to cancel and
+// 3 to reschedule. Only the option alone, the word of an option alone, e.g. "Confirmo", or the option followed by
+// its word, e.g. "3. Reprogramar", are understood, so a mensaje that just mentions a word, e.g. "¿puedo cancelar
+// con tarjeta?", is not taken as a reply.
+func interpretar(cuerpo string) string {
+	palabras := strings.FieldsFunc(strings.ToLower(cuerpo), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	switch len(palabras) {
+	case 1:
+		if a := opcion(palabras[0]); a != "" {
+			return a
+		}
+		return palabra(palabras[0])
+	case 2:
+		if a := opcion(palabras[0]); a != "" && a == palabra(palabras[1]) {
+			return a
+		}
+	}
+	return AccionDesconocida
+}
+
+// opcion returns the accion of the number of an option of the recordatorio, or "" when it is not one.
+func opcion(p string) string {
+	switch p {
+	case "1":
+		return AccionConfirmar
+	case "2":
+		return AccionCancelar
+	case "3":
+		return AccionReprogramar
+	}
+	return ""
+}
+
+// palabra returns the accion of the word of an option of the recordatorio.
+func palabra(p string) string {
+	switch {
+	case strings.HasPrefix(p, "reprogram"), p == "cambiar":
+		return AccionReprogramar
+	case strings.HasPrefix(p, "cancel"), strings.HasPrefix(p, "anul"):
+		return AccionCancelar
+	case strings.HasPrefix(p, "confirm"):
+		return AccionConfirmar
+	}
+	return AccionDesconocida
+}
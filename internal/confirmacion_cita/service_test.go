@@ -0,0 +1,203 @@
+package confirmacion_cita
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"veterinaria-server/internal/cita_medica"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_ProcesarMensaje(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	telefono := "0991111111"
+	tests := []struct {
+		name       string
+		remitente  string
+		cuerpo     string
+		accion     string
+		estado     string //Estado de confirmacion de la cita luego del mensaje
+		estadoCita string
+		respuestas []string //Plantillas enviadas al cliente
+		alertas    []string
+	}{
+		{"confirmar", "593991111111", "1", AccionConfirmar, cita_medica.ConfirmacionConfirmada, cita_medica.EstadoConfirmada, []string{PlantillaCitaConfirmada}, nil},
+		{"cancelar", "593991111111", "Cancelar", AccionCancelar, cita_medica.ConfirmacionCancelada, cita_medica.EstadoCancelada, []string{PlantillaCitaCancelada}, []string{notificaciones.AlertaCitaCancelada}},
+		{"reprogramar", "593991111111", "3. Reprogramar", AccionReprogramar, cita_medica.ConfirmacionReprogramar, cita_medica.EstadoAgendada, []string{PlantillaCitaReprogramar}, []string{notificaciones.AlertaCitaReprogramar}},
+		{"otro mensaje", "593991111111", "Buenos días", AccionDesconocida, cita_medica.ConfirmacionPendiente, cita_medica.EstadoAgendada, nil, nil},
+		{"cliente desconocido", "593992222222", "1", AccionConfirmar, cita_medica.ConfirmacionPendiente, cita_medica.EstadoAgendada, nil, nil},
+		{"pregunta", "593991111111", "¿Puedo cancelar con tarjeta?", AccionDesconocida, cita_medica.ConfirmacionPendiente, cita_medica.EstadoAgendada, nil, nil},
+		{"frase", "593991111111", "No confirmo todavía, le aviso", AccionDesconocida, cita_medica.ConfirmacionPendiente, cita_medica.EstadoAgendada, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{
+				clientes: []entity.Cliente{{IdCliente: 1, Nombres: "Ana", Apellidos: "Pérez", Telefono: &telefono}},
+				citas: []CitaConfirmacion{
+					{CitaMedica: entity.CitaMedica{IdCitaMedica: 1, IdMascota: 1, Fecha: ahora.Add(-time.Hour), Estado: cita_medica.EstadoAgendada, EstadoConfirmacion: cita_medica.ConfirmacionPendiente}},
+					{CitaMedica: entity.CitaMedica{IdCitaMedica: 2, IdMascota: 1, Fecha: ahora.Add(24 * time.Hour), Estado: cita_medica.EstadoAgendada, EstadoConfirmacion: cita_medica.ConfirmacionPendiente}, Duenio: "Pérez Ana", Mascota: "Firulais"},
+				},
+				recordatorios: map[int]time.Time{2: ahora.Add(-2 * time.Hour)},
+			}
+			sn := &mockNotificaciones{}
+			s := NewService(repo, sn, logger).(service)
+			s.now = func() time.Time { return ahora }
+			fuente := &notificaciones.FuenteFake{}
+			fuente.Escuchar(func(m notificaciones.MensajeRecibido) {
+				_, err := s.ProcesarMensaje(context.Background(), m)
+				assert.Nil(t, err)
+			})
+
+			fuente.Recibir(notificaciones.MensajeRecibido{Canal: notificaciones.CanalWhatsApp, Remitente: tt.remitente, Cuerpo: tt.cuerpo, Fecha: ahora})
+			if assert.Len(t, repo.mensajes, 1) {
+				assert.Equal(t, tt.accion, repo.mensajes[0].Accion)
+				assert.Equal(t, tt.cuerpo, repo.mensajes[0].Cuerpo)
+			}
+			assert.Equal(t, tt.estado, repo.citas[1].EstadoConfirmacion)
+			assert.Equal(t, tt.estadoCita, repo.citas[1].Estado)
+			assert.Equal(t, cita_medica.ConfirmacionPendiente, repo.citas[0].EstadoConfirmacion)
+			assert.Equal(t, tt.respuestas, sn.respuestas)
+			assert.Equal(t, tt.alertas, sn.alertas)
+			if len(tt.respuestas) > 0 {
+				assert.Equal(t, 2, *repo.mensajes[0].IdCitaMedica)
+				assert.Equal(t, tt.remitente, sn.destinatario)
+			}
+		})
+	}
+}
+
+func Test_service_ProcesarMensaje_recordatorio(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	telefono := "0991111111"
+	tests := []struct {
+		name          string
+		recordatorios map[int]time.Time
+	}{
+		{"sin recordatorio", map[int]time.Time{}},
+		{"recordatorio vencido", map[int]time.Time{2: ahora.Add(-ventanaRespuesta - time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{
+				clientes:      []entity.Cliente{{IdCliente: 1, Telefono: &telefono}},
+				citas:         []CitaConfirmacion{{CitaMedica: entity.CitaMedica{IdCitaMedica: 2, IdMascota: 1, Fecha: ahora.Add(24 * time.Hour), Estado: cita_medica.EstadoAgendada, EstadoConfirmacion: cita_medica.ConfirmacionPendiente}}},
+				recordatorios: tt.recordatorios,
+			}
+			sn := &mockNotificaciones{}
+			s := NewService(repo, sn, logger).(service)
+			s.now = func() time.Time { return ahora }
+
+			mensaje, err := s.ProcesarMensaje(context.Background(), notificaciones.MensajeRecibido{Canal: notificaciones.CanalWhatsApp, Remitente: "593991111111", Cuerpo: "2", Fecha: ahora})
+			assert.Nil(t, err)
+			assert.Equal(t, AccionDesconocida, mensaje.Accion)
+			assert.Equal(t, cita_medica.ConfirmacionPendiente, repo.citas[0].EstadoConfirmacion)
+			assert.Equal(t, cita_medica.EstadoAgendada, repo.citas[0].Estado)
+			assert.Nil(t, sn.respuestas)
+			assert.Nil(t, sn.alertas)
+		})
+	}
+}
+
+func Test_interpretar(t *testing.T) {
+	for cuerpo, want := range map[string]string{
+		"1":                            AccionConfirmar,
+		" 1 confirmar":                 AccionConfirmar,
+		"Confirmo.":                    AccionConfirmar,
+		"2":                            AccionCancelar,
+		"CANCELAR":                     AccionCancelar,
+		"3":                            AccionReprogramar,
+		"3. Reprogramar":               AccionReprogramar,
+		"1 cancelar":                   AccionDesconocida,
+		"Confirmo, gracias":            AccionDesconocida,
+		"No podré, CANCELAR":           AccionDesconocida,
+		"¿Puedo cambiar la hora?":      AccionDesconocida,
+		"¿puedo cancelar con tarjeta?": AccionDesconocida,
+		"No confirmo":                  AccionDesconocida,
+		"12":                           AccionDesconocida,
+		"Gracias 1000":                 AccionDesconocida,
+		"":                             AccionDesconocida,
+	} {
+		assert.Equal(t, want, interpretar(cuerpo), cuerpo)
+	}
+}
+
+type mockNotificaciones struct {
+	notificaciones.Service
+	respuestas   []string
+	destinatario string
+	alertas      []string
+}
+
+func (m *mockNotificaciones) Notificar(ctx context.Context, codigo, canal, destinatario string, referencia notificaciones.Referencia, datos interface{}) (notificaciones.Notificacion, error) {
+	m.respuestas = append(m.respuestas, codigo)
+	m.destinatario = destinatario
+	return notificaciones.Notificacion{}, nil
+}
+
+func (m *mockNotificaciones) Alertar(ctx context.Context, tipoAlerta string, datos interface{}) ([]notificaciones.Notificacion, error) {
+	m.alertas = append(m.alertas, tipoAlerta)
+	return nil, nil
+}
+
+type mockRepository struct {
+	clientes []entity.Cliente
+	citas    []CitaConfirmacion
+	mensajes []entity.MensajeEntrante
+	//Fecha del ultimo recordatorio de cada cita
+	recordatorios map[int]time.Time
+}
+
+func (m *mockRepository) GetMensajes(ctx context.Context, query pagination.Query) ([]entity.MensajeEntrante, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.mensajes))
+	return m.mensajes, pages, nil
+}
+
+func (m *mockRepository) GetClientePorTelefono(ctx context.Context, telefono string) (entity.Cliente, error) {
+	for _, cliente := range m.clientes {
+		if cliente.Telefono != nil && len(telefono) >= 9 && (*cliente.Telefono)[len(*cliente.Telefono)-9:] == telefono[len(telefono)-9:] {
+			return cliente, nil
+		}
+	}
+	return entity.Cliente{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetProximaCita(ctx context.Context, idCliente int, desde time.Time) (CitaConfirmacion, error) {
+	for _, cita := range m.citas {
+		if cita.Fecha.After(desde) && cita_medica.Activa(cita.Estado) {
+			return cita, nil
+		}
+	}
+	return CitaConfirmacion{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetFechaRecordatorio(ctx context.Context, idCitaMedica int) (time.Time, error) {
+	fecha, ok := m.recordatorios[idCitaMedica]
+	if !ok {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return fecha, nil
+}
+
+func (m *mockRepository) ActualizarEstadoConfirmacion(ctx context.Context, cita entity.CitaMedica) error {
+	for i := range m.citas {
+		if m.citas[i].IdCitaMedica == cita.IdCitaMedica {
+			m.citas[i].EstadoConfirmacion = cita.EstadoConfirmacion
+			m.citas[i].Estado = cita.Estado
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) CrearMensaje(ctx context.Context, mensaje *entity.MensajeEntrante) error {
+	mensaje.IdMensajeEntrante = len(m.mensajes) + 1
+	m.mensajes = append(m.mensajes, *mensaje)
+	return nil
+}
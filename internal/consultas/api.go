@@ -3,15 +3,24 @@ package consultas
 import (
 	"net/http"
 	"strconv"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/factura"
+	"veterinaria-server/internal/movimiento_inventario"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/internal/tarifa_iva"
+	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
 
 // RegisterHandlers sets up the routing of the HTTP handlers.
-func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
-	res := resource{service, logger}
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, db *dbcontext.DB) {
+	res := resource{service, logger, db}
 	r.Use(authHandler)
 	// the following endpoints require a valid JWT
 	r.Get("/consultas", res.getConsultas)
@@ -22,19 +31,22 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 	r.Get("/consultas/activa/<idUsuario>", res.getConsultaActiva)
 	r.Post("/consultas", res.crearConsulta)
 	r.Put("/consultas", res.actualizarConsulta)
+	r.Post("/consultas/<idConsulta>/cobro", res.cobrarConsulta)
 }
 
 type resource struct {
 	service Service
 	logger  log.Logger
+	db      *dbcontext.DB
 }
 
 func (r resource) getConsultas(c *routing.Context) error {
-	consultas, err := r.service.GetConsultas(c.Request.Context())
+	pages, err := r.service.GetConsultas(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(consultas)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearConsulta(c *routing.Context) error {
@@ -108,3 +120,142 @@ func (r resource) getConsultaRecetaServicios(c *routing.Context) error {
 	}
 	return c.Write(consulta)
 }
+
+func (r resource) cobrarConsulta(c *routing.Context) error {
+	idConsulta, _ := strconv.Atoi(c.Param("idConsulta"))
+	var input CobroRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	identity := auth.CurrentUser(c.Request.Context())
+	if identity == nil {
+		return errors.Unauthorized("")
+	}
+
+	cobro, err := r.service.PrepararCobro(c.Request.Context(), idConsulta, input)
+	if err != nil {
+		return err
+	}
+
+	//Los productos de la receta se venden de los lotes por FEFO y conservan la receta de origen
+	sm := movimiento_inventario.NewService(movimiento_inventario.NewRepository(r.db, r.logger), r.logger)
+	detalles := cobro.Detalles
+	items := []movimiento_inventario.ItemStock{}
+	for _, dispensado := range cobro.Dispensados {
+		asignaciones, err := sm.AsignarFEFO(c.Request.Context(), dispensado.IdProducto, dispensado.Cantidad, items)
+		if err != nil {
+			return err
+		}
+		origen := detalle_factura.OrigenReceta
+		idReceta := dispensado.IdReceta
+		for _, asignacion := range asignaciones {
+			detalles = append(detalles, detalle_factura.CreateDetalleFacturaRequest{
+				Tabla:        asignacion.Tabla,
+				IdReferencia: asignacion.IdReferencia,
+				Cantidad:     asignacion.Cantidad,
+				Origen:       &origen,
+				IdOrigen:     &idReceta,
+			})
+			items = append(items, asignacion)
+		}
+	}
+	if err := sm.VerificarDisponibilidad(c.Request.Context(), items); err != nil {
+		return err
+	}
+
+	var facturaG factura.Factura
+	detallesFacturaG := []detalle_factura.DetalleFactura{}
+	pagosG := []pago.Pago{}
+	if len(detalles) > 0 {
+		fecha := time.Now()
+		st := tarifa_iva.NewService(tarifa_iva.NewRepository(r.db, r.logger), r.logger)
+		tarifaIva, err := st.GetTarifaIvaVigente(c.Request.Context(), fecha)
+		if err != nil {
+			return err
+		}
+		sf := factura.NewService(factura.NewRepository(r.db, r.logger), r.logger)
+		detalles, totales, err := sf.CalcularTotales(c.Request.Context(), detalles, tarifaIva.Porcentaje)
+		if err != nil {
+			return err
+		}
+		origen := "consulta"
+		facturaG, err = sf.CrearFactura(c.Request.Context(), factura.CreateFacturaRequest{
+			IdCliente: cobro.IdCliente,
+			IdUsuario: identity.GetIdUsuario(),
+			Fecha:     fecha,
+			Totales:   totales,
+			Origen:    &origen,
+			IdOrigen:  &idConsulta,
+		})
+		if err != nil {
+			return err
+		}
+		sd := detalle_factura.NewService(detalle_factura.NewRepository(r.db, r.logger), r.logger)
+		for _, detalle := range detalles {
+			detalle.IdFactura = facturaG.IdFactura
+			detalleFacturaG, err := sd.CrearDetalleFactura(c.Request.Context(), detalle)
+			if err != nil {
+				return err
+			}
+			if detalle_factura.EsInventario(detalleFacturaG.Tabla) {
+				origen := "detalles_factura"
+				_, err = sm.RegistrarMovimiento(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+					Tabla:        movimiento_inventario.TablaDetalle(detalleFacturaG.Tabla),
+					IdReferencia: detalleFacturaG.IdReferencia,
+					Tipo:         movimiento_inventario.TipoVenta,
+					Cantidad:     -detalleFacturaG.Cantidad,
+					Origen:       &origen,
+					IdOrigen:     &detalleFacturaG.IdDetalleFactura,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			detallesFacturaG = append(detallesFacturaG, detalleFacturaG)
+		}
+		if len(input.Pagos) > 0 {
+			sp := pago.NewService(pago.NewRepository(r.db, r.logger), r.logger)
+			pagosG, err = sp.RegistrarPagos(c.Request.Context(), pago.RegistrarPagosRequest{
+				IdFactura: &facturaG.IdFactura,
+				Pagos:     input.Pagos,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	consultaBD := cobro.Consulta
+	consultaG, err := r.service.ActualizarConsulta(c.Request.Context(), UpdateConsultaRequest{
+		IdConsulta:             consultaBD.IdConsulta,
+		IdMascota:              consultaBD.IdMascota,
+		IdUsuario:              consultaBD.IdUsuario,
+		Fecha:                  consultaBD.Fecha,
+		Valor:                  consultaBD.Valor,
+		Motivo:                 consultaBD.Motivo,
+		Temperatura:            consultaBD.Temperatura,
+		Peso:                   consultaBD.Peso,
+		Tamaño:                 consultaBD.Tamaño,
+		CondicionCorporal:      consultaBD.CondicionCorporal,
+		NivelesDeshidratacion:  consultaBD.NivelesDeshidratacion,
+		Diagnostico:            consultaBD.Diagnostico,
+		Edad:                   consultaBD.Edad,
+		TiempoLlenadoCapilar:   consultaBD.TiempoLlenadoCapilar,
+		FrecuenciaCardiaca:     consultaBD.FrecuenciaCardiaca,
+		FrecuenciaRespiratoria: consultaBD.FrecuenciaRespiratoria,
+		EstadoConsulta:         EstadoFinalizada,
+	})
+	if err != nil {
+		return err
+	}
+
+	var result = struct {
+		Consulta        Consulta
+		Factura         factura.Factura
+		DetallesFactura []detalle_factura.DetalleFactura
+		Pagos           []pago.Pago
+	}{consultaG, facturaG, detallesFacturaG, pagosG}
+
+	return c.WriteWithStatus(result, http.StatusCreated)
+}
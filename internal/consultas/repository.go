@@ -3,9 +3,11 @@ package consultas
 import (
 	"context"
 	"database/sql"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -16,12 +18,21 @@ type Repository interface {
 	GetConsultaPorId(ctx context.Context, idConsulta int) (entity.Consulta, error)
 	GetConsultaActiva(ctx context.Context, idUsuario int) (entity.Consulta, error)
 	// GetConsultas returns the list consultas.
-	GetConsultas(ctx context.Context) ([]entity.Consulta, error)
+	GetConsultas(ctx context.Context, query pagination.Query) ([]entity.Consulta, *pagination.Pages, error)
 	GetConsultaPorMesYAnio(ctx context.Context, mes int, anio int) ([]ConsultaConDatos, error)
 	GetConsultaPorMascota(ctx context.Context, idMascota int) ([]entity.Consulta, error)
 	GetConsultaRecetaServicios(ctx context.Context, idConsulta int) (RecetaServicios, error)
 	CrearConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error)
 	ActualizarConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error)
+	// GetIdClienteConsulta returns the owner of the mascota attended.
+	GetIdClienteConsulta(ctx context.Context, idConsulta int) (int, error)
+	// ConsultaFacturada reports whether a factura that is not voided bills the consulta. It locks the consulta
+	// until the end of the transaction.
+	ConsultaFacturada(ctx context.Context, idConsulta int) (bool, error)
+	GetServiciosConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error)
+	// GetExamenesConsulta returns the examenes requested during the consulta with the valor they added to it.
+	GetExamenesConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error)
+	GetRecetasConsulta(ctx context.Context, idConsulta int) ([]RecetaProducto, error)
 }
 
 // repository persists consultas in database
@@ -35,23 +46,52 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposConsultas are the fields the list of consultas can be filtered, searched and sorted by.
+var camposConsultas = pagination.Fields{
+	Filters: map[string]string{
+		"id_mascota":      "id_mascota",
+		"id_usuario":      "id_usuario",
+		"estado_consulta": "estado_consulta",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Search: []string{"motivo", "condicion_corporal", "niveles_deshidratacion", "diagnostico", "edad"},
+	Sort: map[string]string{
+		"id_consulta":             "id_consulta",
+		"id_mascota":              "id_mascota",
+		"id_usuario":              "id_usuario",
+		"fecha":                   "fecha",
+		"valor":                   "valor",
+		"motivo":                  "motivo",
+		"temperatura":             "temperatura",
+		"peso":                    "peso",
+		"tamaño":                  "tamaño",
+		"condicion_corporal":      "condicion_corporal",
+		"niveles_deshidratacion":  "niveles_deshidratacion",
+		"diagnostico":             "diagnostico",
+		"edad":                    "edad",
+		"tiempo_llenado_capilar":  "tiempo_llenado_capilar",
+		"frecuencia_cardiaca":     "frecuencia_cardiaca",
+		"frecuencia_respiratoria": "frecuencia_respiratoria",
+		"estado_consulta":         "estado_consulta",
+	},
+	DefaultSort: []string{"id_consulta asc"},
+}
+
 // Get reads the list consultas from the database.
-func (r repository) GetConsultas(ctx context.Context) ([]entity.Consulta, error) {
-	var consultas []entity.Consulta
+func (r repository) GetConsultas(ctx context.Context, query pagination.Query) ([]entity.Consulta, *pagination.Pages, error) {
+	var consultas []entity.Consulta = []entity.Consulta{}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&consultas)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("consulta"), camposConsultas, &consultas)
 	if err != nil {
-		return consultas, err
+		return nil, nil, err
 	}
-	return consultas, err
+	return consultas, pages, nil
 }
 
 // Create saves a new Consulta record in the database.
 // It returns the ID of the newly inserted consulta record.
 func (r repository) CrearConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error) {
-	err := r.db.With(ctx).Model(&consulta).Insert()
+	err := auditoria.Insertar(ctx, r.db, &consulta)
 	if err != nil {
 		return entity.Consulta{}, err
 	}
@@ -63,9 +103,9 @@ func (r repository) CrearConsulta(ctx context.Context, consulta entity.Consulta)
 func (r repository) ActualizarConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error) {
 	var err error
 	if consulta.IdConsulta != 0 {
-		err = r.db.With(ctx).Model(&consulta).Update()
+		err = auditoria.Actualizar(ctx, r.db, &consulta)
 	} else {
-		err = r.db.With(ctx).Model(&consulta).Insert()
+		err = auditoria.Insertar(ctx, r.db, &consulta)
 	}
 	if err != nil {
 		return entity.Consulta{}, err
@@ -211,3 +251,68 @@ func (r repository) GetConsultaRecetaServicios(ctx context.Context, idConsulta i
 	recetaServicios.Servicios = servicios
 	return recetaServicios, err
 }
+
+func (r repository) GetIdClienteConsulta(ctx context.Context, idConsulta int) (int, error) {
+	var idCliente int
+	err := r.db.With(ctx).
+		Select("m.id_cliente").
+		From("consulta c").
+		InnerJoin("mascotas m", dbx.NewExp("m.id_mascota = c.id_mascota")).
+		Where(dbx.HashExp{"c.id_consulta": idConsulta}).
+		Row(&idCliente)
+	return idCliente, err
+}
+
+func (r repository) ConsultaFacturada(ctx context.Context, idConsulta int) (bool, error) {
+	//Bloquea la consulta hasta el fin de la transaccion, dos cobros simultaneos no la facturan dos veces
+	var id int
+	err := r.db.With(ctx).
+		NewQuery("SELECT id_consulta FROM consulta WHERE id_consulta = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idConsulta}).
+		Row(&id)
+	if err != nil {
+		return false, err
+	}
+	var facturas int
+	err = r.db.With(ctx).
+		Select("count(*)").
+		From("facturas").
+		Where(dbx.HashExp{"origen": "consulta", "id_origen": idConsulta, "anulada": false}).
+		Row(&facturas)
+	return facturas > 0, err
+}
+
+func (r repository) GetServiciosConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error) {
+	var servicios []CargoConsulta = []CargoConsulta{}
+	err := r.db.With(ctx).
+		Select("dsc.id_detalle_servicio_consulta as id_referencia", "s.descripcion", "dsc.valor").
+		From("detalles_servicios_consulta dsc").
+		InnerJoin("servicios s", dbx.NewExp("s.id_servicio = dsc.id_servicio")).
+		Where(dbx.HashExp{"dsc.id_consulta": idConsulta}).
+		OrderBy("dsc.fecha asc").
+		All(&servicios)
+	return servicios, err
+}
+
+func (r repository) GetExamenesConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error) {
+	var examenes []CargoConsulta = []CargoConsulta{}
+	err := r.db.With(ctx).
+		Select("em.id_examen_mascota as id_referencia", "te.titulo as descripcion", "em.valor").
+		From("examenes_mascota em").
+		InnerJoin("tipos_examenes te", dbx.NewExp("te.id_tipo_examen = em.id_tipo_examen")).
+		Where(dbx.HashExp{"em.id_referencia": idConsulta, "em.tabla": "Consulta"}).
+		OrderBy("em.fecha_solicitud asc").
+		All(&examenes)
+	return examenes, err
+}
+
+func (r repository) GetRecetasConsulta(ctx context.Context, idConsulta int) ([]RecetaProducto, error) {
+	var recetas []RecetaProducto = []RecetaProducto{}
+	err := r.db.With(ctx).
+		Select("r.id_receta", "r.id_producto", "p.descripcion").
+		From("receta r").
+		InnerJoin("producto p", dbx.NewExp("p.id_producto = r.id_producto")).
+		Where(dbx.HashExp{"r.id_consulta": idConsulta}).
+		All(&recetas)
+	return recetas, err
+}
@@ -3,15 +3,21 @@ package consultas
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/pago"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for consultas.
 type Service interface {
-	GetConsultas(ctx context.Context) ([]Consulta, error)
+	GetConsultas(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetConsultaPorId(ctx context.Context, idConsulta int) (Consulta, error)
 	GetConsultaActiva(ctx context.Context, idUsuario int) (Consulta, error)
 	GetConsultaPorMesYAnio(ctx context.Context, mes int, anio int) ([]ConsultaConDatos, error)
@@ -19,8 +25,17 @@ type Service interface {
 	GetConsultaRecetaServicios(ctx context.Context, idConsulta int) (RecetaServicios, error)
 	CrearConsulta(ctx context.Context, input CreateConsultaRequest) (Consulta, error)
 	ActualizarConsulta(ctx context.Context, input UpdateConsultaRequest) (Consulta, error)
+	// PrepararCobro gathers the charges of a consulta into the detalles of its factura and the productos
+	// of its receta to dispense. A consulta can only be billed once unless its factura is voided.
+	PrepararCobro(ctx context.Context, idConsulta int, input CobroRequest) (Cobro, error)
 }
 
+// Estados de una consulta.
+const (
+	EstadoActiva     = "ACTIVA"
+	EstadoFinalizada = "FINALIZADA"
+)
+
 // Consultas represents the data about an consultas.
 type Consulta struct {
 	entity.Consulta
@@ -41,6 +56,51 @@ type ServicioConDatos struct {
 	Valor    float32 `json:"valor"`
 }
 
+// CargoConsulta is a servicio or examen charged to a consulta.
+type CargoConsulta struct {
+	IdReferencia int     `json:"id_referencia" db:"id_referencia"`
+	Descripcion  string  `json:"descripcion" db:"descripcion"`
+	Valor        float32 `json:"valor" db:"valor"`
+}
+
+// RecetaProducto is a producto prescribed in the receta of a consulta.
+type RecetaProducto struct {
+	IdReceta    int    `db:"id_receta"`
+	IdProducto  int    `db:"id_producto"`
+	Descripcion string `db:"descripcion"`
+}
+
+// CobroRequest represents the checkout of a consulta.
+type CobroRequest struct {
+	// Productos are the productos of the receta sold by the clinic.
+	Productos []ProductoDispensadoRequest `json:"productos"`
+	// Pagos received at checkout, the rest remains as a pending balance.
+	Pagos []pago.DetallePagoRequest `json:"pagos"`
+}
+
+// ProductoDispensadoRequest is the quantity of a producto of the receta sold at checkout.
+type ProductoDispensadoRequest struct {
+	IdReceta int     `json:"id_receta"`
+	Cantidad float32 `json:"cantidad"`
+}
+
+// Cobro is a consulta ready to be billed.
+type Cobro struct {
+	Consulta  entity.Consulta
+	IdCliente int
+	// Detalles are the charges that do not come from inventory.
+	Detalles []detalle_factura.CreateDetalleFacturaRequest
+	// Dispensados are the productos of the receta, the lotes are assigned when billing.
+	Dispensados []ProductoDispensado
+}
+
+// ProductoDispensado is a producto of the receta to sell.
+type ProductoDispensado struct {
+	IdReceta   int
+	IdProducto int
+	Cantidad   float32
+}
+
 type ConsultaConDatos struct {
 	entity.Consulta
 	Mascota string `json:"mascota"`
@@ -57,8 +117,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list consultas.
-func (s service) GetConsultas(ctx context.Context) ([]Consulta, error) {
-	consultas, err := s.repo.GetConsultas(ctx)
+func (s service) GetConsultas(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	consultas, pages, err := s.repo.GetConsultas(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +126,8 @@ func (s service) GetConsultas(ctx context.Context) ([]Consulta, error) {
 	for _, item := range consultas {
 		result = append(result, Consulta{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateConsultaRequest represents an consulta creation request.
@@ -127,6 +188,11 @@ func (m CreateConsultaRequest) Validate() error {
 
 // CrearConsulta creates a new consulta.
 func (s service) CrearConsulta(ctx context.Context, req CreateConsultaRequest) (Consulta, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return Consulta{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return Consulta{}, err
 	}
@@ -156,6 +222,19 @@ func (s service) CrearConsulta(ctx context.Context, req CreateConsultaRequest) (
 
 // ActualizarConsulta creates a new consulta.
 func (s service) ActualizarConsulta(ctx context.Context, req UpdateConsultaRequest) (Consulta, error) {
+	var idGuardado int
+	if req.IdConsulta != 0 {
+		consultaBD, err := s.repo.GetConsultaPorId(ctx, req.IdConsulta)
+		if err != nil {
+			return Consulta{}, err
+		}
+		idGuardado = consultaBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return Consulta{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return Consulta{}, err
 	}
@@ -228,3 +307,91 @@ func (s service) GetConsultaRecetaServicios(ctx context.Context, idConsulta int)
 	}
 	return recetaServicios, nil
 }
+
+func (s service) PrepararCobro(ctx context.Context, idConsulta int, req CobroRequest) (Cobro, error) {
+	consulta, err := s.repo.GetConsultaPorId(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+	facturada, err := s.repo.ConsultaFacturada(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+	if facturada {
+		return Cobro{}, errors.Conflict("La consulta ya fue facturada.")
+	}
+	cobro := Cobro{Consulta: consulta, Detalles: []detalle_factura.CreateDetalleFacturaRequest{}, Dispensados: []ProductoDispensado{}}
+	cobro.IdCliente, err = s.repo.GetIdClienteConsulta(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+
+	recetas, err := s.repo.GetRecetasConsulta(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+	for _, producto := range req.Productos {
+		if producto.Cantidad <= 0 {
+			return Cobro{}, errors.BadRequest("La cantidad a dispensar debe ser mayor a cero.")
+		}
+		idProducto := 0
+		for _, receta := range recetas {
+			if receta.IdReceta == producto.IdReceta {
+				idProducto = receta.IdProducto
+			}
+		}
+		if idProducto == 0 {
+			return Cobro{}, errors.BadRequest("El producto no pertenece a la receta de la consulta.")
+		}
+		cobro.Dispensados = append(cobro.Dispensados, ProductoDispensado{IdReceta: producto.IdReceta, IdProducto: idProducto, Cantidad: producto.Cantidad})
+	}
+
+	servicios, err := s.repo.GetServiciosConsulta(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+	examenes, err := s.repo.GetExamenesConsulta(ctx, idConsulta)
+	if err != nil {
+		return Cobro{}, err
+	}
+	cobro.Detalles = detallesCobro(consulta, servicios, examenes)
+	return cobro, nil
+}
+
+// detallesCobro itemises the consulta. The valor of the consulta accumulates its servicios and examenes
+// over the fee of the consulta, so the fee is what remains after them. Each examen is billed at what it
+// added to the consulta, the examenes that added nothing are not billed.
+func detallesCobro(consulta entity.Consulta, servicios []CargoConsulta, examenes []CargoConsulta) []detalle_factura.CreateDetalleFacturaRequest {
+	detalles := []detalle_factura.CreateDetalleFacturaRequest{}
+	cargo := func(tabla string, idReferencia int, descripcion string, valor money.Money) {
+		d := descripcion
+		detalles = append(detalles, detalle_factura.CreateDetalleFacturaRequest{
+			Tabla:          tabla,
+			IdReferencia:   idReferencia,
+			Descripcion:    &d,
+			Cantidad:       1,
+			PrecioUnitario: valor,
+		})
+	}
+
+	acumulado := money.Zero
+	for _, servicio := range servicios {
+		acumulado += money.FromFloat(float64(servicio.Valor))
+	}
+	for _, examen := range examenes {
+		acumulado += money.FromFloat(float64(examen.Valor))
+	}
+	if honorario := money.FromFloat(float64(consulta.Valor)) - acumulado; honorario > 0 {
+		cargo(detalle_factura.TablaConsulta, consulta.IdConsulta, "Consulta veterinaria", honorario)
+	}
+	for _, servicio := range servicios {
+		cargo(detalle_factura.TablaServicioConsulta, servicio.IdReferencia, servicio.Descripcion, money.FromFloat(float64(servicio.Valor)))
+	}
+	for _, examen := range examenes {
+		if examen.Valor <= 0 {
+			continue
+		}
+		cargo(detalle_factura.TablaExamen, examen.IdReferencia, "Examen: "+examen.Descripcion, money.FromFloat(float64(examen.Valor)))
+	}
+	return detalles
+}
@@ -0,0 +1,137 @@
+package consultas
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"veterinaria-server/internal/detalle_factura"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_PrepararCobro(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		consulta:  entity.Consulta{IdConsulta: 5, IdMascota: 2, Valor: 45, EstadoConsulta: EstadoActiva},
+		servicios: []CargoConsulta{{IdReferencia: 11, Descripcion: "Desparasitación", Valor: 10}},
+		examenes:  []CargoConsulta{{IdReferencia: 21, Descripcion: "Coproparasitario", Valor: 15}},
+		recetas:   []RecetaProducto{{IdReceta: 31, IdProducto: 8, Descripcion: "Antiparasitario"}},
+	}
+	s := NewService(repo, logger)
+
+	// producto fuera de la receta
+	_, err := s.PrepararCobro(context.Background(), 5, CobroRequest{Productos: []ProductoDispensadoRequest{{IdReceta: 99, Cantidad: 1}}})
+	assert.NotNil(t, err)
+
+	cobro, err := s.PrepararCobro(context.Background(), 5, CobroRequest{Productos: []ProductoDispensadoRequest{{IdReceta: 31, Cantidad: 2}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, cobro.IdCliente)
+	assert.Equal(t, []ProductoDispensado{{IdReceta: 31, IdProducto: 8, Cantidad: 2}}, cobro.Dispensados)
+	if assert.Len(t, cobro.Detalles, 3) {
+		assert.Equal(t, detalle_factura.TablaConsulta, cobro.Detalles[0].Tabla)
+		assert.Equal(t, 5, cobro.Detalles[0].IdReferencia)
+		assert.Equal(t, money.Money(2000), cobro.Detalles[0].PrecioUnitario)
+		assert.Equal(t, detalle_factura.TablaServicioConsulta, cobro.Detalles[1].Tabla)
+		assert.Equal(t, 11, cobro.Detalles[1].IdReferencia)
+		assert.Equal(t, detalle_factura.TablaExamen, cobro.Detalles[2].Tabla)
+		assert.Equal(t, money.Money(1500), cobro.Detalles[2].PrecioUnitario)
+	}
+
+	// consulta ya facturada
+	repo.facturada = true
+	_, err = s.PrepararCobro(context.Background(), 5, CobroRequest{})
+	assert.NotNil(t, err)
+}
+
+func Test_detallesCobro(t *testing.T) {
+	consulta := entity.Consulta{IdConsulta: 5, Valor: 52.5}
+	servicios := []CargoConsulta{{IdReferencia: 11, Descripcion: "Desparasitación", Valor: 10}}
+	//El hemograma sumo menos que su tipo y el urianalisis sigue pendiente, no sumo nada
+	examenes := []CargoConsulta{{IdReferencia: 21, Descripcion: "Hemograma", Valor: 12.5}, {IdReferencia: 22, Descripcion: "Urianálisis", Valor: 0}}
+
+	detalles := detallesCobro(consulta, servicios, examenes)
+	if assert.Len(t, detalles, 3) {
+		assert.Equal(t, detalle_factura.TablaConsulta, detalles[0].Tabla)
+		assert.Equal(t, money.Money(3000), detalles[0].PrecioUnitario)
+		assert.Equal(t, detalle_factura.TablaExamen, detalles[2].Tabla)
+		assert.Equal(t, 21, detalles[2].IdReferencia)
+		assert.Equal(t, money.Money(1250), detalles[2].PrecioUnitario)
+	}
+	total := money.Zero
+	for _, d := range detalles {
+		total += d.PrecioUnitario
+	}
+	assert.Equal(t, money.FromFloat(float64(consulta.Valor)), total)
+}
+
+type mockRepository struct {
+	consulta  entity.Consulta
+	facturada bool
+	servicios []CargoConsulta
+	examenes  []CargoConsulta
+	recetas   []RecetaProducto
+}
+
+func (m *mockRepository) GetConsultaPorId(ctx context.Context, idConsulta int) (entity.Consulta, error) {
+	if m.consulta.IdConsulta != idConsulta {
+		return entity.Consulta{}, sql.ErrNoRows
+	}
+	return m.consulta, nil
+}
+
+func (m *mockRepository) GetConsultaActiva(ctx context.Context, idUsuario int) (entity.Consulta, error) {
+	return m.consulta, nil
+}
+
+func (m *mockRepository) GetConsultas(ctx context.Context, query pagination.Query) ([]entity.Consulta, *pagination.Pages, error) {
+	items := []entity.Consulta{m.consulta}
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m *mockRepository) GetConsultaPorMesYAnio(ctx context.Context, mes int, anio int) ([]ConsultaConDatos, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) GetConsultaPorMascota(ctx context.Context, idMascota int) ([]entity.Consulta, error) {
+	return []entity.Consulta{m.consulta}, nil
+}
+
+func (m *mockRepository) GetConsultaRecetaServicios(ctx context.Context, idConsulta int) (RecetaServicios, error) {
+	return RecetaServicios{}, nil
+}
+
+func (m *mockRepository) CrearConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error) {
+	m.consulta = consulta
+	return consulta, nil
+}
+
+func (m *mockRepository) ActualizarConsulta(ctx context.Context, consulta entity.Consulta) (entity.Consulta, error) {
+	m.consulta = consulta
+	return consulta, nil
+}
+
+func (m *mockRepository) GetIdClienteConsulta(ctx context.Context, idConsulta int) (int, error) {
+	return 3, nil
+}
+
+func (m *mockRepository) ConsultaFacturada(ctx context.Context, idConsulta int) (bool, error) {
+	return m.facturada, nil
+}
+
+func (m *mockRepository) GetServiciosConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error) {
+	return m.servicios, nil
+}
+
+func (m *mockRepository) GetExamenesConsulta(ctx context.Context, idConsulta int) ([]CargoConsulta, error) {
+	return m.examenes, nil
+}
+
+func (m *mockRepository) GetRecetasConsulta(ctx context.Context, idConsulta int) ([]RecetaProducto, error) {
+	return m.recetas, nil
+}
@@ -2,9 +2,11 @@ package detalle_compra
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetDetalleCompraPorId(ctx context.Context, idDetalleCompra int) (entity.DetalleCompra, error)
 	GetDetalleCompraPorIdCompra(ctx context.Context, idCompra int) ([]DetallesCompraConDatos, error)
 	// GetDetallesCompra returns the list detallesCompra.
-	GetDetallesCompra(ctx context.Context) ([]entity.DetalleCompra, error)
+	GetDetallesCompra(ctx context.Context, query pagination.Query) ([]entity.DetalleCompra, *pagination.Pages, error)
 	CrearDetalleCompra(ctx context.Context, detalleCompra entity.DetalleCompra) (entity.DetalleCompra, error)
 	ActualizarDetalleCompra(ctx context.Context, detalleCompra entity.DetalleCompra) (entity.DetalleCompra, error)
 }
@@ -31,24 +33,34 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesCompra are the fields the list of detalles compra can be filtered, searched and sorted by.
+var camposDetallesCompra = pagination.Fields{
+	Filters: map[string]string{"id_compra": "id_compra", "id_lote": "id_lote"},
+	Sort: map[string]string{
+		"id_detalle_compra": "id_detalle_compra",
+		"id_compra":         "id_compra",
+		"id_lote":           "id_lote",
+		"cantidad":          "cantidad",
+		"valor":             "valor",
+	},
+	DefaultSort: []string{"id_detalle_compra asc"},
+}
+
 // Get reads the list detallesCompra from the database.
-func (r repository) GetDetallesCompra(ctx context.Context) ([]entity.DetalleCompra, error) {
-	var detallesCompra []entity.DetalleCompra
+func (r repository) GetDetallesCompra(ctx context.Context, query pagination.Query) ([]entity.DetalleCompra, *pagination.Pages, error) {
+	var detallesCompra []entity.DetalleCompra = []entity.DetalleCompra{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesCompra)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_compra"), camposDetallesCompra, &detallesCompra)
 	if err != nil {
-		return detallesCompra, err
+		return nil, nil, err
 	}
-	return detallesCompra, err
+	return detallesCompra, pages, nil
 }
 
 // Create saves a new DetalleCompra record in the database.
 // It returns the ID of the newly inserted detalleCompra record.
 func (r repository) CrearDetalleCompra(ctx context.Context, detalleCompra entity.DetalleCompra) (entity.DetalleCompra, error) {
-	err := r.db.With(ctx).Model(&detalleCompra).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleCompra)
 	if err != nil {
 		return entity.DetalleCompra{}, err
 	}
@@ -60,9 +72,9 @@ func (r repository) CrearDetalleCompra(ctx context.Context, detalleCompra entity
 func (r repository) ActualizarDetalleCompra(ctx context.Context, detalleCompra entity.DetalleCompra) (entity.DetalleCompra, error) {
 	var err error
 	if detalleCompra.IdDetalleCompra != 0 {
-		err = r.db.With(ctx).Model(&detalleCompra).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleCompra)
 	} else {
-		err = r.db.With(ctx).Model(&detalleCompra).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleCompra)
 	}
 	if err != nil {
 		return entity.DetalleCompra{}, err
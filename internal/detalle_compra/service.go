@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesCompra.
 type Service interface {
-	GetDetallesCompra(ctx context.Context) ([]DetalleCompra, error)
+	GetDetallesCompra(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleCompraPorId(ctx context.Context, idDetalleCompra int) (DetalleCompra, error)
 	GetDetalleCompraPorIdCompra(ctx context.Context, idCompra int) ([]DetallesCompraConDatos, error)
 	CrearDetalleCompra(ctx context.Context, input CreateDetalleCompraRequest) (DetalleCompra, error)
@@ -38,8 +39,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesCompra.
-func (s service) GetDetallesCompra(ctx context.Context) ([]DetalleCompra, error) {
-	detallesCompra, err := s.repo.GetDetallesCompra(ctx)
+func (s service) GetDetallesCompra(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesCompra, pages, err := s.repo.GetDetallesCompra(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +48,8 @@ func (s service) GetDetallesCompra(ctx context.Context) ([]DetalleCompra, error)
 	for _, item := range detallesCompra {
 		result = append(result, DetalleCompra{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleCompraRequest represents an detalleCompra creation request.
@@ -2,9 +2,11 @@ package detalle_examen_cualitativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetDetalleExamenCualitativoPorId returns the DetalleExamenCualitativo with the specified detalleExamenCualitativo ID.
 	GetDetalleExamenCualitativoPorId(ctx context.Context, idDetalleExamenCualitativo int) (entity.DetallesExamenCualitativo, error)
 	// GetDetallesExamenCualitativo returns the list detallesExamenCualitativo.
-	GetDetallesExamenCualitativo(ctx context.Context) ([]entity.DetallesExamenCualitativo, error)
+	GetDetallesExamenCualitativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenCualitativo, *pagination.Pages, error)
 	GetDetallesExamenCualitativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]entity.DetallesExamenCualitativo, error)
 	CrearDetalleExamenCualitativo(ctx context.Context, detalleExamenCualitativo entity.DetallesExamenCualitativo) (entity.DetallesExamenCualitativo, error)
 	ActualizarDetalleExamenCualitativo(ctx context.Context, detalleExamenCualitativo entity.DetallesExamenCualitativo) (entity.DetallesExamenCualitativo, error)
@@ -31,24 +33,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesExamenCualitativo are the fields the list of detalles examen cualitativo can be filtered, searched and sorted by.
+var camposDetallesExamenCualitativo = pagination.Fields{
+	Filters: map[string]string{"id_tipo_examen": "id_tipo_examen"},
+	Search:  []string{"parametro"},
+	Sort: map[string]string{
+		"id_detalle_examen_cualitativo": "id_detalle_examen_cualitativo",
+		"id_tipo_examen":                "id_tipo_examen",
+		"parametro":                     "parametro",
+	},
+	DefaultSort: []string{"id_detalle_examen_cualitativo asc"},
+}
+
 // Get reads the list detallesExamenCualitativo from the database.
-func (r repository) GetDetallesExamenCualitativo(ctx context.Context) ([]entity.DetallesExamenCualitativo, error) {
-	var detallesExamenCualitativo []entity.DetallesExamenCualitativo
+func (r repository) GetDetallesExamenCualitativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenCualitativo, *pagination.Pages, error) {
+	var detallesExamenCualitativo []entity.DetallesExamenCualitativo = []entity.DetallesExamenCualitativo{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesExamenCualitativo)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_examen_cualitativo"), camposDetallesExamenCualitativo, &detallesExamenCualitativo)
 	if err != nil {
-		return detallesExamenCualitativo, err
+		return nil, nil, err
 	}
-	return detallesExamenCualitativo, err
+	return detallesExamenCualitativo, pages, nil
 }
 
 // Create saves a new DetallesExamenCualitativo record in the database.
 // It returns the ID of the newly inserted detalleExamenCualitativo record.
 func (r repository) CrearDetalleExamenCualitativo(ctx context.Context, detalleExamenCualitativo entity.DetallesExamenCualitativo) (entity.DetallesExamenCualitativo, error) {
-	err := r.db.With(ctx).Model(&detalleExamenCualitativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleExamenCualitativo)
 	if err != nil {
 		return entity.DetallesExamenCualitativo{}, err
 	}
@@ -60,9 +71,9 @@ func (r repository) CrearDetalleExamenCualitativo(ctx context.Context, detalleEx
 func (r repository) ActualizarDetalleExamenCualitativo(ctx context.Context, detalleExamenCualitativo entity.DetallesExamenCualitativo) (entity.DetallesExamenCualitativo, error) {
 	var err error
 	if detalleExamenCualitativo.IdDetalleExamenCualitativo != 0 {
-		err = r.db.With(ctx).Model(&detalleExamenCualitativo).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleExamenCualitativo)
 	} else {
-		err = r.db.With(ctx).Model(&detalleExamenCualitativo).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleExamenCualitativo)
 	}
 	if err != nil {
 		return entity.DetallesExamenCualitativo{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesExamenCualitativo.
 type Service interface {
-	GetDetallesExamenCualitativo(ctx context.Context) ([]DetallesExamenCualitativo, error)
+	GetDetallesExamenCualitativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleExamenCualitativoPorId(ctx context.Context, idDetalleExamenCualitativo int) (DetallesExamenCualitativo, error)
 	GetDetallesExamenCualitativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]DetallesExamenCualitativo, error)
 	CrearDetalleExamenCualitativo(ctx context.Context, input CreateDetalleExamenCualitativoRequest) (DetallesExamenCualitativo, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesExamenCualitativo.
-func (s service) GetDetallesExamenCualitativo(ctx context.Context) ([]DetallesExamenCualitativo, error) {
-	detallesExamenCualitativo, err := s.repo.GetDetallesExamenCualitativo(ctx)
+func (s service) GetDetallesExamenCualitativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesExamenCualitativo, pages, err := s.repo.GetDetallesExamenCualitativo(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetDetallesExamenCualitativo(ctx context.Context) ([]DetallesEx
 	for _, item := range detallesExamenCualitativo {
 		result = append(result, DetallesExamenCualitativo{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleExamenCualitativoRequest represents an detalleExamenCualitativo creation request.
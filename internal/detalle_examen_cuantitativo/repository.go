@@ -2,9 +2,11 @@ package detalle_examen_cuantitativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetDetalleExamenCuantitativoPorId returns the detalleExamenCuantitativo with the specified detalleExamenCuantitativo ID.
 	GetDetalleExamenCuantitativoPorId(ctx context.Context, idDetalleExamenCuantitativo int) (entity.DetallesExamenCuantitativo, error)
 	// GetDetallesExamenCuantitativo returns the list detallesExamenCuantitativo.
-	GetDetallesExamenCuantitativo(ctx context.Context) ([]entity.DetallesExamenCuantitativo, error)
+	GetDetallesExamenCuantitativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenCuantitativo, *pagination.Pages, error)
 	GetDetallesExamenCuantitativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]entity.DetallesExamenCuantitativo, error)
 	CrearDetalleExamenCuantitativo(ctx context.Context, detalleExamenCuantitativo entity.DetallesExamenCuantitativo) (entity.DetallesExamenCuantitativo, error)
 	ActualizarDetalleExamenCuantitativo(ctx context.Context, detalleExamenCuantitativo entity.DetallesExamenCuantitativo) (entity.DetallesExamenCuantitativo, error)
@@ -31,24 +33,39 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesExamenCuantitativo are the fields the list of detalles examen cuantitativo can be filtered, searched and sorted by.
+var camposDetallesExamenCuantitativo = pagination.Fields{
+	Filters: map[string]string{"id_tipo_examen": "id_tipo_examen"},
+	Search:  []string{"parametro", "unidad", "alerta_menor", "alerta_rango", "alerta_mayor"},
+	Sort: map[string]string{
+		"id_detalle_examen_cuantitativo": "id_detalle_examen_cuantitativo",
+		"id_tipo_examen":                 "id_tipo_examen",
+		"parametro":                      "parametro",
+		"rango_referencia_inicial":       "rango_referencia_inicial",
+		"rango_referencia_final":         "rango_referencia_final",
+		"unidad":                         "unidad",
+		"alerta_menor":                   "alerta_menor",
+		"alerta_rango":                   "alerta_rango",
+		"alerta_mayor":                   "alerta_mayor",
+	},
+	DefaultSort: []string{"id_detalle_examen_cuantitativo asc"},
+}
+
 // Get reads the list detallesExamenCuantitativo from the database.
-func (r repository) GetDetallesExamenCuantitativo(ctx context.Context) ([]entity.DetallesExamenCuantitativo, error) {
-	var detallesExamenCuantitativo []entity.DetallesExamenCuantitativo
+func (r repository) GetDetallesExamenCuantitativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenCuantitativo, *pagination.Pages, error) {
+	var detallesExamenCuantitativo []entity.DetallesExamenCuantitativo = []entity.DetallesExamenCuantitativo{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesExamenCuantitativo)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_examen_cuantitativo"), camposDetallesExamenCuantitativo, &detallesExamenCuantitativo)
 	if err != nil {
-		return detallesExamenCuantitativo, err
+		return nil, nil, err
 	}
-	return detallesExamenCuantitativo, err
+	return detallesExamenCuantitativo, pages, nil
 }
 
 // Create saves a new DetallesExamenCuantitativo record in the database.
 // It returns the ID of the newly inserted detalleExamenCuantitativo record.
 func (r repository) CrearDetalleExamenCuantitativo(ctx context.Context, detalleExamenCuantitativo entity.DetallesExamenCuantitativo) (entity.DetallesExamenCuantitativo, error) {
-	err := r.db.With(ctx).Model(&detalleExamenCuantitativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleExamenCuantitativo)
 	if err != nil {
 		return entity.DetallesExamenCuantitativo{}, err
 	}
@@ -60,9 +77,9 @@ func (r repository) CrearDetalleExamenCuantitativo(ctx context.Context, detalleE
 func (r repository) ActualizarDetalleExamenCuantitativo(ctx context.Context, detalleExamenCuantitativo entity.DetallesExamenCuantitativo) (entity.DetallesExamenCuantitativo, error) {
 	var err error
 	if detalleExamenCuantitativo.IdDetalleExamenCuantitativo != 0 {
-		err = r.db.With(ctx).Model(&detalleExamenCuantitativo).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleExamenCuantitativo)
 	} else {
-		err = r.db.With(ctx).Model(&detalleExamenCuantitativo).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleExamenCuantitativo)
 	}
 	if err != nil {
 		return entity.DetallesExamenCuantitativo{}, err
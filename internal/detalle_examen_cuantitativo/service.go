@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesExamenCuantitativo.
 type Service interface {
-	GetDetallesExamenCuantitativo(ctx context.Context) ([]DetallesExamenCuantitativo, error)
+	GetDetallesExamenCuantitativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetallesExamenCuantitativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]DetallesExamenCuantitativo, error)
 	GetDetalleExamenCuantitativoPorId(ctx context.Context, idDetalleExamenCuantitativo int) (DetallesExamenCuantitativo, error)
 	CrearDetalleExamenCuantitativo(ctx context.Context, input CreateDetalleExamenCuantitativoRequest) (DetallesExamenCuantitativo, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesExamenCuantitativo.
-func (s service) GetDetallesExamenCuantitativo(ctx context.Context) ([]DetallesExamenCuantitativo, error) {
-	detallesExamenCuantitativo, err := s.repo.GetDetallesExamenCuantitativo(ctx)
+func (s service) GetDetallesExamenCuantitativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesExamenCuantitativo, pages, err := s.repo.GetDetallesExamenCuantitativo(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetDetallesExamenCuantitativo(ctx context.Context) ([]DetallesE
 	for _, item := range detallesExamenCuantitativo {
 		result = append(result, DetallesExamenCuantitativo{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleExamenCuantitativoRequest represents an detalleExamenCuantitativo creation request.
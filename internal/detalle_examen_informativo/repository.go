@@ -2,9 +2,11 @@ package detalle_examen_informativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetDetalleExamenInformativoPorId returns the detalleExamenInformativo with the specified detalleExamenInformativo ID.
 	GetDetalleExamenInformativoPorId(ctx context.Context, idDetalleExamenInformativo int) (entity.DetallesExamenInformativo, error)
 	// GetDetallesExamenInformativo returns the list detallesExamenInformativo.
-	GetDetallesExamenInformativo(ctx context.Context) ([]entity.DetallesExamenInformativo, error)
+	GetDetallesExamenInformativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenInformativo, *pagination.Pages, error)
 	GetDetallesExamenInformativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]entity.DetallesExamenInformativo, error)
 	CrearDetalleExamenInformativo(ctx context.Context, detalleExamenInformativo entity.DetallesExamenInformativo) (entity.DetallesExamenInformativo, error)
 	ActualizarDetalleExamenInformativo(ctx context.Context, detalleExamenInformativo entity.DetallesExamenInformativo) (entity.DetallesExamenInformativo, error)
@@ -31,24 +33,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesExamenInformativo are the fields the list of detalles examen informativo can be filtered, searched and sorted by.
+var camposDetallesExamenInformativo = pagination.Fields{
+	Filters: map[string]string{"id_tipo_examen": "id_tipo_examen"},
+	Search:  []string{"parametro"},
+	Sort: map[string]string{
+		"id_detalle_examen_informativo": "id_detalle_examen_informativo",
+		"id_tipo_examen":                "id_tipo_examen",
+		"parametro":                     "parametro",
+	},
+	DefaultSort: []string{"id_detalle_examen_informativo asc"},
+}
+
 // Get reads the list detallesExamenInformativo from the database.
-func (r repository) GetDetallesExamenInformativo(ctx context.Context) ([]entity.DetallesExamenInformativo, error) {
-	var detallesExamenInformativo []entity.DetallesExamenInformativo
+func (r repository) GetDetallesExamenInformativo(ctx context.Context, query pagination.Query) ([]entity.DetallesExamenInformativo, *pagination.Pages, error) {
+	var detallesExamenInformativo []entity.DetallesExamenInformativo = []entity.DetallesExamenInformativo{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesExamenInformativo)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_examen_informativo"), camposDetallesExamenInformativo, &detallesExamenInformativo)
 	if err != nil {
-		return detallesExamenInformativo, err
+		return nil, nil, err
 	}
-	return detallesExamenInformativo, err
+	return detallesExamenInformativo, pages, nil
 }
 
 // Create saves a new DetallesExamenInformativo record in the database.
 // It returns the ID of the newly inserted detalleExamenInformativo record.
 func (r repository) CrearDetalleExamenInformativo(ctx context.Context, detalleExamenInformativo entity.DetallesExamenInformativo) (entity.DetallesExamenInformativo, error) {
-	err := r.db.With(ctx).Model(&detalleExamenInformativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleExamenInformativo)
 	if err != nil {
 		return entity.DetallesExamenInformativo{}, err
 	}
@@ -60,9 +71,9 @@ func (r repository) CrearDetalleExamenInformativo(ctx context.Context, detalleEx
 func (r repository) ActualizarDetalleExamenInformativo(ctx context.Context, detalleExamenInformativo entity.DetallesExamenInformativo) (entity.DetallesExamenInformativo, error) {
 	var err error
 	if detalleExamenInformativo.IdDetalleExamenInformativo != 0 {
-		err = r.db.With(ctx).Model(&detalleExamenInformativo).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleExamenInformativo)
 	} else {
-		err = r.db.With(ctx).Model(&detalleExamenInformativo).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleExamenInformativo)
 	}
 	if err != nil {
 		return entity.DetallesExamenInformativo{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesExamenInformativo.
 type Service interface {
-	GetDetallesExamenInformativo(ctx context.Context) ([]DetallesExamenInformativo, error)
+	GetDetallesExamenInformativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleExamenInformativoPorId(ctx context.Context, idDetalleExamenInformativo int) (DetallesExamenInformativo, error)
 	GetDetallesExamenInformativoPorTipoExamen(ctx context.Context, idTipoDeExamen int) ([]DetallesExamenInformativo, error)
 	CrearDetalleExamenInformativo(ctx context.Context, input CreateDetalleExamenInformativoRequest) (DetallesExamenInformativo, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesExamenInformativo.
-func (s service) GetDetallesExamenInformativo(ctx context.Context) ([]DetallesExamenInformativo, error) {
-	detallesExamenInformativo, err := s.repo.GetDetallesExamenInformativo(ctx)
+func (s service) GetDetallesExamenInformativo(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesExamenInformativo, pages, err := s.repo.GetDetallesExamenInformativo(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetDetallesExamenInformativo(ctx context.Context) ([]DetallesEx
 	for _, item := range detallesExamenInformativo {
 		result = append(result, DetallesExamenInformativo{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleExamenInformativoRequest represents an detalleExamenInformativo creation request.
@@ -2,9 +2,11 @@ package detalle_factura
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetDetalleFacturaPorId(ctx context.Context, idDetalleFactura int) (entity.DetalleFactura, error)
 	GetDetalleFacturaPorIdFactura(ctx context.Context, idFactura int) ([]DetallesFacturaConDatos, error)
 	// GetDetallesFactura returns the list detallesFactura.
-	GetDetallesFactura(ctx context.Context) ([]entity.DetalleFactura, error)
+	GetDetallesFactura(ctx context.Context, query pagination.Query) ([]entity.DetalleFactura, *pagination.Pages, error)
 	CrearDetalleFactura(ctx context.Context, detalleFactura entity.DetalleFactura) (entity.DetalleFactura, error)
 	ActualizarDetalleFactura(ctx context.Context, detalleFactura entity.DetalleFactura) (entity.DetalleFactura, error)
 }
@@ -31,24 +33,50 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesFactura are the fields the list of detalles factura can be filtered, searched and sorted by.
+var camposDetallesFactura = pagination.Fields{
+	Filters: map[string]string{
+		"id_factura":    "id_factura",
+		"id_referencia": "id_referencia",
+		"tabla":         "tabla",
+		"origen":        "origen",
+		"id_origen":     "id_origen",
+	},
+	Search: []string{"descripcion"},
+	Sort: map[string]string{
+		"id_detalle_factura": "id_detalle_factura",
+		"id_factura":         "id_factura",
+		"id_referencia":      "id_referencia",
+		"tabla":              "tabla",
+		"cantidad":           "cantidad",
+		"precio_unitario":    "precio_unitario",
+		"descuento":          "descuento",
+		"subtotal":           "subtotal",
+		"porcentaje_iva":     "porcentaje_iva",
+		"valor_iva":          "valor_iva",
+		"valor":              "valor",
+		"descripcion":        "descripcion",
+		"origen":             "origen",
+		"id_origen":          "id_origen",
+	},
+	DefaultSort: []string{"id_detalle_factura asc"},
+}
+
 // Get reads the list detallesFactura from the database.
-func (r repository) GetDetallesFactura(ctx context.Context) ([]entity.DetalleFactura, error) {
-	var detallesFactura []entity.DetalleFactura
+func (r repository) GetDetallesFactura(ctx context.Context, query pagination.Query) ([]entity.DetalleFactura, *pagination.Pages, error) {
+	var detallesFactura []entity.DetalleFactura = []entity.DetalleFactura{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesFactura)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_factura"), camposDetallesFactura, &detallesFactura)
 	if err != nil {
-		return detallesFactura, err
+		return nil, nil, err
 	}
-	return detallesFactura, err
+	return detallesFactura, pages, nil
 }
 
 // Create saves a new DetalleFactura record in the database.
 // It returns the ID of the newly inserted detalleFactura record.
 func (r repository) CrearDetalleFactura(ctx context.Context, detalleFactura entity.DetalleFactura) (entity.DetalleFactura, error) {
-	err := r.db.With(ctx).Model(&detalleFactura).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleFactura)
 	if err != nil {
 		return entity.DetalleFactura{}, err
 	}
@@ -60,9 +88,9 @@ func (r repository) CrearDetalleFactura(ctx context.Context, detalleFactura enti
 func (r repository) ActualizarDetalleFactura(ctx context.Context, detalleFactura entity.DetalleFactura) (entity.DetalleFactura, error) {
 	var err error
 	if detalleFactura.IdDetalleFactura != 0 {
-		err = r.db.With(ctx).Model(&detalleFactura).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleFactura)
 	} else {
-		err = r.db.With(ctx).Model(&detalleFactura).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleFactura)
 	}
 	if err != nil {
 		return entity.DetalleFactura{}, err
@@ -96,7 +124,11 @@ func (r repository) GetDetalleFacturaPorIdFactura(ctx context.Context, idFactura
 
 		idReferencia := detallesFactura[i].IdReferencia
 
-		if detallesFactura[i].Tabla == "lote" {
+		if !EsInventario(detallesFactura[i].Tabla) {
+			if detallesFactura[i].Descripcion != nil {
+				nombreProducto = *detallesFactura[i].Descripcion
+			}
+		} else if detallesFactura[i].Tabla == "lote" {
 			err := r.db.With(ctx).
 				Select("p.descripcion", "l.descripcion").
 				From("lote as l").
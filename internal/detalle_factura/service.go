@@ -3,14 +3,66 @@ package detalle_factura
 import (
 	"context"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// Tablas of the detalles that do not come from inventory. Their IdReferencia points to the
+// record that originated the charge and their Descripcion is printed instead of a producto.
+const (
+	TablaServicioHospitalizacion = "detalles_servicios_hospitalizacion"
+	TablaUsoServicio             = "detalle_usos_servicio"
+	TablaExamen                  = "examenes_mascota"
+	TablaHospitalizacion         = "hospitalizacion"
+	TablaConsulta                = "consulta"
+	TablaServicioConsulta        = "detalles_servicios_consulta"
+)
+
+// Tablas of the detalles that sell from inventory.
+const (
+	TablaLote            = "lote"
+	TablaStockIndividual = "stock_individual"
+)
+
+// OrigenReceta identifies the detalles that dispense a producto prescribed in a receta.
+const OrigenReceta = "receta"
+
+// TablasSinInventario lists the tablas of the detalles that do not move stock.
+var TablasSinInventario = []string{TablaServicioHospitalizacion, TablaUsoServicio, TablaExamen, TablaHospitalizacion, TablaConsulta, TablaServicioConsulta}
+
+// tablas are the tablas a detalle can refer to.
+var tablas = []interface{}{
+	TablaLote, TablaStockIndividual, TablaServicioHospitalizacion, TablaUsoServicio, TablaExamen,
+	TablaHospitalizacion, TablaConsulta, TablaServicioConsulta,
+}
+
+// TablaValida reports whether a detalle can refer to the tabla.
+func TablaValida(tabla string) bool {
+	for _, t := range tablas {
+		if t == tabla {
+			return true
+		}
+	}
+	return false
+}
+
+// EsInventario reports whether the detalle refers to a lote or stock individual.
+func EsInventario(tabla string) bool {
+	for _, t := range TablasSinInventario {
+		if t == tabla {
+			return false
+		}
+	}
+	return true
+}
+
 // Service encapsulates usecase logic for detallesFactura.
 type Service interface {
-	GetDetallesFactura(ctx context.Context) ([]DetalleFactura, error)
+	GetDetallesFactura(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleFacturaPorId(ctx context.Context, idDetalleFactura int) (DetalleFactura, error)
 	GetDetalleFacturaPorIdFactura(ctx context.Context, idFactura int) ([]DetallesFacturaConDatos, error)
 	CrearDetalleFactura(ctx context.Context, input CreateDetalleFacturaRequest) (DetalleFactura, error)
@@ -41,8 +93,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesFactura.
-func (s service) GetDetallesFactura(ctx context.Context) ([]DetalleFactura, error) {
-	detallesFactura, err := s.repo.GetDetallesFactura(ctx)
+func (s service) GetDetallesFactura(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesFactura, pages, err := s.repo.GetDetallesFactura(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -50,25 +102,64 @@ func (s service) GetDetallesFactura(ctx context.Context) ([]DetalleFactura, erro
 	for _, item := range detallesFactura {
 		result = append(result, DetalleFactura{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleFacturaRequest represents an detalleFactura creation request.
+// PrecioUnitario excludes IVA; detalles from inventory are always billed at the precio de venta of the producto.
+// The taxable base, IVA and total of the line are always computed by the server.
 type CreateDetalleFacturaRequest struct {
-	IdFactura    int     `json:"id_factura"`
-	IdReferencia int     `json:"id_referencia"`
-	Tabla        string  `json:"tabla"`
-	Cantidad     float32 `json:"cantidad"`
-	Valor        float32 `json:"valor"`
+	IdFactura      int         `json:"id_factura"`
+	IdReferencia   int         `json:"id_referencia"`
+	Tabla          string      `json:"tabla"`
+	Cantidad       float32     `json:"cantidad"`
+	PrecioUnitario money.Money `json:"precio_unitario"`
+	Descuento      money.Money `json:"descuento"`
+	// IdProducto lets the server pick the lote or stock individual first-expired-first-out
+	// when IdReferencia and Tabla are not sent.
+	IdProducto  int     `json:"id_producto"`
+	Descripcion *string `json:"-"`
+	// GravaIva applies the IVA rate to detalles that do not come from inventory.
+	GravaIva bool `json:"-"`
+	// Origen and IdOrigen identify the clinical record a detalle from inventory was sold for.
+	Origen        *string `json:"-"`
+	IdOrigen      *int    `json:"-"`
+	PorcentajeIva int     `json:"-"`
+	Linea         Linea   `json:"-"`
 }
 
 type UpdateDetalleFacturaRequest struct {
-	IdDetalleFactura int     `json:"id_detalle_factura"`
-	IdFactura        int     `json:"id_factura"`
-	IdReferencia     int     `json:"id_referencia"`
-	Tabla            string  `json:"tabla"`
-	Cantidad         float32 `json:"cantidad"`
-	Valor            float32 `json:"valor"`
+	IdDetalleFactura int         `json:"id_detalle_factura"`
+	IdFactura        int         `json:"id_factura"`
+	IdReferencia     int         `json:"id_referencia"`
+	Tabla            string      `json:"tabla"`
+	Cantidad         float32     `json:"cantidad"`
+	PrecioUnitario   money.Money `json:"precio_unitario"`
+	Descuento        money.Money `json:"descuento"`
+}
+
+// Linea holds the amounts of a detalleFactura computed from its cantidad, precio unitario and descuento.
+type Linea struct {
+	Subtotal money.Money
+	ValorIva money.Money
+	Valor    money.Money
+}
+
+// CalcularLinea computes the taxable base, the IVA and the total of a detalleFactura.
+// porcentajeIva is 0 for productos that do not carry IVA.
+func CalcularLinea(cantidad float32, precioUnitario money.Money, descuento money.Money, porcentajeIva int) (Linea, error) {
+	bruto := precioUnitario.Mul(float64(cantidad))
+	if precioUnitario < 0 || descuento < 0 || descuento > bruto {
+		return Linea{}, errors.BadRequest("El descuento no puede ser negativo ni mayor al valor del detalle.")
+	}
+	subtotal := bruto - descuento
+	valorIva := subtotal.Percent(porcentajeIva)
+	return Linea{
+		Subtotal: subtotal,
+		ValorIva: valorIva,
+		Valor:    subtotal + valorIva,
+	}, nil
 }
 
 // Validate validates the UpdateDetalleFacturaRequest fields.
@@ -76,7 +167,7 @@ func (m UpdateDetalleFacturaRequest) ValidateUpdate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.IdFactura, validation.Required),
 		validation.Field(&m.IdReferencia, validation.Required),
-		validation.Field(&m.Tabla, validation.Required),
+		validation.Field(&m.Tabla, validation.Required, validation.In(tablas...)),
 		validation.Field(&m.Cantidad, validation.Required),
 	)
 }
@@ -86,7 +177,7 @@ func (m CreateDetalleFacturaRequest) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.IdFactura, validation.Required),
 		validation.Field(&m.IdReferencia, validation.Required),
-		validation.Field(&m.Tabla, validation.Required),
+		validation.Field(&m.Tabla, validation.Required, validation.In(tablas...)),
 		validation.Field(&m.Cantidad, validation.Required),
 	)
 }
@@ -97,11 +188,19 @@ func (s service) CrearDetalleFactura(ctx context.Context, req CreateDetalleFactu
 		return DetalleFactura{}, err
 	}
 	detalleFacturaG, err := s.repo.CrearDetalleFactura(ctx, entity.DetalleFactura{
-		IdFactura:    req.IdFactura,
-		IdReferencia: req.IdReferencia,
-		Cantidad:     req.Cantidad,
-		Valor:        req.Valor,
-		Tabla:        req.Tabla,
+		IdFactura:      req.IdFactura,
+		IdReferencia:   req.IdReferencia,
+		Cantidad:       req.Cantidad,
+		PrecioUnitario: req.PrecioUnitario,
+		Descuento:      req.Descuento,
+		Subtotal:       req.Linea.Subtotal,
+		PorcentajeIva:  req.PorcentajeIva,
+		ValorIva:       req.Linea.ValorIva,
+		Valor:          req.Linea.Valor,
+		Tabla:          req.Tabla,
+		Descripcion:    req.Descripcion,
+		Origen:         req.Origen,
+		IdOrigen:       req.IdOrigen,
 	})
 	if err != nil {
 		return DetalleFactura{}, err
@@ -114,13 +213,30 @@ func (s service) ActualizarDetalleFactura(ctx context.Context, req UpdateDetalle
 	if err := req.ValidateUpdate(); err != nil {
 		return DetalleFactura{}, err
 	}
+	//El IVA se mantiene con la tarifa con la que se emitió el detalle
+	detalleFacturaBD, err := s.repo.GetDetalleFacturaPorId(ctx, req.IdDetalleFactura)
+	if err != nil {
+		return DetalleFactura{}, err
+	}
+	linea, err := CalcularLinea(req.Cantidad, req.PrecioUnitario, req.Descuento, detalleFacturaBD.PorcentajeIva)
+	if err != nil {
+		return DetalleFactura{}, err
+	}
 	detalleFacturaG, err := s.repo.ActualizarDetalleFactura(ctx, entity.DetalleFactura{
 		IdDetalleFactura: req.IdDetalleFactura,
 		IdFactura:        req.IdFactura,
 		IdReferencia:     req.IdReferencia,
 		Cantidad:         req.Cantidad,
-		Valor:            req.Valor,
+		PrecioUnitario:   req.PrecioUnitario,
+		Descuento:        req.Descuento,
+		Subtotal:         linea.Subtotal,
+		PorcentajeIva:    detalleFacturaBD.PorcentajeIva,
+		ValorIva:         linea.ValorIva,
+		Valor:            linea.Valor,
 		Tabla:            req.Tabla,
+		Descripcion:      detalleFacturaBD.Descripcion,
+		Origen:           detalleFacturaBD.Origen,
+		IdOrigen:         detalleFacturaBD.IdOrigen,
 	})
 	if err != nil {
 		return DetalleFactura{}, err
@@ -2,9 +2,11 @@ package detalle_hospitalizacion
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetDetalleHospitalizacionPorId returns the detalleHospitalizacion with the specified detalleHospitalizacion ID.
 	GetDetalleHospitalizacionPorId(ctx context.Context, idDetalleHospitalizacion int) (entity.DetalleHospitalizacion, error)
 	// GetDetallesHospitalizacion returns the list detallesHospitalizacion.
-	GetDetallesHospitalizacion(ctx context.Context) ([]entity.DetalleHospitalizacion, error)
+	GetDetallesHospitalizacion(ctx context.Context, query pagination.Query) ([]entity.DetalleHospitalizacion, *pagination.Pages, error)
 	GetDetalleHospitalizacionPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error)
 	GetDetalleHospitalizacionPorHospitalizacion2(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error)
 	CrearDetalleHospitalizacion(ctx context.Context, detalleHospitalizacion entity.DetalleHospitalizacion) (entity.DetalleHospitalizacion, error)
@@ -32,18 +34,30 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesHospitalizacion are the fields the list of detalles hospitalizacion can be filtered, searched and sorted by.
+var camposDetallesHospitalizacion = pagination.Fields{
+	Filters: map[string]string{"id_hospitalizacion": "id_hospitalizacion", "id_usuario": "id_usuario"},
+	Ranges:  map[string]string{"fecha": "fecha"},
+	Search:  []string{"descripcion"},
+	Sort: map[string]string{
+		"id_detalle_hospitalizacion": "id_detalle_hospitalizacion",
+		"id_hospitalizacion":         "id_hospitalizacion",
+		"id_usuario":                 "id_usuario",
+		"descripcion":                "descripcion",
+		"fecha":                      "fecha",
+	},
+	DefaultSort: []string{"id_detalle_hospitalizacion asc"},
+}
+
 // Get reads the list detallesHospitalizacion from the database.
-func (r repository) GetDetallesHospitalizacion(ctx context.Context) ([]entity.DetalleHospitalizacion, error) {
-	var detallesHospitalizacion []entity.DetalleHospitalizacion
+func (r repository) GetDetallesHospitalizacion(ctx context.Context, query pagination.Query) ([]entity.DetalleHospitalizacion, *pagination.Pages, error) {
+	var detallesHospitalizacion []entity.DetalleHospitalizacion = []entity.DetalleHospitalizacion{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesHospitalizacion)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_hospitalizacion"), camposDetallesHospitalizacion, &detallesHospitalizacion)
 	if err != nil {
-		return detallesHospitalizacion, err
+		return nil, nil, err
 	}
-	return detallesHospitalizacion, err
+	return detallesHospitalizacion, pages, nil
 }
 
 func (r repository) GetDetalleHospitalizacionPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error) {
@@ -123,7 +137,7 @@ func (r repository) GetDetalleHospitalizacionPorHospitalizacion2(ctx context.Con
 // Create saves a new DetalleHospitalizacion record in the database.
 // It returns the ID of the newly inserted detalleHospitalizacion record.
 func (r repository) CrearDetalleHospitalizacion(ctx context.Context, detalleHospitalizacion entity.DetalleHospitalizacion) (entity.DetalleHospitalizacion, error) {
-	err := r.db.With(ctx).Model(&detalleHospitalizacion).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleHospitalizacion)
 	if err != nil {
 		return entity.DetalleHospitalizacion{}, err
 	}
@@ -135,9 +149,9 @@ func (r repository) CrearDetalleHospitalizacion(ctx context.Context, detalleHosp
 func (r repository) ActualizarDetalleHospitalizacion(ctx context.Context, detalleHospitalizacion entity.DetalleHospitalizacion) (entity.DetalleHospitalizacion, error) {
 	var err error
 	if detalleHospitalizacion.IdDetalleHospitalizacion != 0 {
-		err = r.db.With(ctx).Model(&detalleHospitalizacion).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleHospitalizacion)
 	} else {
-		err = r.db.With(ctx).Model(&detalleHospitalizacion).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleHospitalizacion)
 	}
 	if err != nil {
 		return entity.DetalleHospitalizacion{}, err
@@ -3,15 +3,17 @@ package detalle_hospitalizacion
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesHospitalizacion.
 type Service interface {
-	GetDetallesHospitalizacion(ctx context.Context) ([]DetalleHospitalizacion, error)
+	GetDetallesHospitalizacion(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleHospitalizacionPorId(ctx context.Context, idDetalleHospitalizacion int) (DetalleHospitalizacion, error)
 	GetDetalleHospitalizacionPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error)
 	GetDetalleHospitalizacionPorHospitalizacion2(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error)
@@ -40,8 +42,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesHospitalizacion.
-func (s service) GetDetallesHospitalizacion(ctx context.Context) ([]DetalleHospitalizacion, error) {
-	detallesHospitalizacion, err := s.repo.GetDetallesHospitalizacion(ctx)
+func (s service) GetDetallesHospitalizacion(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesHospitalizacion, pages, err := s.repo.GetDetallesHospitalizacion(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +51,8 @@ func (s service) GetDetallesHospitalizacion(ctx context.Context) ([]DetalleHospi
 	for _, item := range detallesHospitalizacion {
 		result = append(result, DetalleHospitalizacion{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetDetalleHospitalizacionPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]DetalleHospitalizacionConResponsable, error) {
@@ -104,6 +107,11 @@ func (m CreateDetalleHospitalizacionRequest) Validate() error {
 
 // CrearDetalleHospitalizacion creates a new detalleHospitalizacion.
 func (s service) CrearDetalleHospitalizacion(ctx context.Context, req CreateDetalleHospitalizacionRequest) (DetalleHospitalizacion, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return DetalleHospitalizacion{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return DetalleHospitalizacion{}, err
 	}
@@ -121,6 +129,19 @@ func (s service) CrearDetalleHospitalizacion(ctx context.Context, req CreateDeta
 
 // ActualizarDetalleHospitalizacion creates a new detalleHospitalizacion.
 func (s service) ActualizarDetalleHospitalizacion(ctx context.Context, req UpdateDetalleHospitalizacionRequest) (DetalleHospitalizacion, error) {
+	var idGuardado int
+	if req.IdDetalleHospitalizacion != 0 {
+		detalleHospitalizacionBD, err := s.repo.GetDetalleHospitalizacionPorId(ctx, req.IdDetalleHospitalizacion)
+		if err != nil {
+			return DetalleHospitalizacion{}, err
+		}
+		idGuardado = detalleHospitalizacionBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return DetalleHospitalizacion{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return DetalleHospitalizacion{}, err
 	}
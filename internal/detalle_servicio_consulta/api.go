@@ -6,10 +6,10 @@ import (
 	"veterinaria-server/internal/consultas"
 	"veterinaria-server/internal/detalle_uso_servicio_consulta"
 	"veterinaria-server/internal/errors"
-	"veterinaria-server/internal/lote"
-	"veterinaria-server/internal/stock_individual"
+	"veterinaria-server/internal/movimiento_inventario"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -34,11 +34,12 @@ type resource struct {
 }
 
 func (r resource) getDetallesServicioConsulta(c *routing.Context) error {
-	detallesServicioConsulta, err := r.service.GetDetallesServicioConsulta(c.Request.Context())
+	pages, err := r.service.GetDetallesServicioConsulta(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(detallesServicioConsulta)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearDetalleServicioConsulta(c *routing.Context) error {
@@ -92,6 +93,40 @@ func (r resource) crearDetalleServicioConsultaConDetalles(c *routing.Context) er
 		return errors.BadRequest("")
 	}
 
+	sm := movimiento_inventario.NewService(movimiento_inventario.NewRepository(r.db, r.logger), r.logger)
+
+	//Asigna los lotes por FEFO cuando solo se envía el producto
+	detalles := []detalle_uso_servicio_consulta.CreateDetalleUsoServicioConsultaRequest{}
+	asignado := []movimiento_inventario.ItemStock{}
+	for _, detalle := range input.Productos {
+		if detalle.IdProducto == 0 {
+			detalles = append(detalles, detalle)
+			continue
+		}
+		asignaciones, err := sm.AsignarFEFO(c.Request.Context(), detalle.IdProducto, detalle.Cantidad, asignado)
+		if err != nil {
+			return err
+		}
+		asignado = append(asignado, asignaciones...)
+		for _, asignacion := range asignaciones {
+			d := detalle
+			d.Tabla = asignacion.Tabla
+			d.IdReferencia = asignacion.IdReferencia
+			d.Cantidad = asignacion.Cantidad
+			detalles = append(detalles, d)
+		}
+	}
+	input.Productos = detalles
+
+	//Verifica que exista stock suficiente antes de registrar
+	items := []movimiento_inventario.ItemStock{}
+	for _, detalle := range input.Productos {
+		items = append(items, movimiento_inventario.ItemStock{Tabla: detalle.Tabla, IdReferencia: detalle.IdReferencia, Cantidad: detalle.Cantidad})
+	}
+	if err := sm.VerificarDisponibilidad(c.Request.Context(), items); err != nil {
+		return err
+	}
+
 	detalleServicioConsultaG, err := r.service.CrearDetalleServicioConsulta(c.Request.Context(), input.DetalleServicioConsulta)
 	if err != nil {
 		return err
@@ -106,58 +141,17 @@ func (r resource) crearDetalleServicioConsultaConDetalles(c *routing.Context) er
 		if err != nil {
 			return err
 		}
-		if detalleUsoServicioConsultaG.Tabla == "lote" {
-			s2 := lote.NewService(lote.NewRepository(r.db, r.logger), r.logger)
-			productoVenderBD, err2 := s2.GetLotePorId(c.Request.Context(), detalleUsoServicioConsultaG.IdReferencia)
-			if err2 != nil {
-				return err2
-			}
-			_, err3 := s2.ActualizarLote(c.Request.Context(),
-				lote.UpdateLoteRequest{
-					IdLote:              productoVenderBD.IdLote,
-					Descripcion:         productoVenderBD.Descripcion,
-					IdProveedorProducto: productoVenderBD.IdProveedorProducto,
-					FechaCaducidad:      productoVenderBD.FechaCaducidad,
-					Stock:               productoVenderBD.Stock - int(detalleUsoServicioConsultaG.Cantidad),
-				})
-			if err3 != nil {
-				return err3
-			}
-		} else {
-			s2 := stock_individual.NewService(stock_individual.NewRepository(r.db, r.logger), r.logger)
-			productoVenderBD, err2 := s2.GetStockIndividualPorId(c.Request.Context(), detalleUsoServicioConsultaG.IdReferencia)
-			if err2 != nil {
-				return err2
-			}
-			_, err3 := s2.ActualizarStockIndividual(c.Request.Context(),
-				stock_individual.UpdateStockIndividualRequest{
-					IdLote:            productoVenderBD.IdLote,
-					Descripcion:       productoVenderBD.Descripcion,
-					IdStockIndividual: productoVenderBD.IdStockIndividual,
-					CantidadInicial:   productoVenderBD.CantidadInicial,
-					Cantidad:          productoVenderBD.Cantidad - detalleUsoServicioConsultaG.Cantidad,
-				})
-			if err3 != nil {
-				return err3
-			}
-			if (productoVenderBD.Cantidad - detalleUsoServicioConsultaG.Cantidad) == float32(0) {
-				s2 := lote.NewService(lote.NewRepository(r.db, r.logger), r.logger)
-				productoVenderBD, err2 := s2.GetLotePorId(c.Request.Context(), productoVenderBD.IdLote)
-				if err2 != nil {
-					return err2
-				}
-				_, err3 := s2.ActualizarLote(c.Request.Context(),
-					lote.UpdateLoteRequest{
-						IdLote:              productoVenderBD.IdLote,
-						Descripcion:         productoVenderBD.Descripcion,
-						IdProveedorProducto: productoVenderBD.IdProveedorProducto,
-						FechaCaducidad:      productoVenderBD.FechaCaducidad,
-						Stock:               productoVenderBD.Stock - 1,
-					})
-				if err3 != nil {
-					return err3
-				}
-			}
+		origen := "detalle_usos_servicio_consulta"
+		_, err = sm.RegistrarMovimiento(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+			Tabla:        movimiento_inventario.TablaDetalle(detalleUsoServicioConsultaG.Tabla),
+			IdReferencia: detalleUsoServicioConsultaG.IdReferencia,
+			Tipo:         movimiento_inventario.TipoUsoServicio,
+			Cantidad:     -detalleUsoServicioConsultaG.Cantidad,
+			Origen:       &origen,
+			IdOrigen:     &detalleUsoServicioConsultaG.IdDetalleUsoServicioConsulta,
+		})
+		if err != nil {
+			return err
 		}
 
 		detallesUsoServicioConsultaG = append(detallesUsoServicioConsultaG, detalleUsoServicioConsultaG)
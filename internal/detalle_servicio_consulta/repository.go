@@ -2,9 +2,11 @@ package detalle_servicio_consulta
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetDetalleServicioConsultaPorId(ctx context.Context, idDetalleServicioConsulta int) (entity.DetalleServicioConsulta, error)
 	GetDetalleServicioConsultaPorConsulta(ctx context.Context, idConsulta int) ([]DetalleServicioConsultaConDatos, error)
 	// GetDetallesServicioConsulta returns the list detallesServicioConsulta.
-	GetDetallesServicioConsulta(ctx context.Context) ([]entity.DetalleServicioConsulta, error)
+	GetDetallesServicioConsulta(ctx context.Context, query pagination.Query) ([]entity.DetalleServicioConsulta, *pagination.Pages, error)
 	CrearDetalleServicioConsulta(ctx context.Context, detalleServicioConsulta entity.DetalleServicioConsulta) (entity.DetalleServicioConsulta, error)
 	ActualizarDetalleServicioConsulta(ctx context.Context, detalleServicioConsulta entity.DetalleServicioConsulta) (entity.DetalleServicioConsulta, error)
 }
@@ -31,24 +33,35 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesServicioConsulta are the fields the list of detalles servicio consulta can be filtered, searched and sorted by.
+var camposDetallesServicioConsulta = pagination.Fields{
+	Filters: map[string]string{"id_consulta": "id_consulta", "id_servicio": "id_servicio"},
+	Ranges:  map[string]string{"fecha": "fecha"},
+	Sort: map[string]string{
+		"id_detalle_servicio_consulta": "id_detalle_servicio_consulta",
+		"id_consulta":                  "id_consulta",
+		"id_servicio":                  "id_servicio",
+		"valor":                        "valor",
+		"fecha":                        "fecha",
+	},
+	DefaultSort: []string{"id_detalle_servicio_consulta asc"},
+}
+
 // Get reads the list detallesServicioConsulta from the database.
-func (r repository) GetDetallesServicioConsulta(ctx context.Context) ([]entity.DetalleServicioConsulta, error) {
-	var detallesServicioConsulta []entity.DetalleServicioConsulta
+func (r repository) GetDetallesServicioConsulta(ctx context.Context, query pagination.Query) ([]entity.DetalleServicioConsulta, *pagination.Pages, error) {
+	var detallesServicioConsulta []entity.DetalleServicioConsulta = []entity.DetalleServicioConsulta{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesServicioConsulta)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_servicios_consulta"), camposDetallesServicioConsulta, &detallesServicioConsulta)
 	if err != nil {
-		return detallesServicioConsulta, err
+		return nil, nil, err
 	}
-	return detallesServicioConsulta, err
+	return detallesServicioConsulta, pages, nil
 }
 
 // Create saves a new DetalleServicioConsulta record in the database.
 // It returns the ID of the newly inserted detalleServicioConsulta record.
 func (r repository) CrearDetalleServicioConsulta(ctx context.Context, detalleServicioConsulta entity.DetalleServicioConsulta) (entity.DetalleServicioConsulta, error) {
-	err := r.db.With(ctx).Model(&detalleServicioConsulta).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleServicioConsulta)
 	if err != nil {
 		return entity.DetalleServicioConsulta{}, err
 	}
@@ -60,9 +73,9 @@ func (r repository) CrearDetalleServicioConsulta(ctx context.Context, detalleSer
 func (r repository) ActualizarDetalleServicioConsulta(ctx context.Context, detalleServicioConsulta entity.DetalleServicioConsulta) (entity.DetalleServicioConsulta, error) {
 	var err error
 	if detalleServicioConsulta.IdDetalleServicioConsulta != 0 {
-		err = r.db.With(ctx).Model(&detalleServicioConsulta).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleServicioConsulta)
 	} else {
-		err = r.db.With(ctx).Model(&detalleServicioConsulta).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleServicioConsulta)
 	}
 	if err != nil {
 		return entity.DetalleServicioConsulta{}, err
@@ -6,13 +6,14 @@ import (
 	"veterinaria-server/internal/detalle_uso_servicio_consulta"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesServicioConsulta.
 type Service interface {
-	GetDetallesServicioConsulta(ctx context.Context) ([]DetalleServicioConsulta, error)
+	GetDetallesServicioConsulta(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleServicioConsultaPorId(ctx context.Context, idDetalleServicioConsulta int) (DetalleServicioConsulta, error)
 	GetDetalleServicioConsultaPorConsulta(ctx context.Context, idConsulta int) ([]DetalleServicioConsultaConDatos, error)
 	CrearDetalleServicioConsulta(ctx context.Context, input CreateDetalleServicioConsultaRequest) (DetalleServicioConsulta, error)
@@ -39,8 +40,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesServicioConsulta.
-func (s service) GetDetallesServicioConsulta(ctx context.Context) ([]DetalleServicioConsulta, error) {
-	detallesServicioConsulta, err := s.repo.GetDetallesServicioConsulta(ctx)
+func (s service) GetDetallesServicioConsulta(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesServicioConsulta, pages, err := s.repo.GetDetallesServicioConsulta(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +49,8 @@ func (s service) GetDetallesServicioConsulta(ctx context.Context) ([]DetalleServ
 	for _, item := range detallesServicioConsulta {
 		result = append(result, DetalleServicioConsulta{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 type CreateDetalleServicioConsultaConDetallesRequest struct {
@@ -2,9 +2,11 @@ package detalle_servicio_hospitalizacion
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access detallesServicioHospitalizacion from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetDetalleServicioHospitalizacionPorId returns the detalleServicioHospitalizacion with the specified detalleServicioHospitalizacion ID.
 	GetDetalleServicioHospitalizacionPorId(ctx context.Context, idDetalleServicioHospitalizacion int) (entity.DetalleServicioHospitalizacion, error)
 	// GetDetallesServicioHospitalizacion returns the list detallesServicioHospitalizacion.
-	GetDetallesServicioHospitalizacion(ctx context.Context) ([]entity.DetalleServicioHospitalizacion, error)
+	GetDetallesServicioHospitalizacion(ctx context.Context, query pagination.Query) ([]entity.DetalleServicioHospitalizacion, *pagination.Pages, error)
 	CrearDetalleServicioHospitalizacion(ctx context.Context, detalleServicioHospitalizacion entity.DetalleServicioHospitalizacion) (entity.DetalleServicioHospitalizacion, error)
 	ActualizarDetalleServicioHospitalizacion(ctx context.Context, detalleServicioHospitalizacion entity.DetalleServicioHospitalizacion) (entity.DetalleServicioHospitalizacion, error)
 }
@@ -28,24 +30,40 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesServicioHospitalizacion are the fields the list of detalles servicio hospitalizacion can be filtered, searched and sorted by.
+var camposDetallesServicioHospitalizacion = pagination.Fields{
+	Filters: map[string]string{
+		"id_hospitalizacion": "id_hospitalizacion",
+		"id_usuario":         "id_usuario",
+		"id_servicio":        "id_servicio",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Sort: map[string]string{
+		"id_detalle_servicio_hospitalizacion": "id_detalle_servicio_hospitalizacion",
+		"id_hospitalizacion":                  "id_hospitalizacion",
+		"id_usuario":                          "id_usuario",
+		"id_servicio":                         "id_servicio",
+		"valor":                               "valor",
+		"fecha":                               "fecha",
+	},
+	DefaultSort: []string{"id_detalle_servicio_hospitalizacion asc"},
+}
+
 // Get reads the list detallesServicioHospitalizacion from the database.
-func (r repository) GetDetallesServicioHospitalizacion(ctx context.Context) ([]entity.DetalleServicioHospitalizacion, error) {
-	var detallesServicioHospitalizacion []entity.DetalleServicioHospitalizacion
+func (r repository) GetDetallesServicioHospitalizacion(ctx context.Context, query pagination.Query) ([]entity.DetalleServicioHospitalizacion, *pagination.Pages, error) {
+	var detallesServicioHospitalizacion []entity.DetalleServicioHospitalizacion = []entity.DetalleServicioHospitalizacion{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesServicioHospitalizacion)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalles_servicios_hospitalizacion"), camposDetallesServicioHospitalizacion, &detallesServicioHospitalizacion)
 	if err != nil {
-		return detallesServicioHospitalizacion, err
+		return nil, nil, err
 	}
-	return detallesServicioHospitalizacion, err
+	return detallesServicioHospitalizacion, pages, nil
 }
 
 // Create saves a new DetalleServicioHospitalizacion record in the database.
 // It returns the ID of the newly inserted detalleServicioHospitalizacion record.
 func (r repository) CrearDetalleServicioHospitalizacion(ctx context.Context, detalleServicioHospitalizacion entity.DetalleServicioHospitalizacion) (entity.DetalleServicioHospitalizacion, error) {
-	err := r.db.With(ctx).Model(&detalleServicioHospitalizacion).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleServicioHospitalizacion)
 	if err != nil {
 		return entity.DetalleServicioHospitalizacion{}, err
 	}
@@ -57,9 +75,9 @@ func (r repository) CrearDetalleServicioHospitalizacion(ctx context.Context, det
 func (r repository) ActualizarDetalleServicioHospitalizacion(ctx context.Context, detalleServicioHospitalizacion entity.DetalleServicioHospitalizacion) (entity.DetalleServicioHospitalizacion, error) {
 	var err error
 	if detalleServicioHospitalizacion.IdDetalleServicioHospitalizacion != 0 {
-		err = r.db.With(ctx).Model(&detalleServicioHospitalizacion).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleServicioHospitalizacion)
 	} else {
-		err = r.db.With(ctx).Model(&detalleServicioHospitalizacion).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleServicioHospitalizacion)
 	}
 	if err != nil {
 		return entity.DetalleServicioHospitalizacion{}, err
@@ -3,16 +3,18 @@ package detalle_servicio_hospitalizacion
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/detalle_uso_servicio"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesServicioHospitalizacion.
 type Service interface {
-	GetDetallesServicioHospitalizacion(ctx context.Context) ([]DetalleServicioHospitalizacion, error)
+	GetDetallesServicioHospitalizacion(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleServicioHospitalizacionPorId(ctx context.Context, idDetalleServicioHospitalizacion int) (DetalleServicioHospitalizacion, error)
 	CrearDetalleServicioHospitalizacion(ctx context.Context, input CreateDetalleServicioHospitalizacionRequest) (DetalleServicioHospitalizacion, error)
 	ActualizarDetalleServicioHospitalizacion(ctx context.Context, input UpdateDetalleServicioHospitalizacionRequest) (DetalleServicioHospitalizacion, error)
@@ -34,8 +36,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesServicioHospitalizacion.
-func (s service) GetDetallesServicioHospitalizacion(ctx context.Context) ([]DetalleServicioHospitalizacion, error) {
-	detallesServicioHospitalizacion, err := s.repo.GetDetallesServicioHospitalizacion(ctx)
+func (s service) GetDetallesServicioHospitalizacion(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesServicioHospitalizacion, pages, err := s.repo.GetDetallesServicioHospitalizacion(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +45,8 @@ func (s service) GetDetallesServicioHospitalizacion(ctx context.Context) ([]Deta
 	for _, item := range detallesServicioHospitalizacion {
 		result = append(result, DetalleServicioHospitalizacion{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 type CreateDetalleServicioHospitalizacionConDetallesRequest struct {
@@ -90,6 +93,11 @@ func (m CreateDetalleServicioHospitalizacionRequest) Validate() error {
 
 // CrearDetalleServicioHospitalizacion creates a new detalleServicioHospitalizacion.
 func (s service) CrearDetalleServicioHospitalizacion(ctx context.Context, req CreateDetalleServicioHospitalizacionRequest) (DetalleServicioHospitalizacion, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return DetalleServicioHospitalizacion{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return DetalleServicioHospitalizacion{}, err
 	}
@@ -108,6 +116,19 @@ func (s service) CrearDetalleServicioHospitalizacion(ctx context.Context, req Cr
 
 // ActualizarDetalleServicioHospitalizacion creates a new detalleServicioHospitalizacion.
 func (s service) ActualizarDetalleServicioHospitalizacion(ctx context.Context, req UpdateDetalleServicioHospitalizacionRequest) (DetalleServicioHospitalizacion, error) {
+	var idGuardado int
+	if req.IdDetalleServicioHospitalizacion != 0 {
+		detalleBD, err := s.repo.GetDetalleServicioHospitalizacionPorId(ctx, req.IdDetalleServicioHospitalizacion)
+		if err != nil {
+			return DetalleServicioHospitalizacion{}, err
+		}
+		idGuardado = detalleBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return DetalleServicioHospitalizacion{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return DetalleServicioHospitalizacion{}, err
 	}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -26,11 +27,12 @@ type resource struct {
 }
 
 func (r resource) getDetallesUsoServicio(c *routing.Context) error {
-	detallesUsoServicio, err := r.service.GetDetallesUsoServicio(c.Request.Context())
+	pages, err := r.service.GetDetallesUsoServicio(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(detallesUsoServicio)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearDetalleUsoServicio(c *routing.Context) error {
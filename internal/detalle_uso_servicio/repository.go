@@ -2,9 +2,11 @@ package detalle_uso_servicio
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access detallesUsoServicio from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetDetalleUsoServicioPorId returns the detalleUsoServicio with the specified detalleUsoServicio ID.
 	GetDetalleUsoServicioPorId(ctx context.Context, idDetalleUsoServicio int) (entity.DetalleUsoServicio, error)
 	// GetDetallesUsoServicio returns the list detallesUsoServicio.
-	GetDetallesUsoServicio(ctx context.Context) ([]entity.DetalleUsoServicio, error)
+	GetDetallesUsoServicio(ctx context.Context, query pagination.Query) ([]entity.DetalleUsoServicio, *pagination.Pages, error)
 	CrearDetalleUsoServicio(ctx context.Context, detalleUsoServicio entity.DetalleUsoServicio) (entity.DetalleUsoServicio, error)
 	ActualizarDetalleUsoServicio(ctx context.Context, detalleUsoServicio entity.DetalleUsoServicio) (entity.DetalleUsoServicio, error)
 }
@@ -28,24 +30,38 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesUsoServicio are the fields the list of detalles uso servicio can be filtered, searched and sorted by.
+var camposDetallesUsoServicio = pagination.Fields{
+	Filters: map[string]string{
+		"id_detalle_servicio_hospitalizacion": "id_detalle_servicio_hospitalizacion",
+		"id_referencia":                       "id_referencia",
+		"tabla":                               "tabla",
+	},
+	Sort: map[string]string{
+		"id_detalle_uso_servicio":             "id_detalle_uso_servicio",
+		"id_detalle_servicio_hospitalizacion": "id_detalle_servicio_hospitalizacion",
+		"id_referencia":                       "id_referencia",
+		"tabla":                               "tabla",
+		"cantidad":                            "cantidad",
+	},
+	DefaultSort: []string{"id_detalle_uso_servicio asc"},
+}
+
 // Get reads the list detallesUsoServicio from the database.
-func (r repository) GetDetallesUsoServicio(ctx context.Context) ([]entity.DetalleUsoServicio, error) {
-	var detallesUsoServicio []entity.DetalleUsoServicio
+func (r repository) GetDetallesUsoServicio(ctx context.Context, query pagination.Query) ([]entity.DetalleUsoServicio, *pagination.Pages, error) {
+	var detallesUsoServicio []entity.DetalleUsoServicio = []entity.DetalleUsoServicio{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesUsoServicio)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalle_usos_servicio"), camposDetallesUsoServicio, &detallesUsoServicio)
 	if err != nil {
-		return detallesUsoServicio, err
+		return nil, nil, err
 	}
-	return detallesUsoServicio, err
+	return detallesUsoServicio, pages, nil
 }
 
 // Create saves a new DetalleUsoServicio record in the database.
 // It returns the ID of the newly inserted detalleUsoServicio record.
 func (r repository) CrearDetalleUsoServicio(ctx context.Context, detalleUsoServicio entity.DetalleUsoServicio) (entity.DetalleUsoServicio, error) {
-	err := r.db.With(ctx).Model(&detalleUsoServicio).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleUsoServicio)
 	if err != nil {
 		return entity.DetalleUsoServicio{}, err
 	}
@@ -57,9 +73,9 @@ func (r repository) CrearDetalleUsoServicio(ctx context.Context, detalleUsoServi
 func (r repository) ActualizarDetalleUsoServicio(ctx context.Context, detalleUsoServicio entity.DetalleUsoServicio) (entity.DetalleUsoServicio, error) {
 	var err error
 	if detalleUsoServicio.IdDetalleUsoServicio != 0 {
-		err = r.db.With(ctx).Model(&detalleUsoServicio).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleUsoServicio)
 	} else {
-		err = r.db.With(ctx).Model(&detalleUsoServicio).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleUsoServicio)
 	}
 	if err != nil {
 		return entity.DetalleUsoServicio{}, err
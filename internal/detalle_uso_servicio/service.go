@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesUsoServicio.
 type Service interface {
-	GetDetallesUsoServicio(ctx context.Context) ([]DetalleUsoServicio, error)
+	GetDetallesUsoServicio(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleUsoServicioPorId(ctx context.Context, idDetalleUsoServicio int) (DetalleUsoServicio, error)
 	CrearDetalleUsoServicio(ctx context.Context, input CreateDetalleUsoServicioRequest) (DetalleUsoServicio, error)
 	ActualizarDetalleUsoServicio(ctx context.Context, input UpdateDetalleUsoServicioRequest) (DetalleUsoServicio, error)
@@ -32,8 +33,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesUsoServicio.
-func (s service) GetDetallesUsoServicio(ctx context.Context) ([]DetalleUsoServicio, error) {
-	detallesUsoServicio, err := s.repo.GetDetallesUsoServicio(ctx)
+func (s service) GetDetallesUsoServicio(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesUsoServicio, pages, err := s.repo.GetDetallesUsoServicio(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +42,8 @@ func (s service) GetDetallesUsoServicio(ctx context.Context) ([]DetalleUsoServic
 	for _, item := range detallesUsoServicio {
 		result = append(result, DetalleUsoServicio{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleUsoServicioRequest represents an detalleUsoServicio creation request.
@@ -50,6 +52,9 @@ type CreateDetalleUsoServicioRequest struct {
 	IdReferencia                     int     `json:"id_referencia"`
 	Tabla                            string  `json:"tabla"`
 	Cantidad                         float32 `json:"cantidad"`
+	// IdProducto lets the server pick the lote or stock individual first-expired-first-out
+	// when IdReferencia and Tabla are not sent.
+	IdProducto int `json:"id_producto"`
 }
 
 type UpdateDetalleUsoServicioRequest struct {
@@ -2,9 +2,11 @@ package detalle_uso_servicio_consulta
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access detallesUsoServicioConsulta from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetDetalleUsoServicioConsultaPorId returns the detalleUsoServicioConsulta with the specified detalleUsoServicioConsulta ID.
 	GetDetalleUsoServicioConsultaPorId(ctx context.Context, idDetalleUsoServicioConsulta int) (entity.DetalleUsoServicioConsulta, error)
 	// GetDetallesUsoServicioConsulta returns the list detallesUsoServicioConsulta.
-	GetDetallesUsoServicioConsulta(ctx context.Context) ([]entity.DetalleUsoServicioConsulta, error)
+	GetDetallesUsoServicioConsulta(ctx context.Context, query pagination.Query) ([]entity.DetalleUsoServicioConsulta, *pagination.Pages, error)
 	CrearDetalleUsoServicioConsulta(ctx context.Context, detalleUsoServicioConsulta entity.DetalleUsoServicioConsulta) (entity.DetalleUsoServicioConsulta, error)
 	ActualizarDetalleUsoServicioConsulta(ctx context.Context, detalleUsoServicioConsulta entity.DetalleUsoServicioConsulta) (entity.DetalleUsoServicioConsulta, error)
 }
@@ -28,24 +30,38 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDetallesUsoServicioConsulta are the fields the list of detalles uso servicio consulta can be filtered, searched and sorted by.
+var camposDetallesUsoServicioConsulta = pagination.Fields{
+	Filters: map[string]string{
+		"id_detalle_servicio_consulta": "id_detalle_servicio_consulta",
+		"id_referencia":                "id_referencia",
+		"tabla":                        "tabla",
+	},
+	Sort: map[string]string{
+		"id_detalle_uso_servicio_consulta": "id_detalle_uso_servicio_consulta",
+		"id_detalle_servicio_consulta":     "id_detalle_servicio_consulta",
+		"id_referencia":                    "id_referencia",
+		"tabla":                            "tabla",
+		"cantidad":                         "cantidad",
+	},
+	DefaultSort: []string{"id_detalle_uso_servicio_consulta asc"},
+}
+
 // Get reads the list detallesUsoServicioConsulta from the database.
-func (r repository) GetDetallesUsoServicioConsulta(ctx context.Context) ([]entity.DetalleUsoServicioConsulta, error) {
-	var detallesUsoServicioConsulta []entity.DetalleUsoServicioConsulta
+func (r repository) GetDetallesUsoServicioConsulta(ctx context.Context, query pagination.Query) ([]entity.DetalleUsoServicioConsulta, *pagination.Pages, error) {
+	var detallesUsoServicioConsulta []entity.DetalleUsoServicioConsulta = []entity.DetalleUsoServicioConsulta{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&detallesUsoServicioConsulta)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("detalle_usos_servicio_consulta"), camposDetallesUsoServicioConsulta, &detallesUsoServicioConsulta)
 	if err != nil {
-		return detallesUsoServicioConsulta, err
+		return nil, nil, err
 	}
-	return detallesUsoServicioConsulta, err
+	return detallesUsoServicioConsulta, pages, nil
 }
 
 // Create saves a new DetalleUsoServicioConsulta record in the database.
 // It returns the ID of the newly inserted detalleUsoServicioConsulta record.
 func (r repository) CrearDetalleUsoServicioConsulta(ctx context.Context, detalleUsoServicioConsulta entity.DetalleUsoServicioConsulta) (entity.DetalleUsoServicioConsulta, error) {
-	err := r.db.With(ctx).Model(&detalleUsoServicioConsulta).Insert()
+	err := auditoria.Insertar(ctx, r.db, &detalleUsoServicioConsulta)
 	if err != nil {
 		return entity.DetalleUsoServicioConsulta{}, err
 	}
@@ -57,9 +73,9 @@ func (r repository) CrearDetalleUsoServicioConsulta(ctx context.Context, detalle
 func (r repository) ActualizarDetalleUsoServicioConsulta(ctx context.Context, detalleUsoServicioConsulta entity.DetalleUsoServicioConsulta) (entity.DetalleUsoServicioConsulta, error) {
 	var err error
 	if detalleUsoServicioConsulta.IdDetalleUsoServicioConsulta != 0 {
-		err = r.db.With(ctx).Model(&detalleUsoServicioConsulta).Update()
+		err = auditoria.Actualizar(ctx, r.db, &detalleUsoServicioConsulta)
 	} else {
-		err = r.db.With(ctx).Model(&detalleUsoServicioConsulta).Insert()
+		err = auditoria.Insertar(ctx, r.db, &detalleUsoServicioConsulta)
 	}
 	if err != nil {
 		return entity.DetalleUsoServicioConsulta{}, err
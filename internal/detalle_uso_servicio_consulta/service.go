@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for detallesUsoServicioConsulta.
 type Service interface {
-	GetDetallesUsoServicioConsulta(ctx context.Context) ([]DetalleUsoServicioConsulta, error)
+	GetDetallesUsoServicioConsulta(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDetalleUsoServicioConsultaPorId(ctx context.Context, idDetalleUsoServicioConsulta int) (DetalleUsoServicioConsulta, error)
 	CrearDetalleUsoServicioConsulta(ctx context.Context, input CreateDetalleUsoServicioConsultaRequest) (DetalleUsoServicioConsulta, error)
 	ActualizarDetalleUsoServicioConsulta(ctx context.Context, input UpdateDetalleUsoServicioConsultaRequest) (DetalleUsoServicioConsulta, error)
@@ -32,8 +33,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list detallesUsoServicioConsulta.
-func (s service) GetDetallesUsoServicioConsulta(ctx context.Context) ([]DetalleUsoServicioConsulta, error) {
-	detallesUsoServicioConsulta, err := s.repo.GetDetallesUsoServicioConsulta(ctx)
+func (s service) GetDetallesUsoServicioConsulta(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	detallesUsoServicioConsulta, pages, err := s.repo.GetDetallesUsoServicioConsulta(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +42,8 @@ func (s service) GetDetallesUsoServicioConsulta(ctx context.Context) ([]DetalleU
 	for _, item := range detallesUsoServicioConsulta {
 		result = append(result, DetalleUsoServicioConsulta{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateDetalleUsoServicioConsultaRequest represents an detalleUsoServicioConsulta creation request.
@@ -50,6 +52,9 @@ type CreateDetalleUsoServicioConsultaRequest struct {
 	IdReferencia              int     `json:"id_referencia"`
 	Tabla                     string  `json:"tabla"`
 	Cantidad                  float32 `json:"cantidad"`
+	// IdProducto lets the server pick the lote or stock individual first-expired-first-out
+	// when IdReferencia and Tabla are not sent.
+	IdProducto int `json:"id_producto"`
 }
 
 type UpdateDetalleUsoServicioConsultaRequest struct {
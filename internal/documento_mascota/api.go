@@ -1,20 +1,25 @@
 package documento_mascota
 
 import (
+	"bytes"
 	"encoding/base64"
 	"net/http"
-	"os"
-	"runtime"
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+	"veterinaria-server/pkg/storage"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
 
+// RutaCargas is the path, relative to the static files of the API, where the uploaded documentos are served.
+const RutaCargas = "documentosMascota/"
+
 // RegisterHandlers sets up the routing of the HTTP handlers.
-func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
-	res := resource{service, logger}
+// The uploaded documentos are saved in cargas.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, cargas storage.Storage) {
+	res := resource{service, logger, cargas}
 	r.Use(authHandler)
 	// the following endpoints require a valid JWT
 	r.Get("/documentosMascota", res.getDocumentosMascota)
@@ -27,14 +32,16 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 type resource struct {
 	service Service
 	logger  log.Logger
+	cargas  storage.Storage
 }
 
 func (r resource) getDocumentosMascota(c *routing.Context) error {
-	documentosMascota, err := r.service.GetDocumentosMascota(c.Request.Context())
+	pages, err := r.service.GetDocumentosMascota(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(documentosMascota)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getDocumentoMascotaPorMascota(c *routing.Context) error {
@@ -67,31 +74,14 @@ func (r resource) actualizarDocumentoMascota(c *routing.Context) error {
 	}
 	dec, err := base64.StdEncoding.DecodeString(input.Base64)
 	if err != nil {
-		panic(err)
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("El documento no está codificado en base64.")
 	}
 
 	input.Nombre = input.Nombre + " - " + input.Fecha.Format("2006-01-02")
-	if runtime.GOOS == "windows" {
-		input.Ruta = "./documentos-mascota/"
-	} else {
-		input.Ruta = "/root/go/src/github.com/JorgeTom0609/veterinaria-server/documentos-mascota/"
-	}
-	var f *os.File
-	if runtime.GOOS == "windows" {
-		f, err = os.Create("./documentos-mascota/" + input.Nombre + "." + input.Extension)
-	} else {
-		f, err = os.Create("/root/go/src/github.com/JorgeTom0609/veterinaria-server/documentos-mascota/" + input.Nombre + "." + input.Extension)
-	}
-	if err != nil {
-		panic(err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(dec); err != nil {
-		panic(err)
-	}
-	if err := f.Sync(); err != nil {
-		panic(err)
+	input.Ruta = RutaCargas
+	if err := r.cargas.Save(c.Request.Context(), input.Nombre+"."+input.Extension, bytes.NewReader(dec)); err != nil {
+		return err
 	}
 
 	documentoMascota, err := r.service.ActualizarDocumentoMascota(c.Request.Context(), input)
@@ -99,11 +89,6 @@ func (r resource) actualizarDocumentoMascota(c *routing.Context) error {
 		return err
 	}
 
-	/*
-		f.Seek(0, 0)
-		io.Copy(os.Stdout, f)
-	*/
-
 	return c.WriteWithStatus(documentoMascota, http.StatusCreated)
 }
 
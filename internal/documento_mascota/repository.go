@@ -2,9 +2,11 @@ package documento_mascota
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetDocumentoMascotaPorId returns the documentoMascota with the specified documentoMascota ID.
 	GetDocumentoMascotaPorId(ctx context.Context, idDocumentoMascota int) (entity.DocumentoMascota, error)
 	// GetDocumentosMascota returns the list documentosMascota.
-	GetDocumentosMascota(ctx context.Context) ([]entity.DocumentoMascota, error)
+	GetDocumentosMascota(ctx context.Context, query pagination.Query) ([]entity.DocumentoMascota, *pagination.Pages, error)
 	GetDocumentoMascotaPorMascota(ctx context.Context, idMascota int) ([]entity.DocumentoMascota, error)
 	CrearDocumentoMascota(ctx context.Context, documentoMascota entity.DocumentoMascota) (entity.DocumentoMascota, error)
 	ActualizarDocumentoMascota(ctx context.Context, documentoMascota entity.DocumentoMascota) (entity.DocumentoMascota, error)
@@ -31,18 +33,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposDocumentosMascota are the fields the list of documentos mascota can be filtered, searched and sorted by.
+var camposDocumentosMascota = pagination.Fields{
+	Filters: map[string]string{"id_mascota": "id_mascota", "id_usuario": "id_usuario"},
+	Ranges:  map[string]string{"fecha": "fecha"},
+	Search:  []string{"nombre", "extension", "ruta", "descripcion"},
+	Sort: map[string]string{
+		"id_documento_mascota": "id_documento_mascota",
+		"id_mascota":           "id_mascota",
+		"id_usuario":           "id_usuario",
+		"nombre":               "nombre",
+		"extension":            "extension",
+		"ruta":                 "ruta",
+		"descripcion":          "descripcion",
+		"fecha":                "fecha",
+	},
+	DefaultSort: []string{"id_documento_mascota asc"},
+}
+
 // Get reads the list documentosMascota from the database.
-func (r repository) GetDocumentosMascota(ctx context.Context) ([]entity.DocumentoMascota, error) {
-	var documentosMascota []entity.DocumentoMascota
+func (r repository) GetDocumentosMascota(ctx context.Context, query pagination.Query) ([]entity.DocumentoMascota, *pagination.Pages, error) {
+	var documentosMascota []entity.DocumentoMascota = []entity.DocumentoMascota{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&documentosMascota)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("documento_mascota"), camposDocumentosMascota, &documentosMascota)
 	if err != nil {
-		return documentosMascota, err
+		return nil, nil, err
 	}
-	return documentosMascota, err
+	return documentosMascota, pages, nil
 }
 
 func (r repository) GetDocumentoMascotaPorMascota(ctx context.Context, idMascota int) ([]entity.DocumentoMascota, error) {
@@ -61,7 +78,7 @@ func (r repository) GetDocumentoMascotaPorMascota(ctx context.Context, idMascota
 // Create saves a new DocumentoMascota record in the database.
 // It returns the ID of the newly inserted documentoMascota record.
 func (r repository) CrearDocumentoMascota(ctx context.Context, documentoMascota entity.DocumentoMascota) (entity.DocumentoMascota, error) {
-	err := r.db.With(ctx).Model(&documentoMascota).Insert()
+	err := auditoria.Insertar(ctx, r.db, &documentoMascota)
 	if err != nil {
 		return entity.DocumentoMascota{}, err
 	}
@@ -73,9 +90,9 @@ func (r repository) CrearDocumentoMascota(ctx context.Context, documentoMascota
 func (r repository) ActualizarDocumentoMascota(ctx context.Context, documentoMascota entity.DocumentoMascota) (entity.DocumentoMascota, error) {
 	var err error
 	if documentoMascota.IdDocumentoMascota != 0 {
-		err = r.db.With(ctx).Model(&documentoMascota).Update()
+		err = auditoria.Actualizar(ctx, r.db, &documentoMascota)
 	} else {
-		err = r.db.With(ctx).Model(&documentoMascota).Insert()
+		err = auditoria.Insertar(ctx, r.db, &documentoMascota)
 	}
 	if err != nil {
 		return entity.DocumentoMascota{}, err
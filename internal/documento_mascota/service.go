@@ -3,15 +3,17 @@ package documento_mascota
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for documentosMascota.
 type Service interface {
-	GetDocumentosMascota(ctx context.Context) ([]DocumentoMascota, error)
+	GetDocumentosMascota(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetDocumentoMascotaPorId(ctx context.Context, idDocumentoMascota int) (DocumentoMascota, error)
 	GetDocumentoMascotaPorMascota(ctx context.Context, idMascota int) ([]DocumentoMascota, error)
 	CrearDocumentoMascota(ctx context.Context, input CreateDocumentoMascotaRequest) (DocumentoMascota, error)
@@ -34,8 +36,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list documentosMascota.
-func (s service) GetDocumentosMascota(ctx context.Context) ([]DocumentoMascota, error) {
-	documentosMascota, err := s.repo.GetDocumentosMascota(ctx)
+func (s service) GetDocumentosMascota(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	documentosMascota, pages, err := s.repo.GetDocumentosMascota(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +45,8 @@ func (s service) GetDocumentosMascota(ctx context.Context) ([]DocumentoMascota,
 	for _, item := range documentosMascota {
 		result = append(result, DocumentoMascota{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetDocumentoMascotaPorMascota(ctx context.Context, idMascota int) ([]DocumentoMascota, error) {
@@ -106,6 +109,11 @@ func (m CreateDocumentoMascotaRequest) Validate() error {
 
 // CrearDocumentoMascota creates a new documentoMascota.
 func (s service) CrearDocumentoMascota(ctx context.Context, req CreateDocumentoMascotaRequest) (DocumentoMascota, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return DocumentoMascota{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return DocumentoMascota{}, err
 	}
@@ -126,6 +134,19 @@ func (s service) CrearDocumentoMascota(ctx context.Context, req CreateDocumentoM
 
 // ActualizarDocumentoMascota creates a new documentoMascota.
 func (s service) ActualizarDocumentoMascota(ctx context.Context, req UpdateDocumentoMascotaRequest) (DocumentoMascota, error) {
+	var idGuardado int
+	if req.IdDocumentoMascota != 0 {
+		documentoBD, err := s.repo.GetDocumentoMascotaPorId(ctx, req.IdDocumentoMascota)
+		if err != nil {
+			return DocumentoMascota{}, err
+		}
+		idGuardado = documentoBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return DocumentoMascota{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return DocumentoMascota{}, err
 	}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Auditoria represents an insert or update of a record, with its values before and after it as JSON.
+type Auditoria struct {
+	IdAuditoria   int       `json:"id_auditoria" db:"pk,id_auditoria"`
+	IdUsuario     *int      `json:"id_usuario" db:"id_usuario"`
+	NombreUsuario *string   `json:"nombre_usuario" db:"nombre_usuario"`
+	Fecha         time.Time `json:"fecha" db:"fecha"`
+	Entidad       string    `json:"entidad" db:"entidad"`
+	IdRegistro    string    `json:"id_registro" db:"id_registro"`
+	Accion        string    `json:"accion" db:"accion"`
+	Antes         *string   `json:"antes" db:"antes"`
+	Despues       *string   `json:"despues" db:"despues"`
+}
+
+func (a Auditoria) TableName() string {
+	return "auditoria"
+}
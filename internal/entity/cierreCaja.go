@@ -0,0 +1,16 @@
+package entity
+
+import "veterinaria-server/pkg/money"
+
+type CierreCaja struct {
+	IdCierreCaja int         `json:"id_cierre_caja" db:"pk,id_cierre_caja"`
+	IdSesionCaja int         `json:"id_sesion_caja" db:"id_sesion_caja"`
+	MetodoPago   string      `json:"metodo_pago" db:"metodo_pago"`
+	Esperado     money.Money `json:"esperado" db:"esperado"`
+	Contado      money.Money `json:"contado" db:"contado"`
+	Diferencia   money.Money `json:"diferencia" db:"diferencia"`
+}
+
+func (c CierreCaja) TableName() string {
+	return "cierres_caja"
+}
@@ -5,9 +5,15 @@ import "time"
 type CitaMedica struct {
 	IdCitaMedica       int       `json:"id_cita_medica" db:"pk,id_cita_medica"`
 	IdMascota          int       `json:"id_mascota" db:"id_mascota"`
+	IdTipoCita         *int      `json:"id_tipo_cita" db:"id_tipo_cita"`
+	IdUsuario          *int      `json:"id_usuario" db:"id_usuario"`
 	Motivo             string    `json:"motivo" db:"motivo"`
 	Fecha              time.Time `json:"fecha" db:"fecha"`
+	DuracionMinutos    int       `json:"duracion_minutos" db:"duracion_minutos"`
+	Sala               *string   `json:"sala" db:"sala"`
+	Estado             string    `json:"estado" db:"estado"`
 	EstadoNotificacion string    `json:"estado_notificacion" db:"estado_notificacion"`
+	EstadoConfirmacion string    `json:"estado_confirmacion" db:"estado_confirmacion"`
 }
 
 func (c CitaMedica) TableName() string {
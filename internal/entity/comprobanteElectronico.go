@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type ComprobanteElectronico struct {
+	IdComprobanteElectronico int        `json:"id_comprobante_electronico" db:"pk,id_comprobante_electronico"`
+	IdFactura                int        `json:"id_factura" db:"id_factura"`
+	CodDoc                   string     `json:"cod_doc" db:"cod_doc"`
+	Establecimiento          string     `json:"establecimiento" db:"establecimiento"`
+	PuntoEmision             string     `json:"punto_emision" db:"punto_emision"`
+	Secuencial               int        `json:"secuencial" db:"secuencial"`
+	ClaveAcceso              string     `json:"clave_acceso" db:"clave_acceso"`
+	Xml                      string     `json:"-" db:"xml"`
+	Estado                   string     `json:"estado" db:"estado"`
+	NumeroAutorizacion       *string    `json:"numero_autorizacion" db:"numero_autorizacion"`
+	FechaAutorizacion        *time.Time `json:"fecha_autorizacion" db:"fecha_autorizacion"`
+	Mensajes                 *string    `json:"mensajes" db:"mensajes"`
+	Fecha                    time.Time  `json:"fecha" db:"fecha"`
+}
+
+func (c ComprobanteElectronico) TableName() string {
+	return "comprobantes_electronicos"
+}
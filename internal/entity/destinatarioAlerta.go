@@ -0,0 +1,14 @@
+package entity
+
+type DestinatarioAlerta struct {
+	IdDestinatarioAlerta int     `json:"id_destinatario_alerta" db:"pk,id_destinatario_alerta"`
+	TipoAlerta           string  `json:"tipo_alerta" db:"tipo_alerta"`
+	Canal                string  `json:"canal" db:"canal"`
+	Destino              string  `json:"destino" db:"destino"`
+	Nombre               *string `json:"nombre" db:"nombre"`
+	Activo               bool    `json:"activo" db:"activo"`
+}
+
+func (d DestinatarioAlerta) TableName() string {
+	return "destinatarios_alerta"
+}
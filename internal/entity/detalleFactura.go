@@ -1,12 +1,22 @@
 package entity
 
+import "veterinaria-server/pkg/money"
+
 type DetalleFactura struct {
-	IdDetalleFactura int     `json:"id_detalle_factura" db:"pk,id_detalle_factura"`
-	IdFactura        int     `json:"id_factura" db:"id_factura"`
-	IdReferencia     int     `json:"id_referencia" db:"id_referencia"`
-	Tabla            string  `json:"tabla" db:"tabla"`
-	Cantidad         float32 `json:"cantidad" db:"cantidad"`
-	Valor            float32 `json:"valor" db:"valor"`
+	IdDetalleFactura int         `json:"id_detalle_factura" db:"pk,id_detalle_factura"`
+	IdFactura        int         `json:"id_factura" db:"id_factura"`
+	IdReferencia     int         `json:"id_referencia" db:"id_referencia"`
+	Tabla            string      `json:"tabla" db:"tabla"`
+	Cantidad         float32     `json:"cantidad" db:"cantidad"`
+	PrecioUnitario   money.Money `json:"precio_unitario" db:"precio_unitario"`
+	Descuento        money.Money `json:"descuento" db:"descuento"`
+	Subtotal         money.Money `json:"subtotal" db:"subtotal"`
+	PorcentajeIva    int         `json:"porcentaje_iva" db:"porcentaje_iva"`
+	ValorIva         money.Money `json:"valor_iva" db:"valor_iva"`
+	Valor            money.Money `json:"valor" db:"valor"`
+	Descripcion      *string     `json:"descripcion" db:"descripcion"`
+	Origen           *string     `json:"origen" db:"origen"`
+	IdOrigen         *int        `json:"id_origen" db:"id_origen"`
 }
 
 func (d DetalleFactura) TableName() string {
@@ -13,6 +13,7 @@ type ExamenMascota struct {
 	Estado          string     `json:"estado" db:"estado"`
 	IdReferencia    int        `json:"id_referencia" db:"id_referencia"`
 	Tabla           string     `json:"tabla" db:"tabla"`
+	Valor           float32    `json:"valor" db:"valor"` //Lo que el examen sumo al valor de la consulta u hospitalizacion
 }
 
 func (em ExamenMascota) TableName() string {
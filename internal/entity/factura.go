@@ -1,13 +1,25 @@
 package entity
 
-import "time"
+import (
+	"time"
+	"veterinaria-server/pkg/money"
+)
 
 type Factura struct {
-	IdFactura int       `json:"id_factura" db:"pk,id_factura"`
-	IdCliente int       `json:"id_cliente" db:"id_cliente"`
-	IdUsuario int       `json:"id_usuario" db:"id_usuario"`
-	Fecha     time.Time `json:"fecha" db:"fecha"`
-	Valor     float32   `json:"valor" db:"valor"`
+	IdFactura     int         `json:"id_factura" db:"pk,id_factura"`
+	IdCliente     int         `json:"id_cliente" db:"id_cliente"`
+	IdUsuario     int         `json:"id_usuario" db:"id_usuario"`
+	Fecha         time.Time   `json:"fecha" db:"fecha"`
+	Subtotal0     money.Money `json:"subtotal_0" db:"subtotal_0"`
+	SubtotalIva   money.Money `json:"subtotal_iva" db:"subtotal_iva"`
+	Descuento     money.Money `json:"descuento" db:"descuento"`
+	PorcentajeIva int         `json:"porcentaje_iva" db:"porcentaje_iva"`
+	Iva           money.Money `json:"iva" db:"iva"`
+	Valor         money.Money `json:"valor" db:"valor"`
+	Anulada       bool        `json:"anulada" db:"anulada"`
+	IdSesionCaja  *int        `json:"id_sesion_caja" db:"id_sesion_caja"`
+	Origen        *string     `json:"origen" db:"origen"`
+	IdOrigen      *int        `json:"id_origen" db:"id_origen"`
 }
 
 func (f Factura) TableName() string {
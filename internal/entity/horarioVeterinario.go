@@ -0,0 +1,15 @@
+package entity
+
+type HorarioVeterinario struct {
+	IdHorarioVeterinario int `json:"id_horario_veterinario" db:"pk,id_horario_veterinario"`
+	IdUsuario            int `json:"id_usuario" db:"id_usuario"`
+	//0 domingo a 6 sabado
+	DiaSemana  int    `json:"dia_semana" db:"dia_semana"`
+	HoraInicio string `json:"hora_inicio" db:"hora_inicio"`
+	HoraFin    string `json:"hora_fin" db:"hora_fin"`
+	Activo     bool   `json:"activo" db:"activo"`
+}
+
+func (h HorarioVeterinario) TableName() string {
+	return "horarios_veterinario"
+}
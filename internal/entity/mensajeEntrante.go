@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+type MensajeEntrante struct {
+	IdMensajeEntrante int       `json:"id_mensaje_entrante" db:"pk,id_mensaje_entrante"`
+	Canal             string    `json:"canal" db:"canal"`
+	Remitente         string    `json:"remitente" db:"remitente"`
+	IdCliente         *int      `json:"id_cliente" db:"id_cliente"`
+	IdCitaMedica      *int      `json:"id_cita_medica" db:"id_cita_medica"`
+	Cuerpo            string    `json:"cuerpo" db:"cuerpo"`
+	Accion            string    `json:"accion" db:"accion"`
+	Fecha             time.Time `json:"fecha" db:"fecha"`
+}
+
+func (m MensajeEntrante) TableName() string {
+	return "mensajes_entrantes"
+}
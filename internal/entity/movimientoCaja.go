@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+	"veterinaria-server/pkg/money"
+)
+
+type MovimientoCaja struct {
+	IdMovimientoCaja int         `json:"id_movimiento_caja" db:"pk,id_movimiento_caja"`
+	IdSesionCaja     int         `json:"id_sesion_caja" db:"id_sesion_caja"`
+	IdUsuario        int         `json:"id_usuario" db:"id_usuario"`
+	Tipo             string      `json:"tipo" db:"tipo"`
+	Concepto         string      `json:"concepto" db:"concepto"`
+	Monto            money.Money `json:"monto" db:"monto"`
+	Fecha            time.Time   `json:"fecha" db:"fecha"`
+}
+
+func (m MovimientoCaja) TableName() string {
+	return "movimientos_caja"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type MovimientoInventario struct {
+	IdMovimientoInventario int       `json:"id_movimiento_inventario" db:"pk,id_movimiento_inventario"`
+	IdLote                 int       `json:"id_lote" db:"id_lote"`
+	IdStockIndividual      *int      `json:"id_stock_individual" db:"id_stock_individual"`
+	Tipo                   string    `json:"tipo" db:"tipo"`
+	Cantidad               float32   `json:"cantidad" db:"cantidad"`
+	StockResultante        float32   `json:"stock_resultante" db:"stock_resultante"`
+	Origen                 *string   `json:"origen" db:"origen"`
+	IdOrigen               *int      `json:"id_origen" db:"id_origen"`
+	IdUsuario              *int      `json:"id_usuario" db:"id_usuario"`
+	Fecha                  time.Time `json:"fecha" db:"fecha"`
+	Observacion            *string   `json:"observacion" db:"observacion"`
+}
+
+func (m MovimientoInventario) TableName() string {
+	return "movimientos_inventario"
+}
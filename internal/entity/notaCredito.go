@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+	"veterinaria-server/pkg/money"
+)
+
+type NotaCredito struct {
+	IdNotaCredito int         `json:"id_nota_credito" db:"pk,id_nota_credito"`
+	IdFactura     int         `json:"id_factura" db:"id_factura"`
+	IdUsuario     int         `json:"id_usuario" db:"id_usuario"`
+	Fecha         time.Time   `json:"fecha" db:"fecha"`
+	Motivo        string      `json:"motivo" db:"motivo"`
+	Valor         money.Money `json:"valor" db:"valor"`
+}
+
+func (n NotaCredito) TableName() string {
+	return "notas_credito"
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type Notificacion struct {
+	IdNotificacion      int        `json:"id_notificacion" db:"pk,id_notificacion"`
+	IdCliente           *int       `json:"id_cliente" db:"id_cliente"`
+	Tabla               *string    `json:"tabla" db:"tabla"`
+	IdReferencia        *int       `json:"id_referencia" db:"id_referencia"`
+	Canal               string     `json:"canal" db:"canal"`
+	Destinatario        string     `json:"destinatario" db:"destinatario"`
+	CodigoPlantilla     string     `json:"codigo_plantilla" db:"codigo_plantilla"`
+	Asunto              string     `json:"asunto" db:"asunto"`
+	Cuerpo              string     `json:"cuerpo" db:"cuerpo"`
+	Estado              string     `json:"estado" db:"estado"`
+	Intentos            int        `json:"intentos" db:"intentos"`
+	UltimoError         *string    `json:"ultimo_error" db:"ultimo_error"`
+	FechaCreacion       time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaProximoIntento *time.Time `json:"fecha_proximo_intento" db:"fecha_proximo_intento"`
+	FechaEnvio          *time.Time `json:"fecha_envio" db:"fecha_envio"`
+}
+
+func (n Notificacion) TableName() string {
+	return "notificaciones"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+	"veterinaria-server/pkg/money"
+)
+
+type Pago struct {
+	IdPago            int         `json:"id_pago" db:"pk,id_pago"`
+	IdCliente         int         `json:"id_cliente" db:"id_cliente"`
+	IdFactura         *int        `json:"id_factura" db:"id_factura"`
+	IdHospitalizacion *int        `json:"id_hospitalizacion" db:"id_hospitalizacion"`
+	IdUsuario         *int        `json:"id_usuario" db:"id_usuario"`
+	Fecha             time.Time   `json:"fecha" db:"fecha"`
+	MetodoPago        string      `json:"metodo_pago" db:"metodo_pago"`
+	Monto             money.Money `json:"monto" db:"monto"`
+	Referencia        *string     `json:"referencia" db:"referencia"`
+	IdSesionCaja      *int        `json:"id_sesion_caja" db:"id_sesion_caja"`
+}
+
+func (p Pago) TableName() string {
+	return "pagos"
+}
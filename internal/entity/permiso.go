@@ -0,0 +1,12 @@
+package entity
+
+// Permiso represents an action of a modulo of the API that can be granted to a rol.
+type Permiso struct {
+	IdPermiso   int    `json:"id_permiso" db:"pk,id_permiso"`
+	Codigo      string `json:"codigo" db:"codigo"`
+	Descripcion string `json:"descripcion" db:"descripcion"`
+}
+
+func (p Permiso) TableName() string {
+	return "permisos"
+}
@@ -0,0 +1,13 @@
+package entity
+
+type PlantillaNotificacion struct {
+	IdPlantillaNotificacion int    `json:"id_plantilla_notificacion" db:"pk,id_plantilla_notificacion"`
+	Codigo                  string `json:"codigo" db:"codigo"`
+	Canal                   string `json:"canal" db:"canal"`
+	Asunto                  string `json:"asunto" db:"asunto"`
+	Cuerpo                  string `json:"cuerpo" db:"cuerpo"`
+}
+
+func (p PlantillaNotificacion) TableName() string {
+	return "plantillas_notificacion"
+}
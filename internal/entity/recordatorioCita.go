@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type RecordatorioCita struct {
+	IdRecordatorioCita int       `json:"id_recordatorio_cita" db:"pk,id_recordatorio_cita"`
+	IdCitaMedica       int       `json:"id_cita_medica" db:"id_cita_medica"`
+	MinutosAntes       int       `json:"minutos_antes" db:"minutos_antes"`
+	FechaCita          time.Time `json:"fecha_cita" db:"fecha_cita"`
+	Estado             string    `json:"estado" db:"estado"`
+	FechaRegistro      time.Time `json:"fecha_registro" db:"fecha_registro"`
+}
+
+func (r RecordatorioCita) TableName() string {
+	return "recordatorios_cita"
+}
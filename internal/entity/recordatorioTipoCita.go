@@ -0,0 +1,12 @@
+package entity
+
+type RecordatorioTipoCita struct {
+	IdRecordatorioTipoCita int  `json:"id_recordatorio_tipo_cita" db:"pk,id_recordatorio_tipo_cita"`
+	IdTipoCita             *int `json:"id_tipo_cita" db:"id_tipo_cita"`
+	MinutosAntes           int  `json:"minutos_antes" db:"minutos_antes"`
+	Activo                 bool `json:"activo" db:"activo"`
+}
+
+func (r RecordatorioTipoCita) TableName() string {
+	return "recordatorios_tipo_cita"
+}
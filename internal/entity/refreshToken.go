@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// RefreshToken represents a refresh token of a sesionUsuario. Only the hash of the token is stored.
+type RefreshToken struct {
+	IdRefreshToken  int       `json:"id_refresh_token" db:"pk,id_refresh_token"`
+	IdSesionUsuario int       `json:"id_sesion_usuario" db:"id_sesion_usuario"`
+	TokenHash       string    `json:"-" db:"token_hash"`
+	FechaCreacion   time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaExpiracion time.Time `json:"fecha_expiracion" db:"fecha_expiracion"`
+	Usado           bool      `json:"usado" db:"usado"`
+}
+
+func (r RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
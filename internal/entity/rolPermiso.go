@@ -0,0 +1,12 @@
+package entity
+
+// RolPermiso represents a permiso granted to a rol.
+type RolPermiso struct {
+	IdRolPermiso int `json:"id_rol_permiso" db:"pk,id_rol_permiso"`
+	IdRol        int `json:"id_rol" db:"id_rol"`
+	IdPermiso    int `json:"id_permiso" db:"id_permiso"`
+}
+
+func (rp RolPermiso) TableName() string {
+	return "rol_permiso"
+}
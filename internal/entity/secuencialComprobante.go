@@ -0,0 +1,13 @@
+package entity
+
+type SecuencialComprobante struct {
+	IdSecuencialComprobante int    `json:"id_secuencial_comprobante" db:"pk,id_secuencial_comprobante"`
+	CodDoc                  string `json:"cod_doc" db:"cod_doc"`
+	Establecimiento         string `json:"establecimiento" db:"establecimiento"`
+	PuntoEmision            string `json:"punto_emision" db:"punto_emision"`
+	Secuencial              int    `json:"secuencial" db:"secuencial"`
+}
+
+func (s SecuencialComprobante) TableName() string {
+	return "secuenciales_comprobante"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+	"veterinaria-server/pkg/money"
+)
+
+type SesionCaja struct {
+	IdSesionCaja  int          `json:"id_sesion_caja" db:"pk,id_sesion_caja"`
+	IdUsuario     int          `json:"id_usuario" db:"id_usuario"`
+	FechaApertura time.Time    `json:"fecha_apertura" db:"fecha_apertura"`
+	MontoInicial  money.Money  `json:"monto_inicial" db:"monto_inicial"`
+	FechaCierre   *time.Time   `json:"fecha_cierre" db:"fecha_cierre"`
+	MontoContado  *money.Money `json:"monto_contado" db:"monto_contado"`
+	Diferencia    *money.Money `json:"diferencia" db:"diferencia"`
+	Estado        string       `json:"estado" db:"estado"`
+	Observacion   *string      `json:"observacion" db:"observacion"`
+}
+
+func (s SesionCaja) TableName() string {
+	return "sesiones_caja"
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// SesionUsuario represents a login of a user. Its access and refresh tokens stop working once it is revoked.
+type SesionUsuario struct {
+	IdSesionUsuario  int        `json:"id_sesion_usuario" db:"pk,id_sesion_usuario"`
+	IdUsuario        int        `json:"id_usuario" db:"id_usuario"`
+	FechaCreacion    time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaRevocacion  *time.Time `json:"fecha_revocacion" db:"fecha_revocacion"`
+	MotivoRevocacion *string    `json:"motivo_revocacion" db:"motivo_revocacion"`
+}
+
+func (s SesionUsuario) TableName() string {
+	return "sesiones_usuario"
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+type TarifaIva struct {
+	IdTarifaIva int       `json:"id_tarifa_iva" db:"pk,id_tarifa_iva"`
+	Porcentaje  int       `json:"porcentaje" db:"porcentaje"`
+	FechaInicio time.Time `json:"fecha_inicio" db:"fecha_inicio"`
+}
+
+func (t TarifaIva) TableName() string {
+	return "tarifas_iva"
+}
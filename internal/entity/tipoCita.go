@@ -0,0 +1,11 @@
+package entity
+
+type TipoCita struct {
+	IdTipoCita      int    `json:"id_tipo_cita" db:"pk,id_tipo_cita"`
+	Descripcion     string `json:"descripcion" db:"descripcion"`
+	DuracionMinutos int    `json:"duracion_minutos" db:"duracion_minutos"`
+}
+
+func (t TipoCita) TableName() string {
+	return "tipos_cita"
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"database/sql"
+	"time"
 )
 
 // User represents a user.
@@ -12,6 +13,11 @@ type User struct {
 	NombreUsuario string       `json:"nombre_usuario" db:"nombre_usuario"`
 	Clave         string       `json:"clave" db:"clave"`
 	Estado        sql.NullBool `json:"estado" db:"estado"`
+	// CambiarClave forces the user to change the clave, e.g. a temporary one, before using the API.
+	CambiarClave     bool       `json:"cambiar_clave" db:"cambiar_clave"`
+	IntentosFallidos int        `json:"intentos_fallidos" db:"intentos_fallidos"`
+	BloqueadoHasta   *time.Time `json:"bloqueado_hasta" db:"bloqueado_hasta"`
+	Roles            []int      `json:"-" db:"-"`
 }
 
 // TableName represents the table name
@@ -34,6 +40,16 @@ func (u User) IsEstado() sql.NullBool {
 	return u.Estado
 }
 
+// IsCambiarClave returns whether the user must change the clave.
+func (u User) IsCambiarClave() bool {
+	return u.CambiarClave
+}
+
+// GetRoles returns the ids of the roles of the user.
+func (u User) GetRoles() []int {
+	return u.Roles
+}
+
 // GetNombres returns the Nombre.
 func (u User) GetNombres() string {
 	return (u.Apellido + " " + u.Nombre)
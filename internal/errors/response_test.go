@@ -54,6 +54,14 @@ func TestForbidden(t *testing.T) {
 	assert.NotEmpty(t, res.Error())
 }
 
+func TestConflict(t *testing.T) {
+	res := Conflict("test")
+	assert.Equal(t, http.StatusConflict, res.StatusCode())
+	assert.Equal(t, "test", res.Error())
+	res = Conflict("")
+	assert.NotEmpty(t, res.Error())
+}
+
 func TestBadRequest(t *testing.T) {
 	res := BadRequest("test")
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode())
@@ -3,6 +3,7 @@ package especies
 import (
 	"strconv"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -22,12 +23,12 @@ type resource struct {
 }
 
 func (r resource) getEspecies(c *routing.Context) error {
-	especies, err := r.service.GetEspecies(c.Request.Context())
+	pages, err := r.service.GetEspecies(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-
-	return c.Write(especies)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getEspeciePorID(c *routing.Context) error {
@@ -5,12 +5,13 @@ import (
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access especies from the data source.
 type Repository interface {
 	// Get returns the list especies.
-	GetEspecies(ctx context.Context) ([]entity.Especie, error)
+	GetEspecies(ctx context.Context, query pagination.Query) ([]entity.Especie, *pagination.Pages, error)
 	GetEspeciePorID(ctx context.Context, idEspecie int) (entity.Especie, error)
 }
 
@@ -25,19 +26,22 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposEspecies are the fields the list of especies can be filtered, searched and sorted by.
+var camposEspecies = pagination.Fields{
+	Search:      []string{"descripcion"},
+	Sort:        map[string]string{"id_especie": "id_especie", "descripcion": "descripcion"},
+	DefaultSort: []string{"descripcion asc"},
+}
+
 // Get reads the list especies from the database.
-func (r repository) GetEspecies(ctx context.Context) ([]entity.Especie, error) {
-	var especies []entity.Especie
-
-	err := r.db.With(ctx).
-		Select().
-		From("especies").
-		OrderBy("descripcion asc").
-		All(&especies)
+func (r repository) GetEspecies(ctx context.Context, query pagination.Query) ([]entity.Especie, *pagination.Pages, error) {
+	var especies []entity.Especie = []entity.Especie{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("especies"), camposEspecies, &especies)
 	if err != nil {
-		return especies, err
+		return nil, nil, err
 	}
-	return especies, err
+	return especies, pages, nil
 }
 
 func (r repository) GetEspeciePorID(ctx context.Context, idEspecie int) (entity.Especie, error) {
@@ -4,11 +4,12 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Service encapsulates usecase logic for especies.
 type Service interface {
-	GetEspecies(ctx context.Context) ([]Especies, error)
+	GetEspecies(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetEspeciePorID(ctx context.Context, idEspecie int) (Especies, error)
 }
 
@@ -28,8 +29,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list especies.
-func (s service) GetEspecies(ctx context.Context) ([]Especies, error) {
-	especies, err := s.repo.GetEspecies(ctx)
+func (s service) GetEspecies(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	especies, pages, err := s.repo.GetEspecies(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +38,8 @@ func (s service) GetEspecies(ctx context.Context) ([]Especies, error) {
 	for _, item := range especies {
 		result = append(result, Especies{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetEspeciePorID(ctx context.Context, idEspecie int) (Especies, error) {
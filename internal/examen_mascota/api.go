@@ -1,9 +1,10 @@
 package examen_mascota
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
-	"runtime"
 	"strconv"
 	"veterinaria-server/internal/consultas"
 	"veterinaria-server/internal/detalle_hospitalizacion"
@@ -11,6 +12,8 @@ import (
 	"veterinaria-server/internal/hospitalizacion"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+	"veterinaria-server/pkg/storage"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 	"github.com/nguyenthenguyen/docx"
@@ -18,8 +21,9 @@ import (
 )
 
 // RegisterHandlers sets up the routing of the HTTP handlers.
-func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, db *dbcontext.DB) {
-	res := resource{service, logger, db}
+// The result files and authorizations are filled from the templates of plantillas and saved in documentos.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, db *dbcontext.DB, plantillas storage.Storage, documentos storage.Storage) {
+	res := resource{service, logger, db, plantillas, documentos}
 	r.Use(authHandler)
 	// the following endpoints require a valid JWT
 	r.Get("/examenesMascota", res.getExamenesMascota)
@@ -34,17 +38,20 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 }
 
 type resource struct {
-	service Service
-	logger  log.Logger
-	db      *dbcontext.DB
+	service    Service
+	logger     log.Logger
+	db         *dbcontext.DB
+	plantillas storage.Storage
+	documentos storage.Storage
 }
 
 func (r resource) getExamenesMascota(c *routing.Context) error {
-	examenesMascota, err := r.service.GetExamenesMascota(c.Request.Context())
+	pages, err := r.service.GetExamenesMascota(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(examenesMascota)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getExamenesMascotaPorMascotayEstado(c *routing.Context) error {
@@ -145,7 +152,7 @@ func (r resource) actualizarExamenMascota(c *routing.Context) error {
 		_, errSdh := sdh.ActualizarDetalleHospitalizacion(c.Request.Context(), detalle_hospitalizacion.UpdateDetalleHospitalizacionRequest{
 			IdDetalleHospitalizacion: 0,
 			IdHospitalizacion:        hospitalizacionBD.IdHospitalizacion,
-			IdUsuario:                examenesMascota.IdUsuario,
+			IdUsuario:                0, //La bitacora la registra quien actualiza el examen, no quien lo solicito
 			Descripcion:              "Se solicitó el siguiente examen: " + input.DTipoExamen,
 			Fecha:                    examenesMascota.FechaSolicitud,
 		})
@@ -182,14 +189,12 @@ func (r resource) archivo(c *routing.Context) error {
 		return errors.BadRequest("")
 	}
 
-	var err error
-	var ss *excelize.File
-	if runtime.GOOS == "windows" {
-		ss, err = excelize.OpenFile("./plantillas/Resultados.xlsx")
-	} else {
-		ss, err = excelize.OpenFile("/root/go/src/github.com/JorgeTom0609/veterinaria-server/plantillas/Resultados.xlsx")
+	plantilla, err := r.plantillas.Open(c.Request.Context(), "Resultados.xlsx")
+	if err != nil {
+		return err
 	}
-
+	defer plantilla.Close()
+	ss, err := excelize.OpenReader(plantilla)
 	if err != nil {
 		return err
 	}
@@ -223,10 +228,12 @@ func (r resource) archivo(c *routing.Context) error {
 	}
 
 	fileName := fmt.Sprintf("Resultado-%s-%s.xlsx", input.Datos.Paciente, input.Datos.FechaLlenado.Format("2006-01-02"))
-	if runtime.GOOS == "windows" {
-		ss.SaveAs("./resources" + "/" + fileName)
-	} else {
-		ss.SaveAs("/root/go/src/github.com/JorgeTom0609/veterinaria-server/resources" + "/" + fileName)
+	var archivo bytes.Buffer
+	if err := ss.Write(&archivo); err != nil {
+		return err
+	}
+	if err := r.documentos.Save(c.Request.Context(), fileName, &archivo); err != nil {
+		return err
 	}
 	return c.Write(fileName)
 }
@@ -249,13 +256,16 @@ func (r resource) autorizacion(c *routing.Context) error {
 	case 4:
 		nombreDoc = "PlanSanitario"
 	}
-	var err error
-	var rd *docx.ReplaceDocx
-	if runtime.GOOS == "windows" {
-		rd, err = docx.ReadDocxFile("./plantillas/" + nombreDoc + ".docx")
-	} else {
-		rd, err = docx.ReadDocxFile("/root/go/src/github.com/JorgeTom0609/veterinaria-server/plantillas/" + nombreDoc + ".docx")
+	plantilla, err := r.plantillas.Open(c.Request.Context(), nombreDoc+".docx")
+	if err != nil {
+		return err
+	}
+	contenido, err := ioutil.ReadAll(plantilla)
+	plantilla.Close()
+	if err != nil {
+		return err
 	}
+	rd, err := docx.ReadDocxFromMemory(bytes.NewReader(contenido), int64(len(contenido)))
 	if err != nil {
 		return err
 	}
@@ -308,11 +318,14 @@ func (r resource) autorizacion(c *routing.Context) error {
 	docx1.Replace("cdías", strconv.Itoa(input.Fecha.Day()), -1)
 	docx1.Replace("cdía", strconv.Itoa(input.Fecha.Day()), -1)
 	fileName := fmt.Sprintf("%s-%s-%s.docx", nombreDoc, input.Paciente, input.Fecha.Format("2006-01-02"))
-	if runtime.GOOS == "windows" {
-		docx1.WriteToFile("./resources" + "/" + fileName)
-	} else {
-		docx1.WriteToFile("/root/go/src/github.com/JorgeTom0609/veterinaria-server/resources" + "/" + fileName)
-	}
+	var archivo bytes.Buffer
+	err = docx1.Write(&archivo)
 	rd.Close()
+	if err != nil {
+		return err
+	}
+	if err := r.documentos.Save(c.Request.Context(), fileName, &archivo); err != nil {
+		return err
+	}
 	return c.Write(fileName)
 }
@@ -0,0 +1,36 @@
+package examen_mascota
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/storage"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_examenHospitalizacion(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	archivos := storage.NewLocal("testdata")
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), logger), auth.MockAuthHandler, logger, db, archivos, archivos)
+
+	test.Fixtures(t, db, "testdata/fixtures.sql")
+	//El usuario 100 actualiza el examen que solicito la usuaria 1
+	test.Endpoint(t, router, test.APITestCase{
+		Name:         "actualizacion por otro usuario",
+		Method:       "PUT",
+		URL:          "/examenesMascota",
+		Body:         `{"id_examen_mascota":1,"id_usuario":1,"id_mascota":1,"id_tipo_examen":1,"fecha_solicitud":"2026-10-01T09:00:00Z","estado":"PENDIENTE","id_referencia":1,"tabla":"Hospitalizacion","valor":15,"d_tipo_examen":"Hemograma"}`,
+		Header:       auth.MockAuthHeader(),
+		WantStatus:   http.StatusCreated,
+		WantResponse: `*"id_usuario":1*`,
+	})
+	assert.Equal(t, 1, test.Count(t, db, "examenes_mascota", dbx.HashExp{"id_examen_mascota": 1, "id_usuario": 1, "valor": 15}))
+	assert.Equal(t, 1, test.Count(t, db, "detalles_hospitalizacion", dbx.HashExp{"id_hospitalizacion": 1, "id_usuario": 100}))
+	assert.Equal(t, 1, test.Count(t, db, "hospitalizacion", dbx.HashExp{"id_hospitalizacion": 1, "valor": 15}))
+}
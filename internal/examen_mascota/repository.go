@@ -3,9 +3,11 @@ package examen_mascota
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	// GetExamenMascotaPorId returns the examenesMascota with the specified examenesMascota ID.
 	GetExamenMascotaPorId(ctx context.Context, idExamenMascota int) (entity.ExamenMascota, error)
 	// GetExamenesMascota returns the list examenesMascota.
-	GetExamenesMascota(ctx context.Context) ([]entity.ExamenMascota, error)
+	GetExamenesMascota(ctx context.Context, query pagination.Query) ([]entity.ExamenMascota, *pagination.Pages, error)
 	GetExamenesMascotaPorMascotayEstado(ctx context.Context, idExamenMascota int, estado string) ([]ExamenMascotaAll, error)
 	GetExamenesMascotaPorEstado(ctx context.Context, estado string) ([]ExamenMascotaAll, error)
 	ObtenerResultadosPorExamen(ctx context.Context, idExamenMascota int) (Resultados, error)
@@ -34,18 +36,40 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposExamenesMascota are the fields the list of examenes mascota can be filtered, searched and sorted by.
+var camposExamenesMascota = pagination.Fields{
+	Filters: map[string]string{
+		"id_usuario":     "id_usuario",
+		"id_mascota":     "id_mascota",
+		"id_tipo_examen": "id_tipo_examen",
+		"estado":         "estado",
+		"id_referencia":  "id_referencia",
+		"tabla":          "tabla",
+	},
+	Ranges: map[string]string{"fecha_solicitud": "fecha_solicitud", "fecha_llenado": "fecha_llenado"},
+	Sort: map[string]string{
+		"id_examen_mascota": "id_examen_mascota",
+		"id_usuario":        "id_usuario",
+		"id_mascota":        "id_mascota",
+		"id_tipo_examen":    "id_tipo_examen",
+		"fecha_solicitud":   "fecha_solicitud",
+		"fecha_llenado":     "fecha_llenado",
+		"estado":            "estado",
+		"id_referencia":     "id_referencia",
+		"tabla":             "tabla",
+	},
+	DefaultSort: []string{"id_examen_mascota asc"},
+}
+
 // Get reads the list examenesMascota from the database.
-func (r repository) GetExamenesMascota(ctx context.Context) ([]entity.ExamenMascota, error) {
-	var examenesMascota []entity.ExamenMascota
+func (r repository) GetExamenesMascota(ctx context.Context, query pagination.Query) ([]entity.ExamenMascota, *pagination.Pages, error) {
+	var examenesMascota []entity.ExamenMascota = []entity.ExamenMascota{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&examenesMascota)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("examenes_mascota"), camposExamenesMascota, &examenesMascota)
 	if err != nil {
-		return examenesMascota, err
+		return nil, nil, err
 	}
-	return examenesMascota, err
+	return examenesMascota, pages, nil
 }
 
 func (r repository) GetExamenesMascotaPorMascotayEstado(ctx context.Context, idMascota int, estado string) ([]ExamenMascotaAll, error) {
@@ -168,7 +192,7 @@ func (r repository) GetExamenesMascotaPorEstado(ctx context.Context, estado stri
 // Create saves a new ExamenMascota record in the database.
 // It returns the ID of the newly inserted examenesMascota record.
 func (r repository) CrearExamenMascota(ctx context.Context, examenesMascota entity.ExamenMascota) (entity.ExamenMascota, error) {
-	err := r.db.With(ctx).Model(&examenesMascota).Insert()
+	err := auditoria.Insertar(ctx, r.db, &examenesMascota)
 	if err != nil {
 		return entity.ExamenMascota{}, err
 	}
@@ -180,9 +204,9 @@ func (r repository) CrearExamenMascota(ctx context.Context, examenesMascota enti
 func (r repository) ActualizarExamenMascota(ctx context.Context, examenesMascota entity.ExamenMascota) (entity.ExamenMascota, error) {
 	var err error
 	if examenesMascota.IdExamenMascota != 0 {
-		err = r.db.With(ctx).Model(&examenesMascota).Update()
+		err = auditoria.Actualizar(ctx, r.db, &examenesMascota)
 	} else {
-		err = r.db.With(ctx).Model(&examenesMascota).Insert()
+		err = auditoria.Insertar(ctx, r.db, &examenesMascota)
 	}
 	if err != nil {
 		return entity.ExamenMascota{}, err
@@ -203,7 +227,7 @@ func ActualizarEstadoExamenMascota(ctx context.Context, idExamenMascota int, db
 	fecha := time.Now()
 	examenMascota.FechaLlenado = &fecha
 	examenMascota.Estado = "FINALIZADO"
-	err := db.With(ctx).Model(&examenMascota).Update("FechaLlenado", "Estado")
+	err := auditoria.Actualizar(ctx, db, &examenMascota, "FechaLlenado", "Estado")
 	if err != nil {
 		return false, err
 	}
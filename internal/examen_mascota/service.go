@@ -4,15 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for examenesMascota.
 type Service interface {
-	GetExamenesMascota(ctx context.Context) ([]ExamenMascota, error)
+	GetExamenesMascota(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetExamenesMascotaPorMascotayEstado(ctx context.Context, idExamenMascota int, estado string) ([]ExamenMascotaAll, error)
 	GetExamenesMascotaPorEstado(ctx context.Context, estado string) ([]ExamenMascotaAll, error)
 	GetExamenMascotaPorId(ctx context.Context, idExamenMascota int) (ExamenMascota, error)
@@ -78,8 +80,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list examenesMascota.
-func (s service) GetExamenesMascota(ctx context.Context) ([]ExamenMascota, error) {
-	examenesMascota, err := s.repo.GetExamenesMascota(ctx)
+func (s service) GetExamenesMascota(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	examenesMascota, pages, err := s.repo.GetExamenesMascota(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +89,8 @@ func (s service) GetExamenesMascota(ctx context.Context) ([]ExamenMascota, error
 	for _, item := range examenesMascota {
 		result = append(result, ExamenMascota{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetExamenesMascotaPorMascotayEstado(ctx context.Context, idMascota int, estado string) ([]ExamenMascotaAll, error) {
@@ -192,6 +195,11 @@ func (m CreateExamenMascotaRequest) Validate() error {
 
 // CrearExamenMascota creates a new examenesMascota.
 func (s service) CrearExamenMascota(ctx context.Context, req CreateExamenMascotaRequest) (ExamenMascota, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return ExamenMascota{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return ExamenMascota{}, err
 	}
@@ -213,6 +221,21 @@ func (s service) CrearExamenMascota(ctx context.Context, req CreateExamenMascota
 
 // ActualizarExamenMascota creates a new examenesMascota.
 func (s service) ActualizarExamenMascota(ctx context.Context, req UpdateExamenMascotaRequest) (ExamenMascota, error) {
+	var idGuardado int
+	var valor float32
+	if req.IdExamenMascota != 0 {
+		examenBD, err := s.repo.GetExamenMascotaPorId(ctx, req.IdExamenMascota)
+		if err != nil {
+			return ExamenMascota{}, err
+		}
+		idGuardado = examenBD.IdUsuario
+		valor = examenBD.Valor
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return ExamenMascota{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return ExamenMascota{}, err
 	}
@@ -226,6 +249,7 @@ func (s service) ActualizarExamenMascota(ctx context.Context, req UpdateExamenMa
 		Estado:          req.Estado,
 		IdReferencia:    req.IdReferencia,
 		Tabla:           req.Tabla,
+		Valor:           valor + req.Valor, //Se acumula igual que en la consulta u hospitalizacion
 	})
 	if err != nil {
 		return ExamenMascota{}, err
@@ -0,0 +1,30 @@
+-- Un examen solicitado por la usuaria 1 para una mascota hospitalizada, que actualiza el usuario de
+-- pruebas 100.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (1, 'Ana', 'Solicitante', 'ana', '-', 1),
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO especies (id_especie, descripcion) VALUES
+    (1, 'Canino');
+
+INSERT INTO generos (id_genero, descripcion) VALUES
+    (1, 'Macho');
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001');
+
+INSERT INTO mascotas (id_mascota, id_especie, id_cliente, id_genero, nombre) VALUES
+    (1, 1, 1, 1, 'Firulais');
+
+INSERT INTO consulta (id_consulta, id_mascota, id_usuario, fecha, valor, motivo) VALUES
+    (1, 1, 1, '2026-10-01 07:00:00', 20.00, 'Vómitos persistentes');
+
+INSERT INTO hospitalizacion (id_hospitalizacion, id_consulta, motivo, fecha_ingreso, fecha_salida, valor, abono, autoriza_examenes, estado_hospitalizacion) VALUES
+    (1, 1, 'Gastroenteritis', '2026-10-01 08:00:00', NULL, 0, 0, 1, 'ACTIVA');
+
+INSERT INTO tipos_examenes (id_tipo_examen, id_especie, titulo, descripcion, muestra, valor) VALUES
+    (1, 1, 'Hemograma', 'Conteo sanguíneo completo', 'Sangre', 15.00);
+
+INSERT INTO examenes_mascota (id_examen_mascota, id_usuario, id_mascota, id_tipo_examen, fecha_solicitud, estado, id_referencia, tabla) VALUES
+    (1, 1, 1, 1, '2026-10-01 09:00:00', 'PENDIENTE', 1, 'Hospitalizacion');
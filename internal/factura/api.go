@@ -3,14 +3,20 @@ package factura
 import (
 	"net/http"
 	"strconv"
+	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/clientes"
 	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/internal/errors"
-	"veterinaria-server/internal/lote"
-	"veterinaria-server/internal/stock_individual"
+	"veterinaria-server/internal/movimiento_inventario"
+	"veterinaria-server/internal/nota_credito"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/internal/tarifa_iva"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -22,9 +28,11 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 	// the following endpoints require a valid JWT
 	r.Get("/facturas", res.getFacturas)
 	r.Get("/facturas/conDatos", res.getFacturasConDatos)
+	r.Get("/facturas/totalVentas/<desde>/<hasta>", res.getTotalVentas)
 	r.Get("/facturas/<idFactura>", res.getFacturaPorId)
 	r.Post("/facturas", res.crearFactura)
 	r.Post("/facturas/conDetalle", res.crearFacturaConDetalles)
+	r.Post("/facturas/<idFactura>/anular", res.anularFactura)
 	r.Put("/facturas", res.actualizarFactura)
 }
 
@@ -35,19 +43,21 @@ type resource struct {
 }
 
 func (r resource) getFacturas(c *routing.Context) error {
-	facturas, err := r.service.GetFacturas(c.Request.Context())
+	pages, err := r.service.GetFacturas(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(facturas)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getFacturasConDatos(c *routing.Context) error {
-	facturas, err := r.service.GetFacturasConDatos(c.Request.Context())
+	pages, err := r.service.GetFacturasConDatos(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(facturas)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearFactura(c *routing.Context) error {
@@ -85,12 +95,157 @@ func (r resource) getFacturaPorId(c *routing.Context) error {
 	return c.Write(factura)
 }
 
+func (r resource) getTotalVentas(c *routing.Context) error {
+	desde, err := time.Parse("2006-01-02", c.Param("desde"))
+	if err != nil {
+		return errors.BadRequest("La fecha desde debe tener el formato AAAA-MM-DD.")
+	}
+	hasta, err := time.Parse("2006-01-02", c.Param("hasta"))
+	if err != nil {
+		return errors.BadRequest("La fecha hasta debe tener el formato AAAA-MM-DD.")
+	}
+	//Incluye todo el día final
+	totalVentas, err := r.service.GetTotalVentas(c.Request.Context(), desde, hasta.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	return c.Write(totalVentas)
+}
+
+func (r resource) anularFactura(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	var input AnularFacturaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	identity := auth.CurrentUser(c.Request.Context())
+	if identity == nil {
+		return errors.Unauthorized("")
+	}
+
+	facturaG, err := r.service.AnularFactura(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+
+	sn := nota_credito.NewService(nota_credito.NewRepository(r.db, r.logger), r.logger)
+	notaCreditoG, err := sn.CrearNotaCredito(c.Request.Context(), nota_credito.CreateNotaCreditoRequest{
+		IdFactura: facturaG.IdFactura,
+		IdUsuario: identity.GetIdUsuario(),
+		Motivo:    input.Motivo,
+		Valor:     facturaG.Valor,
+	})
+	if err != nil {
+		return err
+	}
+
+	//Devuelve las cantidades vendidas a los lotes y stocks individuales originales
+	sd := detalle_factura.NewService(detalle_factura.NewRepository(r.db, r.logger), r.logger)
+	detallesFactura, err := sd.GetDetalleFacturaPorIdFactura(c.Request.Context(), facturaG.IdFactura)
+	if err != nil {
+		return err
+	}
+	sm := movimiento_inventario.NewService(movimiento_inventario.NewRepository(r.db, r.logger), r.logger)
+	movimientosG := []movimiento_inventario.MovimientoInventario{}
+	origen := "notas_credito"
+	for _, detalle := range detallesFactura {
+		if !detalle_factura.EsInventario(detalle.Tabla) {
+			continue
+		}
+		movimientos, err := sm.RegistrarMovimiento(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+			Tabla:        movimiento_inventario.TablaDetalle(detalle.Tabla),
+			IdReferencia: detalle.IdReferencia,
+			Tipo:         movimiento_inventario.TipoDevolucion,
+			Cantidad:     detalle.Cantidad,
+			Origen:       &origen,
+			IdOrigen:     &notaCreditoG.IdNotaCredito,
+		})
+		if err != nil {
+			return err
+		}
+		movimientosG = append(movimientosG, movimientos...)
+	}
+
+	var result = struct {
+		Factura     Factura
+		NotaCredito nota_credito.NotaCredito
+		Movimientos []movimiento_inventario.MovimientoInventario
+	}{facturaG, notaCreditoG, movimientosG}
+
+	return c.WriteWithStatus(result, http.StatusCreated)
+}
+
 func (r resource) crearFacturaConDetalles(c *routing.Context) error {
 	var input CreateFacturaConDetallesRequest
 	if err := c.Read(&input); err != nil {
 		r.logger.With(c.Request.Context()).Info(err)
 		return errors.BadRequest("")
 	}
+	sm := movimiento_inventario.NewService(movimiento_inventario.NewRepository(r.db, r.logger), r.logger)
+
+	//Asigna los lotes por FEFO cuando solo se envía el producto
+	detalles := []detalle_factura.CreateDetalleFacturaRequest{}
+	asignado := []movimiento_inventario.ItemStock{}
+	for _, detalle := range input.DetallesFactura {
+		if detalle.IdProducto == 0 {
+			if !detalle_factura.TablaValida(detalle.Tabla) {
+				return errors.BadRequest("El detalle se refiere a una tabla desconocida.")
+			}
+			detalles = append(detalles, detalle)
+			continue
+		}
+		asignaciones, err := sm.AsignarFEFO(c.Request.Context(), detalle.IdProducto, detalle.Cantidad, asignado)
+		if err != nil {
+			return err
+		}
+		asignado = append(asignado, asignaciones...)
+		//El descuento se reparte entre los lotes asignados, el último recibe el residuo
+		descuentoAsignado := money.Zero
+		for i, asignacion := range asignaciones {
+			d := detalle
+			d.Tabla = asignacion.Tabla
+			d.IdReferencia = asignacion.IdReferencia
+			d.Cantidad = asignacion.Cantidad
+			d.Descuento = detalle.Descuento.Mul(float64(asignacion.Cantidad / detalle.Cantidad))
+			if i == len(asignaciones)-1 {
+				d.Descuento = detalle.Descuento - descuentoAsignado
+			}
+			descuentoAsignado += d.Descuento
+			detalles = append(detalles, d)
+		}
+	}
+	input.DetallesFactura = detalles
+
+	//Verifica que exista stock suficiente antes de registrar
+	items := []movimiento_inventario.ItemStock{}
+	for _, detalle := range input.DetallesFactura {
+		if !detalle_factura.EsInventario(detalle.Tabla) {
+			continue
+		}
+		items = append(items, movimiento_inventario.ItemStock{Tabla: detalle.Tabla, IdReferencia: detalle.IdReferencia, Cantidad: detalle.Cantidad})
+	}
+	if err := sm.VerificarDisponibilidad(c.Request.Context(), items); err != nil {
+		return err
+	}
+
+	//Calcula los valores con la tarifa de IVA vigente a la fecha de la factura
+	if input.Factura.Fecha.IsZero() {
+		input.Factura.Fecha = time.Now()
+	}
+	st := tarifa_iva.NewService(tarifa_iva.NewRepository(r.db, r.logger), r.logger)
+	tarifaIva, err := st.GetTarifaIvaVigente(c.Request.Context(), input.Factura.Fecha)
+	if err != nil {
+		return err
+	}
+	input.DetallesFactura, input.Factura.Totales, err = r.service.CalcularTotales(c.Request.Context(), input.DetallesFactura, tarifaIva.Porcentaje)
+	if err != nil {
+		return err
+	}
+
 	var clienteG clientes.Cliente
 
 	if input.Factura.IdCliente == 0 {
@@ -129,68 +284,44 @@ func (r resource) crearFacturaConDetalles(c *routing.Context) error {
 		if err != nil {
 			return err
 		}
-		if detalleFacturaG.Tabla == "lote" {
-			s2 := lote.NewService(lote.NewRepository(r.db, r.logger), r.logger)
-			productoVenderBD, err2 := s2.GetLotePorId(c.Request.Context(), detalleFacturaG.IdReferencia)
-			if err2 != nil {
-				return err2
-			}
-			_, err3 := s2.ActualizarLote(c.Request.Context(),
-				lote.UpdateLoteRequest{
-					IdLote:              productoVenderBD.IdLote,
-					Descripcion:         productoVenderBD.Descripcion,
-					IdProveedorProducto: productoVenderBD.IdProveedorProducto,
-					FechaCaducidad:      productoVenderBD.FechaCaducidad,
-					Stock:               productoVenderBD.Stock - int(detalleFacturaG.Cantidad),
-				})
-			if err3 != nil {
-				return err3
-			}
-		} else {
-			s2 := stock_individual.NewService(stock_individual.NewRepository(r.db, r.logger), r.logger)
-			productoVenderBD, err2 := s2.GetStockIndividualPorId(c.Request.Context(), detalleFacturaG.IdReferencia)
-			if err2 != nil {
-				return err2
-			}
-			_, err3 := s2.ActualizarStockIndividual(c.Request.Context(),
-				stock_individual.UpdateStockIndividualRequest{
-					IdLote:            productoVenderBD.IdLote,
-					Descripcion:       productoVenderBD.Descripcion,
-					IdStockIndividual: productoVenderBD.IdStockIndividual,
-					CantidadInicial:   productoVenderBD.CantidadInicial,
-					Cantidad:          productoVenderBD.Cantidad - detalleFacturaG.Cantidad,
-				})
-			if err3 != nil {
-				return err3
-			}
-			if (productoVenderBD.Cantidad - detalleFacturaG.Cantidad) == float32(0) {
-				s2 := lote.NewService(lote.NewRepository(r.db, r.logger), r.logger)
-				productoVenderBD, err2 := s2.GetLotePorId(c.Request.Context(), productoVenderBD.IdLote)
-				if err2 != nil {
-					return err2
-				}
-				_, err3 := s2.ActualizarLote(c.Request.Context(),
-					lote.UpdateLoteRequest{
-						IdLote:              productoVenderBD.IdLote,
-						Descripcion:         productoVenderBD.Descripcion,
-						IdProveedorProducto: productoVenderBD.IdProveedorProducto,
-						FechaCaducidad:      productoVenderBD.FechaCaducidad,
-						Stock:               productoVenderBD.Stock - 1,
-					})
-				if err3 != nil {
-					return err3
-				}
-			}
+		detallesFacturaG = append(detallesFacturaG, detalleFacturaG)
+		//Los cargos de consultas, examenes y hospitalizaciones no mueven stock
+		if !detalle_factura.EsInventario(detalleFacturaG.Tabla) {
+			continue
 		}
+		origen := "detalles_factura"
+		_, err = sm.RegistrarMovimiento(c.Request.Context(), movimiento_inventario.CreateMovimientoInventarioRequest{
+			Tabla:        movimiento_inventario.TablaDetalle(detalleFacturaG.Tabla),
+			IdReferencia: detalleFacturaG.IdReferencia,
+			Tipo:         movimiento_inventario.TipoVenta,
+			Cantidad:     -detalleFacturaG.Cantidad,
+			Origen:       &origen,
+			IdOrigen:     &detalleFacturaG.IdDetalleFactura,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-		detallesFacturaG = append(detallesFacturaG, detalleFacturaG)
+	//Registra los pagos recibidos al emitir la factura, el resto queda como saldo pendiente
+	pagosG := []pago.Pago{}
+	if len(input.Pagos) > 0 {
+		sp := pago.NewService(pago.NewRepository(r.db, r.logger), r.logger)
+		pagosG, err = sp.RegistrarPagos(c.Request.Context(), pago.RegistrarPagosRequest{
+			IdFactura: &facturaG.IdFactura,
+			Pagos:     input.Pagos,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	var result = struct {
 		Cliente         clientes.Cliente
 		Factura         Factura
 		DetallesFactura []detalle_factura.DetalleFactura
-	}{clienteG, facturaG, detallesFacturaG}
+		Pagos           []pago.Pago
+	}{clienteG, facturaG, detallesFacturaG, pagosG}
 
 	return c.WriteWithStatus(result, http.StatusCreated)
 }
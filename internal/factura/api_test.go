@@ -0,0 +1,184 @@
+package factura
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_usuarioActuante(t *testing.T) {
+	logger, _ := log.NewForTest()
+	router := test.MockRouter(logger)
+	RegisterHandlers(router.Group(""), NewService(mockRepository{}, logger), auth.MockAuthHandler, logger, nil)
+
+	tests := []test.APITestCase{
+		{
+			Name:         "create unauthorized",
+			Method:       "POST",
+			URL:          "/facturas",
+			Body:         `{"id_cliente":3}`,
+			Header:       nil,
+			WantStatus:   http.StatusUnauthorized,
+			WantResponse: "",
+		},
+		{
+			Name:         "create as current user",
+			Method:       "POST",
+			URL:          "/facturas",
+			Body:         `{"id_cliente":3}`,
+			Header:       auth.MockAuthHeader(),
+			WantStatus:   http.StatusCreated,
+			WantResponse: `*"id_usuario":100*`,
+		},
+		{
+			Name:         "create as same user",
+			Method:       "POST",
+			URL:          "/facturas",
+			Body:         `{"id_cliente":3,"id_usuario":100}`,
+			Header:       auth.MockAuthHeader(),
+			WantStatus:   http.StatusCreated,
+			WantResponse: `*"id_usuario":100*`,
+		},
+		{
+			Name:         "create as other user",
+			Method:       "POST",
+			URL:          "/facturas",
+			Body:         `{"id_cliente":3,"id_usuario":7}`,
+			Header:       auth.MockAuthHeader(),
+			WantStatus:   http.StatusForbidden,
+			WantResponse: "",
+		},
+		{
+			Name:         "update as other user",
+			Method:       "PUT",
+			URL:          "/facturas",
+			Body:         `{"id_cliente":3,"id_usuario":7}`,
+			Header:       auth.MockAuthHeader(),
+			WantStatus:   http.StatusForbidden,
+			WantResponse: "",
+		},
+	}
+	for _, tc := range tests {
+		test.Endpoint(t, router, tc)
+	}
+}
+
+func TestAPI_crearFacturaConDetalles(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), logger), auth.MockAuthHandler, logger, db)
+
+	tests := []struct {
+		caso        test.APITestCase
+		stock       map[int]int //Stock de cada lote luego de la factura
+		movimientos int
+	}{
+		{
+			caso: test.APITestCase{
+				Name:         "producto por FEFO",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"id_producto":1,"cantidad":7}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"id_cliente":1*`,
+			},
+			stock:       map[int]int{1: 8, 2: 0},
+			movimientos: 2,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "producto repetido por FEFO",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"id_producto":1,"cantidad":4},{"id_producto":1,"cantidad":4}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"id_cliente":1*`,
+			},
+			stock:       map[int]int{1: 7, 2: 0},
+			movimientos: 3,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "lote elegido",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"tabla":"lote","id_referencia":1,"cantidad":3}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"id_cliente":1*`,
+			},
+			stock:       map[int]int{1: 7, 2: 5},
+			movimientos: 1,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "stock insuficiente",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"tabla":"lote","id_referencia":2,"cantidad":6}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusConflict,
+				WantResponse: "",
+			},
+			stock:       map[int]int{1: 10, 2: 5},
+			movimientos: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "producto sin stock suficiente",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"id_producto":1,"cantidad":16}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusConflict,
+				WantResponse: "",
+			},
+			stock:       map[int]int{1: 10, 2: 5},
+			movimientos: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "cargo sin inventario",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"tabla":"consulta","id_referencia":1,"descripcion":"Consulta veterinaria","cantidad":1,"precio_unitario":20},{"tabla":"lote","id_referencia":1,"cantidad":1}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"id_cliente":1*`,
+			},
+			stock:       map[int]int{1: 9, 2: 5},
+			movimientos: 1,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "tabla desconocida",
+				Method:       "POST",
+				URL:          "/facturas/conDetalle",
+				Body:         `{"factura":{"id_cliente":1,"fecha":"2026-10-01T10:00:00Z"},"detalles_factura":[{"tabla":"producto","id_referencia":1,"cantidad":1}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusBadRequest,
+				WantResponse: "",
+			},
+			stock:       map[int]int{1: 10, 2: 5},
+			movimientos: 0,
+		},
+	}
+	for _, tc := range tests {
+		test.Fixtures(t, db, "testdata/fixtures.sql")
+		test.Endpoint(t, router, tc.caso)
+		for idLote, stock := range tc.stock {
+			var actual int
+			assert.Nil(t, db.DB().Select("stock").From("lote").Where(dbx.HashExp{"id_lote": idLote}).Row(&actual))
+			assert.Equal(t, stock, actual, "%s: stock del lote %d", tc.caso.Name, idLote)
+		}
+		assert.Equal(t, tc.movimientos, test.Count(t, db, "movimientos_inventario", dbx.HashExp{"tipo": "venta"}), tc.caso.Name)
+	}
+}
@@ -2,9 +2,13 @@ package factura
 
 import (
 	"context"
+	"database/sql"
+	"time"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -13,11 +17,21 @@ import (
 type Repository interface {
 	// GetFacturaPorId returns the factura with the specified factura ID.
 	GetFacturaPorId(ctx context.Context, idFactura int) (entity.Factura, error)
+	// BloquearFactura reads the factura and locks its row until the current transaction ends.
+	BloquearFactura(ctx context.Context, idFactura int) (entity.Factura, error)
 	// GetFacturas returns the list facturas.
-	GetFacturas(ctx context.Context) ([]entity.Factura, error)
-	GetFacturasConDatos(ctx context.Context) ([]FacturaConDatos, error)
+	GetFacturas(ctx context.Context, query pagination.Query) ([]entity.Factura, *pagination.Pages, error)
+	GetFacturasConDatos(ctx context.Context, query pagination.Query) ([]FacturaConDatos, *pagination.Pages, error)
 	CrearFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error)
 	ActualizarFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error)
+	// AnularFactura marks the factura as voided.
+	AnularFactura(ctx context.Context, idFactura int) error
+	// GetTotalVentas sums the facturas between the given dates, excluding voided facturas.
+	GetTotalVentas(ctx context.Context, desde time.Time, hasta time.Time) (TotalVentas, error)
+	// GetProductoPorReferencia returns the producto of the lote or stock individual a detalle refers to.
+	GetProductoPorReferencia(ctx context.Context, tabla string, idReferencia int) (entity.Producto, error)
+	// GetIdSesionCajaAbierta returns the open caja session of the usuario, or nil when there is none.
+	GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error)
 }
 
 // repository persists facturas in database
@@ -31,67 +45,90 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposFacturas are the fields the list of facturas can be filtered, searched and sorted by.
+var camposFacturas = pagination.Fields{
+	Filters: map[string]string{
+		"id_cliente":     "id_cliente",
+		"id_usuario":     "id_usuario",
+		"anulada":        "anulada",
+		"id_sesion_caja": "id_sesion_caja",
+		"origen":         "origen",
+		"id_origen":      "id_origen",
+	},
+	Ranges: map[string]string{"fecha": "fecha"},
+	Sort: map[string]string{
+		"id_factura":     "id_factura",
+		"id_cliente":     "id_cliente",
+		"id_usuario":     "id_usuario",
+		"fecha":          "fecha",
+		"subtotal_0":     "subtotal_0",
+		"subtotal_iva":   "subtotal_iva",
+		"descuento":      "descuento",
+		"porcentaje_iva": "porcentaje_iva",
+		"iva":            "iva",
+		"valor":          "valor",
+		"anulada":        "anulada",
+		"id_sesion_caja": "id_sesion_caja",
+		"origen":         "origen",
+		"id_origen":      "id_origen",
+	},
+	DefaultSort: []string{"id_factura asc"},
+}
+
 // Get reads the list facturas from the database.
-func (r repository) GetFacturas(ctx context.Context) ([]entity.Factura, error) {
-	var facturas []entity.Factura
+func (r repository) GetFacturas(ctx context.Context, query pagination.Query) ([]entity.Factura, *pagination.Pages, error) {
+	var facturas []entity.Factura = []entity.Factura{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&facturas)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("facturas"), camposFacturas, &facturas)
 	if err != nil {
-		return facturas, err
+		return nil, nil, err
 	}
-	return facturas, err
+	return facturas, pages, nil
 }
 
-func (r repository) GetFacturasConDatos(ctx context.Context) ([]FacturaConDatos, error) {
-	var facturas []entity.Factura
-	var facturasConDatos []FacturaConDatos = []FacturaConDatos{}
-	var clienteNombre, clienteApellido, vendedorNombre, vendedorApellido string
+// camposFacturasConDatos are the fields of the facturas joined with their cliente and vendedor.
+var camposFacturasConDatos = pagination.Fields{
+	Filters: map[string]string{
+		"id_cliente":     "f.id_cliente",
+		"id_usuario":     "f.id_usuario",
+		"anulada":        "f.anulada",
+		"id_sesion_caja": "f.id_sesion_caja",
+		"origen":         "f.origen",
+		"id_origen":      "f.id_origen",
+	},
+	Ranges: map[string]string{"fecha": "f.fecha"},
+	Search: []string{"c.nombres", "c.apellidos", "c.cedula", "u.nombre", "u.apellido"},
+	Sort: map[string]string{
+		"id_factura": "f.id_factura",
+		"fecha":      "f.fecha",
+		"valor":      "f.valor",
+		"anulada":    "f.anulada",
+		"cliente":    "cliente",
+		"vendedor":   "vendedor",
+	},
+	DefaultSort: []string{"f.id_factura asc"},
+}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&facturas)
-
-	for i := 0; i < len(facturas); i++ {
-		idCliente := facturas[i].IdCliente
-		err := r.db.With(ctx).
-			Select("nombres", "apellidos").
-			From("clientes").
-			Where(dbx.HashExp{"id_cliente": idCliente}).
-			Row(&clienteNombre, &clienteApellido)
-		if err != nil {
-			return []FacturaConDatos{}, err
-		}
-
-		idUsuario := facturas[i].IdUsuario
-		err = r.db.With(ctx).
-			Select("nombre", "apellido").
-			From("usuarios").
-			Where(dbx.HashExp{"id_usuario": idUsuario}).
-			Row(&vendedorNombre, &vendedorApellido)
-		if err != nil {
-			return []FacturaConDatos{}, err
-		}
-
-		facturasConDatos = append(facturasConDatos, FacturaConDatos{
-			facturas[i],
-			clienteApellido + " " + clienteNombre,
-			vendedorApellido + " " + vendedorNombre,
-		})
-	}
+// GetFacturasConDatos reads the facturas with the names of their cliente and vendedor in a single query.
+func (r repository) GetFacturasConDatos(ctx context.Context, query pagination.Query) ([]FacturaConDatos, *pagination.Pages, error) {
+	var facturasConDatos []FacturaConDatos = []FacturaConDatos{}
 
+	q := r.db.With(ctx).
+		Select("f.*", "CONCAT(c.apellidos, ' ', c.nombres) AS cliente", "CONCAT(u.apellido, ' ', u.nombre) AS vendedor").
+		From("facturas f").
+		InnerJoin("clientes c", dbx.NewExp("c.id_cliente = f.id_cliente")).
+		InnerJoin("usuarios u", dbx.NewExp("u.id_usuario = f.id_usuario"))
+	pages, err := query.List(ctx, r.db.With(ctx), q, camposFacturasConDatos, &facturasConDatos)
 	if err != nil {
-		return []FacturaConDatos{}, err
+		return nil, nil, err
 	}
-	return facturasConDatos, err
+	return facturasConDatos, pages, nil
 }
 
 // Create saves a new Factura record in the database.
 // It returns the ID of the newly inserted factura record.
 func (r repository) CrearFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error) {
-	err := r.db.With(ctx).Model(&factura).Insert()
+	err := auditoria.Insertar(ctx, r.db, &factura)
 	if err != nil {
 		return entity.Factura{}, err
 	}
@@ -103,9 +140,9 @@ func (r repository) CrearFactura(ctx context.Context, factura entity.Factura) (e
 func (r repository) ActualizarFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error) {
 	var err error
 	if factura.IdFactura != 0 {
-		err = r.db.With(ctx).Model(&factura).Update()
+		err = auditoria.Actualizar(ctx, r.db, &factura)
 	} else {
-		err = r.db.With(ctx).Model(&factura).Insert()
+		err = auditoria.Insertar(ctx, r.db, &factura)
 	}
 	if err != nil {
 		return entity.Factura{}, err
@@ -119,3 +156,61 @@ func (r repository) GetFacturaPorId(ctx context.Context, idFactura int) (entity.
 	err := r.db.With(ctx).Select().Model(idFactura, &factura)
 	return factura, err
 }
+
+func (r repository) BloquearFactura(ctx context.Context, idFactura int) (entity.Factura, error) {
+	var factura entity.Factura
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM facturas WHERE id_factura = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idFactura}).
+		One(&factura)
+	return factura, err
+}
+
+func (r repository) AnularFactura(ctx context.Context, idFactura int) error {
+	factura := entity.Factura{IdFactura: idFactura, Anulada: true}
+	return auditoria.Actualizar(ctx, r.db, &factura, "Anulada")
+}
+
+func (r repository) GetTotalVentas(ctx context.Context, desde time.Time, hasta time.Time) (TotalVentas, error) {
+	var totalVentas TotalVentas
+	err := r.db.With(ctx).
+		Select("count(*) as numero_facturas", "coalesce(sum(valor), 0) as total").
+		From("facturas").
+		Where(dbx.Between("fecha", desde, hasta)).
+		AndWhere(dbx.HashExp{"anulada": false}).
+		One(&totalVentas)
+	return totalVentas, err
+}
+
+func (r repository) GetProductoPorReferencia(ctx context.Context, tabla string, idReferencia int) (entity.Producto, error) {
+	var producto entity.Producto
+	q := r.db.With(ctx).
+		Select("p.*").
+		From("producto p").
+		InnerJoin("proveedor_producto pp", dbx.NewExp("pp.id_producto = p.id_producto"))
+	if tabla == "lote" {
+		q = q.InnerJoin("lote l", dbx.NewExp("l.id_proveedor_producto = pp.id_proveedor_producto")).
+			Where(dbx.HashExp{"l.id_lote": idReferencia})
+	} else {
+		q = q.InnerJoin("lote l", dbx.NewExp("l.id_proveedor_producto = pp.id_proveedor_producto")).
+			InnerJoin("stock_individual si", dbx.NewExp("si.id_lote = l.id_lote")).
+			Where(dbx.HashExp{"si.id_stock_individual": idReferencia})
+	}
+	err := q.One(&producto)
+	return producto, err
+}
+
+func (r repository) GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error) {
+	var sesion entity.SesionCaja
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_usuario": idUsuario, "estado": "ABIERTA"}).
+		One(&sesion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sesion.IdSesionCaja, nil
+}
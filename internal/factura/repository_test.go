@@ -0,0 +1,50 @@
+package factura
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// BenchmarkRepository_GetFacturasConDatos checks that the facturas with their cliente and vendedor
+// are read with the same number of queries whatever the number of facturas.
+func BenchmarkRepository_GetFacturasConDatos(b *testing.B) {
+	logger, _ := log.NewForTest()
+	db := test.DB(b)
+	repo := NewRepository(db, logger)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("facturas=%d", n), func(b *testing.B) {
+			test.Rollback(b, db, func(ctx context.Context) {
+				cliente := entity.Cliente{Nombres: "Ana", Apellidos: "Pérez", Cedula: "0900000001"}
+				usuario := entity.User{Nombre: "Luis", Apellido: "Mora", NombreUsuario: "benchmark"}
+				test.Insert(b, ctx, db, &cliente, &usuario)
+				for i := 0; i < n; i++ {
+					test.Insert(b, ctx, db, &entity.Factura{IdCliente: cliente.IdCliente, IdUsuario: usuario.IdUsuario, Fecha: time.Now()})
+				}
+
+				query := pagination.Query{Page: 1, PerPage: n}
+				consultas := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					consultas = test.CountQueries(db, func() {
+						facturas, _, err := repo.GetFacturasConDatos(ctx, query)
+						if err != nil || len(facturas) != n {
+							b.Fatalf("GetFacturasConDatos: %d facturas, %v", len(facturas), err)
+						}
+					})
+				}
+				//El conteo y la pagina
+				if consultas != 2 {
+					b.Fatalf("GetFacturasConDatos ran %d queries", consultas)
+				}
+				b.ReportMetric(float64(consultas), "queries/op")
+			})
+		})
+	}
+}
@@ -3,21 +3,33 @@ package factura
 import (
 	"context"
 	"time"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/clientes"
 	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/pago"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for facturas.
 type Service interface {
-	GetFacturas(ctx context.Context) ([]Factura, error)
-	GetFacturasConDatos(ctx context.Context) ([]FacturaConDatos, error)
+	GetFacturas(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	GetFacturasConDatos(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetFacturaPorId(ctx context.Context, idFactura int) (Factura, error)
 	CrearFactura(ctx context.Context, input CreateFacturaRequest) (Factura, error)
 	ActualizarFactura(ctx context.Context, input UpdateFacturaRequest) (Factura, error)
+	// AnularFactura marks the factura as voided. Voided facturas can not be updated nor voided again.
+	AnularFactura(ctx context.Context, idFactura int) (Factura, error)
+	GetTotalVentas(ctx context.Context, desde time.Time, hasta time.Time) (TotalVentas, error)
+	// CalcularTotales computes every detalle with the given IVA rate, applied only to the productos
+	// that carry IVA, and returns the detalles with their amounts and the totals of the factura.
+	// Detalles from inventory always take the precio de venta and the IVA of their producto.
+	CalcularTotales(ctx context.Context, detalles []detalle_factura.CreateDetalleFacturaRequest, porcentajeIva int) ([]detalle_factura.CreateDetalleFacturaRequest, Totales, error)
 }
 
 // Facturas represents the data about an facturas.
@@ -31,6 +43,24 @@ type FacturaConDatos struct {
 	Vendedor string `json:"vendedor"`
 }
 
+// TotalVentas represents the sales of a period, excluding voided facturas.
+type TotalVentas struct {
+	Desde          time.Time   `json:"desde"`
+	Hasta          time.Time   `json:"hasta"`
+	NumeroFacturas int         `json:"numero_facturas"`
+	Total          money.Money `json:"total"`
+}
+
+// Totales are the amounts of a factura computed from its detalles.
+type Totales struct {
+	Subtotal0     money.Money
+	SubtotalIva   money.Money
+	Descuento     money.Money
+	PorcentajeIva int
+	Iva           money.Money
+	Total         money.Money
+}
+
 type service struct {
 	repo   Repository
 	logger log.Logger
@@ -42,8 +72,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list facturas.
-func (s service) GetFacturas(ctx context.Context) ([]Factura, error) {
-	facturas, err := s.repo.GetFacturas(ctx)
+func (s service) GetFacturas(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	facturas, pages, err := s.repo.GetFacturas(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -51,37 +81,56 @@ func (s service) GetFacturas(ctx context.Context) ([]Factura, error) {
 	for _, item := range facturas {
 		result = append(result, Factura{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
-func (s service) GetFacturasConDatos(ctx context.Context) ([]FacturaConDatos, error) {
-	facturas, err := s.repo.GetFacturasConDatos(ctx)
+func (s service) GetFacturasConDatos(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	facturas, pages, err := s.repo.GetFacturasConDatos(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	return facturas, nil
+	pages.Items = facturas
+	return pages, nil
 }
 
 // CreateFacturaRequest represents an factura creation request.
+// The totals are not read from the client, they are computed from the detalles.
 type CreateFacturaRequest struct {
 	IdCliente int       `json:"id_cliente"`
 	IdUsuario int       `json:"id_usuario"`
 	Fecha     time.Time `json:"fecha"`
-	Valor     float32   `json:"valor"`
+	Totales   Totales   `json:"-"`
+	// Origen and IdOrigen identify the record billed by the factura, e.g. a hospitalizacion.
+	Origen   *string `json:"-"`
+	IdOrigen *int    `json:"-"`
 }
 
+// UpdateFacturaRequest represents an factura update request. The totals of the factura are kept.
 type UpdateFacturaRequest struct {
 	IdFactura int       `json:"id_factura"`
 	IdCliente int       `json:"id_cliente"`
 	IdUsuario int       `json:"id_usuario"`
 	Fecha     time.Time `json:"fecha"`
-	Valor     float32   `json:"valor"`
+}
+
+// AnularFacturaRequest represents a factura voiding request.
+type AnularFacturaRequest struct {
+	Motivo string `json:"motivo"`
+}
+
+// Validate validates the AnularFacturaRequest fields.
+func (m AnularFacturaRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Motivo, validation.Required, validation.Length(0, 1000)),
+	)
 }
 
 type CreateFacturaConDetallesRequest struct {
 	Cliente         clientes.CreateClienteRequest                 `json:"cliente"`
 	Factura         CreateFacturaRequest                          `json:"factura"`
 	DetallesFactura []detalle_factura.CreateDetalleFacturaRequest `json:"detalles_factura"`
+	Pagos           []pago.DetallePagoRequest                     `json:"pagos"`
 }
 
 // Validate validates the UpdateFacturaRequest fields.
@@ -102,14 +151,32 @@ func (m CreateFacturaRequest) Validate() error {
 
 // CrearFactura creates a new factura.
 func (s service) CrearFactura(ctx context.Context, req CreateFacturaRequest) (Factura, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return Factura{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return Factura{}, err
 	}
+	//La factura queda en la caja abierta del usuario que la emite
+	idSesionCaja, err := s.repo.GetIdSesionCajaAbierta(ctx, req.IdUsuario)
+	if err != nil {
+		return Factura{}, err
+	}
 	facturaG, err := s.repo.CrearFactura(ctx, entity.Factura{
-		IdCliente: req.IdCliente,
-		IdUsuario: req.IdUsuario,
-		Fecha:     req.Fecha,
-		Valor:     req.Valor,
+		IdCliente:     req.IdCliente,
+		IdUsuario:     req.IdUsuario,
+		Fecha:         req.Fecha,
+		Subtotal0:     req.Totales.Subtotal0,
+		SubtotalIva:   req.Totales.SubtotalIva,
+		Descuento:     req.Totales.Descuento,
+		PorcentajeIva: req.Totales.PorcentajeIva,
+		Iva:           req.Totales.Iva,
+		Valor:         req.Totales.Total,
+		IdSesionCaja:  idSesionCaja,
+		Origen:        req.Origen,
+		IdOrigen:      req.IdOrigen,
 	})
 	if err != nil {
 		return Factura{}, err
@@ -119,16 +186,30 @@ func (s service) CrearFactura(ctx context.Context, req CreateFacturaRequest) (Fa
 
 // ActualizarFactura creates a new factura.
 func (s service) ActualizarFactura(ctx context.Context, req UpdateFacturaRequest) (Factura, error) {
+	var factura entity.Factura
+	if req.IdFactura != 0 {
+		facturaBD, err := s.repo.GetFacturaPorId(ctx, req.IdFactura)
+		if err != nil {
+			return Factura{}, err
+		}
+		if facturaBD.Anulada {
+			return Factura{}, errors.Conflict("La factura está anulada y no puede modificarse.")
+		}
+		factura = facturaBD
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, factura.IdUsuario)
+	if err != nil {
+		return Factura{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return Factura{}, err
 	}
-	facturaG, err := s.repo.ActualizarFactura(ctx, entity.Factura{
-		IdFactura: req.IdFactura,
-		IdCliente: req.IdCliente,
-		IdUsuario: req.IdUsuario,
-		Fecha:     req.Fecha,
-		Valor:     req.Valor,
-	})
+	factura.IdFactura = req.IdFactura
+	factura.IdCliente = req.IdCliente
+	factura.IdUsuario = req.IdUsuario
+	factura.Fecha = req.Fecha
+	facturaG, err := s.repo.ActualizarFactura(ctx, factura)
 	if err != nil {
 		return Factura{}, err
 	}
@@ -143,3 +224,66 @@ func (s service) GetFacturaPorId(ctx context.Context, idFactura int) (Factura, e
 	}
 	return Factura{factura}, nil
 }
+
+func (s service) AnularFactura(ctx context.Context, idFactura int) (Factura, error) {
+	//Se bloquea la factura para que dos anulaciones simultaneas no pasen ambas la verificacion
+	factura, err := s.repo.BloquearFactura(ctx, idFactura)
+	if err != nil {
+		return Factura{}, err
+	}
+	if factura.Anulada {
+		return Factura{}, errors.Conflict("La factura ya se encuentra anulada.")
+	}
+	if err := s.repo.AnularFactura(ctx, idFactura); err != nil {
+		return Factura{}, err
+	}
+	factura.Anulada = true
+	return Factura{factura}, nil
+}
+
+func (s service) GetTotalVentas(ctx context.Context, desde time.Time, hasta time.Time) (TotalVentas, error) {
+	totalVentas, err := s.repo.GetTotalVentas(ctx, desde, hasta)
+	if err != nil {
+		return TotalVentas{}, err
+	}
+	totalVentas.Desde = desde
+	totalVentas.Hasta = hasta
+	return totalVentas, nil
+}
+
+func (s service) CalcularTotales(ctx context.Context, detalles []detalle_factura.CreateDetalleFacturaRequest, porcentajeIva int) ([]detalle_factura.CreateDetalleFacturaRequest, Totales, error) {
+	totales := Totales{PorcentajeIva: porcentajeIva}
+	result := []detalle_factura.CreateDetalleFacturaRequest{}
+	for _, detalle := range detalles {
+		detalle.PorcentajeIva = 0
+		if detalle_factura.EsInventario(detalle.Tabla) {
+			//El precio y el IVA de los productos salen del catalogo, no de lo enviado por el cliente
+			producto, err := s.repo.GetProductoPorReferencia(ctx, detalle.Tabla, detalle.IdReferencia)
+			if err != nil {
+				return nil, Totales{}, err
+			}
+			detalle.PrecioUnitario = money.FromFloat(float64(producto.PrecioVenta))
+			detalle.GravaIva = producto.Iva.Valid && producto.Iva.Bool
+		}
+		//Los detalles sin inventario llevan IVA solo si se indica al generarlos
+		if detalle.GravaIva {
+			detalle.PorcentajeIva = porcentajeIva
+		}
+		linea, err := detalle_factura.CalcularLinea(detalle.Cantidad, detalle.PrecioUnitario, detalle.Descuento, detalle.PorcentajeIva)
+		if err != nil {
+			return nil, Totales{}, err
+		}
+		detalle.Linea = linea
+
+		if detalle.PorcentajeIva == 0 {
+			totales.Subtotal0 += linea.Subtotal
+		} else {
+			totales.SubtotalIva += linea.Subtotal
+		}
+		totales.Descuento += detalle.Descuento
+		totales.Iva += linea.ValorIva
+		result = append(result, detalle)
+	}
+	totales.Total = totales.Subtotal0 + totales.SubtotalIva + totales.Iva
+	return result, totales, nil
+}
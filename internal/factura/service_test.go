@@ -0,0 +1,103 @@
+package factura
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"veterinaria-server/internal/detalle_factura"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_CalcularTotales(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{productos: map[int]entity.Producto{
+		1: {IdProducto: 1, PrecioVenta: 5, Iva: sql.NullBool{Bool: true, Valid: true}},
+		2: {IdProducto: 2, PrecioVenta: 15},
+	}}
+	s := NewService(repo, logger)
+
+	detalles, totales, err := s.CalcularTotales(context.Background(), []detalle_factura.CreateDetalleFacturaRequest{
+		{Tabla: "lote", IdReferencia: 1, Cantidad: 3, Descuento: 100},
+		{Tabla: "lote", IdReferencia: 2, Cantidad: 1, PrecioUnitario: 1450, GravaIva: true},
+	}, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, detalles[0].PorcentajeIva)
+	assert.Equal(t, detalle_factura.Linea{Subtotal: 1400, ValorIva: 168, Valor: 1568}, detalles[0].Linea)
+	assert.Equal(t, 0, detalles[1].PorcentajeIva)
+	assert.Equal(t, detalle_factura.Linea{Subtotal: 1500, ValorIva: 0, Valor: 1500}, detalles[1].Linea)
+	assert.Equal(t, Totales{Subtotal0: 1500, SubtotalIva: 1400, Descuento: 100, PorcentajeIva: 12, Iva: 168, Total: 3068}, totales)
+
+	// los detalles sin inventario conservan el precio y el IVA indicados
+	detalles, _, err = s.CalcularTotales(context.Background(), []detalle_factura.CreateDetalleFacturaRequest{
+		{Tabla: detalle_factura.TablaConsulta, IdReferencia: 1, Cantidad: 1, PrecioUnitario: 2000, GravaIva: true},
+		{Tabla: detalle_factura.TablaExamen, IdReferencia: 1, Cantidad: 1, PrecioUnitario: 1000},
+	}, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, detalle_factura.Linea{Subtotal: 2000, ValorIva: 240, Valor: 2240}, detalles[0].Linea)
+	assert.Equal(t, detalle_factura.Linea{Subtotal: 1000, ValorIva: 0, Valor: 1000}, detalles[1].Linea)
+
+	// descuento mayor al valor del detalle
+	_, _, err = s.CalcularTotales(context.Background(), []detalle_factura.CreateDetalleFacturaRequest{
+		{Tabla: "lote", IdReferencia: 2, Cantidad: 1, Descuento: 2000},
+	}, 12)
+	assert.NotNil(t, err)
+}
+
+type mockRepository struct {
+	productos map[int]entity.Producto
+}
+
+func (m mockRepository) GetFacturaPorId(ctx context.Context, idFactura int) (entity.Factura, error) {
+	return entity.Factura{}, sql.ErrNoRows
+}
+
+func (m mockRepository) BloquearFactura(ctx context.Context, idFactura int) (entity.Factura, error) {
+	return entity.Factura{}, sql.ErrNoRows
+}
+
+func (m mockRepository) GetFacturas(ctx context.Context, query pagination.Query) ([]entity.Factura, *pagination.Pages, error) {
+	items := []entity.Factura{}
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m mockRepository) GetFacturasConDatos(ctx context.Context, query pagination.Query) ([]FacturaConDatos, *pagination.Pages, error) {
+	items := []FacturaConDatos{}
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m mockRepository) CrearFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error) {
+	return factura, nil
+}
+
+func (m mockRepository) ActualizarFactura(ctx context.Context, factura entity.Factura) (entity.Factura, error) {
+	return factura, nil
+}
+
+func (m mockRepository) AnularFactura(ctx context.Context, idFactura int) error {
+	return nil
+}
+
+func (m mockRepository) GetTotalVentas(ctx context.Context, desde time.Time, hasta time.Time) (TotalVentas, error) {
+	return TotalVentas{}, nil
+}
+
+func (m mockRepository) GetProductoPorReferencia(ctx context.Context, tabla string, idReferencia int) (entity.Producto, error) {
+	producto, ok := m.productos[idReferencia]
+	if !ok {
+		return entity.Producto{}, sql.ErrNoRows
+	}
+	return producto, nil
+}
+
+func (m mockRepository) GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error) {
+	return nil, nil
+}
@@ -0,0 +1,23 @@
+-- Un producto con dos lotes vigentes, el lote 2 caduca primero.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001');
+
+INSERT INTO tarifas_iva (id_tarifa_iva, porcentaje, fecha_inicio) VALUES
+    (1, 15, '2024-04-01');
+
+INSERT INTO producto (id_producto, descripcion, precio_venta, iva, uso_interno, venta_publico, por_medida, stock_minimo) VALUES
+    (1, 'Antiparasitario', 10.00, 1, 0, 1, 0, 0);
+
+INSERT INTO proveedor (id_proveedor, descripcion) VALUES
+    (1, 'Distribuidora Veterinaria');
+
+INSERT INTO proveedor_producto (id_proveedor_producto, id_proveedor, id_producto, precio_compra) VALUES
+    (1, 1, 1, 6.00);
+
+INSERT INTO lote (id_lote, id_proveedor_producto, fecha_caducidad, stock, descripcion) VALUES
+    (1, 1, '2099-12-31', 10, 'Lote tardío'),
+    (2, 1, '2099-06-30', 5, 'Lote temprano');
@@ -3,6 +3,7 @@ package generos
 import (
 	"strconv"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -22,12 +23,12 @@ type resource struct {
 }
 
 func (r resource) getGeneros(c *routing.Context) error {
-	generos, err := r.service.GetGeneros(c.Request.Context())
+	pages, err := r.service.GetGeneros(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-
-	return c.Write(generos)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getGeneroPorID(c *routing.Context) error {
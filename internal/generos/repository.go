@@ -5,12 +5,13 @@ import (
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access generos from the data source.
 type Repository interface {
 	// Get returns the list generos.
-	GetGeneros(ctx context.Context) ([]entity.Genero, error)
+	GetGeneros(ctx context.Context, query pagination.Query) ([]entity.Genero, *pagination.Pages, error)
 	GetGeneroPorID(ctx context.Context, idGenero int) (entity.Genero, error)
 }
 
@@ -25,18 +26,22 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposGeneros are the fields the list of generos can be filtered, searched and sorted by.
+var camposGeneros = pagination.Fields{
+	Search:      []string{"descripcion"},
+	Sort:        map[string]string{"id_genero": "id_genero", "descripcion": "descripcion"},
+	DefaultSort: []string{"id_genero asc"},
+}
+
 // Get reads the list generos from the database.
-func (r repository) GetGeneros(ctx context.Context) ([]entity.Genero, error) {
-	var generos []entity.Genero
+func (r repository) GetGeneros(ctx context.Context, query pagination.Query) ([]entity.Genero, *pagination.Pages, error) {
+	var generos []entity.Genero = []entity.Genero{}
 
-	err := r.db.With(ctx).
-		Select().
-		From("generos").
-		All(&generos)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("generos"), camposGeneros, &generos)
 	if err != nil {
-		return generos, err
+		return nil, nil, err
 	}
-	return generos, err
+	return generos, pages, nil
 }
 
 func (r repository) GetGeneroPorID(ctx context.Context, idGenero int) (entity.Genero, error) {
@@ -4,11 +4,12 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Service encapsulates usecase logic for generos.
 type Service interface {
-	GetGeneros(ctx context.Context) ([]Generos, error)
+	GetGeneros(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetGeneroPorID(ctx context.Context, idGenero int) (Generos, error)
 }
 
@@ -28,8 +29,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list generos.
-func (s service) GetGeneros(ctx context.Context) ([]Generos, error) {
-	generos, err := s.repo.GetGeneros(ctx)
+func (s service) GetGeneros(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	generos, pages, err := s.repo.GetGeneros(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +38,8 @@ func (s service) GetGeneros(ctx context.Context) ([]Generos, error) {
 	for _, item := range generos {
 		result = append(result, Generos{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetGeneroPorID(ctx context.Context, idGenero int) (Generos, error) {
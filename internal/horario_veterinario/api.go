@@ -0,0 +1,57 @@
+package horario_veterinario
+
+import (
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/horariosVeterinario", res.getHorarios)
+	r.Get("/horariosVeterinario/<idHorarioVeterinario>", res.getHorarioPorId)
+	r.Put("/horariosVeterinario", res.actualizarHorario)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getHorarios(c *routing.Context) error {
+	pages, err := r.service.GetHorarios(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getHorarioPorId(c *routing.Context) error {
+	idHorarioVeterinario, _ := strconv.Atoi(c.Param("idHorarioVeterinario"))
+	horario, err := r.service.GetHorarioPorId(c.Request.Context(), idHorarioVeterinario)
+	if err != nil {
+		return err
+	}
+	return c.Write(horario)
+}
+
+func (r resource) actualizarHorario(c *routing.Context) error {
+	var input UpdateHorarioRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	horario, err := r.service.ActualizarHorario(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(horario, http.StatusCreated)
+}
@@ -0,0 +1,83 @@
+package horario_veterinario
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access the horarios of the veterinarios from the data source.
+type Repository interface {
+	GetHorarios(ctx context.Context, query pagination.Query) ([]entity.HorarioVeterinario, *pagination.Pages, error)
+	GetHorarioPorId(ctx context.Context, idHorarioVeterinario int) (entity.HorarioVeterinario, error)
+	// GetHorariosActivos returns the active horarios of the veterinario on the dia de la semana.
+	GetHorariosActivos(ctx context.Context, idUsuario int, diaSemana int) ([]entity.HorarioVeterinario, error)
+	ActualizarHorario(ctx context.Context, horario entity.HorarioVeterinario) (entity.HorarioVeterinario, error)
+}
+
+// repository persists the horarios of the veterinarios in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new horarioVeterinario repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposHorarios are the fields the list of horarios can be filtered and sorted by.
+var camposHorarios = pagination.Fields{
+	Filters: map[string]string{"id_usuario": "id_usuario", "dia_semana": "dia_semana", "activo": "activo"},
+	Sort: map[string]string{
+		"id_horario_veterinario": "id_horario_veterinario",
+		"id_usuario":             "id_usuario",
+		"dia_semana":             "dia_semana",
+		"hora_inicio":            "hora_inicio",
+	},
+	DefaultSort: []string{"id_usuario asc", "dia_semana asc", "hora_inicio asc"},
+}
+
+func (r repository) GetHorarios(ctx context.Context, query pagination.Query) ([]entity.HorarioVeterinario, *pagination.Pages, error) {
+	var horarios []entity.HorarioVeterinario = []entity.HorarioVeterinario{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("horarios_veterinario"), camposHorarios, &horarios)
+	if err != nil {
+		return nil, nil, err
+	}
+	return horarios, pages, nil
+}
+
+func (r repository) GetHorarioPorId(ctx context.Context, idHorarioVeterinario int) (entity.HorarioVeterinario, error) {
+	var horario entity.HorarioVeterinario
+	err := r.db.With(ctx).Select().Model(idHorarioVeterinario, &horario)
+	return horario, err
+}
+
+func (r repository) GetHorariosActivos(ctx context.Context, idUsuario int, diaSemana int) ([]entity.HorarioVeterinario, error) {
+	var horarios []entity.HorarioVeterinario = []entity.HorarioVeterinario{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_usuario": idUsuario, "dia_semana": diaSemana, "activo": true}).
+		OrderBy("hora_inicio asc").
+		All(&horarios)
+	return horarios, err
+}
+
+func (r repository) ActualizarHorario(ctx context.Context, horario entity.HorarioVeterinario) (entity.HorarioVeterinario, error) {
+	var err error
+	if horario.IdHorarioVeterinario != 0 {
+		err = auditoria.Actualizar(ctx, r.db, &horario)
+	} else {
+		err = auditoria.Insertar(ctx, r.db, &horario)
+	}
+	if err != nil {
+		return entity.HorarioVeterinario{}, err
+	}
+	return horario, nil
+}
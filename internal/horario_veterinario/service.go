@@ -0,0 +1,159 @@
+package horario_veterinario
+
+import (
+	"context"
+	"regexp"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// formatoHora is a time of the day as "15:04", the database returns it as "15:04:05".
+var formatoHora = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9](:[0-5][0-9])?$`)
+
+// Service encapsulates usecase logic for the horarios of the veterinarios.
+type Service interface {
+	GetHorarios(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	GetHorarioPorId(ctx context.Context, idHorarioVeterinario int) (Horario, error)
+	// ActualizarHorario creates a horario of a veterinario when it has no ID and updates it otherwise.
+	ActualizarHorario(ctx context.Context, input UpdateHorarioRequest) (Horario, error)
+}
+
+// Horario is a time of a dia de la semana a veterinario attends citas.
+type Horario struct {
+	entity.HorarioVeterinario
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new horariosVeterinario service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+func (s service) GetHorarios(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	horarios, pages, err := s.repo.GetHorarios(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Horario{}
+	for _, item := range horarios {
+		result = append(result, Horario{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetHorarioPorId(ctx context.Context, idHorarioVeterinario int) (Horario, error) {
+	horario, err := s.repo.GetHorarioPorId(ctx, idHorarioVeterinario)
+	if err != nil {
+		return Horario{}, err
+	}
+	return Horario{horario}, nil
+}
+
+// UpdateHorarioRequest represents a horario creation or update request.
+type UpdateHorarioRequest struct {
+	IdHorarioVeterinario int    `json:"id_horario_veterinario"`
+	IdUsuario            int    `json:"id_usuario"`
+	DiaSemana            int    `json:"dia_semana"`
+	HoraInicio           string `json:"hora_inicio"`
+	HoraFin              string `json:"hora_fin"`
+	Activo               bool   `json:"activo"`
+}
+
+// Validate validates the UpdateHorarioRequest fields. The horas are "15:04" and the horario ends after it starts.
+func (m UpdateHorarioRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.IdUsuario, validation.Required),
+		validation.Field(&m.DiaSemana, validation.Min(0), validation.Max(6)),
+		validation.Field(&m.HoraInicio, validation.Required, validation.Match(formatoHora)),
+		validation.Field(&m.HoraFin, validation.Required, validation.Match(formatoHora), validation.By(func(interface{}) error {
+			inicio, errInicio := minutos(m.HoraInicio)
+			fin, errFin := minutos(m.HoraFin)
+			if errInicio == nil && errFin == nil && fin <= inicio {
+				return validation.NewError("validation_hora_fin", "must be after hora_inicio")
+			}
+			return nil
+		})),
+	)
+}
+
+func (s service) ActualizarHorario(ctx context.Context, req UpdateHorarioRequest) (Horario, error) {
+	if err := req.Validate(); err != nil {
+		return Horario{}, err
+	}
+	if req.IdHorarioVeterinario != 0 {
+		if _, err := s.repo.GetHorarioPorId(ctx, req.IdHorarioVeterinario); err != nil {
+			return Horario{}, err
+		}
+	}
+	horario := entity.HorarioVeterinario{
+		IdHorarioVeterinario: req.IdHorarioVeterinario,
+		IdUsuario:            req.IdUsuario,
+		DiaSemana:            req.DiaSemana,
+		HoraInicio:           req.HoraInicio,
+		HoraFin:              req.HoraFin,
+		Activo:               req.Activo,
+	}
+	if req.Activo {
+		activos, err := s.repo.GetHorariosActivos(ctx, req.IdUsuario, req.DiaSemana)
+		if err != nil {
+			return Horario{}, err
+		}
+		for _, activo := range activos {
+			if activo.IdHorarioVeterinario != req.IdHorarioVeterinario && Solapados(activo, horario) {
+				return Horario{}, errors.Conflict("El veterinario ya tiene un horario que se cruza con ese horario.")
+			}
+		}
+	}
+	horarioG, err := s.repo.ActualizarHorario(ctx, horario)
+	if err != nil {
+		return Horario{}, err
+	}
+	return Horario{horarioG}, nil
+}
+
+// Intervalo returns when the horario starts and ends on the dia.
+func Intervalo(horario entity.HorarioVeterinario, dia time.Time) (time.Time, time.Time, error) {
+	inicio, err := minutos(horario.HoraInicio)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	fin, err := minutos(horario.HoraFin)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	medianoche := time.Date(dia.Year(), dia.Month(), dia.Day(), 0, 0, 0, 0, dia.Location())
+	return medianoche.Add(time.Duration(inicio) * time.Minute), medianoche.Add(time.Duration(fin) * time.Minute), nil
+}
+
+// Solapados reports whether both horarios share some time. Horarios with invalid horas never overlap.
+func Solapados(a, b entity.HorarioVeterinario) bool {
+	inicioA, errInicioA := minutos(a.HoraInicio)
+	finA, errFinA := minutos(a.HoraFin)
+	inicioB, errInicioB := minutos(b.HoraInicio)
+	finB, errFinB := minutos(b.HoraFin)
+	if errInicioA != nil || errFinA != nil || errInicioB != nil || errFinB != nil {
+		return false
+	}
+	return inicioA < finB && inicioB < finA
+}
+
+// minutos returns the minutes since midnight of a hora written as "15:04" or "15:04:05".
+func minutos(hora string) (int, error) {
+	t, err := time.Parse("15:04:05", hora)
+	if err != nil {
+		if t, err = time.Parse("15:04", hora); err != nil {
+			return 0, err
+		}
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
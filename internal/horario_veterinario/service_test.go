@@ -0,0 +1,41 @@
+package horario_veterinario
+
+import (
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateHorarioRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   UpdateHorarioRequest
+		wantErr bool
+	}{
+		{"success", UpdateHorarioRequest{IdUsuario: 1, DiaSemana: 0, HoraInicio: "08:00", HoraFin: "12:30", Activo: true}, false},
+		{"formato de la base", UpdateHorarioRequest{IdUsuario: 1, DiaSemana: 6, HoraInicio: "08:00:00", HoraFin: "12:30:00"}, false},
+		{"dia invalido", UpdateHorarioRequest{IdUsuario: 1, DiaSemana: 7, HoraInicio: "08:00", HoraFin: "12:00"}, true},
+		{"hora invalida", UpdateHorarioRequest{IdUsuario: 1, DiaSemana: 1, HoraInicio: "8:00", HoraFin: "24:00"}, true},
+		{"termina antes de empezar", UpdateHorarioRequest{IdUsuario: 1, DiaSemana: 1, HoraInicio: "14:00", HoraFin: "12:00"}, true},
+		{"sin veterinario", UpdateHorarioRequest{DiaSemana: 1, HoraInicio: "08:00", HoraFin: "12:00"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestIntervalo(t *testing.T) {
+	dia := time.Date(2026, 10, 5, 15, 30, 0, 0, time.Local)
+	inicio, fin, err := Intervalo(entity.HorarioVeterinario{HoraInicio: "08:00:00", HoraFin: "12:30"}, dia)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2026, 10, 5, 8, 0, 0, 0, time.Local), inicio)
+	assert.Equal(t, time.Date(2026, 10, 5, 12, 30, 0, 0, time.Local), fin)
+
+	assert.True(t, Solapados(entity.HorarioVeterinario{HoraInicio: "08:00", HoraFin: "12:00"}, entity.HorarioVeterinario{HoraInicio: "11:00", HoraFin: "13:00"}))
+	assert.False(t, Solapados(entity.HorarioVeterinario{HoraInicio: "08:00", HoraFin: "12:00"}, entity.HorarioVeterinario{HoraInicio: "12:00", HoraFin: "13:00"}))
+}
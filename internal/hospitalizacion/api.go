@@ -4,10 +4,16 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/detalle_hospitalizacion"
 	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/factura"
+	"veterinaria-server/internal/pago"
+	"veterinaria-server/internal/tarifa_iva"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -23,6 +29,7 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 	r.Get("/hospitalizaciones/<idHospitalizacion>", res.getHospitalizacionPorId)
 	r.Post("/hospitalizaciones", res.crearHospitalizacion)
 	r.Put("/hospitalizaciones", res.actualizarHospitalizacion)
+	r.Post("/hospitalizaciones/<idHospitalizacion>/alta", res.darAlta)
 }
 
 type resource struct {
@@ -32,11 +39,12 @@ type resource struct {
 }
 
 func (r resource) getHospitalizaciones(c *routing.Context) error {
-	hospitalizaciones, err := r.service.GetHospitalizaciones(c.Request.Context())
+	pages, err := r.service.GetHospitalizaciones(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(hospitalizaciones)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getHospitalizacionesActivas(c *routing.Context) error {
@@ -84,7 +92,7 @@ func (r resource) actualizarHospitalizacion(c *routing.Context) error {
 		var detalleHospitalizacion detalle_hospitalizacion.UpdateDetalleHospitalizacionRequest = detalle_hospitalizacion.UpdateDetalleHospitalizacionRequest{
 			IdDetalleHospitalizacion: 0,
 			IdHospitalizacion:        hospitalizacion.IdHospitalizacion,
-			IdUsuario:                input.IdUsuario,
+			IdUsuario:                0, //La bitacora la registra el usuario actual
 			Descripcion:              "Ingreso al área de hospitalización",
 			Fecha:                    time.Now(),
 		}
@@ -104,3 +112,103 @@ func (r resource) getHospitalizacionPorId(c *routing.Context) error {
 	}
 	return c.Write(hospitalizacion)
 }
+
+func (r resource) darAlta(c *routing.Context) error {
+	idHospitalizacion, _ := strconv.Atoi(c.Param("idHospitalizacion"))
+	var input AltaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	identity := auth.CurrentUser(c.Request.Context())
+	if identity == nil {
+		return errors.Unauthorized("")
+	}
+
+	alta, err := r.service.PrepararAlta(c.Request.Context(), idHospitalizacion, input)
+	if err != nil {
+		return err
+	}
+
+	//Factura final al dueño de la mascota con todos los cargos de la hospitalización
+	var facturaG factura.Factura
+	detallesFacturaG := []detalle_factura.DetalleFactura{}
+	pagosG := []pago.Pago{}
+	if len(alta.Detalles) > 0 {
+		st := tarifa_iva.NewService(tarifa_iva.NewRepository(r.db, r.logger), r.logger)
+		tarifaIva, err := st.GetTarifaIvaVigente(c.Request.Context(), alta.FechaSalida)
+		if err != nil {
+			return err
+		}
+		sf := factura.NewService(factura.NewRepository(r.db, r.logger), r.logger)
+		detalles, totales, err := sf.CalcularTotales(c.Request.Context(), alta.Detalles, tarifaIva.Porcentaje)
+		if err != nil {
+			return err
+		}
+		origen := "hospitalizacion"
+		facturaG, err = sf.CrearFactura(c.Request.Context(), factura.CreateFacturaRequest{
+			IdCliente: alta.IdCliente,
+			IdUsuario: identity.GetIdUsuario(),
+			Fecha:     alta.FechaSalida,
+			Totales:   totales,
+			Origen:    &origen,
+			IdOrigen:  &idHospitalizacion,
+		})
+		if err != nil {
+			return err
+		}
+		//Los cargos de hospitalización no generan movimientos de inventario, los productos ya se descontaron al usarse
+		sd := detalle_factura.NewService(detalle_factura.NewRepository(r.db, r.logger), r.logger)
+		for _, detalle := range detalles {
+			detalle.IdFactura = facturaG.IdFactura
+			detalleFacturaG, err := sd.CrearDetalleFactura(c.Request.Context(), detalle)
+			if err != nil {
+				return err
+			}
+			detallesFacturaG = append(detallesFacturaG, detalleFacturaG)
+		}
+		//Los abonos recibidos durante la hospitalización se aplican a la factura
+		sp := pago.NewService(pago.NewRepository(r.db, r.logger), r.logger)
+		pagosG, err = sp.AplicarAbonosHospitalizacion(c.Request.Context(), idHospitalizacion, facturaG.IdFactura)
+		if err != nil {
+			return err
+		}
+	}
+
+	h := alta.Hospitalizacion
+	hospitalizacion, err := r.service.ActualizarHospitalizacion(c.Request.Context(), UpdateHospitalizacionRequest{
+		IdHospitalizacion:     h.IdHospitalizacion,
+		IdConsulta:            h.IdConsulta,
+		Motivo:                h.Motivo,
+		FechaIngreso:          h.FechaIngreso,
+		FechaSalida:           &alta.FechaSalida,
+		Valor:                 h.Valor,
+		Abono:                 h.Abono,
+		AutorizaExamenes:      h.AuorizaExamenes,
+		EstadoHospitalizacion: EstadoFinalizado,
+		IdUsuario:             identity.GetIdUsuario(),
+	})
+	if err != nil {
+		return err
+	}
+	s := detalle_hospitalizacion.NewService(detalle_hospitalizacion.NewRepository(r.db, r.logger), r.logger)
+	_, err = s.ActualizarDetalleHospitalizacion(c.Request.Context(), detalle_hospitalizacion.UpdateDetalleHospitalizacionRequest{
+		IdDetalleHospitalizacion: 0,
+		IdHospitalizacion:        hospitalizacion.IdHospitalizacion,
+		IdUsuario:                identity.GetIdUsuario(),
+		Descripcion:              "Alta de hospitalización",
+		Fecha:                    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var result = struct {
+		Hospitalizacion Hospitalizacion
+		Factura         factura.Factura
+		DetallesFactura []detalle_factura.DetalleFactura
+		Pagos           []pago.Pago
+	}{hospitalizacion, facturaG, detallesFacturaG, pagosG}
+
+	return c.WriteWithStatus(result, http.StatusCreated)
+}
@@ -0,0 +1,136 @@
+package hospitalizacion
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_hospitalizacion(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), logger), auth.MockAuthHandler, logger, db)
+
+	tests := []struct {
+		caso     test.APITestCase
+		estados  map[int]string //Estado de cada hospitalizacion luego de la peticion
+		detalles int            //Detalles registrados en la bitacora
+		facturas int
+	}{
+		{
+			caso: test.APITestCase{
+				Name:         "ingreso",
+				Method:       "PUT",
+				URL:          "/hospitalizaciones",
+				Body:         `{"id_consulta":1,"motivo":"Fractura de cadera","estado_hospitalizacion":"ACTIVA","autoriza_examenes":{"Bool":true,"Valid":true}}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"id_hospitalizacion":4*`,
+			},
+			estados:  map[int]string{1: EstadoActiva, 4: EstadoActiva},
+			detalles: 1,
+			facturas: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "actualizacion",
+				Method:       "PUT",
+				URL:          "/hospitalizaciones",
+				Body:         `{"id_hospitalizacion":1,"id_consulta":1,"motivo":"Deshidratación severa","valor":30,"estado_hospitalizacion":"ACTIVA"}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"motivo":"Deshidratación severa"*`,
+			},
+			estados:  map[int]string{1: EstadoActiva},
+			detalles: 0,
+			facturas: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "sin motivo",
+				Method:       "PUT",
+				URL:          "/hospitalizaciones",
+				Body:         `{"id_consulta":1,"estado_hospitalizacion":"ACTIVA"}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusBadRequest,
+				WantResponse: "",
+			},
+			estados:  map[int]string{1: EstadoActiva},
+			detalles: 0,
+			facturas: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "alta",
+				Method:       "POST",
+				URL:          "/hospitalizaciones/1/alta",
+				Body:         `{"fecha_salida":"2026-10-03T08:00:00Z","tarifa_diaria":20}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"estado_hospitalizacion":"FINALIZADO"*`,
+			},
+			estados:  map[int]string{1: EstadoFinalizado},
+			detalles: 1,
+			facturas: 1,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "alta de una hospitalizacion finalizada",
+				Method:       "POST",
+				URL:          "/hospitalizaciones/2/alta",
+				Body:         `{"tarifa_diaria":20}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusConflict,
+				WantResponse: "",
+			},
+			estados:  map[int]string{2: EstadoFinalizado},
+			detalles: 0,
+			facturas: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "alta con examenes pendientes",
+				Method:       "POST",
+				URL:          "/hospitalizaciones/3/alta",
+				Body:         `{"fecha_salida":"2026-10-02T08:00:00Z"}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusConflict,
+				WantResponse: `*"Hemograma"*`,
+			},
+			estados:  map[int]string{3: EstadoActiva},
+			detalles: 0,
+			facturas: 0,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "alta ignorando examenes pendientes",
+				Method:       "POST",
+				URL:          "/hospitalizaciones/3/alta",
+				Body:         `{"fecha_salida":"2026-10-02T08:00:00Z","ignorar_examenes_pendientes":true}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"Examen: Hemograma"*`,
+			},
+			estados:  map[int]string{3: EstadoFinalizado},
+			detalles: 1,
+			facturas: 1,
+		},
+	}
+	for _, tc := range tests {
+		test.Fixtures(t, db, "testdata/fixtures.sql")
+		test.Endpoint(t, router, tc.caso)
+		for idHospitalizacion, estado := range tc.estados {
+			assert.Equal(t, 1, test.Count(t, db, "hospitalizacion", dbx.HashExp{"id_hospitalizacion": idHospitalizacion, "estado_hospitalizacion": estado}), "%s: hospitalizacion %d", tc.caso.Name, idHospitalizacion)
+		}
+		assert.Equal(t, tc.detalles, test.Count(t, db, "detalles_hospitalizacion", nil), tc.caso.Name)
+		assert.Equal(t, tc.facturas, test.Count(t, db, "facturas", dbx.HashExp{"origen": "hospitalizacion"}), tc.caso.Name)
+	}
+	//El examen se factura por lo que sumo a la hospitalizacion, no por el valor de su tipo
+	assert.Equal(t, 1, test.Count(t, db, "detalles_factura", dbx.HashExp{"tabla": "examenes_mascota", "precio_unitario": 12.5}))
+}
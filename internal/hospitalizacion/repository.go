@@ -2,9 +2,11 @@ package hospitalizacion
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -13,12 +15,21 @@ import (
 type Repository interface {
 	// GetHospitalizacionPorId returns the hospitalizacion with the specified hospitalizacion ID.
 	GetHospitalizacionPorId(ctx context.Context, idHospitalizacion int) (entity.Hospitalizacion, error)
+	// BloquearHospitalizacion reads the hospitalizacion and locks its row until the current transaction ends.
+	BloquearHospitalizacion(ctx context.Context, idHospitalizacion int) (entity.Hospitalizacion, error)
 	// GetHospitalizaciones returns the list hospitalizaciones.
-	GetHospitalizaciones(ctx context.Context) ([]entity.Hospitalizacion, error)
+	GetHospitalizaciones(ctx context.Context, query pagination.Query) ([]entity.Hospitalizacion, *pagination.Pages, error)
 	GetHospitalizacionesActivas(ctx context.Context) ([]HospitalizacionesActivas, error)
 	GetHospitalizacionesFinalizadas(ctx context.Context) ([]HospitalizacionesActivas, error)
 	CrearHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error)
 	ActualizarHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error)
+	// GetIdClienteHospitalizacion returns the owner of the mascota hospitalized.
+	GetIdClienteHospitalizacion(ctx context.Context, idHospitalizacion int) (int, error)
+	GetServiciosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error)
+	// GetExamenesHospitalizacion returns the examenes requested during the hospitalizacion with the valor they added to it.
+	GetExamenesHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error)
+	// GetProductosHospitalizacion returns the productos used by the servicios of the hospitalizacion.
+	GetProductosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]ProductoUsado, error)
 }
 
 // repository persists hospitalizaciones in database
@@ -32,18 +43,38 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposHospitalizaciones are the fields the list of hospitalizaciones can be filtered, searched and sorted by.
+var camposHospitalizaciones = pagination.Fields{
+	Filters: map[string]string{
+		"id_consulta":            "id_consulta",
+		"autoriza_examenes":      "autoriza_examenes",
+		"estado_hospitalizacion": "estado_hospitalizacion",
+	},
+	Ranges: map[string]string{"fecha_ingreso": "fecha_ingreso", "fecha_salida": "fecha_salida"},
+	Search: []string{"motivo"},
+	Sort: map[string]string{
+		"id_hospitalizacion":     "id_hospitalizacion",
+		"id_consulta":            "id_consulta",
+		"motivo":                 "motivo",
+		"fecha_ingreso":          "fecha_ingreso",
+		"fecha_salida":           "fecha_salida",
+		"valor":                  "valor",
+		"abono":                  "abono",
+		"autoriza_examenes":      "autoriza_examenes",
+		"estado_hospitalizacion": "estado_hospitalizacion",
+	},
+	DefaultSort: []string{"id_hospitalizacion asc"},
+}
+
 // Get reads the list hospitalizaciones from the database.
-func (r repository) GetHospitalizaciones(ctx context.Context) ([]entity.Hospitalizacion, error) {
-	var hospitalizaciones []entity.Hospitalizacion
+func (r repository) GetHospitalizaciones(ctx context.Context, query pagination.Query) ([]entity.Hospitalizacion, *pagination.Pages, error) {
+	var hospitalizaciones []entity.Hospitalizacion = []entity.Hospitalizacion{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&hospitalizaciones)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("hospitalizacion"), camposHospitalizaciones, &hospitalizaciones)
 	if err != nil {
-		return hospitalizaciones, err
+		return nil, nil, err
 	}
-	return hospitalizaciones, err
+	return hospitalizaciones, pages, nil
 }
 
 func (r repository) GetHospitalizacionesActivas(ctx context.Context) ([]HospitalizacionesActivas, error) {
@@ -151,7 +182,7 @@ func (r repository) GetHospitalizacionesFinalizadas(ctx context.Context) ([]Hosp
 // Create saves a new Hospitalizacion record in the database.
 // It returns the ID of the newly inserted hospitalizacion record.
 func (r repository) CrearHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error) {
-	err := r.db.With(ctx).Model(&hospitalizacion).Insert()
+	err := auditoria.Insertar(ctx, r.db, &hospitalizacion)
 	if err != nil {
 		return entity.Hospitalizacion{}, err
 	}
@@ -163,9 +194,9 @@ func (r repository) CrearHospitalizacion(ctx context.Context, hospitalizacion en
 func (r repository) ActualizarHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error) {
 	var err error
 	if hospitalizacion.IdHospitalizacion != 0 {
-		err = r.db.With(ctx).Model(&hospitalizacion).Update()
+		err = auditoria.Actualizar(ctx, r.db, &hospitalizacion)
 	} else {
-		err = r.db.With(ctx).Model(&hospitalizacion).Insert()
+		err = auditoria.Insertar(ctx, r.db, &hospitalizacion)
 	}
 	if err != nil {
 		return entity.Hospitalizacion{}, err
@@ -179,3 +210,65 @@ func (r repository) GetHospitalizacionPorId(ctx context.Context, idHospitalizaci
 	err := r.db.With(ctx).Select().Model(idHospitalizacion, &hospitalizacion)
 	return hospitalizacion, err
 }
+
+func (r repository) BloquearHospitalizacion(ctx context.Context, idHospitalizacion int) (entity.Hospitalizacion, error) {
+	var hospitalizacion entity.Hospitalizacion
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM hospitalizacion WHERE id_hospitalizacion = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idHospitalizacion}).
+		One(&hospitalizacion)
+	return hospitalizacion, err
+}
+
+func (r repository) GetIdClienteHospitalizacion(ctx context.Context, idHospitalizacion int) (int, error) {
+	var idCliente int
+	err := r.db.With(ctx).
+		Select("m.id_cliente").
+		From("hospitalizacion h").
+		InnerJoin("consulta c", dbx.NewExp("c.id_consulta = h.id_consulta")).
+		InnerJoin("mascotas m", dbx.NewExp("m.id_mascota = c.id_mascota")).
+		Where(dbx.HashExp{"h.id_hospitalizacion": idHospitalizacion}).
+		Row(&idCliente)
+	return idCliente, err
+}
+
+func (r repository) GetServiciosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error) {
+	var servicios []CargoHospitalizacion = []CargoHospitalizacion{}
+	err := r.db.With(ctx).
+		Select("dsh.id_detalle_servicio_hospitalizacion as id_referencia", "s.descripcion", "dsh.valor", "'' as estado").
+		From("detalles_servicios_hospitalizacion dsh").
+		InnerJoin("servicios s", dbx.NewExp("s.id_servicio = dsh.id_servicio")).
+		Where(dbx.HashExp{"dsh.id_hospitalizacion": idHospitalizacion}).
+		OrderBy("dsh.fecha asc").
+		All(&servicios)
+	return servicios, err
+}
+
+func (r repository) GetExamenesHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error) {
+	var examenes []CargoHospitalizacion = []CargoHospitalizacion{}
+	err := r.db.With(ctx).
+		Select("em.id_examen_mascota as id_referencia", "te.titulo as descripcion", "em.valor", "em.estado").
+		From("examenes_mascota em").
+		InnerJoin("tipos_examenes te", dbx.NewExp("te.id_tipo_examen = em.id_tipo_examen")).
+		Where(dbx.HashExp{"em.id_referencia": idHospitalizacion}).
+		AndWhere(dbx.NewExp("em.tabla <> 'Consulta'")).
+		OrderBy("em.fecha_solicitud asc").
+		All(&examenes)
+	return examenes, err
+}
+
+func (r repository) GetProductosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]ProductoUsado, error) {
+	var productos []ProductoUsado = []ProductoUsado{}
+	err := r.db.With(ctx).
+		Select("dus.id_detalle_uso_servicio", "dus.tabla", "dus.cantidad", "p.descripcion", "p.precio_venta", "p.iva", "p.uso_interno", "p.contenido").
+		From("detalle_usos_servicio dus").
+		InnerJoin("detalles_servicios_hospitalizacion dsh", dbx.NewExp("dsh.id_detalle_servicio_hospitalizacion = dus.id_detalle_servicio_hospitalizacion")).
+		LeftJoin("stock_individual si", dbx.NewExp("dus.tabla <> 'lote' and si.id_stock_individual = dus.id_referencia")).
+		InnerJoin("lote l", dbx.NewExp("l.id_lote = (case when dus.tabla = 'lote' then dus.id_referencia else si.id_lote end)")).
+		InnerJoin("proveedor_producto pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
+		InnerJoin("producto p", dbx.NewExp("p.id_producto = pp.id_producto")).
+		Where(dbx.HashExp{"dsh.id_hospitalizacion": idHospitalizacion}).
+		OrderBy("dus.id_detalle_uso_servicio asc").
+		All(&productos)
+	return productos, err
+}
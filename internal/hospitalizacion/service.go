@@ -3,23 +3,40 @@ package hospitalizacion
 import (
 	"context"
 	"database/sql"
+	"math"
 	"time"
+	"veterinaria-server/internal/detalle_factura"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for hospitalizaciones.
 type Service interface {
-	GetHospitalizaciones(ctx context.Context) ([]Hospitalizacion, error)
+	GetHospitalizaciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetHospitalizacionesActivas(ctx context.Context) ([]HospitalizacionesActivas, error)
 	GetHospitalizacionesFinalizadas(ctx context.Context) ([]HospitalizacionesActivas, error)
 	GetHospitalizacionPorId(ctx context.Context, idHospitalizacion int) (Hospitalizacion, error)
 	CrearHospitalizacion(ctx context.Context, input CreateHospitalizacionRequest) (Hospitalizacion, error)
 	ActualizarHospitalizacion(ctx context.Context, input UpdateHospitalizacionRequest) (Hospitalizacion, error)
+	// PrepararAlta gathers the charges of an active hospitalizacion into the detalles of its final factura.
+	// It refuses the discharge while examenes are pending unless the request overrides it.
+	PrepararAlta(ctx context.Context, idHospitalizacion int, input AltaRequest) (Alta, error)
 }
 
+// Estados de una hospitalización.
+const (
+	EstadoActiva     = "ACTIVA"
+	EstadoFinalizado = "FINALIZADO"
+)
+
+// estadoExamenFinalizado is the estado of an examenMascota with results.
+const estadoExamenFinalizado = "FINALIZADO"
+
 // Hospitalizaciones represents the data about an hospitalizaciones.
 type Hospitalizacion struct {
 	entity.Hospitalizacion
@@ -32,19 +49,63 @@ type HospitalizacionesActivas struct {
 	entity.Consulta        `json:"consulta"`
 }
 
+// CargoHospitalizacion is a servicio or examen charged to a hospitalizacion.
+type CargoHospitalizacion struct {
+	IdReferencia int     `json:"id_referencia" db:"id_referencia"`
+	Descripcion  string  `json:"descripcion" db:"descripcion"`
+	Valor        float32 `json:"valor" db:"valor"`
+	Estado       string  `json:"estado" db:"estado"`
+}
+
+// ProductoUsado is a producto consumed by a servicio of the hospitalizacion.
+type ProductoUsado struct {
+	IdDetalleUsoServicio int          `db:"id_detalle_uso_servicio"`
+	Tabla                string       `db:"tabla"`
+	Cantidad             float32      `db:"cantidad"`
+	Descripcion          string       `db:"descripcion"`
+	PrecioVenta          float32      `db:"precio_venta"`
+	Iva                  sql.NullBool `db:"iva"`
+	UsoInterno           sql.NullBool `db:"uso_interno"`
+	Contenido            *float32     `db:"contenido"`
+}
+
+// AltaRequest represents the discharge of a hospitalizacion.
+type AltaRequest struct {
+	FechaSalida *time.Time `json:"fecha_salida"`
+	// TarifaDiaria overrides the configured daily stay charge.
+	TarifaDiaria *money.Money `json:"tarifa_diaria"`
+	// IgnorarExamenesPendientes allows the discharge while examenes have no results yet.
+	IgnorarExamenesPendientes bool `json:"ignorar_examenes_pendientes"`
+}
+
+// Alta is a hospitalizacion ready to be discharged with the detalles of its factura.
+type Alta struct {
+	Hospitalizacion entity.Hospitalizacion
+	IdCliente       int
+	FechaSalida     time.Time
+	Dias            int
+	Detalles        []detalle_factura.CreateDetalleFacturaRequest
+}
+
 type service struct {
-	repo   Repository
-	logger log.Logger
+	repo         Repository
+	logger       log.Logger
+	tarifaDiaria money.Money
 }
 
 // NewService creates a new hospitalizaciones service.
 func NewService(repo Repository, logger log.Logger) Service {
-	return service{repo, logger}
+	return service{repo, logger, 0}
+}
+
+// NewServiceConTarifa creates a new hospitalizaciones service that charges the given amount per day of stay at discharge.
+func NewServiceConTarifa(repo Repository, logger log.Logger, tarifaDiaria money.Money) Service {
+	return service{repo, logger, tarifaDiaria}
 }
 
 // Get returns the list hospitalizaciones.
-func (s service) GetHospitalizaciones(ctx context.Context) ([]Hospitalizacion, error) {
-	hospitalizaciones, err := s.repo.GetHospitalizaciones(ctx)
+func (s service) GetHospitalizaciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	hospitalizaciones, pages, err := s.repo.GetHospitalizaciones(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +113,8 @@ func (s service) GetHospitalizaciones(ctx context.Context) ([]Hospitalizacion, e
 	for _, item := range hospitalizaciones {
 		result = append(result, Hospitalizacion{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetHospitalizacionesActivas(ctx context.Context) ([]HospitalizacionesActivas, error) {
@@ -165,3 +227,118 @@ func (s service) GetHospitalizacionPorId(ctx context.Context, idHospitalizacion
 	}
 	return Hospitalizacion{hospitalizacion}, nil
 }
+
+func (s service) PrepararAlta(ctx context.Context, idHospitalizacion int, req AltaRequest) (Alta, error) {
+	//Bloqueada hasta el fin de la transaccion, dos altas simultaneas no la facturan dos veces
+	hospitalizacion, err := s.repo.BloquearHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return Alta{}, err
+	}
+	if hospitalizacion.EstadoHospitalizacion != EstadoActiva {
+		return Alta{}, errors.Conflict("La hospitalización ya fue finalizada.")
+	}
+	alta := Alta{Hospitalizacion: hospitalizacion, FechaSalida: time.Now()}
+	if req.FechaSalida != nil {
+		alta.FechaSalida = *req.FechaSalida
+	}
+	if alta.FechaSalida.Before(hospitalizacion.FechaIngreso) {
+		return Alta{}, errors.BadRequest("La fecha de salida no puede ser anterior a la fecha de ingreso.")
+	}
+	alta.IdCliente, err = s.repo.GetIdClienteHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return Alta{}, err
+	}
+
+	examenes, err := s.repo.GetExamenesHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return Alta{}, err
+	}
+	pendientes := []CargoHospitalizacion{}
+	for _, examen := range examenes {
+		if examen.Estado != estadoExamenFinalizado {
+			pendientes = append(pendientes, examen)
+		}
+	}
+	if len(pendientes) > 0 && !req.IgnorarExamenesPendientes {
+		res := errors.Conflict("Existen exámenes pendientes de resultados.")
+		res.Details = pendientes
+		return Alta{}, res
+	}
+	servicios, err := s.repo.GetServiciosHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return Alta{}, err
+	}
+	productos, err := s.repo.GetProductosHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return Alta{}, err
+	}
+
+	tarifaDiaria := s.tarifaDiaria
+	if req.TarifaDiaria != nil {
+		tarifaDiaria = *req.TarifaDiaria
+	}
+	alta.Dias = diasEstancia(hospitalizacion.FechaIngreso, alta.FechaSalida)
+	alta.Detalles = detallesAlta(hospitalizacion, alta.Dias, tarifaDiaria, servicios, examenes, productos)
+	return alta, nil
+}
+
+// diasEstancia counts every started day of stay, at least one.
+func diasEstancia(ingreso time.Time, salida time.Time) int {
+	dias := int(math.Ceil(salida.Sub(ingreso).Hours() / 24))
+	if dias < 1 {
+		return 1
+	}
+	return dias
+}
+
+// detallesAlta itemises the hospitalizacion. Productos for internal use are part of the servicio that
+// used them and are not charged; productos used by measure are charged by the fraction of the unit.
+// Each examen is billed at what it added to the hospitalizacion. Whatever the valor of the hospitalizacion
+// holds beyond its servicios and examenes is charged as other charges, so the factura never bills less
+// than the hospitalizacion accumulated.
+func detallesAlta(hospitalizacion entity.Hospitalizacion, dias int, tarifaDiaria money.Money, servicios []CargoHospitalizacion, examenes []CargoHospitalizacion, productos []ProductoUsado) []detalle_factura.CreateDetalleFacturaRequest {
+	detalles := []detalle_factura.CreateDetalleFacturaRequest{}
+	cargo := func(tabla string, idReferencia int, descripcion string, cantidad float32, precio money.Money, gravaIva bool) {
+		d := descripcion
+		detalles = append(detalles, detalle_factura.CreateDetalleFacturaRequest{
+			Tabla:          tabla,
+			IdReferencia:   idReferencia,
+			Descripcion:    &d,
+			Cantidad:       cantidad,
+			PrecioUnitario: precio,
+			GravaIva:       gravaIva,
+		})
+	}
+
+	if tarifaDiaria > 0 {
+		cargo(detalle_factura.TablaHospitalizacion, hospitalizacion.IdHospitalizacion, "Estancia de hospitalización (días)", float32(dias), tarifaDiaria, false)
+	}
+	acumulado := money.Zero
+	for _, servicio := range servicios {
+		valor := money.FromFloat(float64(servicio.Valor))
+		acumulado += valor
+		cargo(detalle_factura.TablaServicioHospitalizacion, servicio.IdReferencia, servicio.Descripcion, 1, valor, false)
+	}
+	for _, examen := range examenes {
+		if examen.Valor <= 0 {
+			continue
+		}
+		valor := money.FromFloat(float64(examen.Valor))
+		acumulado += valor
+		cargo(detalle_factura.TablaExamen, examen.IdReferencia, "Examen: "+examen.Descripcion, 1, valor, false)
+	}
+	for _, producto := range productos {
+		if producto.UsoInterno.Valid && producto.UsoInterno.Bool {
+			continue
+		}
+		precio := money.FromFloat(float64(producto.PrecioVenta))
+		if producto.Tabla != "lote" && producto.Contenido != nil && *producto.Contenido > 0 {
+			precio = money.FromFloat(float64(producto.PrecioVenta) / float64(*producto.Contenido))
+		}
+		cargo(detalle_factura.TablaUsoServicio, producto.IdDetalleUsoServicio, producto.Descripcion, producto.Cantidad, precio, producto.Iva.Valid && producto.Iva.Bool)
+	}
+	if otros := money.FromFloat(float64(hospitalizacion.Valor)) - acumulado; otros > 0 {
+		cargo(detalle_factura.TablaHospitalizacion, hospitalizacion.IdHospitalizacion, "Otros cargos de hospitalización", 1, otros, false)
+	}
+	return detalles
+}
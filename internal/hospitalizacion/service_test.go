@@ -0,0 +1,122 @@
+package hospitalizacion
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"veterinaria-server/internal/detalle_factura"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_PrepararAlta(t *testing.T) {
+	logger, _ := log.NewForTest()
+	contenido := float32(10)
+	repo := &mockRepository{
+		hospitalizacion: entity.Hospitalizacion{
+			IdHospitalizacion:     4,
+			FechaIngreso:          time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+			Valor:                 80,
+			EstadoHospitalizacion: EstadoActiva,
+		},
+		servicios: []CargoHospitalizacion{{IdReferencia: 11, Descripcion: "Fluidoterapia", Valor: 30}},
+		examenes:  []CargoHospitalizacion{{IdReferencia: 21, Descripcion: "Hemograma", Valor: 25, Estado: "PENDIENTE"}, {IdReferencia: 22, Descripcion: "Urianálisis", Estado: "PENDIENTE"}},
+		productos: []ProductoUsado{
+			{IdDetalleUsoServicio: 31, Tabla: "stock_individual", Cantidad: 2, Descripcion: "Suero", PrecioVenta: 5, Contenido: &contenido},
+			{IdDetalleUsoServicio: 32, Tabla: "lote", Cantidad: 1, Descripcion: "Antibiótico", PrecioVenta: 12, Iva: sql.NullBool{Bool: true, Valid: true}},
+			{IdDetalleUsoServicio: 33, Tabla: "lote", Cantidad: 1, Descripcion: "Jeringa", PrecioVenta: 1, UsoInterno: sql.NullBool{Bool: true, Valid: true}},
+		},
+	}
+	s := NewServiceConTarifa(repo, logger, 1500)
+	salida := time.Date(2026, 10, 3, 10, 0, 0, 0, time.UTC)
+
+	// examenes pendientes
+	_, err := s.PrepararAlta(context.Background(), 4, AltaRequest{FechaSalida: &salida})
+	assert.NotNil(t, err)
+
+	alta, err := s.PrepararAlta(context.Background(), 4, AltaRequest{FechaSalida: &salida, IgnorarExamenesPendientes: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 9, alta.IdCliente)
+	assert.Equal(t, 3, alta.Dias)
+	if assert.Len(t, alta.Detalles, 6) {
+		assert.Equal(t, detalle_factura.TablaHospitalizacion, alta.Detalles[0].Tabla)
+		assert.Equal(t, float32(3), alta.Detalles[0].Cantidad)
+		assert.Equal(t, money.Money(1500), alta.Detalles[0].PrecioUnitario)
+		assert.Equal(t, detalle_factura.TablaServicioHospitalizacion, alta.Detalles[1].Tabla)
+		assert.Equal(t, detalle_factura.TablaExamen, alta.Detalles[2].Tabla)
+		assert.Equal(t, money.Money(50), alta.Detalles[3].PrecioUnitario)
+		assert.Equal(t, 32, alta.Detalles[4].IdReferencia)
+		assert.True(t, alta.Detalles[4].GravaIva)
+		assert.Equal(t, "Otros cargos de hospitalización", *alta.Detalles[5].Descripcion)
+		assert.Equal(t, money.Money(2500), alta.Detalles[5].PrecioUnitario)
+	}
+
+	// hospitalizacion finalizada
+	repo.hospitalizacion.EstadoHospitalizacion = EstadoFinalizado
+	_, err = s.PrepararAlta(context.Background(), 4, AltaRequest{FechaSalida: &salida, IgnorarExamenesPendientes: true})
+	assert.NotNil(t, err)
+}
+
+type mockRepository struct {
+	hospitalizacion entity.Hospitalizacion
+	servicios       []CargoHospitalizacion
+	examenes        []CargoHospitalizacion
+	productos       []ProductoUsado
+}
+
+func (m *mockRepository) GetHospitalizacionPorId(ctx context.Context, idHospitalizacion int) (entity.Hospitalizacion, error) {
+	if m.hospitalizacion.IdHospitalizacion != idHospitalizacion {
+		return entity.Hospitalizacion{}, sql.ErrNoRows
+	}
+	return m.hospitalizacion, nil
+}
+
+func (m *mockRepository) BloquearHospitalizacion(ctx context.Context, idHospitalizacion int) (entity.Hospitalizacion, error) {
+	return m.GetHospitalizacionPorId(ctx, idHospitalizacion)
+}
+
+func (m *mockRepository) GetHospitalizaciones(ctx context.Context, query pagination.Query) ([]entity.Hospitalizacion, *pagination.Pages, error) {
+	items := []entity.Hospitalizacion{m.hospitalizacion}
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m *mockRepository) GetHospitalizacionesActivas(ctx context.Context) ([]HospitalizacionesActivas, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) GetHospitalizacionesFinalizadas(ctx context.Context) ([]HospitalizacionesActivas, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) CrearHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error) {
+	m.hospitalizacion = hospitalizacion
+	return hospitalizacion, nil
+}
+
+func (m *mockRepository) ActualizarHospitalizacion(ctx context.Context, hospitalizacion entity.Hospitalizacion) (entity.Hospitalizacion, error) {
+	m.hospitalizacion = hospitalizacion
+	return hospitalizacion, nil
+}
+
+func (m *mockRepository) GetIdClienteHospitalizacion(ctx context.Context, idHospitalizacion int) (int, error) {
+	return 9, nil
+}
+
+func (m *mockRepository) GetServiciosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error) {
+	return m.servicios, nil
+}
+
+func (m *mockRepository) GetExamenesHospitalizacion(ctx context.Context, idHospitalizacion int) ([]CargoHospitalizacion, error) {
+	return m.examenes, nil
+}
+
+func (m *mockRepository) GetProductosHospitalizacion(ctx context.Context, idHospitalizacion int) ([]ProductoUsado, error) {
+	return m.productos, nil
+}
@@ -0,0 +1,34 @@
+-- Tres hospitalizaciones de la misma consulta: una activa, una finalizada y una activa con un examen
+-- pendiente de resultados.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO especies (id_especie, descripcion) VALUES
+    (1, 'Canino');
+
+INSERT INTO generos (id_genero, descripcion) VALUES
+    (1, 'Macho');
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001');
+
+INSERT INTO mascotas (id_mascota, id_especie, id_cliente, id_genero, nombre) VALUES
+    (1, 1, 1, 1, 'Firulais');
+
+INSERT INTO consulta (id_consulta, id_mascota, id_usuario, fecha, valor, motivo) VALUES
+    (1, 1, 100, '2026-10-01 07:00:00', 20.00, 'Vómitos persistentes');
+
+INSERT INTO tarifas_iva (id_tarifa_iva, porcentaje, fecha_inicio) VALUES
+    (1, 15, '2024-04-01');
+
+INSERT INTO hospitalizacion (id_hospitalizacion, id_consulta, motivo, fecha_ingreso, fecha_salida, valor, abono, autoriza_examenes, estado_hospitalizacion) VALUES
+    (1, 1, 'Deshidratación', '2026-10-01 08:00:00', NULL, 0, 0, 1, 'ACTIVA'),
+    (2, 1, 'Observación', '2026-09-01 08:00:00', '2026-09-02 08:00:00', 0, 0, 0, 'FINALIZADO'),
+    (3, 1, 'Gastroenteritis', '2026-10-01 08:00:00', NULL, 12.50, 0, 1, 'ACTIVA');
+
+INSERT INTO tipos_examenes (id_tipo_examen, id_especie, titulo, descripcion, muestra, valor) VALUES
+    (1, 1, 'Hemograma', 'Conteo sanguíneo completo', 'Sangre', 15.00);
+
+INSERT INTO examenes_mascota (id_examen_mascota, id_usuario, id_mascota, id_tipo_examen, fecha_solicitud, estado, id_referencia, tabla, valor) VALUES
+    (1, 100, 1, 1, '2026-10-01 09:00:00', 'PENDIENTE', 3, 'Hospitalizacion', 12.50);
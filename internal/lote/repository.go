@@ -2,9 +2,11 @@ package lote
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access lotes from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetLotePorId returns the lote with the specified lote ID.
 	GetLotePorId(ctx context.Context, idLote int) (entity.Lote, error)
 	// GetLotes returns the list lotes.
-	GetLotes(ctx context.Context) ([]entity.Lote, error)
+	GetLotes(ctx context.Context, query pagination.Query) ([]entity.Lote, *pagination.Pages, error)
 	CrearLote(ctx context.Context, lote entity.Lote) (entity.Lote, error)
 	ActualizarLote(ctx context.Context, lote entity.Lote) (entity.Lote, error)
 }
@@ -28,23 +30,37 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposLotes are the fields the list of lotes can be filtered, searched and sorted by.
+var camposLotes = pagination.Fields{
+	Filters: map[string]string{"id_proveedor_producto": "id_proveedor_producto"},
+	Ranges:  map[string]string{"fecha_caducidad": "fecha_caducidad"},
+	Search:  []string{"descripcion", "codigo_barra"},
+	Sort: map[string]string{
+		"id_lote":               "id_lote",
+		"id_proveedor_producto": "id_proveedor_producto",
+		"fecha_caducidad":       "fecha_caducidad",
+		"stock":                 "stock",
+		"descripcion":           "descripcion",
+		"codigo_barra":          "codigo_barra",
+	},
+	DefaultSort: []string{"id_lote asc"},
+}
+
 // Get reads the list lotes from the database.
-func (r repository) GetLotes(ctx context.Context) ([]entity.Lote, error) {
-	var lotes []entity.Lote
+func (r repository) GetLotes(ctx context.Context, query pagination.Query) ([]entity.Lote, *pagination.Pages, error) {
+	var lotes []entity.Lote = []entity.Lote{}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&lotes)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("lote"), camposLotes, &lotes)
 	if err != nil {
-		return lotes, err
+		return nil, nil, err
 	}
-	return lotes, err
+	return lotes, pages, nil
 }
 
 // Create saves a new Lote record in the database.
 // It returns the ID of the newly inserted lote record.
 func (r repository) CrearLote(ctx context.Context, lote entity.Lote) (entity.Lote, error) {
-	err := r.db.With(ctx).Model(&lote).Insert()
+	err := auditoria.Insertar(ctx, r.db, &lote)
 	if err != nil {
 		return entity.Lote{}, err
 	}
@@ -56,9 +72,9 @@ func (r repository) CrearLote(ctx context.Context, lote entity.Lote) (entity.Lot
 func (r repository) ActualizarLote(ctx context.Context, lote entity.Lote) (entity.Lote, error) {
 	var err error
 	if lote.IdLote != 0 {
-		err = r.db.With(ctx).Model(&lote).Update()
+		err = auditoria.Actualizar(ctx, r.db, &lote, "IdProveedorProducto", "FechaCaducidad", "Descripcion", "CodigoBarra")
 	} else {
-		err = r.db.With(ctx).Model(&lote).Insert()
+		err = auditoria.Insertar(ctx, r.db, &lote)
 	}
 	if err != nil {
 		return entity.Lote{}, err
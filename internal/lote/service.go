@@ -4,14 +4,16 @@ import (
 	"context"
 	"time"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/movimiento_inventario"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for lotes.
 type Service interface {
-	GetLotes(ctx context.Context) ([]Lote, error)
+	GetLotes(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetLotePorId(ctx context.Context, idLote int) (Lote, error)
 	CrearLote(ctx context.Context, input CreateLoteRequest) (Lote, error)
 	ActualizarLote(ctx context.Context, input UpdateLoteRequest) (Lote, error)
@@ -23,18 +25,19 @@ type Lote struct {
 }
 
 type service struct {
-	repo   Repository
-	logger log.Logger
+	repo        Repository
+	movimientos movimiento_inventario.Service
+	logger      log.Logger
 }
 
 // NewService creates a new lotes service.
-func NewService(repo Repository, logger log.Logger) Service {
-	return service{repo, logger}
+func NewService(repo Repository, movimientos movimiento_inventario.Service, logger log.Logger) Service {
+	return service{repo, movimientos, logger}
 }
 
 // Get returns the list lotes.
-func (s service) GetLotes(ctx context.Context) ([]Lote, error) {
-	lotes, err := s.repo.GetLotes(ctx)
+func (s service) GetLotes(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	lotes, pages, err := s.repo.GetLotes(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +45,8 @@ func (s service) GetLotes(ctx context.Context) ([]Lote, error) {
 	for _, item := range lotes {
 		result = append(result, Lote{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateLoteRequest represents an lote creation request.
@@ -68,6 +72,7 @@ func (m UpdateLoteRequest) ValidateUpdate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.Descripcion, validation.Required, validation.Length(0, 1000)),
 		validation.Field(&m.IdProveedorProducto, validation.Required),
+		validation.Field(&m.Stock, validation.Min(0)),
 	)
 }
 
@@ -76,6 +81,7 @@ func (m CreateLoteRequest) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.Descripcion, validation.Required, validation.Length(0, 1000)),
 		validation.Field(&m.IdProveedorProducto, validation.Required),
+		validation.Field(&m.Stock, validation.Min(0)),
 	)
 }
 
@@ -102,18 +108,29 @@ func (s service) ActualizarLote(ctx context.Context, req UpdateLoteRequest) (Lot
 	if err := req.ValidateUpdate(); err != nil {
 		return Lote{}, err
 	}
-	loteG, err := s.repo.ActualizarLote(ctx, entity.Lote{
+	if req.IdLote == 0 {
+		return s.CrearLote(ctx, CreateLoteRequest{
+			IdProveedorProducto: req.IdProveedorProducto,
+			FechaCaducidad:      req.FechaCaducidad,
+			Stock:               req.Stock,
+			Descripcion:         req.Descripcion,
+			CodigoBarra:         req.CodigoBarra,
+		})
+	}
+	//El stock solo cambia con un ajuste registrado en el ledger de inventario
+	if _, err := s.movimientos.AjustarStock(ctx, movimiento_inventario.TablaLote, req.IdLote, float32(req.Stock)); err != nil {
+		return Lote{}, err
+	}
+	if _, err := s.repo.ActualizarLote(ctx, entity.Lote{
 		IdLote:              req.IdLote,
 		IdProveedorProducto: req.IdProveedorProducto,
 		FechaCaducidad:      req.FechaCaducidad,
-		Stock:               req.Stock,
 		Descripcion:         req.Descripcion,
 		CodigoBarra:         req.CodigoBarra,
-	})
-	if err != nil {
+	}); err != nil {
 		return Lote{}, err
 	}
-	return Lote{loteG}, nil
+	return s.GetLotePorId(ctx, req.IdLote)
 }
 
 // GetLotePorId returns the lote with the specified the lote ID.
@@ -2,6 +2,7 @@ package mascotas
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
@@ -46,7 +47,7 @@ func (r repository) GetMascotasPorCliente(ctx context.Context, idCliente int) ([
 // Create saves a new Mascota record in the database.
 // It returns the ID of the newly inserted mascota record.
 func (r repository) CrearMascota(ctx context.Context, mascota entity.Mascota) (entity.Mascota, error) {
-	err := r.db.With(ctx).Model(&mascota).Insert()
+	err := auditoria.Insertar(ctx, r.db, &mascota)
 	if err != nil {
 		return entity.Mascota{}, err
 	}
@@ -58,9 +59,9 @@ func (r repository) CrearMascota(ctx context.Context, mascota entity.Mascota) (e
 func (r repository) ActualizarMascotaPorGrupo(ctx context.Context, mascota entity.Mascota) (entity.Mascota, error) {
 	var err error
 	if mascota.IdMascota != 0 {
-		err = r.db.With(ctx).Model(&mascota).Update()
+		err = auditoria.Actualizar(ctx, r.db, &mascota)
 	} else {
-		err = r.db.With(ctx).Model(&mascota).Insert()
+		err = auditoria.Insertar(ctx, r.db, &mascota)
 	}
 	if err != nil {
 		return entity.Mascota{}, err
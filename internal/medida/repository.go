@@ -2,9 +2,11 @@ package medida
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access medidas from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetMedidaPorId returns the medida with the specified medida ID.
 	GetMedidaPorId(ctx context.Context, idMedida int) (entity.Medida, error)
 	// GetMedidas returns the list medidas.
-	GetMedidas(ctx context.Context) ([]entity.Medida, error)
+	GetMedidas(ctx context.Context, query pagination.Query) ([]entity.Medida, *pagination.Pages, error)
 	CrearMedida(ctx context.Context, medida entity.Medida) (entity.Medida, error)
 	ActualizarMedida(ctx context.Context, medida entity.Medida) (entity.Medida, error)
 }
@@ -28,23 +30,28 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposMedidas are the fields the list of medidas can be filtered, searched and sorted by.
+var camposMedidas = pagination.Fields{
+	Search:      []string{"descripcion"},
+	Sort:        map[string]string{"id_medida": "id_medida", "descripcion": "descripcion"},
+	DefaultSort: []string{"id_medida asc"},
+}
+
 // Get reads the list medidas from the database.
-func (r repository) GetMedidas(ctx context.Context) ([]entity.Medida, error) {
-	var medidas []entity.Medida
+func (r repository) GetMedidas(ctx context.Context, query pagination.Query) ([]entity.Medida, *pagination.Pages, error) {
+	var medidas []entity.Medida = []entity.Medida{}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&medidas)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("medida"), camposMedidas, &medidas)
 	if err != nil {
-		return medidas, err
+		return nil, nil, err
 	}
-	return medidas, err
+	return medidas, pages, nil
 }
 
 // Create saves a new Medida record in the database.
 // It returns the ID of the newly inserted medida record.
 func (r repository) CrearMedida(ctx context.Context, medida entity.Medida) (entity.Medida, error) {
-	err := r.db.With(ctx).Model(&medida).Insert()
+	err := auditoria.Insertar(ctx, r.db, &medida)
 	if err != nil {
 		return entity.Medida{}, err
 	}
@@ -56,9 +63,9 @@ func (r repository) CrearMedida(ctx context.Context, medida entity.Medida) (enti
 func (r repository) ActualizarMedida(ctx context.Context, medida entity.Medida) (entity.Medida, error) {
 	var err error
 	if medida.IdMedida != 0 {
-		err = r.db.With(ctx).Model(&medida).Update()
+		err = auditoria.Actualizar(ctx, r.db, &medida)
 	} else {
-		err = r.db.With(ctx).Model(&medida).Insert()
+		err = auditoria.Insertar(ctx, r.db, &medida)
 	}
 	if err != nil {
 		return entity.Medida{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for medidas.
 type Service interface {
-	GetMedidas(ctx context.Context) ([]Medida, error)
+	GetMedidas(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetMedidaPorId(ctx context.Context, idMedida int) (Medida, error)
 	CrearMedida(ctx context.Context, input CreateMedidaRequest) (Medida, error)
 	ActualizarMedida(ctx context.Context, input UpdateMedidaRequest) (Medida, error)
@@ -32,8 +33,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list medidas.
-func (s service) GetMedidas(ctx context.Context) ([]Medida, error) {
-	medidas, err := s.repo.GetMedidas(ctx)
+func (s service) GetMedidas(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	medidas, pages, err := s.repo.GetMedidas(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +42,8 @@ func (s service) GetMedidas(ctx context.Context) ([]Medida, error) {
 	for _, item := range medidas {
 		result = append(result, Medida{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateMedidaRequest represents an medida creation request.
@@ -0,0 +1,70 @@
+package movimiento_inventario
+
+import (
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/movimientosInventario/conciliacion", res.getConciliacion)
+	r.Get("/movimientosInventario/porLote/<idLote>", res.getMovimientosPorLote)
+	r.Get("/movimientosInventario/porProducto/<idProducto>", res.getMovimientosPorProducto)
+	r.Post("/movimientosInventario", res.registrarMovimiento)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getConciliacion(c *routing.Context) error {
+	conciliacion, err := r.service.GetConciliacion(c.Request.Context())
+	if err != nil {
+		return err
+	}
+	return c.Write(conciliacion)
+}
+
+func (r resource) getMovimientosPorLote(c *routing.Context) error {
+	idLote, _ := strconv.Atoi(c.Param("idLote"))
+	movimientos, err := r.service.GetMovimientosPorLote(c.Request.Context(), idLote)
+	if err != nil {
+		return err
+	}
+	return c.Write(movimientos)
+}
+
+func (r resource) getMovimientosPorProducto(c *routing.Context) error {
+	idProducto, _ := strconv.Atoi(c.Param("idProducto"))
+	movimientos, err := r.service.GetMovimientosPorProducto(c.Request.Context(), idProducto)
+	if err != nil {
+		return err
+	}
+	return c.Write(movimientos)
+}
+
+// registrarMovimiento registers a manual movimiento (ajuste or caducidad).
+// Ventas, usos en servicio and entradas por compra are registered by their own flows.
+func (r resource) registrarMovimiento(c *routing.Context) error {
+	var input CreateMovimientoInventarioRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	if input.Tipo != TipoAjuste && input.Tipo != TipoCaducidad {
+		return errors.BadRequest("Solo se pueden registrar manualmente movimientos de ajuste o caducidad.")
+	}
+	movimientos, err := r.service.RegistrarMovimiento(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(movimientos, http.StatusCreated)
+}
@@ -0,0 +1,196 @@
+package movimiento_inventario
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access movimientosInventario from the data source.
+type Repository interface {
+	// GetMovimientosPorLote returns the movimientos of the specified lote, oldest first.
+	GetMovimientosPorLote(ctx context.Context, idLote int) ([]entity.MovimientoInventario, error)
+	// GetMovimientosPorProducto returns the movimientos of every lote of the specified producto, oldest first.
+	GetMovimientosPorProducto(ctx context.Context, idProducto int) ([]entity.MovimientoInventario, error)
+	CrearMovimiento(ctx context.Context, movimiento entity.MovimientoInventario) (entity.MovimientoInventario, error)
+	// BloquearLote reads the lote and locks its row until the current transaction ends.
+	BloquearLote(ctx context.Context, idLote int) (entity.Lote, error)
+	// BloquearStockIndividual reads the stockIndividual and locks its row until the current transaction ends.
+	BloquearStockIndividual(ctx context.Context, idStockIndividual int) (entity.StockIndividual, error)
+	// AplicarLote adds cantidad to the stock of the lote in a single statement.
+	AplicarLote(ctx context.Context, idLote int, cantidad int) error
+	// AplicarStockIndividual adds cantidad to the cantidad of the stockIndividual in a single statement.
+	AplicarStockIndividual(ctx context.Context, idStockIndividual int, cantidad float32) error
+	GetProductoPorId(ctx context.Context, idProducto int) (entity.Producto, error)
+	// BloquearLotesDisponibles locks and returns the lotes of the producto that are not expired and have stock,
+	// ordered first-expired-first-out. Lotes without fecha_caducidad come last.
+	BloquearLotesDisponibles(ctx context.Context, idProducto int) ([]entity.Lote, error)
+	// BloquearStocksIndividualAbiertos locks and returns the opened units of the lote that still have cantidad.
+	BloquearStocksIndividualAbiertos(ctx context.Context, idLote int) ([]entity.StockIndividual, error)
+	CrearStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error)
+	// GetConciliacion compares the stock stored in lote and stock_individual with the stock recomputed from the ledger.
+	// The stock previous to the ledger is in it as an ajuste with origen saldo_inicial.
+	GetConciliacion(ctx context.Context) ([]Conciliacion, error)
+}
+
+// repository persists movimientosInventario in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new movimientoInventario repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+func (r repository) GetMovimientosPorLote(ctx context.Context, idLote int) ([]entity.MovimientoInventario, error) {
+	var movimientos []entity.MovimientoInventario = []entity.MovimientoInventario{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_lote": idLote}).
+		OrderBy("id_movimiento_inventario asc").
+		All(&movimientos)
+	if err != nil {
+		return []entity.MovimientoInventario{}, err
+	}
+	return movimientos, err
+}
+
+func (r repository) GetMovimientosPorProducto(ctx context.Context, idProducto int) ([]entity.MovimientoInventario, error) {
+	var movimientos []entity.MovimientoInventario = []entity.MovimientoInventario{}
+	err := r.db.With(ctx).
+		Select("m.*").
+		From("movimientos_inventario m").
+		InnerJoin("lote l", dbx.NewExp("l.id_lote = m.id_lote")).
+		InnerJoin("proveedor_producto pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
+		Where(dbx.HashExp{"pp.id_producto": idProducto}).
+		OrderBy("m.id_movimiento_inventario asc").
+		All(&movimientos)
+	if err != nil {
+		return []entity.MovimientoInventario{}, err
+	}
+	return movimientos, err
+}
+
+// CrearMovimiento appends a new MovimientoInventario record to the ledger.
+// It returns the movimiento with the ID of the newly inserted record.
+func (r repository) CrearMovimiento(ctx context.Context, movimiento entity.MovimientoInventario) (entity.MovimientoInventario, error) {
+	err := auditoria.Insertar(ctx, r.db, &movimiento)
+	if err != nil {
+		return entity.MovimientoInventario{}, err
+	}
+	return movimiento, nil
+}
+
+func (r repository) BloquearLote(ctx context.Context, idLote int) (entity.Lote, error) {
+	var lote entity.Lote
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM lote WHERE id_lote = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idLote}).
+		One(&lote)
+	return lote, err
+}
+
+func (r repository) BloquearStockIndividual(ctx context.Context, idStockIndividual int) (entity.StockIndividual, error) {
+	var stockIndividual entity.StockIndividual
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM stock_individual WHERE id_stock_individual = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idStockIndividual}).
+		One(&stockIndividual)
+	return stockIndividual, err
+}
+
+func (r repository) AplicarLote(ctx context.Context, idLote int, cantidad int) error {
+	lote := entity.Lote{IdLote: idLote}
+	return auditoria.ActualizarCon(ctx, r.db, &lote, func() error {
+		_, err := r.db.With(ctx).
+			Update("lote", dbx.Params{"stock": dbx.NewExp("stock + {:cantidad}", dbx.Params{"cantidad": cantidad})}, dbx.HashExp{"id_lote": idLote}).
+			Execute()
+		return err
+	})
+}
+
+func (r repository) AplicarStockIndividual(ctx context.Context, idStockIndividual int, cantidad float32) error {
+	stockIndividual := entity.StockIndividual{IdStockIndividual: idStockIndividual}
+	return auditoria.ActualizarCon(ctx, r.db, &stockIndividual, func() error {
+		_, err := r.db.With(ctx).
+			Update("stock_individual", dbx.Params{"cantidad": dbx.NewExp("cantidad + {:cantidad}", dbx.Params{"cantidad": cantidad})}, dbx.HashExp{"id_stock_individual": idStockIndividual}).
+			Execute()
+		return err
+	})
+}
+
+func (r repository) GetConciliacion(ctx context.Context) ([]Conciliacion, error) {
+	var lotes []Conciliacion = []Conciliacion{}
+	var stocksIndividual []Conciliacion = []Conciliacion{}
+
+	err := r.db.With(ctx).
+		Select("'lote' as tabla", "l.id_lote as id_referencia", "l.id_lote", "l.descripcion", "l.stock as stock_actual", "coalesce(sum(m.cantidad), 0) as stock_calculado").
+		From("lote l").
+		LeftJoin("movimientos_inventario m", dbx.NewExp("m.id_lote = l.id_lote and m.id_stock_individual is null")).
+		GroupBy("l.id_lote", "l.descripcion", "l.stock").
+		Having(dbx.NewExp("l.stock <> coalesce(sum(m.cantidad), 0)")).
+		All(&lotes)
+	if err != nil {
+		return []Conciliacion{}, err
+	}
+
+	err = r.db.With(ctx).
+		Select("'stock_individual' as tabla", "si.id_stock_individual as id_referencia", "si.id_lote", "si.descripcion", "si.cantidad as stock_actual", "coalesce(sum(m.cantidad), 0) as stock_calculado").
+		From("stock_individual si").
+		LeftJoin("movimientos_inventario m", dbx.NewExp("m.id_stock_individual = si.id_stock_individual")).
+		GroupBy("si.id_stock_individual", "si.id_lote", "si.descripcion", "si.cantidad").
+		Having(dbx.NewExp("abs(si.cantidad - coalesce(sum(m.cantidad), 0)) > 0.001")).
+		All(&stocksIndividual)
+	if err != nil {
+		return []Conciliacion{}, err
+	}
+	return append(lotes, stocksIndividual...), err
+}
+
+func (r repository) GetProductoPorId(ctx context.Context, idProducto int) (entity.Producto, error) {
+	var producto entity.Producto
+	err := r.db.With(ctx).Select().Model(idProducto, &producto)
+	return producto, err
+}
+
+func (r repository) BloquearLotesDisponibles(ctx context.Context, idProducto int) ([]entity.Lote, error) {
+	var lotes []entity.Lote = []entity.Lote{}
+	err := r.db.With(ctx).
+		NewQuery("SELECT l.* FROM lote l " +
+			"INNER JOIN proveedor_producto pp ON pp.id_proveedor_producto = l.id_proveedor_producto " +
+			"WHERE pp.id_producto = {:id} AND (DATE(now()) <= l.fecha_caducidad OR l.fecha_caducidad IS NULL) AND l.stock > 0 " +
+			"ORDER BY l.fecha_caducidad IS NULL, l.fecha_caducidad ASC, l.id_lote ASC FOR UPDATE").
+		Bind(dbx.Params{"id": idProducto}).
+		All(&lotes)
+	if err != nil {
+		return []entity.Lote{}, err
+	}
+	return lotes, err
+}
+
+func (r repository) BloquearStocksIndividualAbiertos(ctx context.Context, idLote int) ([]entity.StockIndividual, error) {
+	var stocksIndividual []entity.StockIndividual = []entity.StockIndividual{}
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM stock_individual WHERE id_lote = {:id} AND cantidad > 0 ORDER BY id_stock_individual ASC FOR UPDATE").
+		Bind(dbx.Params{"id": idLote}).
+		All(&stocksIndividual)
+	if err != nil {
+		return []entity.StockIndividual{}, err
+	}
+	return stocksIndividual, err
+}
+
+// CrearStockIndividual saves a newly opened unit of a lote.
+func (r repository) CrearStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error) {
+	err := auditoria.Insertar(ctx, r.db, &stockIndividual)
+	if err != nil {
+		return entity.StockIndividual{}, err
+	}
+	return stockIndividual, nil
+}
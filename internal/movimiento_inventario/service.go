@@ -0,0 +1,507 @@
+package movimiento_inventario
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Tipos de movimiento registrados en el ledger de inventario.
+const (
+	TipoEntradaCompra = "entrada_compra"
+	TipoVenta         = "venta"
+	TipoUsoServicio   = "uso_servicio"
+	TipoAjuste        = "ajuste"
+	TipoCaducidad     = "caducidad"
+	TipoApertura      = "apertura"
+	TipoDevolucion    = "devolucion"
+)
+
+// Tablas sobre las que se aplica un movimiento.
+const (
+	TablaLote            = "lote"
+	TablaStockIndividual = "stock_individual"
+	TablaProducto        = "producto"
+)
+
+// tolerancia absorbs float32 rounding when comparing fractional quantities of stockIndividual.
+const tolerancia = 0.0001
+
+// Service encapsulates usecase logic for movimientosInventario.
+type Service interface {
+	GetMovimientosPorLote(ctx context.Context, idLote int) ([]MovimientoInventario, error)
+	GetMovimientosPorProducto(ctx context.Context, idProducto int) ([]MovimientoInventario, error)
+	GetConciliacion(ctx context.Context) ([]Conciliacion, error)
+	// RegistrarMovimiento locks the affected row, applies the signed cantidad to its stock and
+	// appends the movimiento to the ledger. When a stockIndividual is used up, the lote that
+	// contains it is decremented by one unit with an additional movimiento, and incremented
+	// back when a used up stockIndividual is restored.
+	RegistrarMovimiento(ctx context.Context, input CreateMovimientoInventarioRequest) ([]MovimientoInventario, error)
+	// RegistrarEntrada records the initial stock of a lote or stockIndividual that has just been
+	// created with that stock, without modifying the row again.
+	RegistrarEntrada(ctx context.Context, input CreateMovimientoInventarioRequest) (MovimientoInventario, error)
+	// AjustarStock locks the lote or stockIndividual and records the ajuste that brings its stock to
+	// the given value. No movimiento is recorded when the stock does not change.
+	AjustarStock(ctx context.Context, tabla string, idReferencia int, stock float32) ([]MovimientoInventario, error)
+	// VerificarDisponibilidad locks the requested lotes and stocksIndividual and checks that all of
+	// them have enough stock. It returns a 409 error listing every item that is short otherwise.
+	VerificarDisponibilidad(ctx context.Context, items []ItemStock) error
+	// AsignarFEFO allocates cantidad of the producto across its lotes first-expired-first-out, skipping
+	// expired lotes. For productos por medida the opened units are used first and new units are opened
+	// as needed. asignado are the items already allocated by the same request, whose quantities are not
+	// available again. It returns one ItemStock per lote or stockIndividual used, without applying any movimiento.
+	AsignarFEFO(ctx context.Context, idProducto int, cantidad float32, asignado []ItemStock) ([]ItemStock, error)
+}
+
+// MovimientoInventario represents the data about a movimientoInventario.
+type MovimientoInventario struct {
+	entity.MovimientoInventario
+}
+
+// Conciliacion represents the difference between the stored stock and the stock recomputed from the ledger.
+type Conciliacion struct {
+	Tabla          string  `json:"tabla"`
+	IdReferencia   int     `json:"id_referencia"`
+	IdLote         int     `json:"id_lote"`
+	Descripcion    string  `json:"descripcion"`
+	StockActual    float32 `json:"stock_actual"`
+	StockCalculado float32 `json:"stock_calculado"`
+	Diferencia     float32 `json:"diferencia"`
+}
+
+// ItemStock represents a quantity of a lote or stockIndividual requested by a sale or a service.
+type ItemStock struct {
+	Tabla        string  `json:"tabla"`
+	IdReferencia int     `json:"id_referencia"`
+	Cantidad     float32 `json:"cantidad"`
+}
+
+// StockInsuficiente describes an item whose requested quantity exceeds the available stock.
+type StockInsuficiente struct {
+	Tabla        string  `json:"tabla"`
+	IdReferencia int     `json:"id_referencia"`
+	Descripcion  string  `json:"descripcion"`
+	Solicitado   float32 `json:"solicitado"`
+	Disponible   float32 `json:"disponible"`
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new movimientosInventario service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+// CreateMovimientoInventarioRequest represents a movimientoInventario creation request.
+// Cantidad is signed: positive values add stock and negative values remove it.
+type CreateMovimientoInventarioRequest struct {
+	Tabla        string  `json:"tabla"`
+	IdReferencia int     `json:"id_referencia"`
+	Tipo         string  `json:"tipo"`
+	Cantidad     float32 `json:"cantidad"`
+	Origen       *string `json:"origen"`
+	IdOrigen     *int    `json:"id_origen"`
+	Observacion  *string `json:"observacion"`
+}
+
+// Validate validates the CreateMovimientoInventarioRequest fields.
+func (m CreateMovimientoInventarioRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Tabla, validation.Required, validation.In(TablaLote, TablaStockIndividual)),
+		validation.Field(&m.IdReferencia, validation.Required),
+		validation.Field(&m.Tipo, validation.Required, validation.In(TipoEntradaCompra, TipoVenta, TipoUsoServicio, TipoAjuste, TipoCaducidad, TipoApertura, TipoDevolucion)),
+		validation.Field(&m.Cantidad, validation.Required, validation.When(m.Tabla == TablaLote, validation.By(cantidadEntera))),
+	)
+}
+
+// cantidadEntera checks that a cantidad applied to a lote is a whole number of units.
+func cantidadEntera(value interface{}) error {
+	cantidad, _ := value.(float32)
+	if cantidad != float32(math.Trunc(float64(cantidad))) {
+		return validation.NewError("validation_cantidad_entera", "must be a whole number of units")
+	}
+	return nil
+}
+
+// TablaDetalle maps the Tabla stored in the detail rows (detalles_factura, detalle_usos_servicio...)
+// to the table a movimiento is applied to.
+func TablaDetalle(tabla string) string {
+	if tabla == TablaLote {
+		return TablaLote
+	}
+	return TablaStockIndividual
+}
+
+func (s service) GetMovimientosPorLote(ctx context.Context, idLote int) ([]MovimientoInventario, error) {
+	movimientos, err := s.repo.GetMovimientosPorLote(ctx, idLote)
+	if err != nil {
+		return nil, err
+	}
+	result := []MovimientoInventario{}
+	for _, item := range movimientos {
+		result = append(result, MovimientoInventario{item})
+	}
+	return result, nil
+}
+
+func (s service) GetMovimientosPorProducto(ctx context.Context, idProducto int) ([]MovimientoInventario, error) {
+	movimientos, err := s.repo.GetMovimientosPorProducto(ctx, idProducto)
+	if err != nil {
+		return nil, err
+	}
+	result := []MovimientoInventario{}
+	for _, item := range movimientos {
+		result = append(result, MovimientoInventario{item})
+	}
+	return result, nil
+}
+
+func (s service) GetConciliacion(ctx context.Context) ([]Conciliacion, error) {
+	conciliacion, err := s.repo.GetConciliacion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range conciliacion {
+		conciliacion[i].Diferencia = conciliacion[i].StockActual - conciliacion[i].StockCalculado
+	}
+	return conciliacion, nil
+}
+
+func (s service) RegistrarMovimiento(ctx context.Context, req CreateMovimientoInventarioRequest) ([]MovimientoInventario, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Tabla == TablaLote {
+		movimientoG, err := s.aplicarLote(ctx, req, req.IdReferencia, int(req.Cantidad))
+		if err != nil {
+			return nil, err
+		}
+		return []MovimientoInventario{movimientoG}, nil
+	}
+
+	stockIndividual, err := s.repo.BloquearStockIndividual(ctx, req.IdReferencia)
+	if err != nil {
+		return nil, err
+	}
+	if stockIndividual.Cantidad+req.Cantidad < -tolerancia {
+		return nil, stockInsuficiente([]StockInsuficiente{{
+			Tabla:        TablaStockIndividual,
+			IdReferencia: stockIndividual.IdStockIndividual,
+			Descripcion:  stockIndividual.Descripcion,
+			Solicitado:   -req.Cantidad,
+			Disponible:   stockIndividual.Cantidad,
+		}})
+	}
+	if err := s.repo.AplicarStockIndividual(ctx, stockIndividual.IdStockIndividual, req.Cantidad); err != nil {
+		return nil, err
+	}
+	idStockIndividual := stockIndividual.IdStockIndividual
+	movimientoG, err := s.repo.CrearMovimiento(ctx, s.nuevoMovimiento(ctx, req, stockIndividual.IdLote, &idStockIndividual, req.Cantidad, stockIndividual.Cantidad+req.Cantidad))
+	if err != nil {
+		return nil, err
+	}
+	movimientos := []MovimientoInventario{{movimientoG}}
+
+	//La unidad abierta se agotó, se descuenta del lote
+	if stockIndividual.Cantidad > tolerancia && stockIndividual.Cantidad+req.Cantidad <= tolerancia {
+		observacion := "Unidad agotada: " + stockIndividual.Descripcion
+		reqLote := req
+		reqLote.Observacion = &observacion
+		movimientoLoteG, err := s.aplicarLote(ctx, reqLote, stockIndividual.IdLote, -1)
+		if err != nil {
+			return nil, err
+		}
+		movimientos = append(movimientos, movimientoLoteG)
+	}
+
+	//Una unidad agotada vuelve a tener contenido, se restituye al lote
+	if stockIndividual.Cantidad <= tolerancia && stockIndividual.Cantidad+req.Cantidad > tolerancia {
+		observacion := "Unidad restituida: " + stockIndividual.Descripcion
+		reqLote := req
+		reqLote.Observacion = &observacion
+		movimientoLoteG, err := s.aplicarLote(ctx, reqLote, stockIndividual.IdLote, 1)
+		if err != nil {
+			return nil, err
+		}
+		movimientos = append(movimientos, movimientoLoteG)
+	}
+	return movimientos, nil
+}
+
+func (s service) RegistrarEntrada(ctx context.Context, req CreateMovimientoInventarioRequest) (MovimientoInventario, error) {
+	if err := req.Validate(); err != nil {
+		return MovimientoInventario{}, err
+	}
+	var movimiento entity.MovimientoInventario
+	if req.Tabla == TablaLote {
+		movimiento = s.nuevoMovimiento(ctx, req, req.IdReferencia, nil, req.Cantidad, req.Cantidad)
+	} else {
+		stockIndividual, err := s.repo.BloquearStockIndividual(ctx, req.IdReferencia)
+		if err != nil {
+			return MovimientoInventario{}, err
+		}
+		idStockIndividual := stockIndividual.IdStockIndividual
+		movimiento = s.nuevoMovimiento(ctx, req, stockIndividual.IdLote, &idStockIndividual, req.Cantidad, stockIndividual.Cantidad)
+	}
+	movimientoG, err := s.repo.CrearMovimiento(ctx, movimiento)
+	if err != nil {
+		return MovimientoInventario{}, err
+	}
+	return MovimientoInventario{movimientoG}, nil
+}
+
+func (s service) AjustarStock(ctx context.Context, tabla string, idReferencia int, stock float32) ([]MovimientoInventario, error) {
+	var actual float32
+	if tabla == TablaLote {
+		lote, err := s.repo.BloquearLote(ctx, idReferencia)
+		if err != nil {
+			return nil, err
+		}
+		actual = float32(lote.Stock)
+	} else {
+		stockIndividual, err := s.repo.BloquearStockIndividual(ctx, idReferencia)
+		if err != nil {
+			return nil, err
+		}
+		actual = stockIndividual.Cantidad
+	}
+	diferencia := stock - actual
+	if diferencia > -tolerancia && diferencia < tolerancia {
+		return []MovimientoInventario{}, nil
+	}
+	observacion := "Ajuste manual de stock"
+	return s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{
+		Tabla:        tabla,
+		IdReferencia: idReferencia,
+		Tipo:         TipoAjuste,
+		Cantidad:     diferencia,
+		Observacion:  &observacion,
+	})
+}
+
+// aplicarLote locks the lote, applies cantidad to its stock and records the movimiento.
+func (s service) aplicarLote(ctx context.Context, req CreateMovimientoInventarioRequest, idLote int, cantidad int) (MovimientoInventario, error) {
+	lote, err := s.repo.BloquearLote(ctx, idLote)
+	if err != nil {
+		return MovimientoInventario{}, err
+	}
+	if lote.Stock+cantidad < 0 {
+		return MovimientoInventario{}, stockInsuficiente([]StockInsuficiente{{
+			Tabla:        TablaLote,
+			IdReferencia: lote.IdLote,
+			Descripcion:  lote.Descripcion,
+			Solicitado:   float32(-cantidad),
+			Disponible:   float32(lote.Stock),
+		}})
+	}
+	if err := s.repo.AplicarLote(ctx, lote.IdLote, cantidad); err != nil {
+		return MovimientoInventario{}, err
+	}
+	movimientoG, err := s.repo.CrearMovimiento(ctx, s.nuevoMovimiento(ctx, req, lote.IdLote, nil, float32(cantidad), float32(lote.Stock+cantidad)))
+	if err != nil {
+		return MovimientoInventario{}, err
+	}
+	return MovimientoInventario{movimientoG}, nil
+}
+
+// claveStock identifies a lote or stockIndividual.
+type claveStock struct {
+	tabla string
+	id    int
+}
+
+func (s service) VerificarDisponibilidad(ctx context.Context, items []ItemStock) error {
+	//Agrupa las cantidades solicitadas de un mismo lote o stock individual
+	solicitado := map[claveStock]float32{}
+	orden := []claveStock{}
+	for _, item := range items {
+		k := claveStock{TablaDetalle(item.Tabla), item.IdReferencia}
+		if _, ok := solicitado[k]; !ok {
+			orden = append(orden, k)
+		}
+		solicitado[k] += item.Cantidad
+	}
+
+	faltantes := []StockInsuficiente{}
+	for _, k := range orden {
+		var disponible float32
+		var descripcion string
+		if k.tabla == TablaLote {
+			lote, err := s.repo.BloquearLote(ctx, k.id)
+			if err != nil {
+				return err
+			}
+			disponible, descripcion = float32(lote.Stock), lote.Descripcion
+		} else {
+			stockIndividual, err := s.repo.BloquearStockIndividual(ctx, k.id)
+			if err != nil {
+				return err
+			}
+			disponible, descripcion = stockIndividual.Cantidad, stockIndividual.Descripcion
+		}
+		if solicitado[k] > disponible+tolerancia {
+			faltantes = append(faltantes, StockInsuficiente{
+				Tabla:        k.tabla,
+				IdReferencia: k.id,
+				Descripcion:  descripcion,
+				Solicitado:   solicitado[k],
+				Disponible:   disponible,
+			})
+		}
+	}
+	if len(faltantes) > 0 {
+		return stockInsuficiente(faltantes)
+	}
+	return nil
+}
+
+func (s service) AsignarFEFO(ctx context.Context, idProducto int, cantidad float32, asignado []ItemStock) ([]ItemStock, error) {
+	if cantidad <= 0 {
+		return nil, errors.BadRequest("La cantidad a asignar debe ser mayor a cero.")
+	}
+	//Lo asignado antes en la misma solicitud ya no esta disponible
+	tomado := map[claveStock]float32{}
+	for _, item := range asignado {
+		tomado[claveStock{TablaDetalle(item.Tabla), item.IdReferencia}] += item.Cantidad
+	}
+	producto, err := s.repo.GetProductoPorId(ctx, idProducto)
+	if err != nil {
+		return nil, err
+	}
+	lotes, err := s.repo.BloquearLotesDisponibles(ctx, idProducto)
+	if err != nil {
+		return nil, err
+	}
+
+	asignaciones := []ItemStock{}
+	pendiente := cantidad
+	var disponible float32
+	if !producto.PorMedida.Bool {
+		if cantidad != float32(int(cantidad)) {
+			return nil, errors.BadRequest("El producto " + producto.Descripcion + " solo se puede asignar en unidades enteras.")
+		}
+		for _, lote := range lotes {
+			if pendiente <= 0 {
+				break
+			}
+			stock := float32(lote.Stock) - tomado[claveStock{TablaLote, lote.IdLote}]
+			if stock <= 0 {
+				continue
+			}
+			tomar := pendiente
+			if tomar > stock {
+				tomar = stock
+			}
+			disponible += stock
+			asignaciones = append(asignaciones, ItemStock{Tabla: TablaLote, IdReferencia: lote.IdLote, Cantidad: tomar})
+			pendiente -= tomar
+		}
+	} else {
+		if producto.Contenido == nil || *producto.Contenido <= 0 {
+			return nil, errors.BadRequest("El producto " + producto.Descripcion + " se vende por medida pero no tiene contenido por unidad.")
+		}
+		contenido := *producto.Contenido
+		for _, lote := range lotes {
+			if pendiente <= tolerancia {
+				break
+			}
+			abiertos, err := s.repo.BloquearStocksIndividualAbiertos(ctx, lote.IdLote)
+			if err != nil {
+				return nil, err
+			}
+			//Primero se consumen las unidades ya abiertas
+			for _, stockIndividual := range abiertos {
+				if pendiente <= tolerancia {
+					break
+				}
+				cantidadAbierta := stockIndividual.Cantidad - tomado[claveStock{TablaStockIndividual, stockIndividual.IdStockIndividual}]
+				if cantidadAbierta <= tolerancia {
+					continue
+				}
+				tomar := pendiente
+				if tomar > cantidadAbierta {
+					tomar = cantidadAbierta
+				}
+				disponible += cantidadAbierta
+				asignaciones = append(asignaciones, ItemStock{Tabla: TablaStockIndividual, IdReferencia: stockIndividual.IdStockIndividual, Cantidad: tomar})
+				pendiente -= tomar
+			}
+			//Luego se abren unidades nuevas del lote
+			cerradas := lote.Stock - len(abiertos)
+			for i := 0; i < cerradas && pendiente > tolerancia; i++ {
+				stockIndividualG, err := s.repo.CrearStockIndividual(ctx, entity.StockIndividual{
+					IdLote:          lote.IdLote,
+					Descripcion:     lote.Descripcion + " - unidad " + strconv.Itoa(len(abiertos)+i+1),
+					Cantidad:        contenido,
+					CantidadInicial: contenido,
+				})
+				if err != nil {
+					return nil, err
+				}
+				if _, err := s.RegistrarEntrada(ctx, CreateMovimientoInventarioRequest{
+					Tabla:        TablaStockIndividual,
+					IdReferencia: stockIndividualG.IdStockIndividual,
+					Tipo:         TipoApertura,
+					Cantidad:     contenido,
+				}); err != nil {
+					return nil, err
+				}
+				tomar := pendiente
+				if tomar > contenido {
+					tomar = contenido
+				}
+				disponible += contenido
+				asignaciones = append(asignaciones, ItemStock{Tabla: TablaStockIndividual, IdReferencia: stockIndividualG.IdStockIndividual, Cantidad: tomar})
+				pendiente -= tomar
+			}
+		}
+	}
+
+	if pendiente > tolerancia {
+		return nil, stockInsuficiente([]StockInsuficiente{{
+			Tabla:        TablaProducto,
+			IdReferencia: producto.IdProducto,
+			Descripcion:  producto.Descripcion,
+			Solicitado:   cantidad,
+			Disponible:   disponible,
+		}})
+	}
+	return asignaciones, nil
+}
+
+// stockInsuficiente builds the 409 error returned when the stock cannot cover a request.
+func stockInsuficiente(faltantes []StockInsuficiente) error {
+	res := errors.Conflict("No existe stock suficiente para los siguientes productos.")
+	res.Details = faltantes
+	return res
+}
+
+func (s service) nuevoMovimiento(ctx context.Context, req CreateMovimientoInventarioRequest, idLote int, idStockIndividual *int, cantidad float32, stockResultante float32) entity.MovimientoInventario {
+	var idUsuario *int
+	if identity := auth.CurrentUser(ctx); identity != nil {
+		id := identity.GetIdUsuario()
+		idUsuario = &id
+	}
+	return entity.MovimientoInventario{
+		IdLote:            idLote,
+		IdStockIndividual: idStockIndividual,
+		Tipo:              req.Tipo,
+		Cantidad:          cantidad,
+		StockResultante:   stockResultante,
+		Origen:            req.Origen,
+		IdOrigen:          req.IdOrigen,
+		IdUsuario:         idUsuario,
+		Fecha:             time.Now(),
+		Observacion:       req.Observacion,
+	}
+}
@@ -0,0 +1,331 @@
+package movimiento_inventario
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMovimientoInventarioRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     CreateMovimientoInventarioRequest
+		wantError bool
+	}{
+		{"success", CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoVenta, Cantidad: -1}, false},
+		{"invalid tabla", CreateMovimientoInventarioRequest{Tabla: "producto", IdReferencia: 1, Tipo: TipoVenta, Cantidad: -1}, true},
+		{"invalid tipo", CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: "regalo", Cantidad: -1}, true},
+		{"zero cantidad", CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoAjuste}, true},
+		{"fractional lote", CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoVenta, Cantidad: -1.5}, true},
+		{"fractional stock individual", CreateMovimientoInventarioRequest{Tabla: TablaStockIndividual, IdReferencia: 1, Tipo: TipoVenta, Cantidad: -1.5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			assert.Equal(t, tt.wantError, err != nil)
+		})
+	}
+}
+
+func Test_service_RegistrarMovimiento(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		lotes:            map[int]entity.Lote{1: {IdLote: 1, Stock: 5}},
+		stocksIndividual: map[int]entity.StockIndividual{7: {IdStockIndividual: 7, IdLote: 1, Cantidad: 2.5}},
+	}
+	s := NewService(repo, logger)
+	ctx := context.Background()
+
+	// venta de un lote
+	movimientos, err := s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoVenta, Cantidad: -2})
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 1)
+	assert.Equal(t, float32(3), movimientos[0].StockResultante)
+	assert.Equal(t, 3, repo.lotes[1].Stock)
+
+	// uso parcial de una unidad abierta
+	movimientos, err = s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaStockIndividual, IdReferencia: 7, Tipo: TipoUsoServicio, Cantidad: -1})
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 1)
+	assert.Equal(t, float32(1.5), repo.stocksIndividual[7].Cantidad)
+	assert.Equal(t, 3, repo.lotes[1].Stock)
+
+	// la unidad se agota y se descuenta del lote
+	movimientos, err = s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaStockIndividual, IdReferencia: 7, Tipo: TipoUsoServicio, Cantidad: -1.5})
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 2)
+	assert.Nil(t, movimientos[1].IdStockIndividual)
+	assert.Equal(t, float32(-1), movimientos[1].Cantidad)
+	assert.Equal(t, 2, repo.lotes[1].Stock)
+	assert.Len(t, repo.movimientos, 4)
+
+	// la devolución restituye la unidad agotada al lote
+	movimientos, err = s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaStockIndividual, IdReferencia: 7, Tipo: TipoDevolucion, Cantidad: 1.5})
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 2)
+	assert.Equal(t, float32(1), movimientos[1].Cantidad)
+	assert.Equal(t, float32(1.5), repo.stocksIndividual[7].Cantidad)
+	assert.Equal(t, 3, repo.lotes[1].Stock)
+	assert.Len(t, repo.movimientos, 6)
+
+	// validation error
+	_, err = s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoVenta})
+	assert.NotNil(t, err)
+	assert.Len(t, repo.movimientos, 6)
+}
+
+func Test_service_AjustarStock(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		lotes:            map[int]entity.Lote{1: {IdLote: 1, Stock: 5}},
+		stocksIndividual: map[int]entity.StockIndividual{7: {IdStockIndividual: 7, IdLote: 1, Cantidad: 2.5}},
+	}
+	s := NewService(repo, logger)
+	ctx := context.Background()
+
+	// el lote baja de 5 a 3
+	movimientos, err := s.AjustarStock(ctx, TablaLote, 1, 3)
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 1)
+	assert.Equal(t, TipoAjuste, movimientos[0].Tipo)
+	assert.Equal(t, float32(-2), movimientos[0].Cantidad)
+	assert.Equal(t, 3, repo.lotes[1].Stock)
+
+	// sin cambios no se registra movimiento
+	movimientos, err = s.AjustarStock(ctx, TablaLote, 1, 3)
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 0)
+
+	// la unidad abierta sube de 2.5 a 4
+	movimientos, err = s.AjustarStock(ctx, TablaStockIndividual, 7, 4)
+	assert.Nil(t, err)
+	assert.Len(t, movimientos, 1)
+	assert.Equal(t, float32(4), repo.stocksIndividual[7].Cantidad)
+	assert.Len(t, repo.movimientos, 2)
+}
+
+func Test_service_VerificarDisponibilidad(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		lotes:            map[int]entity.Lote{1: {IdLote: 1, Descripcion: "L1", Stock: 3}},
+		stocksIndividual: map[int]entity.StockIndividual{7: {IdStockIndividual: 7, IdLote: 1, Descripcion: "Frasco", Cantidad: 0.5}},
+	}
+	s := NewService(repo, logger)
+	ctx := context.Background()
+
+	assert.Nil(t, s.VerificarDisponibilidad(ctx, []ItemStock{{Tabla: "lote", IdReferencia: 1, Cantidad: 3}}))
+
+	// the same lote requested twice is aggregated
+	err := s.VerificarDisponibilidad(ctx, []ItemStock{
+		{Tabla: "lote", IdReferencia: 1, Cantidad: 2},
+		{Tabla: "lote", IdReferencia: 1, Cantidad: 2},
+		{Tabla: "stock_individual", IdReferencia: 7, Cantidad: 0.75},
+	})
+	if assert.IsType(t, errors.ErrorResponse{}, err) {
+		res := err.(errors.ErrorResponse)
+		assert.Equal(t, http.StatusConflict, res.StatusCode())
+		assert.Equal(t, []StockInsuficiente{
+			{Tabla: TablaLote, IdReferencia: 1, Descripcion: "L1", Solicitado: 4, Disponible: 3},
+			{Tabla: TablaStockIndividual, IdReferencia: 7, Descripcion: "Frasco", Solicitado: 0.75, Disponible: 0.5},
+		}, res.Details)
+	}
+
+	// movimientos that would drive stock negative are refused
+	_, err = s.RegistrarMovimiento(ctx, CreateMovimientoInventarioRequest{Tabla: TablaLote, IdReferencia: 1, Tipo: TipoVenta, Cantidad: -4})
+	assert.IsType(t, errors.ErrorResponse{}, err)
+	assert.Equal(t, 3, repo.lotes[1].Stock)
+	assert.Empty(t, repo.movimientos)
+}
+
+func Test_service_GetConciliacion(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{conciliacion: []Conciliacion{{Tabla: TablaLote, IdReferencia: 1, StockActual: 4, StockCalculado: 6}}}
+	s := NewService(repo, logger)
+	conciliacion, err := s.GetConciliacion(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, float32(-2), conciliacion[0].Diferencia)
+}
+
+func Test_service_AsignarFEFO(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ctx := context.Background()
+
+	// lotes are consumed in the order returned by the repository (first-expired-first-out)
+	repo := &mockRepository{
+		productos:        map[int]entity.Producto{1: {IdProducto: 1, Descripcion: "Collar"}},
+		lotes:            map[int]entity.Lote{10: {IdLote: 10, Stock: 2}, 11: {IdLote: 11, Stock: 5}},
+		lotesPorProducto: map[int][]int{1: {10, 11}},
+	}
+	s := NewService(repo, logger)
+	asignaciones, err := s.AsignarFEFO(ctx, 1, 3, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemStock{
+		{Tabla: TablaLote, IdReferencia: 10, Cantidad: 2},
+		{Tabla: TablaLote, IdReferencia: 11, Cantidad: 1},
+	}, asignaciones)
+
+	_, err = s.AsignarFEFO(ctx, 1, 1.5, nil)
+	assert.NotNil(t, err)
+
+	_, err = s.AsignarFEFO(ctx, 1, 8, nil)
+	if assert.IsType(t, errors.ErrorResponse{}, err) {
+		assert.Equal(t, []StockInsuficiente{{Tabla: TablaProducto, IdReferencia: 1, Descripcion: "Collar", Solicitado: 8, Disponible: 7}}, err.(errors.ErrorResponse).Details)
+	}
+
+	// productos por medida use the opened unit first and then open new ones
+	contenido := float32(100)
+	repo = &mockRepository{
+		productos:        map[int]entity.Producto{2: {IdProducto: 2, Descripcion: "Antibiotico", PorMedida: sql.NullBool{Bool: true, Valid: true}, Contenido: &contenido}},
+		lotes:            map[int]entity.Lote{20: {IdLote: 20, Descripcion: "L20", Stock: 3}},
+		lotesPorProducto: map[int][]int{2: {20}},
+		stocksIndividual: map[int]entity.StockIndividual{1: {IdStockIndividual: 1, IdLote: 20, Cantidad: 30, CantidadInicial: 100}},
+	}
+	s = NewService(repo, logger)
+	asignaciones, err = s.AsignarFEFO(ctx, 2, 150, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemStock{
+		{Tabla: TablaStockIndividual, IdReferencia: 1, Cantidad: 30},
+		{Tabla: TablaStockIndividual, IdReferencia: 2, Cantidad: 100},
+		{Tabla: TablaStockIndividual, IdReferencia: 3, Cantidad: 20},
+	}, asignaciones)
+	assert.Equal(t, "L20 - unidad 2", repo.stocksIndividual[2].Descripcion)
+	assert.Equal(t, float32(100), repo.stocksIndividual[3].Cantidad)
+	assert.Len(t, repo.movimientos, 2)
+	assert.Equal(t, TipoApertura, repo.movimientos[0].Tipo)
+
+	// only 3 units exist in the lote: 1 opened + 2 new ones
+	_, err = s.AsignarFEFO(ctx, 2, 500, nil)
+	assert.IsType(t, errors.ErrorResponse{}, err)
+}
+
+func Test_service_AsignarFEFO_asignado(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ctx := context.Background()
+
+	// a second line of the same producto continues where the first one stopped
+	repo := &mockRepository{
+		productos:        map[int]entity.Producto{1: {IdProducto: 1, Descripcion: "Collar"}},
+		lotes:            map[int]entity.Lote{10: {IdLote: 10, Stock: 2}, 11: {IdLote: 11, Stock: 5}},
+		lotesPorProducto: map[int][]int{1: {10, 11}},
+	}
+	s := NewService(repo, logger)
+	asignado, err := s.AsignarFEFO(ctx, 1, 1, nil)
+	assert.Nil(t, err)
+	asignaciones, err := s.AsignarFEFO(ctx, 1, 3, asignado)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemStock{
+		{Tabla: TablaLote, IdReferencia: 10, Cantidad: 1},
+		{Tabla: TablaLote, IdReferencia: 11, Cantidad: 2},
+	}, asignaciones)
+
+	asignado = append(asignado, asignaciones...)
+	_, err = s.AsignarFEFO(ctx, 1, 4, asignado)
+	if assert.IsType(t, errors.ErrorResponse{}, err) {
+		assert.Equal(t, []StockInsuficiente{{Tabla: TablaProducto, IdReferencia: 1, Descripcion: "Collar", Solicitado: 4, Disponible: 3}}, err.(errors.ErrorResponse).Details)
+	}
+
+	// productos por medida reuse the unit opened by the first line instead of opening it again
+	contenido := float32(100)
+	repo = &mockRepository{
+		productos:        map[int]entity.Producto{2: {IdProducto: 2, Descripcion: "Antibiotico", PorMedida: sql.NullBool{Bool: true, Valid: true}, Contenido: &contenido}},
+		lotes:            map[int]entity.Lote{20: {IdLote: 20, Descripcion: "L20", Stock: 2}},
+		lotesPorProducto: map[int][]int{2: {20}},
+		stocksIndividual: map[int]entity.StockIndividual{},
+	}
+	s = NewService(repo, logger)
+	asignado, err = s.AsignarFEFO(ctx, 2, 60, nil)
+	assert.Nil(t, err)
+	asignaciones, err = s.AsignarFEFO(ctx, 2, 60, asignado)
+	assert.Nil(t, err)
+	assert.Equal(t, []ItemStock{
+		{Tabla: TablaStockIndividual, IdReferencia: 1, Cantidad: 40},
+		{Tabla: TablaStockIndividual, IdReferencia: 2, Cantidad: 20},
+	}, asignaciones)
+	assert.Len(t, repo.stocksIndividual, 2)
+}
+
+type mockRepository struct {
+	productos        map[int]entity.Producto
+	lotes            map[int]entity.Lote
+	lotesPorProducto map[int][]int
+	stocksIndividual map[int]entity.StockIndividual
+	movimientos      []entity.MovimientoInventario
+	conciliacion     []Conciliacion
+}
+
+func (m *mockRepository) GetProductoPorId(ctx context.Context, idProducto int) (entity.Producto, error) {
+	if producto, ok := m.productos[idProducto]; ok {
+		return producto, nil
+	}
+	return entity.Producto{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) BloquearLotesDisponibles(ctx context.Context, idProducto int) ([]entity.Lote, error) {
+	lotes := []entity.Lote{}
+	for _, id := range m.lotesPorProducto[idProducto] {
+		lotes = append(lotes, m.lotes[id])
+	}
+	return lotes, nil
+}
+
+func (m *mockRepository) BloquearStocksIndividualAbiertos(ctx context.Context, idLote int) ([]entity.StockIndividual, error) {
+	stocksIndividual := []entity.StockIndividual{}
+	for id := 1; id <= len(m.stocksIndividual); id++ {
+		if si := m.stocksIndividual[id]; si.IdLote == idLote && si.Cantidad > 0 {
+			stocksIndividual = append(stocksIndividual, si)
+		}
+	}
+	return stocksIndividual, nil
+}
+
+func (m *mockRepository) CrearStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error) {
+	stockIndividual.IdStockIndividual = len(m.stocksIndividual) + 1
+	m.stocksIndividual[stockIndividual.IdStockIndividual] = stockIndividual
+	return stockIndividual, nil
+}
+
+func (m *mockRepository) GetMovimientosPorLote(ctx context.Context, idLote int) ([]entity.MovimientoInventario, error) {
+	return m.movimientos, nil
+}
+
+func (m *mockRepository) GetMovimientosPorProducto(ctx context.Context, idProducto int) ([]entity.MovimientoInventario, error) {
+	return m.movimientos, nil
+}
+
+func (m *mockRepository) CrearMovimiento(ctx context.Context, movimiento entity.MovimientoInventario) (entity.MovimientoInventario, error) {
+	movimiento.IdMovimientoInventario = len(m.movimientos) + 1
+	m.movimientos = append(m.movimientos, movimiento)
+	return movimiento, nil
+}
+
+func (m *mockRepository) BloquearLote(ctx context.Context, idLote int) (entity.Lote, error) {
+	return m.lotes[idLote], nil
+}
+
+func (m *mockRepository) BloquearStockIndividual(ctx context.Context, idStockIndividual int) (entity.StockIndividual, error) {
+	return m.stocksIndividual[idStockIndividual], nil
+}
+
+func (m *mockRepository) AplicarLote(ctx context.Context, idLote int, cantidad int) error {
+	lote := m.lotes[idLote]
+	lote.Stock += cantidad
+	m.lotes[idLote] = lote
+	return nil
+}
+
+func (m *mockRepository) AplicarStockIndividual(ctx context.Context, idStockIndividual int, cantidad float32) error {
+	stockIndividual := m.stocksIndividual[idStockIndividual]
+	stockIndividual.Cantidad += cantidad
+	m.stocksIndividual[idStockIndividual] = stockIndividual
+	return nil
+}
+
+func (m *mockRepository) GetConciliacion(ctx context.Context) ([]Conciliacion, error) {
+	return m.conciliacion, nil
+}
@@ -0,0 +1,52 @@
+package nota_credito
+
+import (
+	"strconv"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+// Notas de crédito are created by voiding a factura (POST /facturas/<idFactura>/anular).
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/notasCredito", res.getNotasCredito)
+	r.Get("/notasCredito/<idNotaCredito>", res.getNotaCreditoPorId)
+	r.Get("/notasCredito/porFactura/<idFactura>", res.getNotaCreditoPorFactura)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getNotasCredito(c *routing.Context) error {
+	pages, err := r.service.GetNotasCredito(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getNotaCreditoPorId(c *routing.Context) error {
+	idNotaCredito, _ := strconv.Atoi(c.Param("idNotaCredito"))
+	notaCredito, err := r.service.GetNotaCreditoPorId(c.Request.Context(), idNotaCredito)
+	if err != nil {
+		return err
+	}
+	return c.Write(notaCredito)
+}
+
+func (r resource) getNotaCreditoPorFactura(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	notaCredito, err := r.service.GetNotaCreditoPorFactura(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	return c.Write(notaCredito)
+}
@@ -0,0 +1,86 @@
+package nota_credito
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access notasCredito from the data source.
+type Repository interface {
+	// GetNotaCreditoPorId returns the notaCredito with the specified notaCredito ID.
+	GetNotaCreditoPorId(ctx context.Context, idNotaCredito int) (entity.NotaCredito, error)
+	// GetNotasCredito returns the list notasCredito.
+	GetNotasCredito(ctx context.Context, query pagination.Query) ([]entity.NotaCredito, *pagination.Pages, error)
+	GetNotaCreditoPorFactura(ctx context.Context, idFactura int) (entity.NotaCredito, error)
+	CrearNotaCredito(ctx context.Context, notaCredito entity.NotaCredito) (entity.NotaCredito, error)
+}
+
+// repository persists notasCredito in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new notaCredito repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposNotasCredito are the fields the list of notas credito can be filtered, searched and sorted by.
+var camposNotasCredito = pagination.Fields{
+	Filters: map[string]string{"id_factura": "id_factura", "id_usuario": "id_usuario"},
+	Ranges:  map[string]string{"fecha": "fecha"},
+	Search:  []string{"motivo"},
+	Sort: map[string]string{
+		"id_nota_credito": "id_nota_credito",
+		"id_factura":      "id_factura",
+		"id_usuario":      "id_usuario",
+		"fecha":           "fecha",
+		"motivo":          "motivo",
+		"valor":           "valor",
+	},
+	DefaultSort: []string{"fecha desc"},
+}
+
+// Get reads the list notasCredito from the database.
+func (r repository) GetNotasCredito(ctx context.Context, query pagination.Query) ([]entity.NotaCredito, *pagination.Pages, error) {
+	var notasCredito []entity.NotaCredito = []entity.NotaCredito{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("notas_credito"), camposNotasCredito, &notasCredito)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notasCredito, pages, nil
+}
+
+// Create saves a new NotaCredito record in the database.
+// It returns the ID of the newly inserted notaCredito record.
+func (r repository) CrearNotaCredito(ctx context.Context, notaCredito entity.NotaCredito) (entity.NotaCredito, error) {
+	err := auditoria.Insertar(ctx, r.db, &notaCredito)
+	if err != nil {
+		return entity.NotaCredito{}, err
+	}
+	return notaCredito, nil
+}
+
+// Get reads the notaCredito with the specified ID from the database.
+func (r repository) GetNotaCreditoPorId(ctx context.Context, idNotaCredito int) (entity.NotaCredito, error) {
+	var notaCredito entity.NotaCredito
+	err := r.db.With(ctx).Select().Model(idNotaCredito, &notaCredito)
+	return notaCredito, err
+}
+
+func (r repository) GetNotaCreditoPorFactura(ctx context.Context, idFactura int) (entity.NotaCredito, error) {
+	var notaCredito entity.NotaCredito
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_factura": idFactura}).
+		One(&notaCredito)
+	return notaCredito, err
+}
@@ -0,0 +1,108 @@
+package nota_credito
+
+import (
+	"context"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Service encapsulates usecase logic for notasCredito.
+type Service interface {
+	GetNotasCredito(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	GetNotaCreditoPorId(ctx context.Context, idNotaCredito int) (NotaCredito, error)
+	GetNotaCreditoPorFactura(ctx context.Context, idFactura int) (NotaCredito, error)
+	CrearNotaCredito(ctx context.Context, input CreateNotaCreditoRequest) (NotaCredito, error)
+}
+
+// NotaCredito represents the data about a notaCredito.
+type NotaCredito struct {
+	entity.NotaCredito
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new notasCredito service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+// Get returns the list notasCredito.
+func (s service) GetNotasCredito(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	notasCredito, pages, err := s.repo.GetNotasCredito(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []NotaCredito{}
+	for _, item := range notasCredito {
+		result = append(result, NotaCredito{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+// CreateNotaCreditoRequest represents a notaCredito creation request.
+type CreateNotaCreditoRequest struct {
+	IdFactura int         `json:"id_factura"`
+	IdUsuario int         `json:"id_usuario"`
+	Motivo    string      `json:"motivo"`
+	Valor     money.Money `json:"valor"`
+}
+
+// Validate validates the CreateNotaCreditoRequest fields.
+func (m CreateNotaCreditoRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.IdFactura, validation.Required),
+		validation.Field(&m.IdUsuario, validation.Required),
+		validation.Field(&m.Motivo, validation.Required, validation.Length(0, 1000)),
+	)
+}
+
+// CrearNotaCredito creates a new notaCredito.
+func (s service) CrearNotaCredito(ctx context.Context, req CreateNotaCreditoRequest) (NotaCredito, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return NotaCredito{}, err
+	}
+	req.IdUsuario = idUsuario
+	if err := req.Validate(); err != nil {
+		return NotaCredito{}, err
+	}
+	notaCreditoG, err := s.repo.CrearNotaCredito(ctx, entity.NotaCredito{
+		IdFactura: req.IdFactura,
+		IdUsuario: req.IdUsuario,
+		Fecha:     time.Now(),
+		Motivo:    req.Motivo,
+		Valor:     req.Valor,
+	})
+	if err != nil {
+		return NotaCredito{}, err
+	}
+	return NotaCredito{notaCreditoG}, nil
+}
+
+// GetNotaCreditoPorId returns the notaCredito with the specified the notaCredito ID.
+func (s service) GetNotaCreditoPorId(ctx context.Context, idNotaCredito int) (NotaCredito, error) {
+	notaCredito, err := s.repo.GetNotaCreditoPorId(ctx, idNotaCredito)
+	if err != nil {
+		return NotaCredito{}, err
+	}
+	return NotaCredito{notaCredito}, nil
+}
+
+// GetNotaCreditoPorFactura returns the notaCredito that voided the specified factura.
+func (s service) GetNotaCreditoPorFactura(ctx context.Context, idFactura int) (NotaCredito, error) {
+	notaCredito, err := s.repo.GetNotaCreditoPorFactura(ctx, idFactura)
+	if err != nil {
+		return NotaCredito{}, err
+	}
+	return NotaCredito{notaCredito}, nil
+}
@@ -0,0 +1,102 @@
+package notificaciones
+
+import (
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/notificaciones", res.getNotificaciones)
+	r.Get("/notificaciones/porCliente/<idCliente>", res.getNotificacionesPorCliente)
+	r.Post("/notificaciones/<idNotificacion>/reenviar", res.reenviar)
+	r.Get("/notificaciones/plantillas", res.getPlantillas)
+	r.Put("/notificaciones/plantillas", res.actualizarPlantilla)
+	r.Get("/notificaciones/destinatarios", res.getDestinatarios)
+	r.Put("/notificaciones/destinatarios", res.actualizarDestinatario)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getNotificaciones(c *routing.Context) error {
+	pages, err := r.service.GetNotificaciones(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getNotificacionesPorCliente(c *routing.Context) error {
+	idCliente, _ := strconv.Atoi(c.Param("idCliente"))
+	pages, err := r.service.GetNotificacionesPorCliente(c.Request.Context(), idCliente, pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) reenviar(c *routing.Context) error {
+	idNotificacion, _ := strconv.Atoi(c.Param("idNotificacion"))
+	notificacion, err := r.service.Reenviar(c.Request.Context(), idNotificacion)
+	if err != nil {
+		return err
+	}
+	return c.Write(notificacion)
+}
+
+func (r resource) getPlantillas(c *routing.Context) error {
+	pages, err := r.service.GetPlantillas(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) actualizarPlantilla(c *routing.Context) error {
+	var input UpdatePlantillaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	plantilla, err := r.service.ActualizarPlantilla(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(plantilla, http.StatusCreated)
+}
+
+func (r resource) getDestinatarios(c *routing.Context) error {
+	pages, err := r.service.GetDestinatarios(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) actualizarDestinatario(c *routing.Context) error {
+	var input UpdateDestinatarioRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	destinatario, err := r.service.ActualizarDestinatario(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(destinatario, http.StatusCreated)
+}
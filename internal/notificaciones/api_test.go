@@ -0,0 +1,75 @@
+package notificaciones
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_historialYReenvio(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	whatsApp := &Fake{}
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), map[string]Notifier{CanalWhatsApp: whatsApp}, logger), auth.MockAuthHandler, logger)
+
+	tests := []struct {
+		caso     test.APITestCase
+		enviadas int //Notificaciones ENVIADA luego de la peticion
+	}{
+		{
+			caso: test.APITestCase{
+				Name:         "historial del cliente",
+				Method:       "GET",
+				URL:          "/notificaciones/porCliente/1",
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusOK,
+				WantResponse: `*"total_count":2*`,
+			},
+			enviadas: 2,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "reenvio de una fallida",
+				Method:       "POST",
+				URL:          "/notificaciones/1/reenviar",
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusOK,
+				WantResponse: `*"estado":"ENVIADA"*`,
+			},
+			enviadas: 3,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "reenvio de una enviada",
+				Method:       "POST",
+				URL:          "/notificaciones/2/reenviar",
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusConflict,
+				WantResponse: "",
+			},
+			enviadas: 2,
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "reenvio de una inexistente",
+				Method:       "POST",
+				URL:          "/notificaciones/9/reenviar",
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusNotFound,
+				WantResponse: "",
+			},
+			enviadas: 2,
+		},
+	}
+	for _, tc := range tests {
+		test.Fixtures(t, db, "testdata/fixtures.sql")
+		test.Endpoint(t, router, tc.caso)
+		assert.Equal(t, tc.enviadas, test.Count(t, db, "notificaciones", dbx.HashExp{"estado": EstadoEnviada}), tc.caso.Name)
+	}
+}
@@ -0,0 +1,62 @@
+package notificaciones
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type email struct {
+	direccion string
+	auth      smtp.Auth
+	remitente string
+	enviar    func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now       func() time.Time
+}
+
+// NewEmail creates a Notifier that sends the mensajes by email from remitente through the SMTP server at
+// host:puerto. The server is only authenticated against when usuario is set.
+func NewEmail(host string, puerto int, usuario, clave, remitente string) Notifier {
+	var auth smtp.Auth
+	if usuario != "" {
+		auth = smtp.PlainAuth("", usuario, clave, host)
+	}
+	return email{net.JoinHostPort(host, strconv.Itoa(puerto)), auth, remitente, smtp.SendMail, time.Now}
+}
+
+func (e email) Enviar(ctx context.Context, mensaje Mensaje) error {
+	para, err := mail.ParseAddress(mensaje.Destinatario)
+	if err != nil {
+		return err
+	}
+	de, err := mail.ParseAddress(e.remitente)
+	if err != nil {
+		return err
+	}
+	return e.enviar(e.direccion, e.auth, de.Address, []string{para.Address}, e.contenido(de, para, mensaje))
+}
+
+// contenido builds the email with its headers. The body is sent as quoted-printable UTF-8 text.
+func (e email) contenido(de, para *mail.Address, mensaje Mensaje) []byte {
+	//El asunto sale de una plantilla, no puede agregar cabeceras
+	asunto := strings.NewReplacer("\r", " ", "\n", " ").Replace(mensaje.Asunto)
+	var b bytes.Buffer
+	b.WriteString("From: " + de.String() + "\r\n")
+	b.WriteString("To: " + para.String() + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", asunto) + "\r\n")
+	b.WriteString("Date: " + e.now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(strings.NewReplacer("\r\n", "\r\n", "\n", "\r\n").Replace(mensaje.Cuerpo)))
+	w.Close()
+	return b.Bytes()
+}
@@ -0,0 +1,44 @@
+package notificaciones
+
+import (
+	"sync"
+	"time"
+)
+
+// MensajeRecibido is a mensaje sent to the application through a canal, such as a reply of a cliente.
+type MensajeRecibido struct {
+	Canal string
+	// Remitente is the phone number, with the country code and no "+", for WhatsApp and the address for email.
+	Remitente string
+	Cuerpo    string
+	Fecha     time.Time
+}
+
+// Fuente delivers the mensajes received through a canal.
+type Fuente interface {
+	// Escuchar calls handler with each mensaje received from then on.
+	Escuchar(handler func(MensajeRecibido))
+}
+
+// FuenteFake is a Fuente whose mensajes are the ones passed to Recibir, for tests.
+type FuenteFake struct {
+	mu       sync.Mutex
+	handlers []func(MensajeRecibido)
+}
+
+func (f *FuenteFake) Escuchar(handler func(MensajeRecibido)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append(f.handlers, handler)
+}
+
+// Recibir delivers the mensaje to the handlers, as if it had been received through the canal. It returns
+// when all of them have handled it.
+func (f *FuenteFake) Recibir(mensaje MensajeRecibido) {
+	f.mu.Lock()
+	handlers := append([]func(MensajeRecibido){}, f.handlers...)
+	f.mu.Unlock()
+	for _, handler := range handlers {
+		handler(mensaje)
+	}
+}
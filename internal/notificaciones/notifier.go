@@ -0,0 +1,74 @@
+package notificaciones
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Canales through which the notificaciones are delivered.
+const (
+	CanalWhatsApp = "whatsapp"
+	CanalEmail    = "email"
+)
+
+// ErrCanalNoConfigurado is returned when a notificacion is queued for a canal without Notifier.
+var ErrCanalNoConfigurado = errors.New("notificaciones: canal not configured")
+
+// Mensaje is a notificacion already rendered from its plantilla.
+type Mensaje struct {
+	// Destinatario is the phone number, with the country code and no "+", for WhatsApp and the address for email.
+	Destinatario string
+	// Asunto is only used by the canales that have one, such as email.
+	Asunto string
+	Cuerpo string
+}
+
+// Notifier delivers mensajes through a canal.
+type Notifier interface {
+	Enviar(ctx context.Context, mensaje Mensaje) error
+}
+
+// Fake is a Notifier that keeps the mensajes in memory instead of delivering them, for tests.
+type Fake struct {
+	// Err, when set, is returned by Enviar and the mensaje is not kept.
+	Err error
+
+	mu       sync.Mutex
+	enviados []Mensaje
+}
+
+func (f *Fake) Enviar(ctx context.Context, mensaje Mensaje) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.enviados = append(f.enviados, mensaje)
+	return nil
+}
+
+// Enviados returns the mensajes delivered so far.
+func (f *Fake) Enviados() []Mensaje {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Mensaje{}, f.enviados...)
+}
+
+// FormatoFecha writes the fecha the way the mensajes show it, e.g. "Lunes 05 de Octubre a las 10:30".
+// It is available in the plantillas as the fecha function.
+func FormatoFecha(t time.Time) string {
+	return fmt.Sprintf("%s %02d de %s a las %02d:%02d",
+		dias[t.Weekday()], t.Day(), meses[t.Month()-1], t.Hour(), t.Minute(),
+	)
+}
+
+var dias = [...]string{
+	"Domingo", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado"}
+
+var meses = [...]string{
+	"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio",
+	"Julio", "Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre",
+}
@@ -0,0 +1,242 @@
+package notificaciones
+
+import (
+	"context"
+	"time"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access notificaciones, plantillas and destinatarios from the data source.
+type Repository interface {
+	GetNotificaciones(ctx context.Context, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error)
+	// GetNotificacionesPorCliente returns the notificaciones sent to the cliente, the most recent first.
+	GetNotificacionesPorCliente(ctx context.Context, idCliente int, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error)
+	GetNotificacionPorId(ctx context.Context, idNotificacion int) (entity.Notificacion, error)
+	// ReclamarPendientes claims up to limite notificaciones due to be sent at the fecha, the oldest first. The claimed
+	// notificaciones are not due again until hasta, so concurrent senders never get the same notificacion.
+	ReclamarPendientes(ctx context.Context, fecha, hasta time.Time, limite int64) ([]entity.Notificacion, error)
+	CrearNotificacion(ctx context.Context, notificacion entity.Notificacion) (entity.Notificacion, error)
+	// ActualizarEnvio saves the result of a delivery attempt of the notificacion.
+	ActualizarEnvio(ctx context.Context, notificacion entity.Notificacion) error
+	GetPlantillas(ctx context.Context, query pagination.Query) ([]entity.PlantillaNotificacion, *pagination.Pages, error)
+	// GetPlantilla returns the plantilla of the codigo for the canal.
+	GetPlantilla(ctx context.Context, codigo, canal string) (entity.PlantillaNotificacion, error)
+	GetPlantillaPorId(ctx context.Context, idPlantillaNotificacion int) (entity.PlantillaNotificacion, error)
+	ActualizarPlantilla(ctx context.Context, plantilla entity.PlantillaNotificacion) (entity.PlantillaNotificacion, error)
+	GetDestinatarios(ctx context.Context, query pagination.Query) ([]entity.DestinatarioAlerta, *pagination.Pages, error)
+	// GetDestinatariosActivos returns the active destinatarios of the tipo de alerta.
+	GetDestinatariosActivos(ctx context.Context, tipoAlerta string) ([]entity.DestinatarioAlerta, error)
+	GetDestinatarioPorId(ctx context.Context, idDestinatarioAlerta int) (entity.DestinatarioAlerta, error)
+	ActualizarDestinatario(ctx context.Context, destinatario entity.DestinatarioAlerta) (entity.DestinatarioAlerta, error)
+}
+
+// repository persists notificaciones in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new notificaciones repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposNotificaciones are the fields the list of notificaciones can be filtered, searched and sorted by.
+var camposNotificaciones = pagination.Fields{
+	Filters: map[string]string{
+		"id_cliente":       "id_cliente",
+		"tabla":            "tabla",
+		"id_referencia":    "id_referencia",
+		"canal":            "canal",
+		"estado":           "estado",
+		"codigo_plantilla": "codigo_plantilla",
+		"destinatario":     "destinatario",
+	},
+	Ranges: map[string]string{"fecha_creacion": "fecha_creacion", "fecha_envio": "fecha_envio"},
+	Search: []string{"destinatario", "cuerpo"},
+	Sort: map[string]string{
+		"id_notificacion": "id_notificacion",
+		"canal":           "canal",
+		"estado":          "estado",
+		"intentos":        "intentos",
+		"fecha_creacion":  "fecha_creacion",
+		"fecha_envio":     "fecha_envio",
+	},
+	DefaultSort: []string{"id_notificacion desc"},
+}
+
+func (r repository) GetNotificaciones(ctx context.Context, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error) {
+	var notificaciones []entity.Notificacion = []entity.Notificacion{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("notificaciones"), camposNotificaciones, &notificaciones)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notificaciones, pages, nil
+}
+
+func (r repository) GetNotificacionesPorCliente(ctx context.Context, idCliente int, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error) {
+	var notificaciones []entity.Notificacion = []entity.Notificacion{}
+
+	q := r.db.With(ctx).Select().From("notificaciones").Where(dbx.HashExp{"id_cliente": idCliente})
+	pages, err := query.List(ctx, r.db.With(ctx), q, camposNotificaciones, &notificaciones)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notificaciones, pages, nil
+}
+
+func (r repository) GetNotificacionPorId(ctx context.Context, idNotificacion int) (entity.Notificacion, error) {
+	var notificacion entity.Notificacion
+	err := r.db.With(ctx).Select().Model(idNotificacion, &notificacion)
+	return notificacion, err
+}
+
+func (r repository) ReclamarPendientes(ctx context.Context, fecha, hasta time.Time, limite int64) ([]entity.Notificacion, error) {
+	var notificaciones []entity.Notificacion = []entity.Notificacion{}
+	err := r.db.Transactional(ctx, func(ctx context.Context) error {
+		//SKIP LOCKED deja a otro proceso las filas que ya se estan reclamando
+		err := r.db.With(ctx).
+			NewQuery("SELECT * FROM notificaciones WHERE estado = {:estado} AND fecha_proximo_intento <= {:fecha} " +
+				"ORDER BY fecha_proximo_intento ASC, id_notificacion ASC LIMIT {:limite} FOR UPDATE SKIP LOCKED").
+			Bind(dbx.Params{"estado": EstadoPendiente, "fecha": fecha, "limite": limite}).
+			All(&notificaciones)
+		if err != nil || len(notificaciones) == 0 {
+			return err
+		}
+		ids := make([]interface{}, len(notificaciones))
+		for i := range notificaciones {
+			ids[i] = notificaciones[i].IdNotificacion
+			notificaciones[i].FechaProximoIntento = &hasta
+		}
+		_, err = r.db.With(ctx).
+			Update("notificaciones", dbx.Params{"fecha_proximo_intento": hasta}, dbx.In("id_notificacion", ids...)).
+			Execute()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notificaciones, nil
+}
+
+// CrearNotificacion queues the notificacion. The outbox is not audited, it is a log by itself.
+func (r repository) CrearNotificacion(ctx context.Context, notificacion entity.Notificacion) (entity.Notificacion, error) {
+	err := r.db.With(ctx).Model(&notificacion).Insert()
+	if err != nil {
+		return entity.Notificacion{}, err
+	}
+	return notificacion, nil
+}
+
+func (r repository) ActualizarEnvio(ctx context.Context, notificacion entity.Notificacion) error {
+	return r.db.With(ctx).Model(&notificacion).Update("Estado", "Intentos", "UltimoError", "FechaProximoIntento", "FechaEnvio")
+}
+
+// camposPlantillas are the fields the list of plantillas can be filtered, searched and sorted by.
+var camposPlantillas = pagination.Fields{
+	Filters: map[string]string{"codigo": "codigo", "canal": "canal"},
+	Search:  []string{"codigo", "asunto", "cuerpo"},
+	Sort: map[string]string{
+		"id_plantilla_notificacion": "id_plantilla_notificacion",
+		"codigo":                    "codigo",
+		"canal":                     "canal",
+	},
+	DefaultSort: []string{"codigo asc", "canal asc"},
+}
+
+func (r repository) GetPlantillas(ctx context.Context, query pagination.Query) ([]entity.PlantillaNotificacion, *pagination.Pages, error) {
+	var plantillas []entity.PlantillaNotificacion = []entity.PlantillaNotificacion{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("plantillas_notificacion"), camposPlantillas, &plantillas)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plantillas, pages, nil
+}
+
+func (r repository) GetPlantilla(ctx context.Context, codigo, canal string) (entity.PlantillaNotificacion, error) {
+	var plantilla entity.PlantillaNotificacion
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"codigo": codigo, "canal": canal}).
+		One(&plantilla)
+	return plantilla, err
+}
+
+func (r repository) GetPlantillaPorId(ctx context.Context, idPlantillaNotificacion int) (entity.PlantillaNotificacion, error) {
+	var plantilla entity.PlantillaNotificacion
+	err := r.db.With(ctx).Select().Model(idPlantillaNotificacion, &plantilla)
+	return plantilla, err
+}
+
+func (r repository) ActualizarPlantilla(ctx context.Context, plantilla entity.PlantillaNotificacion) (entity.PlantillaNotificacion, error) {
+	var err error
+	if plantilla.IdPlantillaNotificacion != 0 {
+		err = auditoria.Actualizar(ctx, r.db, &plantilla)
+	} else {
+		err = auditoria.Insertar(ctx, r.db, &plantilla)
+	}
+	if err != nil {
+		return entity.PlantillaNotificacion{}, err
+	}
+	return plantilla, nil
+}
+
+// camposDestinatarios are the fields the list of destinatarios can be filtered, searched and sorted by.
+var camposDestinatarios = pagination.Fields{
+	Filters: map[string]string{"tipo_alerta": "tipo_alerta", "canal": "canal", "activo": "activo"},
+	Search:  []string{"destino", "nombre"},
+	Sort: map[string]string{
+		"id_destinatario_alerta": "id_destinatario_alerta",
+		"tipo_alerta":            "tipo_alerta",
+		"canal":                  "canal",
+		"destino":                "destino",
+	},
+	DefaultSort: []string{"tipo_alerta asc", "id_destinatario_alerta asc"},
+}
+
+func (r repository) GetDestinatarios(ctx context.Context, query pagination.Query) ([]entity.DestinatarioAlerta, *pagination.Pages, error) {
+	var destinatarios []entity.DestinatarioAlerta = []entity.DestinatarioAlerta{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("destinatarios_alerta"), camposDestinatarios, &destinatarios)
+	if err != nil {
+		return nil, nil, err
+	}
+	return destinatarios, pages, nil
+}
+
+func (r repository) GetDestinatariosActivos(ctx context.Context, tipoAlerta string) ([]entity.DestinatarioAlerta, error) {
+	var destinatarios []entity.DestinatarioAlerta = []entity.DestinatarioAlerta{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"tipo_alerta": tipoAlerta, "activo": true}).
+		OrderBy("id_destinatario_alerta asc").
+		All(&destinatarios)
+	return destinatarios, err
+}
+
+func (r repository) GetDestinatarioPorId(ctx context.Context, idDestinatarioAlerta int) (entity.DestinatarioAlerta, error) {
+	var destinatario entity.DestinatarioAlerta
+	err := r.db.With(ctx).Select().Model(idDestinatarioAlerta, &destinatario)
+	return destinatario, err
+}
+
+func (r repository) ActualizarDestinatario(ctx context.Context, destinatario entity.DestinatarioAlerta) (entity.DestinatarioAlerta, error) {
+	var err error
+	if destinatario.IdDestinatarioAlerta != 0 {
+		err = auditoria.Actualizar(ctx, r.db, &destinatario)
+	} else {
+		err = auditoria.Insertar(ctx, r.db, &destinatario)
+	}
+	if err != nil {
+		return entity.DestinatarioAlerta{}, err
+	}
+	return destinatario, nil
+}
@@ -0,0 +1,35 @@
+package notificaciones
+
+import (
+	"context"
+	"testing"
+	"time"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_ReclamarPendientes(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	repo := NewRepository(db, logger)
+	test.Fixtures(t, db, "testdata/fixtures.sql")
+	ctx := context.Background()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.Local)
+	hasta := ahora.Add(reservaEnvio)
+
+	notificaciones, err := repo.ReclamarPendientes(ctx, ahora, hasta, lotePendientes)
+	assert.Nil(t, err)
+	if assert.Len(t, notificaciones, 1) {
+		assert.Equal(t, 4, notificaciones[0].IdNotificacion)
+	}
+	//Reclamada, otro envio no la toma hasta que venza la reserva
+	notificaciones, err = repo.ReclamarPendientes(ctx, ahora, hasta, lotePendientes)
+	assert.Nil(t, err)
+	assert.Empty(t, notificaciones)
+
+	notificaciones, err = repo.ReclamarPendientes(ctx, hasta, hasta.Add(reservaEnvio), lotePendientes)
+	assert.Nil(t, err)
+	assert.Len(t, notificaciones, 1)
+}
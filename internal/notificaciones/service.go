@@ -0,0 +1,418 @@
+package notificaciones
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"text/template"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// Estados of a notificacion in the outbox.
+const (
+	EstadoPendiente = "PENDIENTE"
+	EstadoEnviada   = "ENVIADA"
+	EstadoFallida   = "FALLIDA"
+)
+
+// Codigos of the plantillas the application sends. An alerta uses the codigo of its plantilla as tipo de alerta.
+const (
+	PlantillaRecordatorioCita = "recordatorio_cita"
+	AlertaEstadoCanal         = "estado_canal"
+	AlertaPocoStock           = "poco_stock"
+	AlertaCitaCancelada       = "cita_cancelada"
+	AlertaCitaReprogramar     = "cita_reprogramar"
+)
+
+// MaxIntentos is how many delivery attempts a notificacion gets before it is marked FALLIDA.
+const MaxIntentos = 5
+
+// esperaReintento is the wait after the first failed attempt. It is multiplied by 4 after each failure,
+// so the attempts are 1, 4, 16 and 64 minutes apart.
+const esperaReintento = time.Minute
+
+// lotePendientes is how many notificaciones each run of EnviarPendientes delivers at most.
+const lotePendientes = 50
+
+// reservaEnvio is how long the notificaciones claimed by EnviarPendientes are kept from other senders. If the
+// process stops before saving the result they are sent again after it.
+const reservaEnvio = 10 * time.Minute
+
+// timeoutEnvio limits each delivery attempt.
+const timeoutEnvio = 30 * time.Second
+
+// funciones are the functions available in the plantillas.
+var funciones = template.FuncMap{"fecha": FormatoFecha}
+
+// Service encapsulates usecase logic for notificaciones.
+type Service interface {
+	GetNotificaciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	// GetNotificacionesPorCliente returns the history of the notificaciones sent to the cliente.
+	GetNotificacionesPorCliente(ctx context.Context, idCliente int, query pagination.Query) (*pagination.Pages, error)
+	// Notificar renders the plantilla of the codigo for the canal with the datos and queues the notificacion
+	// about the referencia for the destinatario. It is delivered by EnviarPendientes.
+	Notificar(ctx context.Context, codigo, canal, destinatario string, referencia Referencia, datos interface{}) (Notificacion, error)
+	// Alertar queues the plantilla of the tipo de alerta for each of its active destinatarios whose canal is
+	// configured.
+	Alertar(ctx context.Context, tipoAlerta string, datos interface{}) ([]Notificacion, error)
+	// EnviarPendientes delivers the queued notificaciones that are due and returns how many were sent. A failed
+	// delivery is attempted again after a growing wait until the notificacion reaches MaxIntentos.
+	EnviarPendientes(ctx context.Context) (int, error)
+	// Reenviar attempts again the delivery of a FALLIDA notificacion, with a new set of MaxIntentos.
+	Reenviar(ctx context.Context, idNotificacion int) (Notificacion, error)
+	GetPlantillas(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	ActualizarPlantilla(ctx context.Context, input UpdatePlantillaRequest) (Plantilla, error)
+	GetDestinatarios(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	ActualizarDestinatario(ctx context.Context, input UpdateDestinatarioRequest) (Destinatario, error)
+}
+
+// Notificacion represents the data about a notificacion.
+type Notificacion struct {
+	entity.Notificacion
+}
+
+// Referencia is the cliente and the record a notificacion is about, e.g. the cita médica of a recordatorio.
+// The alertas have none.
+type Referencia struct {
+	IdCliente    *int
+	Tabla        *string
+	IdReferencia *int
+}
+
+// Plantilla represents the data about a plantilla de notificacion.
+type Plantilla struct {
+	entity.PlantillaNotificacion
+}
+
+// Destinatario represents the data about a destinatario of alertas.
+type Destinatario struct {
+	entity.DestinatarioAlerta
+}
+
+type service struct {
+	repo      Repository
+	notifiers map[string]Notifier
+	logger    log.Logger
+	now       func() time.Time
+}
+
+// NewService creates a new notificaciones service that delivers the notificaciones of each canal with
+// its Notifier. Notificar returns ErrCanalNoConfigurado for a canal without Notifier.
+func NewService(repo Repository, notifiers map[string]Notifier, logger log.Logger) Service {
+	return service{repo, notifiers, logger, time.Now}
+}
+
+func (s service) GetNotificaciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	notificaciones, pages, err := s.repo.GetNotificaciones(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Notificacion{}
+	for _, item := range notificaciones {
+		result = append(result, Notificacion{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetNotificacionesPorCliente(ctx context.Context, idCliente int, query pagination.Query) (*pagination.Pages, error) {
+	notificaciones, pages, err := s.repo.GetNotificacionesPorCliente(ctx, idCliente, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Notificacion{}
+	for _, item := range notificaciones {
+		result = append(result, Notificacion{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) Notificar(ctx context.Context, codigo, canal, destinatario string, referencia Referencia, datos interface{}) (Notificacion, error) {
+	if _, ok := s.notifiers[canal]; !ok {
+		return Notificacion{}, ErrCanalNoConfigurado
+	}
+	plantilla, err := s.repo.GetPlantilla(ctx, codigo, canal)
+	if err != nil {
+		return Notificacion{}, fmt.Errorf("notificaciones: plantilla %s de %s: %w", codigo, canal, err)
+	}
+	asunto, err := renderizar(plantilla.Asunto, datos)
+	if err != nil {
+		return Notificacion{}, err
+	}
+	cuerpo, err := renderizar(plantilla.Cuerpo, datos)
+	if err != nil {
+		return Notificacion{}, err
+	}
+	ahora := s.now()
+	notificacion, err := s.repo.CrearNotificacion(ctx, entity.Notificacion{
+		IdCliente:           referencia.IdCliente,
+		Tabla:               referencia.Tabla,
+		IdReferencia:        referencia.IdReferencia,
+		Canal:               canal,
+		Destinatario:        destinatario,
+		CodigoPlantilla:     codigo,
+		Asunto:              asunto,
+		Cuerpo:              cuerpo,
+		Estado:              EstadoPendiente,
+		FechaCreacion:       ahora,
+		FechaProximoIntento: &ahora,
+	})
+	if err != nil {
+		return Notificacion{}, err
+	}
+	return Notificacion{notificacion}, nil
+}
+
+func (s service) Alertar(ctx context.Context, tipoAlerta string, datos interface{}) ([]Notificacion, error) {
+	destinatarios, err := s.repo.GetDestinatariosActivos(ctx, tipoAlerta)
+	if err != nil {
+		return nil, err
+	}
+	result := []Notificacion{}
+	for _, destinatario := range destinatarios {
+		if _, ok := s.notifiers[destinatario.Canal]; !ok {
+			s.logger.With(ctx).Infof("alerta %s not sent to %s, the canal %s is not configured", tipoAlerta, destinatario.Destino, destinatario.Canal)
+			continue
+		}
+		notificacion, err := s.Notificar(ctx, tipoAlerta, destinatario.Canal, destinatario.Destino, Referencia{}, datos)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, notificacion)
+	}
+	return result, nil
+}
+
+func (s service) EnviarPendientes(ctx context.Context) (int, error) {
+	ahora := s.now()
+	pendientes, err := s.repo.ReclamarPendientes(ctx, ahora, ahora.Add(reservaEnvio), lotePendientes)
+	if err != nil {
+		return 0, err
+	}
+	enviadas := 0
+	for _, notificacion := range pendientes {
+		notificacion, err := s.procesar(ctx, notificacion)
+		if err != nil {
+			return enviadas, err
+		}
+		if notificacion.Estado == EstadoEnviada {
+			enviadas++
+		}
+	}
+	return enviadas, nil
+}
+
+func (s service) Reenviar(ctx context.Context, idNotificacion int) (Notificacion, error) {
+	notificacion, err := s.repo.GetNotificacionPorId(ctx, idNotificacion)
+	if err != nil {
+		return Notificacion{}, err
+	}
+	if notificacion.Estado != EstadoFallida {
+		return Notificacion{}, errors.Conflict("Solo se puede reenviar una notificación fallida.")
+	}
+	notificacion.Estado = EstadoPendiente
+	notificacion.Intentos = 0
+	notificacion, err = s.procesar(ctx, notificacion)
+	if err != nil {
+		return Notificacion{}, err
+	}
+	return Notificacion{notificacion}, nil
+}
+
+// procesar attempts the delivery of the notificacion and saves the result. After a failure the notificacion
+// stays PENDIENTE until its next attempt, or becomes FALLIDA when it reached MaxIntentos.
+func (s service) procesar(ctx context.Context, notificacion entity.Notificacion) (entity.Notificacion, error) {
+	notificacion.Intentos++
+	ahora := s.now()
+	if err := s.enviar(ctx, notificacion); err != nil {
+		s.logger.With(ctx).Errorf("failed to send the notificacion %d: %s", notificacion.IdNotificacion, err)
+		mensaje := err.Error()
+		notificacion.UltimoError = &mensaje
+		if notificacion.Intentos >= MaxIntentos {
+			notificacion.Estado = EstadoFallida
+			notificacion.FechaProximoIntento = nil
+		} else {
+			proximo := ahora.Add(esperaReintento << (2 * uint(notificacion.Intentos-1)))
+			notificacion.FechaProximoIntento = &proximo
+		}
+	} else {
+		notificacion.Estado = EstadoEnviada
+		notificacion.FechaEnvio = &ahora
+		notificacion.FechaProximoIntento = nil
+		notificacion.UltimoError = nil
+	}
+	if err := s.repo.ActualizarEnvio(ctx, notificacion); err != nil {
+		return entity.Notificacion{}, err
+	}
+	return notificacion, nil
+}
+
+// enviar delivers the notificacion with the Notifier of its canal.
+func (s service) enviar(ctx context.Context, notificacion entity.Notificacion) error {
+	notifier, ok := s.notifiers[notificacion.Canal]
+	if !ok {
+		return ErrCanalNoConfigurado
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeoutEnvio)
+	defer cancel()
+	return notifier.Enviar(ctx, Mensaje{
+		Destinatario: notificacion.Destinatario,
+		Asunto:       notificacion.Asunto,
+		Cuerpo:       notificacion.Cuerpo,
+	})
+}
+
+// renderizar executes the text of a plantilla with the datos.
+func renderizar(texto string, datos interface{}) (string, error) {
+	t, err := template.New("").Funcs(funciones).Option("missingkey=error").Parse(texto)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, datos); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// plantillaValida validates that the text is a valid plantilla.
+func plantillaValida(value interface{}) error {
+	texto, _ := value.(string)
+	if _, err := template.New("").Funcs(funciones).Parse(texto); err != nil {
+		return validation.NewError("validation_plantilla_invalida", err.Error())
+	}
+	return nil
+}
+
+func (s service) GetPlantillas(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	plantillas, pages, err := s.repo.GetPlantillas(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Plantilla{}
+	for _, item := range plantillas {
+		result = append(result, Plantilla{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+// UpdatePlantillaRequest represents a plantilla creation or update request.
+type UpdatePlantillaRequest struct {
+	IdPlantillaNotificacion int    `json:"id_plantilla_notificacion"`
+	Codigo                  string `json:"codigo"`
+	Canal                   string `json:"canal"`
+	Asunto                  string `json:"asunto"`
+	Cuerpo                  string `json:"cuerpo"`
+}
+
+// Validate validates the UpdatePlantillaRequest fields.
+func (m UpdatePlantillaRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Codigo, validation.Required, validation.Length(0, 50)),
+		validation.Field(&m.Canal, validation.Required, validation.In(CanalWhatsApp, CanalEmail)),
+		validation.Field(&m.Asunto, validation.When(m.Canal == CanalEmail, validation.Required), validation.Length(0, 255), validation.By(plantillaValida)),
+		validation.Field(&m.Cuerpo, validation.Required, validation.By(plantillaValida)),
+	)
+}
+
+// ActualizarPlantilla creates a plantilla when it has no ID and updates it otherwise. There is a single
+// plantilla for each codigo and canal.
+func (s service) ActualizarPlantilla(ctx context.Context, req UpdatePlantillaRequest) (Plantilla, error) {
+	if err := req.Validate(); err != nil {
+		return Plantilla{}, err
+	}
+	if req.IdPlantillaNotificacion != 0 {
+		if _, err := s.repo.GetPlantillaPorId(ctx, req.IdPlantillaNotificacion); err != nil {
+			return Plantilla{}, err
+		}
+	}
+	existente, err := s.repo.GetPlantilla(ctx, req.Codigo, req.Canal)
+	if err != nil && err != sql.ErrNoRows {
+		return Plantilla{}, err
+	}
+	if err == nil && existente.IdPlantillaNotificacion != req.IdPlantillaNotificacion {
+		return Plantilla{}, errors.Conflict("Ya existe una plantilla " + req.Codigo + " para el canal " + req.Canal + ".")
+	}
+	plantillaG, err := s.repo.ActualizarPlantilla(ctx, entity.PlantillaNotificacion{
+		IdPlantillaNotificacion: req.IdPlantillaNotificacion,
+		Codigo:                  req.Codigo,
+		Canal:                   req.Canal,
+		Asunto:                  req.Asunto,
+		Cuerpo:                  req.Cuerpo,
+	})
+	if err != nil {
+		return Plantilla{}, err
+	}
+	return Plantilla{plantillaG}, nil
+}
+
+func (s service) GetDestinatarios(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	destinatarios, pages, err := s.repo.GetDestinatarios(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Destinatario{}
+	for _, item := range destinatarios {
+		result = append(result, Destinatario{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+// UpdateDestinatarioRequest represents a destinatario creation or update request.
+type UpdateDestinatarioRequest struct {
+	IdDestinatarioAlerta int     `json:"id_destinatario_alerta"`
+	TipoAlerta           string  `json:"tipo_alerta"`
+	Canal                string  `json:"canal"`
+	Destino              string  `json:"destino"`
+	Nombre               *string `json:"nombre"`
+	Activo               bool    `json:"activo"`
+}
+
+// Validate validates the UpdateDestinatarioRequest fields. The destino is a phone number with the country
+// code for WhatsApp and an address for email.
+func (m UpdateDestinatarioRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.TipoAlerta, validation.Required, validation.In(AlertaEstadoCanal, AlertaPocoStock, AlertaCitaCancelada, AlertaCitaReprogramar)),
+		validation.Field(&m.Canal, validation.Required, validation.In(CanalWhatsApp, CanalEmail)),
+		validation.Field(&m.Destino, validation.Required,
+			validation.When(m.Canal == CanalWhatsApp, is.Digit, validation.Length(8, 15)),
+			validation.When(m.Canal == CanalEmail, is.Email, validation.Length(0, 150)),
+		),
+		validation.Field(&m.Nombre, validation.NilOrNotEmpty, validation.Length(0, 100)),
+	)
+}
+
+// ActualizarDestinatario creates a destinatario when it has no ID and updates it otherwise.
+func (s service) ActualizarDestinatario(ctx context.Context, req UpdateDestinatarioRequest) (Destinatario, error) {
+	if err := req.Validate(); err != nil {
+		return Destinatario{}, err
+	}
+	if req.IdDestinatarioAlerta != 0 {
+		if _, err := s.repo.GetDestinatarioPorId(ctx, req.IdDestinatarioAlerta); err != nil {
+			return Destinatario{}, err
+		}
+	}
+	destinatarioG, err := s.repo.ActualizarDestinatario(ctx, entity.DestinatarioAlerta{
+		IdDestinatarioAlerta: req.IdDestinatarioAlerta,
+		TipoAlerta:           req.TipoAlerta,
+		Canal:                req.Canal,
+		Destino:              req.Destino,
+		Nombre:               req.Nombre,
+		Activo:               req.Activo,
+	})
+	if err != nil {
+		return Destinatario{}, err
+	}
+	return Destinatario{destinatarioG}, nil
+}
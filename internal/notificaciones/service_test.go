@@ -0,0 +1,363 @@
+package notificaciones
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/smtp"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_service_Notificar(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{plantillas: []entity.PlantillaNotificacion{
+		{IdPlantillaNotificacion: 1, Codigo: PlantillaRecordatorioCita, Canal: CanalWhatsApp, Cuerpo: "Hola {{.Duenio}}, cita el *{{fecha .Fecha}}*."},
+		{IdPlantillaNotificacion: 2, Codigo: PlantillaRecordatorioCita, Canal: CanalEmail, Asunto: "Cita de {{.Mascota}}", Cuerpo: "{{.Noexiste}}"},
+	}}
+	whatsApp := &Fake{}
+	s := NewService(repo, map[string]Notifier{CanalWhatsApp: whatsApp, CanalEmail: &Fake{}}, logger)
+	ctx := context.Background()
+	datos := struct {
+		Duenio, Mascota string
+		Fecha           time.Time
+	}{"Ana Pérez", "Firulais", time.Date(2026, 10, 5, 10, 30, 0, 0, time.UTC)}
+
+	idCliente, tabla, idCita := 7, "CitaMedica", 12
+	referencia := Referencia{IdCliente: &idCliente, Tabla: &tabla, IdReferencia: &idCita}
+
+	notificacion, err := s.Notificar(ctx, PlantillaRecordatorioCita, CanalWhatsApp, "593999999999", referencia, datos)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Hola Ana Pérez, cita el *Lunes 05 de Octubre a las 10:30*.", notificacion.Cuerpo)
+		assert.Equal(t, EstadoPendiente, notificacion.Estado)
+		assert.Equal(t, &idCliente, notificacion.IdCliente)
+		assert.Equal(t, &idCita, notificacion.IdReferencia)
+		assert.NotNil(t, notificacion.FechaProximoIntento)
+	}
+	//Solo se encola, el envio lo hace EnviarPendientes
+	assert.Empty(t, whatsApp.Enviados())
+
+	//Campo inexistente en los datos
+	_, err = s.Notificar(ctx, PlantillaRecordatorioCita, CanalEmail, "ana@example.com", referencia, datos)
+	assert.NotNil(t, err)
+	//Sin plantilla
+	_, err = s.Notificar(ctx, AlertaPocoStock, CanalWhatsApp, "593999999999", Referencia{}, nil)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	//Canal sin Notifier
+	_, err = NewService(repo, map[string]Notifier{}, logger).Notificar(ctx, PlantillaRecordatorioCita, CanalWhatsApp, "593999999999", referencia, datos)
+	assert.Equal(t, ErrCanalNoConfigurado, err)
+	assert.Len(t, repo.notificaciones, 1)
+}
+
+func Test_service_Alertar(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		plantillas: []entity.PlantillaNotificacion{
+			{Codigo: AlertaPocoStock, Canal: CanalWhatsApp, Cuerpo: "{{range .}}{{.}} {{end}}"},
+		},
+		destinatarios: []entity.DestinatarioAlerta{
+			{IdDestinatarioAlerta: 1, TipoAlerta: AlertaPocoStock, Canal: CanalWhatsApp, Destino: "593911111111", Activo: true},
+			{IdDestinatarioAlerta: 2, TipoAlerta: AlertaPocoStock, Canal: CanalWhatsApp, Destino: "593922222222", Activo: false},
+			{IdDestinatarioAlerta: 3, TipoAlerta: AlertaPocoStock, Canal: CanalEmail, Destino: "bodega@example.com", Activo: true},
+			{IdDestinatarioAlerta: 4, TipoAlerta: AlertaEstadoCanal, Canal: CanalWhatsApp, Destino: "593933333333", Activo: true},
+		},
+	}
+	s := NewService(repo, map[string]Notifier{CanalWhatsApp: &Fake{}}, logger)
+
+	notificaciones, err := s.Alertar(context.Background(), AlertaPocoStock, []string{"Vacuna", "Collar"})
+	assert.Nil(t, err)
+	if assert.Len(t, notificaciones, 1) {
+		assert.Equal(t, "593911111111", notificaciones[0].Destinatario)
+		assert.Equal(t, "Vacuna Collar ", notificaciones[0].Cuerpo)
+	}
+}
+
+func Test_service_EnviarPendientes(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	despues := ahora.Add(time.Hour)
+	error1 := "timeout"
+	repo := &mockRepository{notificaciones: []entity.Notificacion{
+		{IdNotificacion: 1, Canal: CanalWhatsApp, Destinatario: "593911111111", Cuerpo: "uno", Estado: EstadoPendiente, FechaProximoIntento: &ahora},
+		{IdNotificacion: 2, Canal: CanalEmail, Destinatario: "a@example.com", Asunto: "dos", Cuerpo: "dos", Estado: EstadoPendiente, FechaProximoIntento: &ahora},
+		{IdNotificacion: 3, Canal: CanalEmail, Destinatario: "b@example.com", Cuerpo: "tres", Estado: EstadoPendiente, Intentos: MaxIntentos - 1, UltimoError: &error1, FechaProximoIntento: &ahora},
+		{IdNotificacion: 4, Canal: CanalWhatsApp, Destinatario: "593922222222", Cuerpo: "cuatro", Estado: EstadoEnviada, Intentos: 1},
+		{IdNotificacion: 5, Canal: CanalWhatsApp, Destinatario: "593933333333", Cuerpo: "cinco", Estado: EstadoPendiente, Intentos: 1, FechaProximoIntento: &despues},
+	}}
+	whatsApp, email := &Fake{}, &Fake{Err: errors.New("smtp caido")}
+	s := NewService(repo, map[string]Notifier{CanalWhatsApp: whatsApp, CanalEmail: email}, logger).(service)
+	s.now = func() time.Time { return ahora }
+
+	enviadas, err := s.EnviarPendientes(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, enviadas)
+	assert.Equal(t, []Mensaje{{Destinatario: "593911111111", Cuerpo: "uno"}}, whatsApp.Enviados())
+	assert.Equal(t, map[int]string{1: EstadoEnviada, 2: EstadoPendiente, 3: EstadoFallida, 4: EstadoEnviada, 5: EstadoPendiente}, repo.estados())
+	assert.Equal(t, &ahora, repo.notificaciones[0].FechaEnvio)
+	assert.Nil(t, repo.notificaciones[0].UltimoError)
+	assert.Equal(t, 1, repo.notificaciones[1].Intentos)
+	if assert.NotNil(t, repo.notificaciones[1].UltimoError) {
+		assert.Equal(t, "smtp caido", *repo.notificaciones[1].UltimoError)
+	}
+	assert.Equal(t, ahora.Add(time.Minute), *repo.notificaciones[1].FechaProximoIntento)
+	assert.Equal(t, MaxIntentos, repo.notificaciones[2].Intentos)
+	assert.Nil(t, repo.notificaciones[2].FechaProximoIntento)
+
+	//Antes de la espera no se reintenta
+	email.Err = nil
+	s.now = func() time.Time { return ahora.Add(30 * time.Second) }
+	enviadas, _ = s.EnviarPendientes(context.Background())
+	assert.Equal(t, 0, enviadas)
+
+	//Cada falla multiplica la espera
+	email.Err = errors.New("smtp caido")
+	s.now = func() time.Time { return ahora.Add(time.Minute) }
+	enviadas, _ = s.EnviarPendientes(context.Background())
+	assert.Equal(t, 0, enviadas)
+	assert.Equal(t, 2, repo.notificaciones[1].Intentos)
+	assert.Equal(t, ahora.Add(5*time.Minute), *repo.notificaciones[1].FechaProximoIntento)
+
+	email.Err = nil
+	s.now = func() time.Time { return despues }
+	enviadas, err = s.EnviarPendientes(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, enviadas)
+	assert.Equal(t, map[int]string{1: EstadoEnviada, 2: EstadoEnviada, 3: EstadoFallida, 4: EstadoEnviada, 5: EstadoEnviada}, repo.estados())
+}
+
+func Test_service_Reenviar(t *testing.T) {
+	logger, _ := log.NewForTest()
+	error1 := "smtp caido"
+	repo := &mockRepository{notificaciones: []entity.Notificacion{
+		{IdNotificacion: 1, Canal: CanalEmail, Destinatario: "a@example.com", Cuerpo: "uno", Estado: EstadoFallida, Intentos: MaxIntentos, UltimoError: &error1},
+		{IdNotificacion: 2, Canal: CanalEmail, Destinatario: "b@example.com", Cuerpo: "dos", Estado: EstadoEnviada, Intentos: 1},
+	}}
+	email := &Fake{}
+	s := NewService(repo, map[string]Notifier{CanalEmail: email}, logger)
+
+	notificacion, err := s.Reenviar(context.Background(), 1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, EstadoEnviada, notificacion.Estado)
+		assert.Equal(t, 1, notificacion.Intentos)
+		assert.Nil(t, notificacion.UltimoError)
+	}
+	assert.Len(t, email.Enviados(), 1)
+
+	_, err = s.Reenviar(context.Background(), 2)
+	assert.NotNil(t, err)
+	_, err = s.Reenviar(context.Background(), 3)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	//Si vuelve a fallar queda pendiente para el siguiente intento
+	email.Err = errors.New("smtp caido")
+	repo.notificaciones[0].Estado = EstadoFallida
+	notificacion, err = s.Reenviar(context.Background(), 1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, EstadoPendiente, notificacion.Estado)
+		assert.NotNil(t, notificacion.FechaProximoIntento)
+	}
+}
+
+func TestUpdatePlantillaRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   UpdatePlantillaRequest
+		wantErr bool
+	}{
+		{"success", UpdatePlantillaRequest{Codigo: AlertaPocoStock, Canal: CanalWhatsApp, Cuerpo: "{{range .}}{{.Producto}}{{end}}"}, false},
+		{"email sin asunto", UpdatePlantillaRequest{Codigo: AlertaPocoStock, Canal: CanalEmail, Cuerpo: "Stock"}, true},
+		{"canal invalido", UpdatePlantillaRequest{Codigo: AlertaPocoStock, Canal: "sms", Cuerpo: "Stock"}, true},
+		{"plantilla invalida", UpdatePlantillaRequest{Codigo: AlertaPocoStock, Canal: CanalWhatsApp, Cuerpo: "{{range .}}"}, true},
+		{"funcion inexistente", UpdatePlantillaRequest{Codigo: AlertaPocoStock, Canal: CanalWhatsApp, Cuerpo: "{{hora .Fecha}}"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_email_Enviar(t *testing.T) {
+	var para []string
+	var contenido string
+	e := NewEmail("smtp.example.com", 587, "", "", "Veterinaria <avisos@example.com>").(email)
+	e.now = func() time.Time { return time.Date(2026, 10, 5, 10, 30, 0, 0, time.UTC) }
+	e.enviar = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Equal(t, "avisos@example.com", from)
+		para, contenido = to, string(msg)
+		return nil
+	}
+
+	err := e.Enviar(context.Background(), Mensaje{Destinatario: "ana@example.com", Asunto: "Cita médica\r\nBcc: otro@example.com", Cuerpo: "Línea 1\nLínea 2"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ana@example.com"}, para)
+	assert.Contains(t, contenido, "Subject: =?utf-8?q?Cita_m=C3=A9dica__Bcc:_otro@example.com?=\r\n")
+	assert.NotContains(t, contenido, "\r\nBcc:")
+	assert.Contains(t, contenido, "\r\n\r\nL=C3=ADnea 1\r\nL=C3=ADnea 2")
+
+	assert.NotNil(t, e.Enviar(context.Background(), Mensaje{Destinatario: "no es un correo"}))
+}
+
+type mockRepository struct {
+	notificaciones []entity.Notificacion
+	plantillas     []entity.PlantillaNotificacion
+	destinatarios  []entity.DestinatarioAlerta
+}
+
+func (m *mockRepository) GetNotificaciones(ctx context.Context, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.notificaciones))
+	return m.notificaciones, pages, nil
+}
+
+func (m *mockRepository) GetNotificacionesPorCliente(ctx context.Context, idCliente int, query pagination.Query) ([]entity.Notificacion, *pagination.Pages, error) {
+	result := []entity.Notificacion{}
+	for _, n := range m.notificaciones {
+		if n.IdCliente != nil && *n.IdCliente == idCliente {
+			result = append(result, n)
+		}
+	}
+	pages := pagination.New(query.Page, query.PerPage, len(result))
+	return result, pages, nil
+}
+
+func (m *mockRepository) GetNotificacionPorId(ctx context.Context, idNotificacion int) (entity.Notificacion, error) {
+	for _, n := range m.notificaciones {
+		if n.IdNotificacion == idNotificacion {
+			return n, nil
+		}
+	}
+	return entity.Notificacion{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) ReclamarPendientes(ctx context.Context, fecha, hasta time.Time, limite int64) ([]entity.Notificacion, error) {
+	result := []entity.Notificacion{}
+	for i, n := range m.notificaciones {
+		if n.Estado == EstadoPendiente && !n.FechaProximoIntento.After(fecha) && int64(len(result)) < limite {
+			m.notificaciones[i].FechaProximoIntento = &hasta
+			result = append(result, m.notificaciones[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) CrearNotificacion(ctx context.Context, notificacion entity.Notificacion) (entity.Notificacion, error) {
+	notificacion.IdNotificacion = len(m.notificaciones) + 1
+	m.notificaciones = append(m.notificaciones, notificacion)
+	return notificacion, nil
+}
+
+func (m *mockRepository) ActualizarEnvio(ctx context.Context, notificacion entity.Notificacion) error {
+	for i, n := range m.notificaciones {
+		if n.IdNotificacion == notificacion.IdNotificacion {
+			m.notificaciones[i] = notificacion
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// estados returns the estado of each notificacion by its ID.
+func (m *mockRepository) estados() map[int]string {
+	result := map[int]string{}
+	for _, n := range m.notificaciones {
+		result[n.IdNotificacion] = n.Estado
+	}
+	return result
+}
+
+func (m *mockRepository) GetPlantillas(ctx context.Context, query pagination.Query) ([]entity.PlantillaNotificacion, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.plantillas))
+	return m.plantillas, pages, nil
+}
+
+func (m *mockRepository) GetPlantilla(ctx context.Context, codigo, canal string) (entity.PlantillaNotificacion, error) {
+	for _, p := range m.plantillas {
+		if p.Codigo == codigo && p.Canal == canal {
+			return p, nil
+		}
+	}
+	return entity.PlantillaNotificacion{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetPlantillaPorId(ctx context.Context, idPlantillaNotificacion int) (entity.PlantillaNotificacion, error) {
+	for _, p := range m.plantillas {
+		if p.IdPlantillaNotificacion == idPlantillaNotificacion {
+			return p, nil
+		}
+	}
+	return entity.PlantillaNotificacion{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) ActualizarPlantilla(ctx context.Context, plantilla entity.PlantillaNotificacion) (entity.PlantillaNotificacion, error) {
+	return plantilla, nil
+}
+
+func (m *mockRepository) GetDestinatarios(ctx context.Context, query pagination.Query) ([]entity.DestinatarioAlerta, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.destinatarios))
+	return m.destinatarios, pages, nil
+}
+
+func (m *mockRepository) GetDestinatariosActivos(ctx context.Context, tipoAlerta string) ([]entity.DestinatarioAlerta, error) {
+	result := []entity.DestinatarioAlerta{}
+	for _, d := range m.destinatarios {
+		if d.TipoAlerta == tipoAlerta && d.Activo {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetDestinatarioPorId(ctx context.Context, idDestinatarioAlerta int) (entity.DestinatarioAlerta, error) {
+	for _, d := range m.destinatarios {
+		if d.IdDestinatarioAlerta == idDestinatarioAlerta {
+			return d, nil
+		}
+	}
+	return entity.DestinatarioAlerta{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) ActualizarDestinatario(ctx context.Context, destinatario entity.DestinatarioAlerta) (entity.DestinatarioAlerta, error) {
+	return destinatario, nil
+}
+
+func Test_mensajeWhatsApp(t *testing.T) {
+	fecha := time.Date(2026, 10, 4, 9, 0, 0, 0, time.UTC)
+	info := func(fromMe, group bool) types.MessageInfo {
+		return types.MessageInfo{
+			MessageSource: types.MessageSource{Sender: types.JID{User: "593999999999", Server: types.DefaultUserServer}, IsFromMe: fromMe, IsGroup: group},
+			Timestamp:     fecha,
+		}
+	}
+
+	mensaje, ok := mensajeWhatsApp(&events.Message{Info: info(false, false), Message: &waProto.Message{Conversation: proto.String("1")}})
+	assert.True(t, ok)
+	assert.Equal(t, MensajeRecibido{Canal: CanalWhatsApp, Remitente: "593999999999", Cuerpo: "1", Fecha: fecha}, mensaje)
+
+	mensaje, ok = mensajeWhatsApp(&events.Message{Info: info(false, false), Message: &waProto.Message{
+		ExtendedTextMessage: &waProto.ExtendedTextMessage{Text: proto.String("Cancelar por favor")},
+	}})
+	assert.True(t, ok)
+	assert.Equal(t, "Cancelar por favor", mensaje.Cuerpo)
+
+	for nombre, evt := range map[string]interface{}{
+		"propio":      &events.Message{Info: info(true, false), Message: &waProto.Message{Conversation: proto.String("1")}},
+		"grupo":       &events.Message{Info: info(false, true), Message: &waProto.Message{Conversation: proto.String("1")}},
+		"imagen":      &events.Message{Info: info(false, false), Message: &waProto.Message{ImageMessage: &waProto.ImageMessage{}}},
+		"recibo":      &events.Receipt{},
+		"desconexion": &events.Disconnected{},
+	} {
+		_, ok := mensajeWhatsApp(evt)
+		assert.False(t, ok, nombre)
+	}
+}
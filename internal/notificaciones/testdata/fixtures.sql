@@ -0,0 +1,10 @@
+-- Historial de un cliente con un recordatorio fallido y otro enviado, y una alerta sin cliente.
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001');
+
+INSERT INTO notificaciones (id_notificacion, id_cliente, tabla, id_referencia, canal, destinatario, codigo_plantilla, asunto, cuerpo, estado, intentos, ultimo_error, fecha_creacion, fecha_proximo_intento, fecha_envio) VALUES
+    (1, 1, 'CitaMedica', 1, 'whatsapp', '593911111111', 'recordatorio_cita', '', 'Recordatorio 1', 'FALLIDA', 5, 'timeout', '2026-10-01 10:00:00', NULL, NULL),
+    (2, 1, 'CitaMedica', 2, 'whatsapp', '593911111111', 'recordatorio_cita', '', 'Recordatorio 2', 'ENVIADA', 1, NULL, '2026-10-02 10:00:00', NULL, '2026-10-02 10:01:00'),
+    (3, NULL, NULL, NULL, 'whatsapp', '593969708327', 'poco_stock', '', 'Alerta', 'ENVIADA', 1, NULL, '2026-10-02 09:00:00', NULL, '2026-10-02 09:01:00'),
+    (4, NULL, NULL, NULL, 'whatsapp', '593969708327', 'poco_stock', '', 'Alerta', 'PENDIENTE', 0, NULL, '2026-10-03 09:00:00', '2026-10-03 09:00:00', NULL);
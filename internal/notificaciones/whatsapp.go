@@ -0,0 +1,125 @@
+package notificaciones
+
+import (
+	"context"
+	"os"
+	"sync"
+	"veterinaria-server/pkg/log"
+
+	"github.com/mdp/qrterminal"
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+	"google.golang.org/protobuf/proto"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type whatsApp struct {
+	mu     sync.Mutex
+	client *whatsmeow.Client
+}
+
+// NewWhatsApp creates a Notifier that sends the mensajes from the WhatsApp account of the client. The client
+// is connected again when the connection was lost.
+func NewWhatsApp(client *whatsmeow.Client) Notifier {
+	return &whatsApp{client: client}
+}
+
+func (w *whatsApp) Enviar(ctx context.Context, mensaje Mensaje) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.client.IsConnected() {
+		if err := w.client.Connect(); err != nil {
+			return err
+		}
+	}
+	_, err := w.client.SendMessage(types.JID{
+		User:   mensaje.Destinatario,
+		Server: types.DefaultUserServer,
+	}, "", &waProto.Message{
+		Conversation: proto.String(mensaje.Cuerpo),
+	})
+	return err
+}
+
+type fuenteWhatsApp struct {
+	client *whatsmeow.Client
+}
+
+// NewFuenteWhatsApp creates a Fuente with the text mensajes other users send to the WhatsApp account of the
+// client in private chats.
+func NewFuenteWhatsApp(client *whatsmeow.Client) Fuente {
+	return fuenteWhatsApp{client}
+}
+
+func (f fuenteWhatsApp) Escuchar(handler func(MensajeRecibido)) {
+	f.client.AddEventHandler(func(evt interface{}) {
+		if mensaje, ok := mensajeWhatsApp(evt); ok {
+			handler(mensaje)
+		}
+	})
+}
+
+// mensajeWhatsApp returns the mensaje of a WhatsApp event. It is false for the other events, the mensajes sent
+// by the account itself or to groups and the mensajes without text, such as images or stickers.
+func mensajeWhatsApp(evt interface{}) (MensajeRecibido, bool) {
+	m, ok := evt.(*events.Message)
+	if !ok || m.Info.IsFromMe || m.Info.IsGroup {
+		return MensajeRecibido{}, false
+	}
+	texto := m.Message.GetConversation()
+	if texto == "" {
+		texto = m.Message.GetExtendedTextMessage().GetText()
+	}
+	if texto == "" {
+		return MensajeRecibido{}, false
+	}
+	return MensajeRecibido{
+		Canal:     CanalWhatsApp,
+		Remitente: m.Info.Sender.User,
+		Cuerpo:    texto,
+		Fecha:     m.Info.Timestamp,
+	}, true
+}
+
+// ConectarWhatsApp connects to WhatsApp with the session kept in the SQLite file. When there is no session
+// yet, it prints the QR code to link the account in the terminal and waits until it is scanned. A stored session
+// that fails to connect is still returned, the Notifier connects again when it sends.
+func ConectarWhatsApp(archivo string, logger log.Logger) (*whatsmeow.Client, error) {
+	container, err := sqlstore.New("sqlite3", "file:"+archivo+"?_foreign_keys=on", waLog.Noop)
+	if err != nil {
+		return nil, err
+	}
+	deviceStore, err := container.GetFirstDevice()
+	if err != nil {
+		return nil, err
+	}
+	client := whatsmeow.NewClient(deviceStore, waLog.Noop)
+	if client.Store.ID != nil {
+		//El Notifier vuelve a conectar al enviar, no se pierde el canal por una falla de red al iniciar
+		if err := client.Connect(); err != nil {
+			logger.Errorf("failed to connect to WhatsApp: %s", err)
+		}
+		return client, nil
+	}
+	//Sin sesion guardada, se vincula la cuenta con el codigo QR
+	qrChan, err := client.GetQRChannel(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+	for evt := range qrChan {
+		if evt.Event == "code" {
+			qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+		} else {
+			logger.Infof("WhatsApp login event: %s", evt.Event)
+		}
+	}
+	return client, nil
+}
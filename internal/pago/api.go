@@ -0,0 +1,114 @@
+package pago
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/pagos/porFactura/<idFactura>", res.getPagosPorFactura)
+	r.Get("/pagos/porHospitalizacion/<idHospitalizacion>", res.getPagosPorHospitalizacion)
+	r.Get("/pagos/saldos", res.getSaldosClientes)
+	r.Get("/pagos/antiguedadSaldos", res.getAntiguedadSaldos)
+	r.Get("/pagos/antiguedadSaldos/<fecha>", res.getAntiguedadSaldos)
+	r.Post("/pagos", res.registrarPagos)
+	r.Get("/clientes/<idCliente>/saldo", res.getSaldoCliente)
+	r.Get("/clientes/<idCliente>/estadoCuenta/<desde>/<hasta>", res.getEstadoCuenta)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getPagosPorFactura(c *routing.Context) error {
+	idFactura, _ := strconv.Atoi(c.Param("idFactura"))
+	pagos, err := r.service.GetPagosPorFactura(c.Request.Context(), idFactura)
+	if err != nil {
+		return err
+	}
+	return c.Write(pagos)
+}
+
+func (r resource) getPagosPorHospitalizacion(c *routing.Context) error {
+	idHospitalizacion, _ := strconv.Atoi(c.Param("idHospitalizacion"))
+	pagos, err := r.service.GetPagosPorHospitalizacion(c.Request.Context(), idHospitalizacion)
+	if err != nil {
+		return err
+	}
+	return c.Write(pagos)
+}
+
+func (r resource) registrarPagos(c *routing.Context) error {
+	var input RegistrarPagosRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	pagos, err := r.service.RegistrarPagos(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(pagos, http.StatusCreated)
+}
+
+func (r resource) getSaldosClientes(c *routing.Context) error {
+	saldos, err := r.service.GetSaldosClientes(c.Request.Context())
+	if err != nil {
+		return err
+	}
+	return c.Write(saldos)
+}
+
+func (r resource) getSaldoCliente(c *routing.Context) error {
+	idCliente, _ := strconv.Atoi(c.Param("idCliente"))
+	saldo, err := r.service.GetSaldoCliente(c.Request.Context(), idCliente)
+	if err != nil {
+		return err
+	}
+	return c.Write(saldo)
+}
+
+func (r resource) getAntiguedadSaldos(c *routing.Context) error {
+	//Sin fecha de corte se calcula al momento actual
+	corte := time.Now()
+	if c.Param("fecha") != "" {
+		fecha, err := time.Parse("2006-01-02", c.Param("fecha"))
+		if err != nil {
+			return errors.BadRequest("La fecha de corte debe tener el formato AAAA-MM-DD.")
+		}
+		corte = fecha.Add(24*time.Hour - time.Nanosecond)
+	}
+	antiguedad, err := r.service.GetAntiguedadSaldos(c.Request.Context(), corte)
+	if err != nil {
+		return err
+	}
+	return c.Write(antiguedad)
+}
+
+func (r resource) getEstadoCuenta(c *routing.Context) error {
+	idCliente, _ := strconv.Atoi(c.Param("idCliente"))
+	desde, err := time.Parse("2006-01-02", c.Param("desde"))
+	if err != nil {
+		return errors.BadRequest("La fecha desde debe tener el formato AAAA-MM-DD.")
+	}
+	hasta, err := time.Parse("2006-01-02", c.Param("hasta"))
+	if err != nil {
+		return errors.BadRequest("La fecha hasta debe tener el formato AAAA-MM-DD.")
+	}
+	//Incluye todo el día final
+	estadoCuenta, err := r.service.GetEstadoCuenta(c.Request.Context(), idCliente, desde, hasta.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	return c.Write(estadoCuenta)
+}
@@ -0,0 +1,212 @@
+package pago
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// hospitalizacionSinFacturar filters the hospitalizaciones whose charges have not been moved to a factura
+// at discharge. Once billed, the factura is the documento that is owed.
+const hospitalizacionSinFacturar = "NOT EXISTS (SELECT 1 FROM facturas f WHERE f.origen = 'hospitalizacion' AND f.id_origen = h.id_hospitalizacion AND f.anulada = false)"
+
+// Repository encapsulates the logic to access pagos from the data source.
+type Repository interface {
+	GetPagosPorFactura(ctx context.Context, idFactura int) ([]entity.Pago, error)
+	GetPagosPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]entity.Pago, error)
+	CrearPago(ctx context.Context, pago entity.Pago) (entity.Pago, error)
+	// GetFacturaParaPago locks the factura and returns it with the amount already paid.
+	GetFacturaParaPago(ctx context.Context, idFactura int) (Documento, error)
+	// GetHospitalizacionParaPago locks the hospitalizacion and returns it with its abono as the amount already paid.
+	GetHospitalizacionParaPago(ctx context.Context, idHospitalizacion int) (Documento, error)
+	SumarAbonoHospitalizacion(ctx context.Context, idHospitalizacion int, monto money.Money) error
+	// AsignarFacturaPagosHospitalizacion links the pagos of the hospitalizacion to the factura that billed it.
+	AsignarFacturaPagosHospitalizacion(ctx context.Context, idHospitalizacion int, idFactura int) error
+	// GetDocumentosPendientes returns the facturas and hospitalizaciones with an outstanding balance at the corte,
+	// counting only the pagos made up to it. An idCliente of 0 returns the documentos of every cliente.
+	GetDocumentosPendientes(ctx context.Context, idCliente int, corte time.Time) ([]Documento, error)
+	// GetMovimientosCliente returns every cargo and abono of the cliente ordered by fecha.
+	GetMovimientosCliente(ctx context.Context, idCliente int) ([]MovimientoCuenta, error)
+	// GetIdSesionCajaAbierta returns the open caja session of the usuario, or nil when there is none.
+	GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error)
+}
+
+// repository persists pagos in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new pago repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+func (r repository) GetPagosPorFactura(ctx context.Context, idFactura int) ([]entity.Pago, error) {
+	var pagos []entity.Pago = []entity.Pago{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_factura": idFactura}).
+		OrderBy("fecha asc").
+		All(&pagos)
+	return pagos, err
+}
+
+func (r repository) GetPagosPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]entity.Pago, error) {
+	var pagos []entity.Pago = []entity.Pago{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_hospitalizacion": idHospitalizacion}).
+		OrderBy("fecha asc").
+		All(&pagos)
+	return pagos, err
+}
+
+// CrearPago saves a new Pago record in the database.
+// It returns the pago with the ID of the newly inserted record.
+func (r repository) CrearPago(ctx context.Context, pago entity.Pago) (entity.Pago, error) {
+	err := auditoria.Insertar(ctx, r.db, &pago)
+	if err != nil {
+		return entity.Pago{}, err
+	}
+	return pago, nil
+}
+
+func (r repository) GetFacturaParaPago(ctx context.Context, idFactura int) (Documento, error) {
+	var documento Documento
+	err := r.db.With(ctx).
+		NewQuery("SELECT 'factura' AS tipo, id_factura AS id_documento, id_cliente, fecha, valor, anulada AS anulado FROM facturas WHERE id_factura = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idFactura}).
+		One(&documento)
+	if err != nil {
+		return Documento{}, err
+	}
+	var pagado struct {
+		Total money.Money `db:"total"`
+	}
+	err = r.db.With(ctx).
+		Select("coalesce(sum(monto), 0) as total").
+		From("pagos").
+		Where(dbx.HashExp{"id_factura": idFactura}).
+		One(&pagado)
+	documento.Pagado = pagado.Total
+	return documento, err
+}
+
+func (r repository) GetHospitalizacionParaPago(ctx context.Context, idHospitalizacion int) (Documento, error) {
+	var documento Documento
+	err := r.db.With(ctx).
+		NewQuery("SELECT 'hospitalizacion' AS tipo, h.id_hospitalizacion AS id_documento, m.id_cliente, h.fecha_ingreso AS fecha, h.valor, h.abono AS pagado, " +
+			"NOT " + hospitalizacionSinFacturar + " AS facturado " +
+			"FROM hospitalizacion h " +
+			"JOIN consulta c ON c.id_consulta = h.id_consulta " +
+			"JOIN mascotas m ON m.id_mascota = c.id_mascota " +
+			"WHERE h.id_hospitalizacion = {:id} FOR UPDATE").
+		Bind(dbx.Params{"id": idHospitalizacion}).
+		One(&documento)
+	return documento, err
+}
+
+func (r repository) SumarAbonoHospitalizacion(ctx context.Context, idHospitalizacion int, monto money.Money) error {
+	hospitalizacion := entity.Hospitalizacion{IdHospitalizacion: idHospitalizacion}
+	return auditoria.ActualizarCon(ctx, r.db, &hospitalizacion, func() error {
+		_, err := r.db.With(ctx).
+			NewQuery("UPDATE hospitalizacion SET abono = abono + {:monto} WHERE id_hospitalizacion = {:id}").
+			Bind(dbx.Params{"monto": monto, "id": idHospitalizacion}).
+			Execute()
+		return err
+	})
+}
+
+func (r repository) AsignarFacturaPagosHospitalizacion(ctx context.Context, idHospitalizacion int, idFactura int) error {
+	var pagos []entity.Pago
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_hospitalizacion": idHospitalizacion}).
+		All(&pagos)
+	if err != nil {
+		return err
+	}
+	//Cada abono se actualiza por separado para que quede en la auditoría
+	for _, pago := range pagos {
+		pago.IdFactura = &idFactura
+		if err := auditoria.Actualizar(ctx, r.db, &pago, "IdFactura"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r repository) GetDocumentosPendientes(ctx context.Context, idCliente int, corte time.Time) ([]Documento, error) {
+	var documentos []Documento = []Documento{}
+	filtro := ""
+	if idCliente != 0 {
+		filtro = " AND d.id_cliente = {:idCliente}"
+	}
+	//El abono de la hospitalizacion es el total pagado, se le restan los pagos posteriores al corte
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM (" +
+			"SELECT 'factura' AS tipo, f.id_factura AS id_documento, f.id_cliente, f.fecha, f.valor, " +
+			"coalesce((SELECT sum(p.monto) FROM pagos p WHERE p.id_factura = f.id_factura AND p.fecha <= {:corte}), 0) AS pagado " +
+			"FROM facturas f WHERE f.anulada = false AND f.fecha <= {:corte} " +
+			"UNION ALL " +
+			"SELECT 'hospitalizacion', h.id_hospitalizacion, m.id_cliente, h.fecha_ingreso, h.valor, " +
+			"h.abono - coalesce((SELECT sum(p.monto) FROM pagos p WHERE p.id_hospitalizacion = h.id_hospitalizacion AND p.fecha > {:corte}), 0) " +
+			"FROM hospitalizacion h " +
+			"JOIN consulta c ON c.id_consulta = h.id_consulta " +
+			"JOIN mascotas m ON m.id_mascota = c.id_mascota " +
+			"WHERE h.fecha_ingreso <= {:corte} AND NOT EXISTS (SELECT 1 FROM facturas f WHERE f.origen = 'hospitalizacion' " +
+			"AND f.id_origen = h.id_hospitalizacion AND f.anulada = false AND f.fecha <= {:corte})" +
+			") d WHERE d.valor > d.pagado" + filtro + " ORDER BY d.id_cliente, d.fecha").
+		Bind(dbx.Params{"idCliente": idCliente, "corte": corte}).
+		All(&documentos)
+	return documentos, err
+}
+
+func (r repository) GetMovimientosCliente(ctx context.Context, idCliente int) ([]MovimientoCuenta, error) {
+	var movimientos []MovimientoCuenta = []MovimientoCuenta{}
+	err := r.db.With(ctx).
+		NewQuery("SELECT * FROM (" +
+			"SELECT 'factura' AS tipo, f.id_factura AS id_documento, f.fecha, 'Factura' AS detalle, f.valor AS cargo, 0 AS abono " +
+			"FROM facturas f WHERE f.anulada = false AND f.id_cliente = {:idCliente} " +
+			"UNION ALL " +
+			"SELECT 'hospitalizacion', h.id_hospitalizacion, h.fecha_ingreso, h.motivo, h.valor, 0 " +
+			"FROM hospitalizacion h JOIN consulta c ON c.id_consulta = h.id_consulta JOIN mascotas m ON m.id_mascota = c.id_mascota " +
+			"WHERE m.id_cliente = {:idCliente} AND " + hospitalizacionSinFacturar + " " +
+			"UNION ALL " +
+			//Abonos registrados en la hospitalización antes de existir los pagos
+			"SELECT 'abono_hospitalizacion', h.id_hospitalizacion, h.fecha_ingreso, 'Abono de hospitalización', 0, " +
+			"h.abono - coalesce((SELECT sum(p.monto) FROM pagos p WHERE p.id_hospitalizacion = h.id_hospitalizacion), 0) " +
+			"FROM hospitalizacion h JOIN consulta c ON c.id_consulta = h.id_consulta JOIN mascotas m ON m.id_mascota = c.id_mascota " +
+			"WHERE m.id_cliente = {:idCliente} AND " + hospitalizacionSinFacturar + " " +
+			"AND h.abono > coalesce((SELECT sum(p.monto) FROM pagos p WHERE p.id_hospitalizacion = h.id_hospitalizacion), 0) " +
+			"UNION ALL " +
+			"SELECT 'pago', p.id_pago, p.fecha, p.metodo_pago, 0, p.monto " +
+			"FROM pagos p WHERE p.id_cliente = {:idCliente}" +
+			") m ORDER BY m.fecha, m.tipo").
+		Bind(dbx.Params{"idCliente": idCliente}).
+		All(&movimientos)
+	return movimientos, err
+}
+
+func (r repository) GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error) {
+	var sesion entity.SesionCaja
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"id_usuario": idUsuario, "estado": "ABIERTA"}).
+		One(&sesion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sesion.IdSesionCaja, nil
+}
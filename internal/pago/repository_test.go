@@ -0,0 +1,42 @@
+package pago
+
+import (
+	"context"
+	"testing"
+	"time"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetDocumentosPendientes(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	repo := NewRepository(db, logger)
+	test.Fixtures(t, db, "testdata/fixtures.sql")
+	ctx := context.Background()
+	corte := time.Date(2022, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	documentos, err := repo.GetDocumentosPendientes(ctx, 0, corte)
+	assert.Nil(t, err)
+	if assert.Len(t, documentos, 2) {
+		//El pago posterior al corte no cuenta
+		assert.Equal(t, 2, documentos[0].IdDocumento)
+		assert.Equal(t, money.Money(600), documentos[0].Valor)
+		assert.Equal(t, money.Money(0), documentos[0].Pagado)
+		assert.Equal(t, 1, documentos[1].IdDocumento)
+	}
+
+	documentos, err = repo.GetDocumentosPendientes(ctx, 2, corte)
+	assert.Nil(t, err)
+	assert.Empty(t, documentos)
+
+	//Despues del pago la factura 2 queda saldada
+	documentos, err = repo.GetDocumentosPendientes(ctx, 1, corte.AddDate(0, 0, 5))
+	assert.Nil(t, err)
+	if assert.Len(t, documentos, 1) {
+		assert.Equal(t, 1, documentos[0].IdDocumento)
+	}
+}
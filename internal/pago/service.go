@@ -0,0 +1,408 @@
+package pago
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Métodos de pago aceptados.
+const (
+	MetodoEfectivo       = "efectivo"
+	MetodoTarjetaCredito = "tarjeta_credito"
+	MetodoTarjetaDebito  = "tarjeta_debito"
+	MetodoTransferencia  = "transferencia"
+	MetodoCheque         = "cheque"
+	// MetodoAbonoHospitalizacion records the abonos of a hospitalizacion registered before the
+	// pagos existed. It is not accepted from clients.
+	MetodoAbonoHospitalizacion = "abono_hospitalizacion"
+)
+
+// Tipos de documento que se pagan.
+const (
+	DocumentoFactura         = "factura"
+	DocumentoHospitalizacion = "hospitalizacion"
+)
+
+// Service encapsulates usecase logic for pagos.
+type Service interface {
+	GetPagosPorFactura(ctx context.Context, idFactura int) ([]Pago, error)
+	GetPagosPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]Pago, error)
+	// RegistrarPagos records one or more pagos, possibly with different métodos, against a factura or
+	// hospitalizacion. The pagos can not exceed the outstanding balance of the documento.
+	RegistrarPagos(ctx context.Context, input RegistrarPagosRequest) ([]Pago, error)
+	// AplicarAbonosHospitalizacion moves the abonos of a discharged hospitalizacion to the factura that billed it.
+	AplicarAbonosHospitalizacion(ctx context.Context, idHospitalizacion int, idFactura int) ([]Pago, error)
+	GetSaldoCliente(ctx context.Context, idCliente int) (SaldoCliente, error)
+	// GetSaldosClientes returns the outstanding balance of every cliente that owes something.
+	GetSaldosClientes(ctx context.Context) ([]SaldoCliente, error)
+	// GetAntiguedadSaldos classifies the outstanding balances by the days elapsed since each documento.
+	GetAntiguedadSaldos(ctx context.Context, corte time.Time) (AntiguedadSaldos, error)
+	// GetEstadoCuenta returns the cargos and abonos of the cliente between two dates with the running balance.
+	GetEstadoCuenta(ctx context.Context, idCliente int, desde time.Time, hasta time.Time) (EstadoCuenta, error)
+}
+
+// Pago represents the data about a pago.
+type Pago struct {
+	entity.Pago
+}
+
+// Documento is a factura or hospitalizacion that has to be paid.
+type Documento struct {
+	Tipo        string      `json:"tipo" db:"tipo"`
+	IdDocumento int         `json:"id_documento" db:"id_documento"`
+	IdCliente   int         `json:"id_cliente" db:"id_cliente"`
+	Fecha       time.Time   `json:"fecha" db:"fecha"`
+	Valor       money.Money `json:"valor" db:"valor"`
+	Pagado      money.Money `json:"pagado" db:"pagado"`
+	Anulado     bool        `json:"-" db:"anulado"`
+	Facturado   bool        `json:"-" db:"facturado"`
+}
+
+// Saldo returns the amount that is still owed.
+func (d Documento) Saldo() money.Money {
+	return d.Valor - d.Pagado
+}
+
+// DocumentoPendiente is a documento with its outstanding balance and age.
+type DocumentoPendiente struct {
+	Documento
+	Saldo money.Money `json:"saldo"`
+	Dias  int         `json:"dias"`
+}
+
+// SaldoCliente is the outstanding balance of a cliente.
+type SaldoCliente struct {
+	IdCliente  int                  `json:"id_cliente"`
+	Saldo      money.Money          `json:"saldo"`
+	Documentos []DocumentoPendiente `json:"documentos"`
+}
+
+// Antiguedad splits an outstanding balance into aging buckets.
+type Antiguedad struct {
+	De0A30  money.Money `json:"de_0_a_30"`
+	De31A60 money.Money `json:"de_31_a_60"`
+	De61A90 money.Money `json:"de_61_a_90"`
+	MasDe90 money.Money `json:"mas_de_90"`
+	Total   money.Money `json:"total"`
+}
+
+func (a *Antiguedad) sumar(dias int, saldo money.Money) {
+	switch {
+	case dias <= 30:
+		a.De0A30 += saldo
+	case dias <= 60:
+		a.De31A60 += saldo
+	case dias <= 90:
+		a.De61A90 += saldo
+	default:
+		a.MasDe90 += saldo
+	}
+	a.Total += saldo
+}
+
+// AntiguedadCliente is the aging of the balance of a cliente.
+type AntiguedadCliente struct {
+	IdCliente int `json:"id_cliente"`
+	Antiguedad
+}
+
+// AntiguedadSaldos is the aging report of the accounts receivable at a cutoff date.
+type AntiguedadSaldos struct {
+	Fecha    time.Time           `json:"fecha"`
+	Clientes []AntiguedadCliente `json:"clientes"`
+	Totales  Antiguedad          `json:"totales"`
+}
+
+// MovimientoCuenta is a cargo or abono in the estado de cuenta of a cliente.
+type MovimientoCuenta struct {
+	Tipo        string      `json:"tipo" db:"tipo"`
+	IdDocumento int         `json:"id_documento" db:"id_documento"`
+	Fecha       time.Time   `json:"fecha" db:"fecha"`
+	Detalle     string      `json:"detalle" db:"detalle"`
+	Cargo       money.Money `json:"cargo" db:"cargo"`
+	Abono       money.Money `json:"abono" db:"abono"`
+	Saldo       money.Money `json:"saldo" db:"-"`
+}
+
+// EstadoCuenta is the statement of a cliente for a period.
+type EstadoCuenta struct {
+	IdCliente    int                `json:"id_cliente"`
+	Desde        time.Time          `json:"desde"`
+	Hasta        time.Time          `json:"hasta"`
+	SaldoInicial money.Money        `json:"saldo_inicial"`
+	TotalCargos  money.Money        `json:"total_cargos"`
+	TotalAbonos  money.Money        `json:"total_abonos"`
+	SaldoFinal   money.Money        `json:"saldo_final"`
+	Movimientos  []MovimientoCuenta `json:"movimientos"`
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new pagos service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+// DetallePagoRequest is a single pago with one método.
+type DetallePagoRequest struct {
+	MetodoPago string      `json:"metodo_pago"`
+	Monto      money.Money `json:"monto"`
+	Referencia *string     `json:"referencia"`
+}
+
+// Validate validates the DetallePagoRequest fields.
+func (m DetallePagoRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.MetodoPago, validation.Required, validation.In(MetodoEfectivo, MetodoTarjetaCredito, MetodoTarjetaDebito, MetodoTransferencia, MetodoCheque)),
+		validation.Field(&m.Monto, validation.Required, validation.By(positivo)),
+		validation.Field(&m.Referencia, validation.NilOrNotEmpty, validation.Length(0, 100)),
+	)
+}
+
+// positivo validates that a money.Money amount is greater than zero.
+func positivo(value interface{}) error {
+	if monto, _ := value.(money.Money); monto <= 0 {
+		return validation.NewError("validation_min_greater_than_required", "must be greater than 0")
+	}
+	return nil
+}
+
+// RegistrarPagosRequest represents the pagos made to a factura or a hospitalizacion.
+type RegistrarPagosRequest struct {
+	IdFactura         *int                 `json:"id_factura"`
+	IdHospitalizacion *int                 `json:"id_hospitalizacion"`
+	Pagos             []DetallePagoRequest `json:"pagos"`
+}
+
+// Validate validates the RegistrarPagosRequest fields.
+func (m RegistrarPagosRequest) Validate() error {
+	if (m.IdFactura == nil) == (m.IdHospitalizacion == nil) {
+		return errors.BadRequest("Los pagos deben corresponder a una factura o a una hospitalización.")
+	}
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Pagos, validation.Required),
+	)
+}
+
+func (s service) GetPagosPorFactura(ctx context.Context, idFactura int) ([]Pago, error) {
+	pagos, err := s.repo.GetPagosPorFactura(ctx, idFactura)
+	if err != nil {
+		return nil, err
+	}
+	return toPagos(pagos), nil
+}
+
+func (s service) GetPagosPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]Pago, error) {
+	pagos, err := s.repo.GetPagosPorHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return nil, err
+	}
+	return toPagos(pagos), nil
+}
+
+func (s service) RegistrarPagos(ctx context.Context, req RegistrarPagosRequest) ([]Pago, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var documento Documento
+	var err error
+	if req.IdFactura != nil {
+		documento, err = s.repo.GetFacturaParaPago(ctx, *req.IdFactura)
+	} else {
+		documento, err = s.repo.GetHospitalizacionParaPago(ctx, *req.IdHospitalizacion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if documento.Anulado {
+		return nil, errors.Conflict("La factura está anulada.")
+	}
+	if documento.Facturado {
+		return nil, errors.Conflict("La hospitalización ya fue facturada, registre el pago en su factura.")
+	}
+
+	total := money.Zero
+	for _, detalle := range req.Pagos {
+		total += detalle.Monto
+	}
+	if total > documento.Saldo() {
+		return nil, errors.Conflict(fmt.Sprintf("Los pagos (%s) exceden el saldo pendiente (%s).", total, documento.Saldo()))
+	}
+
+	//Los pagos quedan en la caja abierta del usuario que los recibe
+	var idUsuario, idSesionCaja *int
+	if identity := auth.CurrentUser(ctx); identity != nil {
+		id := identity.GetIdUsuario()
+		idUsuario = &id
+		idSesionCaja, err = s.repo.GetIdSesionCajaAbierta(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pagosG := []Pago{}
+	for _, detalle := range req.Pagos {
+		pagoG, err := s.repo.CrearPago(ctx, entity.Pago{
+			IdCliente:         documento.IdCliente,
+			IdFactura:         req.IdFactura,
+			IdHospitalizacion: req.IdHospitalizacion,
+			IdUsuario:         idUsuario,
+			Fecha:             time.Now(),
+			MetodoPago:        detalle.MetodoPago,
+			Monto:             detalle.Monto,
+			Referencia:        detalle.Referencia,
+			IdSesionCaja:      idSesionCaja,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pagosG = append(pagosG, Pago{pagoG})
+	}
+	//El abono de la hospitalización se mantiene como el total pagado
+	if req.IdHospitalizacion != nil {
+		if err := s.repo.SumarAbonoHospitalizacion(ctx, *req.IdHospitalizacion, total); err != nil {
+			return nil, err
+		}
+	}
+	return pagosG, nil
+}
+
+func (s service) AplicarAbonosHospitalizacion(ctx context.Context, idHospitalizacion int, idFactura int) ([]Pago, error) {
+	documento, err := s.repo.GetHospitalizacionParaPago(ctx, idHospitalizacion)
+	if err != nil {
+		return nil, err
+	}
+	pagos, err := s.repo.GetPagosPorHospitalizacion(ctx, idHospitalizacion)
+	if err != nil {
+		return nil, err
+	}
+	//El abono registrado directamente en la hospitalización se conserva como un pago
+	abonoPrevio := documento.Pagado
+	for _, pago := range pagos {
+		abonoPrevio -= pago.Monto
+	}
+	if abonoPrevio > 0 {
+		pagoG, err := s.repo.CrearPago(ctx, entity.Pago{
+			IdCliente:         documento.IdCliente,
+			IdHospitalizacion: &idHospitalizacion,
+			Fecha:             documento.Fecha,
+			MetodoPago:        MetodoAbonoHospitalizacion,
+			Monto:             abonoPrevio,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pagos = append(pagos, pagoG)
+	}
+	if err := s.repo.AsignarFacturaPagosHospitalizacion(ctx, idHospitalizacion, idFactura); err != nil {
+		return nil, err
+	}
+	result := toPagos(pagos)
+	for i := range result {
+		result[i].IdFactura = &idFactura
+	}
+	return result, nil
+}
+
+func (s service) GetSaldoCliente(ctx context.Context, idCliente int) (SaldoCliente, error) {
+	ahora := time.Now()
+	documentos, err := s.repo.GetDocumentosPendientes(ctx, idCliente, ahora)
+	if err != nil {
+		return SaldoCliente{}, err
+	}
+	saldos := saldosPorCliente(documentos, ahora)
+	if len(saldos) == 0 {
+		return SaldoCliente{IdCliente: idCliente, Documentos: []DocumentoPendiente{}}, nil
+	}
+	return saldos[0], nil
+}
+
+func (s service) GetSaldosClientes(ctx context.Context) ([]SaldoCliente, error) {
+	ahora := time.Now()
+	documentos, err := s.repo.GetDocumentosPendientes(ctx, 0, ahora)
+	if err != nil {
+		return nil, err
+	}
+	return saldosPorCliente(documentos, ahora), nil
+}
+
+func (s service) GetAntiguedadSaldos(ctx context.Context, corte time.Time) (AntiguedadSaldos, error) {
+	documentos, err := s.repo.GetDocumentosPendientes(ctx, 0, corte)
+	if err != nil {
+		return AntiguedadSaldos{}, err
+	}
+	result := AntiguedadSaldos{Fecha: corte, Clientes: []AntiguedadCliente{}}
+	for _, saldo := range saldosPorCliente(documentos, corte) {
+		cliente := AntiguedadCliente{IdCliente: saldo.IdCliente}
+		for _, documento := range saldo.Documentos {
+			cliente.sumar(documento.Dias, documento.Saldo)
+			result.Totales.sumar(documento.Dias, documento.Saldo)
+		}
+		result.Clientes = append(result.Clientes, cliente)
+	}
+	return result, nil
+}
+
+func (s service) GetEstadoCuenta(ctx context.Context, idCliente int, desde time.Time, hasta time.Time) (EstadoCuenta, error) {
+	movimientos, err := s.repo.GetMovimientosCliente(ctx, idCliente)
+	if err != nil {
+		return EstadoCuenta{}, err
+	}
+	result := EstadoCuenta{IdCliente: idCliente, Desde: desde, Hasta: hasta, Movimientos: []MovimientoCuenta{}}
+	for _, movimiento := range movimientos {
+		if movimiento.Fecha.After(hasta) {
+			break
+		}
+		if movimiento.Fecha.Before(desde) {
+			result.SaldoInicial += movimiento.Cargo - movimiento.Abono
+			continue
+		}
+		result.TotalCargos += movimiento.Cargo
+		result.TotalAbonos += movimiento.Abono
+		movimiento.Saldo = result.SaldoInicial + result.TotalCargos - result.TotalAbonos
+		result.Movimientos = append(result.Movimientos, movimiento)
+	}
+	result.SaldoFinal = result.SaldoInicial + result.TotalCargos - result.TotalAbonos
+	return result, nil
+}
+
+// saldosPorCliente groups the documentos, ordered by cliente, and computes their age at the cutoff date.
+// Documentos issued after the cutoff are left out.
+func saldosPorCliente(documentos []Documento, corte time.Time) []SaldoCliente {
+	saldos := []SaldoCliente{}
+	for _, documento := range documentos {
+		if documento.Fecha.After(corte) || documento.Saldo() <= 0 {
+			continue
+		}
+		if len(saldos) == 0 || saldos[len(saldos)-1].IdCliente != documento.IdCliente {
+			saldos = append(saldos, SaldoCliente{IdCliente: documento.IdCliente, Documentos: []DocumentoPendiente{}})
+		}
+		saldo := &saldos[len(saldos)-1]
+		saldo.Saldo += documento.Saldo()
+		saldo.Documentos = append(saldo.Documentos, DocumentoPendiente{
+			Documento: documento,
+			Saldo:     documento.Saldo(),
+			Dias:      int(corte.Sub(documento.Fecha).Hours() / 24),
+		})
+	}
+	return saldos
+}
+
+func toPagos(pagos []entity.Pago) []Pago {
+	result := []Pago{}
+	for _, item := range pagos {
+		result = append(result, Pago{item})
+	}
+	return result
+}
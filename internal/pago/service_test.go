@@ -0,0 +1,209 @@
+package pago
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_RegistrarPagos(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{documentos: []Documento{
+		{Tipo: DocumentoFactura, IdDocumento: 1, IdCliente: 3, Valor: 5000},
+		{Tipo: DocumentoFactura, IdDocumento: 2, IdCliente: 3, Valor: 5000, Anulado: true},
+		{Tipo: DocumentoHospitalizacion, IdDocumento: 1, IdCliente: 4, Valor: 20000, Pagado: 5000},
+	}}
+	s := NewService(repo, logger)
+	ctx := context.Background()
+	idFactura, idAnulada, idHospitalizacion := 1, 2, 1
+
+	pagos, err := s.RegistrarPagos(ctx, RegistrarPagosRequest{IdFactura: &idFactura, Pagos: []DetallePagoRequest{
+		{MetodoPago: MetodoEfectivo, Monto: 2000},
+		{MetodoPago: MetodoTarjetaCredito, Monto: 1000},
+	}})
+	assert.Nil(t, err)
+	assert.Len(t, pagos, 2)
+	assert.Equal(t, 3, pagos[0].IdCliente)
+
+	// excede el saldo pendiente
+	_, err = s.RegistrarPagos(ctx, RegistrarPagosRequest{IdFactura: &idFactura, Pagos: []DetallePagoRequest{{MetodoPago: MetodoEfectivo, Monto: 2001}}})
+	assert.NotNil(t, err)
+	// factura anulada
+	_, err = s.RegistrarPagos(ctx, RegistrarPagosRequest{IdFactura: &idAnulada, Pagos: []DetallePagoRequest{{MetodoPago: MetodoEfectivo, Monto: 100}}})
+	assert.NotNil(t, err)
+	// método desconocido
+	_, err = s.RegistrarPagos(ctx, RegistrarPagosRequest{IdFactura: &idFactura, Pagos: []DetallePagoRequest{{MetodoPago: "bitcoin", Monto: 100}}})
+	assert.NotNil(t, err)
+	// sin documento
+	_, err = s.RegistrarPagos(ctx, RegistrarPagosRequest{Pagos: []DetallePagoRequest{{MetodoPago: MetodoEfectivo, Monto: 100}}})
+	assert.NotNil(t, err)
+
+	_, err = s.RegistrarPagos(ctx, RegistrarPagosRequest{IdHospitalizacion: &idHospitalizacion, Pagos: []DetallePagoRequest{{MetodoPago: MetodoTransferencia, Monto: 15000}}})
+	assert.Nil(t, err)
+	assert.Equal(t, money.Money(20000), repo.documentos[2].Pagado)
+}
+
+func Test_service_AplicarAbonosHospitalizacion(t *testing.T) {
+	logger, _ := log.NewForTest()
+	idHospitalizacion := 1
+	repo := &mockRepository{
+		documentos: []Documento{{Tipo: DocumentoHospitalizacion, IdDocumento: 1, IdCliente: 4, Valor: 20000, Pagado: 8000}},
+		pagos:      []entity.Pago{{IdPago: 1, IdCliente: 4, IdHospitalizacion: &idHospitalizacion, MetodoPago: MetodoEfectivo, Monto: 5000}},
+	}
+	s := NewService(repo, logger)
+
+	pagos, err := s.AplicarAbonosHospitalizacion(context.Background(), 1, 9)
+	assert.Nil(t, err)
+	assert.Len(t, pagos, 2)
+	assert.Equal(t, MetodoAbonoHospitalizacion, pagos[1].MetodoPago)
+	assert.Equal(t, money.Money(3000), pagos[1].Monto)
+	for _, pago := range repo.pagos {
+		assert.Equal(t, 9, *pago.IdFactura)
+	}
+
+	// la hospitalización facturada ya no recibe pagos
+	repo.documentos[0].Facturado = true
+	_, err = s.RegistrarPagos(context.Background(), RegistrarPagosRequest{IdHospitalizacion: &idHospitalizacion, Pagos: []DetallePagoRequest{{MetodoPago: MetodoEfectivo, Monto: 100}}})
+	assert.NotNil(t, err)
+}
+
+func Test_service_GetAntiguedadSaldos(t *testing.T) {
+	logger, _ := log.NewForTest()
+	corte := time.Date(2022, 6, 30, 23, 59, 59, 0, time.UTC)
+	dias := func(n int) time.Time { return corte.AddDate(0, 0, -n) }
+	repo := &mockRepository{documentos: []Documento{
+		{Tipo: DocumentoFactura, IdDocumento: 1, IdCliente: 3, Fecha: dias(10), Valor: 1000},
+		{Tipo: DocumentoFactura, IdDocumento: 2, IdCliente: 3, Fecha: dias(45), Valor: 2000, Pagado: 500},
+		{Tipo: DocumentoHospitalizacion, IdDocumento: 1, IdCliente: 3, Fecha: dias(120), Valor: 3000},
+		{Tipo: DocumentoFactura, IdDocumento: 3, IdCliente: 4, Fecha: dias(75), Valor: 400},
+		{Tipo: DocumentoFactura, IdDocumento: 4, IdCliente: 4, Fecha: dias(-1), Valor: 900},
+		//Pagada despues del corte, el repositorio solo cuenta lo pagado al corte
+		{Tipo: DocumentoFactura, IdDocumento: 5, IdCliente: 4, Fecha: dias(20), Valor: 600},
+	}}
+	s := NewService(repo, logger)
+
+	antiguedad, err := s.GetAntiguedadSaldos(context.Background(), corte)
+	assert.Nil(t, err)
+	assert.Len(t, antiguedad.Clientes, 2)
+	assert.Equal(t, Antiguedad{De0A30: 1000, De31A60: 1500, MasDe90: 3000, Total: 5500}, antiguedad.Clientes[0].Antiguedad)
+	assert.Equal(t, Antiguedad{De0A30: 600, De61A90: 400, Total: 1000}, antiguedad.Clientes[1].Antiguedad)
+	assert.Equal(t, money.Money(6500), antiguedad.Totales.Total)
+}
+
+func Test_service_GetEstadoCuenta(t *testing.T) {
+	logger, _ := log.NewForTest()
+	fecha := func(d int) time.Time { return time.Date(2022, 6, d, 10, 0, 0, 0, time.UTC) }
+	repo := &mockRepository{movimientos: []MovimientoCuenta{
+		{Tipo: DocumentoFactura, IdDocumento: 1, Fecha: fecha(1), Cargo: 5000},
+		{Tipo: "pago", IdDocumento: 1, Fecha: fecha(2), Abono: 2000},
+		{Tipo: DocumentoFactura, IdDocumento: 2, Fecha: fecha(10), Cargo: 1000},
+		{Tipo: "pago", IdDocumento: 2, Fecha: fecha(12), Abono: 3000},
+		{Tipo: DocumentoFactura, IdDocumento: 3, Fecha: fecha(25), Cargo: 700},
+	}}
+	s := NewService(repo, logger)
+
+	estado, err := s.GetEstadoCuenta(context.Background(), 3, fecha(5), fecha(20))
+	assert.Nil(t, err)
+	assert.Equal(t, money.Money(3000), estado.SaldoInicial)
+	assert.Equal(t, money.Money(1000), estado.TotalCargos)
+	assert.Equal(t, money.Money(3000), estado.TotalAbonos)
+	assert.Equal(t, money.Money(1000), estado.SaldoFinal)
+	assert.Len(t, estado.Movimientos, 2)
+	assert.Equal(t, money.Money(4000), estado.Movimientos[0].Saldo)
+}
+
+type mockRepository struct {
+	documentos  []Documento
+	movimientos []MovimientoCuenta
+	pagos       []entity.Pago
+}
+
+func (m *mockRepository) GetPagosPorFactura(ctx context.Context, idFactura int) ([]entity.Pago, error) {
+	result := []entity.Pago{}
+	for _, pago := range m.pagos {
+		if pago.IdFactura != nil && *pago.IdFactura == idFactura {
+			result = append(result, pago)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetPagosPorHospitalizacion(ctx context.Context, idHospitalizacion int) ([]entity.Pago, error) {
+	result := []entity.Pago{}
+	for _, pago := range m.pagos {
+		if pago.IdHospitalizacion != nil && *pago.IdHospitalizacion == idHospitalizacion {
+			result = append(result, pago)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) CrearPago(ctx context.Context, pago entity.Pago) (entity.Pago, error) {
+	pago.IdPago = len(m.pagos) + 1
+	m.pagos = append(m.pagos, pago)
+	if pago.IdFactura != nil {
+		m.documento(DocumentoFactura, *pago.IdFactura).Pagado += pago.Monto
+	}
+	return pago, nil
+}
+
+func (m *mockRepository) GetFacturaParaPago(ctx context.Context, idFactura int) (Documento, error) {
+	if documento := m.documento(DocumentoFactura, idFactura); documento != nil {
+		return *documento, nil
+	}
+	return Documento{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetHospitalizacionParaPago(ctx context.Context, idHospitalizacion int) (Documento, error) {
+	if documento := m.documento(DocumentoHospitalizacion, idHospitalizacion); documento != nil {
+		return *documento, nil
+	}
+	return Documento{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) SumarAbonoHospitalizacion(ctx context.Context, idHospitalizacion int, monto money.Money) error {
+	m.documento(DocumentoHospitalizacion, idHospitalizacion).Pagado += monto
+	return nil
+}
+
+func (m *mockRepository) AsignarFacturaPagosHospitalizacion(ctx context.Context, idHospitalizacion int, idFactura int) error {
+	for i, pago := range m.pagos {
+		if pago.IdHospitalizacion != nil && *pago.IdHospitalizacion == idHospitalizacion {
+			m.pagos[i].IdFactura = &idFactura
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) GetDocumentosPendientes(ctx context.Context, idCliente int, corte time.Time) ([]Documento, error) {
+	result := []Documento{}
+	for _, documento := range m.documentos {
+		if !documento.Anulado && documento.Saldo() > 0 && (idCliente == 0 || documento.IdCliente == idCliente) {
+			result = append(result, documento)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetMovimientosCliente(ctx context.Context, idCliente int) ([]MovimientoCuenta, error) {
+	return m.movimientos, nil
+}
+
+func (m *mockRepository) documento(tipo string, id int) *Documento {
+	for i := range m.documentos {
+		if m.documentos[i].Tipo == tipo && m.documentos[i].IdDocumento == id {
+			return &m.documentos[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) GetIdSesionCajaAbierta(ctx context.Context, idUsuario int) (*int, error) {
+	return nil, nil
+}
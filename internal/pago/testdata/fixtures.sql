@@ -0,0 +1,20 @@
+-- Facturas de dos clientes alrededor del corte del 2022-06-30.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001'),
+    (2, 'Luis', 'Mera', '0900000002');
+
+-- La 2 se pagó después del corte, la 3 antes, la 4 es posterior al corte y la 5 está anulada.
+INSERT INTO facturas (id_factura, id_cliente, id_usuario, fecha, valor, anulada) VALUES
+    (1, 1, 100, '2022-06-20 10:00:00', 10.00, 0),
+    (2, 1, 100, '2022-06-10 10:00:00', 6.00, 0),
+    (3, 2, 100, '2022-05-01 10:00:00', 4.00, 0),
+    (4, 2, 100, '2022-07-02 10:00:00', 9.00, 0),
+    (5, 2, 100, '2022-06-01 10:00:00', 7.00, 1);
+
+INSERT INTO pagos (id_pago, id_cliente, id_factura, id_usuario, fecha, metodo_pago, monto) VALUES
+    (1, 1, 2, 100, '2022-07-03 10:00:00', 'efectivo', 6.00),
+    (2, 2, 3, 100, '2022-06-15 10:00:00', 'efectivo', 4.00);
@@ -0,0 +1,57 @@
+package permiso
+
+import (
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/permisos", res.getPermisos)
+	r.Get("/roles/<idRol>/permisos", res.getPermisosPorRol)
+	r.Put("/roles/<idRol>/permisos", res.asignarPermisosRol)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getPermisos(c *routing.Context) error {
+	pages, err := r.service.GetPermisos(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getPermisosPorRol(c *routing.Context) error {
+	idRol, _ := strconv.Atoi(c.Param("idRol"))
+	permisos, err := r.service.GetPermisosPorRol(c.Request.Context(), idRol)
+	if err != nil {
+		return err
+	}
+	return c.Write(permisos)
+}
+
+func (r resource) asignarPermisosRol(c *routing.Context) error {
+	idRol, _ := strconv.Atoi(c.Param("idRol"))
+	var input AsignarPermisosRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	permisos, err := r.service.AsignarPermisosRol(c.Request.Context(), idRol, input)
+	if err != nil {
+		return err
+	}
+	return c.Write(permisos)
+}
@@ -0,0 +1,133 @@
+package permiso
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access permisos from the data source.
+type Repository interface {
+	// GetPermisos returns the list permisos.
+	GetPermisos(ctx context.Context, query pagination.Query) ([]entity.Permiso, *pagination.Pages, error)
+	// GetPermisosPorId returns the permisos with the given IDs that exist.
+	GetPermisosPorId(ctx context.Context, idPermisos []int) ([]entity.Permiso, error)
+	// GetPermisosPorRol returns the permisos granted to the rol.
+	GetPermisosPorRol(ctx context.Context, idRol int) ([]entity.Permiso, error)
+	GetRolPorId(ctx context.Context, idRol int) (entity.Rol, error)
+	// ReemplazarPermisosRol replaces the permisos granted to the rol with the given ones.
+	ReemplazarPermisosRol(ctx context.Context, idRol int, idPermisos []int) error
+	// ContarPermisos counts the codigos granted to the active roles.
+	ContarPermisos(ctx context.Context, roles []int, codigos []string) (int, error)
+}
+
+// repository persists permisos in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new permiso repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposPermisos are the fields the list of permisos can be filtered, searched and sorted by.
+var camposPermisos = pagination.Fields{
+	Search: []string{"codigo", "descripcion"},
+	Sort: map[string]string{
+		"id_permiso":  "id_permiso",
+		"codigo":      "codigo",
+		"descripcion": "descripcion",
+	},
+	DefaultSort: []string{"codigo asc"},
+}
+
+func (r repository) GetPermisos(ctx context.Context, query pagination.Query) ([]entity.Permiso, *pagination.Pages, error) {
+	var permisos []entity.Permiso = []entity.Permiso{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("permisos"), camposPermisos, &permisos)
+	if err != nil {
+		return nil, nil, err
+	}
+	return permisos, pages, nil
+}
+
+func (r repository) GetPermisosPorId(ctx context.Context, idPermisos []int) ([]entity.Permiso, error) {
+	var permisos []entity.Permiso = []entity.Permiso{}
+	if len(idPermisos) == 0 {
+		return permisos, nil
+	}
+	ids := []interface{}{}
+	for _, idPermiso := range idPermisos {
+		ids = append(ids, idPermiso)
+	}
+	err := r.db.With(ctx).
+		Select().
+		From("permisos").
+		Where(dbx.In("id_permiso", ids...)).
+		All(&permisos)
+	return permisos, err
+}
+
+func (r repository) GetPermisosPorRol(ctx context.Context, idRol int) ([]entity.Permiso, error) {
+	var permisos []entity.Permiso = []entity.Permiso{}
+	err := r.db.With(ctx).
+		Select("p.*").
+		From("permisos as p").
+		InnerJoin("rol_permiso as rp", dbx.NewExp("rp.id_permiso = p.id_permiso")).
+		Where(dbx.HashExp{"rp.id_rol": idRol}).
+		OrderBy("p.codigo asc").
+		All(&permisos)
+	return permisos, err
+}
+
+func (r repository) GetRolPorId(ctx context.Context, idRol int) (entity.Rol, error) {
+	var rol entity.Rol
+	err := r.db.With(ctx).Select().Model(idRol, &rol)
+	return rol, err
+}
+
+func (r repository) ReemplazarPermisosRol(ctx context.Context, idRol int, idPermisos []int) error {
+	_, err := r.db.With(ctx).Delete("rol_permiso", dbx.HashExp{"id_rol": idRol}).Execute()
+	if err != nil {
+		return err
+	}
+	for _, idPermiso := range idPermisos {
+		rolPermiso := entity.RolPermiso{IdRol: idRol, IdPermiso: idPermiso}
+		if err := auditoria.Insertar(ctx, r.db, &rolPermiso); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r repository) ContarPermisos(ctx context.Context, roles []int, codigos []string) (int, error) {
+	var permisos int
+	if len(roles) == 0 || len(codigos) == 0 {
+		return 0, nil
+	}
+	idRoles := make([]interface{}, len(roles))
+	for i, idRol := range roles {
+		idRoles[i] = idRol
+	}
+	codigosIn := make([]interface{}, len(codigos))
+	for i, codigo := range codigos {
+		codigosIn[i] = codigo
+	}
+	err := r.db.With(ctx).
+		Select("count(*)").
+		From("rol_permiso as rp").
+		InnerJoin("permisos as p", dbx.NewExp("p.id_permiso = rp.id_permiso")).
+		InnerJoin("roles as r", dbx.NewExp("r.id_rol = rp.id_rol")).
+		Where(dbx.In("rp.id_rol", idRoles...)).
+		AndWhere(dbx.In("p.codigo", codigosIn...)).
+		AndWhere(dbx.HashExp{"r.estado": true}).
+		Row(&permisos)
+	return permisos, err
+}
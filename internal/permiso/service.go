@@ -0,0 +1,124 @@
+package permiso
+
+import (
+	"context"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// Modulos of the API guarded by permisos. Each one has a leer and an escribir permiso,
+// e.g. "facturacion.leer" and "facturacion.escribir".
+const (
+	ModuloAlbums            = "albums"
+	ModuloCatalogos         = "catalogos"
+	ModuloClientes          = "clientes"
+	ModuloConsultas         = "consultas"
+	ModuloCitas             = "citas"
+	ModuloHospitalizaciones = "hospitalizaciones"
+	ModuloFacturacion       = "facturacion"
+	ModuloPagos             = "pagos"
+	ModuloCaja              = "caja"
+	ModuloInventario        = "inventario"
+	ModuloUsuarios          = "usuarios"
+	ModuloRoles             = "roles"
+	ModuloAuditoria         = "auditoria"
+	ModuloNotificaciones    = "notificaciones"
+)
+
+// Service encapsulates usecase logic for permisos.
+type Service interface {
+	GetPermisos(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	GetPermisosPorRol(ctx context.Context, idRol int) ([]Permiso, error)
+	// AsignarPermisosRol replaces the permisos granted to the rol.
+	AsignarPermisosRol(ctx context.Context, idRol int, input AsignarPermisosRequest) ([]Permiso, error)
+	// TienePermiso reports whether any of the active roles is granted any of the codigos.
+	TienePermiso(ctx context.Context, roles []int, codigos []string) (bool, error)
+}
+
+// Permiso represents the data about a permiso.
+type Permiso struct {
+	entity.Permiso
+}
+
+// AsignarPermisosRequest represents the permisos to grant to a rol.
+type AsignarPermisosRequest struct {
+	Permisos []int `json:"permisos"`
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new permisos service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+func (s service) GetPermisos(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	permisos, pages, err := s.repo.GetPermisos(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Permiso{}
+	for _, item := range permisos {
+		result = append(result, Permiso{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetPermisosPorRol(ctx context.Context, idRol int) ([]Permiso, error) {
+	if _, err := s.repo.GetRolPorId(ctx, idRol); err != nil {
+		return nil, err
+	}
+	permisos, err := s.repo.GetPermisosPorRol(ctx, idRol)
+	if err != nil {
+		return nil, err
+	}
+	result := []Permiso{}
+	for _, item := range permisos {
+		result = append(result, Permiso{item})
+	}
+	return result, nil
+}
+
+func (s service) AsignarPermisosRol(ctx context.Context, idRol int, req AsignarPermisosRequest) ([]Permiso, error) {
+	if _, err := s.repo.GetRolPorId(ctx, idRol); err != nil {
+		return nil, err
+	}
+	permisos, err := s.repo.GetPermisosPorId(ctx, req.Permisos)
+	if err != nil {
+		return nil, err
+	}
+	existentes := map[int]bool{}
+	for _, permiso := range permisos {
+		existentes[permiso.IdPermiso] = true
+	}
+	//Se ignoran los permisos repetidos
+	asignados := map[int]bool{}
+	idPermisos := []int{}
+	for _, idPermiso := range req.Permisos {
+		if !existentes[idPermiso] {
+			return nil, errors.BadRequest("El permiso no existe.")
+		}
+		if !asignados[idPermiso] {
+			asignados[idPermiso] = true
+			idPermisos = append(idPermisos, idPermiso)
+		}
+	}
+	if err := s.repo.ReemplazarPermisosRol(ctx, idRol, idPermisos); err != nil {
+		return nil, err
+	}
+	return s.GetPermisosPorRol(ctx, idRol)
+}
+
+func (s service) TienePermiso(ctx context.Context, roles []int, codigos []string) (bool, error) {
+	permisos, err := s.repo.ContarPermisos(ctx, roles, codigos)
+	if err != nil {
+		return false, err
+	}
+	return permisos > 0, nil
+}
@@ -0,0 +1,99 @@
+package permiso
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_AsignarPermisosRol(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{
+		permisos: []entity.Permiso{
+			{IdPermiso: 1, Codigo: "facturacion.leer"},
+			{IdPermiso: 2, Codigo: "facturacion.escribir"},
+		},
+		asignados: map[int][]int{},
+	}
+	s := NewService(repo, logger)
+
+	_, err := s.AsignarPermisosRol(context.Background(), 3, AsignarPermisosRequest{Permisos: []int{1}})
+	assert.Equal(t, sql.ErrNoRows, err)
+	_, err = s.AsignarPermisosRol(context.Background(), 7, AsignarPermisosRequest{Permisos: []int{9}})
+	assert.NotNil(t, err)
+
+	permisos, err := s.AsignarPermisosRol(context.Background(), 7, AsignarPermisosRequest{Permisos: []int{2, 2}})
+	assert.Nil(t, err)
+	if assert.Len(t, permisos, 1) {
+		assert.Equal(t, "facturacion.escribir", permisos[0].Codigo)
+	}
+
+	permitido, err := s.TienePermiso(context.Background(), []int{7}, []string{"facturacion.escribir", "*"})
+	assert.Nil(t, err)
+	assert.True(t, permitido)
+	permitido, _ = s.TienePermiso(context.Background(), []int{7}, []string{"pagos.leer"})
+	assert.False(t, permitido)
+}
+
+type mockRepository struct {
+	permisos  []entity.Permiso
+	asignados map[int][]int
+}
+
+func (m *mockRepository) GetPermisos(ctx context.Context, query pagination.Query) ([]entity.Permiso, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.permisos))
+	pages.Items = m.permisos
+	return m.permisos, pages, nil
+}
+
+func (m *mockRepository) GetPermisosPorId(ctx context.Context, idPermisos []int) ([]entity.Permiso, error) {
+	permisos := []entity.Permiso{}
+	for _, permiso := range m.permisos {
+		for _, idPermiso := range idPermisos {
+			if permiso.IdPermiso == idPermiso {
+				permisos = append(permisos, permiso)
+				break
+			}
+		}
+	}
+	return permisos, nil
+}
+
+func (m *mockRepository) GetPermisosPorRol(ctx context.Context, idRol int) ([]entity.Permiso, error) {
+	permisos := []entity.Permiso{}
+	for _, idPermiso := range m.asignados[idRol] {
+		permisos = append(permisos, m.permisos[idPermiso-1])
+	}
+	return permisos, nil
+}
+
+func (m *mockRepository) GetRolPorId(ctx context.Context, idRol int) (entity.Rol, error) {
+	if idRol != 7 {
+		return entity.Rol{}, sql.ErrNoRows
+	}
+	return entity.Rol{IdRol: idRol, Descripcion: "Recepción"}, nil
+}
+
+func (m *mockRepository) ReemplazarPermisosRol(ctx context.Context, idRol int, idPermisos []int) error {
+	m.asignados[idRol] = idPermisos
+	return nil
+}
+
+func (m *mockRepository) ContarPermisos(ctx context.Context, roles []int, codigos []string) (int, error) {
+	count := 0
+	for _, idRol := range roles {
+		for _, idPermiso := range m.asignados[idRol] {
+			for _, codigo := range codigos {
+				if m.permisos[idPermiso-1].Codigo == codigo {
+					count++
+				}
+			}
+		}
+	}
+	return count, nil
+}
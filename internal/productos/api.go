@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -35,11 +36,12 @@ type resource struct {
 }
 
 func (r resource) getProductos(c *routing.Context) error {
-	productos, err := r.service.GetProductos(c.Request.Context())
+	pages, err := r.service.GetProductos(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(productos)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getProductosSinAsignarAProveedor(c *routing.Context) error {
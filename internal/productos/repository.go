@@ -3,9 +3,11 @@ package productos
 import (
 	"context"
 	"strconv"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	// GetProductoPorId returns the producto with the specified producto ID.
 	GetProductoPorId(ctx context.Context, idProducto int) (entity.Producto, error)
 	// GetProductos returns the list productos.
-	GetProductos(ctx context.Context) ([]entity.Producto, error)
+	GetProductos(ctx context.Context, query pagination.Query) ([]entity.Producto, *pagination.Pages, error)
 	GetProductosCaducados(ctx context.Context) ([]ProductoStock, error)
 	GetProductosStock(ctx context.Context) ([]ProductoStock, error)
 	GetProductosConStock(ctx context.Context) ([]ProductosConStock, error)
@@ -40,48 +42,57 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposProductos are the fields the list of productos can be filtered, searched and sorted by.
+var camposProductos = pagination.Fields{
+	Filters: map[string]string{
+		"iva":           "iva",
+		"uso_interno":   "uso_interno",
+		"venta_publico": "venta_publico",
+		"por_medida":    "por_medida",
+		"id_unidad":     "id_unidad",
+	},
+	Search: []string{"descripcion"},
+	Sort: map[string]string{
+		"id_producto":   "id_producto",
+		"descripcion":   "descripcion",
+		"precio_venta":  "precio_venta",
+		"iva":           "iva",
+		"uso_interno":   "uso_interno",
+		"venta_publico": "venta_publico",
+		"por_medida":    "por_medida",
+		"stock_minimo":  "stock_minimo",
+		"id_unidad":     "id_unidad",
+		"contenido":     "contenido",
+	},
+	DefaultSort: []string{"id_producto asc"},
+}
+
 // Get reads the list productos from the database.
-func (r repository) GetProductos(ctx context.Context) ([]entity.Producto, error) {
-	var productos []entity.Producto
+func (r repository) GetProductos(ctx context.Context, query pagination.Query) ([]entity.Producto, *pagination.Pages, error) {
+	var productos []entity.Producto = []entity.Producto{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&productos)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("producto"), camposProductos, &productos)
 	if err != nil {
-		return productos, err
+		return nil, nil, err
 	}
-	return productos, err
+	return productos, pages, nil
 }
 
+// GetProductosUsoInterno reads the productos of internal use with the unidad of those sold by medida.
 func (r repository) GetProductosUsoInterno(ctx context.Context) ([]ProductoUsoInterno, error) {
-	var productos []entity.Producto
 	productosUsoInterno := []ProductoUsoInterno{}
 
 	err := r.db.With(ctx).
-		Select().
-		Where(dbx.NewExp("uso_interno = true")).
-		All(&productos)
+		Select("p.*", "CASE WHEN p.por_medida THEN COALESCE(u.descripcion, '') ELSE '' END AS unidad").
+		From("producto p").
+		LeftJoin("unidad u", dbx.NewExp("u.id_unidad = p.id_unidad")).
+		Where(dbx.NewExp("p.uso_interno = true")).
+		OrderBy("p.id_producto asc").
+		All(&productosUsoInterno)
 	if err != nil {
 		return []ProductoUsoInterno{}, err
 	}
-
-	for i := 0; i < len(productos); i++ {
-		var unidad entity.Unidad
-		if productos[i].PorMedida.Bool {
-			err := r.db.With(ctx).
-				Select().
-				Where(dbx.HashExp{"id_unidad": productos[i].IdUnidad}).
-				One(&unidad)
-			if err != nil {
-				return []ProductoUsoInterno{}, err
-			}
-			productosUsoInterno = append(productosUsoInterno, ProductoUsoInterno{Producto: productos[i], Unidad: unidad.Descripcion})
-		} else {
-			productosUsoInterno = append(productosUsoInterno, ProductoUsoInterno{Producto: productos[i], Unidad: ""})
-		}
-	}
-	return productosUsoInterno, err
+	return productosUsoInterno, nil
 }
 
 func (r repository) GetProductosStock(ctx context.Context) ([]ProductoStock, error) {
@@ -120,154 +131,128 @@ func (r repository) GetProductosCaducados(ctx context.Context) ([]ProductoStock,
 	return productosStock, err
 }
 
+// GetProductosConStock reads the productos sold to the public that have lotes in date and with stock.
 func (r repository) GetProductosConStock(ctx context.Context) ([]ProductosConStock, error) {
-	var productos []entity.Producto
-	var lotes []entity.Lote
-	var stockIndividuales []entity.StockIndividual
-	var stockPorProducto int
-	var medida string
-
-	var productoConStock ProductosConStock
-	var productosConStock []ProductosConStock
+	var productos []productoMedida
 
 	err := r.db.With(ctx).
-		Select().
-		Where(dbx.NewExp("venta_publico = true")).
+		Select("p.*", "CASE WHEN p.por_medida THEN COALESCE(u.descripcion, '') ELSE '' END AS medida").
+		From("producto p").
+		LeftJoin("unidad u", dbx.NewExp("u.id_unidad = p.id_unidad")).
+		Where(dbx.NewExp("p.venta_publico = true")).
+		OrderBy("p.id_producto asc").
 		All(&productos)
 	if err != nil {
 		return []ProductosConStock{}, err
 	}
-	for i := 0; i < len(productos); i++ {
-		productoConStock = ProductosConStock{}
-		stockPorProducto = 0
-		medida = ""
-		lotes = []entity.Lote{}
-		err := r.db.With(ctx).
-			Select().
-			From("lote l").
-			InnerJoin("proveedor_producto as pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
-			Where(dbx.HashExp{"pp.id_producto": productos[i].IdProducto}).
-			AndWhere(dbx.NewExp("(DATE(now()) <= fecha_caducidad or fecha_caducidad is null) and stock > 0")).
-			All(&lotes)
-		if err != nil {
-			return []ProductosConStock{}, err
-		}
-
-		for j := 0; j < len(lotes); j++ {
-			productoConStock.Lote = append(productoConStock.Lote, LoteConStock{})
-			productoConStock.Lote[j].Lote = lotes[j]
-			stockIndividuales = []entity.StockIndividual{}
-			if productos[i].PorMedida.Bool {
-				err := r.db.With(ctx).
-					Select().
-					Where(dbx.HashExp{"id_lote": lotes[j].IdLote}).
-					AndWhere(dbx.NewExp("cantidad > 0")).
-					All(&stockIndividuales)
-				if err != nil {
-					return []ProductosConStock{}, err
-				}
-				productoConStock.Lote[j].StockIndividual = stockIndividuales
-
-				err = r.db.With(ctx).
-					Select("descripcion").
-					From("unidad").
-					Where(dbx.HashExp{"id_unidad": productos[i].IdUnidad}).
-					Row(&medida)
-				if err != nil {
-					return []ProductosConStock{}, err
-				}
-				productoConStock.Medida = medida
-
-			}
-		}
-		if len(lotes) > 0 {
-			err = r.db.With(ctx).
-				Select("sum(stock)").
-				From("lote l").
-				InnerJoin("proveedor_producto as pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
-				Where(dbx.HashExp{"pp.id_producto": productos[i].IdProducto}).
-				AndWhere(dbx.NewExp("(DATE(now()) <= fecha_caducidad or fecha_caducidad is null) and stock > 0")).
-				Row(&stockPorProducto)
-			if err != nil {
-				return []ProductosConStock{}, err
-			}
-			productoConStock.StockPorProducto = stockPorProducto
-			productoConStock.Producto = productos[i]
-			productosConStock = append(productosConStock, productoConStock)
-		}
-
-	}
-	return productosConStock, err
+	return r.agruparLotes(ctx, productos)
 }
 
 func (r repository) GetProductosConStockUsoInternoPorServicio(ctx context.Context, idServicio int) ([]ProductosConStock, error) {
-	var productos []entity.Producto
-	var lotes []entity.Lote
-	var stockIndividuales []entity.StockIndividual
-	var stockPorProducto int
-
-	var productoConStock ProductosConStock
-	var productosConStock []ProductosConStock
+	var productos []productoMedida
 
 	err := r.db.With(ctx).
-		Select().
+		Select("p.*").
 		From("producto p").
 		InnerJoin("servicio_producto as sp", dbx.NewExp("sp.id_producto = p.id_producto and sp.estado = 'A'")).
 		Where(dbx.NewExp("p.uso_interno = true")).
 		AndWhere(dbx.HashExp{"sp.id_servicio": idServicio}).
+		OrderBy("p.id_producto asc").
 		All(&productos)
 	if err != nil {
 		return []ProductosConStock{}, err
 	}
-	for i := 0; i < len(productos); i++ {
-		productoConStock = ProductosConStock{}
-		stockPorProducto = 0
-		lotes = []entity.Lote{}
-		err := r.db.With(ctx).
+	return r.agruparLotes(ctx, productos)
+}
+
+// productoMedida is a producto with the descripcion of its unidad.
+type productoMedida struct {
+	entity.Producto
+	Medida string
+}
+
+// loteProducto is a lote with the producto it belongs to.
+type loteProducto struct {
+	entity.Lote
+	IdProducto int
+}
+
+// agruparLotes reads the lotes in date and with stock of the productos, and the stock individual of
+// those sold by medida, with one query each whatever the number of productos. Productos without
+// lotes are left out.
+func (r repository) agruparLotes(ctx context.Context, productos []productoMedida) ([]ProductosConStock, error) {
+	var productosConStock []ProductosConStock
+	if len(productos) == 0 {
+		return productosConStock, nil
+	}
+
+	idProductos := []interface{}{}
+	porMedida := map[int]bool{}
+	for _, producto := range productos {
+		idProductos = append(idProductos, producto.IdProducto)
+		porMedida[producto.IdProducto] = producto.PorMedida.Bool
+	}
+	var lotes []loteProducto
+	err := r.db.With(ctx).
+		Select("l.*", "pp.id_producto").
+		From("lote l").
+		InnerJoin("proveedor_producto as pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
+		Where(dbx.In("pp.id_producto", idProductos...)).
+		AndWhere(dbx.NewExp("(DATE(now()) <= l.fecha_caducidad or l.fecha_caducidad is null) and l.stock > 0")).
+		OrderBy("l.id_lote asc").
+		All(&lotes)
+	if err != nil {
+		return []ProductosConStock{}, err
+	}
+
+	//El stock individual solo se lee para los productos por medida
+	idLotes := []interface{}{}
+	for _, lote := range lotes {
+		if porMedida[lote.IdProducto] {
+			idLotes = append(idLotes, lote.IdLote)
+		}
+	}
+	stockPorLote := map[int][]entity.StockIndividual{}
+	if len(idLotes) > 0 {
+		var stockIndividuales []entity.StockIndividual
+		err = r.db.With(ctx).
 			Select().
-			From("lote l").
-			InnerJoin("proveedor_producto as pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
-			Where(dbx.HashExp{"pp.id_producto": productos[i].IdProducto}).
-			AndWhere(dbx.NewExp("(DATE(now()) <= fecha_caducidad or fecha_caducidad is null) and stock > 0")).
-			All(&lotes)
+			From("stock_individual").
+			Where(dbx.In("id_lote", idLotes...)).
+			AndWhere(dbx.NewExp("cantidad > 0")).
+			OrderBy("id_stock_individual asc").
+			All(&stockIndividuales)
 		if err != nil {
 			return []ProductosConStock{}, err
 		}
+		for _, stockIndividual := range stockIndividuales {
+			stockPorLote[stockIndividual.IdLote] = append(stockPorLote[stockIndividual.IdLote], stockIndividual)
+		}
+	}
 
-		for j := 0; j < len(lotes); j++ {
-			productoConStock.Lote = append(productoConStock.Lote, LoteConStock{})
-			productoConStock.Lote[j].Lote = lotes[j]
-			stockIndividuales = []entity.StockIndividual{}
-			if productos[i].PorMedida.Bool {
-				err := r.db.With(ctx).
-					Select().
-					Where(dbx.HashExp{"id_lote": lotes[j].IdLote}).
-					AndWhere(dbx.NewExp("cantidad > 0")).
-					All(&stockIndividuales)
-				if err != nil {
-					return []ProductosConStock{}, err
-				}
-				productoConStock.Lote[j].StockIndividual = stockIndividuales
-			}
+	lotesPorProducto := map[int][]LoteConStock{}
+	stockPorProducto := map[int]int{}
+	for _, lote := range lotes {
+		loteConStock := LoteConStock{Lote: lote.Lote}
+		if porMedida[lote.IdProducto] {
+			loteConStock.StockIndividual = []entity.StockIndividual{}
+			loteConStock.StockIndividual = append(loteConStock.StockIndividual, stockPorLote[lote.IdLote]...)
 		}
-		if len(lotes) > 0 {
-			err = r.db.With(ctx).
-				Select("sum(stock)").
-				From("lote l").
-				InnerJoin("proveedor_producto as pp", dbx.NewExp("pp.id_proveedor_producto = l.id_proveedor_producto")).
-				Where(dbx.HashExp{"pp.id_producto": productos[i].IdProducto}).
-				AndWhere(dbx.NewExp("(DATE(now()) <= fecha_caducidad or fecha_caducidad is null) and stock > 0")).
-				Row(&stockPorProducto)
-			if err != nil {
-				return []ProductosConStock{}, err
-			}
-			productoConStock.StockPorProducto = stockPorProducto
-			productoConStock.Producto = productos[i]
-			productosConStock = append(productosConStock, productoConStock)
+		lotesPorProducto[lote.IdProducto] = append(lotesPorProducto[lote.IdProducto], loteConStock)
+		stockPorProducto[lote.IdProducto] += lote.Stock
+	}
+	for _, producto := range productos {
+		if len(lotesPorProducto[producto.IdProducto]) == 0 {
+			continue
 		}
-
+		productosConStock = append(productosConStock, ProductosConStock{
+			StockPorProducto: stockPorProducto[producto.IdProducto],
+			Medida:           producto.Medida,
+			Producto:         producto.Producto,
+			Lote:             lotesPorProducto[producto.IdProducto],
+		})
 	}
-	return productosConStock, err
+	return productosConStock, nil
 }
 
 func (r repository) GetProductosSinAsignarAProveedor(ctx context.Context, idProveedor int) ([]entity.Producto, error) {
@@ -325,7 +310,7 @@ func (r repository) GetProductosAComparar(ctx context.Context, idProveedor1 int,
 // Create saves a new Producto record in the database.
 // It returns the ID of the newly inserted producto record.
 func (r repository) CrearProducto(ctx context.Context, producto entity.Producto) (entity.Producto, error) {
-	err := r.db.With(ctx).Model(&producto).Insert()
+	err := auditoria.Insertar(ctx, r.db, &producto)
 	if err != nil {
 		return entity.Producto{}, err
 	}
@@ -337,9 +322,9 @@ func (r repository) CrearProducto(ctx context.Context, producto entity.Producto)
 func (r repository) ActualizarProducto(ctx context.Context, producto entity.Producto) (entity.Producto, error) {
 	var err error
 	if producto.IdProducto != 0 {
-		err = r.db.With(ctx).Model(&producto).Update()
+		err = auditoria.Actualizar(ctx, r.db, &producto)
 	} else {
-		err = r.db.With(ctx).Model(&producto).Insert()
+		err = auditoria.Insertar(ctx, r.db, &producto)
 	}
 	if err != nil {
 		return entity.Producto{}, err
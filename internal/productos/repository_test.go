@@ -0,0 +1,104 @@
+package productos
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+)
+
+// BenchmarkRepository_GetProductosConStock checks that the productos with their lotes and stock
+// individual are read with the same number of queries whatever the number of productos.
+func BenchmarkRepository_GetProductosConStock(b *testing.B) {
+	logger, _ := log.NewForTest()
+	db := test.DB(b)
+	repo := NewRepository(db, logger)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("productos=%d", n), func(b *testing.B) {
+			test.Rollback(b, db, func(ctx context.Context) {
+				sembrarProductos(b, ctx, db, n)
+
+				consultas := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					consultas = test.CountQueries(db, func() {
+						productos, err := repo.GetProductosConStock(ctx)
+						if err != nil || len(productos) < n {
+							b.Fatalf("GetProductosConStock: %d productos, %v", len(productos), err)
+						}
+					})
+				}
+				//Los productos, sus lotes y el stock individual de los lotes
+				if consultas != 3 {
+					b.Fatalf("GetProductosConStock ran %d queries", consultas)
+				}
+				b.ReportMetric(float64(consultas), "queries/op")
+			})
+		})
+	}
+}
+
+// BenchmarkRepository_GetProductosUsoInterno checks that the productos of internal use are read with
+// a single query whatever the number of productos.
+func BenchmarkRepository_GetProductosUsoInterno(b *testing.B) {
+	logger, _ := log.NewForTest()
+	db := test.DB(b)
+	repo := NewRepository(db, logger)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("productos=%d", n), func(b *testing.B) {
+			test.Rollback(b, db, func(ctx context.Context) {
+				sembrarProductos(b, ctx, db, n)
+
+				consultas := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					consultas = test.CountQueries(db, func() {
+						productos, err := repo.GetProductosUsoInterno(ctx)
+						if err != nil || len(productos) < n {
+							b.Fatalf("GetProductosUsoInterno: %d productos, %v", len(productos), err)
+						}
+					})
+				}
+				if consultas != 1 {
+					b.Fatalf("GetProductosUsoInterno ran %d queries", consultas)
+				}
+				b.ReportMetric(float64(consultas), "queries/op")
+			})
+		})
+	}
+}
+
+// sembrarProductos saves n productos sold by medida, each one with two lotes in date and a stock
+// individual per lote.
+func sembrarProductos(b *testing.B, ctx context.Context, db *dbcontext.DB, n int) {
+	si := sql.NullBool{Bool: true, Valid: true}
+	medida := entity.Medida{Descripcion: "Volumen"}
+	proveedor := entity.Proveedor{Descripcion: "Distribuidora"}
+	test.Insert(b, ctx, db, &medida, &proveedor)
+	unidad := entity.Unidad{IdMedida: medida.IdMedida, Descripcion: "ml"}
+	test.Insert(b, ctx, db, &unidad)
+	for i := 0; i < n; i++ {
+		producto := entity.Producto{
+			Descripcion:  fmt.Sprintf("Producto %d", i),
+			PrecioVenta:  2,
+			UsoInterno:   si,
+			VentaPublico: si,
+			PorMedida:    si,
+			IdUnidad:     &unidad.IdUnidad,
+		}
+		test.Insert(b, ctx, db, &producto)
+		proveedorProducto := entity.ProveedorProducto{IdProveedor: proveedor.IdProveedor, IdProducto: producto.IdProducto, PrecioCompra: 1}
+		test.Insert(b, ctx, db, &proveedorProducto)
+		for j := 0; j < 2; j++ {
+			lote := entity.Lote{IdProveedorProducto: proveedorProducto.IdProveedorProducto, Stock: 5, Descripcion: fmt.Sprintf("Lote %d-%d", i, j)}
+			test.Insert(b, ctx, db, &lote)
+			test.Insert(b, ctx, db, &entity.StockIndividual{IdLote: lote.IdLote, Descripcion: "Frasco", Cantidad: 100, CantidadInicial: 100})
+		}
+	}
+}
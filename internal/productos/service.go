@@ -6,13 +6,14 @@ import (
 	"time"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for productos.
 type Service interface {
-	GetProductos(ctx context.Context) ([]Producto, error)
+	GetProductos(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetProductosSinAsignarAProveedor(ctx context.Context, idProveedor int) ([]Producto, error)
 	GetProductosStock(ctx context.Context) ([]ProductoStock, error)
 	GetProductosCaducados(ctx context.Context) ([]ProductoStock, error)
@@ -84,8 +85,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list productos.
-func (s service) GetProductos(ctx context.Context) ([]Producto, error) {
-	productos, err := s.repo.GetProductos(ctx)
+func (s service) GetProductos(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	productos, pages, err := s.repo.GetProductos(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +94,8 @@ func (s service) GetProductos(ctx context.Context) ([]Producto, error) {
 	for _, item := range productos {
 		result = append(result, Producto{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetProductosStock(ctx context.Context) ([]ProductoStock, error) {
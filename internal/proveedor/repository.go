@@ -2,9 +2,11 @@ package proveedor
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access proveedores from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetProveedorPorId returns the proveedor with the specified proveedor ID.
 	GetProveedorPorId(ctx context.Context, idProveedor int) (entity.Proveedor, error)
 	// GetProveedores returns the list proveedores.
-	GetProveedores(ctx context.Context) ([]entity.Proveedor, error)
+	GetProveedores(ctx context.Context, query pagination.Query) ([]entity.Proveedor, *pagination.Pages, error)
 	CrearProveedor(ctx context.Context, proveedor entity.Proveedor) (entity.Proveedor, error)
 	ActualizarProveedor(ctx context.Context, proveedor entity.Proveedor) (entity.Proveedor, error)
 }
@@ -28,24 +30,35 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposProveedores are the fields the list of proveedores can be filtered, searched and sorted by.
+var camposProveedores = pagination.Fields{
+	Search: []string{"descripcion", "celular", "correo", "ruc", "direccion"},
+	Sort: map[string]string{
+		"id_proveedor": "id_proveedor",
+		"descripcion":  "descripcion",
+		"celular":      "celular",
+		"correo":       "correo",
+		"ruc":          "ruc",
+		"direccion":    "direccion",
+	},
+	DefaultSort: []string{"id_proveedor asc"},
+}
+
 // Get reads the list proveedores from the database.
-func (r repository) GetProveedores(ctx context.Context) ([]entity.Proveedor, error) {
-	var proveedores []entity.Proveedor
+func (r repository) GetProveedores(ctx context.Context, query pagination.Query) ([]entity.Proveedor, *pagination.Pages, error) {
+	var proveedores []entity.Proveedor = []entity.Proveedor{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&proveedores)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("proveedor"), camposProveedores, &proveedores)
 	if err != nil {
-		return proveedores, err
+		return nil, nil, err
 	}
-	return proveedores, err
+	return proveedores, pages, nil
 }
 
 // Create saves a new Proveedor record in the database.
 // It returns the ID of the newly inserted proveedor record.
 func (r repository) CrearProveedor(ctx context.Context, proveedor entity.Proveedor) (entity.Proveedor, error) {
-	err := r.db.With(ctx).Model(&proveedor).Insert()
+	err := auditoria.Insertar(ctx, r.db, &proveedor)
 	if err != nil {
 		return entity.Proveedor{}, err
 	}
@@ -57,9 +70,9 @@ func (r repository) CrearProveedor(ctx context.Context, proveedor entity.Proveed
 func (r repository) ActualizarProveedor(ctx context.Context, proveedor entity.Proveedor) (entity.Proveedor, error) {
 	var err error
 	if proveedor.IdProveedor != 0 {
-		err = r.db.With(ctx).Model(&proveedor).Update()
+		err = auditoria.Actualizar(ctx, r.db, &proveedor)
 	} else {
-		err = r.db.With(ctx).Model(&proveedor).Insert()
+		err = auditoria.Insertar(ctx, r.db, &proveedor)
 	}
 	if err != nil {
 		return entity.Proveedor{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for proveedores.
 type Service interface {
-	GetProveedores(ctx context.Context) ([]Proveedor, error)
+	GetProveedores(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetProveedorPorId(ctx context.Context, idProveedor int) (Proveedor, error)
 	CrearProveedor(ctx context.Context, input CreateProveedorRequest) (Proveedor, error)
 	ActualizarProveedor(ctx context.Context, input UpdateProveedorRequest) (Proveedor, error)
@@ -32,8 +33,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list proveedores.
-func (s service) GetProveedores(ctx context.Context) ([]Proveedor, error) {
-	proveedores, err := s.repo.GetProveedores(ctx)
+func (s service) GetProveedores(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	proveedores, pages, err := s.repo.GetProveedores(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +42,8 @@ func (s service) GetProveedores(ctx context.Context) ([]Proveedor, error) {
 	for _, item := range proveedores {
 		result = append(result, Proveedor{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateProveedorRequest represents an proveedor creation request.
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -28,11 +29,12 @@ type resource struct {
 }
 
 func (r resource) getProveedoresProducto(c *routing.Context) error {
-	proveedoresProducto, err := r.service.GetProveedoresProducto(c.Request.Context())
+	pages, err := r.service.GetProveedoresProducto(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(proveedoresProducto)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearProveedorProducto(c *routing.Context) error {
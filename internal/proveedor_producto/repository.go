@@ -2,9 +2,11 @@ package proveedor_producto
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetProveedorProductoPorId(ctx context.Context, idProveedorProducto int) (entity.ProveedorProducto, error)
 	GetProveedorProductoPorIdProveedor(ctx context.Context, idProveedor int) ([]ProveedorProductoConDatos, error)
 	// GetProveedoresProducto returns the list proveedoresProducto.
-	GetProveedoresProducto(ctx context.Context) ([]entity.ProveedorProducto, error)
+	GetProveedoresProducto(ctx context.Context, query pagination.Query) ([]entity.ProveedorProducto, *pagination.Pages, error)
 	CrearProveedorProducto(ctx context.Context, proveedorProducto entity.ProveedorProducto) (entity.ProveedorProducto, error)
 	ActualizarProveedorProducto(ctx context.Context, proveedorProducto entity.ProveedorProducto) (entity.ProveedorProducto, error)
 }
@@ -31,24 +33,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposProveedoresProducto are the fields the list of proveedores producto can be filtered, searched and sorted by.
+var camposProveedoresProducto = pagination.Fields{
+	Filters: map[string]string{"id_proveedor": "id_proveedor", "id_producto": "id_producto"},
+	Sort: map[string]string{
+		"id_proveedor_producto": "id_proveedor_producto",
+		"id_proveedor":          "id_proveedor",
+		"id_producto":           "id_producto",
+		"precio_compra":         "precio_compra",
+	},
+	DefaultSort: []string{"id_proveedor_producto asc"},
+}
+
 // Get reads the list proveedoresProducto from the database.
-func (r repository) GetProveedoresProducto(ctx context.Context) ([]entity.ProveedorProducto, error) {
-	var proveedoresProducto []entity.ProveedorProducto
+func (r repository) GetProveedoresProducto(ctx context.Context, query pagination.Query) ([]entity.ProveedorProducto, *pagination.Pages, error) {
+	var proveedoresProducto []entity.ProveedorProducto = []entity.ProveedorProducto{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&proveedoresProducto)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("proveedor_producto"), camposProveedoresProducto, &proveedoresProducto)
 	if err != nil {
-		return proveedoresProducto, err
+		return nil, nil, err
 	}
-	return proveedoresProducto, err
+	return proveedoresProducto, pages, nil
 }
 
 // Create saves a new ProveedorProducto record in the database.
 // It returns the ID of the newly inserted proveedorProducto record.
 func (r repository) CrearProveedorProducto(ctx context.Context, proveedorProducto entity.ProveedorProducto) (entity.ProveedorProducto, error) {
-	err := r.db.With(ctx).Model(&proveedorProducto).Insert()
+	err := auditoria.Insertar(ctx, r.db, &proveedorProducto)
 	if err != nil {
 		return entity.ProveedorProducto{}, err
 	}
@@ -60,9 +71,9 @@ func (r repository) CrearProveedorProducto(ctx context.Context, proveedorProduct
 func (r repository) ActualizarProveedorProducto(ctx context.Context, proveedorProducto entity.ProveedorProducto) (entity.ProveedorProducto, error) {
 	var err error
 	if proveedorProducto.IdProveedorProducto != 0 {
-		err = r.db.With(ctx).Model(&proveedorProducto).Update()
+		err = auditoria.Actualizar(ctx, r.db, &proveedorProducto)
 	} else {
-		err = r.db.With(ctx).Model(&proveedorProducto).Insert()
+		err = auditoria.Insertar(ctx, r.db, &proveedorProducto)
 	}
 	if err != nil {
 		return entity.ProveedorProducto{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for proveedoresProducto.
 type Service interface {
-	GetProveedoresProducto(ctx context.Context) ([]ProveedorProducto, error)
+	GetProveedoresProducto(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetProveedorProductoPorId(ctx context.Context, idProveedorProducto int) (ProveedorProducto, error)
 	GetProveedorProductoPorIdProveedor(ctx context.Context, idProveedor int) ([]ProveedorProductoConDatos, error)
 	CrearProveedorProducto(ctx context.Context, input CreateProveedorProductoRequest) (ProveedorProducto, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list proveedoresProducto.
-func (s service) GetProveedoresProducto(ctx context.Context) ([]ProveedorProducto, error) {
-	proveedoresProducto, err := s.repo.GetProveedoresProducto(ctx)
+func (s service) GetProveedoresProducto(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	proveedoresProducto, pages, err := s.repo.GetProveedoresProducto(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetProveedoresProducto(ctx context.Context) ([]ProveedorProduct
 	for _, item := range proveedoresProducto {
 		result = append(result, ProveedorProducto{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateProveedorProductoRequest represents an proveedorProducto creation request.
@@ -1,21 +1,24 @@
 package receta
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"net/http"
-	"runtime"
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+	"veterinaria-server/pkg/storage"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 	"github.com/xuri/excelize/v2"
 )
 
 // RegisterHandlers sets up the routing of the HTTP handlers.
-func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
-	res := resource{service, logger}
+// The recetas are filled from the template of plantillas and saved in documentos.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger, plantillas storage.Storage, documentos storage.Storage) {
+	res := resource{service, logger, plantillas, documentos}
 	r.Use(authHandler)
 	// the following endpoints require a valid JWT
 	r.Get("/recetas", res.getRecetas)
@@ -28,16 +31,19 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 }
 
 type resource struct {
-	service Service
-	logger  log.Logger
+	service    Service
+	logger     log.Logger
+	plantillas storage.Storage
+	documentos storage.Storage
 }
 
 func (r resource) getRecetas(c *routing.Context) error {
-	recetas, err := r.service.GetRecetas(c.Request.Context())
+	pages, err := r.service.GetRecetas(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(recetas)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearReceta(c *routing.Context) error {
@@ -108,14 +114,12 @@ func (r resource) archivo(c *routing.Context) error {
 		return errors.BadRequest("")
 	}
 
-	var err error
-	var ss *excelize.File
-	if runtime.GOOS == "windows" {
-		ss, err = excelize.OpenFile("./plantillas/Receta.xlsx")
-	} else {
-		ss, err = excelize.OpenFile("/root/go/src/github.com/JorgeTom0609/veterinaria-server/plantillas/Receta.xlsx")
+	plantilla, err := r.plantillas.Open(c.Request.Context(), "Receta.xlsx")
+	if err != nil {
+		return err
 	}
-
+	defer plantilla.Close()
+	ss, err := excelize.OpenReader(plantilla)
 	if err != nil {
 		return err
 	}
@@ -153,10 +157,12 @@ func (r resource) archivo(c *routing.Context) error {
 	}
 
 	fileName := fmt.Sprintf("Receta-%s-%s.xlsx", input.Datos.Paciente, input.Datos.FechaLlenado.Format("2006-01-02"))
-	if runtime.GOOS == "windows" {
-		ss.SaveAs("./resources" + "/" + fileName)
-	} else {
-		ss.SaveAs("/root/go/src/github.com/JorgeTom0609/veterinaria-server/resources" + "/" + fileName)
+	var archivo bytes.Buffer
+	if err := ss.Write(&archivo); err != nil {
+		return err
+	}
+	if err := r.documentos.Save(c.Request.Context(), fileName, &archivo); err != nil {
+		return err
 	}
 	return c.Write(fileName)
 }
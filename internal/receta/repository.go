@@ -2,9 +2,11 @@ package receta
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetRecetaPorId(ctx context.Context, idReceta int) (entity.Receta, error)
 	GetRecetaPorConsulta(ctx context.Context, idConsulta int) ([]entity.Receta, error)
 	// GetRecetas returns the list recetas.
-	GetRecetas(ctx context.Context) ([]entity.Receta, error)
+	GetRecetas(ctx context.Context, query pagination.Query) ([]entity.Receta, *pagination.Pages, error)
 	CrearReceta(ctx context.Context, receta entity.Receta) (entity.Receta, error)
 	ActualizarReceta(ctx context.Context, receta entity.Receta) (entity.Receta, error)
 }
@@ -31,25 +33,34 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposRecetas are the fields the list of recetas can be filtered, searched and sorted by.
+var camposRecetas = pagination.Fields{
+	Filters: map[string]string{"id_producto": "id_producto", "id_consulta": "id_consulta"},
+	Search:  []string{"prescripcion"},
+	Sort: map[string]string{
+		"id_receta":    "id_receta",
+		"id_producto":  "id_producto",
+		"id_consulta":  "id_consulta",
+		"prescripcion": "prescripcion",
+	},
+	DefaultSort: []string{"id_receta asc"},
+}
+
 // Get reads the list recetas from the database.
-func (r repository) GetRecetas(ctx context.Context) ([]entity.Receta, error) {
-	var recetas []entity.Receta
+func (r repository) GetRecetas(ctx context.Context, query pagination.Query) ([]entity.Receta, *pagination.Pages, error) {
+	var recetas []entity.Receta = []entity.Receta{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		OrderBy("apellidos asc").
-		All(&recetas)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("receta"), camposRecetas, &recetas)
 	if err != nil {
-		return recetas, err
+		return nil, nil, err
 	}
-	return recetas, err
+	return recetas, pages, nil
 }
 
 // Create saves a new Receta record in the database.
 // It returns the ID of the newly inserted receta record.
 func (r repository) CrearReceta(ctx context.Context, receta entity.Receta) (entity.Receta, error) {
-	err := r.db.With(ctx).Model(&receta).Insert()
+	err := auditoria.Insertar(ctx, r.db, &receta)
 	if err != nil {
 		return entity.Receta{}, err
 	}
@@ -61,9 +72,9 @@ func (r repository) CrearReceta(ctx context.Context, receta entity.Receta) (enti
 func (r repository) ActualizarReceta(ctx context.Context, receta entity.Receta) (entity.Receta, error) {
 	var err error
 	if receta.IdReceta != 0 {
-		err = r.db.With(ctx).Model(&receta).Update()
+		err = auditoria.Actualizar(ctx, r.db, &receta)
 	} else {
-		err = r.db.With(ctx).Model(&receta).Insert()
+		err = auditoria.Insertar(ctx, r.db, &receta)
 	}
 	if err != nil {
 		return entity.Receta{}, err
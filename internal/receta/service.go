@@ -5,13 +5,14 @@ import (
 	"time"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for recetas.
 type Service interface {
-	GetRecetas(ctx context.Context) ([]Receta, error)
+	GetRecetas(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetRecetaPorId(ctx context.Context, idReceta int) (Receta, error)
 	GetRecetaPorConsulta(ctx context.Context, idConsulta int) ([]Receta, error)
 	CrearReceta(ctx context.Context, input CreateRecetaRequest) (Receta, error)
@@ -34,8 +35,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list recetas.
-func (s service) GetRecetas(ctx context.Context) ([]Receta, error) {
-	recetas, err := s.repo.GetRecetas(ctx)
+func (s service) GetRecetas(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	recetas, pages, err := s.repo.GetRecetas(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +44,8 @@ func (s service) GetRecetas(ctx context.Context) ([]Receta, error) {
 	for _, item := range recetas {
 		result = append(result, Receta{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateRecetaRequest represents an receta creation request.
@@ -0,0 +1,57 @@
+package recordatorio_cita
+
+import (
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/recordatoriosCita/configuracion", res.getConfiguraciones)
+	r.Put("/recordatoriosCita/configuracion", res.actualizarConfiguracion)
+	r.Get("/recordatoriosCita/porCita/<idCitaMedica>", res.getRecordatoriosPorCita)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getConfiguraciones(c *routing.Context) error {
+	pages, err := r.service.GetConfiguraciones(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) actualizarConfiguracion(c *routing.Context) error {
+	var input UpdateConfiguracionRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	configuracion, err := r.service.ActualizarConfiguracion(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(configuracion, http.StatusCreated)
+}
+
+func (r resource) getRecordatoriosPorCita(c *routing.Context) error {
+	idCitaMedica, _ := strconv.Atoi(c.Param("idCitaMedica"))
+	recordatorios, err := r.service.GetRecordatoriosPorCita(c.Request.Context(), idCitaMedica)
+	if err != nil {
+		return err
+	}
+	return c.Write(recordatorios)
+}
@@ -0,0 +1,143 @@
+package recordatorio_cita
+
+import (
+	"context"
+	"time"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/cita_medica"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access the recordatorios of the citas from the data source.
+type Repository interface {
+	GetConfiguraciones(ctx context.Context, query pagination.Query) ([]entity.RecordatorioTipoCita, *pagination.Pages, error)
+	GetConfiguracionPorId(ctx context.Context, idRecordatorioTipoCita int) (entity.RecordatorioTipoCita, error)
+	// GetConfiguracionesActivas returns the active recordatorios of every tipo de cita.
+	GetConfiguracionesActivas(ctx context.Context) ([]entity.RecordatorioTipoCita, error)
+	ActualizarConfiguracion(ctx context.Context, configuracion entity.RecordatorioTipoCita) (entity.RecordatorioTipoCita, error)
+	// GetCitasPorRecordar returns the agendadas and confirmadas citas after desde and up to hasta, with the
+	// mascota and its duenio.
+	GetCitasPorRecordar(ctx context.Context, desde, hasta time.Time) ([]CitaPorRecordar, error)
+	// GetRecordatoriosPorCitas returns the recordatorios registered for the citas.
+	GetRecordatoriosPorCitas(ctx context.Context, idsCitaMedica []int) ([]entity.RecordatorioCita, error)
+	CrearRecordatorio(ctx context.Context, recordatorio entity.RecordatorioCita) error
+	// MarcarCitaNotificada sets the estado_notificacion of the cita to SI.
+	MarcarCitaNotificada(ctx context.Context, idCitaMedica int) error
+	// Transaccion calls f in a transaction, so everything f writes is saved or discarded as a whole.
+	Transaccion(ctx context.Context, f func(ctx context.Context) error) error
+}
+
+// repository persists the recordatorios of the citas in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new recordatorioCita repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposConfiguraciones are the fields the list of recordatorios configured can be filtered and sorted by.
+var camposConfiguraciones = pagination.Fields{
+	Filters: map[string]string{"id_tipo_cita": "id_tipo_cita", "activo": "activo"},
+	Sort: map[string]string{
+		"id_recordatorio_tipo_cita": "id_recordatorio_tipo_cita",
+		"id_tipo_cita":              "id_tipo_cita",
+		"minutos_antes":             "minutos_antes",
+	},
+	DefaultSort: []string{"id_tipo_cita asc", "minutos_antes desc"},
+}
+
+func (r repository) GetConfiguraciones(ctx context.Context, query pagination.Query) ([]entity.RecordatorioTipoCita, *pagination.Pages, error) {
+	var configuraciones []entity.RecordatorioTipoCita = []entity.RecordatorioTipoCita{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("recordatorios_tipo_cita"), camposConfiguraciones, &configuraciones)
+	if err != nil {
+		return nil, nil, err
+	}
+	return configuraciones, pages, nil
+}
+
+func (r repository) GetConfiguracionPorId(ctx context.Context, idRecordatorioTipoCita int) (entity.RecordatorioTipoCita, error) {
+	var configuracion entity.RecordatorioTipoCita
+	err := r.db.With(ctx).Select().Model(idRecordatorioTipoCita, &configuracion)
+	return configuracion, err
+}
+
+func (r repository) GetConfiguracionesActivas(ctx context.Context) ([]entity.RecordatorioTipoCita, error) {
+	var configuraciones []entity.RecordatorioTipoCita = []entity.RecordatorioTipoCita{}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.HashExp{"activo": true}).
+		OrderBy("minutos_antes desc").
+		All(&configuraciones)
+	return configuraciones, err
+}
+
+func (r repository) ActualizarConfiguracion(ctx context.Context, configuracion entity.RecordatorioTipoCita) (entity.RecordatorioTipoCita, error) {
+	var err error
+	if configuracion.IdRecordatorioTipoCita != 0 {
+		err = auditoria.Actualizar(ctx, r.db, &configuracion)
+	} else {
+		err = auditoria.Insertar(ctx, r.db, &configuracion)
+	}
+	if err != nil {
+		return entity.RecordatorioTipoCita{}, err
+	}
+	return configuracion, nil
+}
+
+func (r repository) GetCitasPorRecordar(ctx context.Context, desde, hasta time.Time) ([]CitaPorRecordar, error) {
+	var citas []CitaPorRecordar = []CitaPorRecordar{}
+	err := r.db.With(ctx).
+		Select("cm.*", "c.id_cliente", "CONCAT(c.apellidos, ' ', c.nombres) AS duenio", "COALESCE(c.telefono, '') AS telefono",
+			"COALESCE(c.correo, '') AS correo", "COALESCE(m.nombre, '') AS mascota", "COALESCE(tc.descripcion, '') AS tipo_cita").
+		From("citas_medicas cm").
+		InnerJoin("mascotas m", dbx.NewExp("m.id_mascota = cm.id_mascota")).
+		InnerJoin("clientes c", dbx.NewExp("c.id_cliente = m.id_cliente")).
+		LeftJoin("tipos_cita tc", dbx.NewExp("tc.id_tipo_cita = cm.id_tipo_cita")).
+		Where(dbx.NewExp("cm.fecha > {:desde} AND cm.fecha <= {:hasta}", dbx.Params{"desde": desde, "hasta": hasta})).
+		AndWhere(dbx.In("cm.estado", cita_medica.EstadoAgendada, cita_medica.EstadoConfirmada)).
+		OrderBy("cm.fecha asc").
+		All(&citas)
+	return citas, err
+}
+
+func (r repository) GetRecordatoriosPorCitas(ctx context.Context, idsCitaMedica []int) ([]entity.RecordatorioCita, error) {
+	var recordatorios []entity.RecordatorioCita = []entity.RecordatorioCita{}
+	if len(idsCitaMedica) == 0 {
+		return recordatorios, nil
+	}
+	ids := make([]interface{}, len(idsCitaMedica))
+	for i, id := range idsCitaMedica {
+		ids[i] = id
+	}
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.In("id_cita_medica", ids...)).
+		OrderBy("id_recordatorio_cita asc").
+		All(&recordatorios)
+	return recordatorios, err
+}
+
+// CrearRecordatorio registers a recordatorio sent by the scheduler. It is not audited, like the notificaciones.
+func (r repository) CrearRecordatorio(ctx context.Context, recordatorio entity.RecordatorioCita) error {
+	return r.db.With(ctx).Model(&recordatorio).Insert()
+}
+
+func (r repository) MarcarCitaNotificada(ctx context.Context, idCitaMedica int) error {
+	_, err := r.db.With(ctx).
+		Update("citas_medicas", dbx.Params{"estado_notificacion": "SI"}, dbx.HashExp{"id_cita_medica": idCitaMedica}).
+		Execute()
+	return err
+}
+
+func (r repository) Transaccion(ctx context.Context, f func(ctx context.Context) error) error {
+	return r.db.Transactional(ctx, f)
+}
@@ -0,0 +1,282 @@
+package recordatorio_cita
+
+import (
+	"context"
+	"sort"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Estados of a recordatorio of a cita.
+const (
+	// EstadoEnviado is a recordatorio queued in the notificaciones.
+	EstadoEnviado = "ENVIADO"
+	// EstadoOmitido is a recordatorio that was not sent, because the duenio has no contact on a configured
+	// canal or a recordatorio closer to the cita was due at the same time.
+	EstadoOmitido = "OMITIDO"
+)
+
+// tablaCitaMedica is the tabla of the notificaciones of the recordatorios.
+const tablaCitaMedica = "CitaMedica"
+
+// Service encapsulates usecase logic for the recordatorios of the citas.
+type Service interface {
+	GetConfiguraciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	// ActualizarConfiguracion creates a recordatorio of a tipo de cita when it has no ID and updates it otherwise.
+	ActualizarConfiguracion(ctx context.Context, input UpdateConfiguracionRequest) (Configuracion, error)
+	GetRecordatoriosPorCita(ctx context.Context, idCitaMedica int) ([]RecordatorioCita, error)
+	// Programar queues the recordatorios of the citas that are due and returns how many citas were reminded.
+	// When several recordatorios of a cita are due, e.g. for a cita registered the day before, only the
+	// closest to the cita is sent.
+	Programar(ctx context.Context) (int, error)
+}
+
+// Configuracion is a recordatorio configured for a tipo de cita, or for every cita without recordatorios of
+// its tipo when it has no tipo.
+type Configuracion struct {
+	entity.RecordatorioTipoCita
+}
+
+// RecordatorioCita represents the data about a recordatorio of a cita.
+type RecordatorioCita struct {
+	entity.RecordatorioCita
+}
+
+// CitaPorRecordar is a cita with the data the plantilla of the recordatorio shows.
+type CitaPorRecordar struct {
+	entity.CitaMedica
+	IdCliente int    `json:"id_cliente"`
+	Duenio    string `json:"duenio"`
+	Telefono  string `json:"telefono"`
+	Correo    string `json:"correo"`
+	Mascota   string `json:"mascota"`
+	TipoCita  string `json:"tipo_cita"`
+}
+
+type service struct {
+	repo           Repository
+	notificaciones notificaciones.Service
+	logger         log.Logger
+	now            func() time.Time
+}
+
+// NewService creates a new recordatorios service that queues the recordatorios in the notificaciones.
+func NewService(repo Repository, notificaciones notificaciones.Service, logger log.Logger) Service {
+	return service{repo, notificaciones, logger, time.Now}
+}
+
+func (s service) GetConfiguraciones(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	configuraciones, pages, err := s.repo.GetConfiguraciones(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []Configuracion{}
+	for _, item := range configuraciones {
+		result = append(result, Configuracion{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+// UpdateConfiguracionRequest represents a recordatorio creation or update request.
+type UpdateConfiguracionRequest struct {
+	IdRecordatorioTipoCita int  `json:"id_recordatorio_tipo_cita"`
+	IdTipoCita             *int `json:"id_tipo_cita"`
+	MinutosAntes           int  `json:"minutos_antes"`
+	Activo                 bool `json:"activo"`
+}
+
+// Validate validates the UpdateConfiguracionRequest fields. A recordatorio can be up to 30 days before the cita.
+func (m UpdateConfiguracionRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.MinutosAntes, validation.Required, validation.Min(1), validation.Max(30*24*60)),
+	)
+}
+
+func (s service) ActualizarConfiguracion(ctx context.Context, req UpdateConfiguracionRequest) (Configuracion, error) {
+	if err := req.Validate(); err != nil {
+		return Configuracion{}, err
+	}
+	if req.IdRecordatorioTipoCita != 0 {
+		if _, err := s.repo.GetConfiguracionPorId(ctx, req.IdRecordatorioTipoCita); err != nil {
+			return Configuracion{}, err
+		}
+	}
+	if req.Activo {
+		activas, err := s.repo.GetConfiguracionesActivas(ctx)
+		if err != nil {
+			return Configuracion{}, err
+		}
+		for _, activa := range activas {
+			if activa.IdRecordatorioTipoCita != req.IdRecordatorioTipoCita && activa.MinutosAntes == req.MinutosAntes && mismoTipo(activa.IdTipoCita, req.IdTipoCita) {
+				return Configuracion{}, errors.Conflict("El tipo de cita ya tiene un recordatorio a esos minutos de la cita.")
+			}
+		}
+	}
+	configuracionG, err := s.repo.ActualizarConfiguracion(ctx, entity.RecordatorioTipoCita{
+		IdRecordatorioTipoCita: req.IdRecordatorioTipoCita,
+		IdTipoCita:             req.IdTipoCita,
+		MinutosAntes:           req.MinutosAntes,
+		Activo:                 req.Activo,
+	})
+	if err != nil {
+		return Configuracion{}, err
+	}
+	return Configuracion{configuracionG}, nil
+}
+
+// mismoTipo reports whether both recordatorios are of the same tipo de cita, or both have none.
+func mismoTipo(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (s service) GetRecordatoriosPorCita(ctx context.Context, idCitaMedica int) ([]RecordatorioCita, error) {
+	recordatorios, err := s.repo.GetRecordatoriosPorCitas(ctx, []int{idCitaMedica})
+	if err != nil {
+		return nil, err
+	}
+	result := []RecordatorioCita{}
+	for _, item := range recordatorios {
+		result = append(result, RecordatorioCita{item})
+	}
+	return result, nil
+}
+
+func (s service) Programar(ctx context.Context) (int, error) {
+	ahora := s.now()
+	configuraciones, err := s.repo.GetConfiguracionesActivas(ctx)
+	if err != nil {
+		return 0, err
+	}
+	//Minutos de los recordatorios de cada tipo, los de las citas sin tipo van en 0
+	minutosPorTipo := map[int][]int{}
+	maximo := 0
+	for _, configuracion := range configuraciones {
+		idTipoCita := 0
+		if configuracion.IdTipoCita != nil {
+			idTipoCita = *configuracion.IdTipoCita
+		}
+		minutosPorTipo[idTipoCita] = append(minutosPorTipo[idTipoCita], configuracion.MinutosAntes)
+		if configuracion.MinutosAntes > maximo {
+			maximo = configuracion.MinutosAntes
+		}
+	}
+	if maximo == 0 {
+		return 0, nil
+	}
+
+	citas, err := s.repo.GetCitasPorRecordar(ctx, ahora, ahora.Add(time.Duration(maximo)*time.Minute))
+	if err != nil {
+		return 0, err
+	}
+	idsCitaMedica := []int{}
+	for _, cita := range citas {
+		idsCitaMedica = append(idsCitaMedica, cita.IdCitaMedica)
+	}
+	registrados, err := s.repo.GetRecordatoriosPorCitas(ctx, idsCitaMedica)
+	if err != nil {
+		return 0, err
+	}
+	//Un recordatorio registrado para otra fecha de la cita no cuenta, la cita se reprogramo
+	enviados := map[int]map[int]bool{}
+	for _, registrado := range registrados {
+		for _, cita := range citas {
+			if cita.IdCitaMedica == registrado.IdCitaMedica && cita.Fecha.Equal(registrado.FechaCita) {
+				if enviados[cita.IdCitaMedica] == nil {
+					enviados[cita.IdCitaMedica] = map[int]bool{}
+				}
+				enviados[cita.IdCitaMedica][registrado.MinutosAntes] = true
+			}
+		}
+	}
+
+	recordadas := 0
+	for _, cita := range citas {
+		minutos := minutosPorTipo[0]
+		if cita.IdTipoCita != nil && len(minutosPorTipo[*cita.IdTipoCita]) > 0 {
+			minutos = minutosPorTipo[*cita.IdTipoCita]
+		}
+		vencidos := []int{}
+		for _, m := range minutos {
+			if !enviados[cita.IdCitaMedica][m] && !cita.Fecha.Add(-time.Duration(m)*time.Minute).After(ahora) {
+				vencidos = append(vencidos, m)
+			}
+		}
+		if len(vencidos) == 0 {
+			continue
+		}
+		sort.Ints(vencidos)
+
+		//Los recordatorios de la cita se encolan en todos los canales o en ninguno, un reintento no repite un canal
+		enviado := false
+		err := s.repo.Transaccion(ctx, func(ctx context.Context) error {
+			estado, err := s.recordar(ctx, cita)
+			if err != nil {
+				return err
+			}
+			for i, m := range vencidos {
+				recordatorio := entity.RecordatorioCita{
+					IdCitaMedica:  cita.IdCitaMedica,
+					MinutosAntes:  m,
+					FechaCita:     cita.Fecha,
+					Estado:        EstadoOmitido,
+					FechaRegistro: ahora,
+				}
+				if i == 0 {
+					recordatorio.Estado = estado
+				}
+				if err := s.repo.CrearRecordatorio(ctx, recordatorio); err != nil {
+					return err
+				}
+			}
+			if estado != EstadoEnviado {
+				return nil
+			}
+			enviado = true
+			return s.repo.MarcarCitaNotificada(ctx, cita.IdCitaMedica)
+		})
+		if err != nil {
+			//Se vuelve a intentar en la siguiente ejecucion
+			s.logger.With(ctx).Errorf("failed to queue the recordatorio of the cita médica %d: %s", cita.IdCitaMedica, err)
+			continue
+		}
+		if enviado {
+			recordadas++
+		}
+	}
+	return recordadas, nil
+}
+
+// recordar queues the recordatorio of the cita by WhatsApp and by email, when the duenio has a telefono and
+// a correo and the canal is configured. It returns EstadoOmitido when it was not queued on any canal.
+func (s service) recordar(ctx context.Context, cita CitaPorRecordar) (string, error) {
+	idCliente, tabla, idCitaMedica := cita.IdCliente, tablaCitaMedica, cita.IdCitaMedica
+	referencia := notificaciones.Referencia{IdCliente: &idCliente, Tabla: &tabla, IdReferencia: &idCitaMedica}
+	estado := EstadoOmitido
+	for _, destino := range []struct{ canal, destinatario string }{
+		{notificaciones.CanalWhatsApp, cita.Telefono},
+		{notificaciones.CanalEmail, cita.Correo},
+	} {
+		if destino.destinatario == "" {
+			continue
+		}
+		_, err := s.notificaciones.Notificar(ctx, notificaciones.PlantillaRecordatorioCita, destino.canal, destino.destinatario, referencia, cita)
+		if err == notificaciones.ErrCanalNoConfigurado {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		estado = EstadoEnviado
+	}
+	return estado, nil
+}
@@ -0,0 +1,216 @@
+package recordatorio_cita
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/notificaciones"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_Programar(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	vacunacion := 2
+	cita := func(id int, idTipoCita *int, fecha time.Time, telefono string) CitaPorRecordar {
+		return CitaPorRecordar{
+			CitaMedica: entity.CitaMedica{IdCitaMedica: id, IdMascota: 1, IdTipoCita: idTipoCita, Motivo: "Control", Fecha: fecha},
+			IdCliente:  1, Duenio: "Pérez Ana", Telefono: telefono, Mascota: "Firulais",
+		}
+	}
+	repo := &mockRepository{
+		configuraciones: []entity.RecordatorioTipoCita{
+			{IdRecordatorioTipoCita: 1, MinutosAntes: 4320, Activo: true},
+			{IdRecordatorioTipoCita: 2, MinutosAntes: 1440, Activo: true},
+			{IdRecordatorioTipoCita: 3, MinutosAntes: 120, Activo: true},
+			{IdRecordatorioTipoCita: 4, IdTipoCita: &vacunacion, MinutosAntes: 60, Activo: true},
+		},
+		citas: []CitaPorRecordar{
+			cita(1, nil, ahora.Add(48*time.Hour), "593911111111"),
+			cita(2, nil, ahora.Add(time.Hour), "593911111111"),
+			cita(3, &vacunacion, ahora.Add(30*time.Minute), "593911111111"),
+			cita(4, &vacunacion, ahora.Add(2*time.Hour), "593911111111"),
+			cita(5, nil, ahora.Add(90*time.Minute), "593911111111"),
+			cita(6, nil, ahora.Add(48*time.Hour), "593911111111"),
+			cita(7, nil, ahora.Add(20*time.Hour), ""),
+			cita(8, nil, ahora.Add(-time.Hour), "593911111111"),
+		},
+		recordatorios: []entity.RecordatorioCita{
+			{IdCitaMedica: 5, MinutosAntes: 4320, FechaCita: ahora.Add(90 * time.Minute), Estado: EstadoEnviado},
+			{IdCitaMedica: 5, MinutosAntes: 1440, FechaCita: ahora.Add(90 * time.Minute), Estado: EstadoEnviado},
+			//La cita 6 se reprogramo
+			{IdCitaMedica: 6, MinutosAntes: 4320, FechaCita: ahora.Add(24 * time.Hour), Estado: EstadoEnviado},
+		},
+	}
+	sn := &mockNotificaciones{}
+	s := NewService(repo, sn, logger).(service)
+	s.now = func() time.Time { return ahora }
+
+	recordadas, err := s.Programar(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 5, recordadas)
+	assert.Equal(t, []int{1, 2, 3, 5, 6}, sn.citas)
+	assert.Equal(t, []int{1, 2, 3, 5, 6}, repo.notificadas)
+	assert.Equal(t, []string{
+		"1:4320:ENVIADO",
+		"2:120:ENVIADO", "2:1440:OMITIDO", "2:4320:OMITIDO",
+		"3:60:ENVIADO",
+		"5:120:ENVIADO",
+		"6:4320:ENVIADO",
+		"7:1440:OMITIDO", "7:4320:OMITIDO",
+	}, repo.registrados(3))
+
+	//Los recordatorios registrados no se repiten
+	sn.citas = nil
+	recordadas, err = s.Programar(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, recordadas)
+	assert.Empty(t, sn.citas)
+}
+
+func TestUpdateConfiguracionRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   UpdateConfiguracionRequest
+		wantErr bool
+	}{
+		{"success", UpdateConfiguracionRequest{MinutosAntes: 120, Activo: true}, false},
+		{"sin minutos", UpdateConfiguracionRequest{Activo: true}, true},
+		{"mas de 30 dias", UpdateConfiguracionRequest{MinutosAntes: 31 * 24 * 60}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_service_Programar_falloDeUnCanal(t *testing.T) {
+	logger, _ := log.NewForTest()
+	ahora := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	sn := &mockNotificaciones{errEmail: errors.New("plantilla sin email")}
+	repo := &mockRepository{
+		configuraciones: []entity.RecordatorioTipoCita{{IdRecordatorioTipoCita: 1, MinutosAntes: 120, Activo: true}},
+		citas: []CitaPorRecordar{{
+			CitaMedica: entity.CitaMedica{IdCitaMedica: 1, IdMascota: 1, Motivo: "Control", Fecha: ahora.Add(time.Hour)},
+			IdCliente:  1, Duenio: "Pérez Ana", Telefono: "593911111111", Correo: "ana@example.com", Mascota: "Firulais",
+		}},
+		notificaciones: sn,
+	}
+	s := NewService(repo, sn, logger).(service)
+	s.now = func() time.Time { return ahora }
+
+	//El WhatsApp ya encolado se descarta con el fallo del email
+	recordadas, err := s.Programar(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, recordadas)
+	assert.Empty(t, sn.citas)
+	assert.Empty(t, repo.recordatorios)
+
+	//El reintento encola el WhatsApp una sola vez
+	sn.errEmail = nil
+	recordadas, err = s.Programar(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, recordadas)
+	assert.Equal(t, []int{1}, sn.citas)
+	assert.Equal(t, []string{"1:120:ENVIADO"}, repo.registrados(0))
+}
+
+type mockNotificaciones struct {
+	notificaciones.Service
+	citas    []int
+	errEmail error //Sin error el canal email no esta configurado
+}
+
+func (m *mockNotificaciones) Notificar(ctx context.Context, codigo, canal, destinatario string, referencia notificaciones.Referencia, datos interface{}) (notificaciones.Notificacion, error) {
+	if canal == notificaciones.CanalEmail && m.errEmail != nil {
+		return notificaciones.Notificacion{}, m.errEmail
+	}
+	if canal != notificaciones.CanalWhatsApp {
+		return notificaciones.Notificacion{}, notificaciones.ErrCanalNoConfigurado
+	}
+	m.citas = append(m.citas, *referencia.IdReferencia)
+	return notificaciones.Notificacion{}, nil
+}
+
+type mockRepository struct {
+	configuraciones []entity.RecordatorioTipoCita
+	citas           []CitaPorRecordar
+	recordatorios   []entity.RecordatorioCita
+	notificadas     []int
+	notificaciones  *mockNotificaciones //Encoladas en la transaccion, se descartan con ella
+}
+
+// registrados returns the recordatorios registered after the first n as "cita:minutos:estado".
+func (m *mockRepository) registrados(n int) []string {
+	result := []string{}
+	for _, r := range m.recordatorios[n:] {
+		result = append(result, fmt.Sprintf("%d:%d:%s", r.IdCitaMedica, r.MinutosAntes, r.Estado))
+	}
+	return result
+}
+
+func (m *mockRepository) GetConfiguraciones(ctx context.Context, query pagination.Query) ([]entity.RecordatorioTipoCita, *pagination.Pages, error) {
+	pages := pagination.New(query.Page, query.PerPage, len(m.configuraciones))
+	return m.configuraciones, pages, nil
+}
+
+func (m *mockRepository) GetConfiguracionPorId(ctx context.Context, idRecordatorioTipoCita int) (entity.RecordatorioTipoCita, error) {
+	return entity.RecordatorioTipoCita{}, nil
+}
+
+func (m *mockRepository) GetConfiguracionesActivas(ctx context.Context) ([]entity.RecordatorioTipoCita, error) {
+	return m.configuraciones, nil
+}
+
+func (m *mockRepository) ActualizarConfiguracion(ctx context.Context, configuracion entity.RecordatorioTipoCita) (entity.RecordatorioTipoCita, error) {
+	return configuracion, nil
+}
+
+func (m *mockRepository) GetCitasPorRecordar(ctx context.Context, desde, hasta time.Time) ([]CitaPorRecordar, error) {
+	result := []CitaPorRecordar{}
+	for _, cita := range m.citas {
+		if cita.Fecha.After(desde) && !cita.Fecha.After(hasta) {
+			result = append(result, cita)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) GetRecordatoriosPorCitas(ctx context.Context, idsCitaMedica []int) ([]entity.RecordatorioCita, error) {
+	return m.recordatorios, nil
+}
+
+func (m *mockRepository) CrearRecordatorio(ctx context.Context, recordatorio entity.RecordatorioCita) error {
+	m.recordatorios = append(m.recordatorios, recordatorio)
+	return nil
+}
+
+func (m *mockRepository) MarcarCitaNotificada(ctx context.Context, idCitaMedica int) error {
+	m.notificadas = append(m.notificadas, idCitaMedica)
+	return nil
+}
+
+// Transaccion discards what f registered and queued when it fails.
+func (m *mockRepository) Transaccion(ctx context.Context, f func(ctx context.Context) error) error {
+	recordatorios, notificadas := len(m.recordatorios), len(m.notificadas)
+	citas := 0
+	if m.notificaciones != nil {
+		citas = len(m.notificaciones.citas)
+	}
+	err := f(ctx)
+	if err != nil {
+		m.recordatorios, m.notificadas = m.recordatorios[:recordatorios], m.notificadas[:notificadas]
+		if m.notificaciones != nil {
+			m.notificaciones.citas = m.notificaciones.citas[:citas]
+		}
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package resultado_examen_cualitativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
@@ -28,7 +29,7 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 // Create saves a new ResultadoDetalleCualitativo record in the database.
 // It returns the ID of the newly inserted resultadoDetalleCualitativo record.
 func (r repository) CrearResultadoDetalleCualitativo(ctx context.Context, resultadoDetalleCualitativo entity.ResultadoDetalleCualitativo) (entity.ResultadoDetalleCualitativo, error) {
-	err := r.db.With(ctx).Model(&resultadoDetalleCualitativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &resultadoDetalleCualitativo)
 	if err != nil {
 		return entity.ResultadoDetalleCualitativo{}, err
 	}
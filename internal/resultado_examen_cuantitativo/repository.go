@@ -2,6 +2,7 @@ package resultado_examen_cuantitativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
@@ -28,7 +29,7 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 // Create saves a new ResultadoDetalleCuantitativo record in the database.
 // It returns the ID of the newly inserted resultadoDetalleCuantitativo record.
 func (r repository) CrearResultadoDetalleCuantitativo(ctx context.Context, resultadoDetalleCuantitativo entity.ResultadoDetalleCuantitativo) (entity.ResultadoDetalleCuantitativo, error) {
-	err := r.db.With(ctx).Model(&resultadoDetalleCuantitativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &resultadoDetalleCuantitativo)
 	if err != nil {
 		return entity.ResultadoDetalleCuantitativo{}, err
 	}
@@ -2,6 +2,7 @@ package resultado_examen_informativo
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
@@ -28,7 +29,7 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 // Create saves a new ResultadoDetalleInformativo record in the database.
 // It returns the ID of the newly inserted resultadoDetalleInformativo record.
 func (r repository) CrearResultadoDetalleInformativo(ctx context.Context, resultadoDetalleInformativo entity.ResultadoDetalleInformativo) (entity.ResultadoDetalleInformativo, error) {
-	err := r.db.With(ctx).Model(&resultadoDetalleInformativo).Insert()
+	err := auditoria.Insertar(ctx, r.db, &resultadoDetalleInformativo)
 	if err != nil {
 		return entity.ResultadoDetalleInformativo{}, err
 	}
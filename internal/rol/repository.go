@@ -2,9 +2,11 @@ package rol
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access roles from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetRolPorId returns the rol with the specified rol ID.
 	GetRolPorId(ctx context.Context, idRol int) (entity.Rol, error)
 	// GetRoles returns the list roles.
-	GetRoles(ctx context.Context) ([]entity.Rol, error)
+	GetRoles(ctx context.Context, query pagination.Query) ([]entity.Rol, *pagination.Pages, error)
 	CrearRol(ctx context.Context, rol entity.Rol) (entity.Rol, error)
 	ActualizarRol(ctx context.Context, rol entity.Rol) (entity.Rol, error)
 }
@@ -28,24 +30,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposRoles are the fields the list of roles can be filtered, searched and sorted by.
+var camposRoles = pagination.Fields{
+	Filters: map[string]string{"estado": "estado"},
+	Search:  []string{"descripcion"},
+	Sort: map[string]string{
+		"id_rol":      "id_rol",
+		"descripcion": "descripcion",
+		"estado":      "estado",
+	},
+	DefaultSort: []string{"id_rol asc"},
+}
+
 // Get reads the list roles from the database.
-func (r repository) GetRoles(ctx context.Context) ([]entity.Rol, error) {
-	var roles []entity.Rol
+func (r repository) GetRoles(ctx context.Context, query pagination.Query) ([]entity.Rol, *pagination.Pages, error) {
+	var roles []entity.Rol = []entity.Rol{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&roles)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("roles"), camposRoles, &roles)
 	if err != nil {
-		return roles, err
+		return nil, nil, err
 	}
-	return roles, err
+	return roles, pages, nil
 }
 
 // Create saves a new Rol record in the database.
 // It returns the ID of the newly inserted rol record.
 func (r repository) CrearRol(ctx context.Context, rol entity.Rol) (entity.Rol, error) {
-	err := r.db.With(ctx).Model(&rol).Insert()
+	err := auditoria.Insertar(ctx, r.db, &rol)
 	if err != nil {
 		return entity.Rol{}, err
 	}
@@ -57,9 +68,9 @@ func (r repository) CrearRol(ctx context.Context, rol entity.Rol) (entity.Rol, e
 func (r repository) ActualizarRol(ctx context.Context, rol entity.Rol) (entity.Rol, error) {
 	var err error
 	if rol.IdRol != 0 {
-		err = r.db.With(ctx).Model(&rol).Update()
+		err = auditoria.Actualizar(ctx, r.db, &rol)
 	} else {
-		err = r.db.With(ctx).Model(&rol).Insert()
+		err = auditoria.Insertar(ctx, r.db, &rol)
 	}
 	if err != nil {
 		return entity.Rol{}, err
@@ -5,13 +5,14 @@ import (
 	"database/sql"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for roles.
 type Service interface {
-	GetRoles(ctx context.Context) ([]Rol, error)
+	GetRoles(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetRolPorId(ctx context.Context, idRol int) (Rol, error)
 	CrearRol(ctx context.Context, input CreateRolRequest) (Rol, error)
 	ActualizarRol(ctx context.Context, input UpdateRolRequest) (Rol, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list roles.
-func (s service) GetRoles(ctx context.Context) ([]Rol, error) {
-	roles, err := s.repo.GetRoles(ctx)
+func (s service) GetRoles(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	roles, pages, err := s.repo.GetRoles(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetRoles(ctx context.Context) ([]Rol, error) {
 	for _, item := range roles {
 		result = append(result, Rol{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateRolRequest represents an rol creation request.
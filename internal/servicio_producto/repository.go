@@ -2,9 +2,11 @@ package servicio_producto
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	GetServicioProductoPorId(ctx context.Context, idServicioProducto int) (entity.ServicioProducto, error)
 	GetServicioProductoPorServicio(ctx context.Context, idServicio int) ([]ServicioProductoConCantidad, error)
 	// GetServicioProductos returns the list servicioProductos.
-	GetServicioProductos(ctx context.Context) ([]entity.ServicioProducto, error)
+	GetServicioProductos(ctx context.Context, query pagination.Query) ([]entity.ServicioProducto, *pagination.Pages, error)
 	GetServicioProductosConDatos(ctx context.Context) ([]ServicioProductoConDatos, error)
 	CrearServicioProducto(ctx context.Context, servicioProducto entity.ServicioProducto) (entity.ServicioProducto, error)
 	ActualizarServicioProducto(ctx context.Context, servicioProducto entity.ServicioProducto) (entity.ServicioProducto, error)
@@ -32,19 +34,36 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposServicioProductos are the fields the list of servicio productos can be filtered, searched and sorted by.
+var camposServicioProductos = pagination.Fields{
+	Filters: map[string]string{
+		"id_servicio": "id_servicio",
+		"id_producto": "id_producto",
+	},
+	Sort: map[string]string{
+		"id_servicio_producto": "id_servicio_producto",
+		"id_servicio":          "id_servicio",
+		"id_producto":          "id_producto",
+		"cantidad":             "cantidad",
+		"razon":                "razon",
+		"estado":               "estado",
+	},
+	DefaultSort: []string{"id_servicio_producto asc"},
+}
+
 // Get reads the list servicioProductos from the database.
-func (r repository) GetServicioProductos(ctx context.Context) ([]entity.ServicioProducto, error) {
-	var servicioProductos []entity.ServicioProducto
+func (r repository) GetServicioProductos(ctx context.Context, query pagination.Query) ([]entity.ServicioProducto, *pagination.Pages, error) {
+	var servicioProductos []entity.ServicioProducto = []entity.ServicioProducto{}
 
-	err := r.db.With(ctx).
+	q := r.db.With(ctx).
 		Select().
-		From().
-		Where(dbx.HashExp{"estado": "A"}).
-		All(&servicioProductos)
+		From("servicio_producto").
+		Where(dbx.HashExp{"estado": "A"})
+	pages, err := query.List(ctx, r.db.With(ctx), q, camposServicioProductos, &servicioProductos)
 	if err != nil {
-		return servicioProductos, err
+		return nil, nil, err
 	}
-	return servicioProductos, err
+	return servicioProductos, pages, nil
 }
 
 func (r repository) GetServicioProductosConDatos(ctx context.Context) ([]ServicioProductoConDatos, error) {
@@ -97,7 +116,7 @@ func (r repository) GetServicioProductosConDatos(ctx context.Context) ([]Servici
 // Create saves a new ServicioProducto record in the database.
 // It returns the ID of the newly inserted servicioProducto record.
 func (r repository) CrearServicioProducto(ctx context.Context, servicioProducto entity.ServicioProducto) (entity.ServicioProducto, error) {
-	err := r.db.With(ctx).Model(&servicioProducto).Insert()
+	err := auditoria.Insertar(ctx, r.db, &servicioProducto)
 	if err != nil {
 		return entity.ServicioProducto{}, err
 	}
@@ -109,9 +128,9 @@ func (r repository) CrearServicioProducto(ctx context.Context, servicioProducto
 func (r repository) ActualizarServicioProducto(ctx context.Context, servicioProducto entity.ServicioProducto) (entity.ServicioProducto, error) {
 	var err error
 	if servicioProducto.IdServicioProducto != 0 {
-		err = r.db.With(ctx).Model(&servicioProducto).Update()
+		err = auditoria.Actualizar(ctx, r.db, &servicioProducto)
 	} else {
-		err = r.db.With(ctx).Model(&servicioProducto).Insert()
+		err = auditoria.Insertar(ctx, r.db, &servicioProducto)
 	}
 	if err != nil {
 		return entity.ServicioProducto{}, err
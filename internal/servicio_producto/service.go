@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for servicioProductos.
 type Service interface {
-	GetServicioProductos(ctx context.Context) ([]ServicioProducto, error)
+	GetServicioProductos(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetServicioProductosConDatos(ctx context.Context) ([]ServicioProductoConDatos, error)
 	GetServicioProductoPorId(ctx context.Context, idServicioProducto int) (ServicioProducto, error)
 	GetServicioProductoPorServicio(ctx context.Context, idServicio int) ([]ServicioProductoConCantidad, error)
@@ -46,8 +47,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list servicioProductos.
-func (s service) GetServicioProductos(ctx context.Context) ([]ServicioProducto, error) {
-	servicioProductos, err := s.repo.GetServicioProductos(ctx)
+func (s service) GetServicioProductos(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	servicioProductos, pages, err := s.repo.GetServicioProductos(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +56,8 @@ func (s service) GetServicioProductos(ctx context.Context) ([]ServicioProducto,
 	for _, item := range servicioProductos {
 		result = append(result, ServicioProducto{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // Get returns the list servicioProductos.
@@ -7,6 +7,7 @@ import (
 	"veterinaria-server/internal/servicio_producto"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -32,11 +33,12 @@ type resource struct {
 }
 
 func (r resource) getServicios(c *routing.Context) error {
-	servicios, err := r.service.GetServicios(c.Request.Context())
+	pages, err := r.service.GetServicios(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(servicios)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getServicioPorEspecie(c *routing.Context) error {
@@ -2,9 +2,11 @@ package servicios
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -14,7 +16,7 @@ type Repository interface {
 	// GetServicioPorId returns the servicio with the specified servicio ID.
 	GetServicioPorId(ctx context.Context, idServicio int) (entity.Servicio, error)
 	// GetServicios returns the list servicios.
-	GetServicios(ctx context.Context) ([]entity.Servicio, error)
+	GetServicios(ctx context.Context, query pagination.Query) ([]entity.Servicio, *pagination.Pages, error)
 	GetServicioPorEspecie(ctx context.Context, idEspecie int, modo int) ([]ServicioTieneProductos, error)
 	GetServiciosConProductos(ctx context.Context) ([]ServicioTieneProductos, error)
 	CrearServicio(ctx context.Context, servicio entity.Servicio) (entity.Servicio, error)
@@ -32,18 +34,36 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposServicios are the fields the list of servicios can be filtered, searched and sorted by.
+var camposServicios = pagination.Fields{
+	Filters: map[string]string{
+		"id_usuario":             "id_usuario",
+		"id_especie":             "id_especie",
+		"aplica_consulta":        "aplica_consulta",
+		"aplica_hospitalizacion": "aplica_hospitalizacion",
+	},
+	Search: []string{"descripcion"},
+	Sort: map[string]string{
+		"id_servicio":            "id_servicio",
+		"id_usuario":             "id_usuario",
+		"id_especie":             "id_especie",
+		"descripcion":            "descripcion",
+		"valor":                  "valor",
+		"aplica_consulta":        "aplica_consulta",
+		"aplica_hospitalizacion": "aplica_hospitalizacion",
+	},
+	DefaultSort: []string{"id_servicio asc"},
+}
+
 // Get reads the list servicios from the database.
-func (r repository) GetServicios(ctx context.Context) ([]entity.Servicio, error) {
-	var servicios []entity.Servicio
+func (r repository) GetServicios(ctx context.Context, query pagination.Query) ([]entity.Servicio, *pagination.Pages, error) {
+	var servicios []entity.Servicio = []entity.Servicio{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&servicios)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("servicios"), camposServicios, &servicios)
 	if err != nil {
-		return servicios, err
+		return nil, nil, err
 	}
-	return servicios, err
+	return servicios, pages, nil
 }
 
 func (r repository) GetServicioPorEspecie(ctx context.Context, idEspecie int, modo int) ([]ServicioTieneProductos, error) {
@@ -131,7 +151,7 @@ func (r repository) GetServiciosConProductos(ctx context.Context) ([]ServicioTie
 // Create saves a new Servicio record in the database.
 // It returns the ID of the newly inserted servicio record.
 func (r repository) CrearServicio(ctx context.Context, servicio entity.Servicio) (entity.Servicio, error) {
-	err := r.db.With(ctx).Model(&servicio).Insert()
+	err := auditoria.Insertar(ctx, r.db, &servicio)
 	if err != nil {
 		return entity.Servicio{}, err
 	}
@@ -143,9 +163,9 @@ func (r repository) CrearServicio(ctx context.Context, servicio entity.Servicio)
 func (r repository) ActualizarServicio(ctx context.Context, servicio entity.Servicio) (entity.Servicio, error) {
 	var err error
 	if servicio.IdServicio != 0 {
-		err = r.db.With(ctx).Model(&servicio).Update()
+		err = auditoria.Actualizar(ctx, r.db, &servicio)
 	} else {
-		err = r.db.With(ctx).Model(&servicio).Insert()
+		err = auditoria.Insertar(ctx, r.db, &servicio)
 	}
 	if err != nil {
 		return entity.Servicio{}, err
@@ -3,16 +3,18 @@ package servicios
 import (
 	"context"
 	"database/sql"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/internal/servicio_producto"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for servicios.
 type Service interface {
-	GetServicios(ctx context.Context) ([]Servicio, error)
+	GetServicios(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetServicioPorEspecie(ctx context.Context, idEspecie int, modo int) ([]ServicioTieneProductos, error)
 	GetServiciosConProductos(ctx context.Context) ([]ServicioTieneProductos, error)
 	GetServicioPorId(ctx context.Context, idServicio int) (Servicio, error)
@@ -41,8 +43,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list servicios.
-func (s service) GetServicios(ctx context.Context) ([]Servicio, error) {
-	servicios, err := s.repo.GetServicios(ctx)
+func (s service) GetServicios(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	servicios, pages, err := s.repo.GetServicios(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +52,8 @@ func (s service) GetServicios(ctx context.Context) ([]Servicio, error) {
 	for _, item := range servicios {
 		result = append(result, Servicio{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetServicioPorEspecie(ctx context.Context, idEspecie int, modo int) ([]ServicioTieneProductos, error) {
@@ -110,6 +113,11 @@ func (m CreateServicioRequest) Validate() error {
 
 // CrearServicio creates a new servicio.
 func (s service) CrearServicio(ctx context.Context, req CreateServicioRequest) (Servicio, error) {
+	idUsuario, err := auth.UsuarioActuante(ctx, req.IdUsuario)
+	if err != nil {
+		return Servicio{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.Validate(); err != nil {
 		return Servicio{}, err
 	}
@@ -129,6 +137,19 @@ func (s service) CrearServicio(ctx context.Context, req CreateServicioRequest) (
 
 // ActualizarServicio creates a new servicio.
 func (s service) ActualizarServicio(ctx context.Context, req UpdateServicioRequest) (Servicio, error) {
+	var idGuardado int
+	if req.IdServicio != 0 {
+		servicioBD, err := s.repo.GetServicioPorId(ctx, req.IdServicio)
+		if err != nil {
+			return Servicio{}, err
+		}
+		idGuardado = servicioBD.IdUsuario
+	}
+	idUsuario, err := auth.UsuarioRegistro(ctx, req.IdUsuario, idGuardado)
+	if err != nil {
+		return Servicio{}, err
+	}
+	req.IdUsuario = idUsuario
 	if err := req.ValidateUpdate(); err != nil {
 		return Servicio{}, err
 	}
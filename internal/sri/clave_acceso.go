@@ -0,0 +1,108 @@
+package sri
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Códigos de tipo de comprobante de la ficha técnica del SRI.
+const (
+	CodDocFactura     = "01"
+	CodDocNotaCredito = "04"
+)
+
+// Ambientes de los web services del SRI.
+const (
+	AmbientePruebas    = "1"
+	AmbienteProduccion = "2"
+)
+
+// TipoEmisionNormal is the only emission type accepted by the offline scheme.
+const TipoEmisionNormal = "1"
+
+// ClaveAcceso holds the fields that make up the 49 digit clave de acceso of a comprobante.
+type ClaveAcceso struct {
+	FechaEmision    time.Time
+	CodDoc          string
+	Ruc             string
+	Ambiente        string
+	Establecimiento string
+	PuntoEmision    string
+	Secuencial      int
+	CodigoNumerico  string
+	TipoEmision     string
+}
+
+// String returns the clave de acceso including its módulo 11 check digit.
+func (c ClaveAcceso) String() string {
+	clave := fmt.Sprintf("%s%s%s%s%s%s%09d%s%s",
+		c.FechaEmision.Format("02012006"),
+		c.CodDoc,
+		c.Ruc,
+		c.Ambiente,
+		c.Establecimiento,
+		c.PuntoEmision,
+		c.Secuencial,
+		c.CodigoNumerico,
+		c.TipoEmision,
+	)
+	return clave + strconv.Itoa(DigitoVerificador(clave))
+}
+
+// Validate checks that every field has the length required by the SRI.
+func (c ClaveAcceso) Validate() error {
+	campos := []struct {
+		nombre   string
+		valor    string
+		longitud int
+	}{
+		{"codDoc", c.CodDoc, 2},
+		{"ruc", c.Ruc, 13},
+		{"ambiente", c.Ambiente, 1},
+		{"estab", c.Establecimiento, 3},
+		{"ptoEmi", c.PuntoEmision, 3},
+		{"codigoNumerico", c.CodigoNumerico, 8},
+		{"tipoEmision", c.TipoEmision, 1},
+	}
+	for _, campo := range campos {
+		if len(campo.valor) != campo.longitud || !esNumerico(campo.valor) {
+			return fmt.Errorf("sri: %s debe tener %d dígitos", campo.nombre, campo.longitud)
+		}
+	}
+	if c.Secuencial < 1 || c.Secuencial > 999999999 {
+		return fmt.Errorf("sri: secuencial fuera de rango: %d", c.Secuencial)
+	}
+	return nil
+}
+
+// DigitoVerificador computes the módulo 11 check digit of the given digits, weighting them
+// from right to left with the factors 2 to 7.
+func DigitoVerificador(digitos string) int {
+	suma := 0
+	factor := 2
+	for i := len(digitos) - 1; i >= 0; i-- {
+		suma += int(digitos[i]-'0') * factor
+		factor++
+		if factor > 7 {
+			factor = 2
+		}
+	}
+	digito := 11 - suma%11
+	switch digito {
+	case 11:
+		return 0
+	case 10:
+		return 1
+	}
+	return digito
+}
+
+func esNumerico(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
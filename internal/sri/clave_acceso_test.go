@@ -0,0 +1,39 @@
+package sri
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigitoVerificador(t *testing.T) {
+	assert.Equal(t, 6, DigitoVerificador("41261533"))
+	// 11 - 0 se reemplaza por 0
+	assert.Equal(t, 0, DigitoVerificador("0"))
+	// 11 - 1 se reemplaza por 1
+	assert.Equal(t, 1, DigitoVerificador("5"))
+}
+
+func TestClaveAcceso(t *testing.T) {
+	clave := ClaveAcceso{
+		FechaEmision:    time.Date(2022, 6, 15, 10, 0, 0, 0, time.UTC),
+		CodDoc:          CodDocFactura,
+		Ruc:             "0912345678001",
+		Ambiente:        AmbientePruebas,
+		Establecimiento: "001",
+		PuntoEmision:    "002",
+		Secuencial:      25,
+		CodigoNumerico:  "12345678",
+		TipoEmision:     TipoEmisionNormal,
+	}
+	assert.Nil(t, clave.Validate())
+
+	valor := clave.String()
+	assert.Len(t, valor, 49)
+	assert.Equal(t, "150620220109123456780011001002000000025123456781", valor[:48])
+	assert.Equal(t, string(rune('0'+DigitoVerificador(valor[:48]))), valor[48:])
+
+	clave.Ruc = "091234567"
+	assert.NotNil(t, clave.Validate())
+}
@@ -0,0 +1,149 @@
+package sri
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Estados devueltos por los web services del SRI.
+const (
+	EstadoRecibida        = "RECIBIDA"
+	EstadoDevuelta        = "DEVUELTA"
+	EstadoAutorizado      = "AUTORIZADO"
+	EstadoNoAutorizado    = "NO AUTORIZADO"
+	EstadoEnProcesamiento = "EN PROCESAMIENTO"
+)
+
+// Direcciones de los web services offline del SRI.
+const (
+	URLRecepcionPruebas       = "https://celcer.sri.gob.ec/comprobantes-electronicos-ws/RecepcionComprobantesOffline"
+	URLAutorizacionPruebas    = "https://celcer.sri.gob.ec/comprobantes-electronicos-ws/AutorizacionComprobantesOffline"
+	URLRecepcionProduccion    = "https://cel.sri.gob.ec/comprobantes-electronicos-ws/RecepcionComprobantesOffline"
+	URLAutorizacionProduccion = "https://cel.sri.gob.ec/comprobantes-electronicos-ws/AutorizacionComprobantesOffline"
+)
+
+// Cliente submits signed comprobantes to the SRI. It is an interface so that the reception and
+// authorization web services can be replaced by a local stub.
+type Cliente interface {
+	// Enviar sends the signed comprobante to the reception web service.
+	Enviar(ctx context.Context, comprobante []byte) (Recepcion, error)
+	// Autorizar queries the authorization of the comprobante with the given clave de acceso.
+	Autorizar(ctx context.Context, claveAcceso string) (Autorizacion, error)
+}
+
+// Mensaje is an informative or error message returned by the SRI.
+type Mensaje struct {
+	Identificador        string `xml:"identificador" json:"identificador"`
+	Mensaje              string `xml:"mensaje" json:"mensaje"`
+	InformacionAdicional string `xml:"informacionAdicional" json:"informacion_adicional"`
+	Tipo                 string `xml:"tipo" json:"tipo"`
+}
+
+// Recepcion is the answer of the reception web service.
+type Recepcion struct {
+	Estado   string    `json:"estado"`
+	Mensajes []Mensaje `json:"mensajes"`
+}
+
+// Autorizacion is the answer of the authorization web service.
+type Autorizacion struct {
+	Estado             string    `xml:"estado" json:"estado"`
+	NumeroAutorizacion string    `xml:"numeroAutorizacion" json:"numero_autorizacion"`
+	FechaAutorizacion  string    `xml:"fechaAutorizacion" json:"fecha_autorizacion"`
+	Ambiente           string    `xml:"ambiente" json:"ambiente"`
+	Comprobante        string    `xml:"comprobante" json:"-"`
+	Mensajes           []Mensaje `xml:"mensajes>mensaje" json:"mensajes"`
+}
+
+type clienteSOAP struct {
+	urlRecepcion    string
+	urlAutorizacion string
+	http            *http.Client
+}
+
+// NuevoCliente creates a Cliente that calls the SOAP web services at the given addresses.
+func NuevoCliente(urlRecepcion, urlAutorizacion string, httpClient *http.Client) Cliente {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return clienteSOAP{urlRecepcion, urlAutorizacion, httpClient}
+}
+
+func (c clienteSOAP) Enviar(ctx context.Context, comprobante []byte) (Recepcion, error) {
+	cuerpo := `<ec:validarComprobante xmlns:ec="http://ec.gob.sri.ws.recepcion"><xml>` +
+		base64.StdEncoding.EncodeToString(comprobante) +
+		`</xml></ec:validarComprobante>`
+	var respuesta struct {
+		Body struct {
+			Respuesta struct {
+				Estado       string `xml:"RespuestaRecepcionComprobante>estado"`
+				Comprobantes []struct {
+					Mensajes []Mensaje `xml:"mensajes>mensaje"`
+				} `xml:"RespuestaRecepcionComprobante>comprobantes>comprobante"`
+			} `xml:"validarComprobanteResponse"`
+		} `xml:"Body"`
+	}
+	if err := c.llamar(ctx, c.urlRecepcion, cuerpo, &respuesta); err != nil {
+		return Recepcion{}, err
+	}
+	recepcion := Recepcion{Estado: respuesta.Body.Respuesta.Estado, Mensajes: []Mensaje{}}
+	for _, comprobante := range respuesta.Body.Respuesta.Comprobantes {
+		recepcion.Mensajes = append(recepcion.Mensajes, comprobante.Mensajes...)
+	}
+	return recepcion, nil
+}
+
+func (c clienteSOAP) Autorizar(ctx context.Context, claveAcceso string) (Autorizacion, error) {
+	var clave bytes.Buffer
+	if err := xml.EscapeText(&clave, []byte(claveAcceso)); err != nil {
+		return Autorizacion{}, err
+	}
+	cuerpo := `<ec:autorizacionComprobante xmlns:ec="http://ec.gob.sri.ws.autorizacion"><claveAccesoComprobante>` +
+		clave.String() +
+		`</claveAccesoComprobante></ec:autorizacionComprobante>`
+	var respuesta struct {
+		Body struct {
+			Respuesta struct {
+				Autorizaciones []Autorizacion `xml:"RespuestaAutorizacionComprobante>autorizaciones>autorizacion"`
+			} `xml:"autorizacionComprobanteResponse"`
+		} `xml:"Body"`
+	}
+	if err := c.llamar(ctx, c.urlAutorizacion, cuerpo, &respuesta); err != nil {
+		return Autorizacion{}, err
+	}
+	// Sin autorizaciones el comprobante todavía no ha sido procesado
+	if len(respuesta.Body.Respuesta.Autorizaciones) == 0 {
+		return Autorizacion{Estado: EstadoEnProcesamiento, Mensajes: []Mensaje{}}, nil
+	}
+	return respuesta.Body.Respuesta.Autorizaciones[0], nil
+}
+
+func (c clienteSOAP) llamar(ctx context.Context, url string, cuerpo string, respuesta interface{}) error {
+	sobre := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">` +
+		`<soapenv:Header></soapenv:Header><soapenv:Body>` + cuerpo + `</soapenv:Body></soapenv:Envelope>`
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(sobre))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	contenido, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("sri: %s respondió %d", url, res.StatusCode)
+	}
+	return xml.Unmarshal(contenido, respuesta)
+}
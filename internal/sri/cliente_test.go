@@ -0,0 +1,63 @@
+package sri
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const respuestaRecepcion = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+	`<ns2:validarComprobanteResponse xmlns:ns2="http://ec.gob.sri.ws.recepcion"><RespuestaRecepcionComprobante>` +
+	`<estado>DEVUELTA</estado><comprobantes><comprobante><claveAcceso>123</claveAcceso><mensajes><mensaje>` +
+	`<identificador>43</identificador><mensaje>CLAVE ACCESO REGISTRADA</mensaje><tipo>ERROR</tipo>` +
+	`</mensaje></mensajes></comprobante></comprobantes></RespuestaRecepcionComprobante>` +
+	`</ns2:validarComprobanteResponse></soap:Body></soap:Envelope>`
+
+const respuestaAutorizacion = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+	`<ns2:autorizacionComprobanteResponse xmlns:ns2="http://ec.gob.sri.ws.autorizacion"><RespuestaAutorizacionComprobante>` +
+	`<claveAccesoConsultada>123</claveAccesoConsultada><numeroComprobantes>1</numeroComprobantes><autorizaciones><autorizacion>` +
+	`<estado>AUTORIZADO</estado><numeroAutorizacion>123</numeroAutorizacion><fechaAutorizacion>2022-06-15T10:00:00-05:00</fechaAutorizacion>` +
+	`<ambiente>PRUEBAS</ambiente><comprobante><![CDATA[<factura></factura>]]></comprobante><mensajes></mensajes>` +
+	`</autorizacion></autorizaciones></RespuestaAutorizacionComprobante>` +
+	`</ns2:autorizacionComprobanteResponse></soap:Body></soap:Envelope>`
+
+func TestCliente(t *testing.T) {
+	var recibido string
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contenido, _ := ioutil.ReadAll(r.Body)
+		recibido = string(contenido)
+		if strings.HasSuffix(r.URL.Path, "/recepcion") {
+			_, _ = w.Write([]byte(respuestaRecepcion))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/autorizacion") {
+			_, _ = w.Write([]byte(respuestaAutorizacion))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer stub.Close()
+
+	ctx := context.Background()
+	c := NuevoCliente(stub.URL+"/recepcion", stub.URL+"/autorizacion", nil)
+
+	recepcion, err := c.Enviar(ctx, []byte("<factura></factura>"))
+	assert.Nil(t, err)
+	assert.Contains(t, recibido, "<xml>PGZhY3R1cmE+PC9mYWN0dXJhPg==</xml>")
+	assert.Equal(t, EstadoDevuelta, recepcion.Estado)
+	assert.Equal(t, "CLAVE ACCESO REGISTRADA", recepcion.Mensajes[0].Mensaje)
+
+	autorizacion, err := c.Autorizar(ctx, "123")
+	assert.Nil(t, err)
+	assert.Contains(t, recibido, "<claveAccesoComprobante>123</claveAccesoComprobante>")
+	assert.Equal(t, EstadoAutorizado, autorizacion.Estado)
+	assert.Equal(t, "<factura></factura>", autorizacion.Comprobante)
+
+	_, err = NuevoCliente(stub.URL+"/otro", stub.URL+"/otro", nil).Enviar(ctx, []byte("<factura></factura>"))
+	assert.NotNil(t, err)
+}
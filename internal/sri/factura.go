@@ -0,0 +1,279 @@
+package sri
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"veterinaria-server/pkg/money"
+)
+
+// Códigos de impuesto y forma de pago de la ficha técnica del SRI.
+const (
+	CodigoImpuestoIva             = "2"
+	MonedaDolar                   = "DOLAR"
+	FormaPagoSinSistemaFinanciero = "01"
+	identificacionConsumidorFinal = "9999999999999"
+)
+
+// codigosPorcentajeIva maps the IVA rates to the codigoPorcentaje of the SRI catalogue.
+var codigosPorcentajeIva = map[int]string{
+	0:  "0",
+	12: "2",
+	14: "3",
+	15: "4",
+	5:  "5",
+	8:  "8",
+	13: "10",
+}
+
+// Emisor holds the taxpayer data printed on every comprobante.
+type Emisor struct {
+	Ambiente                 string
+	Ruc                      string
+	RazonSocial              string
+	NombreComercial          string
+	DireccionMatriz          string
+	DireccionEstablecimiento string
+	Establecimiento          string
+	PuntoEmision             string
+	ObligadoContabilidad     string
+}
+
+// Comprador identifies the buyer of a factura.
+type Comprador struct {
+	Identificacion string
+	RazonSocial    string
+	Direccion      string
+	Correo         string
+	Telefono       string
+}
+
+// ItemFactura is a line of the factura with the amounts already computed. TarifaIva is 0 for
+// lines that do not carry IVA.
+type ItemFactura struct {
+	Codigo         string
+	Descripcion    string
+	Cantidad       float64
+	PrecioUnitario money.Money
+	Descuento      money.Money
+	Subtotal       money.Money
+	TarifaIva      int
+	ValorIva       money.Money
+}
+
+// Factura is the comprobante electrónico "factura" version 1.1.0.
+type Factura struct {
+	XMLName        xml.Name         `xml:"factura"`
+	Id             string           `xml:"id,attr"`
+	Version        string           `xml:"version,attr"`
+	InfoTributaria InfoTributaria   `xml:"infoTributaria"`
+	InfoFactura    InfoFactura      `xml:"infoFactura"`
+	Detalles       []Detalle        `xml:"detalles>detalle"`
+	InfoAdicional  []CampoAdicional `xml:"infoAdicional>campoAdicional,omitempty"`
+}
+
+type InfoTributaria struct {
+	Ambiente        string `xml:"ambiente"`
+	TipoEmision     string `xml:"tipoEmision"`
+	RazonSocial     string `xml:"razonSocial"`
+	NombreComercial string `xml:"nombreComercial,omitempty"`
+	Ruc             string `xml:"ruc"`
+	ClaveAcceso     string `xml:"claveAcceso"`
+	CodDoc          string `xml:"codDoc"`
+	Estab           string `xml:"estab"`
+	PtoEmi          string `xml:"ptoEmi"`
+	Secuencial      string `xml:"secuencial"`
+	DirMatriz       string `xml:"dirMatriz"`
+}
+
+type InfoFactura struct {
+	FechaEmision                string          `xml:"fechaEmision"`
+	DirEstablecimiento          string          `xml:"dirEstablecimiento,omitempty"`
+	ObligadoContabilidad        string          `xml:"obligadoContabilidad,omitempty"`
+	TipoIdentificacionComprador string          `xml:"tipoIdentificacionComprador"`
+	RazonSocialComprador        string          `xml:"razonSocialComprador"`
+	IdentificacionComprador     string          `xml:"identificacionComprador"`
+	DireccionComprador          string          `xml:"direccionComprador,omitempty"`
+	TotalSinImpuestos           string          `xml:"totalSinImpuestos"`
+	TotalDescuento              string          `xml:"totalDescuento"`
+	TotalConImpuestos           []TotalImpuesto `xml:"totalConImpuestos>totalImpuesto"`
+	Propina                     string          `xml:"propina"`
+	ImporteTotal                string          `xml:"importeTotal"`
+	Moneda                      string          `xml:"moneda"`
+	Pagos                       []Pago          `xml:"pagos>pago"`
+}
+
+type TotalImpuesto struct {
+	Codigo           string `xml:"codigo"`
+	CodigoPorcentaje string `xml:"codigoPorcentaje"`
+	BaseImponible    string `xml:"baseImponible"`
+	Valor            string `xml:"valor"`
+}
+
+type Pago struct {
+	FormaPago string `xml:"formaPago"`
+	Total     string `xml:"total"`
+}
+
+type Detalle struct {
+	CodigoPrincipal        string     `xml:"codigoPrincipal"`
+	Descripcion            string     `xml:"descripcion"`
+	Cantidad               string     `xml:"cantidad"`
+	PrecioUnitario         string     `xml:"precioUnitario"`
+	Descuento              string     `xml:"descuento"`
+	PrecioTotalSinImpuesto string     `xml:"precioTotalSinImpuesto"`
+	Impuestos              []Impuesto `xml:"impuestos>impuesto"`
+}
+
+type Impuesto struct {
+	Codigo           string `xml:"codigo"`
+	CodigoPorcentaje string `xml:"codigoPorcentaje"`
+	Tarifa           string `xml:"tarifa"`
+	BaseImponible    string `xml:"baseImponible"`
+	Valor            string `xml:"valor"`
+}
+
+type CampoAdicional struct {
+	Nombre string `xml:"nombre,attr"`
+	Valor  string `xml:",chardata"`
+}
+
+// NuevaFactura builds the comprobante of a factura, grouping the IVA of its lines by rate.
+func NuevaFactura(emisor Emisor, clave ClaveAcceso, comprador Comprador, items []ItemFactura) (Factura, error) {
+	if err := clave.Validate(); err != nil {
+		return Factura{}, err
+	}
+	if len(items) == 0 {
+		return Factura{}, fmt.Errorf("sri: la factura no tiene detalles")
+	}
+
+	detalles := []Detalle{}
+	bases := map[string]money.Money{}
+	impuestos := map[string]money.Money{}
+	codigos := []string{}
+	var totalSinImpuestos, totalDescuento, importeTotal money.Money
+	for _, item := range items {
+		codigo, ok := codigosPorcentajeIva[item.TarifaIva]
+		if !ok {
+			return Factura{}, fmt.Errorf("sri: tarifa de IVA no soportada: %d", item.TarifaIva)
+		}
+		detalles = append(detalles, Detalle{
+			CodigoPrincipal:        limpiar(item.Codigo),
+			Descripcion:            limpiar(item.Descripcion),
+			Cantidad:               fmt.Sprintf("%.6f", item.Cantidad),
+			PrecioUnitario:         item.PrecioUnitario.String(),
+			Descuento:              item.Descuento.String(),
+			PrecioTotalSinImpuesto: item.Subtotal.String(),
+			Impuestos: []Impuesto{{
+				Codigo:           CodigoImpuestoIva,
+				CodigoPorcentaje: codigo,
+				Tarifa:           fmt.Sprintf("%d", item.TarifaIva),
+				BaseImponible:    item.Subtotal.String(),
+				Valor:            item.ValorIva.String(),
+			}},
+		})
+
+		if _, ok := bases[codigo]; !ok {
+			codigos = append(codigos, codigo)
+		}
+		bases[codigo] += item.Subtotal
+		impuestos[codigo] += item.ValorIva
+		totalSinImpuestos += item.Subtotal
+		totalDescuento += item.Descuento
+		importeTotal += item.Subtotal + item.ValorIva
+	}
+
+	totalConImpuestos := []TotalImpuesto{}
+	for _, codigo := range codigos {
+		totalConImpuestos = append(totalConImpuestos, TotalImpuesto{
+			Codigo:           CodigoImpuestoIva,
+			CodigoPorcentaje: codigo,
+			BaseImponible:    bases[codigo].String(),
+			Valor:            impuestos[codigo].String(),
+		})
+	}
+
+	factura := Factura{
+		Id:      "comprobante",
+		Version: "1.1.0",
+		InfoTributaria: InfoTributaria{
+			Ambiente:        clave.Ambiente,
+			TipoEmision:     clave.TipoEmision,
+			RazonSocial:     limpiar(emisor.RazonSocial),
+			NombreComercial: limpiar(emisor.NombreComercial),
+			Ruc:             clave.Ruc,
+			ClaveAcceso:     clave.String(),
+			CodDoc:          clave.CodDoc,
+			Estab:           clave.Establecimiento,
+			PtoEmi:          clave.PuntoEmision,
+			Secuencial:      fmt.Sprintf("%09d", clave.Secuencial),
+			DirMatriz:       limpiar(emisor.DireccionMatriz),
+		},
+		InfoFactura: InfoFactura{
+			FechaEmision:                clave.FechaEmision.Format("02/01/2006"),
+			DirEstablecimiento:          limpiar(emisor.DireccionEstablecimiento),
+			ObligadoContabilidad:        emisor.ObligadoContabilidad,
+			TipoIdentificacionComprador: TipoIdentificacion(comprador.Identificacion),
+			RazonSocialComprador:        limpiar(comprador.RazonSocial),
+			IdentificacionComprador:     comprador.Identificacion,
+			DireccionComprador:          limpiar(comprador.Direccion),
+			TotalSinImpuestos:           totalSinImpuestos.String(),
+			TotalDescuento:              totalDescuento.String(),
+			TotalConImpuestos:           totalConImpuestos,
+			Propina:                     "0.00",
+			ImporteTotal:                importeTotal.String(),
+			Moneda:                      MonedaDolar,
+			Pagos: []Pago{{
+				FormaPago: FormaPagoSinSistemaFinanciero,
+				Total:     importeTotal.String(),
+			}},
+		},
+		Detalles: detalles,
+	}
+	if comprador.Correo != "" {
+		factura.InfoAdicional = append(factura.InfoAdicional, CampoAdicional{Nombre: "Email", Valor: limpiar(comprador.Correo)})
+	}
+	if comprador.Telefono != "" {
+		factura.InfoAdicional = append(factura.InfoAdicional, CampoAdicional{Nombre: "Telefono", Valor: limpiar(comprador.Telefono)})
+	}
+	return factura, nil
+}
+
+// XML serializes the comprobante in canonical form, ready to be signed.
+func (f Factura) XML() ([]byte, error) {
+	contenido, err := xml.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), canonizarTexto(contenido)...), nil
+}
+
+// TipoIdentificacion returns the SRI code of the buyer identification: RUC, cédula,
+// consumidor final or pasaporte.
+func TipoIdentificacion(identificacion string) string {
+	switch {
+	case identificacion == identificacionConsumidorFinal:
+		return "07"
+	case len(identificacion) == 13 && esNumerico(identificacion):
+		return "04"
+	case len(identificacion) == 10 && esNumerico(identificacion):
+		return "05"
+	}
+	return "06"
+}
+
+// limpiar replaces control characters, which the SRI rejects and C14N would escape differently.
+func limpiar(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+// canonizarTexto undoes the quote escaping of encoding/xml, which C14N leaves as is in text nodes.
+func canonizarTexto(contenido []byte) []byte {
+	s := strings.NewReplacer("&#34;", `"`, "&#39;", "'").Replace(string(contenido))
+	return []byte(s)
+}
@@ -0,0 +1,75 @@
+package sri
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func facturaDePrueba(t *testing.T) Factura {
+	factura, err := NuevaFactura(
+		Emisor{
+			Ambiente:             AmbientePruebas,
+			Ruc:                  "0912345678001",
+			RazonSocial:          "Veterinaria de prueba",
+			DireccionMatriz:      "Guayaquil",
+			ObligadoContabilidad: "NO",
+		},
+		ClaveAcceso{
+			FechaEmision:    time.Date(2022, 6, 15, 10, 0, 0, 0, time.UTC),
+			CodDoc:          CodDocFactura,
+			Ruc:             "0912345678001",
+			Ambiente:        AmbientePruebas,
+			Establecimiento: "001",
+			PuntoEmision:    "001",
+			Secuencial:      1,
+			CodigoNumerico:  "12345678",
+			TipoEmision:     TipoEmisionNormal,
+		},
+		Comprador{Identificacion: "0912345678", RazonSocial: `Juan "Perez"`, Correo: "juan@example.com"},
+		[]ItemFactura{
+			{Codigo: "1", Descripcion: "Antiparasitario", Cantidad: 2, PrecioUnitario: 500, Subtotal: 1000, TarifaIva: 12, ValorIva: 120},
+			{Codigo: "2", Descripcion: "Vacuna", Cantidad: 1, PrecioUnitario: 1600, Descuento: 100, Subtotal: 1500},
+		},
+	)
+	assert.Nil(t, err)
+	return factura
+}
+
+func TestNuevaFactura(t *testing.T) {
+	factura := facturaDePrueba(t)
+	assert.Equal(t, "05", factura.InfoFactura.TipoIdentificacionComprador)
+	assert.Equal(t, "15/06/2022", factura.InfoFactura.FechaEmision)
+	assert.Equal(t, "000000001", factura.InfoTributaria.Secuencial)
+	assert.Equal(t, "25.00", factura.InfoFactura.TotalSinImpuestos)
+	assert.Equal(t, "26.20", factura.InfoFactura.ImporteTotal)
+	assert.Equal(t, []TotalImpuesto{
+		{Codigo: "2", CodigoPorcentaje: "2", BaseImponible: "10.00", Valor: "1.20"},
+		{Codigo: "2", CodigoPorcentaje: "0", BaseImponible: "15.00", Valor: "0.00"},
+	}, factura.InfoFactura.TotalConImpuestos)
+	assert.Equal(t, "1.00", factura.InfoFactura.TotalDescuento)
+	assert.Equal(t, "5.00", factura.Detalles[0].PrecioUnitario)
+
+	_, err := NuevaFactura(Emisor{}, ClaveAcceso{}, Comprador{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestFactura_XML(t *testing.T) {
+	contenido, err := facturaDePrueba(t).XML()
+	assert.Nil(t, err)
+	s := string(contenido)
+	assert.True(t, strings.HasPrefix(s, xml.Header+`<factura id="comprobante" version="1.1.0"><infoTributaria>`))
+	assert.Contains(t, s, `<razonSocialComprador>Juan "Perez"</razonSocialComprador>`)
+	assert.Contains(t, s, `<campoAdicional nombre="Email">juan@example.com</campoAdicional>`)
+	assert.NotContains(t, s, "/>")
+}
+
+func TestTipoIdentificacion(t *testing.T) {
+	assert.Equal(t, "07", TipoIdentificacion("9999999999999"))
+	assert.Equal(t, "04", TipoIdentificacion("0912345678001"))
+	assert.Equal(t, "05", TipoIdentificacion("0912345678"))
+	assert.Equal(t, "06", TipoIdentificacion("AB123456"))
+}
@@ -0,0 +1,182 @@
+package sri
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+const (
+	nsDs   = "http://www.w3.org/2000/09/xmldsig#"
+	nsEtsi = "http://uri.etsi.org/01903/v1.3.2#"
+)
+
+// Firmador signs comprobantes before they are sent to the SRI.
+type Firmador interface {
+	// Firmar returns the comprobante with an enveloped XAdES-BES signature.
+	Firmar(comprobante []byte) ([]byte, error)
+}
+
+type firmador struct {
+	llave       *rsa.PrivateKey
+	certificado *x509.Certificate
+	ahora       func() time.Time
+}
+
+// NuevoFirmador creates a XAdES-BES signer from a PKCS#12 (.p12) certificate issued for electronic invoicing.
+// When the file holds the whole chain, the certificate that matches the private key is used.
+func NuevoFirmador(p12 []byte, clave string) (Firmador, error) {
+	bloques, err := pkcs12.ToPEM(p12, clave)
+	if err != nil {
+		return nil, err
+	}
+	var llave *rsa.PrivateKey
+	certificados := []*x509.Certificate{}
+	for _, bloque := range bloques {
+		switch bloque.Type {
+		case "PRIVATE KEY":
+			if llave, err = x509.ParsePKCS1PrivateKey(bloque.Bytes); err != nil {
+				return nil, err
+			}
+		case "CERTIFICATE":
+			certificado, err := x509.ParseCertificate(bloque.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certificados = append(certificados, certificado)
+		}
+	}
+	if llave == nil {
+		return nil, errors.New("sri: el certificado no contiene una llave privada RSA")
+	}
+	for _, certificado := range certificados {
+		if publica, ok := certificado.PublicKey.(*rsa.PublicKey); ok && publica.N.Cmp(llave.N) == 0 {
+			return nuevoFirmador(llave, certificado), nil
+		}
+	}
+	return nil, errors.New("sri: el certificado no corresponde a la llave privada")
+}
+
+func nuevoFirmador(llave *rsa.PrivateKey, certificado *x509.Certificate) firmador {
+	return firmador{llave, certificado, time.Now}
+}
+
+// Firmar signs the comprobante following the XAdES-BES profile required by the SRI: RSA-SHA1 over the
+// inclusive C14N of SignedInfo, which references the comprobante, the signed properties and the certificate.
+// The comprobante must already be canonical, as produced by Factura.XML.
+func (f firmador) Firmar(comprobante []byte) ([]byte, error) {
+	documento := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(comprobante), []byte(strings.TrimSpace(xml.Header))))
+	cierre := bytes.LastIndex(documento, []byte("</"))
+	if cierre < 0 {
+		return nil, errors.New("sri: comprobante inválido")
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, err
+	}
+	id := n.String()
+	namespaces := fmt.Sprintf(` xmlns:ds="%s" xmlns:etsi="%s"`, nsDs, nsEtsi)
+
+	certificado := base64.StdEncoding.EncodeToString(f.certificado.Raw)
+	digestCertificado := digest(f.certificado.Raw)
+	modulo := base64.StdEncoding.EncodeToString(f.llave.PublicKey.N.Bytes())
+	exponente := base64.StdEncoding.EncodeToString(big.NewInt(int64(f.llave.PublicKey.E)).Bytes())
+
+	propiedades := `<etsi:SignedProperties Id="Signature` + id + `-SignedProperties` + id + `">` +
+		`<etsi:SignedSignatureProperties>` +
+		`<etsi:SigningTime>` + f.ahora().Format("2006-01-02T15:04:05-07:00") + `</etsi:SigningTime>` +
+		`<etsi:SigningCertificate><etsi:Cert><etsi:CertDigest>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2000/09/xmldsig#sha1"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + digestCertificado + `</ds:DigestValue>` +
+		`</etsi:CertDigest><etsi:IssuerSerial>` +
+		`<ds:X509IssuerName>` + escaparTexto(f.certificado.Issuer.String()) + `</ds:X509IssuerName>` +
+		`<ds:X509SerialNumber>` + f.certificado.SerialNumber.String() + `</ds:X509SerialNumber>` +
+		`</etsi:IssuerSerial></etsi:Cert></etsi:SigningCertificate>` +
+		`</etsi:SignedSignatureProperties>` +
+		`<etsi:SignedDataObjectProperties>` +
+		`<etsi:DataObjectFormat ObjectReference="#Reference-ID-` + id + `">` +
+		`<etsi:Description>contenido comprobante</etsi:Description>` +
+		`<etsi:MimeType>text/xml</etsi:MimeType>` +
+		`</etsi:DataObjectFormat>` +
+		`</etsi:SignedDataObjectProperties>` +
+		`</etsi:SignedProperties>`
+
+	keyInfo := `<ds:KeyInfo Id="Certificate` + id + `">` +
+		`<ds:X509Data><ds:X509Certificate>` + certificado + `</ds:X509Certificate></ds:X509Data>` +
+		`<ds:KeyValue><ds:RSAKeyValue>` +
+		`<ds:Modulus>` + modulo + `</ds:Modulus>` +
+		`<ds:Exponent>` + exponente + `</ds:Exponent>` +
+		`</ds:RSAKeyValue></ds:KeyValue>` +
+		`</ds:KeyInfo>`
+
+	signedInfo := `<ds:SignedInfo Id="Signature-SignedInfo` + id + `">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/TR/2001/REC-xml-c14n-20010315"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2000/09/xmldsig#rsa-sha1"></ds:SignatureMethod>` +
+		`<ds:Reference Id="SignedPropertiesID` + id + `" Type="http://uri.etsi.org/01903#SignedProperties" URI="#Signature` + id + `-SignedProperties` + id + `">` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2000/09/xmldsig#sha1"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + digest([]byte(conNamespaces(propiedades, namespaces))) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`<ds:Reference URI="#Certificate` + id + `">` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2000/09/xmldsig#sha1"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + digest([]byte(conNamespaces(keyInfo, namespaces))) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`<ds:Reference Id="Reference-ID-` + id + `" URI="#comprobante">` +
+		`<ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform></ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2000/09/xmldsig#sha1"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + digest(documento) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	hash := sha1.Sum([]byte(conNamespaces(signedInfo, namespaces)))
+	valor, err := rsa.SignPKCS1v15(rand.Reader, f.llave, crypto.SHA1, hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	firma := `<ds:Signature` + namespaces + ` Id="Signature` + id + `">` +
+		signedInfo +
+		`<ds:SignatureValue Id="SignatureValue` + id + `">` + base64.StdEncoding.EncodeToString(valor) + `</ds:SignatureValue>` +
+		keyInfo +
+		`<ds:Object Id="Signature` + id + `-Object` + id + `">` +
+		`<etsi:QualifyingProperties Target="#Signature` + id + `">` +
+		propiedades +
+		`</etsi:QualifyingProperties>` +
+		`</ds:Object>` +
+		`</ds:Signature>`
+
+	var firmado bytes.Buffer
+	firmado.WriteString(xml.Header)
+	firmado.Write(documento[:cierre])
+	firmado.WriteString(firma)
+	firmado.Write(documento[cierre:])
+	return firmado.Bytes(), nil
+}
+
+func digest(contenido []byte) string {
+	hash := sha1.Sum(contenido)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// conNamespaces renders an element of the signature as inclusive C14N does when it is digested on its own:
+// the namespaces declared on ds:Signature are written on the element itself.
+func conNamespaces(elemento string, namespaces string) string {
+	fin := strings.IndexAny(elemento, " >")
+	return elemento[:fin] + namespaces + elemento[fin:]
+}
+
+func escaparTexto(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace(s)
+}
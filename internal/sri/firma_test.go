@@ -0,0 +1,76 @@
+package sri
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func firmadorDePrueba(t *testing.T) firmador {
+	llave, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	plantilla := x509.Certificate{
+		SerialNumber: big.NewInt(12345),
+		Subject:      pkix.Name{CommonName: "Veterinaria de prueba"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &plantilla, &plantilla, &llave.PublicKey, llave)
+	assert.Nil(t, err)
+	certificado, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return nuevoFirmador(llave, certificado)
+}
+
+func Test_firmador_Firmar(t *testing.T) {
+	f := firmadorDePrueba(t)
+	comprobante, err := facturaDePrueba(t).XML()
+	assert.Nil(t, err)
+
+	firmado, err := f.Firmar(comprobante)
+	assert.Nil(t, err)
+	s := string(firmado)
+	assert.True(t, strings.HasPrefix(s, xml.Header))
+	assert.True(t, strings.HasSuffix(s, "</ds:Signature></factura>"))
+
+	// el documento firmado sigue siendo XML válido
+	var factura Factura
+	assert.Nil(t, xml.Unmarshal(firmado, &factura))
+
+	// la transformación enveloped-signature devuelve el comprobante original
+	inicio := strings.Index(s, "<ds:Signature ")
+	fin := strings.Index(s, "</ds:Signature>") + len("</ds:Signature>")
+	assert.Equal(t, string(comprobante), s[:inicio]+s[fin:])
+
+	digestValues := regexp.MustCompile(`<ds:DigestValue>([^<]*)</ds:DigestValue>`).FindAllStringSubmatch(s, -1)
+	documento := strings.TrimPrefix(string(comprobante), xml.Header)
+	assert.Equal(t, digest([]byte(documento)), digestValues[2][1])
+
+	// la firma corresponde al SignedInfo canonizado
+	signedInfo := regexp.MustCompile(`<ds:SignedInfo .*</ds:SignedInfo>`).FindString(s)
+	hash := sha1.Sum([]byte(conNamespaces(signedInfo, ` xmlns:ds="`+nsDs+`" xmlns:etsi="`+nsEtsi+`"`)))
+	valor, err := base64.StdEncoding.DecodeString(regexp.MustCompile(`<ds:SignatureValue [^>]*>([^<]*)<`).FindStringSubmatch(s)[1])
+	assert.Nil(t, err)
+	assert.Nil(t, rsa.VerifyPKCS1v15(&f.llave.PublicKey, crypto.SHA1, hash[:], valor))
+
+	_, err = f.Firmar([]byte("sin xml"))
+	assert.NotNil(t, err)
+}
+
+func TestNuevoFirmador(t *testing.T) {
+	_, err := NuevoFirmador([]byte("no es un p12"), "clave")
+	assert.NotNil(t, err)
+}
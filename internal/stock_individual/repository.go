@@ -2,9 +2,11 @@ package stock_individual
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access stocksIndividual from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetStockIndividualPorId returns the stockIndividual with the specified stockIndividual ID.
 	GetStockIndividualPorId(ctx context.Context, idStockIndividual int) (entity.StockIndividual, error)
 	// GetStocksIndividual returns the list stocksIndividual.
-	GetStocksIndividual(ctx context.Context) ([]entity.StockIndividual, error)
+	GetStocksIndividual(ctx context.Context, query pagination.Query) ([]entity.StockIndividual, *pagination.Pages, error)
 	CrearStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error)
 	ActualizarStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error)
 }
@@ -28,23 +30,35 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposStocksIndividual are the fields the list of stocks individual can be filtered, searched and sorted by.
+var camposStocksIndividual = pagination.Fields{
+	Filters: map[string]string{"id_lote": "id_lote"},
+	Search:  []string{"descripcion"},
+	Sort: map[string]string{
+		"id_stock_individual": "id_stock_individual",
+		"id_lote":             "id_lote",
+		"descripcion":         "descripcion",
+		"cantidad":            "cantidad",
+		"cantidad_inicial":    "cantidad_inicial",
+	},
+	DefaultSort: []string{"id_stock_individual asc"},
+}
+
 // Get reads the list stocksIndividual from the database.
-func (r repository) GetStocksIndividual(ctx context.Context) ([]entity.StockIndividual, error) {
-	var stocksIndividual []entity.StockIndividual
+func (r repository) GetStocksIndividual(ctx context.Context, query pagination.Query) ([]entity.StockIndividual, *pagination.Pages, error) {
+	var stocksIndividual []entity.StockIndividual = []entity.StockIndividual{}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&stocksIndividual)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("stock_individual"), camposStocksIndividual, &stocksIndividual)
 	if err != nil {
-		return stocksIndividual, err
+		return nil, nil, err
 	}
-	return stocksIndividual, err
+	return stocksIndividual, pages, nil
 }
 
 // Create saves a new StockIndividual record in the database.
 // It returns the ID of the newly inserted stockIndividual record.
 func (r repository) CrearStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error) {
-	err := r.db.With(ctx).Model(&stockIndividual).Insert()
+	err := auditoria.Insertar(ctx, r.db, &stockIndividual)
 	if err != nil {
 		return entity.StockIndividual{}, err
 	}
@@ -56,9 +70,9 @@ func (r repository) CrearStockIndividual(ctx context.Context, stockIndividual en
 func (r repository) ActualizarStockIndividual(ctx context.Context, stockIndividual entity.StockIndividual) (entity.StockIndividual, error) {
 	var err error
 	if stockIndividual.IdStockIndividual != 0 {
-		err = r.db.With(ctx).Model(&stockIndividual).Update()
+		err = auditoria.Actualizar(ctx, r.db, &stockIndividual, "IdLote", "Descripcion", "CantidadInicial")
 	} else {
-		err = r.db.With(ctx).Model(&stockIndividual).Insert()
+		err = auditoria.Insertar(ctx, r.db, &stockIndividual)
 	}
 	if err != nil {
 		return entity.StockIndividual{}, err
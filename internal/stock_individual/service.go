@@ -3,14 +3,16 @@ package stock_individual
 import (
 	"context"
 	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/movimiento_inventario"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for stocksIndividual.
 type Service interface {
-	GetStocksIndividual(ctx context.Context) ([]StockIndividual, error)
+	GetStocksIndividual(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetStockIndividualPorId(ctx context.Context, idStockIndividual int) (StockIndividual, error)
 	CrearStockIndividual(ctx context.Context, input CreateStockIndividualRequest) (StockIndividual, error)
 	ActualizarStockIndividual(ctx context.Context, input UpdateStockIndividualRequest) (StockIndividual, error)
@@ -22,18 +24,19 @@ type StockIndividual struct {
 }
 
 type service struct {
-	repo   Repository
-	logger log.Logger
+	repo        Repository
+	movimientos movimiento_inventario.Service
+	logger      log.Logger
 }
 
 // NewService creates a new stocksIndividual service.
-func NewService(repo Repository, logger log.Logger) Service {
-	return service{repo, logger}
+func NewService(repo Repository, movimientos movimiento_inventario.Service, logger log.Logger) Service {
+	return service{repo, movimientos, logger}
 }
 
 // Get returns the list stocksIndividual.
-func (s service) GetStocksIndividual(ctx context.Context) ([]StockIndividual, error) {
-	stocksIndividual, err := s.repo.GetStocksIndividual(ctx)
+func (s service) GetStocksIndividual(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	stocksIndividual, pages, err := s.repo.GetStocksIndividual(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +44,8 @@ func (s service) GetStocksIndividual(ctx context.Context) ([]StockIndividual, er
 	for _, item := range stocksIndividual {
 		result = append(result, StockIndividual{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateStockIndividualRequest represents an stockIndividual creation request.
@@ -66,6 +70,7 @@ func (m UpdateStockIndividualRequest) ValidateUpdate() error {
 		validation.Field(&m.IdLote, validation.Required),
 		validation.Field(&m.CantidadInicial, validation.Required),
 		//validation.Field(&m.Cantidad, validation.Required),
+		validation.Field(&m.Cantidad, validation.Min(float32(0))),
 		validation.Field(&m.Descripcion, validation.Required, validation.Length(0, 1000)),
 	)
 }
@@ -75,7 +80,7 @@ func (m CreateStockIndividualRequest) Validate() error {
 	return validation.ValidateStruct(&m,
 		validation.Field(&m.IdLote, validation.Required),
 		validation.Field(&m.CantidadInicial, validation.Required),
-		validation.Field(&m.Cantidad, validation.Required),
+		validation.Field(&m.Cantidad, validation.Required, validation.Min(float32(0))),
 		validation.Field(&m.Descripcion, validation.Required, validation.Length(0, 1000)),
 	)
 }
@@ -102,17 +107,27 @@ func (s service) ActualizarStockIndividual(ctx context.Context, req UpdateStockI
 	if err := req.ValidateUpdate(); err != nil {
 		return StockIndividual{}, err
 	}
-	stockIndividualG, err := s.repo.ActualizarStockIndividual(ctx, entity.StockIndividual{
+	if req.IdStockIndividual == 0 {
+		return s.CrearStockIndividual(ctx, CreateStockIndividualRequest{
+			IdLote:          req.IdLote,
+			Descripcion:     req.Descripcion,
+			CantidadInicial: req.CantidadInicial,
+			Cantidad:        req.Cantidad,
+		})
+	}
+	//La cantidad solo cambia con un ajuste registrado en el ledger de inventario
+	if _, err := s.movimientos.AjustarStock(ctx, movimiento_inventario.TablaStockIndividual, req.IdStockIndividual, req.Cantidad); err != nil {
+		return StockIndividual{}, err
+	}
+	if _, err := s.repo.ActualizarStockIndividual(ctx, entity.StockIndividual{
 		IdStockIndividual: req.IdStockIndividual,
 		IdLote:            req.IdLote,
 		CantidadInicial:   req.CantidadInicial,
 		Descripcion:       req.Descripcion,
-		Cantidad:          req.Cantidad,
-	})
-	if err != nil {
+	}); err != nil {
 		return StockIndividual{}, err
 	}
-	return StockIndividual{stockIndividualG}, nil
+	return s.GetStockIndividualPorId(ctx, req.IdStockIndividual)
 }
 
 // GetStockIndividualPorId returns the stockIndividual with the specified the stockIndividual ID.
@@ -0,0 +1,60 @@
+package tarifa_iva
+
+import (
+	"net/http"
+	"time"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/tarifasIva", res.getTarifasIva)
+	r.Get("/tarifasIva/vigente/<fecha>", res.getTarifaIvaVigente)
+	r.Post("/tarifasIva", res.crearTarifaIva)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getTarifasIva(c *routing.Context) error {
+	pages, err := r.service.GetTarifasIva(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getTarifaIvaVigente(c *routing.Context) error {
+	fecha, err := time.Parse("2006-01-02", c.Param("fecha"))
+	if err != nil {
+		return errors.BadRequest("La fecha debe tener el formato AAAA-MM-DD.")
+	}
+	tarifaIva, err := r.service.GetTarifaIvaVigente(c.Request.Context(), fecha)
+	if err != nil {
+		return err
+	}
+	return c.Write(tarifaIva)
+}
+
+func (r resource) crearTarifaIva(c *routing.Context) error {
+	var input CreateTarifaIvaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	tarifaIva, err := r.service.CrearTarifaIva(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(tarifaIva, http.StatusCreated)
+}
@@ -0,0 +1,75 @@
+package tarifa_iva
+
+import (
+	"context"
+	"time"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Repository encapsulates the logic to access tarifasIva from the data source.
+type Repository interface {
+	// GetTarifasIva returns the list tarifasIva, the most recent first.
+	GetTarifasIva(ctx context.Context, query pagination.Query) ([]entity.TarifaIva, *pagination.Pages, error)
+	// GetTarifaIvaVigente returns the tarifaIva with the latest fecha_inicio not after the given fecha.
+	GetTarifaIvaVigente(ctx context.Context, fecha time.Time) (entity.TarifaIva, error)
+	CrearTarifaIva(ctx context.Context, tarifaIva entity.TarifaIva) (entity.TarifaIva, error)
+}
+
+// repository persists tarifasIva in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new tarifaIva repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposTarifasIva are the fields the list of tarifas iva can be filtered, searched and sorted by.
+var camposTarifasIva = pagination.Fields{
+	Ranges: map[string]string{"fecha_inicio": "fecha_inicio"},
+	Sort: map[string]string{
+		"id_tarifa_iva": "id_tarifa_iva",
+		"porcentaje":    "porcentaje",
+		"fecha_inicio":  "fecha_inicio",
+	},
+	DefaultSort: []string{"fecha_inicio desc"},
+}
+
+func (r repository) GetTarifasIva(ctx context.Context, query pagination.Query) ([]entity.TarifaIva, *pagination.Pages, error) {
+	var tarifasIva []entity.TarifaIva = []entity.TarifaIva{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("tarifas_iva"), camposTarifasIva, &tarifasIva)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tarifasIva, pages, nil
+}
+
+func (r repository) GetTarifaIvaVigente(ctx context.Context, fecha time.Time) (entity.TarifaIva, error) {
+	var tarifaIva entity.TarifaIva
+	err := r.db.With(ctx).
+		Select().
+		Where(dbx.NewExp("fecha_inicio <= {:fecha}", dbx.Params{"fecha": fecha})).
+		OrderBy("fecha_inicio desc").
+		Limit(1).
+		One(&tarifaIva)
+	return tarifaIva, err
+}
+
+// CrearTarifaIva saves a new TarifaIva record in the database.
+// It returns the tarifaIva with the ID of the newly inserted record.
+func (r repository) CrearTarifaIva(ctx context.Context, tarifaIva entity.TarifaIva) (entity.TarifaIva, error) {
+	err := auditoria.Insertar(ctx, r.db, &tarifaIva)
+	if err != nil {
+		return entity.TarifaIva{}, err
+	}
+	return tarifaIva, nil
+}
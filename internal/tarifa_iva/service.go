@@ -0,0 +1,92 @@
+package tarifa_iva
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Service encapsulates usecase logic for tarifasIva.
+type Service interface {
+	GetTarifasIva(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	// GetTarifaIvaVigente returns the IVA rate in force on the given fecha.
+	GetTarifaIvaVigente(ctx context.Context, fecha time.Time) (TarifaIva, error)
+	// CrearTarifaIva registers a new IVA rate from its fecha de inicio on. Past rates are kept so
+	// that older facturas keep the rate they were issued with.
+	CrearTarifaIva(ctx context.Context, input CreateTarifaIvaRequest) (TarifaIva, error)
+}
+
+// TarifaIva represents the data about a tarifaIva.
+type TarifaIva struct {
+	entity.TarifaIva
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new tarifasIva service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+// Get returns the list tarifasIva.
+func (s service) GetTarifasIva(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	tarifasIva, pages, err := s.repo.GetTarifasIva(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []TarifaIva{}
+	for _, item := range tarifasIva {
+		result = append(result, TarifaIva{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetTarifaIvaVigente(ctx context.Context, fecha time.Time) (TarifaIva, error) {
+	tarifaIva, err := s.repo.GetTarifaIvaVigente(ctx, fecha)
+	if err == sql.ErrNoRows {
+		return TarifaIva{}, errors.Conflict("No existe una tarifa de IVA vigente para la fecha " + fecha.Format("2006-01-02") + ".")
+	}
+	if err != nil {
+		return TarifaIva{}, err
+	}
+	return TarifaIva{tarifaIva}, nil
+}
+
+// CreateTarifaIvaRequest represents a tarifaIva creation request.
+type CreateTarifaIvaRequest struct {
+	Porcentaje  int       `json:"porcentaje"`
+	FechaInicio time.Time `json:"fecha_inicio"`
+}
+
+// Validate validates the CreateTarifaIvaRequest fields.
+func (m CreateTarifaIvaRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Porcentaje, validation.Min(0), validation.Max(100)),
+		validation.Field(&m.FechaInicio, validation.Required),
+	)
+}
+
+// CrearTarifaIva creates a new tarifaIva.
+func (s service) CrearTarifaIva(ctx context.Context, req CreateTarifaIvaRequest) (TarifaIva, error) {
+	if err := req.Validate(); err != nil {
+		return TarifaIva{}, err
+	}
+	tarifaIvaG, err := s.repo.CrearTarifaIva(ctx, entity.TarifaIva{
+		Porcentaje:  req.Porcentaje,
+		FechaInicio: req.FechaInicio,
+	})
+	if err != nil {
+		return TarifaIva{}, err
+	}
+	return TarifaIva{tarifaIvaG}, nil
+}
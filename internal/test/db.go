@@ -1,43 +1,171 @@
 package test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 	"veterinaria-server/internal/config"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/migrate"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
-	_ "github.com/go-sql-driver/mysql" // initialize mysql for test
+	"github.com/go-sql-driver/mysql"
 )
 
-var db *dbcontext.DB
+// EnvDSN is the environment variable with the DSN of the MySQL server used by the tests. When it is not
+// set, the DSN of config/local.yml is used.
+const EnvDSN = "APP_TEST_DSN"
 
-// DB returns the database connection for testing purpose.
-func DB(t *testing.T) *dbcontext.DB {
-	if db != nil {
-		return db
+// EnvRequerirDB is the environment variable that, set to 1, makes the tests that need a database fail instead of
+// being skipped when there is no server. make test sets it, so a run without database never passes by skipping.
+const EnvRequerirDB = "APP_TEST_REQUIRE_DB"
+
+var (
+	db    *dbcontext.DB
+	dbErr error
+	once  sync.Once
+)
+
+// errSinServidor reports that no MySQL server is configured for the tests.
+var errSinServidor = errors.New("no test database: set " + EnvDSN + " or create config/local.yml")
+
+// DB returns the database connection for testing purpose. Each package gets a disposable database,
+// veterinaria_test_<package>, which is dropped, created again and migrated with the migrations directory
+// the first time DB is called, in the MySQL server of EnvDSN. The test is skipped when there is no server,
+// unless EnvRequerirDB is set. There is no embedded or SQLite fallback: the migrations and the queries are
+// MySQL specific, so a MySQL server must be running.
+func DB(t testing.TB) *dbcontext.DB {
+	_, file, _, _ := runtime.Caller(1)
+	once.Do(func() {
+		db, dbErr = crearDB("veterinaria_test_" + path.Base(path.Dir(file)))
+	})
+	if dbErr == errSinServidor && os.Getenv(EnvRequerirDB) != "1" {
+		t.Skip(dbErr)
 	}
+	if dbErr != nil {
+		t.Error(dbErr)
+		t.FailNow()
+	}
+	return db
+}
+
+// crearDB creates the database with every migration applied.
+func crearDB(nombre string) (*dbcontext.DB, error) {
 	logger, _ := log.NewForTest()
 	dir := getSourcePath()
-	cfg, err := config.Load(dir+"/../../config/local.yml", logger)
+	dsn := os.Getenv(EnvDSN)
+	if dsn == "" {
+		if _, err := os.Stat(dir + "/../../config/local.yml"); err != nil {
+			return nil, errSinServidor
+		}
+		cfg, err := config.Load(dir+"/../../config/local.yml", logger)
+		if err != nil {
+			return nil, err
+		}
+		dsn = cfg.DSN
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+
+	//La base se crea desde el servidor, sin seleccionar ninguna base
+	servidor := *cfg
+	servidor.DBName = ""
+	conn, err := sql.Open("mysql", servidor.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	for _, sentencia := range []string{
+		"DROP DATABASE IF EXISTS `" + nombre + "`",
+		"CREATE DATABASE `" + nombre + "` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci",
+	} {
+		if _, err := conn.Exec(sentencia); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.DBName = nombre
+	dbc, err := dbx.MustOpen("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrate.New(dbc, dir+"/../../migrations", logger).Up(); err != nil {
+		return nil, err
+	}
+	dbc.LogFunc = logger.Infof
+	return dbcontext.New(dbc), nil
+}
+
+// Fixtures empties every table of the database and runs the statements of the SQL file, usually the
+// testdata/fixtures.sql of the package under test, so every test starts from the same data.
+func Fixtures(t testing.TB, db *dbcontext.DB, file string) {
+	fixtures, err := ioutil.ReadFile(file)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
-	dbc, err := dbx.MustOpen("mysql", cfg.DSN)
-	if err != nil {
+	if err := cargar(db, string(fixtures)); err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
-	dbc.LogFunc = logger.Infof
-	db = dbcontext.New(dbc)
-	return db
+}
+
+// cargar truncates the tables and loads the fixtures in a single connection, the one that does not
+// check foreign keys meanwhile.
+func cargar(db *dbcontext.DB, fixtures string) error {
+	ctx := context.Background()
+	conn, err := db.DB().DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+
+	rows, err := conn.QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name <> ?", migrate.Table)
+	if err != nil {
+		return err
+	}
+	tablas := []string{}
+	for rows.Next() {
+		var tabla string
+		if err := rows.Scan(&tabla); err != nil {
+			rows.Close()
+			return err
+		}
+		tablas = append(tablas, tabla)
+	}
+	rows.Close()
+	for _, tabla := range tablas {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE `"+tabla+"`"); err != nil {
+			return err
+		}
+	}
+	for _, sentencia := range migrate.Statements(fixtures) {
+		if _, err := conn.ExecContext(ctx, sentencia); err != nil {
+			return fmt.Errorf("%v: %s", err, sentencia)
+		}
+	}
+	return nil
 }
 
 // ResetTables truncates all data in the specified tables.
-func ResetTables(t *testing.T, db *dbcontext.DB, tables ...string) {
+func ResetTables(t testing.TB, db *dbcontext.DB, tables ...string) {
 	for _, table := range tables {
 		_, err := db.DB().TruncateTable(table).Execute()
 		if err != nil {
@@ -47,6 +175,63 @@ func ResetTables(t *testing.T, db *dbcontext.DB, tables ...string) {
 	}
 }
 
+// errRollback discards the transaction of Rollback.
+var errRollback = errors.New("rollback")
+
+// Rollback calls f with a context holding a transaction that is rolled back once f returns, so the
+// data f seeds is discarded.
+func Rollback(t testing.TB, db *dbcontext.DB, f func(ctx context.Context)) {
+	err := db.Transactional(context.Background(), func(ctx context.Context) error {
+		f(ctx)
+		return errRollback
+	})
+	if err != errRollback {
+		t.Error(err)
+		t.FailNow()
+	}
+}
+
+// Insert saves the models, pointers to entities, in the database of the context.
+func Insert(t testing.TB, ctx context.Context, db *dbcontext.DB, models ...interface{}) {
+	for _, model := range models {
+		if err := db.With(ctx).Model(model).Insert(); err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+	}
+}
+
+// Count returns the number of rows of the table matching where, every row when where is nil.
+func Count(t testing.TB, db *dbcontext.DB, table string, where dbx.Expression) int {
+	var count int
+	query := db.DB().Select("COUNT(*)").From(table)
+	if where != nil {
+		query.Where(where)
+	}
+	if err := query.Row(&count); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	return count
+}
+
+// CountQueries calls f and returns the number of SQL queries returning data that ran meanwhile.
+func CountQueries(db *dbcontext.DB, f func()) int {
+	var count int64
+	previous := db.DB().QueryLogFunc
+	db.DB().QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		atomic.AddInt64(&count, 1)
+		if previous != nil {
+			previous(ctx, t, sql, rows, err)
+		}
+	}
+	defer func() {
+		db.DB().QueryLogFunc = previous
+	}()
+	f()
+	return int(atomic.LoadInt64(&count))
+}
+
 // getSourcePath returns the directory containing the source code that is calling this function.
 func getSourcePath() string {
 	_, filename, _, _ := runtime.Caller(1)
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/accesslog"
+	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
@@ -34,3 +35,11 @@ func MockRouter(logger log.Logger) *routing.Router {
 	)
 	return router
 }
+
+// DBRouter creates a routing.Router for testing APIs against the database. Like in the server, every
+// request runs in its own transaction.
+func DBRouter(logger log.Logger, db *dbcontext.DB) *routing.Router {
+	router := MockRouter(logger)
+	router.Use(db.TransactionHandler())
+	return router
+}
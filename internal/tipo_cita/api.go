@@ -0,0 +1,57 @@
+package tipo_cita
+
+import (
+	"net/http"
+	"strconv"
+	"veterinaria-server/internal/errors"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// RegisterHandlers sets up the routing of the HTTP handlers.
+func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routing.Handler, logger log.Logger) {
+	res := resource{service, logger}
+	r.Use(authHandler)
+	// the following endpoints require a valid JWT
+	r.Get("/tiposCita", res.getTiposCita)
+	r.Get("/tiposCita/<idTipoCita>", res.getTipoCitaPorId)
+	r.Put("/tiposCita", res.actualizarTipoCita)
+}
+
+type resource struct {
+	service Service
+	logger  log.Logger
+}
+
+func (r resource) getTiposCita(c *routing.Context) error {
+	pages, err := r.service.GetTiposCita(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
+}
+
+func (r resource) getTipoCitaPorId(c *routing.Context) error {
+	idTipoCita, _ := strconv.Atoi(c.Param("idTipoCita"))
+	tipoCita, err := r.service.GetTipoCitaPorId(c.Request.Context(), idTipoCita)
+	if err != nil {
+		return err
+	}
+	return c.Write(tipoCita)
+}
+
+func (r resource) actualizarTipoCita(c *routing.Context) error {
+	var input UpdateTipoCitaRequest
+	if err := c.Read(&input); err != nil {
+		r.logger.With(c.Request.Context()).Info(err)
+		return errors.BadRequest("")
+	}
+	tipoCita, err := r.service.ActualizarTipoCita(c.Request.Context(), input)
+	if err != nil {
+		return err
+	}
+	return c.WriteWithStatus(tipoCita, http.StatusCreated)
+}
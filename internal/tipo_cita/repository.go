@@ -0,0 +1,64 @@
+package tipo_cita
+
+import (
+	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/dbcontext"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+)
+
+// Repository encapsulates the logic to access tiposCita from the data source.
+type Repository interface {
+	GetTiposCita(ctx context.Context, query pagination.Query) ([]entity.TipoCita, *pagination.Pages, error)
+	GetTipoCitaPorId(ctx context.Context, idTipoCita int) (entity.TipoCita, error)
+	ActualizarTipoCita(ctx context.Context, tipoCita entity.TipoCita) (entity.TipoCita, error)
+}
+
+// repository persists tiposCita in database
+type repository struct {
+	db     *dbcontext.DB
+	logger log.Logger
+}
+
+// NewRepository creates a new tipoCita repository
+func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
+	return repository{db, logger}
+}
+
+// camposTiposCita are the fields the list of tipos de cita can be filtered, searched and sorted by.
+var camposTiposCita = pagination.Fields{
+	Search:      []string{"descripcion"},
+	Sort:        map[string]string{"id_tipo_cita": "id_tipo_cita", "descripcion": "descripcion", "duracion_minutos": "duracion_minutos"},
+	DefaultSort: []string{"descripcion asc"},
+}
+
+func (r repository) GetTiposCita(ctx context.Context, query pagination.Query) ([]entity.TipoCita, *pagination.Pages, error) {
+	var tiposCita []entity.TipoCita = []entity.TipoCita{}
+
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("tipos_cita"), camposTiposCita, &tiposCita)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tiposCita, pages, nil
+}
+
+func (r repository) GetTipoCitaPorId(ctx context.Context, idTipoCita int) (entity.TipoCita, error) {
+	var tipoCita entity.TipoCita
+	err := r.db.With(ctx).Select().Model(idTipoCita, &tipoCita)
+	return tipoCita, err
+}
+
+func (r repository) ActualizarTipoCita(ctx context.Context, tipoCita entity.TipoCita) (entity.TipoCita, error) {
+	var err error
+	if tipoCita.IdTipoCita != 0 {
+		err = auditoria.Actualizar(ctx, r.db, &tipoCita)
+	} else {
+		err = auditoria.Insertar(ctx, r.db, &tipoCita)
+	}
+	if err != nil {
+		return entity.TipoCita{}, err
+	}
+	return tipoCita, nil
+}
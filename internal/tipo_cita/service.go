@@ -0,0 +1,89 @@
+package tipo_cita
+
+import (
+	"context"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Service encapsulates usecase logic for tiposCita.
+type Service interface {
+	GetTiposCita(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
+	GetTipoCitaPorId(ctx context.Context, idTipoCita int) (TipoCita, error)
+	// ActualizarTipoCita creates a tipo de cita when it has no ID and updates it otherwise.
+	ActualizarTipoCita(ctx context.Context, input UpdateTipoCitaRequest) (TipoCita, error)
+}
+
+// TipoCita represents the data about a tipo de cita.
+type TipoCita struct {
+	entity.TipoCita
+}
+
+type service struct {
+	repo   Repository
+	logger log.Logger
+}
+
+// NewService creates a new tiposCita service.
+func NewService(repo Repository, logger log.Logger) Service {
+	return service{repo, logger}
+}
+
+func (s service) GetTiposCita(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	tiposCita, pages, err := s.repo.GetTiposCita(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	result := []TipoCita{}
+	for _, item := range tiposCita {
+		result = append(result, TipoCita{item})
+	}
+	pages.Items = result
+	return pages, nil
+}
+
+func (s service) GetTipoCitaPorId(ctx context.Context, idTipoCita int) (TipoCita, error) {
+	tipoCita, err := s.repo.GetTipoCitaPorId(ctx, idTipoCita)
+	if err != nil {
+		return TipoCita{}, err
+	}
+	return TipoCita{tipoCita}, nil
+}
+
+// UpdateTipoCitaRequest represents a tipoCita creation or update request.
+type UpdateTipoCitaRequest struct {
+	IdTipoCita      int    `json:"id_tipo_cita"`
+	Descripcion     string `json:"descripcion"`
+	DuracionMinutos int    `json:"duracion_minutos"`
+}
+
+// Validate validates the UpdateTipoCitaRequest fields. The duracion of a cita is up to a day.
+func (m UpdateTipoCitaRequest) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Descripcion, validation.Required, validation.Length(0, 100)),
+		validation.Field(&m.DuracionMinutos, validation.Required, validation.Min(1), validation.Max(24*60)),
+	)
+}
+
+func (s service) ActualizarTipoCita(ctx context.Context, req UpdateTipoCitaRequest) (TipoCita, error) {
+	if err := req.Validate(); err != nil {
+		return TipoCita{}, err
+	}
+	if req.IdTipoCita != 0 {
+		if _, err := s.repo.GetTipoCitaPorId(ctx, req.IdTipoCita); err != nil {
+			return TipoCita{}, err
+		}
+	}
+	tipoCitaG, err := s.repo.ActualizarTipoCita(ctx, entity.TipoCita{
+		IdTipoCita:      req.IdTipoCita,
+		Descripcion:     req.Descripcion,
+		DuracionMinutos: req.DuracionMinutos,
+	})
+	if err != nil {
+		return TipoCita{}, err
+	}
+	return TipoCita{tipoCitaG}, nil
+}
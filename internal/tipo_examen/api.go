@@ -13,6 +13,7 @@ import (
 	"veterinaria-server/internal/resultado_examen_informativo"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
 )
@@ -38,11 +39,12 @@ type resource struct {
 }
 
 func (r resource) getTipoExamenes(c *routing.Context) error {
-	tipoExamenes, err := r.service.GetTipoExamenes(c.Request.Context())
+	pages, err := r.service.GetTipoExamenes(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(tipoExamenes)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) getTipoExamenPorEspecie(c *routing.Context) error {
@@ -0,0 +1,60 @@
+package tipo_examen
+
+import (
+	"net/http"
+	"testing"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/test"
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_guardarResultados(t *testing.T) {
+	logger, _ := log.NewForTest()
+	db := test.DB(t)
+	router := test.DBRouter(logger, db)
+	RegisterHandlers(router.Group(""), NewService(NewRepository(db, logger), logger), auth.MockAuthHandler, logger, db)
+
+	tests := []struct {
+		caso       test.APITestCase
+		resultados int    //Resultados guardados de cada tipo
+		estado     string //Estado del examen luego de guardar
+	}{
+		{
+			caso: test.APITestCase{
+				Name:         "examen completo",
+				Method:       "POST",
+				URL:          "/tipo_examen/con_resultados",
+				Body:         `{"id_examen_mascota":1,"cualitativos":[{"id_examen_mascota":1,"id_detalle_examen_cualitativo":1,"resultado":{"Bool":false,"Valid":true}}],"cuantitativos":[{"id_examen_mascota":1,"id_detalle_examen_cuantitativo":1,"resultado":42.5}],"informativos":[{"id_examen_mascota":1,"id_detalle_examen_informativo":1,"resultado":"Muestra sin alteraciones"}]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusCreated,
+				WantResponse: `*"resultado":"Muestra sin alteraciones"*`,
+			},
+			resultados: 1,
+			estado:     "FINALIZADO",
+		},
+		{
+			caso: test.APITestCase{
+				Name:         "parametro faltante",
+				Method:       "POST",
+				URL:          "/tipo_examen/con_resultados",
+				Body:         `{"id_examen_mascota":1,"cualitativos":[{"id_examen_mascota":1,"id_detalle_examen_cualitativo":1,"resultado":{"Bool":true,"Valid":true}}],"cuantitativos":[{"id_examen_mascota":1,"resultado":42.5}],"informativos":[]}`,
+				Header:       auth.MockAuthHeader(),
+				WantStatus:   http.StatusBadRequest,
+				WantResponse: "",
+			},
+			resultados: 0,
+			estado:     "PENDIENTE",
+		},
+	}
+	for _, tc := range tests {
+		test.Fixtures(t, db, "testdata/fixtures.sql")
+		test.Endpoint(t, router, tc.caso)
+		for _, tabla := range []string{"resultados_detalle_cualitativo", "resultados_detalle_cuantitativo", "resultados_detalle_informativo"} {
+			assert.Equal(t, tc.resultados, test.Count(t, db, tabla, dbx.HashExp{"id_examen_mascota": 1}), "%s: %s", tc.caso.Name, tabla)
+		}
+		assert.Equal(t, 1, test.Count(t, db, "examenes_mascota", dbx.HashExp{"id_examen_mascota": 1, "estado": tc.estado}), tc.caso.Name)
+	}
+}
@@ -3,9 +3,11 @@ package tipo_examen
 import (
 	"context"
 	"strconv"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -15,7 +17,7 @@ type Repository interface {
 	// GetTipoExamenPorId returns the tipoExamen with the specified tipoExamen ID.
 	GetTipoExamenPorId(ctx context.Context, idTipoExamen int) (entity.TipoExamen, error)
 	// GetTipoExamenes returns the list tipoExamenes.
-	GetTipoExamenes(ctx context.Context) ([]entity.TipoExamen, error)
+	GetTipoExamenes(ctx context.Context, query pagination.Query) ([]entity.TipoExamen, *pagination.Pages, error)
 	GetTipoExamenPorEspecie(ctx context.Context, idEspecie int) ([]entity.TipoExamen, error)
 	GetTipoExamenPorEspecieDisponibles(ctx context.Context, idEspecie int, idMascota int) ([]entity.TipoExamen, error)
 	GetDetallesExamenPorTipoExamen(ctx context.Context, idTipoExamen int) (DetallesExamen, error)
@@ -34,18 +36,30 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposTipoExamenes are the fields the list of tipo examenes can be filtered, searched and sorted by.
+var camposTipoExamenes = pagination.Fields{
+	Filters: map[string]string{"id_especie": "id_especie"},
+	Search:  []string{"titulo", "descripcion", "muestra"},
+	Sort: map[string]string{
+		"id_tipo_examen": "id_tipo_examen",
+		"id_especie":     "id_especie",
+		"titulo":         "titulo",
+		"descripcion":    "descripcion",
+		"muestra":        "muestra",
+		"valor":          "valor",
+	},
+	DefaultSort: []string{"id_tipo_examen asc"},
+}
+
 // GetTipoExamenes reads the list tipoExamenes from the database.
-func (r repository) GetTipoExamenes(ctx context.Context) ([]entity.TipoExamen, error) {
-	var tipoExamenes []entity.TipoExamen
+func (r repository) GetTipoExamenes(ctx context.Context, query pagination.Query) ([]entity.TipoExamen, *pagination.Pages, error) {
+	var tipoExamenes []entity.TipoExamen = []entity.TipoExamen{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&tipoExamenes)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("tipos_examenes"), camposTipoExamenes, &tipoExamenes)
 	if err != nil {
-		return tipoExamenes, err
+		return nil, nil, err
 	}
-	return tipoExamenes, err
+	return tipoExamenes, pages, nil
 }
 
 func (r repository) GetTipoExamenPorEspecie(ctx context.Context, idEspecie int) ([]entity.TipoExamen, error) {
@@ -138,7 +152,7 @@ func (r repository) GetDetallesExamenPorTipoExamen(ctx context.Context, idTipoEx
 // Create saves a new TipoExamen record in the database.
 // It returns the ID of the newly inserted tipoExamen record.
 func (r repository) CrearTipoExamen(ctx context.Context, tipoExamen entity.TipoExamen) (entity.TipoExamen, error) {
-	err := r.db.With(ctx).Model(&tipoExamen).Insert()
+	err := auditoria.Insertar(ctx, r.db, &tipoExamen)
 	if err != nil {
 		return entity.TipoExamen{}, err
 	}
@@ -150,9 +164,9 @@ func (r repository) CrearTipoExamen(ctx context.Context, tipoExamen entity.TipoE
 func (r repository) ActualizarTipoExamen(ctx context.Context, tipoExamen entity.TipoExamen) (entity.TipoExamen, error) {
 	var err error
 	if tipoExamen.IdTipoExamen != 0 {
-		err = r.db.With(ctx).Model(&tipoExamen).Update()
+		err = auditoria.Actualizar(ctx, r.db, &tipoExamen)
 	} else {
-		err = r.db.With(ctx).Model(&tipoExamen).Insert()
+		err = auditoria.Insertar(ctx, r.db, &tipoExamen)
 	}
 	if err != nil {
 		return entity.TipoExamen{}, err
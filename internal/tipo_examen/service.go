@@ -10,12 +10,13 @@ import (
 	"veterinaria-server/internal/resultado_examen_cuantitativo"
 	"veterinaria-server/internal/resultado_examen_informativo"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type Service interface {
-	GetTipoExamenes(ctx context.Context) ([]TipoExamen, error)
+	GetTipoExamenes(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetTipoExamenPorEspecie(ctx context.Context, idEspecie int) ([]TipoExamen, error)
 	GetTipoExamenPorEspecieDisponibles(ctx context.Context, idEspecie int, idMascota int) ([]TipoExamen, error)
 	GetDetallesExamenPorTipoExamen(ctx context.Context, idTipoExamen int) (DetallesExamen, error)
@@ -43,8 +44,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 	return service{repo, logger}
 }
 
-func (s service) GetTipoExamenes(ctx context.Context) ([]TipoExamen, error) {
-	tipoExamenes, err := s.repo.GetTipoExamenes(ctx)
+func (s service) GetTipoExamenes(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	tipoExamenes, pages, err := s.repo.GetTipoExamenes(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +53,8 @@ func (s service) GetTipoExamenes(ctx context.Context) ([]TipoExamen, error) {
 	for _, item := range tipoExamenes {
 		result = append(result, TipoExamen{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 func (s service) GetTipoExamenPorEspecie(ctx context.Context, idEspecie int) ([]TipoExamen, error) {
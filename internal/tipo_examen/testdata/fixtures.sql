@@ -0,0 +1,34 @@
+-- Un examen pendiente de una consulta, con un parametro de cada tipo.
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado) VALUES
+    (100, 'Tester', 'Pruebas', 'tester', '-', 1);
+
+INSERT INTO especies (id_especie, descripcion) VALUES
+    (1, 'Canino');
+
+INSERT INTO generos (id_genero, descripcion) VALUES
+    (1, 'Macho');
+
+INSERT INTO clientes (id_cliente, nombres, apellidos, cedula) VALUES
+    (1, 'Ana', 'Pérez', '0900000001');
+
+INSERT INTO mascotas (id_mascota, id_especie, id_cliente, id_genero, nombre) VALUES
+    (1, 1, 1, 1, 'Firulais');
+
+INSERT INTO consulta (id_consulta, id_mascota, id_usuario, fecha, valor, motivo) VALUES
+    (1, 1, 100, '2026-10-01 09:00:00', 20.00, 'Decaimiento');
+
+INSERT INTO tipos_examenes (id_tipo_examen, id_especie, titulo, descripcion, muestra, valor) VALUES
+    (1, 1, 'Hemograma', 'Conteo sanguíneo completo', 'Sangre', 15.00);
+
+INSERT INTO detalles_examen_cualitativo (id_detalle_examen_cualitativo, id_tipo_examen, parametro) VALUES
+    (1, 1, 'Hemoparásitos');
+
+INSERT INTO detalles_examen_cuantitativo (id_detalle_examen_cuantitativo, id_tipo_examen, parametro, rango_referencia_inicial, rango_referencia_final, unidad) VALUES
+    (1, 1, 'Hematocrito', 37.000, 55.000, '%');
+
+INSERT INTO detalles_examen_informativo (id_detalle_examen_informativo, id_tipo_examen, parametro) VALUES
+    (1, 1, 'Observaciones');
+
+INSERT INTO examenes_mascota (id_examen_mascota, id_usuario, id_mascota, id_tipo_examen, fecha_solicitud, estado, id_referencia, tabla) VALUES
+    (1, 100, 1, 1, '2026-10-01 09:30:00', 'PENDIENTE', 1, 'Consulta');
@@ -2,9 +2,11 @@ package unidad
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access unidades from the data source.
@@ -12,7 +14,7 @@ type Repository interface {
 	// GetUnidadPorId returns the unidad with the specified unidad ID.
 	GetUnidadPorId(ctx context.Context, idUnidad int) (entity.Unidad, error)
 	// GetUnidades returns the list unidades.
-	GetUnidades(ctx context.Context) ([]entity.Unidad, error)
+	GetUnidades(ctx context.Context, query pagination.Query) ([]entity.Unidad, *pagination.Pages, error)
 	CrearUnidad(ctx context.Context, unidad entity.Unidad) (entity.Unidad, error)
 	ActualizarUnidad(ctx context.Context, unidad entity.Unidad) (entity.Unidad, error)
 }
@@ -28,23 +30,33 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposUnidades are the fields the list of unidades can be filtered, searched and sorted by.
+var camposUnidades = pagination.Fields{
+	Filters: map[string]string{"id_medida": "id_medida"},
+	Search:  []string{"descripcion"},
+	Sort: map[string]string{
+		"id_unidad":   "id_unidad",
+		"id_medida":   "id_medida",
+		"descripcion": "descripcion",
+	},
+	DefaultSort: []string{"id_unidad asc"},
+}
+
 // Get reads the list unidades from the database.
-func (r repository) GetUnidades(ctx context.Context) ([]entity.Unidad, error) {
-	var unidades []entity.Unidad
+func (r repository) GetUnidades(ctx context.Context, query pagination.Query) ([]entity.Unidad, *pagination.Pages, error) {
+	var unidades []entity.Unidad = []entity.Unidad{}
 
-	err := r.db.With(ctx).
-		Select().
-		All(&unidades)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("unidad"), camposUnidades, &unidades)
 	if err != nil {
-		return unidades, err
+		return nil, nil, err
 	}
-	return unidades, err
+	return unidades, pages, nil
 }
 
 // Create saves a new Unidad record in the database.
 // It returns the ID of the newly inserted unidad record.
 func (r repository) CrearUnidad(ctx context.Context, unidad entity.Unidad) (entity.Unidad, error) {
-	err := r.db.With(ctx).Model(&unidad).Insert()
+	err := auditoria.Insertar(ctx, r.db, &unidad)
 	if err != nil {
 		return entity.Unidad{}, err
 	}
@@ -56,9 +68,9 @@ func (r repository) CrearUnidad(ctx context.Context, unidad entity.Unidad) (enti
 func (r repository) ActualizarUnidad(ctx context.Context, unidad entity.Unidad) (entity.Unidad, error) {
 	var err error
 	if unidad.IdUnidad != 0 {
-		err = r.db.With(ctx).Model(&unidad).Update()
+		err = auditoria.Actualizar(ctx, r.db, &unidad)
 	} else {
-		err = r.db.With(ctx).Model(&unidad).Insert()
+		err = auditoria.Insertar(ctx, r.db, &unidad)
 	}
 	if err != nil {
 		return entity.Unidad{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for unidades.
 type Service interface {
-	GetUnidades(ctx context.Context) ([]Unidad, error)
+	GetUnidades(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetUnidadPorId(ctx context.Context, idUnidad int) (Unidad, error)
 	CrearUnidad(ctx context.Context, input CreateUnidadRequest) (Unidad, error)
 	ActualizarUnidad(ctx context.Context, input UpdateUnidadRequest) (Unidad, error)
@@ -32,8 +33,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list unidades.
-func (s service) GetUnidades(ctx context.Context) ([]Unidad, error) {
-	unidades, err := s.repo.GetUnidades(ctx)
+func (s service) GetUnidades(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	unidades, pages, err := s.repo.GetUnidades(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +42,8 @@ func (s service) GetUnidades(ctx context.Context) ([]Unidad, error) {
 	for _, item := range unidades {
 		result = append(result, Unidad{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateUnidadRequest represents an unidad creation request.
@@ -3,9 +3,11 @@ package usuario_rol
 import (
 	"context"
 	"database/sql"
+	"veterinaria-server/internal/auditoria"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	dbx "github.com/go-ozzo/ozzo-dbx"
 )
@@ -16,7 +18,7 @@ type Repository interface {
 	GetUsuarioRolPorId(ctx context.Context, idUsuarioRol int) (entity.UsuarioRol, error)
 	GetUsuarioRolPorUsuario(ctx context.Context, idUsuario int) (entity.UsuarioRol, error)
 	// GetUsuarioRoles returns the list usuarioRoles.
-	GetUsuarioRoles(ctx context.Context) ([]entity.UsuarioRol, error)
+	GetUsuarioRoles(ctx context.Context, query pagination.Query) ([]entity.UsuarioRol, *pagination.Pages, error)
 	CrearUsuarioRol(ctx context.Context, usuarioRol entity.UsuarioRol) (entity.UsuarioRol, error)
 	ActualizarUsuarioRol(ctx context.Context, usuarioRol entity.UsuarioRol) (entity.UsuarioRol, error)
 }
@@ -32,24 +34,32 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposUsuarioRoles are the fields the list of usuario roles can be filtered, searched and sorted by.
+var camposUsuarioRoles = pagination.Fields{
+	Filters: map[string]string{"id_rol": "id_rol", "id_usuario": "id_usuario"},
+	Sort: map[string]string{
+		"id_usuario_rol": "id_usuario_rol",
+		"id_rol":         "id_rol",
+		"id_usuario":     "id_usuario",
+	},
+	DefaultSort: []string{"id_usuario_rol asc"},
+}
+
 // Get reads the list usuarioRoles from the database.
-func (r repository) GetUsuarioRoles(ctx context.Context) ([]entity.UsuarioRol, error) {
-	var usuarioRoles []entity.UsuarioRol
+func (r repository) GetUsuarioRoles(ctx context.Context, query pagination.Query) ([]entity.UsuarioRol, *pagination.Pages, error) {
+	var usuarioRoles []entity.UsuarioRol = []entity.UsuarioRol{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&usuarioRoles)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("usuario_rol"), camposUsuarioRoles, &usuarioRoles)
 	if err != nil {
-		return usuarioRoles, err
+		return nil, nil, err
 	}
-	return usuarioRoles, err
+	return usuarioRoles, pages, nil
 }
 
 // Create saves a new UsuarioRol record in the database.
 // It returns the ID of the newly inserted usuarioRol record.
 func (r repository) CrearUsuarioRol(ctx context.Context, usuarioRol entity.UsuarioRol) (entity.UsuarioRol, error) {
-	err := r.db.With(ctx).Model(&usuarioRol).Insert()
+	err := auditoria.Insertar(ctx, r.db, &usuarioRol)
 	if err != nil {
 		return entity.UsuarioRol{}, err
 	}
@@ -61,9 +71,9 @@ func (r repository) CrearUsuarioRol(ctx context.Context, usuarioRol entity.Usuar
 func (r repository) ActualizarUsuarioRol(ctx context.Context, usuarioRol entity.UsuarioRol) (entity.UsuarioRol, error) {
 	var err error
 	if usuarioRol.IdUsuarioRol != 0 {
-		err = r.db.With(ctx).Model(&usuarioRol).Update()
+		err = auditoria.Actualizar(ctx, r.db, &usuarioRol)
 	} else {
-		err = r.db.With(ctx).Model(&usuarioRol).Insert()
+		err = auditoria.Insertar(ctx, r.db, &usuarioRol)
 	}
 	if err != nil {
 		return entity.UsuarioRol{}, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for usuarioRoles.
 type Service interface {
-	GetUsuarioRoles(ctx context.Context) ([]UsuarioRol, error)
+	GetUsuarioRoles(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetUsuarioRolPorId(ctx context.Context, idUsuarioRol int) (UsuarioRol, error)
 	GetUsuarioRolPorUsuario(ctx context.Context, idUsuario int) (UsuarioRol, error)
 	CrearUsuarioRol(ctx context.Context, input CreateUsuarioRolRequest) (UsuarioRol, error)
@@ -33,8 +34,8 @@ func NewService(repo Repository, logger log.Logger) Service {
 }
 
 // Get returns the list usuarioRoles.
-func (s service) GetUsuarioRoles(ctx context.Context) ([]UsuarioRol, error) {
-	usuarioRoles, err := s.repo.GetUsuarioRoles(ctx)
+func (s service) GetUsuarioRoles(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	usuarioRoles, pages, err := s.repo.GetUsuarioRoles(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (s service) GetUsuarioRoles(ctx context.Context) ([]UsuarioRol, error) {
 	for _, item := range usuarioRoles {
 		result = append(result, UsuarioRol{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateUsuarioRolRequest represents an usuarioRol creation request.
@@ -5,9 +5,9 @@ import (
 	"strconv"
 	"veterinaria-server/internal/errors"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	routing "github.com/go-ozzo/ozzo-routing/v2"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // RegisterHandlers sets up the routing of the HTTP handlers.
@@ -19,6 +19,7 @@ func RegisterHandlers(r *routing.RouteGroup, service Service, authHandler routin
 	r.Get("/users/<idUser>", res.getUserPorId)
 	r.Post("/users", res.crearUser)
 	r.Put("/users", res.actualizarUser)
+	r.Post("/users/<idUser>/resetClave", res.restablecerClave)
 }
 
 type resource struct {
@@ -27,11 +28,12 @@ type resource struct {
 }
 
 func (r resource) getUsers(c *routing.Context) error {
-	users, err := r.service.GetUsers(c.Request.Context())
+	pages, err := r.service.GetUsers(c.Request.Context(), pagination.NewQueryFromRequest(c.Request))
 	if err != nil {
 		return err
 	}
-	return c.Write(users)
+	c.Response.Header().Set("Link", pages.BuildLinkHeader(pagination.LinkURL(c.Request), pagination.DefaultPageSize))
+	return c.Write(pages)
 }
 
 func (r resource) crearUser(c *routing.Context) error {
@@ -54,12 +56,6 @@ func (r resource) actualizarUser(c *routing.Context) error {
 		return errors.BadRequest("")
 	}
 
-	if input.CambioClave == 1 {
-		// para encriptar la pass
-		hash, _ := bcrypt.GenerateFromPassword([]byte(input.Clave), bcrypt.MinCost)
-		input.Clave = string(hash)
-	}
-
 	user, err := r.service.ActualizarUser(c.Request.Context(), input)
 	if err != nil {
 		return err
@@ -75,3 +71,12 @@ func (r resource) getUserPorId(c *routing.Context) error {
 	}
 	return c.Write(user)
 }
+
+func (r resource) restablecerClave(c *routing.Context) error {
+	idUser, _ := strconv.Atoi(c.Param("idUser"))
+	clave, err := r.service.RestablecerClave(c.Request.Context(), idUser)
+	if err != nil {
+		return err
+	}
+	return c.Write(clave)
+}
@@ -2,9 +2,12 @@ package usuarios
 
 import (
 	"context"
+	"veterinaria-server/internal/auditoria"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/dbcontext"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 )
 
 // Repository encapsulates the logic to access users from the data source.
@@ -12,9 +15,13 @@ type Repository interface {
 	// GetUserPorId returns the user with the specified user ID.
 	GetUserPorId(ctx context.Context, idUser int) (entity.User, error)
 	// GetUsers returns the list users.
-	GetUsers(ctx context.Context) ([]entity.User, error)
+	GetUsers(ctx context.Context, query pagination.Query) ([]entity.User, *pagination.Pages, error)
 	CrearUser(ctx context.Context, user entity.User) (entity.User, error)
 	ActualizarUser(ctx context.Context, user entity.User) (entity.User, error)
+	// ActualizarClave saves the clave of the user and its lockout state.
+	ActualizarClave(ctx context.Context, user entity.User) error
+	// RevocarSesiones revokes every sesion of the user, so its tokens stop working.
+	RevocarSesiones(ctx context.Context, idUser int, motivo string) error
 }
 
 // repository persists users in database
@@ -28,24 +35,39 @@ func NewRepository(db *dbcontext.DB, logger log.Logger) Repository {
 	return repository{db, logger}
 }
 
+// camposUsers are the fields the list of users can be filtered, searched and sorted by.
+var camposUsers = pagination.Fields{
+	Filters: map[string]string{"estado": "estado", "cambiar_clave": "cambiar_clave"},
+	Ranges:  map[string]string{"bloqueado_hasta": "bloqueado_hasta"},
+	Search:  []string{"nombre", "apellido", "nombre_usuario"},
+	Sort: map[string]string{
+		"id_usuario":        "id_usuario",
+		"nombre":            "nombre",
+		"apellido":          "apellido",
+		"nombre_usuario":    "nombre_usuario",
+		"estado":            "estado",
+		"cambiar_clave":     "cambiar_clave",
+		"intentos_fallidos": "intentos_fallidos",
+		"bloqueado_hasta":   "bloqueado_hasta",
+	},
+	DefaultSort: []string{"id_usuario asc"},
+}
+
 // Get reads the list users from the database.
-func (r repository) GetUsers(ctx context.Context) ([]entity.User, error) {
-	var users []entity.User
+func (r repository) GetUsers(ctx context.Context, query pagination.Query) ([]entity.User, *pagination.Pages, error) {
+	var users []entity.User = []entity.User{}
 
-	err := r.db.With(ctx).
-		Select().
-		From().
-		All(&users)
+	pages, err := query.List(ctx, r.db.With(ctx), r.db.With(ctx).Select().From("usuarios"), camposUsers, &users)
 	if err != nil {
-		return users, err
+		return nil, nil, err
 	}
-	return users, err
+	return users, pages, nil
 }
 
 // Create saves a new User record in the database.
 // It returns the ID of the newly inserted user record.
 func (r repository) CrearUser(ctx context.Context, user entity.User) (entity.User, error) {
-	err := r.db.With(ctx).Model(&user).Insert()
+	err := auditoria.Insertar(ctx, r.db, &user)
 	if err != nil {
 		return entity.User{}, err
 	}
@@ -57,9 +79,9 @@ func (r repository) CrearUser(ctx context.Context, user entity.User) (entity.Use
 func (r repository) ActualizarUser(ctx context.Context, user entity.User) (entity.User, error) {
 	var err error
 	if user.IdUsuario != 0 {
-		err = r.db.With(ctx).Model(&user).Update()
+		err = auditoria.Actualizar(ctx, r.db, &user)
 	} else {
-		err = r.db.With(ctx).Model(&user).Insert()
+		err = auditoria.Insertar(ctx, r.db, &user)
 	}
 	if err != nil {
 		return entity.User{}, err
@@ -67,9 +89,17 @@ func (r repository) ActualizarUser(ctx context.Context, user entity.User) (entit
 	return user, nil
 }
 
+func (r repository) ActualizarClave(ctx context.Context, user entity.User) error {
+	return auditoria.Actualizar(ctx, r.db, &user, "Clave", "CambiarClave", "IntentosFallidos", "BloqueadoHasta")
+}
+
 // Get reads the user with the specified ID from the database.
 func (r repository) GetUserPorId(ctx context.Context, idUser int) (entity.User, error) {
 	var user entity.User
 	err := r.db.With(ctx).Select().Model(idUser, &user)
 	return user, err
 }
+
+func (r repository) RevocarSesiones(ctx context.Context, idUser int, motivo string) error {
+	return auth.RevocarSesionesUsuario(ctx, r.db, idUser, motivo)
+}
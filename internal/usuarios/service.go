@@ -3,18 +3,23 @@ package usuarios
 import (
 	"context"
 	"database/sql"
+	"veterinaria-server/internal/auth"
 	"veterinaria-server/internal/entity"
 	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 // Service encapsulates usecase logic for users.
 type Service interface {
-	GetUsers(ctx context.Context) ([]User, error)
+	GetUsers(ctx context.Context, query pagination.Query) (*pagination.Pages, error)
 	GetUserPorId(ctx context.Context, idUser int) (User, error)
 	CrearUser(ctx context.Context, input CreateUserRequest) (User, error)
 	ActualizarUser(ctx context.Context, input UpdateUserRequest) (User, error)
+	// RestablecerClave replaces the clave of the user with a temporary one that must be changed at
+	// the next login, unlocks the user and revokes its sesiones.
+	RestablecerClave(ctx context.Context, idUser int) (ClaveTemporal, error)
 }
 
 // Users represents the data about an users.
@@ -22,19 +27,26 @@ type User struct {
 	entity.User
 }
 
+// ClaveTemporal is the clave generated when an administrator resets the clave of a user.
+type ClaveTemporal struct {
+	IdUsuario     int    `json:"id_usuario"`
+	ClaveTemporal string `json:"clave_temporal"`
+}
+
 type service struct {
-	repo   Repository
-	logger log.Logger
+	repo     Repository
+	logger   log.Logger
+	politica auth.PoliticaClave
 }
 
-// NewService creates a new users service.
-func NewService(repo Repository, logger log.Logger) Service {
-	return service{repo, logger}
+// NewService creates a new users service. New claves must follow the politica.
+func NewService(repo Repository, logger log.Logger, politica auth.PoliticaClave) Service {
+	return service{repo, logger, politica}
 }
 
 // Get returns the list users.
-func (s service) GetUsers(ctx context.Context) ([]User, error) {
-	users, err := s.repo.GetUsers(ctx)
+func (s service) GetUsers(ctx context.Context, query pagination.Query) (*pagination.Pages, error) {
+	users, pages, err := s.repo.GetUsers(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +54,8 @@ func (s service) GetUsers(ctx context.Context) ([]User, error) {
 	for _, item := range users {
 		result = append(result, User{item})
 	}
-	return result, nil
+	pages.Items = result
+	return pages, nil
 }
 
 // CreateUserRequest represents an user creation request.
@@ -89,11 +102,18 @@ func (s service) CrearUser(ctx context.Context, req CreateUserRequest) (User, er
 	if err := req.Validate(); err != nil {
 		return User{}, err
 	}
+	if err := s.politica.Validar(req.Clave); err != nil {
+		return User{}, err
+	}
+	hash, err := auth.HashClave(req.Clave)
+	if err != nil {
+		return User{}, err
+	}
 	userG, err := s.repo.CrearUser(ctx, entity.User{
 		Nombre:        req.Nombre,
 		Apellido:      req.Apellido,
 		NombreUsuario: req.NombreUsuario,
-		Clave:         req.Clave,
+		Clave:         hash,
 		Estado:        req.Estado,
 	})
 	if err != nil {
@@ -107,20 +127,78 @@ func (s service) ActualizarUser(ctx context.Context, req UpdateUserRequest) (Use
 	if err := req.ValidateUpdate(); err != nil {
 		return User{}, err
 	}
-	userG, err := s.repo.ActualizarUser(ctx, entity.User{
-		IdUsuario:     req.IdUsuario,
-		Nombre:        req.Nombre,
-		Apellido:      req.Apellido,
-		NombreUsuario: req.NombreUsuario,
-		Clave:         req.Clave,
-		Estado:        req.Estado,
-	})
+	user := entity.User{}
+	//Un usuario nuevo siempre fija su clave, se valida y se cifra como en un cambio de clave
+	if req.CambioClave == 1 || req.IdUsuario == 0 {
+		if err := s.politica.Validar(req.Clave); err != nil {
+			return User{}, err
+		}
+		hash, err := auth.HashClave(req.Clave)
+		if err != nil {
+			return User{}, err
+		}
+		req.Clave = hash
+	}
+	//Al desactivar al usuario o cambiar su clave se cierran todas sus sesiones
+	if req.IdUsuario != 0 {
+		userBD, err := s.repo.GetUserPorId(ctx, req.IdUsuario)
+		if err != nil {
+			return User{}, err
+		}
+		user = userBD
+		desactivado := userBD.Estado.Bool && !(req.Estado.Valid && req.Estado.Bool)
+		if desactivado || req.CambioClave == 1 {
+			motivo := "Cambio de clave"
+			if desactivado {
+				motivo = "Usuario desactivado"
+			}
+			if err := s.repo.RevocarSesiones(ctx, req.IdUsuario, motivo); err != nil {
+				return User{}, err
+			}
+		}
+	}
+	user.IdUsuario = req.IdUsuario
+	user.Nombre = req.Nombre
+	user.Apellido = req.Apellido
+	user.NombreUsuario = req.NombreUsuario
+	//Sin cambio de clave se conserva la guardada, la enviada no se valida ni se cifra
+	if req.CambioClave == 1 || req.IdUsuario == 0 {
+		user.Clave = req.Clave
+	}
+	user.Estado = req.Estado
+	userG, err := s.repo.ActualizarUser(ctx, user)
 	if err != nil {
 		return User{}, err
 	}
 	return User{userG}, nil
 }
 
+func (s service) RestablecerClave(ctx context.Context, idUser int) (ClaveTemporal, error) {
+	user, err := s.repo.GetUserPorId(ctx, idUser)
+	if err != nil {
+		return ClaveTemporal{}, err
+	}
+	clave, err := s.politica.ClaveTemporal()
+	if err != nil {
+		return ClaveTemporal{}, err
+	}
+	hash, err := auth.HashClave(clave)
+	if err != nil {
+		return ClaveTemporal{}, err
+	}
+	user.Clave = hash
+	user.CambiarClave = true
+	user.IntentosFallidos = 0
+	user.BloqueadoHasta = nil
+	if err := s.repo.ActualizarClave(ctx, user); err != nil {
+		return ClaveTemporal{}, err
+	}
+	if err := s.repo.RevocarSesiones(ctx, idUser, "Clave restablecida"); err != nil {
+		return ClaveTemporal{}, err
+	}
+	return ClaveTemporal{IdUsuario: idUser, ClaveTemporal: clave}, nil
+}
+
 // GetUserPorId returns the user with the specified the user ID.
 func (s service) GetUserPorId(ctx context.Context, idUser int) (User, error) {
 	user, err := s.repo.GetUserPorId(ctx, idUser)
@@ -0,0 +1,134 @@
+package usuarios
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+	"veterinaria-server/internal/auth"
+	"veterinaria-server/internal/entity"
+	"veterinaria-server/pkg/log"
+	"veterinaria-server/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_service_ActualizarUser(t *testing.T) {
+	logger, _ := log.NewForTest()
+	activo := sql.NullBool{Bool: true, Valid: true}
+	repo := &mockRepository{users: []entity.User{{IdUsuario: 1, Nombre: "Ana", Apellido: "Loor", NombreUsuario: "aloor", Clave: "hash", Estado: activo}}}
+	s := NewService(repo, logger, auth.PoliticaClave{LongitudMinima: 8, Numero: true})
+	req := UpdateUserRequest{IdUsuario: 1, Nombre: "Ana", Apellido: "Loor", NombreUsuario: "aloor", Clave: "hash", Estado: activo}
+
+	_, err := s.ActualizarUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Empty(t, repo.revocados)
+
+	// clave fuera de la politica
+	req.CambioClave = 1
+	req.Clave = "corta"
+	_, err = s.ActualizarUser(context.Background(), req)
+	assert.NotNil(t, err)
+	assert.Empty(t, repo.revocados)
+
+	req.Clave = "nuevaclave1"
+	user, err := s.ActualizarUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cambio de clave"}, repo.revocados)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user.Clave), []byte("nuevaclave1")))
+
+	// sin cambio de clave se conserva la guardada
+	req.CambioClave = 0
+	req.Clave = "otraclave1"
+	req.Estado = sql.NullBool{Bool: false, Valid: true}
+	_, err = s.ActualizarUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cambio de clave", "Usuario desactivado"}, repo.revocados)
+	assert.Equal(t, user.Clave, repo.users[0].Clave)
+}
+
+func Test_service_ActualizarUser_nuevo(t *testing.T) {
+	logger, _ := log.NewForTest()
+	repo := &mockRepository{}
+	s := NewService(repo, logger, auth.PoliticaClave{LongitudMinima: 8, Numero: true})
+	req := UpdateUserRequest{Nombre: "Luis", Apellido: "Mera", NombreUsuario: "lmera", Clave: "corta", Estado: sql.NullBool{Bool: true, Valid: true}}
+
+	// clave fuera de la politica
+	_, err := s.ActualizarUser(context.Background(), req)
+	assert.NotNil(t, err)
+	assert.Empty(t, repo.users)
+
+	req.Clave = "clavenueva1"
+	user, err := s.ActualizarUser(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Len(t, repo.users, 1)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user.Clave), []byte("clavenueva1")))
+}
+
+func Test_service_RestablecerClave(t *testing.T) {
+	logger, _ := log.NewForTest()
+	bloqueado := time.Now().Add(time.Hour)
+	repo := &mockRepository{users: []entity.User{{IdUsuario: 1, NombreUsuario: "aloor", Clave: "hash", IntentosFallidos: 2, BloqueadoHasta: &bloqueado}}}
+	s := NewService(repo, logger, auth.PoliticaClave{LongitudMinima: 8, Mayuscula: true, Numero: true, Simbolo: true})
+
+	_, err := s.RestablecerClave(context.Background(), 2)
+	assert.NotNil(t, err)
+
+	clave, err := s.RestablecerClave(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, clave.IdUsuario)
+	user := repo.users[0]
+	assert.True(t, user.CambiarClave)
+	assert.Nil(t, user.BloqueadoHasta)
+	assert.Equal(t, 0, user.IntentosFallidos)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user.Clave), []byte(clave.ClaveTemporal)))
+	assert.Equal(t, []string{"Clave restablecida"}, repo.revocados)
+}
+
+type mockRepository struct {
+	users     []entity.User
+	revocados []string
+}
+
+func (m *mockRepository) GetUserPorId(ctx context.Context, idUser int) (entity.User, error) {
+	for _, user := range m.users {
+		if user.IdUsuario == idUser {
+			return user, nil
+		}
+	}
+	return entity.User{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) GetUsers(ctx context.Context, query pagination.Query) ([]entity.User, *pagination.Pages, error) {
+	items := m.users
+	pages := pagination.New(query.Page, query.PerPage, len(items))
+	pages.Items = items
+	return items, pages, nil
+}
+
+func (m *mockRepository) CrearUser(ctx context.Context, user entity.User) (entity.User, error) {
+	user.IdUsuario = len(m.users) + 1
+	m.users = append(m.users, user)
+	return user, nil
+}
+
+func (m *mockRepository) ActualizarUser(ctx context.Context, user entity.User) (entity.User, error) {
+	if user.IdUsuario == 0 {
+		user.IdUsuario = len(m.users) + 1
+		m.users = append(m.users, user)
+		return user, nil
+	}
+	m.users[user.IdUsuario-1] = user
+	return user, nil
+}
+
+func (m *mockRepository) ActualizarClave(ctx context.Context, user entity.User) error {
+	m.users[user.IdUsuario-1] = user
+	return nil
+}
+
+func (m *mockRepository) RevocarSesiones(ctx context.Context, idUser int, motivo string) error {
+	m.revocados = append(m.revocados, motivo)
+	return nil
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS auditoria;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sesiones_usuario;
+DROP TABLE IF EXISTS rol_permiso;
+DROP TABLE IF EXISTS permisos;
+DROP TABLE IF EXISTS rol_acceso;
+DROP TABLE IF EXISTS accesos;
+DROP TABLE IF EXISTS usuario_rol;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS usuarios;
@@ -0,0 +1,106 @@
+CREATE TABLE usuarios (
+    id_usuario INT NOT NULL AUTO_INCREMENT,
+    nombre VARCHAR(100) NOT NULL,
+    apellido VARCHAR(100) NOT NULL,
+    nombre_usuario VARCHAR(50) NOT NULL,
+    clave VARCHAR(255) NOT NULL,
+    estado TINYINT(1) NOT NULL DEFAULT 1,
+    cambiar_clave TINYINT(1) NOT NULL DEFAULT 0,
+    intentos_fallidos INT NOT NULL DEFAULT 0,
+    bloqueado_hasta DATETIME NULL,
+    PRIMARY KEY (id_usuario),
+    UNIQUE KEY uq_usuarios_nombre_usuario (nombre_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE roles (
+    id_rol INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(100) NOT NULL,
+    estado TINYINT(1) NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_rol)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE usuario_rol (
+    id_usuario_rol INT NOT NULL AUTO_INCREMENT,
+    id_rol INT NOT NULL,
+    id_usuario INT NOT NULL,
+    PRIMARY KEY (id_usuario_rol),
+    UNIQUE KEY uq_usuario_rol (id_usuario, id_rol),
+    CONSTRAINT fk_usuario_rol_rol FOREIGN KEY (id_rol) REFERENCES roles (id_rol),
+    CONSTRAINT fk_usuario_rol_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE accesos (
+    id_acceso INT NOT NULL AUTO_INCREMENT,
+    id_acceso_padre INT NULL,
+    descripcion VARCHAR(100) NOT NULL,
+    ruta VARCHAR(150) NOT NULL,
+    icono VARCHAR(50) NULL,
+    principal TINYINT(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_acceso),
+    CONSTRAINT fk_accesos_padre FOREIGN KEY (id_acceso_padre) REFERENCES accesos (id_acceso)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE rol_acceso (
+    id_rol INT NOT NULL,
+    id_acceso INT NOT NULL,
+    PRIMARY KEY (id_rol, id_acceso),
+    CONSTRAINT fk_rol_acceso_rol FOREIGN KEY (id_rol) REFERENCES roles (id_rol),
+    CONSTRAINT fk_rol_acceso_acceso FOREIGN KEY (id_acceso) REFERENCES accesos (id_acceso)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE permisos (
+    id_permiso INT NOT NULL AUTO_INCREMENT,
+    codigo VARCHAR(50) NOT NULL,
+    descripcion VARCHAR(150) NOT NULL,
+    PRIMARY KEY (id_permiso),
+    UNIQUE KEY uq_permisos_codigo (codigo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE rol_permiso (
+    id_rol_permiso INT NOT NULL AUTO_INCREMENT,
+    id_rol INT NOT NULL,
+    id_permiso INT NOT NULL,
+    PRIMARY KEY (id_rol_permiso),
+    UNIQUE KEY uq_rol_permiso (id_rol, id_permiso),
+    CONSTRAINT fk_rol_permiso_rol FOREIGN KEY (id_rol) REFERENCES roles (id_rol),
+    CONSTRAINT fk_rol_permiso_permiso FOREIGN KEY (id_permiso) REFERENCES permisos (id_permiso)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE sesiones_usuario (
+    id_sesion_usuario INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    fecha_creacion DATETIME NOT NULL,
+    fecha_revocacion DATETIME NULL,
+    motivo_revocacion VARCHAR(100) NULL,
+    PRIMARY KEY (id_sesion_usuario),
+    CONSTRAINT fk_sesiones_usuario_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE refresh_tokens (
+    id_refresh_token INT NOT NULL AUTO_INCREMENT,
+    id_sesion_usuario INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    fecha_creacion DATETIME NOT NULL,
+    fecha_expiracion DATETIME NOT NULL,
+    usado TINYINT(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_refresh_token),
+    UNIQUE KEY uq_refresh_tokens_token_hash (token_hash),
+    CONSTRAINT fk_refresh_tokens_sesion FOREIGN KEY (id_sesion_usuario) REFERENCES sesiones_usuario (id_sesion_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- id_usuario no tiene llave foranea: la auditoria se conserva aunque el usuario cambie
+CREATE TABLE auditoria (
+    id_auditoria INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NULL,
+    nombre_usuario VARCHAR(50) NULL,
+    fecha DATETIME NOT NULL,
+    entidad VARCHAR(64) NOT NULL,
+    id_registro VARCHAR(64) NOT NULL,
+    accion VARCHAR(20) NOT NULL,
+    antes MEDIUMTEXT NULL,
+    despues MEDIUMTEXT NULL,
+    PRIMARY KEY (id_auditoria),
+    KEY ix_auditoria_registro (entidad, id_registro),
+    KEY ix_auditoria_fecha (fecha),
+    KEY ix_auditoria_usuario (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS citas_medicas;
+DROP TABLE IF EXISTS documento_mascota;
+DROP TABLE IF EXISTS mascotas;
+DROP TABLE IF EXISTS generos;
+DROP TABLE IF EXISTS especies;
+DROP TABLE IF EXISTS clientes;
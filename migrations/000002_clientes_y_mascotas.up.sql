@@ -0,0 +1,65 @@
+CREATE TABLE clientes (
+    id_cliente INT NOT NULL AUTO_INCREMENT,
+    nombres VARCHAR(100) NOT NULL,
+    apellidos VARCHAR(100) NOT NULL,
+    cedula VARCHAR(13) NOT NULL,
+    correo VARCHAR(150) NULL,
+    telefono VARCHAR(20) NULL,
+    direccion VARCHAR(255) NULL,
+    nacionalidad VARCHAR(50) NULL,
+    PRIMARY KEY (id_cliente),
+    UNIQUE KEY uq_clientes_cedula (cedula),
+    KEY ix_clientes_apellidos (apellidos, nombres)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE especies (
+    id_especie INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id_especie)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE generos (
+    id_genero INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id_genero)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE mascotas (
+    id_mascota INT NOT NULL AUTO_INCREMENT,
+    id_especie INT NOT NULL,
+    id_cliente INT NOT NULL,
+    id_genero INT NOT NULL,
+    nombre VARCHAR(100) NULL,
+    raza VARCHAR(100) NULL,
+    color VARCHAR(50) NULL,
+    PRIMARY KEY (id_mascota),
+    CONSTRAINT fk_mascotas_especie FOREIGN KEY (id_especie) REFERENCES especies (id_especie),
+    CONSTRAINT fk_mascotas_cliente FOREIGN KEY (id_cliente) REFERENCES clientes (id_cliente),
+    CONSTRAINT fk_mascotas_genero FOREIGN KEY (id_genero) REFERENCES generos (id_genero)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE documento_mascota (
+    id_documento_mascota INT NOT NULL AUTO_INCREMENT,
+    id_mascota INT NOT NULL,
+    id_usuario INT NOT NULL,
+    nombre VARCHAR(255) NOT NULL,
+    extension VARCHAR(20) NOT NULL,
+    ruta VARCHAR(255) NOT NULL,
+    descripcion VARCHAR(255) NOT NULL,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_documento_mascota),
+    CONSTRAINT fk_documento_mascota_mascota FOREIGN KEY (id_mascota) REFERENCES mascotas (id_mascota),
+    CONSTRAINT fk_documento_mascota_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE citas_medicas (
+    id_cita_medica INT NOT NULL AUTO_INCREMENT,
+    id_mascota INT NOT NULL,
+    motivo VARCHAR(255) NOT NULL,
+    fecha DATETIME NOT NULL,
+    estado_notificacion VARCHAR(2) NOT NULL DEFAULT 'NO',
+    PRIMARY KEY (id_cita_medica),
+    KEY ix_citas_medicas_fecha (fecha),
+    KEY ix_citas_medicas_notificacion (estado_notificacion, fecha),
+    CONSTRAINT fk_citas_medicas_mascota FOREIGN KEY (id_mascota) REFERENCES mascotas (id_mascota)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS movimientos_inventario;
+DROP TABLE IF EXISTS detalles_compra;
+DROP TABLE IF EXISTS compras;
+DROP TABLE IF EXISTS stock_individual;
+DROP TABLE IF EXISTS lote;
+DROP TABLE IF EXISTS proveedor_producto;
+DROP TABLE IF EXISTS proveedor;
+DROP TABLE IF EXISTS producto;
+DROP TABLE IF EXISTS unidad;
+DROP TABLE IF EXISTS medida;
@@ -0,0 +1,118 @@
+CREATE TABLE medida (
+    id_medida INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id_medida)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE unidad (
+    id_unidad INT NOT NULL AUTO_INCREMENT,
+    id_medida INT NOT NULL,
+    descripcion VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id_unidad),
+    CONSTRAINT fk_unidad_medida FOREIGN KEY (id_medida) REFERENCES medida (id_medida)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE producto (
+    id_producto INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(255) NOT NULL,
+    precio_venta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    iva TINYINT(1) NOT NULL DEFAULT 0,
+    uso_interno TINYINT(1) NOT NULL DEFAULT 0,
+    venta_publico TINYINT(1) NOT NULL DEFAULT 0,
+    por_medida TINYINT(1) NOT NULL DEFAULT 0,
+    stock_minimo INT NOT NULL DEFAULT 0,
+    id_unidad INT NULL,
+    contenido DECIMAL(10,3) NULL,
+    PRIMARY KEY (id_producto),
+    KEY ix_producto_descripcion (descripcion),
+    CONSTRAINT fk_producto_unidad FOREIGN KEY (id_unidad) REFERENCES unidad (id_unidad)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE proveedor (
+    id_proveedor INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(150) NOT NULL,
+    celular VARCHAR(20) NULL,
+    correo VARCHAR(150) NULL,
+    ruc VARCHAR(13) NULL,
+    direccion VARCHAR(255) NULL,
+    PRIMARY KEY (id_proveedor)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE proveedor_producto (
+    id_proveedor_producto INT NOT NULL AUTO_INCREMENT,
+    id_proveedor INT NOT NULL,
+    id_producto INT NOT NULL,
+    precio_compra DECIMAL(10,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_proveedor_producto),
+    UNIQUE KEY uq_proveedor_producto (id_proveedor, id_producto),
+    CONSTRAINT fk_proveedor_producto_proveedor FOREIGN KEY (id_proveedor) REFERENCES proveedor (id_proveedor),
+    CONSTRAINT fk_proveedor_producto_producto FOREIGN KEY (id_producto) REFERENCES producto (id_producto)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE lote (
+    id_lote INT NOT NULL AUTO_INCREMENT,
+    id_proveedor_producto INT NOT NULL,
+    fecha_caducidad DATE NULL,
+    stock INT NOT NULL DEFAULT 0,
+    descripcion VARCHAR(150) NOT NULL,
+    codigo_barra VARCHAR(50) NULL,
+    PRIMARY KEY (id_lote),
+    KEY ix_lote_codigo_barra (codigo_barra),
+    KEY ix_lote_fecha_caducidad (fecha_caducidad),
+    CONSTRAINT fk_lote_proveedor_producto FOREIGN KEY (id_proveedor_producto) REFERENCES proveedor_producto (id_proveedor_producto)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE stock_individual (
+    id_stock_individual INT NOT NULL AUTO_INCREMENT,
+    id_lote INT NOT NULL,
+    descripcion VARCHAR(150) NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL DEFAULT 0,
+    cantidad_inicial DECIMAL(10,3) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_stock_individual),
+    CONSTRAINT fk_stock_individual_lote FOREIGN KEY (id_lote) REFERENCES lote (id_lote)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE compras (
+    id_compra INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    id_proveedor INT NOT NULL,
+    fecha DATETIME NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    descripcion VARCHAR(255) NULL,
+    PRIMARY KEY (id_compra),
+    KEY ix_compras_fecha (fecha),
+    CONSTRAINT fk_compras_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_compras_proveedor FOREIGN KEY (id_proveedor) REFERENCES proveedor (id_proveedor)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_compra (
+    id_detalle_compra INT NOT NULL AUTO_INCREMENT,
+    id_compra INT NOT NULL,
+    id_lote INT NOT NULL,
+    cantidad INT NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_detalle_compra),
+    CONSTRAINT fk_detalles_compra_compra FOREIGN KEY (id_compra) REFERENCES compras (id_compra),
+    CONSTRAINT fk_detalles_compra_lote FOREIGN KEY (id_lote) REFERENCES lote (id_lote)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- origen e id_origen apuntan al documento que causo el movimiento (compra, factura, consulta...)
+CREATE TABLE movimientos_inventario (
+    id_movimiento_inventario INT NOT NULL AUTO_INCREMENT,
+    id_lote INT NOT NULL,
+    id_stock_individual INT NULL,
+    tipo VARCHAR(30) NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL,
+    stock_resultante DECIMAL(10,3) NOT NULL,
+    origen VARCHAR(50) NULL,
+    id_origen INT NULL,
+    id_usuario INT NULL,
+    fecha DATETIME NOT NULL,
+    observacion VARCHAR(255) NULL,
+    PRIMARY KEY (id_movimiento_inventario),
+    KEY ix_movimientos_inventario_fecha (fecha),
+    KEY ix_movimientos_inventario_origen (origen, id_origen),
+    CONSTRAINT fk_movimientos_inventario_lote FOREIGN KEY (id_lote) REFERENCES lote (id_lote),
+    CONSTRAINT fk_movimientos_inventario_stock FOREIGN KEY (id_stock_individual) REFERENCES stock_individual (id_stock_individual),
+    CONSTRAINT fk_movimientos_inventario_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS resultados_detalle_informativo;
+DROP TABLE IF EXISTS resultados_detalle_cuantitativo;
+DROP TABLE IF EXISTS resultados_detalle_cualitativo;
+DROP TABLE IF EXISTS examenes_mascota;
+DROP TABLE IF EXISTS detalles_examen_informativo;
+DROP TABLE IF EXISTS detalles_examen_cuantitativo;
+DROP TABLE IF EXISTS detalles_examen_cualitativo;
+DROP TABLE IF EXISTS tipos_examenes;
+DROP TABLE IF EXISTS detalle_usos_servicio;
+DROP TABLE IF EXISTS detalles_servicios_hospitalizacion;
+DROP TABLE IF EXISTS detalles_hospitalizacion;
+DROP TABLE IF EXISTS hospitalizacion;
+DROP TABLE IF EXISTS receta;
+DROP TABLE IF EXISTS detalle_usos_servicio_consulta;
+DROP TABLE IF EXISTS detalles_servicios_consulta;
+DROP TABLE IF EXISTS consulta;
+DROP TABLE IF EXISTS servicio_producto;
+DROP TABLE IF EXISTS servicios;
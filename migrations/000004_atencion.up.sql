@@ -0,0 +1,221 @@
+CREATE TABLE servicios (
+    id_servicio INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    id_especie INT NOT NULL,
+    descripcion VARCHAR(150) NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    aplica_consulta TINYINT(1) NOT NULL DEFAULT 0,
+    aplica_hospitalizacion TINYINT(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_servicio),
+    CONSTRAINT fk_servicios_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_servicios_especie FOREIGN KEY (id_especie) REFERENCES especies (id_especie)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE servicio_producto (
+    id_servicio_producto INT NOT NULL AUTO_INCREMENT,
+    id_servicio INT NOT NULL,
+    id_producto INT NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL DEFAULT 0,
+    razon DECIMAL(10,3) NULL,
+    estado CHAR(1) NOT NULL DEFAULT 'A',
+    PRIMARY KEY (id_servicio_producto),
+    CONSTRAINT fk_servicio_producto_servicio FOREIGN KEY (id_servicio) REFERENCES servicios (id_servicio),
+    CONSTRAINT fk_servicio_producto_producto FOREIGN KEY (id_producto) REFERENCES producto (id_producto)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE consulta (
+    id_consulta INT NOT NULL AUTO_INCREMENT,
+    id_mascota INT NOT NULL,
+    id_usuario INT NOT NULL,
+    fecha DATETIME NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    motivo VARCHAR(255) NULL,
+    temperatura DECIMAL(5,2) NULL,
+    peso DECIMAL(10,3) NULL,
+    `tamaño` DECIMAL(10,2) NULL,
+    condicion_corporal VARCHAR(50) NULL,
+    niveles_deshidratacion VARCHAR(50) NULL,
+    diagnostico TEXT NULL,
+    edad VARCHAR(50) NULL,
+    tiempo_llenado_capilar INT NOT NULL DEFAULT 0,
+    frecuencia_cardiaca INT NOT NULL DEFAULT 0,
+    frecuencia_respiratoria INT NOT NULL DEFAULT 0,
+    estado_consulta VARCHAR(20) NOT NULL DEFAULT 'ACTIVA',
+    PRIMARY KEY (id_consulta),
+    KEY ix_consulta_fecha (fecha),
+    CONSTRAINT fk_consulta_mascota FOREIGN KEY (id_mascota) REFERENCES mascotas (id_mascota),
+    CONSTRAINT fk_consulta_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_servicios_consulta (
+    id_detalle_servicio_consulta INT NOT NULL AUTO_INCREMENT,
+    id_consulta INT NOT NULL,
+    id_servicio INT NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_detalle_servicio_consulta),
+    CONSTRAINT fk_detalles_servicios_consulta_consulta FOREIGN KEY (id_consulta) REFERENCES consulta (id_consulta),
+    CONSTRAINT fk_detalles_servicios_consulta_servicio FOREIGN KEY (id_servicio) REFERENCES servicios (id_servicio)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- tabla e id_referencia apuntan al lote o al stock individual usado
+CREATE TABLE detalle_usos_servicio_consulta (
+    id_detalle_uso_servicio_consulta INT NOT NULL AUTO_INCREMENT,
+    id_detalle_servicio_consulta INT NOT NULL,
+    id_referencia INT NOT NULL,
+    tabla VARCHAR(50) NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_detalle_uso_servicio_consulta),
+    KEY ix_detalle_usos_servicio_consulta_referencia (tabla, id_referencia),
+    CONSTRAINT fk_detalle_usos_servicio_consulta_detalle FOREIGN KEY (id_detalle_servicio_consulta) REFERENCES detalles_servicios_consulta (id_detalle_servicio_consulta)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE receta (
+    id_receta INT NOT NULL AUTO_INCREMENT,
+    id_producto INT NOT NULL,
+    id_consulta INT NOT NULL,
+    prescripcion TEXT NOT NULL,
+    PRIMARY KEY (id_receta),
+    CONSTRAINT fk_receta_producto FOREIGN KEY (id_producto) REFERENCES producto (id_producto),
+    CONSTRAINT fk_receta_consulta FOREIGN KEY (id_consulta) REFERENCES consulta (id_consulta)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE hospitalizacion (
+    id_hospitalizacion INT NOT NULL AUTO_INCREMENT,
+    id_consulta INT NOT NULL,
+    motivo VARCHAR(255) NOT NULL,
+    fecha_ingreso DATETIME NOT NULL,
+    fecha_salida DATETIME NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    abono DECIMAL(10,2) NOT NULL DEFAULT 0,
+    autoriza_examenes TINYINT(1) NOT NULL DEFAULT 0,
+    estado_hospitalizacion VARCHAR(20) NOT NULL DEFAULT 'ACTIVA',
+    PRIMARY KEY (id_hospitalizacion),
+    KEY ix_hospitalizacion_estado (estado_hospitalizacion),
+    CONSTRAINT fk_hospitalizacion_consulta FOREIGN KEY (id_consulta) REFERENCES consulta (id_consulta)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_hospitalizacion (
+    id_detalle_hospitalizacion INT NOT NULL AUTO_INCREMENT,
+    id_hospitalizacion INT NOT NULL,
+    id_usuario INT NOT NULL,
+    descripcion TEXT NOT NULL,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_detalle_hospitalizacion),
+    CONSTRAINT fk_detalles_hospitalizacion_hospitalizacion FOREIGN KEY (id_hospitalizacion) REFERENCES hospitalizacion (id_hospitalizacion),
+    CONSTRAINT fk_detalles_hospitalizacion_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_servicios_hospitalizacion (
+    id_detalle_servicio_hospitalizacion INT NOT NULL AUTO_INCREMENT,
+    id_hospitalizacion INT NOT NULL,
+    id_usuario INT NOT NULL,
+    id_servicio INT NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_detalle_servicio_hospitalizacion),
+    CONSTRAINT fk_detalles_servicios_hospitalizacion_hosp FOREIGN KEY (id_hospitalizacion) REFERENCES hospitalizacion (id_hospitalizacion),
+    CONSTRAINT fk_detalles_servicios_hospitalizacion_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_detalles_servicios_hospitalizacion_servicio FOREIGN KEY (id_servicio) REFERENCES servicios (id_servicio)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- tabla e id_referencia apuntan al lote o al stock individual usado
+CREATE TABLE detalle_usos_servicio (
+    id_detalle_uso_servicio INT NOT NULL AUTO_INCREMENT,
+    id_detalle_servicio_hospitalizacion INT NOT NULL,
+    id_referencia INT NOT NULL,
+    tabla VARCHAR(50) NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_detalle_uso_servicio),
+    KEY ix_detalle_usos_servicio_referencia (tabla, id_referencia),
+    CONSTRAINT fk_detalle_usos_servicio_detalle FOREIGN KEY (id_detalle_servicio_hospitalizacion) REFERENCES detalles_servicios_hospitalizacion (id_detalle_servicio_hospitalizacion)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE tipos_examenes (
+    id_tipo_examen INT NOT NULL AUTO_INCREMENT,
+    id_especie INT NOT NULL,
+    titulo VARCHAR(150) NOT NULL,
+    descripcion VARCHAR(255) NOT NULL,
+    muestra VARCHAR(100) NOT NULL,
+    valor DECIMAL(10,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_tipo_examen),
+    CONSTRAINT fk_tipos_examenes_especie FOREIGN KEY (id_especie) REFERENCES especies (id_especie)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_examen_cualitativo (
+    id_detalle_examen_cualitativo INT NOT NULL AUTO_INCREMENT,
+    id_tipo_examen INT NOT NULL,
+    parametro VARCHAR(150) NOT NULL,
+    PRIMARY KEY (id_detalle_examen_cualitativo),
+    CONSTRAINT fk_detalles_examen_cualitativo_tipo FOREIGN KEY (id_tipo_examen) REFERENCES tipos_examenes (id_tipo_examen)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_examen_cuantitativo (
+    id_detalle_examen_cuantitativo INT NOT NULL AUTO_INCREMENT,
+    id_tipo_examen INT NOT NULL,
+    parametro VARCHAR(150) NOT NULL,
+    rango_referencia_inicial DECIMAL(10,3) NOT NULL DEFAULT 0,
+    rango_referencia_final DECIMAL(10,3) NOT NULL DEFAULT 0,
+    unidad VARCHAR(30) NULL,
+    alerta_menor VARCHAR(255) NULL,
+    alerta_rango VARCHAR(255) NULL,
+    alerta_mayor VARCHAR(255) NULL,
+    PRIMARY KEY (id_detalle_examen_cuantitativo),
+    CONSTRAINT fk_detalles_examen_cuantitativo_tipo FOREIGN KEY (id_tipo_examen) REFERENCES tipos_examenes (id_tipo_examen)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detalles_examen_informativo (
+    id_detalle_examen_informativo INT NOT NULL AUTO_INCREMENT,
+    id_tipo_examen INT NOT NULL,
+    parametro VARCHAR(150) NOT NULL,
+    PRIMARY KEY (id_detalle_examen_informativo),
+    CONSTRAINT fk_detalles_examen_informativo_tipo FOREIGN KEY (id_tipo_examen) REFERENCES tipos_examenes (id_tipo_examen)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- tabla e id_referencia apuntan a la consulta o a la hospitalizacion que pidio el examen
+CREATE TABLE examenes_mascota (
+    id_examen_mascota INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    id_mascota INT NOT NULL,
+    id_tipo_examen INT NOT NULL,
+    fecha_solicitud DATETIME NOT NULL,
+    fecha_llenado DATETIME NULL,
+    estado VARCHAR(20) NOT NULL,
+    id_referencia INT NOT NULL,
+    tabla VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id_examen_mascota),
+    KEY ix_examenes_mascota_referencia (tabla, id_referencia),
+    CONSTRAINT fk_examenes_mascota_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_examenes_mascota_mascota FOREIGN KEY (id_mascota) REFERENCES mascotas (id_mascota),
+    CONSTRAINT fk_examenes_mascota_tipo FOREIGN KEY (id_tipo_examen) REFERENCES tipos_examenes (id_tipo_examen)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE resultados_detalle_cualitativo (
+    id_resultado_detalle_cualitativo INT NOT NULL AUTO_INCREMENT,
+    id_examen_mascota INT NOT NULL,
+    id_detalle_examen_cualitativo INT NOT NULL,
+    resultado TINYINT(1) NULL,
+    PRIMARY KEY (id_resultado_detalle_cualitativo),
+    CONSTRAINT fk_resultados_cualitativo_examen FOREIGN KEY (id_examen_mascota) REFERENCES examenes_mascota (id_examen_mascota),
+    CONSTRAINT fk_resultados_cualitativo_detalle FOREIGN KEY (id_detalle_examen_cualitativo) REFERENCES detalles_examen_cualitativo (id_detalle_examen_cualitativo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE resultados_detalle_cuantitativo (
+    id_resultado_detalle_cuantitativo INT NOT NULL AUTO_INCREMENT,
+    id_examen_mascota INT NOT NULL,
+    id_detalle_examen_cuantitativo INT NOT NULL,
+    resultado DECIMAL(10,3) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_resultado_detalle_cuantitativo),
+    CONSTRAINT fk_resultados_cuantitativo_examen FOREIGN KEY (id_examen_mascota) REFERENCES examenes_mascota (id_examen_mascota),
+    CONSTRAINT fk_resultados_cuantitativo_detalle FOREIGN KEY (id_detalle_examen_cuantitativo) REFERENCES detalles_examen_cuantitativo (id_detalle_examen_cuantitativo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE resultados_detalle_informativo (
+    id_resultado_detalle_informativo INT NOT NULL AUTO_INCREMENT,
+    id_examen_mascota INT NOT NULL,
+    id_detalle_examen_informativo INT NOT NULL,
+    resultado TEXT NOT NULL,
+    PRIMARY KEY (id_resultado_detalle_informativo),
+    CONSTRAINT fk_resultados_informativo_examen FOREIGN KEY (id_examen_mascota) REFERENCES examenes_mascota (id_examen_mascota),
+    CONSTRAINT fk_resultados_informativo_detalle FOREIGN KEY (id_detalle_examen_informativo) REFERENCES detalles_examen_informativo (id_detalle_examen_informativo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
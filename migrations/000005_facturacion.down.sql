@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS comprobantes_electronicos;
+DROP TABLE IF EXISTS secuenciales_comprobante;
+DROP TABLE IF EXISTS pagos;
+DROP TABLE IF EXISTS notas_credito;
+DROP TABLE IF EXISTS detalles_factura;
+DROP TABLE IF EXISTS facturas;
+DROP TABLE IF EXISTS cierres_caja;
+DROP TABLE IF EXISTS movimientos_caja;
+DROP TABLE IF EXISTS sesiones_caja;
+DROP TABLE IF EXISTS tarifas_iva;
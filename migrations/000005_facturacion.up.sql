@@ -0,0 +1,157 @@
+CREATE TABLE tarifas_iva (
+    id_tarifa_iva INT NOT NULL AUTO_INCREMENT,
+    porcentaje INT NOT NULL,
+    fecha_inicio DATE NOT NULL,
+    PRIMARY KEY (id_tarifa_iva),
+    UNIQUE KEY uq_tarifas_iva_fecha_inicio (fecha_inicio)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE sesiones_caja (
+    id_sesion_caja INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    fecha_apertura DATETIME NOT NULL,
+    monto_inicial DECIMAL(12,2) NOT NULL DEFAULT 0,
+    fecha_cierre DATETIME NULL,
+    monto_contado DECIMAL(12,2) NULL,
+    diferencia DECIMAL(12,2) NULL,
+    estado VARCHAR(20) NOT NULL DEFAULT 'ABIERTA',
+    observacion VARCHAR(255) NULL,
+    PRIMARY KEY (id_sesion_caja),
+    KEY ix_sesiones_caja_estado (id_usuario, estado),
+    KEY ix_sesiones_caja_fecha_apertura (fecha_apertura),
+    CONSTRAINT fk_sesiones_caja_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE movimientos_caja (
+    id_movimiento_caja INT NOT NULL AUTO_INCREMENT,
+    id_sesion_caja INT NOT NULL,
+    id_usuario INT NOT NULL,
+    tipo VARCHAR(20) NOT NULL,
+    concepto VARCHAR(255) NOT NULL,
+    monto DECIMAL(12,2) NOT NULL,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_movimiento_caja),
+    CONSTRAINT fk_movimientos_caja_sesion FOREIGN KEY (id_sesion_caja) REFERENCES sesiones_caja (id_sesion_caja),
+    CONSTRAINT fk_movimientos_caja_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE cierres_caja (
+    id_cierre_caja INT NOT NULL AUTO_INCREMENT,
+    id_sesion_caja INT NOT NULL,
+    metodo_pago VARCHAR(30) NOT NULL,
+    esperado DECIMAL(12,2) NOT NULL DEFAULT 0,
+    contado DECIMAL(12,2) NOT NULL DEFAULT 0,
+    diferencia DECIMAL(12,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_cierre_caja),
+    UNIQUE KEY uq_cierres_caja_metodo (id_sesion_caja, metodo_pago),
+    CONSTRAINT fk_cierres_caja_sesion FOREIGN KEY (id_sesion_caja) REFERENCES sesiones_caja (id_sesion_caja)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- origen e id_origen apuntan a la consulta u hospitalizacion facturada
+CREATE TABLE facturas (
+    id_factura INT NOT NULL AUTO_INCREMENT,
+    id_cliente INT NOT NULL,
+    id_usuario INT NOT NULL,
+    fecha DATETIME NOT NULL,
+    subtotal_0 DECIMAL(12,2) NOT NULL DEFAULT 0,
+    subtotal_iva DECIMAL(12,2) NOT NULL DEFAULT 0,
+    descuento DECIMAL(12,2) NOT NULL DEFAULT 0,
+    porcentaje_iva INT NOT NULL DEFAULT 0,
+    iva DECIMAL(12,2) NOT NULL DEFAULT 0,
+    valor DECIMAL(12,2) NOT NULL DEFAULT 0,
+    anulada TINYINT(1) NOT NULL DEFAULT 0,
+    id_sesion_caja INT NULL,
+    origen VARCHAR(50) NULL,
+    id_origen INT NULL,
+    PRIMARY KEY (id_factura),
+    KEY ix_facturas_fecha (fecha),
+    KEY ix_facturas_origen (origen, id_origen),
+    CONSTRAINT fk_facturas_cliente FOREIGN KEY (id_cliente) REFERENCES clientes (id_cliente),
+    CONSTRAINT fk_facturas_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_facturas_sesion_caja FOREIGN KEY (id_sesion_caja) REFERENCES sesiones_caja (id_sesion_caja)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- tabla e id_referencia apuntan a lo vendido (lote, stock individual, servicio...)
+CREATE TABLE detalles_factura (
+    id_detalle_factura INT NOT NULL AUTO_INCREMENT,
+    id_factura INT NOT NULL,
+    id_referencia INT NOT NULL,
+    tabla VARCHAR(50) NOT NULL,
+    cantidad DECIMAL(10,3) NOT NULL DEFAULT 0,
+    precio_unitario DECIMAL(12,2) NOT NULL DEFAULT 0,
+    descuento DECIMAL(12,2) NOT NULL DEFAULT 0,
+    subtotal DECIMAL(12,2) NOT NULL DEFAULT 0,
+    porcentaje_iva INT NOT NULL DEFAULT 0,
+    valor_iva DECIMAL(12,2) NOT NULL DEFAULT 0,
+    valor DECIMAL(12,2) NOT NULL DEFAULT 0,
+    descripcion VARCHAR(255) NULL,
+    origen VARCHAR(50) NULL,
+    id_origen INT NULL,
+    PRIMARY KEY (id_detalle_factura),
+    KEY ix_detalles_factura_referencia (tabla, id_referencia),
+    KEY ix_detalles_factura_origen (origen, id_origen),
+    CONSTRAINT fk_detalles_factura_factura FOREIGN KEY (id_factura) REFERENCES facturas (id_factura)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE notas_credito (
+    id_nota_credito INT NOT NULL AUTO_INCREMENT,
+    id_factura INT NOT NULL,
+    id_usuario INT NOT NULL,
+    fecha DATETIME NOT NULL,
+    motivo VARCHAR(255) NOT NULL,
+    valor DECIMAL(12,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_nota_credito),
+    CONSTRAINT fk_notas_credito_factura FOREIGN KEY (id_factura) REFERENCES facturas (id_factura),
+    CONSTRAINT fk_notas_credito_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE pagos (
+    id_pago INT NOT NULL AUTO_INCREMENT,
+    id_cliente INT NOT NULL,
+    id_factura INT NULL,
+    id_hospitalizacion INT NULL,
+    id_usuario INT NULL,
+    fecha DATETIME NOT NULL,
+    metodo_pago VARCHAR(30) NOT NULL,
+    monto DECIMAL(12,2) NOT NULL,
+    referencia VARCHAR(100) NULL,
+    id_sesion_caja INT NULL,
+    PRIMARY KEY (id_pago),
+    KEY ix_pagos_fecha (fecha),
+    CONSTRAINT fk_pagos_cliente FOREIGN KEY (id_cliente) REFERENCES clientes (id_cliente),
+    CONSTRAINT fk_pagos_factura FOREIGN KEY (id_factura) REFERENCES facturas (id_factura),
+    CONSTRAINT fk_pagos_hospitalizacion FOREIGN KEY (id_hospitalizacion) REFERENCES hospitalizacion (id_hospitalizacion),
+    CONSTRAINT fk_pagos_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario),
+    CONSTRAINT fk_pagos_sesion_caja FOREIGN KEY (id_sesion_caja) REFERENCES sesiones_caja (id_sesion_caja)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE secuenciales_comprobante (
+    id_secuencial_comprobante INT NOT NULL AUTO_INCREMENT,
+    cod_doc CHAR(2) NOT NULL,
+    establecimiento CHAR(3) NOT NULL,
+    punto_emision CHAR(3) NOT NULL,
+    secuencial INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (id_secuencial_comprobante),
+    UNIQUE KEY uq_secuenciales_comprobante (cod_doc, establecimiento, punto_emision)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE comprobantes_electronicos (
+    id_comprobante_electronico INT NOT NULL AUTO_INCREMENT,
+    id_factura INT NOT NULL,
+    cod_doc CHAR(2) NOT NULL,
+    establecimiento CHAR(3) NOT NULL,
+    punto_emision CHAR(3) NOT NULL,
+    secuencial INT NOT NULL,
+    clave_acceso CHAR(49) NOT NULL,
+    `xml` MEDIUMTEXT NOT NULL,
+    estado VARCHAR(30) NOT NULL,
+    numero_autorizacion VARCHAR(49) NULL,
+    fecha_autorizacion DATETIME NULL,
+    mensajes TEXT NULL,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_comprobante_electronico),
+    UNIQUE KEY uq_comprobantes_electronicos_clave_acceso (clave_acceso),
+    UNIQUE KEY uq_comprobantes_electronicos_numero (cod_doc, establecimiento, punto_emision, secuencial),
+    KEY ix_comprobantes_electronicos_estado (estado),
+    CONSTRAINT fk_comprobantes_electronicos_factura FOREIGN KEY (id_factura) REFERENCES facturas (id_factura)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
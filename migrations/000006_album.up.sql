@@ -0,0 +1,7 @@
+CREATE TABLE album (
+    id VARCHAR(36) NOT NULL,
+    name VARCHAR(1000) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,13 @@
+DELETE FROM usuario_rol WHERE id_usuario = 1 AND id_rol = 1;
+DELETE FROM usuarios WHERE id_usuario = 1;
+DELETE FROM rol_acceso WHERE id_rol IN (1, 2, 3);
+DELETE FROM accesos WHERE id_acceso BETWEEN 8 AND 25;
+DELETE FROM accesos WHERE id_acceso BETWEEN 1 AND 7;
+DELETE FROM rol_permiso WHERE id_rol IN (1, 2, 3);
+DELETE FROM permisos WHERE id_permiso BETWEEN 1 AND 27;
+DELETE FROM roles WHERE id_rol IN (1, 2, 3);
+DELETE FROM tarifas_iva WHERE id_tarifa_iva IN (1, 2);
+DELETE FROM unidad WHERE id_unidad BETWEEN 1 AND 6;
+DELETE FROM medida WHERE id_medida BETWEEN 1 AND 3;
+DELETE FROM generos WHERE id_genero IN (1, 2);
+DELETE FROM especies WHERE id_especie BETWEEN 1 AND 6;
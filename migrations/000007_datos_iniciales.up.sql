@@ -0,0 +1,120 @@
+-- Catalogos, roles, accesos y permisos con los que arranca una base nueva. El usuario admin
+-- tiene la clave Admin123* y debe cambiarla en el primer inicio de sesion.
+
+INSERT INTO especies (id_especie, descripcion) VALUES
+    (1, 'Canino'),
+    (2, 'Felino'),
+    (3, 'Ave'),
+    (4, 'Roedor'),
+    (5, 'Reptil'),
+    (6, 'Otro');
+
+INSERT INTO generos (id_genero, descripcion) VALUES
+    (1, 'Macho'),
+    (2, 'Hembra');
+
+INSERT INTO medida (id_medida, descripcion) VALUES
+    (1, 'Volumen'),
+    (2, 'Masa'),
+    (3, 'Unidad');
+
+INSERT INTO unidad (id_unidad, id_medida, descripcion) VALUES
+    (1, 1, 'ml'),
+    (2, 1, 'l'),
+    (3, 2, 'mg'),
+    (4, 2, 'g'),
+    (5, 2, 'kg'),
+    (6, 3, 'unidad');
+
+INSERT INTO tarifas_iva (id_tarifa_iva, porcentaje, fecha_inicio) VALUES
+    (1, 12, '2000-01-01'),
+    (2, 15, '2024-04-01');
+
+INSERT INTO roles (id_rol, descripcion, estado) VALUES
+    (1, 'Administrador', 1),
+    (2, 'Veterinario', 1),
+    (3, 'Recepción', 1);
+
+INSERT INTO permisos (id_permiso, codigo, descripcion) VALUES
+    (1, '*', 'Acceso total'),
+    (2, 'albums.leer', 'Consultar álbumes'),
+    (3, 'albums.escribir', 'Registrar y modificar álbumes'),
+    (4, 'catalogos.leer', 'Consultar catálogos'),
+    (5, 'catalogos.escribir', 'Registrar y modificar catálogos'),
+    (6, 'clientes.leer', 'Consultar clientes y mascotas'),
+    (7, 'clientes.escribir', 'Registrar y modificar clientes y mascotas'),
+    (8, 'consultas.leer', 'Consultar consultas y exámenes'),
+    (9, 'consultas.escribir', 'Registrar y modificar consultas y exámenes'),
+    (10, 'citas.leer', 'Consultar citas médicas'),
+    (11, 'citas.escribir', 'Registrar y modificar citas médicas'),
+    (12, 'hospitalizaciones.leer', 'Consultar hospitalizaciones'),
+    (13, 'hospitalizaciones.escribir', 'Registrar y modificar hospitalizaciones'),
+    (14, 'facturacion.leer', 'Consultar facturas y notas de crédito'),
+    (15, 'facturacion.escribir', 'Registrar y anular facturas y notas de crédito'),
+    (16, 'pagos.leer', 'Consultar pagos'),
+    (17, 'pagos.escribir', 'Registrar pagos'),
+    (18, 'caja.leer', 'Consultar sesiones de caja'),
+    (19, 'caja.escribir', 'Abrir, mover y cerrar caja'),
+    (20, 'inventario.leer', 'Consultar inventario'),
+    (21, 'inventario.escribir', 'Registrar compras y ajustes de inventario'),
+    (22, 'usuarios.leer', 'Consultar usuarios'),
+    (23, 'usuarios.escribir', 'Registrar y modificar usuarios'),
+    (24, 'roles.leer', 'Consultar roles y permisos'),
+    (25, 'roles.escribir', 'Registrar roles y asignar permisos'),
+    (26, 'auditoria.leer', 'Consultar la auditoría'),
+    (27, 'auditoria.escribir', 'Modificar la auditoría');
+
+INSERT INTO rol_permiso (id_rol, id_permiso) VALUES
+    (1, 1),
+    (2, 4),
+    (2, 7),
+    (2, 9),
+    (2, 11),
+    (2, 13),
+    (2, 20),
+    (3, 4),
+    (3, 7),
+    (3, 11),
+    (3, 15),
+    (3, 17),
+    (3, 19),
+    (3, 20);
+
+INSERT INTO accesos (id_acceso, id_acceso_padre, descripcion, ruta, icono, principal) VALUES
+    (1, NULL, 'Inicio', '/inicio', 'home', 1),
+    (2, NULL, 'Clientes', '/clientes', 'people', 1),
+    (3, NULL, 'Atención', '/atencion', 'medical_services', 1),
+    (4, NULL, 'Facturación', '/facturacion', 'receipt', 1),
+    (5, NULL, 'Inventario', '/inventario', 'inventory', 1),
+    (6, NULL, 'Catálogos', '/catalogos', 'list', 1),
+    (7, NULL, 'Administración', '/administracion', 'settings', 1),
+    (8, 2, 'Clientes', '/clientes/lista', 'person', 0),
+    (9, 2, 'Mascotas', '/clientes/mascotas', 'pets', 0),
+    (10, 2, 'Citas médicas', '/clientes/citas', 'event', 0),
+    (11, 3, 'Consultas', '/atencion/consultas', 'assignment', 0),
+    (12, 3, 'Hospitalizaciones', '/atencion/hospitalizaciones', 'local_hospital', 0),
+    (13, 3, 'Exámenes', '/atencion/examenes', 'science', 0),
+    (14, 4, 'Facturas', '/facturacion/facturas', 'receipt_long', 0),
+    (15, 4, 'Pagos', '/facturacion/pagos', 'payments', 0),
+    (16, 4, 'Caja', '/facturacion/caja', 'point_of_sale', 0),
+    (17, 5, 'Productos', '/inventario/productos', 'category', 0),
+    (18, 5, 'Proveedores', '/inventario/proveedores', 'local_shipping', 0),
+    (19, 5, 'Compras', '/inventario/compras', 'shopping_cart', 0),
+    (20, 6, 'Especies', '/catalogos/especies', 'pets', 0),
+    (21, 6, 'Servicios', '/catalogos/servicios', 'healing', 0),
+    (22, 6, 'Tipos de examen', '/catalogos/tipos-examen', 'biotech', 0),
+    (23, 7, 'Usuarios', '/administracion/usuarios', 'manage_accounts', 0),
+    (24, 7, 'Roles', '/administracion/roles', 'admin_panel_settings', 0),
+    (25, 7, 'Auditoría', '/administracion/auditoria', 'history', 0);
+
+INSERT INTO rol_acceso (id_rol, id_acceso) VALUES
+    (1, 1), (1, 2), (1, 3), (1, 4), (1, 5), (1, 6), (1, 7), (1, 8), (1, 9), (1, 10), (1, 11), (1, 12), (1, 13),
+    (1, 14), (1, 15), (1, 16), (1, 17), (1, 18), (1, 19), (1, 20), (1, 21), (1, 22), (1, 23), (1, 24), (1, 25),
+    (2, 1), (2, 2), (2, 3), (2, 5), (2, 8), (2, 9), (2, 10), (2, 11), (2, 12), (2, 13), (2, 17),
+    (3, 1), (3, 2), (3, 4), (3, 5), (3, 8), (3, 9), (3, 10), (3, 14), (3, 15), (3, 16), (3, 17);
+
+INSERT INTO usuarios (id_usuario, nombre, apellido, nombre_usuario, clave, estado, cambiar_clave, intentos_fallidos) VALUES
+    (1, 'Administrador', 'Sistema', 'admin', '$2a$10$2ntYraQCmvv5isHr1zZVxumfHd3yzmEGvJRi5K/LinHSbNBMccosy', 1, 1, 0);
+
+INSERT INTO usuario_rol (id_rol, id_usuario) VALUES
+    (1, 1);
@@ -0,0 +1,7 @@
+DELETE FROM rol_acceso WHERE id_acceso = 26;
+DELETE FROM accesos WHERE id_acceso = 26;
+DELETE FROM rol_permiso WHERE id_permiso IN (28, 29);
+DELETE FROM permisos WHERE id_permiso IN (28, 29);
+DROP TABLE IF EXISTS notificaciones;
+DROP TABLE IF EXISTS destinatarios_alerta;
+DROP TABLE IF EXISTS plantillas_notificacion;
@@ -0,0 +1,61 @@
+-- Plantillas, destinatarios de alertas y bandeja de salida de las notificaciones. Las plantillas usan la
+-- sintaxis de text/template de Go; la funcion fecha da formato a una fecha, e.g. "Lunes 05 de Octubre a las 10:30".
+
+CREATE TABLE plantillas_notificacion (
+    id_plantilla_notificacion INT NOT NULL AUTO_INCREMENT,
+    codigo VARCHAR(50) NOT NULL,
+    canal VARCHAR(20) NOT NULL,
+    asunto VARCHAR(255) NOT NULL DEFAULT '',
+    cuerpo TEXT NOT NULL,
+    PRIMARY KEY (id_plantilla_notificacion),
+    UNIQUE KEY uq_plantillas_notificacion (codigo, canal)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE destinatarios_alerta (
+    id_destinatario_alerta INT NOT NULL AUTO_INCREMENT,
+    tipo_alerta VARCHAR(50) NOT NULL,
+    canal VARCHAR(20) NOT NULL,
+    destino VARCHAR(150) NOT NULL,
+    nombre VARCHAR(100) NULL,
+    activo TINYINT(1) NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_destinatario_alerta),
+    KEY ix_destinatarios_alerta_tipo (tipo_alerta, activo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE notificaciones (
+    id_notificacion INT NOT NULL AUTO_INCREMENT,
+    canal VARCHAR(20) NOT NULL,
+    destinatario VARCHAR(150) NOT NULL,
+    codigo_plantilla VARCHAR(50) NOT NULL,
+    asunto VARCHAR(255) NOT NULL DEFAULT '',
+    cuerpo TEXT NOT NULL,
+    estado VARCHAR(20) NOT NULL DEFAULT 'PENDIENTE',
+    intentos INT NOT NULL DEFAULT 0,
+    ultimo_error TEXT NULL,
+    fecha_creacion DATETIME NOT NULL,
+    fecha_envio DATETIME NULL,
+    PRIMARY KEY (id_notificacion),
+    KEY ix_notificaciones_estado (estado, id_notificacion)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO plantillas_notificacion (id_plantilla_notificacion, codigo, canal, asunto, cuerpo) VALUES
+    (1, 'recordatorio_cita', 'whatsapp', '', 'Saludos {{.Duenio}}, veterinaria DELFICAR le informa que el día *{{fecha .Fecha}}* tiene agendada una cita médica para su mascota *{{.Mascota}}* por el siguiente motivo: *{{.Motivo}}*.'),
+    (2, 'recordatorio_cita', 'email', 'Recordatorio de la cita médica de {{.Mascota}}', 'Saludos {{.Duenio}},\n\nVeterinaria DELFICAR le informa que el día {{fecha .Fecha}} tiene agendada una cita médica para su mascota {{.Mascota}} por el siguiente motivo: {{.Motivo}}.'),
+    (3, 'estado_canal', 'whatsapp', '', 'Whatsapp funcionando'),
+    (4, 'estado_canal', 'email', 'Notificaciones funcionando', 'El envío de notificaciones por correo está funcionando.'),
+    (5, 'poco_stock', 'whatsapp', '', '*Alerta de poco stock*\n{{range .}}Producto: *{{.Producto}}*, Stock: *{{.Stock}}*\n\n{{end}}.'),
+    (6, 'poco_stock', 'email', 'Alerta de poco stock', 'Los siguientes productos tienen poco stock:\n\n{{range .}}- {{.Producto}}: {{.Stock}} (mínimo {{.StockMinimo}})\n{{end}}');
+
+INSERT INTO destinatarios_alerta (id_destinatario_alerta, tipo_alerta, canal, destino, nombre, activo) VALUES
+    (1, 'estado_canal', 'whatsapp', '593960270781', NULL, 1),
+    (2, 'poco_stock', 'whatsapp', '593969708327', NULL, 1);
+
+INSERT INTO permisos (id_permiso, codigo, descripcion) VALUES
+    (28, 'notificaciones.leer', 'Consultar notificaciones, plantillas y destinatarios'),
+    (29, 'notificaciones.escribir', 'Modificar plantillas y destinatarios de notificaciones');
+
+INSERT INTO accesos (id_acceso, id_acceso_padre, descripcion, ruta, icono, principal) VALUES
+    (26, 7, 'Notificaciones', '/administracion/notificaciones', 'notifications', 0);
+
+INSERT INTO rol_acceso (id_rol, id_acceso) VALUES
+    (1, 26);
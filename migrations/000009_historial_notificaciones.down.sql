@@ -0,0 +1,11 @@
+ALTER TABLE notificaciones DROP FOREIGN KEY fk_notificaciones_cliente;
+
+ALTER TABLE notificaciones
+    DROP INDEX ix_notificaciones_referencia,
+    DROP INDEX ix_notificaciones_cliente,
+    DROP INDEX ix_notificaciones_estado,
+    ADD KEY ix_notificaciones_estado (estado, id_notificacion),
+    DROP COLUMN fecha_proximo_intento,
+    DROP COLUMN id_referencia,
+    DROP COLUMN tabla,
+    DROP COLUMN id_cliente;
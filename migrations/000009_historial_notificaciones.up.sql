@@ -0,0 +1,15 @@
+-- Cliente y registro al que se refiere cada notificacion, para el historial de mensajes de un cliente, y la
+-- fecha del proximo intento de envio de las pendientes.
+
+ALTER TABLE notificaciones
+    ADD COLUMN id_cliente INT NULL AFTER id_notificacion,
+    ADD COLUMN tabla VARCHAR(50) NULL AFTER id_cliente,
+    ADD COLUMN id_referencia INT NULL AFTER tabla,
+    ADD COLUMN fecha_proximo_intento DATETIME NULL AFTER fecha_creacion,
+    DROP INDEX ix_notificaciones_estado,
+    ADD KEY ix_notificaciones_estado (estado, fecha_proximo_intento),
+    ADD KEY ix_notificaciones_cliente (id_cliente, id_notificacion),
+    ADD KEY ix_notificaciones_referencia (tabla, id_referencia),
+    ADD CONSTRAINT fk_notificaciones_cliente FOREIGN KEY (id_cliente) REFERENCES clientes (id_cliente);
+
+UPDATE notificaciones SET fecha_proximo_intento = fecha_creacion WHERE estado = 'PENDIENTE';
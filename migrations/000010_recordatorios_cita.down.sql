@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS recordatorios_cita;
+DROP TABLE IF EXISTS recordatorios_tipo_cita;
+ALTER TABLE citas_medicas DROP FOREIGN KEY fk_citas_medicas_tipo_cita;
+ALTER TABLE citas_medicas DROP COLUMN id_tipo_cita;
+DROP TABLE IF EXISTS tipos_cita;
@@ -0,0 +1,55 @@
+-- Tipos de cita y recordatorios configurables: cada tipo tiene sus recordatorios, en minutos antes de la cita,
+-- y las citas sin tipo o de un tipo sin recordatorios usan los de id_tipo_cita NULL. Cada recordatorio enviado
+-- u omitido de una cita se registra en recordatorios_cita.
+
+CREATE TABLE tipos_cita (
+    id_tipo_cita INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id_tipo_cita),
+    UNIQUE KEY uq_tipos_cita_descripcion (descripcion)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE citas_medicas
+    ADD COLUMN id_tipo_cita INT NULL AFTER id_mascota,
+    ADD CONSTRAINT fk_citas_medicas_tipo_cita FOREIGN KEY (id_tipo_cita) REFERENCES tipos_cita (id_tipo_cita);
+
+CREATE TABLE recordatorios_tipo_cita (
+    id_recordatorio_tipo_cita INT NOT NULL AUTO_INCREMENT,
+    id_tipo_cita INT NULL,
+    minutos_antes INT NOT NULL,
+    activo TINYINT(1) NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_recordatorio_tipo_cita),
+    KEY ix_recordatorios_tipo_cita_tipo (id_tipo_cita, activo),
+    CONSTRAINT fk_recordatorios_tipo_cita_tipo FOREIGN KEY (id_tipo_cita) REFERENCES tipos_cita (id_tipo_cita)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE recordatorios_cita (
+    id_recordatorio_cita INT NOT NULL AUTO_INCREMENT,
+    id_cita_medica INT NOT NULL,
+    minutos_antes INT NOT NULL,
+    fecha_cita DATETIME NOT NULL,
+    estado VARCHAR(20) NOT NULL,
+    fecha_registro DATETIME NOT NULL,
+    PRIMARY KEY (id_recordatorio_cita),
+    UNIQUE KEY uq_recordatorios_cita (id_cita_medica, minutos_antes, fecha_cita),
+    CONSTRAINT fk_recordatorios_cita_cita FOREIGN KEY (id_cita_medica) REFERENCES citas_medicas (id_cita_medica)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO tipos_cita (id_tipo_cita, descripcion) VALUES
+    (1, 'Consulta'),
+    (2, 'Vacunación'),
+    (3, 'Control'),
+    (4, 'Cirugía');
+
+INSERT INTO recordatorios_tipo_cita (id_recordatorio_tipo_cita, id_tipo_cita, minutos_antes, activo) VALUES
+    (1, NULL, 4320, 1),
+    (2, NULL, 1440, 1),
+    (3, NULL, 120, 1);
+
+-- Las citas futuras ya notificadas no vuelven a recibir los recordatorios que ya vencieron
+INSERT INTO recordatorios_cita (id_cita_medica, minutos_antes, fecha_cita, estado, fecha_registro)
+SELECT cm.id_cita_medica, r.minutos_antes, cm.fecha, 'OMITIDO', NOW()
+FROM citas_medicas cm
+CROSS JOIN recordatorios_tipo_cita r
+WHERE cm.estado_notificacion = 'SI' AND cm.fecha > NOW() AND r.id_tipo_cita IS NULL
+    AND DATE_SUB(cm.fecha, INTERVAL r.minutos_antes MINUTE) <= NOW();
@@ -0,0 +1,7 @@
+DELETE FROM destinatarios_alerta WHERE id_destinatario_alerta IN (3, 4);
+DELETE FROM plantillas_notificacion WHERE id_plantilla_notificacion BETWEEN 7 AND 13;
+UPDATE plantillas_notificacion
+SET cuerpo = REPLACE(cuerpo, '\n\nResponda *1* para confirmar, *2* para cancelar o *3* para reprogramar la cita.', '')
+WHERE id_plantilla_notificacion = 1;
+DROP TABLE IF EXISTS mensajes_entrantes;
+ALTER TABLE citas_medicas DROP COLUMN estado_confirmacion;
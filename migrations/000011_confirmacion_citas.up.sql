@@ -0,0 +1,38 @@
+-- Confirmacion de las citas por WhatsApp: el recordatorio pide responder 1, 2 o 3, cada mensaje recibido se
+-- registra en mensajes_entrantes y las cancelaciones y reprogramaciones se alertan al personal, al mismo
+-- numero que recibe las alertas de poco stock.
+
+ALTER TABLE citas_medicas
+    ADD COLUMN estado_confirmacion VARCHAR(20) NOT NULL DEFAULT 'PENDIENTE' AFTER estado_notificacion;
+
+CREATE TABLE mensajes_entrantes (
+    id_mensaje_entrante INT NOT NULL AUTO_INCREMENT,
+    canal VARCHAR(20) NOT NULL,
+    remitente VARCHAR(150) NOT NULL,
+    id_cliente INT NULL,
+    id_cita_medica INT NULL,
+    cuerpo TEXT NOT NULL,
+    accion VARCHAR(20) NOT NULL,
+    fecha DATETIME NOT NULL,
+    PRIMARY KEY (id_mensaje_entrante),
+    KEY ix_mensajes_entrantes_cliente (id_cliente, id_mensaje_entrante),
+    CONSTRAINT fk_mensajes_entrantes_cliente FOREIGN KEY (id_cliente) REFERENCES clientes (id_cliente),
+    CONSTRAINT fk_mensajes_entrantes_cita FOREIGN KEY (id_cita_medica) REFERENCES citas_medicas (id_cita_medica)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+UPDATE plantillas_notificacion
+SET cuerpo = CONCAT(cuerpo, '\n\nResponda *1* para confirmar, *2* para cancelar o *3* para reprogramar la cita.')
+WHERE id_plantilla_notificacion = 1;
+
+INSERT INTO plantillas_notificacion (id_plantilla_notificacion, codigo, canal, asunto, cuerpo) VALUES
+    (7, 'respuesta_cita_confirmada', 'whatsapp', '', 'Gracias {{.Duenio}}, su cita del *{{fecha .Fecha}}* para *{{.Mascota}}* quedó confirmada.'),
+    (8, 'respuesta_cita_cancelada', 'whatsapp', '', 'Su cita del *{{fecha .Fecha}}* para *{{.Mascota}}* fue cancelada. Gracias por avisarnos.'),
+    (9, 'respuesta_cita_reprogramar', 'whatsapp', '', 'Recibimos su solicitud, en breve nos comunicaremos con usted para reprogramar la cita de *{{.Mascota}}*.'),
+    (10, 'cita_cancelada', 'whatsapp', '', '*Cita cancelada*\n{{.Duenio}} canceló la cita de *{{.Mascota}}* del *{{fecha .Fecha}}*.'),
+    (11, 'cita_cancelada', 'email', 'Cita cancelada de {{.Mascota}}', '{{.Duenio}} canceló la cita de {{.Mascota}} del {{fecha .Fecha}} por: {{.Motivo}}.'),
+    (12, 'cita_reprogramar', 'whatsapp', '', '*Reprogramar cita*\n{{.Duenio}} ({{.Telefono}}) pide reprogramar la cita de *{{.Mascota}}* del *{{fecha .Fecha}}*.'),
+    (13, 'cita_reprogramar', 'email', 'Reprogramar la cita de {{.Mascota}}', '{{.Duenio}} ({{.Telefono}}) pide reprogramar la cita de {{.Mascota}} del {{fecha .Fecha}}.');
+
+INSERT INTO destinatarios_alerta (id_destinatario_alerta, tipo_alerta, canal, destino, nombre, activo) VALUES
+    (3, 'cita_cancelada', 'whatsapp', '593969708327', NULL, 1),
+    (4, 'cita_reprogramar', 'whatsapp', '593969708327', NULL, 1);
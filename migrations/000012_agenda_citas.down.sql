@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS horarios_veterinario;
+ALTER TABLE citas_medicas DROP FOREIGN KEY fk_citas_medicas_usuario;
+ALTER TABLE citas_medicas
+    DROP INDEX ix_citas_medicas_usuario_fecha,
+    DROP COLUMN estado,
+    DROP COLUMN sala,
+    DROP COLUMN duracion_minutos,
+    DROP COLUMN id_usuario;
+ALTER TABLE tipos_cita DROP COLUMN duracion_minutos;
@@ -0,0 +1,37 @@
+-- Agenda de los veterinarios: cada cita tiene veterinario, duracion, sala y estado, y cada veterinario su
+-- horario de atencion por dia de la semana (0 domingo a 6 sabado). La duracion de una cita se toma de su tipo.
+-- El estado de las citas ya respondidas por el cliente se toma de estado_confirmacion.
+
+ALTER TABLE tipos_cita
+    ADD COLUMN duracion_minutos INT NOT NULL DEFAULT 30 AFTER descripcion;
+
+UPDATE tipos_cita SET duracion_minutos = 15 WHERE id_tipo_cita = 2;
+UPDATE tipos_cita SET duracion_minutos = 20 WHERE id_tipo_cita = 3;
+UPDATE tipos_cita SET duracion_minutos = 120 WHERE id_tipo_cita = 4;
+
+ALTER TABLE citas_medicas
+    ADD COLUMN id_usuario INT NULL AFTER id_tipo_cita,
+    ADD COLUMN duracion_minutos INT NOT NULL DEFAULT 30 AFTER fecha,
+    ADD COLUMN sala VARCHAR(50) NULL AFTER duracion_minutos,
+    ADD COLUMN estado VARCHAR(20) NOT NULL DEFAULT 'AGENDADA' AFTER sala,
+    ADD KEY ix_citas_medicas_usuario_fecha (id_usuario, fecha),
+    ADD CONSTRAINT fk_citas_medicas_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario);
+
+UPDATE citas_medicas cm
+INNER JOIN tipos_cita tc ON tc.id_tipo_cita = cm.id_tipo_cita
+SET cm.duracion_minutos = tc.duracion_minutos;
+
+UPDATE citas_medicas SET estado = 'CONFIRMADA' WHERE estado_confirmacion = 'CONFIRMADA';
+UPDATE citas_medicas SET estado = 'CANCELADA' WHERE estado_confirmacion = 'CANCELADA';
+
+CREATE TABLE horarios_veterinario (
+    id_horario_veterinario INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    dia_semana TINYINT NOT NULL,
+    hora_inicio TIME NOT NULL,
+    hora_fin TIME NOT NULL,
+    activo TINYINT(1) NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_horario_veterinario),
+    KEY ix_horarios_veterinario_usuario (id_usuario, dia_semana, activo),
+    CONSTRAINT fk_horarios_veterinario_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
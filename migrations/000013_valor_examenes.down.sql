@@ -0,0 +1 @@
+ALTER TABLE examenes_mascota DROP COLUMN valor;
@@ -0,0 +1,10 @@
+-- Valor cobrado por cada examen: lo que su actualizacion sumo al valor de la consulta o la hospitalizacion. La
+-- factura cobra este valor, asi los honorarios de la consulta son exactamente lo que queda. Los examenes anteriores
+-- se cobraban al valor de su tipo y lo conservan.
+
+ALTER TABLE examenes_mascota
+    ADD COLUMN valor DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER tabla;
+
+UPDATE examenes_mascota em
+    INNER JOIN tipos_examenes te ON te.id_tipo_examen = em.id_tipo_examen
+SET em.valor = te.valor;
@@ -0,0 +1 @@
+ALTER TABLE citas_medicas DROP INDEX ix_citas_medicas_sala_fecha;
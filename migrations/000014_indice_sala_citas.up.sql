@@ -0,0 +1,4 @@
+-- Indice de la sala de las citas: la revision de cruces bloquea el rango de la sala y no toda la tabla.
+
+ALTER TABLE citas_medicas
+    ADD KEY ix_citas_medicas_sala_fecha (sala, fecha);
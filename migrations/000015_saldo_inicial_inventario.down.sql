@@ -0,0 +1 @@
+DELETE FROM movimientos_inventario WHERE origen = 'saldo_inicial';
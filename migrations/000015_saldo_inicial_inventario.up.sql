@@ -0,0 +1,16 @@
+-- Saldo inicial del ledger de inventario: el stock de los lotes y las unidades abiertas anterior a los movimientos
+-- se registra como un ajuste, asi la conciliacion solo muestra las diferencias posteriores.
+
+INSERT INTO movimientos_inventario (id_lote, id_stock_individual, tipo, cantidad, stock_resultante, origen, fecha, observacion)
+SELECT l.id_lote, NULL, 'ajuste', l.stock - COALESCE(SUM(m.cantidad), 0), l.stock, 'saldo_inicial', NOW(), 'Saldo inicial'
+FROM lote l
+    LEFT JOIN movimientos_inventario m ON m.id_lote = l.id_lote AND m.id_stock_individual IS NULL
+GROUP BY l.id_lote, l.stock
+HAVING l.stock <> COALESCE(SUM(m.cantidad), 0);
+
+INSERT INTO movimientos_inventario (id_lote, id_stock_individual, tipo, cantidad, stock_resultante, origen, fecha, observacion)
+SELECT si.id_lote, si.id_stock_individual, 'ajuste', si.cantidad - COALESCE(SUM(m.cantidad), 0), si.cantidad, 'saldo_inicial', NOW(), 'Saldo inicial'
+FROM stock_individual si
+    LEFT JOIN movimientos_inventario m ON m.id_stock_individual = si.id_stock_individual
+GROUP BY si.id_stock_individual, si.id_lote, si.cantidad
+HAVING si.cantidad <> COALESCE(SUM(m.cantidad), 0);
@@ -0,0 +1,240 @@
+// Package migrate applies the versioned SQL migrations of a directory to a database.
+//
+// The files follow the naming used by golang-migrate, NNNNNN_nombre.up.sql and NNNNNN_nombre.down.sql,
+// and the applied version is kept in the same schema_migrations table, so a database can be migrated
+// with either tool.
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+)
+
+// Table is the table that keeps the version of the schema.
+const Table = "schema_migrations"
+
+// nilVersion is the version golang-migrate records for a schema without migrations.
+const nilVersion = -1
+
+var fileName = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema with the files that apply and revert it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the migrations of a directory to a database.
+type Migrator struct {
+	db     *dbx.DB
+	dir    string
+	logger log.Logger
+}
+
+// New creates a Migrator of the migrations found in dir.
+func New(db *dbx.DB, dir string, logger log.Logger) *Migrator {
+	return &Migrator{db, dir, logger}
+}
+
+// Load reads the migrations of the directory sorted by version. Every migration needs an up file.
+func Load(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	porVersion := map[int64]*Migration{}
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", file.Name(), err)
+		}
+		migration, ok := porVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			porVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = filepath.Join(dir, file.Name())
+		} else {
+			migration.Down = filepath.Join(dir, file.Name())
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range porVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Statements splits a SQL file in its statements. A statement ends with a line ending in ";" and
+// lines starting with "--" are comments.
+func Statements(sql string) []string {
+	statements := []string{}
+	var statement strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(statement.String()), ";"))
+			statement.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Version returns the version of the schema, 0 if no migration was applied. A dirty version is one whose
+// migration failed halfway and must be fixed by hand before migrating again.
+func (m *Migrator) Version() (int64, bool, error) {
+	if err := m.crearTabla(); err != nil {
+		return 0, false, err
+	}
+	var rows []struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+	if err := m.db.NewQuery("SELECT version, dirty FROM " + Table).All(&rows); err != nil {
+		return 0, false, err
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	if rows[0].Version == nilVersion {
+		return 0, rows[0].Dirty, nil
+	}
+	return rows[0].Version, rows[0].Dirty, nil
+}
+
+// Up applies the migrations newer than the version of the schema and returns how many were applied.
+func (m *Migrator) Up() (int, error) {
+	migrations, actual, err := m.preparar()
+	if err != nil {
+		return 0, err
+	}
+	aplicadas := 0
+	for _, migration := range migrations {
+		if migration.Version <= actual {
+			continue
+		}
+		m.logger.Infof("applying migration %d_%s", migration.Version, migration.Name)
+		if err := m.ejecutar(migration.Up, migration.Version); err != nil {
+			return aplicadas, fmt.Errorf("migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		aplicadas++
+	}
+	return aplicadas, nil
+}
+
+// Down reverts the last steps migrations applied and returns how many were reverted.
+func (m *Migrator) Down(steps int) (int, error) {
+	migrations, actual, err := m.preparar()
+	if err != nil {
+		return 0, err
+	}
+	revertidas := 0
+	for i := len(migrations) - 1; i >= 0 && revertidas < steps; i-- {
+		migration := migrations[i]
+		if migration.Version > actual {
+			continue
+		}
+		if migration.Down == "" {
+			return revertidas, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		//La version queda en la migracion anterior
+		anterior := int64(0)
+		if i > 0 {
+			anterior = migrations[i-1].Version
+		}
+		m.logger.Infof("reverting migration %d_%s", migration.Version, migration.Name)
+		if err := m.ejecutar(migration.Down, anterior); err != nil {
+			return revertidas, fmt.Errorf("migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		revertidas++
+	}
+	return revertidas, nil
+}
+
+// preparar loads the migrations and the version of the schema, which must not be dirty.
+func (m *Migrator) preparar() ([]Migration, int64, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	actual, dirty, err := m.Version()
+	if err != nil {
+		return nil, 0, err
+	}
+	if dirty {
+		return nil, 0, fmt.Errorf("the schema is dirty at version %d, fix it and set the version by hand", actual)
+	}
+	return migrations, actual, nil
+}
+
+// ejecutar runs the statements of the file and leaves the schema at version. MySQL commits every
+// DDL statement, so the version stays dirty if a statement fails.
+func (m *Migrator) ejecutar(file string, version int64) error {
+	sql, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := m.guardarVersion(version, true); err != nil {
+		return err
+	}
+	//Se ejecutan sin dbx, que reemplaza {{...}} por nombres de tabla y dañaria las plantillas sembradas
+	for _, statement := range Statements(string(sql)) {
+		if _, err := m.db.DB().Exec(statement); err != nil {
+			return err
+		}
+	}
+	return m.guardarVersion(version, false)
+}
+
+func (m *Migrator) guardarVersion(version int64, dirty bool) error {
+	return m.db.Transactional(func(tx *dbx.Tx) error {
+		if _, err := tx.NewQuery("DELETE FROM " + Table).Execute(); err != nil {
+			return err
+		}
+		if version == 0 && !dirty {
+			return nil
+		}
+		if version == 0 {
+			version = nilVersion
+		}
+		_, err := tx.Insert(Table, dbx.Params{"version": version, "dirty": dirty}).Execute()
+		return err
+	})
+}
+
+func (m *Migrator) crearTabla() error {
+	_, err := m.db.NewQuery("CREATE TABLE IF NOT EXISTS " + Table + " (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)").Execute()
+	return err
+}
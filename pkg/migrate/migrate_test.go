@@ -0,0 +1,140 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"veterinaria-server/pkg/log"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatements(t *testing.T) {
+	sql := `-- Comentario
+CREATE TABLE a (
+    id INT NOT NULL
+);
+
+INSERT INTO a (id) VALUES
+    (1),
+    (2);
+DROP TABLE b`
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n    id INT NOT NULL\n)",
+		"INSERT INTO a (id) VALUES\n    (1),\n    (2)",
+		"DROP TABLE b",
+	}, Statements(sql))
+	assert.Empty(t, Statements("-- Solo comentarios\n\n"))
+}
+
+func TestLoad(t *testing.T) {
+	dir := directorio(t, map[string]string{
+		"000002_b.up.sql":   "",
+		"000001_a.up.sql":   "",
+		"000001_a.down.sql": "",
+		"README.md":         "",
+	})
+	migrations, err := Load(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "a", Up: filepath.Join(dir, "000001_a.up.sql"), Down: filepath.Join(dir, "000001_a.down.sql")},
+		{Version: 2, Name: "b", Up: filepath.Join(dir, "000002_b.up.sql")},
+	}, migrations)
+
+	_, err = Load(directorio(t, map[string]string{"000001_a.down.sql": ""}))
+	assert.NotNil(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	dir := directorio(t, map[string]string{
+		"000001_especies.up.sql":    "CREATE TABLE especies (id INT NOT NULL);",
+		"000001_especies.down.sql":  "DROP TABLE especies;",
+		"000002_datos.up.sql":       "INSERT INTO especies (id) VALUES\n    (1);\nINSERT INTO especies (id) VALUES (2);",
+		"000002_datos.down.sql":     "DELETE FROM especies;",
+		"000003_clientes.up.sql":    "CREATE TABLE clientes (id INT NOT NULL);",
+		"000003_clientes.down.sql":  "DROP TABLE clientes;",
+		"000004_invalida.up.sql":    "CREATE TABLE;",
+		"000004_invalida.down.sql":  "",
+		"000005_pendiente.up.sql":   "CREATE TABLE pendiente (id INT NOT NULL);",
+		"000005_pendiente.down.sql": "DROP TABLE pendiente;",
+	})
+	db, err := dbx.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	logger, _ := log.NewForTest()
+	m := New(db, dir, logger)
+
+	version, dirty, err := m.Version()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), version)
+	assert.False(t, dirty)
+
+	//La migracion 4 falla y deja la version sucia
+	aplicadas, err := m.Up()
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, aplicadas)
+	version, dirty, _ = m.Version()
+	assert.Equal(t, int64(4), version)
+	assert.True(t, dirty)
+	var total int
+	assert.Nil(t, db.NewQuery("SELECT COUNT(*) FROM especies").Row(&total))
+	assert.Equal(t, 2, total)
+
+	_, err = m.Up()
+	assert.NotNil(t, err)
+
+	assert.Nil(t, m.guardarVersion(3, false))
+	revertidas, err := m.Down(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, revertidas)
+	version, dirty, _ = m.Version()
+	assert.Equal(t, int64(1), version)
+	assert.False(t, dirty)
+
+	revertidas, err = m.Down(5)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, revertidas)
+	version, _, _ = m.Version()
+	assert.Equal(t, int64(0), version)
+	assert.NotNil(t, db.NewQuery("SELECT COUNT(*) FROM especies").Row(&total))
+}
+
+func TestMigrator_plantillas(t *testing.T) {
+	plantilla := "Saludos {{.Duenio}}, su cita es el {{fecha .Fecha}} {:hora}"
+	dir := directorio(t, map[string]string{
+		"000001_plantillas.up.sql": "CREATE TABLE plantillas (cuerpo TEXT NOT NULL);\nINSERT INTO plantillas (cuerpo) VALUES ('" + plantilla + "');",
+	})
+	db, err := dbx.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	logger, _ := log.NewForTest()
+
+	_, err = New(db, dir, logger).Up()
+	assert.Nil(t, err)
+	var cuerpo string
+	assert.Nil(t, db.NewQuery("SELECT cuerpo FROM plantillas").Row(&cuerpo))
+	assert.Equal(t, plantilla, cuerpo)
+}
+
+// directorio creates a temporary directory with the files.
+func directorio(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
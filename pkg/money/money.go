@@ -0,0 +1,127 @@
+// Package money provides a fixed point type for monetary amounts, so that sums and taxes
+// do not accumulate the rounding errors of binary floating point.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in cents. It is read from and written to JSON as a decimal number
+// and to the database as a decimal string.
+type Money int64
+
+// Zero is the zero amount.
+const Zero Money = 0
+
+// FromFloat converts a float amount to Money, rounding half away from zero to the cent.
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * 100))
+}
+
+// Parse converts a decimal string such as "12.345" to Money. Digits beyond the cent are
+// rounded half away from zero.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negativo := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	entero, fraccion := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		entero, fraccion = s[:i], s[i+1:]
+	}
+	if entero == "" && fraccion == "" {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	for _, parte := range []string{entero, fraccion} {
+		for i := 0; i < len(parte); i++ {
+			if parte[i] < '0' || parte[i] > '9' {
+				return 0, fmt.Errorf("money: invalid amount %q", s)
+			}
+		}
+	}
+	fraccion += "000"
+	centavos, err := strconv.ParseInt("0"+entero+fraccion[:2], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	if fraccion[2] >= '5' {
+		centavos++
+	}
+	if negativo {
+		centavos = -centavos
+	}
+	return Money(centavos), nil
+}
+
+// Float64 returns the amount as a float, for display or legacy APIs only.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String returns the amount with two decimals, e.g. "12.30".
+func (m Money) String() string {
+	signo := ""
+	centavos := int64(m)
+	if centavos < 0 {
+		signo = "-"
+		centavos = -centavos
+	}
+	return fmt.Sprintf("%s%d.%02d", signo, centavos/100, centavos%100)
+}
+
+// Mul multiplies the amount by a quantity, rounding the result to the cent.
+func (m Money) Mul(cantidad float64) Money {
+	return Money(math.Round(float64(m) * cantidad))
+}
+
+// Percent returns the given percentage of the amount, rounded to the cent.
+func (m Money) Percent(porcentaje int) Money {
+	return Money(math.Round(float64(m) * float64(porcentaje) / 100))
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads the amount from a JSON number or string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	valor, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = valor
+	return nil
+}
+
+// Scan implements sql.Scanner for DECIMAL columns.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		return m.UnmarshalJSON(v)
+	case string:
+		return m.UnmarshalJSON([]byte(v))
+	case int64:
+		*m = Money(v * 100)
+	case float64:
+		*m = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, writing the amount as a decimal string.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,74 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag      string
+		input    string
+		expected Money
+		hasError bool
+	}{
+		{"t1", "12.34", 1234, false},
+		{"t2", "12", 1200, false},
+		{"t3", "0.1", 10, false},
+		{"t4", "1.005", 101, false},
+		{"t5", "1.004", 100, false},
+		{"t6", "-2.5", -250, false},
+		{"t7", ".5", 50, false},
+		{"t8", "abc", 0, true},
+		{"t9", "", 0, true},
+		{"t10", "1.2.3", 0, true},
+	}
+	for _, tc := range tests {
+		m, err := Parse(tc.input)
+		assert.Equal(t, tc.hasError, err != nil, tc.tag)
+		assert.Equal(t, tc.expected, m, tc.tag)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "12.30", Money(1230).String())
+	assert.Equal(t, "0.05", Money(5).String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	// 0.1 + 0.2 sin errores de redondeo binario
+	assert.Equal(t, Money(30), FromFloat(0.1)+FromFloat(0.2))
+	assert.Equal(t, Money(375), Money(250).Mul(1.5))
+	assert.Equal(t, Money(144), Money(1200).Percent(12))
+	assert.Equal(t, Money(13), Money(105).Percent(12))
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var v struct {
+		A Money `json:"a"`
+		B Money `json:"b"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"a": 10.25, "b": "3.1"}`), &v))
+	assert.Equal(t, Money(1025), v.A)
+	assert.Equal(t, Money(310), v.B)
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":10.25,"b":3.10}`, string(b))
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m Money
+	assert.Nil(t, m.Scan([]byte("7.50")))
+	assert.Equal(t, Money(750), m)
+	assert.Nil(t, m.Scan(int64(3)))
+	assert.Equal(t, Money(300), m)
+	assert.Nil(t, m.Scan(nil))
+	assert.Equal(t, Money(0), m)
+	assert.NotNil(t, m.Scan(true))
+	v, err := Money(750).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "7.50", v)
+}
@@ -0,0 +1,205 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+var (
+	// SearchVar specifies the query parameter name for the free-text search
+	SearchVar = "q"
+	// SortVar specifies the query parameter name for the sort, a comma separated list of fields
+	// where a leading "-" sorts in descending order, e.g. "-fecha,nombres"
+	SortVar = "sort"
+	// FromSuffix and ToSuffix are appended to a range field to name its query parameters, e.g. "fecha_desde"
+	FromSuffix = "_desde"
+	ToSuffix   = "_hasta"
+	// DateLayout is the layout of the values of range fields
+	DateLayout = "2006-01-02"
+)
+
+// Fields declares how a list can be filtered, searched and sorted. Every map goes from the name
+// used in the query parameters to the SQL column.
+type Fields struct {
+	// Filters are matched by equality.
+	Filters map[string]string
+	// Ranges are date columns filtered with the FromSuffix and ToSuffix parameters, both included.
+	Ranges map[string]string
+	// Search are the columns matched by the free-text search.
+	Search []string
+	// Sort are the fields the list can be sorted by.
+	Sort map[string]string
+	// DefaultSort is the ORDER BY used when the request has no sort, e.g. "fecha desc".
+	DefaultSort []string
+}
+
+// Query represents the page, the filters, the search and the sort requested for a list.
+type Query struct {
+	Page    int
+	PerPage int
+	Search  string
+	Sort    []string
+	Params  url.Values
+}
+
+// NewQueryFromRequest creates a Query using the query parameters found in the given HTTP request.
+func NewQueryFromRequest(req *http.Request) Query {
+	params := req.URL.Query()
+	query := Query{
+		Page:    parseInt(params.Get(PageVar), 1),
+		PerPage: parseInt(params.Get(PageSizeVar), DefaultPageSize),
+		Search:  strings.TrimSpace(params.Get(SearchVar)),
+		Params:  params,
+	}
+	for _, field := range strings.Split(params.Get(SortVar), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			query.Sort = append(query.Sort, field)
+		}
+	}
+	return query
+}
+
+// List applies the query to the select q restricted to the fields, counts the rows that match and
+// reads the requested page of them into items, a pointer to a slice. q must name its FROM tables.
+// The builder runs the count. A query that does not follow the fields returns a 400 HTTP error.
+func (query Query) List(ctx context.Context, builder dbx.Builder, q *dbx.SelectQuery, fields Fields, items interface{}) (*Pages, error) {
+	if err := query.where(q, fields); err != nil {
+		return nil, err
+	}
+	orderBy, err := query.orderBy(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	count := q.Build()
+	var total int
+	err = builder.NewQuery("SELECT COUNT(*) FROM (" + count.SQL() + ") AS total").
+		Bind(count.Params()).
+		WithContext(ctx).
+		Row(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := New(query.Page, query.PerPage, total)
+	err = q.OrderBy(orderBy...).
+		Offset(int64(pages.Offset())).
+		Limit(int64(pages.Limit())).
+		All(items)
+	if err != nil {
+		return nil, err
+	}
+	pages.Items = items
+	return pages, nil
+}
+
+func (query Query) where(q *dbx.SelectQuery, fields Fields) error {
+	for _, name := range keys(fields.Filters) {
+		column := fields.Filters[name]
+		if value := query.Params.Get(name); value != "" {
+			//Los booleanos se guardan como 0 y 1
+			switch value {
+			case "true":
+				value = "1"
+			case "false":
+				value = "0"
+			}
+			q.AndWhere(dbx.HashExp{column: value})
+		}
+	}
+	for _, name := range keys(fields.Ranges) {
+		column := fields.Ranges[name]
+		from, err := query.date(name + FromSuffix)
+		if err != nil {
+			return err
+		}
+		to, err := query.date(name + ToSuffix)
+		if err != nil {
+			return err
+		}
+		if from != nil && to != nil && to.Before(*from) {
+			return invalid("%s debe ser posterior a %s.", name+ToSuffix, name+FromSuffix)
+		}
+		if from != nil {
+			q.AndWhere(dbx.NewExp(column+" >= {:"+name+"_from}", dbx.Params{name + "_from": *from}))
+		}
+		if to != nil {
+			//El dia hasta se incluye completo
+			q.AndWhere(dbx.NewExp(column+" < {:"+name+"_to}", dbx.Params{name + "_to": to.AddDate(0, 0, 1)}))
+		}
+	}
+	if query.Search != "" && len(fields.Search) > 0 {
+		like := []dbx.Expression{}
+		for _, column := range fields.Search {
+			like = append(like, dbx.Like(column, query.Search))
+		}
+		q.AndWhere(dbx.Or(like...))
+	}
+	return nil
+}
+
+// date parses the date of the param, nil if the param is not present.
+func (query Query) date(param string) (*time.Time, error) {
+	value := query.Params.Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(DateLayout, value, time.Local)
+	if err != nil {
+		return nil, invalid("%s debe tener el formato AAAA-MM-DD.", param)
+	}
+	return &date, nil
+}
+
+func (query Query) orderBy(fields Fields) ([]string, error) {
+	if len(query.Sort) == 0 {
+		return fields.DefaultSort, nil
+	}
+	orderBy := []string{}
+	for _, field := range query.Sort {
+		direction := "asc"
+		if strings.HasPrefix(field, "-") {
+			field, direction = field[1:], "desc"
+		}
+		column, ok := fields.Sort[field]
+		if !ok {
+			return nil, invalid("No se puede ordenar por %s.", field)
+		}
+		orderBy = append(orderBy, column+" "+direction)
+	}
+	return orderBy, nil
+}
+
+// keys returns the keys of the map sorted, so that the SQL built is always the same.
+func keys(m map[string]string) []string {
+	result := []string{}
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func invalid(format string, args ...interface{}) error {
+	return routing.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+// LinkURL returns the URL of the request without the pagination parameters, to be used as the
+// base URL of BuildLinkHeader.
+func LinkURL(req *http.Request) string {
+	params := req.URL.Query()
+	params.Del(PageVar)
+	params.Del(PageSizeVar)
+	if len(params) == 0 {
+		return req.URL.Path
+	}
+	return req.URL.Path + "?" + params.Encode()
+}
@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"net/http"
+	"testing"
+
+	dbx "github.com/go-ozzo/ozzo-dbx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFields = Fields{
+	Filters:     map[string]string{"id_cliente": "f.id_cliente", "anulada": "f.anulada"},
+	Ranges:      map[string]string{"fecha": "f.fecha"},
+	Search:      []string{"c.nombres", "c.apellidos"},
+	Sort:        map[string]string{"fecha": "f.fecha", "cliente": "c.apellidos"},
+	DefaultSort: []string{"f.fecha desc"},
+}
+
+func TestNewQueryFromRequest(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/facturas?page=2&per_page=20&q=+perez+&sort=-fecha,cliente", nil)
+	query := NewQueryFromRequest(req)
+	assert.Equal(t, 2, query.Page)
+	assert.Equal(t, 20, query.PerPage)
+	assert.Equal(t, "perez", query.Search)
+	assert.Equal(t, []string{"-fecha", "cliente"}, query.Sort)
+
+	req, _ = http.NewRequest("GET", "http://example.com/facturas", nil)
+	query = NewQueryFromRequest(req)
+	assert.Equal(t, 1, query.Page)
+	assert.Equal(t, DefaultPageSize, query.PerPage)
+	assert.Nil(t, query.Sort)
+}
+
+func TestQuery_where(t *testing.T) {
+	db := dbx.NewFromDB(nil, "mysql")
+	tests := []struct {
+		tag, url, sql string
+		err           bool
+	}{
+		{"none", "/facturas", "SELECT * FROM `facturas`", false},
+		{"filter", "/facturas?id_cliente=3&anulada=false", "SELECT * FROM `facturas` WHERE (`f`.`anulada`={:p0}) AND (`f`.`id_cliente`={:p1})", false},
+		{"range", "/facturas?fecha_desde=2026-10-01&fecha_hasta=2026-10-31", "SELECT * FROM `facturas` WHERE (f.fecha >= {:fecha_from}) AND (f.fecha < {:fecha_to})", false},
+		{"search", "/facturas?q=perez", "SELECT * FROM `facturas` WHERE (`c`.`nombres` LIKE {:p0}) OR (`c`.`apellidos` LIKE {:p1})", false},
+		{"bad date", "/facturas?fecha_desde=01/10/2026", "", true},
+		{"reversed range", "/facturas?fecha_desde=2026-10-31&fecha_hasta=2026-10-01", "", true},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest("GET", test.url, nil)
+		q := db.Select().From("facturas")
+		err := NewQueryFromRequest(req).where(q, testFields)
+		if test.err {
+			assert.NotNil(t, err, test.tag)
+			continue
+		}
+		assert.Nil(t, err, test.tag)
+		assert.Equal(t, test.sql, q.Build().SQL(), test.tag)
+	}
+}
+
+func TestQuery_orderBy(t *testing.T) {
+	orderBy, err := Query{}.orderBy(testFields)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"f.fecha desc"}, orderBy)
+
+	orderBy, err = Query{Sort: []string{"-fecha", "cliente"}}.orderBy(testFields)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"f.fecha desc", "c.apellidos asc"}, orderBy)
+
+	_, err = Query{Sort: []string{"clave"}}.orderBy(testFields)
+	assert.NotNil(t, err)
+}
+
+func TestLinkURL(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/facturas?page=2&per_page=20&anulada=false", nil)
+	assert.Equal(t, "/facturas?anulada=false", LinkURL(req))
+	req, _ = http.NewRequest("GET", "http://example.com/facturas?page=2", nil)
+	assert.Equal(t, "/facturas", LinkURL(req))
+}
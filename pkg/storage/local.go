@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type local struct {
+	dir string
+}
+
+// NewLocal creates a Storage with the files of the directory dir, which is created when the first file is saved.
+func NewLocal(dir string) Storage {
+	return local{dir}
+}
+
+func (l local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	ruta, err := l.ruta(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(ruta)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		f.Close()
+		return nil, ErrNotFound
+	}
+	return f, nil
+}
+
+func (l local) Save(ctx context.Context, key string, content io.Reader) error {
+	ruta, err := l.ruta(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ruta), 0755); err != nil {
+		return err
+	}
+	//Se escribe en un temporal para no dejar archivos a medias
+	tmp, err := ioutil.TempFile(filepath.Dir(ruta), "."+filepath.Base(ruta)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), ruta)
+}
+
+func (l local) ruta(key string) (string, error) {
+	key, err := clave(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
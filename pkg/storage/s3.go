@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// defaultRegion is the region used to sign the requests when none is configured. MinIO and most
+// S3-compatible servers accept it.
+const defaultRegion = "us-east-1"
+
+// S3Config is the bucket of an S3-compatible server, such as AWS S3 or MinIO.
+type S3Config struct {
+	// Endpoint is the URL of the server, e.g. "https://s3.us-east-1.amazonaws.com" or "http://localhost:9000".
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+type s3 struct {
+	cfg    S3Config
+	prefix string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates a Storage with the objects of the bucket whose keys start with prefix. The objects are
+// addressed by path, <endpoint>/<bucket>/<prefix>/<key>, and the requests are signed with AWS Signature
+// Version 4.
+func NewS3(cfg S3Config, prefix string) Storage {
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	prefix = strings.Trim(path.Clean("/"+prefix), "/")
+	return s3{cfg, prefix, &http.Client{Timeout: time.Minute}, time.Now}
+}
+
+func (s s3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := s.enviar(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, respuestaError(res)
+	}
+	return res.Body, nil
+}
+
+func (s s3) Save(ctx context.Context, key string, content io.Reader) error {
+	//La firma necesita el hash del contenido, los archivos son pequeños y se leen completos
+	body, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	res, err := s.enviar(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return respuestaError(res)
+	}
+	return nil
+}
+
+// enviar sends the signed request for the object with the key.
+func (s s3) enviar(ctx context.Context, method string, key string, body []byte) (*http.Response, error) {
+	key, err := clave(key)
+	if err != nil {
+		return nil, err
+	}
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	//La clave se escapa aparte, parsearla junto al endpoint tomaria "#" y "?" como fragmento y query
+	ruta := endpoint.Path + "/" + s.cfg.Bucket + "/" + key
+	u := url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host, Path: ruta, RawPath: escaparRuta(ruta)}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = int64(len(body))
+	s.firmar(req, body)
+	return s.client.Do(req)
+}
+
+// firmar adds the headers of AWS Signature Version 4 to the request.
+func (s s3) firmar(req *http.Request, body []byte) {
+	ahora := s.now().UTC()
+	fechaHora := ahora.Format("20060102T150405Z")
+	fecha := ahora.Format("20060102")
+	hashContenido := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", fechaHora)
+	req.Header.Set("X-Amz-Content-Sha256", hashContenido)
+
+	cabecerasFirmadas := "host;x-amz-content-sha256;x-amz-date"
+	solicitudCanonica := strings.Join([]string{
+		req.Method,
+		escaparRuta(req.URL.Path),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + hashContenido + "\n" +
+			"x-amz-date:" + fechaHora + "\n",
+		cabecerasFirmadas,
+		hashContenido,
+	}, "\n")
+	alcance := fecha + "/" + s.cfg.Region + "/s3/aws4_request"
+	textoFirma := "AWS4-HMAC-SHA256\n" + fechaHora + "\n" + alcance + "\n" + sha256Hex([]byte(solicitudCanonica))
+	firma := hex.EncodeToString(hmacSHA256(claveFirma(s.cfg.SecretKey, fecha, s.cfg.Region, "s3"), textoFirma))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, alcance, cabecerasFirmadas, firma))
+}
+
+// claveFirma derives the signing key of the date, region and service.
+func claveFirma(secretKey, fecha, region, servicio string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secretKey), fecha)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, servicio)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// escaparRuta encodes every byte of the path but the unreserved characters and the slashes, as S3 expects
+// in the canonical request.
+func escaparRuta(ruta string) string {
+	var b strings.Builder
+	for i := 0; i < len(ruta); i++ {
+		c := ruta[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// respuestaError builds the error of an unexpected response, with the message the server sent.
+func respuestaError(res *http.Response) error {
+	mensaje, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("storage: %s %s: %s %s", res.Request.Method, res.Request.URL.Path, res.Status, strings.TrimSpace(string(mensaje)))
+}
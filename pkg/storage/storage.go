@@ -0,0 +1,72 @@
+// Package storage keeps the files the application reads and produces, such as the templates of the reports,
+// the generated documents and the uploaded files, in the local filesystem or in an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+)
+
+// ErrNotFound is returned by Open when there is no file with the key.
+var ErrNotFound = errors.New("storage: file not found")
+
+// ErrInvalidKey is returned when a key is empty or leaves the storage.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Storage keeps files by key. A key is a slash-separated path relative to the root of the storage.
+type Storage interface {
+	// Open returns the content of the file with the key. The caller must close it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Save writes the content as the file with the key, replacing the previous one.
+	Save(ctx context.Context, key string, content io.Reader) error
+}
+
+// clave cleans the key so it cannot leave the root of the storage.
+func clave(key string) (string, error) {
+	if strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, parte := range strings.Split(key, "/") {
+		if parte == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	limpia := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if limpia == "" {
+		return "", ErrInvalidKey
+	}
+	return limpia, nil
+}
+
+// Server returns a handler that serves the files of the storage. The key of a file is the request path
+// without pathPrefix, e.g. "/v1/files/Receta.xlsx" serves the key "Receta.xlsx" when pathPrefix is "/v1/files/".
+func Server(s Storage, pathPrefix string) routing.Handler {
+	return func(c *routing.Context) error {
+		if !strings.HasPrefix(c.Request.URL.Path, pathPrefix) {
+			return routing.NewHTTPError(http.StatusNotFound)
+		}
+		key := strings.TrimPrefix(c.Request.URL.Path, pathPrefix)
+		content, err := s.Open(c.Request.Context(), key)
+		if err == ErrNotFound || err == ErrInvalidKey {
+			return routing.NewHTTPError(http.StatusNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		tipo := mime.TypeByExtension(path.Ext(key))
+		if tipo == "" {
+			tipo = "application/octet-stream"
+		}
+		c.Response.Header().Set("Content-Type", tipo)
+		_, err = io.Copy(c.Response, content)
+		return err
+	}
+}
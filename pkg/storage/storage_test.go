@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	routing "github.com/go-ozzo/ozzo-routing/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClave(t *testing.T) {
+	for key, want := range map[string]string{
+		"Receta.xlsx":             "Receta.xlsx",
+		"/documentos/a b.pdf":     "documentos/a b.pdf",
+		"documentos//./final.pdf": "documentos/final.pdf",
+	} {
+		got, err := clave(key)
+		assert.Nil(t, err, key)
+		assert.Equal(t, want, got, key)
+	}
+	for _, key := range []string{"", "/", ".", "../config/prod.yml", "a/../../b", "a\\b"} {
+		_, err := clave(key)
+		assert.Equal(t, ErrInvalidKey, err, key)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	probar(t, NewLocal(filepath.Join(dir, "documentos")))
+
+	_, err = os.Stat(filepath.Join(dir, "documentos", "examenes", "Resultado.xlsx"))
+	assert.Nil(t, err)
+}
+
+func TestS3(t *testing.T) {
+	servidor := nuevoServidorS3(t)
+	defer servidor.Close()
+	s3Prueba := NewS3(S3Config{Endpoint: servidor.URL, Bucket: "veterinaria", AccessKey: "AKID", SecretKey: "secreto"}, "/documentos/")
+	probar(t, s3Prueba)
+
+	_, ok := servidor.objetos["/veterinaria/documentos/examenes/Resultado.xlsx"]
+	assert.True(t, ok)
+
+	//Claves con caracteres reservados en una URL
+	for _, key := range []string{"recetas/Rx #1 - 2024.pdf", "recetas/¿dosis? 50%25.pdf"} {
+		assert.Nil(t, s3Prueba.Save(context.Background(), key, strings.NewReader(key)), key)
+		_, ok = servidor.objetos["/veterinaria/documentos/"+key]
+		assert.True(t, ok, key)
+		r, err := s3Prueba.Open(context.Background(), key)
+		if assert.Nil(t, err, key) {
+			contenido, _ := ioutil.ReadAll(r)
+			r.Close()
+			assert.Equal(t, key, string(contenido))
+		}
+	}
+
+	//Credenciales incorrectas
+	s := NewS3(S3Config{Endpoint: servidor.URL, Bucket: "veterinaria", AccessKey: "AKID", SecretKey: "otro"}, "")
+	assert.NotNil(t, s.Save(context.Background(), "a.txt", strings.NewReader("a")))
+}
+
+func TestClaveFirma(t *testing.T) {
+	//Ejemplo de la documentación de AWS Signature Version 4
+	firma := claveFirma("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(firma))
+}
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewLocal(dir)
+	assert.Nil(t, s.Save(context.Background(), "Receta.pdf", strings.NewReader("receta")))
+
+	router := routing.New()
+	router.Get("/v1/files/*", Server(s, "/v1/files/"))
+	for url, want := range map[string]int{
+		"/v1/files/Receta.pdf":          http.StatusOK,
+		"/v1/files/Otra.pdf":            http.StatusNotFound,
+		"/v1/files/%2e%2e/Receta.pdf":   http.StatusNotFound,
+		"/v1/files/":                    http.StatusNotFound,
+		"/v1/files/../../etc/passwd":    http.StatusNotFound,
+		"/v1/files/carpeta/..%2fReceta": http.StatusNotFound,
+	} {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(res, req)
+		assert.Equal(t, want, res.Code, url)
+		if want == http.StatusOK {
+			assert.Equal(t, "receta", res.Body.String())
+			assert.Equal(t, "application/pdf", res.Header().Get("Content-Type"))
+		}
+	}
+}
+
+// probar saves, replaces and opens files of the storage.
+func probar(t *testing.T, s Storage) {
+	ctx := context.Background()
+	_, err := s.Open(ctx, "examenes/Resultado.xlsx")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Nil(t, s.Save(ctx, "examenes/Resultado.xlsx", strings.NewReader("primero")))
+	assert.Nil(t, s.Save(ctx, "examenes/Resultado.xlsx", strings.NewReader("segundo")))
+	assert.Nil(t, s.Save(ctx, "Receta Firulais ñ.xlsx", strings.NewReader("receta")))
+	for key, want := range map[string]string{"examenes/Resultado.xlsx": "segundo", "/Receta Firulais ñ.xlsx": "receta"} {
+		f, err := s.Open(ctx, key)
+		if assert.Nil(t, err, key) {
+			content, _ := ioutil.ReadAll(f)
+			f.Close()
+			assert.Equal(t, want, string(content), key)
+		}
+	}
+
+	assert.Equal(t, ErrInvalidKey, s.Save(ctx, "../fuera.txt", strings.NewReader("x")))
+	_, err = s.Open(ctx, "../fuera.txt")
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+// servidorS3 is a stub of an S3-compatible server that keeps the objects in memory and checks the signature
+// of the requests with the secret key "secreto".
+type servidorS3 struct {
+	*httptest.Server
+	mu      sync.Mutex
+	objetos map[string][]byte
+}
+
+func nuevoServidorS3(t *testing.T) *servidorS3 {
+	s := &servidorS3{objetos: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !firmaValida(r, body) {
+			http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			s.objetos[r.URL.Path] = body
+		case http.MethodGet:
+			objeto, ok := s.objetos[r.URL.Path]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.Write(objeto)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	return s
+}
+
+// firmaValida signs the received request again and compares the Authorization headers.
+func firmaValida(r *http.Request, body []byte) bool {
+	fecha, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		return false
+	}
+	req, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	s := s3{cfg: S3Config{Region: defaultRegion, AccessKey: "AKID", SecretKey: "secreto"}, now: func() time.Time { return fecha }}
+	s.firmar(req, body)
+	return req.Header.Get("Authorization") == r.Header.Get("Authorization")
+}